@@ -0,0 +1,756 @@
+package gopdf
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ============================================================
+// Filling AcroForms of existing PDFs
+// ============================================================
+
+// ErrNoAcroForm is returned when a PDF has no interactive form.
+var ErrNoAcroForm = errors.New("PDF has no AcroForm")
+
+// Field flags (PDF 32000-1:2008, 12.7.3.1 and 12.7.4).
+const (
+	formFlagReadOnly    = 1 << 0
+	formFlagRequired    = 1 << 1
+	formFlagMultiline   = 1 << 12
+	formFlagPassword    = 1 << 13
+	formFlagRadio       = 1 << 15
+	formFlagPushbutton  = 1 << 16
+	formFlagCombo       = 1 << 17
+	formFlagEdit        = 1 << 18
+	formFlagMultiSelect = 1 << 21
+	formFlagComb        = 1 << 24
+)
+
+// Annotation flags that keep a widget from being drawn.
+const (
+	annotFlagHidden = 1 << 1
+	annotFlagNoView = 1 << 5
+)
+
+// FormFieldInfo describes a field of an existing PDF form.
+type FormFieldInfo struct {
+	// Name is the fully qualified field name, e.g. "applicant.address.city".
+	Name string
+	// Type is the field type.
+	Type FormFieldType
+	// Value is the current value. Checkboxes and radio buttons report the
+	// selected state name, or "Off".
+	Value string
+	// Values holds all selected values of a multi-select list box.
+	Values []string
+	// DefaultValue is the value the field is reset to.
+	DefaultValue string
+	// Options are the display labels of a choice field, or the on-state
+	// names of a checkbox or radio button group.
+	Options []string
+	// ExportValues are the export values of a choice field. They equal
+	// Options unless the field defines separate export values.
+	ExportValues []string
+	// MaxLen is the maximum length of a text field (0 = unlimited).
+	MaxLen int
+	// Flags are the raw field flags (/Ff).
+	Flags int
+	// ReadOnly, Required, Multiline, Combo, Editable and MultiSelect
+	// decode the common field flags.
+	ReadOnly    bool
+	Required    bool
+	Multiline   bool
+	Combo       bool
+	Editable    bool
+	MultiSelect bool
+	// Pages are the zero-based indices of the pages showing the field.
+	Pages []int
+}
+
+// FormFillOption configures how a filled form is saved.
+type FormFillOption struct {
+	// Flatten draws the field appearances into the page content and
+	// removes the interactive form.
+	Flatten bool
+	// Incremental appends the changes to the original file instead of
+	// rewriting it, which keeps existing signatures valid.
+	Incremental bool
+}
+
+// acroField is a terminal field of the form field tree.
+type acroField struct {
+	num     int // field object number
+	name    string
+	widgets []int // widget annotation object numbers
+	dirty   bool
+}
+
+// FormFiller sets the values of the fields of an existing PDF form and
+// regenerates their appearances.
+//
+// Example:
+//
+//	data, _ := os.ReadFile("application.pdf")
+//	filler, err := gopdf.NewFormFiller(data)
+//	if err != nil {
+//	    return err
+//	}
+//	filler.SetValue("applicant.name", "Jane Doe")
+//	filler.SetChecked("agree", true)
+//	out, err := filler.Save(gopdf.FormFillOption{Flatten: true})
+type FormFiller struct {
+	store    *pdfObjectStore
+	acroNum  int // object number of the AcroForm, or 0 when inline in the catalog
+	fields   []*acroField
+	byName   map[string]*acroField
+	widgetPg map[int]int // widget object number -> page index
+	fonts    map[string]*formFont
+}
+
+// NewFormFiller parses pdfData and its form field tree.
+func NewFormFiller(pdfData []byte) (*FormFiller, error) {
+	store, err := newPDFObjectStore(pdfData)
+	if err != nil {
+		return nil, err
+	}
+	return newFormFiller(store)
+}
+
+// newFormFiller reads the form field tree of the document in store.
+func newFormFiller(store *pdfObjectStore) (*FormFiller, error) {
+	f := &FormFiller{
+		store:    store,
+		byName:   make(map[string]*acroField),
+		widgetPg: make(map[int]int),
+		fonts:    make(map[string]*formFont),
+	}
+	acro := pdfDictGet(store.dict(store.catalog()), "/AcroForm")
+	if acro == "" {
+		return nil, ErrNoAcroForm
+	}
+	if num, ok := pdfRef(acro); ok {
+		f.acroNum = num
+	}
+	if f.acroForm() == "" {
+		return nil, ErrNoAcroForm
+	}
+	visited := make(map[int]bool)
+	for _, num := range pdfRefs(store.resolve(pdfDictGet(f.acroForm(), "/Fields"))) {
+		f.walk(num, "", visited)
+	}
+	for i, page := range store.pageNums() {
+		for _, num := range pdfRefs(store.resolve(pdfDictGet(store.dict(page), "/Annots"))) {
+			f.widgetPg[num] = i
+		}
+	}
+	return f, nil
+}
+
+// ListFormFields returns the fields of the form in pdfData.
+func ListFormFields(pdfData []byte) ([]FormFieldInfo, error) {
+	f, err := NewFormFiller(pdfData)
+	if err != nil {
+		return nil, err
+	}
+	return f.Fields(), nil
+}
+
+// FillForm sets the fields of the form in pdfData by fully qualified name
+// and returns the saved document. Checkboxes accept "true"/"false" or a
+// state name; list boxes with multiple selections are set with
+// FormFiller.SetValues.
+func FillForm(pdfData []byte, values map[string]string, opt FormFillOption) ([]byte, error) {
+	f, err := NewFormFiller(pdfData)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if err := f.SetValue(name, values[name]); err != nil {
+			return nil, err
+		}
+	}
+	return f.Save(opt)
+}
+
+// acroForm returns the AcroForm dictionary.
+func (f *FormFiller) acroForm() string {
+	if f.acroNum > 0 {
+		return f.store.dict(f.acroNum)
+	}
+	return f.store.resolveDict(pdfDictGet(f.store.dict(f.store.catalog()), "/AcroForm"))
+}
+
+func (f *FormFiller) setAcroForm(dict string) {
+	if f.acroNum > 0 {
+		f.store.setDict(f.acroNum, dict)
+		return
+	}
+	cat := f.store.catalog()
+	f.store.setDict(cat, pdfDictSet(f.store.dict(cat), "/AcroForm", dict))
+}
+
+// walk adds the terminal fields below the field object num.
+func (f *FormFiller) walk(num int, prefix string, visited map[int]bool) {
+	if visited[num] || len(visited) > 100000 {
+		return
+	}
+	visited[num] = true
+	dict := f.store.dict(num)
+	if dict == "" {
+		return
+	}
+	name := prefix
+	if t := pdfDictGet(dict, "/T"); t != "" {
+		part := decodePDFTextString(t)
+		if name != "" {
+			name += "." + part
+		} else {
+			name = part
+		}
+	}
+	var widgets, children []int
+	for _, kid := range pdfRefs(f.store.resolve(pdfDictGet(dict, "/Kids"))) {
+		kd := f.store.dict(kid)
+		if pdfDictGet(kd, "/T") != "" || (pdfDictGet(kd, "/Kids") != "" && pdfDictGet(kd, "/Subtype") != "/Widget") {
+			children = append(children, kid)
+		} else {
+			widgets = append(widgets, kid)
+		}
+	}
+	for _, kid := range children {
+		f.walk(kid, name, visited)
+	}
+	if len(children) > 0 && len(widgets) == 0 {
+		return
+	}
+	if len(widgets) == 0 && pdfDictGet(dict, "/Kids") == "" {
+		widgets = []int{num} // field and widget merged into one dictionary
+	}
+	field := &acroField{num: num, name: name, widgets: widgets}
+	f.fields = append(f.fields, field)
+	if _, dup := f.byName[name]; !dup {
+		f.byName[name] = field
+	}
+}
+
+// inherited returns the value of an inheritable field attribute.
+func (f *FormFiller) inherited(num int, key string) string {
+	for depth := 0; num > 0 && depth < 64; depth++ {
+		dict := f.store.dict(num)
+		if v := pdfDictGet(dict, key); v != "" {
+			return v
+		}
+		num, _ = pdfRef(pdfDictGet(dict, "/Parent"))
+	}
+	return ""
+}
+
+func (f *FormFiller) flags(field *acroField) int {
+	ff, _ := strconv.Atoi(f.store.resolve(f.inherited(field.num, "/Ff")))
+	return ff
+}
+
+func (f *FormFiller) fieldType(field *acroField) FormFieldType {
+	ff := f.flags(field)
+	switch f.inherited(field.num, "/FT") {
+	case "/Btn":
+		switch {
+		case ff&formFlagPushbutton != 0:
+			return FormFieldButton
+		case ff&formFlagRadio != 0:
+			return FormFieldRadio
+		}
+		return FormFieldCheckbox
+	case "/Ch":
+		return FormFieldChoice
+	case "/Sig":
+		return FormFieldSignature
+	}
+	return FormFieldText
+}
+
+// choiceOptions returns the export values and display labels of a choice field.
+func (f *FormFiller) choiceOptions(field *acroField) (exports, labels []string) {
+	for _, item := range parsePDFArray(f.store.resolve(f.inherited(field.num, "/Opt"))) {
+		item = f.store.resolve(item)
+		if pair := parsePDFArray(item); len(pair) >= 2 {
+			exports = append(exports, decodePDFTextString(f.store.resolve(pair[0])))
+			labels = append(labels, decodePDFTextString(f.store.resolve(pair[1])))
+			continue
+		}
+		v := decodePDFTextString(item)
+		exports = append(exports, v)
+		labels = append(labels, v)
+	}
+	return exports, labels
+}
+
+// onStates returns the on-state names of a button widget.
+func (f *FormFiller) onStates(widget int) []string {
+	wd := f.store.dict(widget)
+	var states []string
+	ap := f.store.resolveDict(pdfDictGet(wd, "/AP"))
+	for _, e := range parsePDFDict(f.store.resolveDict(pdfDictGet(ap, "/N"))) {
+		if s := pdfNameValue(e.key); s != "Off" {
+			states = append(states, s)
+		}
+	}
+	if len(states) == 0 {
+		if s := pdfNameValue(pdfDictGet(wd, "/AS")); s != "" && s != "Off" {
+			states = append(states, s)
+		}
+	}
+	return states
+}
+
+// buttonStates returns the on-state names of all widgets of a button field.
+func (f *FormFiller) buttonStates(field *acroField) []string {
+	var states []string
+	seen := make(map[string]bool)
+	for _, w := range field.widgets {
+		for _, s := range f.onStates(w) {
+			if !seen[s] {
+				seen[s] = true
+				states = append(states, s)
+			}
+		}
+	}
+	if len(states) == 0 {
+		if v := pdfNameValue(f.store.resolve(f.inherited(field.num, "/V"))); v != "" && v != "Off" {
+			states = append(states, v)
+		} else {
+			states = append(states, "Yes")
+		}
+	}
+	return states
+}
+
+// values returns the current values of a field.
+func (f *FormFiller) values(field *acroField, key string) []string {
+	v := f.store.resolve(f.inherited(field.num, key))
+	switch {
+	case v == "":
+		return nil
+	case strings.HasPrefix(v, "/"):
+		return []string{pdfNameValue(v)}
+	case strings.HasPrefix(v, "["):
+		var out []string
+		for _, item := range parsePDFArray(v) {
+			out = append(out, decodePDFTextString(f.store.resolve(item)))
+		}
+		return out
+	}
+	return []string{decodePDFTextString(v)}
+}
+
+// Fields returns the fields of the form in field tree order.
+func (f *FormFiller) Fields() []FormFieldInfo {
+	infos := make([]FormFieldInfo, 0, len(f.fields))
+	for _, field := range f.fields {
+		infos = append(infos, f.info(field))
+	}
+	return infos
+}
+
+func (f *FormFiller) info(field *acroField) FormFieldInfo {
+	ff := f.flags(field)
+	info := FormFieldInfo{
+		Name:        field.name,
+		Type:        f.fieldType(field),
+		Flags:       ff,
+		ReadOnly:    ff&formFlagReadOnly != 0,
+		Required:    ff&formFlagRequired != 0,
+		Multiline:   ff&formFlagMultiline != 0,
+		Combo:       ff&formFlagCombo != 0,
+		Editable:    ff&formFlagEdit != 0,
+		MultiSelect: ff&formFlagMultiSelect != 0,
+	}
+	info.MaxLen, _ = strconv.Atoi(f.store.resolve(f.inherited(field.num, "/MaxLen")))
+	if vals := f.values(field, "/V"); len(vals) > 0 {
+		info.Value = vals[0]
+		if len(vals) > 1 {
+			info.Values = vals
+		}
+	}
+	if vals := f.values(field, "/DV"); len(vals) > 0 {
+		info.DefaultValue = vals[0]
+	}
+	switch info.Type {
+	case FormFieldCheckbox, FormFieldRadio:
+		info.Options = f.buttonStates(field)
+		if info.Value == "" {
+			info.Value = "Off"
+		}
+	case FormFieldChoice:
+		info.ExportValues, info.Options = f.choiceOptions(field)
+	}
+	seen := make(map[int]bool)
+	for _, w := range field.widgets {
+		if pg, ok := f.widgetPg[w]; ok && !seen[pg] {
+			seen[pg] = true
+			info.Pages = append(info.Pages, pg)
+		}
+	}
+	return info
+}
+
+func (f *FormFiller) field(name string) (*acroField, error) {
+	field, ok := f.byName[name]
+	if !ok {
+		return nil, fmt.Errorf("form field %q not found", name)
+	}
+	return field, nil
+}
+
+// SetValue sets the value of a field. Checkboxes accept a state name,
+// "Off", or a boolean word such as "true", "yes" or "1"; radio groups and
+// choice fields accept an export value or display label.
+func (f *FormFiller) SetValue(name, value string) error {
+	field, err := f.field(name)
+	if err != nil {
+		return err
+	}
+	switch f.fieldType(field) {
+	case FormFieldCheckbox, FormFieldRadio:
+		return f.setButton(field, value)
+	case FormFieldChoice:
+		return f.setChoice(field, []string{value})
+	case FormFieldText:
+		return f.setText(field, value)
+	}
+	return fmt.Errorf("form field %q: cannot set the value of a %s field", name, f.fieldType(field))
+}
+
+// SetValues selects several options of a multi-select list box.
+func (f *FormFiller) SetValues(name string, values []string) error {
+	field, err := f.field(name)
+	if err != nil {
+		return err
+	}
+	if f.fieldType(field) != FormFieldChoice {
+		return fmt.Errorf("form field %q is not a choice field", name)
+	}
+	return f.setChoice(field, values)
+}
+
+// SetChecked checks or unchecks a checkbox.
+func (f *FormFiller) SetChecked(name string, checked bool) error {
+	field, err := f.field(name)
+	if err != nil {
+		return err
+	}
+	if t := f.fieldType(field); t != FormFieldCheckbox && t != FormFieldRadio {
+		return fmt.Errorf("form field %q is not a checkbox", name)
+	}
+	if !checked {
+		return f.setButton(field, "Off")
+	}
+	return f.setButton(field, f.buttonStates(field)[0])
+}
+
+func (f *FormFiller) setFieldKey(field *acroField, key, value string) {
+	dict := f.store.dict(field.num)
+	if value == "" {
+		dict = pdfDictDelete(dict, key)
+	} else {
+		dict = pdfDictSet(dict, key, value)
+	}
+	f.store.setDict(field.num, dict)
+	field.dirty = true
+}
+
+func (f *FormFiller) setText(field *acroField, value string) error {
+	maxLen, _ := strconv.Atoi(f.store.resolve(f.inherited(field.num, "/MaxLen")))
+	if maxLen > 0 && len([]rune(value)) > maxLen {
+		return fmt.Errorf("form field %q: value longer than MaxLen %d", field.name, maxLen)
+	}
+	f.setFieldKey(field, "/V", encodePDFTextString(value))
+	return nil
+}
+
+func (f *FormFiller) setButton(field *acroField, value string) error {
+	state := ""
+	states := f.buttonStates(field)
+	for _, s := range states {
+		if s == value {
+			state = s
+		}
+	}
+	if state == "" {
+		// radio groups may name their widgets by index into /Opt
+		exports, _ := f.choiceOptions(field)
+		for i, e := range exports {
+			if e == value && i < len(field.widgets) {
+				if on := f.onStates(field.widgets[i]); len(on) > 0 {
+					state = on[0]
+				}
+			}
+		}
+	}
+	if state == "" {
+		switch strings.ToLower(value) {
+		case "", "off", "false", "no", "0":
+			state = "Off"
+		case "true", "yes", "on", "1", "x", "checked":
+			if f.fieldType(field) == FormFieldCheckbox {
+				state = states[0]
+			}
+		}
+	}
+	if state == "" {
+		return fmt.Errorf("form field %q: %q is not one of %v", field.name, value, states)
+	}
+	f.setFieldKey(field, "/V", encodePDFName(state))
+	for _, w := range field.widgets {
+		as := "Off"
+		for _, s := range f.widgetStates(field, w) {
+			if s == state {
+				as = state
+			}
+		}
+		wd := pdfDictSet(f.store.dict(w), "/AS", encodePDFName(as))
+		f.store.setDict(w, wd)
+	}
+	return nil
+}
+
+func (f *FormFiller) setChoice(field *acroField, values []string) error {
+	ff := f.flags(field)
+	exports, labels := f.choiceOptions(field)
+	if len(values) > 1 && ff&formFlagMultiSelect == 0 {
+		return fmt.Errorf("form field %q does not allow multiple selections", field.name)
+	}
+	var selected []string
+	var indices []int
+	for _, v := range values {
+		if v == "" && len(values) == 1 {
+			break
+		}
+		idx := -1
+		for i := range exports {
+			if exports[i] == v {
+				idx = i
+				break
+			}
+		}
+		for i := range labels {
+			if idx < 0 && labels[i] == v {
+				idx = i
+			}
+		}
+		switch {
+		case idx >= 0:
+			selected = append(selected, exports[idx])
+			indices = append(indices, idx)
+		case ff&formFlagCombo != 0 && ff&formFlagEdit != 0:
+			selected = append(selected, v)
+		default:
+			return fmt.Errorf("form field %q: %q is not one of %v", field.name, v, labels)
+		}
+	}
+	switch len(selected) {
+	case 0:
+		f.setFieldKey(field, "/V", "")
+	case 1:
+		f.setFieldKey(field, "/V", encodePDFTextString(selected[0]))
+	default:
+		items := make([]string, len(selected))
+		for i, s := range selected {
+			items[i] = encodePDFTextString(s)
+		}
+		f.setFieldKey(field, "/V", "["+strings.Join(items, " ")+"]")
+	}
+	sort.Ints(indices)
+	if ff&formFlagCombo == 0 && len(indices) > 0 {
+		parts := make([]string, len(indices))
+		for i, idx := range indices {
+			parts[i] = strconv.Itoa(idx)
+		}
+		f.setFieldKey(field, "/I", "["+strings.Join(parts, " ")+"]")
+	} else {
+		f.setFieldKey(field, "/I", "")
+	}
+	return nil
+}
+
+// Save regenerates the appearances of the changed fields and returns the
+// document, optionally flattened.
+func (f *FormFiller) Save(opt FormFillOption) ([]byte, error) {
+	changed := false
+	for _, field := range f.fields {
+		changed = changed || field.dirty
+		for _, w := range field.widgets {
+			// flattening needs an appearance for every widget
+			if field.dirty || (opt.Flatten && pdfDictGet(f.store.dict(w), "/AP") == "") {
+				f.updateAppearance(field, w)
+			}
+		}
+	}
+	if changed {
+		// XFA data would override the filled AcroForm values in viewers that
+		// support it.
+		if acro := f.acroForm(); pdfDictGet(acro, "/XFA") != "" {
+			f.setAcroForm(pdfDictDelete(acro, "/XFA"))
+		}
+	}
+	if opt.Flatten {
+		f.flatten()
+	}
+	if opt.Incremental {
+		return f.store.incremental(), nil
+	}
+	return f.store.rewrite(), nil
+}
+
+// flatten draws the normal appearance of every widget into its page and
+// removes the form.
+func (f *FormFiller) flatten() {
+	widgets := make(map[int]bool)
+	for _, field := range f.fields {
+		for _, w := range field.widgets {
+			widgets[w] = true
+		}
+	}
+	for _, page := range f.store.pageNums() {
+		pd := f.store.dict(page)
+		annots := pdfRefs(f.store.resolve(pdfDictGet(pd, "/Annots")))
+		var kept []string
+		var draw strings.Builder
+		xobjs := f.store.resolveDict(pdfDictGet(f.pageResources(page), "/XObject"))
+		if xobjs == "" {
+			xobjs = "<<>>"
+		}
+		for _, num := range annots {
+			if !widgets[num] {
+				kept = append(kept, fmt.Sprintf("%d 0 R", num))
+				continue
+			}
+			ap, ok := f.widgetAppearance(num)
+			if !ok {
+				continue
+			}
+			name := fmt.Sprintf("/FlatFm%d", ap)
+			xobjs = pdfDictSet(xobjs, name, fmt.Sprintf("%d 0 R", ap))
+			cm := f.appearanceMatrix(num, ap)
+			fmt.Fprintf(&draw, "q\n%s cm\n%s Do\nQ\n", cm, name)
+		}
+		if len(kept) == len(annots) {
+			continue
+		}
+		if draw.Len() > 0 {
+			res := pdfDictSet(f.pageResources(page), "/XObject", xobjs)
+			pd = pdfDictSet(pd, "/Resources", res)
+			contents := []string{fmt.Sprintf("%d 0 R", f.store.addStream("<<>>", []byte("q\n")))}
+			orig := pdfDictGet(pd, "/Contents")
+			if items := parsePDFArray(f.store.resolve(orig)); strings.HasPrefix(strings.TrimSpace(f.store.resolve(orig)), "[") {
+				contents = append(contents, items...)
+			} else if orig != "" {
+				contents = append(contents, orig)
+			}
+			tail := f.store.addStream("<<>>", []byte("Q\n"+draw.String()))
+			contents = append(contents, fmt.Sprintf("%d 0 R", tail))
+			pd = pdfDictSet(pd, "/Contents", "["+strings.Join(contents, " ")+"]")
+		}
+		if len(kept) == 0 {
+			pd = pdfDictDelete(pd, "/Annots")
+		} else {
+			pd = pdfDictSet(pd, "/Annots", "["+strings.Join(kept, " ")+"]")
+		}
+		f.store.setDict(page, pd)
+	}
+	cat := f.store.catalog()
+	f.store.setDict(cat, pdfDictDelete(f.store.dict(cat), "/AcroForm"))
+}
+
+// pageResources returns the resource dictionary of a page, following the
+// inheritance through the page tree.
+func (f *FormFiller) pageResources(page int) string {
+	for num, depth := page, 0; num > 0 && depth < 64; depth++ {
+		dict := f.store.dict(num)
+		if res := pdfDictGet(dict, "/Resources"); res != "" {
+			return f.store.resolveDict(res)
+		}
+		num, _ = pdfRef(pdfDictGet(dict, "/Parent"))
+	}
+	return "<<>>"
+}
+
+// widgetAppearance returns the object number of the normal appearance
+// stream of a visible widget.
+func (f *FormFiller) widgetAppearance(widget int) (int, bool) {
+	wd := f.store.dict(widget)
+	flags, _ := strconv.Atoi(pdfDictGet(wd, "/F"))
+	if flags&(annotFlagHidden|annotFlagNoView) != 0 {
+		return 0, false
+	}
+	n := pdfDictGet(f.store.resolveDict(pdfDictGet(wd, "/AP")), "/N")
+	if num, ok := pdfRef(n); ok && strings.Contains(f.store.body(num), "stream") {
+		return num, true
+	}
+	states := f.store.resolveDict(n)
+	if states == "" {
+		return 0, false
+	}
+	return pdfRef(pdfDictGet(states, pdfDictGet(wd, "/AS")))
+}
+
+// appearanceMatrix returns the matrix that maps the bounding box of an
+// appearance stream onto the rectangle of its widget.
+func (f *FormFiller) appearanceMatrix(widget, ap int) string {
+	rect := normalizeRect(pdfNumbers(f.store.resolve(pdfDictGet(f.store.dict(widget), "/Rect"))))
+	ad := f.store.dict(ap)
+	bbox := normalizeRect(pdfNumbers(f.store.resolve(pdfDictGet(ad, "/BBox"))))
+	m := pdfNumbers(pdfDictGet(ad, "/Matrix"))
+	if len(m) != 6 {
+		m = []float64{1, 0, 0, 1, 0, 0}
+	}
+	// transformed bounding box
+	x0, y0, x1, y1 := 1e9, 1e9, -1e9, -1e9
+	for _, c := range [][2]float64{{bbox[0], bbox[1]}, {bbox[2], bbox[1]}, {bbox[0], bbox[3]}, {bbox[2], bbox[3]}} {
+		x := m[0]*c[0] + m[2]*c[1] + m[4]
+		y := m[1]*c[0] + m[3]*c[1] + m[5]
+		if x < x0 {
+			x0 = x
+		}
+		if x > x1 {
+			x1 = x
+		}
+		if y < y0 {
+			y0 = y
+		}
+		if y > y1 {
+			y1 = y
+		}
+	}
+	sx, sy := 1.0, 1.0
+	if x1 > x0 {
+		sx = (rect[2] - rect[0]) / (x1 - x0)
+	}
+	if y1 > y0 {
+		sy = (rect[3] - rect[1]) / (y1 - y0)
+	}
+	return fmt.Sprintf("%.4f 0 0 %.4f %.4f %.4f", sx, sy, rect[0]-sx*x0, rect[1]-sy*y0)
+}
+
+// normalizeRect returns a rectangle [llx lly urx ury] with ordered corners.
+func normalizeRect(r []float64) [4]float64 {
+	if len(r) < 4 {
+		return [4]float64{}
+	}
+	out := [4]float64{r[0], r[1], r[2], r[3]}
+	if out[0] > out[2] {
+		out[0], out[2] = out[2], out[0]
+	}
+	if out[1] > out[3] {
+		out[1], out[3] = out[3], out[1]
+	}
+	return out
+}
@@ -0,0 +1,633 @@
+package gopdf
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ============================================================
+// Widget appearance streams for filled AcroForm fields
+// ============================================================
+
+// listBoxHighlight is the fill color of selected list box rows.
+const listBoxHighlight = "0.6 0.757 0.855 rg"
+
+// updateAppearance regenerates the normal appearance of a widget from the
+// current value of its field.
+func (f *FormFiller) updateAppearance(field *acroField, widget int) {
+	typ := f.fieldType(field)
+	switch typ {
+	case FormFieldCheckbox, FormFieldRadio:
+		f.buttonAppearance(field, widget)
+		return
+	case FormFieldText, FormFieldChoice:
+	default:
+		return
+	}
+	wd := f.store.dict(widget)
+	rect := normalizeRect(pdfNumbers(f.store.resolve(pdfDictGet(wd, "/Rect"))))
+	w, h := rect[2]-rect[0], rect[3]-rect[1]
+	if w <= 0 || h <= 0 {
+		return
+	}
+	mk := f.store.resolveDict(pdfDictGet(wd, "/MK"))
+	matrix, lw, lh := widgetRotation(mk, w, h)
+
+	var buf strings.Builder
+	bw := f.drawWidgetFrame(&buf, wd, mk, lw, lh)
+
+	da := f.inherited(widget, "/DA")
+	if da == "" {
+		da = pdfDictGet(f.acroForm(), "/DA")
+	}
+	fontName, size, color := parseDA(decodePDFTextString(f.store.resolve(da)))
+	font := f.fieldFont(fontName)
+	q, _ := strconv.Atoi(f.inherited(widget, "/Q"))
+	if f.inherited(widget, "/Q") == "" {
+		q, _ = strconv.Atoi(pdfDictGet(f.acroForm(), "/Q"))
+	}
+	ff := f.flags(field)
+	value := ""
+	if vals := f.values(field, "/V"); len(vals) > 0 {
+		value = vals[0]
+	}
+
+	buf.WriteString("/Tx BMC\nq\n")
+	fmt.Fprintf(&buf, "%.2f %.2f %.2f %.2f re W n\n", bw, bw, lw-2*bw, lh-2*bw)
+	layout := fieldTextLayout{buf: &buf, w: lw, h: lh, bw: bw, size: size, color: color, q: q}
+	switch {
+	case typ == FormFieldChoice && ff&formFlagCombo == 0:
+		if layout.size == 0 {
+			layout.size = 12
+		}
+		layout.font = font
+		f.drawListBox(&layout, field)
+	default:
+		if typ == FormFieldChoice {
+			exports, labels := f.choiceOptions(field)
+			for i := range exports {
+				if exports[i] == value {
+					value = labels[i]
+					break
+				}
+			}
+		}
+		if ff&formFlagPassword != 0 {
+			value = strings.Repeat("*", len([]rune(value)))
+		}
+		if font.composite() && !font.encodable(value) {
+			font = f.fieldFont("Helv\x00")
+		}
+		layout.font = font
+		maxLen, _ := strconv.Atoi(f.store.resolve(f.inherited(field.num, "/MaxLen")))
+		switch {
+		case typ == FormFieldText && ff&formFlagMultiline != 0:
+			layout.multiline(value)
+		case typ == FormFieldText && ff&formFlagComb != 0 && ff&formFlagPassword == 0 && maxLen > 0:
+			layout.comb(value, maxLen)
+		default:
+			layout.singleLine(value)
+		}
+	}
+	buf.WriteString("Q\nEMC\n")
+
+	res := fmt.Sprintf("<< /Font << %s %s >> >>", encodePDFName(layout.font.name), layout.font.ref)
+	dict := fmt.Sprintf("<< /Type /XObject /Subtype /Form /BBox [0 0 %.2f %.2f] /Matrix [%s] /Resources %s >>",
+		lw, lh, matrix, res)
+	ap := f.store.addStream(dict, []byte(buf.String()))
+	f.store.setDict(widget, pdfDictSet(f.store.dict(widget), "/AP", fmt.Sprintf("<< /N %d 0 R >>", ap)))
+}
+
+// buttonAppearance adds on and off appearances to a checkbox or radio
+// button widget that has none.
+func (f *FormFiller) buttonAppearance(field *acroField, widget int) {
+	wd := f.store.dict(widget)
+	ap := f.store.resolveDict(pdfDictGet(wd, "/AP"))
+	if len(parsePDFDict(f.store.resolveDict(pdfDictGet(ap, "/N")))) > 0 {
+		return
+	}
+	rect := normalizeRect(pdfNumbers(f.store.resolve(pdfDictGet(wd, "/Rect"))))
+	w, h := rect[2]-rect[0], rect[3]-rect[1]
+	if w <= 0 || h <= 0 {
+		return
+	}
+	mk := f.store.resolveDict(pdfDictGet(wd, "/MK"))
+	matrix, lw, lh := widgetRotation(mk, w, h)
+
+	glyph := decodePDFTextString(f.store.resolve(pdfDictGet(mk, "/CA")))
+	if glyph == "" {
+		glyph = "4" // check mark
+		if f.fieldType(field) == FormFieldRadio {
+			glyph = "l" // filled circle
+		}
+	}
+	_, size, color := parseDA(decodePDFTextString(f.store.resolve(f.inherited(widget, "/DA"))))
+	var off strings.Builder
+	bw := f.drawWidgetFrame(&off, wd, mk, lw, lh)
+	if size == 0 {
+		size = (min(lw, lh) - 2*bw) * 0.8
+	}
+	font := f.fieldFont("ZaDb\x00")
+	gw := 0.8 * size
+	on := off.String() + fmt.Sprintf("q\nBT\n/ZaDb %.2f Tf\n%s\n%.2f %.2f Td\n%s\nET\nQ\n",
+		size, color, (lw-gw)/2, (lh-0.7*size)/2, font.show(glyph))
+
+	dict := fmt.Sprintf("<< /Type /XObject /Subtype /Form /BBox [0 0 %.2f %.2f] /Matrix [%s] /Resources << /Font << /ZaDb %s >> >> >>",
+		lw, lh, matrix, font.ref)
+	onNum := f.store.addStream(dict, []byte(on))
+	offNum := f.store.addStream(dict, []byte(off.String()))
+	state := f.widgetStates(field, widget)[0]
+	f.store.setDict(widget, pdfDictSet(wd, "/AP",
+		fmt.Sprintf("<< /N << %s %d 0 R /Off %d 0 R >> >>", encodePDFName(state), onNum, offNum)))
+}
+
+// widgetStates returns the on-state names of a widget, falling back to the
+// state of its field for widgets without appearances.
+func (f *FormFiller) widgetStates(field *acroField, widget int) []string {
+	if states := f.onStates(widget); len(states) > 0 {
+		return states
+	}
+	return f.buttonStates(field)[:1]
+}
+
+// widgetRotation returns the appearance matrix for the /MK /R rotation of a
+// widget of size w×h, and the width and height of the rotated layout box.
+func widgetRotation(mk string, w, h float64) (string, float64, float64) {
+	r, _ := strconv.Atoi(pdfDictGet(mk, "/R"))
+	switch ((r % 360) + 360) % 360 {
+	case 90:
+		return fmt.Sprintf("0 1 -1 0 %.2f 0", w), h, w
+	case 180:
+		return fmt.Sprintf("-1 0 0 -1 %.2f %.2f", w, h), w, h
+	case 270:
+		return fmt.Sprintf("0 -1 1 0 0 %.2f", h), h, w
+	}
+	return "1 0 0 1 0 0", w, h
+}
+
+// drawWidgetFrame draws the /MK background and border of a widget and
+// returns the border width.
+func (f *FormFiller) drawWidgetFrame(buf *strings.Builder, wd, mk string, w, h float64) float64 {
+	if bg := pdfColorOp(pdfNumbers(pdfDictGet(mk, "/BG")), false); bg != "" {
+		fmt.Fprintf(buf, "%s\n0 0 %.2f %.2f re f\n", bg, w, h)
+	}
+	bc := pdfColorOp(pdfNumbers(pdfDictGet(mk, "/BC")), true)
+	if bc == "" {
+		return 0
+	}
+	bs := f.store.resolveDict(pdfDictGet(wd, "/BS"))
+	bw := 1.0
+	if v := pdfDictGet(bs, "/W"); v != "" {
+		bw, _ = strconv.ParseFloat(v, 64)
+	} else if border := pdfNumbers(pdfDictGet(wd, "/Border")); len(border) >= 3 {
+		bw = border[2]
+	}
+	if bw <= 0 {
+		return 0
+	}
+	fmt.Fprintf(buf, "%s\n%.2f w\n", bc, bw)
+	switch pdfDictGet(bs, "/S") {
+	case "/D":
+		dash := pdfNumbers(pdfDictGet(bs, "/D"))
+		if len(dash) == 0 {
+			dash = []float64{3}
+		}
+		fmt.Fprintf(buf, "%s d\n", formatDashPattern(dash))
+	case "/U":
+		fmt.Fprintf(buf, "0 %.2f m %.2f %.2f l S\n", bw/2, w, bw/2)
+		return bw
+	}
+	fmt.Fprintf(buf, "%.2f %.2f %.2f %.2f re S\n", bw/2, bw/2, w-bw, h-bw)
+	if pdfDictGet(bs, "/S") == "/D" {
+		buf.WriteString("[] 0 d\n")
+	}
+	return bw
+}
+
+// pdfColorOp returns the color operator for a gray, RGB or CMYK color array.
+func pdfColorOp(c []float64, stroke bool) string {
+	ops := map[int]string{1: "g", 3: "rg", 4: "k"}
+	op, ok := ops[len(c)]
+	if !ok {
+		return ""
+	}
+	if stroke {
+		op = strings.ToUpper(op)
+	}
+	parts := make([]string, len(c))
+	for i, v := range c {
+		parts[i] = strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	return strings.Join(parts, " ") + " " + op
+}
+
+// parseDA returns the font resource name, font size and color operator of
+// a default appearance string such as "/Helv 0 Tf 0 g".
+func parseDA(da string) (font string, size float64, color string) {
+	var tokens []string
+	for i := skipPDFSpace(da, 0); i < len(da); i = skipPDFSpace(da, i) {
+		end := scanPDFValue(da, i)
+		if end <= i {
+			end = i + 1
+		}
+		tokens = append(tokens, da[i:end])
+		i = end
+	}
+	color = "0 g"
+	operands := map[string]int{"g": 1, "rg": 3, "k": 4}
+	for i, tok := range tokens {
+		if tok == "Tf" && i >= 2 {
+			font = pdfNameValue(tokens[i-2])
+			size, _ = strconv.ParseFloat(tokens[i-1], 64)
+		}
+		if n, ok := operands[tok]; ok && i >= n {
+			color = strings.Join(tokens[i-n:i+1], " ")
+		}
+	}
+	if font == "" {
+		font = "Helv"
+	}
+	return font, size, color
+}
+
+// fieldTextLayout lays out the text of a text or choice field inside the
+// widget box w×h with border width bw.
+type fieldTextLayout struct {
+	buf   *strings.Builder
+	font  *formFont
+	w, h  float64
+	bw    float64
+	size  float64 // 0 = auto
+	color string
+	q     int // 0 left, 1 centered, 2 right
+}
+
+const fieldTextPadding = 2.0
+
+func (l *fieldTextLayout) x(width float64) float64 {
+	switch l.q {
+	case 1:
+		return (l.w - width) / 2
+	case 2:
+		return l.w - l.bw - fieldTextPadding - width
+	}
+	return l.bw + fieldTextPadding
+}
+
+func (l *fieldTextLayout) begin() {
+	fmt.Fprintf(l.buf, "BT\n%s %.2f Tf\n%s\n", encodePDFName(l.font.name), l.size, l.color)
+}
+
+func (l *fieldTextLayout) singleLine(text string) {
+	inner := l.h - 2*l.bw
+	if l.size == 0 {
+		l.size = min(12, (inner-2)/l.font.lineHeight())
+		if tw := l.font.width(text, l.size); tw > l.w-2*(l.bw+fieldTextPadding) && tw > 0 {
+			l.size *= (l.w - 2*(l.bw+fieldTextPadding)) / tw
+		}
+		l.size = max(l.size, 4)
+	}
+	y := (l.h-l.font.lineHeight()*l.size)/2 - l.font.descent*l.size/1000
+	l.begin()
+	fmt.Fprintf(l.buf, "%.2f %.2f Td\n%s\nET\n", l.x(l.font.width(text, l.size)), y, l.font.show(text))
+}
+
+func (l *fieldTextLayout) multiline(text string) {
+	maxW := l.w - 2*(l.bw+fieldTextPadding)
+	wrap := func() []string {
+		return wrapAnnotationText(text, maxW, func(s string) float64 { return l.font.width(s, l.size) })
+	}
+	if l.size == 0 {
+		l.size = 12
+		for l.size > 4 && float64(len(wrap()))*l.font.lineHeight()*l.size > l.h-2*(l.bw+fieldTextPadding) {
+			l.size -= 0.5
+		}
+	}
+	lead := l.font.lineHeight() * l.size
+	y := l.h - l.bw - fieldTextPadding - l.font.ascent*l.size/1000
+	l.begin()
+	for _, line := range wrap() {
+		fmt.Fprintf(l.buf, "1 0 0 1 %.2f %.2f Tm\n%s\n", l.x(l.font.width(line, l.size)), y, l.font.show(line))
+		y -= lead
+	}
+	l.buf.WriteString("ET\n")
+}
+
+// comb draws each character centered in one of maxLen equal cells.
+func (l *fieldTextLayout) comb(text string, maxLen int) {
+	cell := l.w / float64(maxLen)
+	if l.size == 0 {
+		l.size = max(4, min(12, (l.h-2*l.bw-2)/l.font.lineHeight()))
+	}
+	y := (l.h-l.font.lineHeight()*l.size)/2 - l.font.descent*l.size/1000
+	l.begin()
+	for i, r := range []rune(text) {
+		c := string(r)
+		x := float64(i)*cell + (cell-l.font.width(c, l.size))/2
+		fmt.Fprintf(l.buf, "1 0 0 1 %.2f %.2f Tm\n%s\n", x, y, l.font.show(c))
+	}
+	l.buf.WriteString("ET\n")
+}
+
+// drawListBox draws the options of a list box from the top index /TI with
+// the selected rows highlighted.
+func (f *FormFiller) drawListBox(l *fieldTextLayout, field *acroField) {
+	exports, labels := f.choiceOptions(field)
+	selected := make(map[int]bool)
+	for _, v := range pdfNumbers(f.store.resolve(pdfDictGet(f.store.dict(field.num), "/I"))) {
+		selected[int(v)] = true
+	}
+	if len(selected) == 0 {
+		for _, v := range f.values(field, "/V") {
+			for i := range exports {
+				if exports[i] == v {
+					selected[i] = true
+				}
+			}
+		}
+	}
+	top, _ := strconv.Atoi(f.store.resolve(f.inherited(field.num, "/TI")))
+	l.listBox(labels, selected, top)
+}
+
+// listBox draws the labels from index top with the selected rows
+// highlighted.
+func (l *fieldTextLayout) listBox(labels []string, selected map[int]bool, top int) {
+	lead := l.font.lineHeight() * l.size
+	var rows []int
+	for i, y := top, l.h-l.bw; i < len(labels) && y > l.bw; i, y = i+1, y-lead {
+		rows = append(rows, i)
+	}
+	for n, i := range rows {
+		if selected[i] {
+			fmt.Fprintf(l.buf, "%s\n%.2f %.2f %.2f %.2f re f\n",
+				listBoxHighlight, l.bw, l.h-l.bw-float64(n+1)*lead, l.w-2*l.bw, lead)
+		}
+	}
+	l.begin()
+	for n, i := range rows {
+		y := l.h - l.bw - float64(n)*lead - l.font.ascent*l.size/1000
+		fmt.Fprintf(l.buf, "1 0 0 1 %.2f %.2f Tm\n%s\n", l.x(l.font.width(labels[i], l.size)), y, l.font.show(labels[i]))
+	}
+	l.buf.WriteString("ET\n")
+}
+
+// ============================================================
+// Fonts named by default appearance strings
+// ============================================================
+
+// formFont is a font from the form's default resources (/DR), or a
+// standard font added for resource names that are not defined there.
+type formFont struct {
+	name string // resource name
+	ref  string // reference or dictionary for the resources
+
+	// simple fonts
+	firstChar int
+	widths    []float64
+	std       *standardFont // standard metrics when /Widths is missing
+
+	// composite fonts
+	codes     map[rune]uint16
+	cidWidths map[uint16]float64
+	dw        float64
+
+	ascent, descent float64 // in 1/1000 em
+}
+
+func (ft *formFont) composite() bool {
+	return ft.codes != nil
+}
+
+func (ft *formFont) lineHeight() float64 {
+	return (ft.ascent - ft.descent) / 1000
+}
+
+// encodable reports whether every character of s has a code in the font.
+func (ft *formFont) encodable(s string) bool {
+	for _, r := range s {
+		if ft.composite() {
+			if _, ok := ft.codes[r]; !ok {
+				return false
+			}
+		} else if _, ok := winAnsiByte(r); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+func (ft *formFont) width(s string, size float64) float64 {
+	total := 0.0
+	for _, r := range s {
+		if ft.composite() {
+			w, ok := ft.cidWidths[ft.codes[r]]
+			if !ok {
+				w = ft.dw
+			}
+			total += w
+			continue
+		}
+		b, _ := winAnsiByte(r)
+		switch {
+		case ft.widths != nil && int(b) >= ft.firstChar && int(b)-ft.firstChar < len(ft.widths):
+			total += ft.widths[int(b)-ft.firstChar]
+		case ft.std != nil && ft.std.widths[b] != 0:
+			total += float64(ft.std.widths[b])
+		default:
+			total += 556
+		}
+	}
+	return total * size / 1000
+}
+
+// show returns the text showing operator for s.
+func (ft *formFont) show(s string) string {
+	var sb strings.Builder
+	if ft.composite() {
+		sb.WriteString("<")
+		for _, r := range s {
+			if code, ok := ft.codes[r]; ok {
+				fmt.Fprintf(&sb, "%04X", code)
+			}
+		}
+		sb.WriteString("> Tj")
+		return sb.String()
+	}
+	sb.WriteString("(")
+	for _, r := range s {
+		b, _ := winAnsiByte(r)
+		switch {
+		case b == '(' || b == ')' || b == '\\':
+			sb.WriteByte('\\')
+			sb.WriteByte(b)
+		case b >= 32 && b <= 126:
+			sb.WriteByte(b)
+		default:
+			fmt.Fprintf(&sb, "\\%03o", b)
+		}
+	}
+	sb.WriteString(") Tj")
+	return sb.String()
+}
+
+// fieldFont returns the font for a resource name of a /DA string. Names
+// ending in NUL select a standard font without consulting /DR.
+func (f *FormFiller) fieldFont(name string) *formFont {
+	if font, ok := f.fonts[name]; ok {
+		return font
+	}
+	font := f.loadFieldFont(name)
+	f.fonts[name] = font
+	return font
+}
+
+func (f *FormFiller) loadFieldFont(name string) *formFont {
+	if base, ok := strings.CutSuffix(name, "\x00"); ok {
+		return f.standardFieldFont(base, "")
+	}
+	dr := f.store.resolveDict(pdfDictGet(f.acroForm(), "/DR"))
+	ref := pdfDictGet(f.store.resolveDict(pdfDictGet(dr, "/Font")), encodePDFName(name))
+	fd := f.store.resolveDict(ref)
+	switch pdfDictGet(fd, "/Subtype") {
+	case "/Type0":
+		if font := f.compositeFieldFont(name, ref, fd); font != nil {
+			return font
+		}
+	case "/Type1", "/TrueType", "/MMType1":
+		font := standardFontMetrics(name, pdfNameValue(pdfDictGet(fd, "/BaseFont")))
+		font.ref = ref
+		font.firstChar, _ = strconv.Atoi(f.store.resolve(pdfDictGet(fd, "/FirstChar")))
+		font.widths = pdfNumbers(f.store.resolve(pdfDictGet(fd, "/Widths")))
+		if len(font.widths) == 0 {
+			font.widths = nil
+		}
+		f.fontMetrics(font, pdfDictGet(fd, "/FontDescriptor"))
+		return font
+	}
+	return f.standardFieldFont(name, "")
+}
+
+// standardFieldFont returns a standard 14 font with WinAnsi encoding. The
+// base font is derived from the resource name when it is empty.
+func (f *FormFiller) standardFieldFont(name, base string) *formFont {
+	if base == "" {
+		switch name {
+		case "HeBo":
+			base = "Helvetica-Bold"
+		case "Cour":
+			base = "Courier"
+		case "TiRo":
+			base = "Times-Roman"
+		case "ZaDb":
+			base = "ZapfDingbats"
+		default:
+			base = "Helvetica"
+		}
+	}
+	font := standardFontMetrics(name, base)
+	enc := " /Encoding /WinAnsiEncoding"
+	if base == "ZapfDingbats" || base == "Symbol" {
+		enc = ""
+	}
+	font.ref = fmt.Sprintf("%d 0 R", f.store.add(fmt.Sprintf(
+		"<< /Type /Font /Subtype /Type1 /BaseFont /%s%s >>", base, enc)))
+	return font
+}
+
+// standardFontMetrics returns the metrics of a simple font approximated by
+// Helvetica, Helvetica-Bold or Courier.
+func standardFontMetrics(name, base string) *formFont {
+	std := "Helvetica"
+	switch {
+	case strings.Contains(base, "Courier"):
+		std = "Courier"
+	case strings.Contains(base, "Bold"):
+		std = "Helvetica-Bold"
+	}
+	f := newStandardFont(std)
+	return &formFont{name: name, std: f, ascent: float64(f.ascent), descent: float64(f.descent)}
+}
+
+// compositeFieldFont loads a Type0 font with 2-byte codes. Text is encoded
+// through the inverse of its /ToUnicode map; fonts without one are not
+// supported.
+func (f *FormFiller) compositeFieldFont(name, ref, fd string) *formFont {
+	num, ok := pdfRef(pdfDictGet(fd, "/ToUnicode"))
+	if !ok {
+		return nil
+	}
+	obj, ok := f.store.parser.objects[num]
+	if !ok || obj.stream == nil {
+		return nil
+	}
+	font := &formFont{name: name, ref: ref, codes: make(map[rune]uint16),
+		cidWidths: make(map[uint16]float64), dw: 1000, ascent: 718, descent: -207}
+	for code, r := range parseCMap(obj.stream) {
+		if old, dup := font.codes[r]; !dup || code < old {
+			font.codes[r] = code
+		}
+	}
+	desc := parsePDFArray(f.store.resolve(pdfDictGet(fd, "/DescendantFonts")))
+	if len(desc) == 0 {
+		return font
+	}
+	cid := f.store.resolveDict(desc[0])
+	if dw, err := strconv.ParseFloat(pdfDictGet(cid, "/DW"), 64); err == nil {
+		font.dw = dw
+	}
+	items := parsePDFArray(f.store.resolve(pdfDictGet(cid, "/W")))
+	for i := 0; i+1 < len(items); {
+		first, _ := strconv.Atoi(items[i])
+		if arr := parsePDFArray(f.store.resolve(items[i+1])); strings.HasPrefix(items[i+1], "[") {
+			for j, w := range arr {
+				font.cidWidths[uint16(first+j)], _ = strconv.ParseFloat(w, 64)
+			}
+			i += 2
+			continue
+		}
+		if i+2 >= len(items) {
+			break
+		}
+		last, _ := strconv.Atoi(items[i+1])
+		w, _ := strconv.ParseFloat(items[i+2], 64)
+		for c := first; c <= last && c-first < 65536; c++ {
+			font.cidWidths[uint16(c)] = w
+		}
+		i += 3
+	}
+	f.fontMetrics(font, pdfDictGet(cid, "/FontDescriptor"))
+	return font
+}
+
+// fontMetrics reads the ascent and descent of a font descriptor.
+func (f *FormFiller) fontMetrics(font *formFont, descriptor string) {
+	fd := f.store.resolveDict(descriptor)
+	if a, err := strconv.ParseFloat(f.store.resolve(pdfDictGet(fd, "/Ascent")), 64); err == nil && a > 0 {
+		font.ascent = a
+	}
+	if d, err := strconv.ParseFloat(f.store.resolve(pdfDictGet(fd, "/Descent")), 64); err == nil && d < 0 {
+		font.descent = d
+	}
+}
+
+// winAnsiSpecials maps the characters of WinAnsiEncoding 0x80–0x9F.
+var winAnsiSpecials = map[rune]byte{
+	'€': 0x80, '‚': 0x82, 'ƒ': 0x83, '„': 0x84, '…': 0x85, '†': 0x86, '‡': 0x87,
+	'ˆ': 0x88, '‰': 0x89, 'Š': 0x8A, '‹': 0x8B, 'Œ': 0x8C, 'Ž': 0x8E,
+	'‘': 0x91, '’': 0x92, '“': 0x93, '”': 0x94, '•': 0x95, '–': 0x96, '—': 0x97,
+	'˜': 0x98, '™': 0x99, 'š': 0x9A, '›': 0x9B, 'œ': 0x9C, 'ž': 0x9E, 'Ÿ': 0x9F,
+}
+
+// winAnsiByte returns the WinAnsiEncoding code of r, or '?' and false.
+func winAnsiByte(r rune) (byte, bool) {
+	switch {
+	case r >= 32 && r <= 126, r >= 0xA0 && r <= 0xFF:
+		return byte(r), true
+	}
+	if b, ok := winAnsiSpecials[r]; ok {
+		return b, true
+	}
+	return '?', false
+}
@@ -10,6 +10,7 @@ type acroFormObj struct {
 	fieldObjIDs []int  // 1-based object IDs of form field objects
 	fontRefs    []acroFormFont // fonts used in form fields
 	needAppearances bool
+	calcObjIDs      []int // 1-based object IDs of calculated fields in calculation order
 }
 
 type acroFormFont struct {
@@ -34,16 +35,25 @@ func (a acroFormObj) write(w io.Writer, objID int) error {
 	io.WriteString(w, "]\n")
 
 	// NeedAppearances — tells viewers to generate appearances
-	io.WriteString(w, "/NeedAppearances true\n")
+	if a.needAppearances {
+		io.WriteString(w, "/NeedAppearances true\n")
+	}
 
-	// Default resources with fonts
-	if len(a.fontRefs) > 0 {
-		io.WriteString(w, "/DR << /Font <<")
-		for _, f := range a.fontRefs {
-			fmt.Fprintf(w, " /%s %d 0 R", f.name, f.objID)
+	// Calculation order
+	if len(a.calcObjIDs) > 0 {
+		io.WriteString(w, "/CO [")
+		for _, id := range a.calcObjIDs {
+			fmt.Fprintf(w, "%d 0 R ", id)
 		}
-		io.WriteString(w, " >> >>\n")
+		io.WriteString(w, "]\n")
+	}
+
+	// Default resources with fonts
+	io.WriteString(w, "/DR << /Font << /Helv "+helvFontDict+" /ZaDb "+zaDbFontDict)
+	for _, f := range a.fontRefs {
+		fmt.Fprintf(w, " /%s %d 0 R", f.name, f.objID)
 	}
+	io.WriteString(w, " >> >>\n")
 
 	// Default appearance
 	io.WriteString(w, "/DA (/Helv 12 Tf 0 0 0 rg)\n")
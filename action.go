@@ -0,0 +1,517 @@
+package gopdf
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf16"
+)
+
+// ============================================================
+// Actions — links, outline items, form fields, page and
+// document triggers (PDF 32000-1:2008, 12.6)
+// ============================================================
+
+// ActionType is the type of an action.
+type ActionType int
+
+const (
+	// ActionGoTo goes to a destination in the document.
+	ActionGoTo ActionType = iota + 1
+	// ActionGoToR goes to a destination in another PDF file.
+	ActionGoToR
+	// ActionGoToE goes to a destination in an embedded PDF file.
+	ActionGoToE
+	// ActionLaunch opens a file with its application.
+	ActionLaunch
+	// ActionURI opens a URI.
+	ActionURI
+	// ActionNamed runs a viewer command such as NextPage or Print.
+	ActionNamed
+	// ActionJavaScript runs a script.
+	ActionJavaScript
+	// ActionSubmitForm sends field values to a URL.
+	ActionSubmitForm
+	// ActionResetForm resets fields to their default values.
+	ActionResetForm
+	// ActionSetOCGState turns layers on or off.
+	ActionSetOCGState
+	// ActionHide hides or shows form fields.
+	ActionHide
+)
+
+// DestinationFit is how a destination shows its page.
+type DestinationFit int
+
+const (
+	// DestXYZ shows the page with Left, Top at the upper-left corner of
+	// the window and magnification Zoom.
+	DestXYZ DestinationFit = iota
+	// DestFit fits the whole page in the window.
+	DestFit
+	// DestFitH fits the page width with Top at the top of the window.
+	DestFitH
+	// DestFitV fits the page height with Left at the left of the window.
+	DestFitV
+	// DestFitR fits the rectangle Left, Top, Right, Bottom in the window.
+	DestFitR
+	// DestFitB fits the bounding box of the page contents in the window.
+	DestFitB
+	// DestFitBH fits the width of the bounding box with Top at the top.
+	DestFitBH
+	// DestFitBV fits the height of the bounding box with Left at the left.
+	DestFitBV
+)
+
+// Destination is a view of a page.
+type Destination struct {
+	// Page is the 1-based page number.
+	Page int
+	// Name is a named destination, used instead of Page. Within the
+	// document an anchor set with SetAnchor may be named.
+	Name string
+	// Fit is how the page is shown.
+	Fit DestinationFit
+	// Left, Top, Right and Bottom are the coordinates used by Fit. In the
+	// document they are in document units from the top-left page corner;
+	// in other files (GoToR, GoToE) they are in points from the
+	// lower-left corner, since the target page height is unknown.
+	Left, Top, Right, Bottom float64
+	// Zoom is the magnification of DestXYZ, e.g. 1.5 for 150%. Zero
+	// keeps the current magnification.
+	Zoom float64
+}
+
+// NamedAction is a viewer command run by an ActionNamed action.
+type NamedAction string
+
+const (
+	NamedActionNextPage   NamedAction = "NextPage"
+	NamedActionPrevPage   NamedAction = "PrevPage"
+	NamedActionFirstPage  NamedAction = "FirstPage"
+	NamedActionLastPage   NamedAction = "LastPage"
+	NamedActionPrint      NamedAction = "Print"
+	NamedActionGoBack     NamedAction = "GoBack"
+	NamedActionFullScreen NamedAction = "FullScreen"
+	NamedActionFind       NamedAction = "Find"
+)
+
+// OCGStateChange is how an ActionSetOCGState action changes a layer.
+type OCGStateChange string
+
+const (
+	OCGTurnOn  OCGStateChange = "ON"
+	OCGTurnOff OCGStateChange = "OFF"
+	OCGToggle  OCGStateChange = "Toggle"
+)
+
+// OCGStateItem changes the state of the layer added with AddOCG under
+// Name.
+type OCGStateItem struct {
+	Name   string
+	Change OCGStateChange
+}
+
+// Action is an action run by a link, an outline item, a form field, a
+// page or the document. Only the fields of its Type are used.
+//
+// Example:
+//
+//	pdf.AddActionLink(gopdf.Action{
+//	    Type: gopdf.ActionGoToR,
+//	    File: "appendix.pdf",
+//	    Dest: gopdf.Destination{Page: 3, Fit: gopdf.DestFit},
+//	    Next: []gopdf.Action{{Type: gopdf.ActionNamed, Named: gopdf.NamedActionPrint}},
+//	}, 50, 100, 120, 20)
+type Action struct {
+	// Type is the action type.
+	Type ActionType
+	// Dest is the destination of GoTo, GoToR and GoToE.
+	Dest Destination
+	// File is the file of GoToR and Launch, or the file containing the
+	// embedded file of GoToE (the document itself when empty).
+	File string
+	// Embedded is the name of the embedded file of GoToE.
+	Embedded string
+	// NewWindow opens the target of GoToR, GoToE and Launch in a new window.
+	NewWindow bool
+	// URI is the target of URI and SubmitForm. Relative URIs are resolved
+	// against the base set with SetBaseURI.
+	URI string
+	// Named is the command of Named.
+	Named NamedAction
+	// JavaScript is the script of JavaScript.
+	JavaScript string
+	// Fields are the fields of SubmitForm, ResetForm and Hide. SubmitForm
+	// and ResetForm use all fields when empty.
+	Fields []string
+	// ExcludeFields makes SubmitForm and ResetForm use all fields except
+	// Fields.
+	ExcludeFields bool
+	// Format is the data format of SubmitForm.
+	Format SubmitFormat
+	// OCGStates are the layer changes of SetOCGState.
+	OCGStates []OCGStateItem
+	// Show makes Hide show the fields instead of hiding them.
+	Show bool
+	// Next are the actions run after this one.
+	Next []Action
+}
+
+// actionToPoints converts the destination coordinates of a GoTo action and
+// its Next chain from document units to points.
+func (gp *GoPdf) actionToPoints(a Action) Action {
+	return mapActionDests(a, gp.destToPoints)
+}
+
+// actionToUnits converts the destination coordinates of a GoTo action and
+// its Next chain from points to document units.
+func (gp *GoPdf) actionToUnits(a Action) Action {
+	return mapActionDests(a, gp.destToUnits)
+}
+
+func mapActionDests(a Action, f func(Destination) Destination) Action {
+	if a.Type == ActionGoTo {
+		a.Dest = f(a.Dest)
+	}
+	if len(a.Next) > 0 {
+		next := make([]Action, len(a.Next))
+		for i := range a.Next {
+			next[i] = mapActionDests(a.Next[i], f)
+		}
+		a.Next = next
+	}
+	return a
+}
+
+// actionWriter writes the action dictionaries of the object objID,
+// encrypting their strings when the document is protected.
+type actionWriter struct {
+	gp    *GoPdf
+	objID int
+}
+
+// str returns s as a literal text string.
+func (aw actionWriter) str(s string) string {
+	b := []byte(s)
+	for _, r := range s {
+		if r > 126 {
+			b = []byte{0xFE, 0xFF}
+			for _, c := range utf16.Encode([]rune(s)) {
+				b = append(b, byte(c>>8), byte(c))
+			}
+			break
+		}
+	}
+	if p := aw.gp.protection(); p != nil {
+		if tmp, err := rc4Cip(p.objectkey(aw.objID), b); err == nil {
+			b = tmp
+		}
+	}
+	return "(" + escapeAnnotString(string(b)) + ")"
+}
+
+// dict returns the action dictionary of a, or "" if a GoTo action has no
+// target.
+func (aw actionWriter) dict(a Action) string {
+	var sb strings.Builder
+	sb.WriteString("<< ")
+	switch a.Type {
+	case ActionGoTo:
+		d := aw.dest(a.Dest, true)
+		if d == "" {
+			return ""
+		}
+		sb.WriteString("/S /GoTo /D " + d)
+	case ActionGoToR:
+		fmt.Fprintf(&sb, "/S /GoToR /F %s /D %s", aw.str(a.File), aw.dest(a.Dest, false))
+	case ActionGoToE:
+		sb.WriteString("/S /GoToE")
+		if a.File != "" {
+			sb.WriteString(" /F " + aw.str(a.File))
+		}
+		fmt.Fprintf(&sb, " /T << /R /C /N %s >> /D %s", aw.str(a.Embedded), aw.dest(a.Dest, false))
+	case ActionLaunch:
+		sb.WriteString("/S /Launch /F " + aw.str(a.File))
+	case ActionURI:
+		sb.WriteString("/S /URI /URI " + aw.str(a.URI))
+	case ActionNamed:
+		sb.WriteString("/S /Named /N " + encodePDFName(string(a.Named)))
+	case ActionJavaScript:
+		sb.WriteString("/S /JavaScript /JS " + aw.str(a.JavaScript))
+	case ActionSubmitForm:
+		flags := a.Format.flags()
+		if a.ExcludeFields {
+			flags |= 1
+		}
+		fmt.Fprintf(&sb, "/S /SubmitForm /F << /FS /URL /F %s >> /Flags %d", aw.str(a.URI), flags)
+		sb.WriteString(aw.fields(a.Fields))
+	case ActionResetForm:
+		sb.WriteString("/S /ResetForm")
+		sb.WriteString(aw.fields(a.Fields))
+		if a.ExcludeFields {
+			sb.WriteString(" /Flags 1")
+		}
+	case ActionSetOCGState:
+		sb.WriteString("/S /SetOCGState /State [")
+		for _, item := range a.OCGStates {
+			if id := aw.gp.ocgObjID(item.Name); id > 0 {
+				fmt.Fprintf(&sb, "/%s %d 0 R ", item.Change, id)
+			}
+		}
+		sb.WriteString("]")
+	case ActionHide:
+		sb.WriteString("/S /Hide /T [")
+		for _, name := range a.Fields {
+			sb.WriteString(aw.str(name) + " ")
+		}
+		sb.WriteString("]")
+		if a.Show {
+			sb.WriteString(" /H false")
+		}
+	default:
+		return ""
+	}
+	if a.NewWindow && (a.Type == ActionGoToR || a.Type == ActionGoToE || a.Type == ActionLaunch) {
+		sb.WriteString(" /NewWindow true")
+	}
+	var next []string
+	for _, n := range a.Next {
+		if d := aw.dict(n); d != "" {
+			next = append(next, d)
+		}
+	}
+	switch len(next) {
+	case 0:
+	case 1:
+		sb.WriteString(" /Next " + next[0])
+	default:
+		sb.WriteString(" /Next [" + strings.Join(next, " ") + "]")
+	}
+	sb.WriteString(" >>")
+	return sb.String()
+}
+
+// fields returns the /Fields entry of a form action.
+func (aw actionWriter) fields(names []string) string {
+	if len(names) == 0 {
+		return ""
+	}
+	var sb strings.Builder
+	sb.WriteString(" /Fields [")
+	for _, name := range names {
+		sb.WriteString(aw.str(name) + " ")
+	}
+	sb.WriteString("]")
+	return sb.String()
+}
+
+// dest returns a destination array or name. Local destinations refer to
+// page objects and convert the top-left coordinates to PDF user space;
+// remote destinations use zero-based page numbers.
+func (aw actionWriter) dest(d Destination, local bool) string {
+	gp := aw.gp
+	if d.Name != "" {
+		if a, ok := gp.anchors[d.Name]; ok && local {
+			return fmt.Sprintf("[%d 0 R /XYZ 0 %.2f null]", a.page+1, a.y)
+		}
+		return aw.str(d.Name)
+	}
+	left, top, right, bottom := d.Left, d.Top, d.Right, d.Bottom
+	page := fmt.Sprintf("%d", max(d.Page-1, 0))
+	if local {
+		id := gp.pageObjIDByNumber(d.Page)
+		if id == 0 {
+			return ""
+		}
+		page = fmt.Sprintf("%d 0 R", id)
+		pageH := gp.pageHeight(d.Page)
+		top, bottom = pageH-top, pageH-bottom
+	}
+	switch d.Fit {
+	case DestFit:
+		return fmt.Sprintf("[%s /Fit]", page)
+	case DestFitH:
+		return fmt.Sprintf("[%s /FitH %.2f]", page, top)
+	case DestFitV:
+		return fmt.Sprintf("[%s /FitV %.2f]", page, left)
+	case DestFitR:
+		return fmt.Sprintf("[%s /FitR %.2f %.2f %.2f %.2f]", page, left, bottom, right, top)
+	case DestFitB:
+		return fmt.Sprintf("[%s /FitB]", page)
+	case DestFitBH:
+		return fmt.Sprintf("[%s /FitBH %.2f]", page, top)
+	case DestFitBV:
+		return fmt.Sprintf("[%s /FitBV %.2f]", page, left)
+	}
+	zoom := "null"
+	if d.Zoom > 0 {
+		zoom = fmt.Sprintf("%.4g", d.Zoom)
+	}
+	return fmt.Sprintf("[%s /XYZ %.2f %.2f %s]", page, left, top, zoom)
+}
+
+// pageHeight returns the height of the n-th page (1-based) in points.
+func (gp *GoPdf) pageHeight(pageNo int) float64 {
+	if page := gp.findPageObj(pageNo); page != nil && !page.pageOption.isEmpty() {
+		return page.pageOption.PageSize.H
+	}
+	return gp.config.PageSize.H
+}
+
+// ocgObjID returns the object ID of the layer named name, or 0.
+func (gp *GoPdf) ocgObjID(name string) int {
+	for i, obj := range gp.pdfObjs {
+		if o, ok := obj.(ocgObj); ok && o.name == name {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+// FieldActions are the actions of a form field. The actions of a radio
+// group are run by each of its buttons.
+type FieldActions struct {
+	// Activate runs when the field is clicked (/A).
+	Activate *Action
+	// Enter and Exit run when the pointer enters and leaves the field.
+	Enter, Exit *Action
+	// Down and Up run when the mouse button is pressed and released.
+	Down, Up *Action
+	// Focus and Blur run when the field gains and loses the input focus.
+	Focus, Blur *Action
+}
+
+// AnnotationActions are the additional actions of an annotation (/AA).
+type AnnotationActions struct {
+	// Enter and Exit run when the pointer enters and leaves the annotation.
+	Enter, Exit *Action
+	// Down and Up run when the mouse button is pressed and released.
+	Down, Up *Action
+	// PageOpen and PageClose run when the page of the annotation is opened
+	// and closed.
+	PageOpen, PageClose *Action
+	// PageVisible and PageInvisible run when the page of the annotation
+	// becomes visible and invisible.
+	PageVisible, PageInvisible *Action
+}
+
+func (a AnnotationActions) entries() []aaEntry {
+	return []aaEntry{
+		{"/E", a.Enter}, {"/X", a.Exit}, {"/D", a.Down}, {"/U", a.Up},
+		{"/PO", a.PageOpen}, {"/PC", a.PageClose}, {"/PV", a.PageVisible}, {"/PI", a.PageInvisible},
+	}
+}
+
+// DocumentActions are the additional actions of the document (/AA of the
+// catalog), typically JavaScript actions.
+type DocumentActions struct {
+	// WillClose runs before the document is closed.
+	WillClose *Action
+	// WillSave and DidSave run before and after the document is saved.
+	WillSave, DidSave *Action
+	// WillPrint and DidPrint run before and after the document is printed.
+	WillPrint, DidPrint *Action
+}
+
+func (a DocumentActions) entries() []aaEntry {
+	return []aaEntry{
+		{"/WC", a.WillClose}, {"/WS", a.WillSave}, {"/DS", a.DidSave},
+		{"/WP", a.WillPrint}, {"/DP", a.DidPrint},
+	}
+}
+
+// aaEntry is an entry of an additional-actions dictionary.
+type aaEntry struct {
+	key    string
+	action *Action
+}
+
+// additionalActions returns the additional-actions dictionary of the set
+// entries, or "" if there are none.
+func (aw actionWriter) additionalActions(entries []aaEntry) string {
+	var parts []string
+	for _, e := range entries {
+		if e.action != nil {
+			if d := aw.dict(*e.action); d != "" {
+				parts = append(parts, e.key+" "+d)
+			}
+		}
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return "<< " + strings.Join(parts, " ") + " >>"
+}
+
+// actionsToPoints replaces the set actions with copies whose destinations
+// are converted from document units to points.
+func (gp *GoPdf) actionsToPoints(actions ...**Action) {
+	for _, a := range actions {
+		if *a != nil {
+			c := gp.actionToPoints(**a)
+			*a = &c
+		}
+	}
+}
+
+// ============================================================
+// Links, outline items, page and document actions
+// ============================================================
+
+// AddActionLink adds a link on the current page that runs an action.
+//
+// Example:
+//
+//	pdf.AddActionLink(gopdf.Action{Type: gopdf.ActionNamed, Named: gopdf.NamedActionNextPage},
+//	    500, 800, 60, 20)
+func (gp *GoPdf) AddActionLink(action Action, x, y, w, h float64) {
+	gp.UnitsToPointsVar(&x, &y, &w, &h)
+	action = gp.actionToPoints(action)
+	gp.addLink(linkOption{x: x, y: gp.config.PageSize.H - y, w: w, h: h, action: &action})
+}
+
+// AddOutlineWithAction adds an outline item on the current page that runs
+// an action instead of going to the page.
+//
+// Example:
+//
+//	pdf.AddOutlineWithAction("Website", gopdf.Action{Type: gopdf.ActionURI, URI: "https://example.com"})
+func (gp *GoPdf) AddOutlineWithAction(title string, action Action) *OutlineObj {
+	o := gp.AddOutlineWithPosition(title)
+	action = gp.actionToPoints(action)
+	o.action = &action
+	o.getRoot = func() *GoPdf { return gp }
+	return o
+}
+
+// SetPageOpenAction sets the action run when the current page is opened
+// (/AA /O).
+func (gp *GoPdf) SetPageOpenAction(action Action) {
+	action = gp.actionToPoints(action)
+	gp.pdfObjs[gp.curr.IndexOfPageObj].(*PageObj).openAction = &action
+}
+
+// SetPageCloseAction sets the action run when the current page is closed
+// (/AA /C).
+func (gp *GoPdf) SetPageCloseAction(action Action) {
+	action = gp.actionToPoints(action)
+	gp.pdfObjs[gp.curr.IndexOfPageObj].(*PageObj).closeAction = &action
+}
+
+// SetOpenAction sets the action run when the document is opened
+// (/OpenAction), e.g. a GoTo action to open at a page and zoom.
+//
+// Example:
+//
+//	pdf.SetOpenAction(gopdf.Action{Type: gopdf.ActionGoTo,
+//	    Dest: gopdf.Destination{Page: 2, Fit: gopdf.DestFitH}})
+func (gp *GoPdf) SetOpenAction(action Action) {
+	action = gp.actionToPoints(action)
+	gp.pdfObjs[gp.indexOfCatalogObj].(*CatalogObj).openAction = &action
+}
+
+// SetBaseURI sets the base URI that relative URIs of URI actions are
+// resolved against (/URI /Base).
+func (gp *GoPdf) SetBaseURI(base string) {
+	gp.pdfObjs[gp.indexOfCatalogObj].(*CatalogObj).baseURI = base
+}
@@ -19,7 +19,11 @@ func (o annotObj) getType() string {
 }
 
 func (o annotObj) write(w io.Writer, objID int) error {
-	if o.url != "" {
+	if o.action != nil {
+		return o.writeActionLink(w, o.linkOption, objID)
+	} else if o.dest != nil {
+		return o.writeDestLink(w, o.linkOption, objID)
+	} else if o.url != "" {
 		return o.writeExternalLink(w, o.linkOption, objID)
 	} else {
 		return o.writeInternalLink(w, o.linkOption)
@@ -55,3 +59,13 @@ func (o annotObj) writeInternalLink(w io.Writer, l linkOption) error {
 		l.x, l.y, l.x+l.w, l.y-l.h, a.page+1, a.y)
 	return err
 }
+
+func (o annotObj) writeActionLink(w io.Writer, l linkOption, objID int) error {
+	action := actionWriter{o.GetRoot(), objID}.dict(*l.action)
+	if action == "" {
+		return nil
+	}
+	_, err := fmt.Fprintf(w, "<</Type /Annot /Subtype /Link /Rect [%.2f %.2f %.2f %.2f] /Border [0 0 0] /A %s>>\n",
+		l.x, l.y, l.x+l.w, l.y-l.h, action)
+	return err
+}
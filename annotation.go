@@ -3,6 +3,7 @@ package gopdf
 import (
 	"fmt"
 	"io"
+	"math"
 	"strings"
 	"time"
 )
@@ -81,6 +82,17 @@ const (
 	LineEndSlash       LineEndingStyle = "Slash"
 )
 
+// AnnotationBorderStyle represents PDF annotation border styles (/BS /S).
+type AnnotationBorderStyle string
+
+const (
+	AnnotBorderSolid     AnnotationBorderStyle = "S"
+	AnnotBorderDashed    AnnotationBorderStyle = "D"
+	AnnotBorderBeveled   AnnotationBorderStyle = "B"
+	AnnotBorderInset     AnnotationBorderStyle = "I"
+	AnnotBorderUnderline AnnotationBorderStyle = "U"
+)
+
 // AnnotationOption configures a PDF annotation.
 type AnnotationOption struct {
 	// Type is the annotation type.
@@ -96,7 +108,8 @@ type AnnotationOption struct {
 	// Content is the annotation text content.
 	Content string
 
-	// Color is the annotation color in RGB. Default: yellow (255, 255, 0).
+	// Color is the annotation color in RGB. Default: yellow (255, 255, 0);
+	// stamps default to a color matching the stamp name.
 	Color [3]uint8
 
 	// Opacity is the annotation opacity (0.0 to 1.0). Default: 1.0.
@@ -111,6 +124,10 @@ type AnnotationOption struct {
 	// FontSize is used for FreeText annotations. Default: 12.
 	FontSize float64
 
+	// FontFamily is the family of a TTF font added with AddTTFFont used to
+	// lay out FreeText annotations. Default: Helvetica.
+	FontFamily string
+
 	// InkList contains stroke paths for Ink annotations.
 	// Each element is a slice of Points representing one stroke.
 	InkList [][]Point
@@ -135,6 +152,12 @@ type AnnotationOption struct {
 	// BorderWidth is the annotation border width. Default: 1.
 	BorderWidth float64
 
+	// BorderStyle is the border style. Default: solid.
+	BorderStyle AnnotationBorderStyle
+
+	// DashPattern is the dash array of the AnnotBorderDashed style. Default: [3].
+	DashPattern []float64
+
 	// FileName is the file name for FileAttachment annotations.
 	FileName string
 
@@ -143,9 +166,19 @@ type AnnotationOption struct {
 
 	// OverlayText is the text displayed over a Redact annotation area.
 	OverlayText string
+
+	// Actions are the additional actions of the annotation, e.g. scripts
+	// run when the pointer enters it or its page is opened.
+	Actions *AnnotationActions
 }
 
 func (o *AnnotationOption) defaults() {
+	if o.Stamp == "" {
+		o.Stamp = StampDraft
+	}
+	if o.Color == [3]uint8{0, 0, 0} && o.Type == AnnotStamp {
+		o.Color = stampColor(o.Stamp)
+	}
 	if o.Color == [3]uint8{0, 0, 0} {
 		o.Color = [3]uint8{255, 255, 0}
 	}
@@ -161,9 +194,62 @@ func (o *AnnotationOption) defaults() {
 	if o.BorderWidth <= 0 {
 		o.BorderWidth = 1
 	}
-	if o.Stamp == "" {
-		o.Stamp = StampDraft
+}
+
+// rect returns the annotation rectangle in points with a top-left origin.
+// The rectangle of Ink, Polyline, Polygon and Line annotations is grown to
+// contain their points, the stroke and the line endings.
+func (o AnnotationOption) rect() (x, y, w, h float64) {
+	var pts []Point
+	switch o.Type {
+	case AnnotInk:
+		for _, stroke := range o.InkList {
+			pts = append(pts, stroke...)
+		}
+	case AnnotPolyline, AnnotPolygon:
+		pts = o.Vertices
+	case AnnotLine:
+		pts = []Point{o.LineStart, o.LineEnd}
+	default:
+		return o.X, o.Y, o.W, o.H
+	}
+	x1, y1, x2, y2 := o.X, o.Y, o.X+o.W, o.Y+o.H
+	if o.W == 0 && o.H == 0 && len(pts) > 0 {
+		x1, y1, x2, y2 = pts[0].X, pts[0].Y, pts[0].X, pts[0].Y
+	}
+	for _, pt := range pts {
+		x1, y1 = math.Min(x1, pt.X), math.Min(y1, pt.Y)
+		x2, y2 = math.Max(x2, pt.X), math.Max(y2, pt.Y)
+	}
+	pad := o.BorderWidth / 2
+	if start, end := o.lineEndings(); (start != LineEndNone || end != LineEndNone) && o.Type != AnnotPolygon && o.Type != AnnotInk {
+		pad += o.lineEndingSize()
+	}
+	return x1 - pad, y1 - pad, x2 - x1 + 2*pad, y2 - y1 + 2*pad
+}
+
+// lineEndings returns the start and end styles with None for unset values.
+func (o AnnotationOption) lineEndings() (LineEndingStyle, LineEndingStyle) {
+	start, end := o.LineEndingStyles[0], o.LineEndingStyles[1]
+	if start == "" {
+		start = LineEndNone
 	}
+	if end == "" {
+		end = LineEndNone
+	}
+	return start, end
+}
+
+// lineEndingSize is the size of the line ending shapes.
+func (o AnnotationOption) lineEndingSize() float64 {
+	return math.Max(o.BorderWidth*4.5, 6)
+}
+
+func (o AnnotationOption) dashPattern() []float64 {
+	if len(o.DashPattern) > 0 {
+		return o.DashPattern
+	}
+	return []float64{3}
 }
 
 // AnnotationInfo holds information about an existing annotation on a page.
@@ -179,6 +265,7 @@ type AnnotationInfo struct {
 // annotationObj represents a PDF annotation object.
 type annotationObj struct {
 	opt     AnnotationOption
+	apObjID int // object ID of the appearance stream, 0 if none
 	getRoot func() *GoPdf
 }
 
@@ -194,10 +281,11 @@ func (a annotationObj) write(w io.Writer, objID int) error {
 	pageH := a.getRoot().config.PageSize.H
 
 	// Convert from top-left origin to PDF bottom-left origin.
-	x1 := a.opt.X
-	y1 := pageH - a.opt.Y
-	x2 := a.opt.X + a.opt.W
-	y2 := pageH - (a.opt.Y + a.opt.H)
+	rx, ry, rw, rh := a.opt.rect()
+	x1 := rx
+	y1 := pageH - ry
+	x2 := rx + rw
+	y2 := pageH - (ry + rh)
 
 	// Escape content string.
 	content := escapeAnnotString(a.opt.Content)
@@ -216,9 +304,6 @@ func (a annotationObj) write(w io.Writer, objID int) error {
 		fmt.Fprintf(w, "/Subtype /Text\n")
 		fmt.Fprintf(w, "/Rect [%.2f %.2f %.2f %.2f]\n", x1, y2, x2, y1)
 		fmt.Fprintf(w, "/Contents (%s)\n", content)
-		if title != "" {
-			fmt.Fprintf(w, "/T (%s)\n", title)
-		}
 		fmt.Fprintf(w, "/C [%.4f %.4f %.4f]\n", cr, cg, cb)
 		if a.opt.Open {
 			io.WriteString(w, "/Open true\n")
@@ -410,6 +495,11 @@ func (a annotationObj) write(w io.Writer, objID int) error {
 		}
 	}
 
+	// Author of the markup annotation.
+	if title != "" {
+		fmt.Fprintf(w, "/T (%s)\n", title)
+	}
+
 	// Opacity via CA entry.
 	if a.opt.Opacity < 1.0 {
 		fmt.Fprintf(w, "/CA %.4f\n", a.opt.Opacity)
@@ -417,24 +507,35 @@ func (a annotationObj) write(w io.Writer, objID int) error {
 
 	// Border.
 	fmt.Fprintf(w, "/Border [0 0 %.0f]\n", a.opt.BorderWidth)
+	if a.opt.BorderStyle != "" {
+		fmt.Fprintf(w, "/BS << /W %.2f /S /%s", a.opt.BorderWidth, a.opt.BorderStyle)
+		if a.opt.BorderStyle == AnnotBorderDashed {
+			fmt.Fprintf(w, " /D [%s]", formatDashPattern(a.opt.dashPattern()))
+		}
+		io.WriteString(w, " >>\n")
+	}
+
+	// Normal appearance.
+	if a.apObjID > 0 {
+		fmt.Fprintf(w, "/AP << /N %d 0 R >>\n", a.apObjID)
+	}
 
 	// Flags: Print (bit 3).
 	io.WriteString(w, "/F 4\n")
 
+	if a.opt.Actions != nil {
+		if aa := (actionWriter{a.getRoot(), objID}).additionalActions(a.opt.Actions.entries()); aa != "" {
+			fmt.Fprintf(w, "/AA %s\n", aa)
+		}
+	}
+
 	io.WriteString(w, ">>\n")
 	return nil
 }
 
 // writeLineEndings writes the /LE entry for Line and Polyline annotations.
 func (a annotationObj) writeLineEndings(w io.Writer) {
-	le0 := a.opt.LineEndingStyles[0]
-	le1 := a.opt.LineEndingStyles[1]
-	if le0 == "" {
-		le0 = LineEndNone
-	}
-	if le1 == "" {
-		le1 = LineEndNone
-	}
+	le0, le1 := a.opt.lineEndings()
 	if le0 != LineEndNone || le1 != LineEndNone {
 		fmt.Fprintf(w, "/LE [/%s /%s]\n", le0, le1)
 	}
@@ -450,7 +551,10 @@ func escapeAnnotString(s string) string {
 	return s
 }
 
-// AddAnnotation adds an annotation to the current page.
+// AddAnnotation adds an annotation to the current page. Every annotation
+// gets a normal appearance stream drawn with its color, border, opacity and
+// font, so it is displayed by viewers that do not generate appearances and
+// can be flattened with BakeAnnotations.
 //
 // Supported annotation types:
 //   - AnnotText: Sticky note (comment icon)
@@ -499,13 +603,33 @@ func (gp *GoPdf) AddAnnotation(opt AnnotationOption) {
 	if opt.Type == AnnotLine {
 		gp.UnitsToPointsVar(&opt.LineStart.X, &opt.LineStart.Y, &opt.LineEnd.X, &opt.LineEnd.Y)
 	}
+	if opt.Actions != nil {
+		a := *opt.Actions
+		gp.actionsToPoints(&a.Enter, &a.Exit, &a.Down, &a.Up,
+			&a.PageOpen, &a.PageClose, &a.PageVisible, &a.PageInvisible)
+		opt.Actions = &a
+	}
 
-	page := gp.pdfObjs[gp.curr.IndexOfPageObj].(*PageObj)
+	gp.addAnnotationToPage(gp.pdfObjs[gp.curr.IndexOfPageObj].(*PageObj), opt)
+}
+
+// addAnnotationToPage adds an annotation given in points and its
+// appearance stream to page.
+func (gp *GoPdf) addAnnotationToPage(page *PageObj, opt AnnotationOption) {
+	gp.addAnnotationChars(opt)
+
+	getRoot := func() *GoPdf {
+		return gp
+	}
+	// The appearance stream directly follows the annotation.
 	objIdx := gp.addObj(annotationObj{
-		opt: opt,
-		getRoot: func() *GoPdf {
-			return gp
-		},
+		opt:     opt,
+		apObjID: len(gp.pdfObjs) + 2,
+		getRoot: getRoot,
+	})
+	gp.addObj(&annotationAppearanceObj{
+		annotIdx: objIdx,
+		getRoot:  getRoot,
 	})
 	page.LinkObjIds = append(page.LinkObjIds, objIdx+1)
 }
@@ -0,0 +1,711 @@
+package gopdf
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"strings"
+)
+
+// annotationAppearanceObj is the normal appearance (/AP /N) Form XObject of an
+// annotation. The annotation option is read when the object is written, so
+// changes made with ModifyAnnotation are reflected in the appearance.
+type annotationAppearanceObj struct {
+	annotIdx int
+	// baked holds the option of an annotation flattened by BakeAnnotations,
+	// whose object has been removed.
+	baked   *AnnotationOption
+	getRoot func() *GoPdf
+}
+
+func (a *annotationAppearanceObj) init(f func() *GoPdf) {
+	a.getRoot = f
+}
+
+func (a *annotationAppearanceObj) getType() string {
+	return "XObject"
+}
+
+func (a *annotationAppearanceObj) option() (AnnotationOption, bool) {
+	if a.baked != nil {
+		return *a.baked, true
+	}
+	gp := a.getRoot()
+	if a.annotIdx < 0 || a.annotIdx >= len(gp.pdfObjs) {
+		return AnnotationOption{}, false
+	}
+	annot, ok := gp.pdfObjs[a.annotIdx].(annotationObj)
+	if !ok {
+		return AnnotationOption{}, false
+	}
+	return annot.opt, true
+}
+
+func (a *annotationAppearanceObj) write(w io.Writer, objID int) error {
+	gp := a.getRoot()
+	ap := &annotationAppearance{}
+	if opt, ok := a.option(); ok {
+		ap = newAnnotationAppearance(gp, opt)
+	}
+
+	stream := []byte(ap.buf.String())
+	if gp.protection() != nil {
+		tmp, err := rc4Cip(gp.protection().objectkey(objID), stream)
+		if err != nil {
+			return err
+		}
+		stream = tmp
+	}
+
+	io.WriteString(w, "<<\n")
+	io.WriteString(w, "/Type /XObject\n")
+	io.WriteString(w, "/Subtype /Form\n")
+	io.WriteString(w, "/FormType 1\n")
+	fmt.Fprintf(w, "/BBox [0 0 %.2f %.2f]\n", ap.w, ap.h)
+	io.WriteString(w, "/Matrix [1 0 0 1 0 0]\n")
+	io.WriteString(w, ap.resources())
+	fmt.Fprintf(w, "/Length %d\n", len(stream))
+	io.WriteString(w, ">>\n")
+	io.WriteString(w, "stream\n")
+	w.Write(stream)
+	io.WriteString(w, "\nendstream\n")
+	return nil
+}
+
+// annotationAppearance builds the content stream and resources of an
+// annotation appearance. Coordinates are relative to the lower-left corner
+// of the annotation rectangle.
+type annotationAppearance struct {
+	gp        *GoPdf
+	opt       AnnotationOption
+	x, y      float64 // top-left corner of the rectangle on the page
+	w, h      float64
+	buf       strings.Builder
+	fonts     []string // font resource entries
+	extGState string   // dictionary of /GS0
+}
+
+func newAnnotationAppearance(gp *GoPdf, opt AnnotationOption) *annotationAppearance {
+	opt.defaults()
+	ap := &annotationAppearance{gp: gp, opt: opt}
+	ap.x, ap.y, ap.w, ap.h = opt.rect()
+	ap.build()
+	return ap
+}
+
+func (ap *annotationAppearance) build() {
+	ap.buf.WriteString("q\n")
+	ap.setGraphicsState()
+	switch ap.opt.Type {
+	case AnnotText:
+		ap.drawNoteIcon()
+	case AnnotHighlight:
+		ap.setFill(ap.opt.Color)
+		fmt.Fprintf(&ap.buf, "0 0 %.2f %.2f re f\n", ap.w, ap.h)
+	case AnnotUnderline:
+		ap.drawTextLine(ap.h / 7)
+	case AnnotStrikeOut:
+		ap.drawTextLine(ap.h * 0.45)
+	case AnnotSquiggly:
+		ap.drawSquiggle()
+	case AnnotSquare:
+		ap.drawBox(false)
+	case AnnotCircle:
+		ap.drawBox(true)
+	case AnnotFreeText:
+		ap.drawBox(false)
+		ap.drawFreeText()
+	case AnnotInk:
+		ap.drawInk()
+	case AnnotPolyline:
+		ap.drawPath(ap.opt.Vertices, false)
+	case AnnotPolygon:
+		ap.drawPath(ap.opt.Vertices, true)
+	case AnnotLine:
+		ap.drawPath([]Point{ap.opt.LineStart, ap.opt.LineEnd}, false)
+	case AnnotStamp:
+		ap.drawStamp()
+	case AnnotCaret:
+		ap.drawCaret()
+	case AnnotFileAttachment:
+		ap.drawAttachmentIcon()
+	case AnnotRedact:
+		ap.setStroke(ap.opt.Color)
+		ap.setLineStyle()
+		bw := ap.opt.BorderWidth
+		fmt.Fprintf(&ap.buf, "%.2f %.2f %.2f %.2f re S\n", bw/2, bw/2, ap.w-bw, ap.h-bw)
+	}
+	ap.buf.WriteString("Q\n")
+}
+
+func (ap *annotationAppearance) resources() string {
+	var sb strings.Builder
+	sb.WriteString("/Resources <<\n")
+	if len(ap.fonts) > 0 {
+		sb.WriteString("/Font << " + strings.Join(ap.fonts, " ") + " >>\n")
+	}
+	if ap.extGState != "" {
+		sb.WriteString("/ExtGState << /GS0 " + ap.extGState + " >>\n")
+	}
+	sb.WriteString(">>\n")
+	return sb.String()
+}
+
+// setGraphicsState applies the opacity, and the multiply blend mode of
+// highlights, through the /GS0 graphics state.
+func (ap *annotationAppearance) setGraphicsState() {
+	var entries []string
+	if ap.opt.Opacity < 1 {
+		entries = append(entries, fmt.Sprintf("/CA %.4f /ca %.4f", ap.opt.Opacity, ap.opt.Opacity))
+	}
+	if ap.opt.Type == AnnotHighlight {
+		entries = append(entries, "/BM /Multiply")
+	}
+	if len(entries) == 0 {
+		return
+	}
+	ap.extGState = "<< /Type /ExtGState " + strings.Join(entries, " ") + " >>"
+	ap.buf.WriteString("/GS0 gs\n")
+}
+
+func (ap *annotationAppearance) setStroke(c [3]uint8) {
+	fmt.Fprintf(&ap.buf, "%.4f %.4f %.4f RG\n",
+		float64(c[0])/255.0, float64(c[1])/255.0, float64(c[2])/255.0)
+}
+
+func (ap *annotationAppearance) setFill(c [3]uint8) {
+	fmt.Fprintf(&ap.buf, "%.4f %.4f %.4f rg\n",
+		float64(c[0])/255.0, float64(c[1])/255.0, float64(c[2])/255.0)
+}
+
+// setLineStyle sets the line width and the dash pattern of the border style.
+func (ap *annotationAppearance) setLineStyle() {
+	fmt.Fprintf(&ap.buf, "%.2f w\n", ap.opt.BorderWidth)
+	if ap.opt.BorderStyle == AnnotBorderDashed {
+		fmt.Fprintf(&ap.buf, "[%s] 0 d\n", formatDashPattern(ap.opt.dashPattern()))
+	}
+}
+
+// local converts a page point (top-left origin) to appearance coordinates.
+func (ap *annotationAppearance) local(p Point) (float64, float64) {
+	return p.X - ap.x, ap.y + ap.h - p.Y
+}
+
+// drawTextLine draws the line of an underline or strike-out annotation.
+func (ap *annotationAppearance) drawTextLine(y float64) {
+	ap.setStroke(ap.opt.Color)
+	fmt.Fprintf(&ap.buf, "%.2f w\n", math.Max(ap.h/14, 0.5))
+	fmt.Fprintf(&ap.buf, "0 %.2f m %.2f %.2f l S\n", y, ap.w, y)
+}
+
+func (ap *annotationAppearance) drawSquiggle() {
+	amp := math.Max(ap.h/12, 1)
+	lw := math.Max(ap.h/20, 0.5)
+	ap.setStroke(ap.opt.Color)
+	fmt.Fprintf(&ap.buf, "%.2f w\n1 j\n", lw)
+	fmt.Fprintf(&ap.buf, "0 %.2f m\n", lw)
+	up := true
+	for x := 0.0; x < ap.w; {
+		x = math.Min(x+amp, ap.w)
+		y := lw
+		if up {
+			y += amp
+		}
+		fmt.Fprintf(&ap.buf, "%.2f %.2f l\n", x, y)
+		up = !up
+	}
+	ap.buf.WriteString("S\n")
+}
+
+// drawBox draws the rectangle or ellipse of a Square, Circle or FreeText
+// annotation, filled with the interior color.
+func (ap *annotationAppearance) drawBox(ellipse bool) {
+	bw := ap.opt.BorderWidth
+	x0, y0 := bw/2, bw/2
+	x1, y1 := ap.w-bw/2, ap.h-bw/2
+	fill := ap.opt.InteriorColor != nil
+	if fill {
+		ap.setFill(*ap.opt.InteriorColor)
+	}
+	if ellipse {
+		ap.setStroke(ap.opt.Color)
+		ap.setLineStyle()
+		ellipsePath(&ap.buf, (x0+x1)/2, (y0+y1)/2, (x1-x0)/2, (y1-y0)/2)
+		if fill {
+			ap.buf.WriteString("B\n")
+		} else {
+			ap.buf.WriteString("S\n")
+		}
+		return
+	}
+
+	if fill {
+		fmt.Fprintf(&ap.buf, "0 0 %.2f %.2f re f\n", ap.w, ap.h)
+	}
+	switch ap.opt.BorderStyle {
+	case AnnotBorderBeveled, AnnotBorderInset:
+		ap.drawBevel(bw, bw, ap.w-bw, ap.h-bw, bw)
+	case AnnotBorderUnderline:
+		ap.setStroke(ap.opt.Color)
+		ap.setLineStyle()
+		fmt.Fprintf(&ap.buf, "0 %.2f m %.2f %.2f l S\n", y0, ap.w, y0)
+		return
+	}
+	ap.setStroke(ap.opt.Color)
+	ap.setLineStyle()
+	fmt.Fprintf(&ap.buf, "%.2f %.2f %.2f %.2f re S\n", x0, y0, x1-x0, y1-y0)
+}
+
+// drawBevel draws the embossed (beveled) or engraved (inset) edges inside
+// the rectangle x0, y0, x1, y1.
+func (ap *annotationAppearance) drawBevel(x0, y0, x1, y1, bw float64) {
+	light, dark := "1 g", "0.5 g"
+	if ap.opt.BorderStyle == AnnotBorderInset {
+		light, dark = "0.5 g", "0.75 g"
+	}
+	fmt.Fprintf(&ap.buf, "%s\n%.2f %.2f m %.2f %.2f l %.2f %.2f l %.2f %.2f l %.2f %.2f l %.2f %.2f l h f\n",
+		light, x0, y0, x0, y1, x1, y1, x1-bw, y1-bw, x0+bw, y1-bw, x0+bw, y0+bw)
+	fmt.Fprintf(&ap.buf, "%s\n%.2f %.2f m %.2f %.2f l %.2f %.2f l %.2f %.2f l %.2f %.2f l %.2f %.2f l h f\n",
+		dark, x1, y1, x1, y0, x0, y0, x0+bw, y0+bw, x1-bw, y0+bw, x1-bw, y1-bw)
+}
+
+func (ap *annotationAppearance) drawInk() {
+	ap.setStroke(ap.opt.Color)
+	ap.setLineStyle()
+	ap.buf.WriteString("1 J 1 j\n")
+	for _, stroke := range ap.opt.InkList {
+		if len(stroke) == 0 {
+			continue
+		}
+		x, y := ap.local(stroke[0])
+		fmt.Fprintf(&ap.buf, "%.2f %.2f m\n", x, y)
+		if len(stroke) == 1 {
+			fmt.Fprintf(&ap.buf, "%.2f %.2f l\n", x, y)
+		}
+		for _, pt := range stroke[1:] {
+			x, y = ap.local(pt)
+			fmt.Fprintf(&ap.buf, "%.2f %.2f l\n", x, y)
+		}
+		ap.buf.WriteString("S\n")
+	}
+}
+
+// drawPath draws the path of a Line, Polyline or Polygon annotation and the
+// line endings of open paths.
+func (ap *annotationAppearance) drawPath(pts []Point, closed bool) {
+	if len(pts) < 2 {
+		return
+	}
+	ap.setStroke(ap.opt.Color)
+	if ap.opt.InteriorColor != nil {
+		ap.setFill(*ap.opt.InteriorColor)
+	}
+	ap.setLineStyle()
+	ap.buf.WriteString("1 j\n")
+	for i, pt := range pts {
+		x, y := ap.local(pt)
+		op := "l"
+		if i == 0 {
+			op = "m"
+		}
+		fmt.Fprintf(&ap.buf, "%.2f %.2f %s\n", x, y, op)
+	}
+	if closed {
+		ap.buf.WriteString(ap.closedPaintOp() + "\n")
+		return
+	}
+	ap.buf.WriteString("S\n")
+
+	// line endings are drawn solid
+	if ap.opt.BorderStyle == AnnotBorderDashed {
+		ap.buf.WriteString("[] 0 d\n")
+	}
+	start, end := ap.opt.lineEndings()
+	ap.drawLineEnding(start, pts[0], pts[1])
+	ap.drawLineEnding(end, pts[len(pts)-1], pts[len(pts)-2])
+}
+
+// closedPaintOp closes, strokes and, with an interior color, fills a path.
+func (ap *annotationAppearance) closedPaintOp() string {
+	if ap.opt.InteriorColor != nil {
+		return "b"
+	}
+	return "s"
+}
+
+// drawLineEnding draws style at tip for a segment coming from from.
+func (ap *annotationAppearance) drawLineEnding(style LineEndingStyle, tip, from Point) {
+	tx, ty := ap.local(tip)
+	fx, fy := ap.local(from)
+	dx, dy := tx-fx, ty-fy
+	l := math.Hypot(dx, dy)
+	if l == 0 {
+		return
+	}
+	ux, uy := dx/l, dy/l
+	nx, ny := -uy, ux
+	s := ap.opt.lineEndingSize()
+
+	switch style {
+	case LineEndOpenArrow, LineEndClosedArrow, LineEndROpenArrow, LineEndRClosedArrow:
+		bx, by := tx-s*ux, ty-s*uy
+		if style == LineEndROpenArrow || style == LineEndRClosedArrow {
+			bx, by = tx+s*ux, ty+s*uy
+		}
+		fmt.Fprintf(&ap.buf, "%.2f %.2f m %.2f %.2f l %.2f %.2f l\n",
+			bx+nx*s/2, by+ny*s/2, tx, ty, bx-nx*s/2, by-ny*s/2)
+		if style == LineEndClosedArrow || style == LineEndRClosedArrow {
+			ap.buf.WriteString(ap.closedPaintOp() + "\n")
+		} else {
+			ap.buf.WriteString("S\n")
+		}
+	case LineEndSquare:
+		fmt.Fprintf(&ap.buf, "%.2f %.2f %.2f %.2f re %s\n", tx-s/2, ty-s/2, s, s, ap.closedPaintOp())
+	case LineEndCircle:
+		ellipsePath(&ap.buf, tx, ty, s/2, s/2)
+		ap.buf.WriteString(ap.closedPaintOp() + "\n")
+	case LineEndDiamond:
+		fmt.Fprintf(&ap.buf, "%.2f %.2f m %.2f %.2f l %.2f %.2f l %.2f %.2f l %s\n",
+			tx+ux*s/2, ty+uy*s/2, tx+nx*s/2, ty+ny*s/2,
+			tx-ux*s/2, ty-uy*s/2, tx-nx*s/2, ty-ny*s/2, ap.closedPaintOp())
+	case LineEndButt:
+		fmt.Fprintf(&ap.buf, "%.2f %.2f m %.2f %.2f l S\n",
+			tx+nx*s/2, ty+ny*s/2, tx-nx*s/2, ty-ny*s/2)
+	case LineEndSlash:
+		// perpendicular rotated by 30 degrees
+		vx, vy := nx*0.866+ux*0.5, ny*0.866+uy*0.5
+		fmt.Fprintf(&ap.buf, "%.2f %.2f m %.2f %.2f l S\n",
+			tx+vx*s/2, ty+vy*s/2, tx-vx*s/2, ty-vy*s/2)
+	}
+}
+
+// drawNoteIcon draws a speech bubble with text lines for sticky notes.
+func (ap *annotationAppearance) drawNoteIcon() {
+	w, h := ap.w, ap.h
+	lw := math.Max(math.Min(w, h)/24, 0.5)
+	x0, x1 := lw, w-lw
+	y0, y1 := h*0.3, h-lw
+	ap.setFill(ap.opt.Color)
+	fmt.Fprintf(&ap.buf, "0.25 G\n%.2f w\n1 j\n", lw)
+	fmt.Fprintf(&ap.buf, "%.2f %.2f m %.2f %.2f l %.2f %.2f l %.2f %.2f l %.2f %.2f l %.2f %.2f l %.2f %.2f l h B\n",
+		x0, y1, x1, y1, x1, y0, w*0.45, y0, w*0.22, lw, w*0.28, y0, x0, y0)
+	for i := 1; i <= 3; i++ {
+		y := y1 - (y1-y0)*float64(i)/4
+		fmt.Fprintf(&ap.buf, "%.2f %.2f m %.2f %.2f l S\n", w*0.2, y, w*0.8, y)
+	}
+}
+
+func (ap *annotationAppearance) drawCaret() {
+	w, h := ap.w, ap.h
+	ap.setFill(ap.opt.Color)
+	fmt.Fprintf(&ap.buf, "0 0 m %.2f %.2f %.2f %.2f %.2f %.2f c %.2f %.2f %.2f %.2f %.2f 0 c h f\n",
+		w*0.35, h*0.1, w*0.45, h*0.5, w*0.5, h,
+		w*0.55, h*0.5, w*0.65, h*0.1, w)
+}
+
+// drawAttachmentIcon draws a document with a folded corner.
+func (ap *annotationAppearance) drawAttachmentIcon() {
+	w, h := ap.w, ap.h
+	lw := math.Max(math.Min(w, h)/24, 0.5)
+	fold := math.Min(w, h) * 0.3
+	x0, x1 := w*0.15, w*0.85
+	y0, y1 := lw, h-lw
+	ap.setFill(ap.opt.Color)
+	fmt.Fprintf(&ap.buf, "0.25 G\n%.2f w\n1 j\n", lw)
+	fmt.Fprintf(&ap.buf, "%.2f %.2f m %.2f %.2f l %.2f %.2f l %.2f %.2f l %.2f %.2f l h B\n",
+		x0, y0, x0, y1, x1-fold, y1, x1, y1-fold, x1, y0)
+	fmt.Fprintf(&ap.buf, "%.2f %.2f m %.2f %.2f l %.2f %.2f l S\n",
+		x1-fold, y1, x1-fold, y1-fold, x1, y1-fold)
+	for i := 1; i <= 3; i++ {
+		y := y1 - fold - (y1-fold-y0)*float64(i)/4
+		fmt.Fprintf(&ap.buf, "%.2f %.2f m %.2f %.2f l S\n", x0+w*0.1, y, x1-w*0.1, y)
+	}
+}
+
+// drawStamp draws a rubber stamp: a tinted rounded frame with the stamp
+// label in Helvetica-Bold.
+func (ap *annotationAppearance) drawStamp() {
+	w, h := ap.w, ap.h
+	bw := math.Max(ap.opt.BorderWidth, math.Min(w, h)*0.06)
+	r := math.Min(w, h) * 0.15
+	c := ap.opt.Color
+	var tint [3]uint8
+	for i := range c {
+		tint[i] = uint8(255 - (255-int(c[i]))*15/100)
+	}
+	ap.setFill(tint)
+	ap.setStroke(c)
+	fmt.Fprintf(&ap.buf, "%.2f w\n", bw)
+	roundedRectPath(&ap.buf, bw/2, bw/2, w-bw, h-bw, r)
+	ap.buf.WriteString("B\n")
+	fmt.Fprintf(&ap.buf, "%.2f w\n", bw/3)
+	roundedRectPath(&ap.buf, bw*1.8, bw*1.8, w-bw*3.6, h-bw*3.6, math.Max(r-bw*1.3, 0))
+	ap.buf.WriteString("S\n")
+
+	label := stampLabel(ap.opt.Stamp)
+	font := ap.standardFont("HeBo", "Helvetica-Bold")
+	unit := font.width(label, 1000)
+	if unit <= 0 {
+		return
+	}
+	size := math.Min(h*0.5, (w-bw*6)*1000/unit)
+	if size <= 0 {
+		return
+	}
+	tx := (w - unit*size/1000) / 2
+	ty := (h - size*0.718) / 2
+	ap.setFill(c)
+	fmt.Fprintf(&ap.buf, "BT\n/%s %.2f Tf\n%.2f %.2f Td\n%s\nET\n", font.name, size, tx, ty, font.show(label))
+}
+
+// drawFreeText lays out the content of a FreeText annotation inside its
+// border, wrapping words to the rectangle width.
+func (ap *annotationAppearance) drawFreeText() {
+	if ap.opt.Content == "" {
+		return
+	}
+	font := ap.textFont()
+	size := ap.opt.FontSize
+	pad := ap.opt.BorderWidth + 2
+	maxW := ap.w - 2*pad
+	if maxW <= 0 || ap.h-2*pad <= 0 {
+		return
+	}
+	lines := wrapAnnotationText(ap.opt.Content, maxW, func(s string) float64 {
+		return font.width(s, size)
+	})
+
+	fmt.Fprintf(&ap.buf, "%.2f %.2f %.2f %.2f re W n\n", pad, pad, maxW, ap.h-2*pad)
+	ap.buf.WriteString("BT\n")
+	ap.setFill(ap.opt.Color)
+	fmt.Fprintf(&ap.buf, "/%s %.2f Tf\n", font.name, size)
+	y := ap.h - pad - font.ascent(size)
+	for _, line := range lines {
+		if y < pad-size {
+			break
+		}
+		fmt.Fprintf(&ap.buf, "1 0 0 1 %.2f %.2f Tm\n%s\n", pad, y, font.show(line))
+		y -= size * 1.2
+	}
+	ap.buf.WriteString("ET\n")
+}
+
+// textFont returns the font of FreeText: the TTF family of the option if it
+// was added to the document, otherwise Helvetica.
+func (ap *annotationAppearance) textFont() annotationFont {
+	if ap.opt.FontFamily != "" {
+		if sub, objID := ap.gp.annotationFont(ap.opt.FontFamily); sub != nil {
+			font := annotationFont{name: "F1", sub: sub}
+			ap.fonts = append(ap.fonts, fmt.Sprintf("/F1 %d 0 R", objID))
+			return font
+		}
+	}
+	return ap.standardFont("Helv", "Helvetica")
+}
+
+func (ap *annotationAppearance) standardFont(name, baseFont string) annotationFont {
+	ap.fonts = append(ap.fonts, fmt.Sprintf(
+		"/%s << /Type /Font /Subtype /Type1 /BaseFont /%s /Encoding /WinAnsiEncoding >>", name, baseFont))
+	return annotationFont{name: name, std: newStandardFont(baseFont)}
+}
+
+// annotationFont returns the subset font of family and its object ID, or
+// nil if the family was not added.
+func (gp *GoPdf) annotationFont(family string) (*SubsetFontObj, int) {
+	var found *SubsetFontObj
+	foundID := 0
+	for i, obj := range gp.pdfObjs {
+		sub, ok := obj.(*SubsetFontObj)
+		if !ok || sub.GetFamily() != family || sub.standard != nil {
+			continue
+		}
+		if found == nil || sub.GetTtfFontOption().Style == Regular {
+			found, foundID = sub, i+1
+		}
+	}
+	return found, foundID
+}
+
+// addAnnotationChars adds the characters of a FreeText annotation to the
+// subset of its font family.
+func (gp *GoPdf) addAnnotationChars(opt AnnotationOption) {
+	if opt.Type != AnnotFreeText || opt.FontFamily == "" {
+		return
+	}
+	if sub, _ := gp.annotationFont(opt.FontFamily); sub != nil {
+		sub.AddChars(opt.Content)
+	}
+}
+
+// annotationFont is a font used by an annotation appearance: a TTF subset
+// font or a standard Type1 font with WinAnsi encoding.
+type annotationFont struct {
+	name string
+	sub  *SubsetFontObj
+	std  *standardFont // metrics of a standard font
+}
+
+func (f annotationFont) width(s string, size float64) float64 {
+	total := 0.0
+	for _, r := range s {
+		switch {
+		case f.sub != nil:
+			if cw, err := f.sub.CharWidth(r); err == nil {
+				total += float64(cw)
+			}
+		default:
+			if w, ok := f.std.runeWidth(r); ok {
+				total += float64(w)
+			} else {
+				total += 556
+			}
+		}
+	}
+	return total * size / 1000
+}
+
+func (f annotationFont) ascent(size float64) float64 {
+	if f.sub != nil {
+		return f.sub.GetAscenderPx(size)
+	}
+	return size * 0.718
+}
+
+// show returns the text showing operator for s.
+func (f annotationFont) show(s string) string {
+	var sb strings.Builder
+	if f.sub != nil {
+		sb.WriteString("<")
+		for _, r := range s {
+			if glyph, err := f.sub.CharIndex(r); err == nil {
+				fmt.Fprintf(&sb, "%04X", glyph)
+			}
+		}
+		sb.WriteString("> Tj")
+		return sb.String()
+	}
+	sb.WriteString("(")
+	for _, r := range s {
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			sb.WriteByte('\\')
+			sb.WriteRune(r)
+		case r >= 32 && r <= 126:
+			sb.WriteRune(r)
+		case r >= 0xA0 && r <= 0xFF:
+			fmt.Fprintf(&sb, "\\%03o", r)
+		default:
+			sb.WriteByte('?')
+		}
+	}
+	sb.WriteString(") Tj")
+	return sb.String()
+}
+
+// wrapAnnotationText breaks text into lines no wider than maxW. Lines are
+// broken at spaces and explicit newlines; words wider than maxW are broken
+// between characters.
+func wrapAnnotationText(text string, maxW float64, width func(string) float64) []string {
+	var lines []string
+	for _, para := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n") {
+		line := ""
+		for _, word := range strings.Fields(para) {
+			candidate := word
+			if line != "" {
+				candidate = line + " " + word
+			}
+			if width(candidate) <= maxW {
+				line = candidate
+				continue
+			}
+			if line != "" {
+				lines = append(lines, line)
+				line = ""
+			}
+			for width(word) > maxW {
+				runes := []rune(word)
+				n := 1
+				for n < len(runes) && width(string(runes[:n+1])) <= maxW {
+					n++
+				}
+				lines = append(lines, string(runes[:n]))
+				word = string(runes[n:])
+			}
+			line = word
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// ellipsePath appends an ellipse approximated by four Bézier curves.
+func ellipsePath(buf *strings.Builder, cx, cy, rx, ry float64) {
+	const k = 0.5523
+	fmt.Fprintf(buf, "%.2f %.2f m\n", cx+rx, cy)
+	fmt.Fprintf(buf, "%.2f %.2f %.2f %.2f %.2f %.2f c\n", cx+rx, cy+ry*k, cx+rx*k, cy+ry, cx, cy+ry)
+	fmt.Fprintf(buf, "%.2f %.2f %.2f %.2f %.2f %.2f c\n", cx-rx*k, cy+ry, cx-rx, cy+ry*k, cx-rx, cy)
+	fmt.Fprintf(buf, "%.2f %.2f %.2f %.2f %.2f %.2f c\n", cx-rx, cy-ry*k, cx-rx*k, cy-ry, cx, cy-ry)
+	fmt.Fprintf(buf, "%.2f %.2f %.2f %.2f %.2f %.2f c\n", cx+rx*k, cy-ry, cx+rx, cy-ry*k, cx+rx, cy)
+	buf.WriteString("h\n")
+}
+
+// roundedRectPath appends a rectangle with corners of radius r.
+func roundedRectPath(buf *strings.Builder, x, y, w, h, r float64) {
+	r = math.Min(r, math.Min(w, h)/2)
+	k := r * 0.5523
+	fmt.Fprintf(buf, "%.2f %.2f m\n", x+r, y)
+	fmt.Fprintf(buf, "%.2f %.2f l\n", x+w-r, y)
+	fmt.Fprintf(buf, "%.2f %.2f %.2f %.2f %.2f %.2f c\n", x+w-r+k, y, x+w, y+r-k, x+w, y+r)
+	fmt.Fprintf(buf, "%.2f %.2f l\n", x+w, y+h-r)
+	fmt.Fprintf(buf, "%.2f %.2f %.2f %.2f %.2f %.2f c\n", x+w, y+h-r+k, x+w-r+k, y+h, x+w-r, y+h)
+	fmt.Fprintf(buf, "%.2f %.2f l\n", x+r, y+h)
+	fmt.Fprintf(buf, "%.2f %.2f %.2f %.2f %.2f %.2f c\n", x+r-k, y+h, x, y+h-r+k, x, y+h-r)
+	fmt.Fprintf(buf, "%.2f %.2f l\n", x, y+r)
+	fmt.Fprintf(buf, "%.2f %.2f %.2f %.2f %.2f %.2f c\n", x, y+r-k, x+r-k, y, x+r, y)
+	buf.WriteString("h\n")
+}
+
+func formatDashPattern(dash []float64) string {
+	parts := make([]string, len(dash))
+	for i, d := range dash {
+		parts[i] = fmt.Sprintf("%.2f", d)
+	}
+	return strings.Join(parts, " ")
+}
+
+// stampLabels are the texts drawn by the predefined stamps.
+var stampLabels = map[StampName]string{
+	StampApproved:            "APPROVED",
+	StampAsIs:                "AS IS",
+	StampConfidential:        "CONFIDENTIAL",
+	StampDepartmental:        "DEPARTMENTAL",
+	StampDraft:               "DRAFT",
+	StampExperimental:        "EXPERIMENTAL",
+	StampExpired:             "EXPIRED",
+	StampFinal:               "FINAL",
+	StampForComment:          "FOR COMMENT",
+	StampForPublicRelease:    "FOR PUBLIC RELEASE",
+	StampNotApproved:         "NOT APPROVED",
+	StampNotForPublicRelease: "NOT FOR PUBLIC RELEASE",
+	StampSold:                "SOLD",
+	StampTopSecret:           "TOP SECRET",
+}
+
+func stampLabel(stamp StampName) string {
+	if label, ok := stampLabels[stamp]; ok {
+		return label
+	}
+	return strings.ToUpper(string(stamp))
+}
+
+// stampColor returns the default color of a stamp: green for approvals, red
+// for restrictive stamps and blue for the others.
+func stampColor(stamp StampName) [3]uint8 {
+	switch stamp {
+	case StampApproved, StampFinal:
+		return [3]uint8{0, 128, 0}
+	case StampNotApproved, StampConfidential, StampTopSecret, StampExpired, StampDraft, StampNotForPublicRelease:
+		return [3]uint8{192, 0, 0}
+	}
+	return [3]uint8{0, 64, 160}
+}
@@ -73,21 +73,27 @@ func (gp *GoPdf) ModifyAnnotation(pageIndex, annotIndex int, opt AnnotationOptio
 	if opt.OverlayText != "" {
 		annot.opt.OverlayText = opt.OverlayText
 	}
+	if opt.FontFamily != "" {
+		annot.opt.FontFamily = opt.FontFamily
+	}
+	if opt.BorderStyle != "" {
+		annot.opt.BorderStyle = opt.BorderStyle
+	}
+	if len(opt.DashPattern) > 0 {
+		annot.opt.DashPattern = opt.DashPattern
+	}
+	if opt.Stamp != "" {
+		annot.opt.Stamp = opt.Stamp
+	}
 
+	// The appearance stream is generated from the modified option when the
+	// document is written.
+	gp.addAnnotationChars(annot.opt)
 	gp.pdfObjs[objIdx] = annot
 	return nil
 }
 
 // getPageByIndex returns the PageObj at the given 0-based page index.
 func (gp *GoPdf) getPageByIndex(pageIndex int) *PageObj {
-	pageCount := 0
-	for _, obj := range gp.pdfObjs {
-		if p, ok := obj.(*PageObj); ok {
-			if pageCount == pageIndex {
-				return p
-			}
-			pageCount++
-		}
-	}
-	return nil
+	return gp.findPageObj(pageIndex + 1)
 }
@@ -83,7 +83,18 @@ func getCharShape(previousChar, currentChar, nextChar rune) rune {
 	return shape
 }
 
+// ToArabic shapes the Arabic letters of text and returns it in visual
+// order for a right-to-left paragraph, as reordered by the Unicode
+// bidirectional algorithm: Latin text and numbers embedded in the Arabic
+// text keep their left-to-right order.
 func ToArabic(text string) string {
+	visual, _ := BidiVisual(text, TextDirectionRTL)
+	return visual
+}
+
+// shapeArabic replaces the Arabic letters of text with their contextual
+// presentation forms and the lam-alef ligatures, keeping the logical order.
+func shapeArabic(text string) string {
 	var nextHarf, previousHarf rune
 
 	hrof := []rune(text)    // hrof is arabic letters
@@ -132,6 +143,5 @@ func ToArabic(text string) string {
 		harfShape := getCharShape(previousHarf, currentHarf, nextHarf)
 		arabicSentence = append(arabicSentence, harfShape)
 	}
-	arabicSentenceRTL := Reverse(string(arabicSentence))
-	return arabicSentenceRTL
+	return string(arabicSentence)
 }
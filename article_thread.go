@@ -0,0 +1,140 @@
+package gopdf
+
+import (
+	"errors"
+	"fmt"
+	"io"
+)
+
+// ============================================================
+// Article threads (PDF 32000-1:2008, 12.4.3)
+// ============================================================
+
+// ArticleBead is a rectangle of an article thread on a page, in document
+// units from the top-left page corner. Page is 1-based.
+type ArticleBead struct {
+	Page       int
+	X, Y, W, H float64
+}
+
+// ArticleThread is an article: a sequence of rectangles across pages that
+// viewers follow to read text laid out in columns or continued on later
+// pages.
+type ArticleThread struct {
+	Title    string
+	Author   string
+	Subject  string
+	Keywords string
+	Beads    []ArticleBead
+}
+
+// AddArticleThread adds an article thread whose beads are read in order.
+// The pages of the beads must exist.
+//
+// Example:
+//
+//	pdf.AddArticleThread(gopdf.ArticleThread{
+//	    Title: "Lead story",
+//	    Beads: []gopdf.ArticleBead{
+//	        {Page: 1, X: 40, Y: 100, W: 250, H: 650},
+//	        {Page: 1, X: 305, Y: 100, W: 250, H: 650},
+//	        {Page: 3, X: 40, Y: 60, W: 515, H: 300},
+//	    },
+//	})
+func (gp *GoPdf) AddArticleThread(thread ArticleThread) error {
+	if len(thread.Beads) == 0 {
+		return errors.New("article thread needs at least one bead")
+	}
+	pages := make([]*PageObj, len(thread.Beads))
+	for i, b := range thread.Beads {
+		if pages[i] = gp.findPageObj(b.Page); pages[i] == nil {
+			return ErrPageOutOfRange
+		}
+		if b.W <= 0 || b.H <= 0 {
+			return errors.New("article bead width and height must be positive")
+		}
+	}
+	getRoot := func() *GoPdf { return gp }
+	threadIdx := gp.addObj(articleThreadObj{thread: thread, firstBead: len(gp.pdfObjs) + 2, getRoot: getRoot})
+	n := len(thread.Beads)
+	for i, b := range thread.Beads {
+		x, y, w, h := b.X, b.Y, b.W, b.H
+		gp.UnitsToPointsVar(&x, &y, &w, &h)
+		pageH := gp.pageHeight(b.Page)
+		// The beads form a circular list.
+		idx := gp.addObj(articleBeadObj{
+			thread:  threadIdx + 1,
+			next:    threadIdx + 2 + (i+1)%n,
+			prev:    threadIdx + 2 + (i+n-1)%n,
+			page:    pages[i],
+			rect:    [4]float64{x, pageH - y - h, x + w, pageH - y},
+			getRoot: getRoot,
+		})
+		pages[i].beadObjIDs = append(pages[i].beadObjIDs, idx+1)
+	}
+	catalog := gp.pdfObjs[gp.indexOfCatalogObj].(*CatalogObj)
+	catalog.threadObjIDs = append(catalog.threadObjIDs, threadIdx+1)
+	gp.articleThreads = append(gp.articleThreads, thread)
+	return nil
+}
+
+// GetArticleThreads returns the article threads added with
+// AddArticleThread.
+func (gp *GoPdf) GetArticleThreads() []ArticleThread {
+	return append([]ArticleThread(nil), gp.articleThreads...)
+}
+
+// articleThreadObj is a thread dictionary.
+type articleThreadObj struct {
+	thread    ArticleThread
+	firstBead int // object ID of the first bead
+	getRoot   func() *GoPdf
+}
+
+func (t articleThreadObj) init(f func() *GoPdf) {}
+
+func (t articleThreadObj) getType() string {
+	return "Thread"
+}
+
+func (t articleThreadObj) write(w io.Writer, objID int) error {
+	aw := actionWriter{t.getRoot(), objID}
+	info := ""
+	for _, e := range []struct{ key, value string }{
+		{"/Title", t.thread.Title}, {"/Author", t.thread.Author},
+		{"/Subject", t.thread.Subject}, {"/Keywords", t.thread.Keywords},
+	} {
+		if e.value != "" {
+			info += fmt.Sprintf(" %s %s", e.key, aw.str(e.value))
+		}
+	}
+	fmt.Fprintf(w, "<< /Type /Thread /F %d 0 R", t.firstBead)
+	if info != "" {
+		fmt.Fprintf(w, " /I <<%s >>", info)
+	}
+	_, err := io.WriteString(w, " >>\n")
+	return err
+}
+
+// articleBeadObj is a bead dictionary.
+type articleBeadObj struct {
+	thread     int // object ID of the thread
+	next, prev int // object IDs of the next and previous beads
+	page       *PageObj
+	rect       [4]float64 // in points, bottom-left origin
+	getRoot    func() *GoPdf
+}
+
+func (b articleBeadObj) init(f func() *GoPdf) {}
+
+func (b articleBeadObj) getType() string {
+	return "Bead"
+}
+
+func (b articleBeadObj) write(w io.Writer, objID int) error {
+	gp := b.getRoot()
+	_, err := fmt.Fprintf(w, "<< /Type /Bead /T %d 0 R /N %d 0 R /V %d 0 R /P %d 0 R /R [%.2f %.2f %.2f %.2f] >>\n",
+		b.thread, b.next, b.prev, gp.pageObjIDByNumber(gp.pageNoOf(b.page)),
+		b.rect[0], b.rect[1], b.rect[2], b.rect[3])
+	return err
+}
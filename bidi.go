@@ -0,0 +1,774 @@
+package gopdf
+
+import (
+	"unicode"
+)
+
+// ============================================================
+// Bidirectional text (UAX #9)
+// ============================================================
+
+// TextDirection is the base direction of text for the Unicode
+// bidirectional algorithm.
+type TextDirection int
+
+const (
+	// TextDirectionNone draws text in logical order without bidi
+	// processing (default).
+	TextDirectionNone TextDirection = iota
+	// TextDirectionAuto takes the base direction of each paragraph from
+	// its first strong character, left-to-right if there is none.
+	TextDirectionAuto
+	// TextDirectionLTR is a left-to-right base direction.
+	TextDirectionLTR
+	// TextDirectionRTL is a right-to-left base direction.
+	TextDirectionRTL
+)
+
+// SetTextDirection sets the base direction of the text drawn with Text,
+// Cell, CellWithOption, MultiCell and MultiCellWithOption, and of the
+// lines returned by SplitText. With a direction other than
+// TextDirectionNone, text is reordered for display with the Unicode
+// bidirectional algorithm after line breaking, Arabic letters are shaped
+// and mirrored characters such as brackets are mirrored in right-to-left
+// runs. Right-to-left paragraphs are right-aligned unless an alignment is
+// given.
+//
+// Example:
+//
+//	pdf.SetTextDirection(gopdf.TextDirectionAuto)
+//	pdf.Cell(nil, "Invoice #1234 فاتورة")
+func (gp *GoPdf) SetTextDirection(dir TextDirection) {
+	gp.textDirection = dir
+}
+
+// GetTextDirection returns the base direction set with SetTextDirection.
+func (gp *GoPdf) GetTextDirection() TextDirection {
+	return gp.textDirection
+}
+
+// BidiVisual returns a line of text in visual (display) order and whether
+// its base direction is right-to-left. Arabic letters are shaped and
+// mirrored characters are mirrored in right-to-left runs. With
+// TextDirectionNone, text is returned as it is.
+func BidiVisual(text string, dir TextDirection) (string, bool) {
+	if dir == TextDirectionNone || text == "" {
+		return text, false
+	}
+	runes := []rune(shapeArabic(text))
+	levels, rtl := bidiLevels(runes, dir)
+	return bidiReorder(runes, levels), rtl
+}
+
+// bidiText returns text in visual order for the direction opt, or the
+// direction set with SetTextDirection if opt is TextDirectionNone.
+func (gp *GoPdf) bidiText(text string, opt TextDirection) (string, bool) {
+	if opt == TextDirectionNone {
+		opt = gp.textDirection
+	}
+	return BidiVisual(text, opt)
+}
+
+// paragraphDirection resolves TextDirectionAuto for a paragraph to
+// TextDirectionLTR or TextDirectionRTL.
+func paragraphDirection(text string, dir TextDirection) TextDirection {
+	if dir != TextDirectionAuto {
+		return dir
+	}
+	runes := []rune(text)
+	classes := make([]bidiClass, len(runes))
+	for i, r := range runes {
+		classes[i] = bidiClassOf(r)
+	}
+	if c := firstStrong(classes, 0, len(classes)); c == bcR || c == bcAL {
+		return TextDirectionRTL
+	}
+	return TextDirectionLTR
+}
+
+// bidiClass is a bidirectional character type.
+type bidiClass uint8
+
+const (
+	bcL bidiClass = iota
+	bcR
+	bcAL
+	bcEN
+	bcES
+	bcET
+	bcAN
+	bcCS
+	bcNSM
+	bcBN
+	bcB
+	bcS
+	bcWS
+	bcON
+	bcLRE
+	bcLRO
+	bcRLE
+	bcRLO
+	bcPDF
+	bcLRI
+	bcRLI
+	bcFSI
+	bcPDI
+)
+
+// bidiClassOf returns the bidirectional character type of r.
+func bidiClassOf(r rune) bidiClass {
+	switch r {
+	case '\n', '\r', 0x1C, 0x1D, 0x1E, 0x85, 0x2029:
+		return bcB
+	case '\t', 0x0B, 0x1F:
+		return bcS
+	case ' ', 0x0C, 0x1680, 0x2028, 0x205F, 0x3000:
+		return bcWS
+	case 0x202A:
+		return bcLRE
+	case 0x202B:
+		return bcRLE
+	case 0x202C:
+		return bcPDF
+	case 0x202D:
+		return bcLRO
+	case 0x202E:
+		return bcRLO
+	case 0x2066:
+		return bcLRI
+	case 0x2067:
+		return bcRLI
+	case 0x2068:
+		return bcFSI
+	case 0x2069:
+		return bcPDI
+	case 0x200E:
+		return bcL
+	case 0x200F:
+		return bcR
+	case 0x061C:
+		return bcAL
+	case 0x00AD, 0x180E, 0xFEFF:
+		return bcBN
+	case '+', '-', 0x207A, 0x207B, 0x208A, 0x208B, 0x2212, 0xFB29, 0xFE62, 0xFE63, 0xFF0B, 0xFF0D:
+		return bcES
+	case '#', '$', '%', 0x00A2, 0x00A3, 0x00A4, 0x00A5, 0x00B0, 0x00B1, 0x058F, 0x0609, 0x060A,
+		0x066A, 0x09F2, 0x09F3, 0x0E3F, 0x17DB, 0x212E, 0x2213, 0xFE5F, 0xFE69, 0xFE6A,
+		0xFF03, 0xFF04, 0xFF05, 0xFFE0, 0xFFE1, 0xFFE5, 0xFFE6:
+		return bcET
+	case ',', '.', '/', ':', 0x00A0, 0x060C, 0x202F, 0x2044, 0xFE50, 0xFE52, 0xFE55,
+		0xFF0C, 0xFF0E, 0xFF0F, 0xFF1A:
+		return bcCS
+	case 0x00B2, 0x00B3, 0x00B9, 0x2070:
+		return bcEN
+	case 0x066B, 0x066C, 0x06DD, 0x0890, 0x0891, 0x08E2:
+		return bcAN
+	}
+	switch {
+	case r < 0x20 || r >= 0x7F && r <= 0x9F || r >= 0x200B && r <= 0x200D ||
+		r >= 0x2060 && r <= 0x2064 || r >= 0x206A && r <= 0x206F:
+		return bcBN
+	case r >= '0' && r <= '9', r >= 0x06F0 && r <= 0x06F9, r >= 0x2074 && r <= 0x2079,
+		r >= 0x2080 && r <= 0x2089, r >= 0xFF10 && r <= 0xFF19, r >= 0x1D7CE && r <= 0x1D7FF:
+		return bcEN
+	case r >= 0x0600 && r <= 0x0605, r >= 0x0660 && r <= 0x0669, r >= 0x10E60 && r <= 0x10E7E:
+		return bcAN
+	case r >= 0x2000 && r <= 0x200A:
+		return bcWS
+	case r >= 0x20A0 && r <= 0x20CF, r >= 0x2030 && r <= 0x2034:
+		return bcET
+	case unicode.In(r, unicode.Mn, unicode.Me):
+		return bcNSM
+	case r >= 0x0590 && r <= 0x05FF, r >= 0x07C0 && r <= 0x085F, r >= 0xFB1D && r <= 0xFB4F,
+		r >= 0x10800 && r <= 0x10FFF, r >= 0x1E800 && r <= 0x1EDFF:
+		return bcR
+	case r >= 0x0600 && r <= 0x07BF, r >= 0x0860 && r <= 0x08FF, r >= 0xFB50 && r <= 0xFDFF,
+		r >= 0xFE70 && r <= 0xFEFE, r >= 0x1EE00 && r <= 0x1EEFF:
+		return bcAL
+	case unicode.IsLetter(r), unicode.IsDigit(r), unicode.Is(unicode.Mc, r):
+		return bcL
+	case unicode.IsPunct(r), unicode.IsSymbol(r):
+		return bcON
+	}
+	return bcL
+}
+
+func isIsolateInitiator(c bidiClass) bool {
+	return c == bcLRI || c == bcRLI || c == bcFSI
+}
+
+// isNeutral reports whether c is a neutral or isolate formatting type (NI).
+func isNeutral(c bidiClass) bool {
+	switch c {
+	case bcB, bcS, bcWS, bcON, bcLRI, bcRLI, bcFSI, bcPDI:
+		return true
+	}
+	return false
+}
+
+// matchingPDI returns the index of the PDI matching the isolate initiator
+// at i, or -1.
+func matchingPDI(classes []bidiClass, i int) int {
+	depth := 1
+	for j := i + 1; j < len(classes); j++ {
+		switch {
+		case isIsolateInitiator(classes[j]):
+			depth++
+		case classes[j] == bcPDI:
+			if depth--; depth == 0 {
+				return j
+			}
+		case classes[j] == bcB:
+			return -1
+		}
+	}
+	return -1
+}
+
+// firstStrong returns the first L, R or AL type in classes[start:end],
+// skipping isolates (P2), or bcON if there is none.
+func firstStrong(classes []bidiClass, start, end int) bidiClass {
+	for i := start; i < end; i++ {
+		switch c := classes[i]; {
+		case c == bcL || c == bcR || c == bcAL:
+			return c
+		case isIsolateInitiator(c):
+			if i = matchingPDI(classes, i); i < 0 {
+				return bcON
+			}
+		case c == bcB:
+			return bcON
+		}
+	}
+	return bcON
+}
+
+// bidiLevels resolves the embedding levels of a line (rules P2 to I2 and
+// L1) and reports whether its paragraph level is right-to-left.
+func bidiLevels(runes []rune, dir TextDirection) ([]uint8, bool) {
+	n := len(runes)
+	classes := make([]bidiClass, n)
+	for i, r := range runes {
+		classes[i] = bidiClassOf(r)
+	}
+	paraLevel := uint8(0)
+	switch dir {
+	case TextDirectionRTL:
+		paraLevel = 1
+	case TextDirectionAuto:
+		if c := firstStrong(classes, 0, n); c == bcR || c == bcAL {
+			paraLevel = 1
+		}
+	}
+
+	// X1-X8: explicit levels and directions.
+	types := append([]bidiClass(nil), classes...)
+	levels := make([]uint8, n)
+	type status struct {
+		level    uint8
+		override bidiClass // bcON for none
+		isolate  bool
+	}
+	stack := []status{{paraLevel, bcON, false}}
+	overflowIsolate, overflowEmbedding, validIsolate := 0, 0, 0
+	nextLevel := func(rtl bool) uint8 {
+		l := stack[len(stack)-1].level + 1
+		if rtl == (l%2 == 0) {
+			l++
+		}
+		return l
+	}
+	for i, c := range classes {
+		top := stack[len(stack)-1]
+		switch c {
+		case bcRLE, bcLRE, bcRLO, bcLRO:
+			levels[i], types[i] = top.level, bcBN
+			l := nextLevel(c == bcRLE || c == bcRLO)
+			if l <= 125 && overflowIsolate == 0 && overflowEmbedding == 0 {
+				override := bcON
+				if c == bcRLO {
+					override = bcR
+				} else if c == bcLRO {
+					override = bcL
+				}
+				stack = append(stack, status{l, override, false})
+			} else if overflowIsolate == 0 {
+				overflowEmbedding++
+			}
+		case bcRLI, bcLRI, bcFSI:
+			levels[i] = top.level
+			if top.override != bcON {
+				types[i] = top.override
+			}
+			rtl := c == bcRLI
+			if c == bcFSI {
+				end := matchingPDI(classes, i)
+				if end < 0 {
+					end = n
+				}
+				s := firstStrong(classes, i+1, end)
+				rtl = s == bcR || s == bcAL
+			}
+			l := nextLevel(rtl)
+			if l <= 125 && overflowIsolate == 0 && overflowEmbedding == 0 {
+				validIsolate++
+				stack = append(stack, status{l, bcON, true})
+			} else {
+				overflowIsolate++
+			}
+		case bcPDI:
+			if overflowIsolate > 0 {
+				overflowIsolate--
+			} else if validIsolate > 0 {
+				overflowEmbedding = 0
+				for !stack[len(stack)-1].isolate {
+					stack = stack[:len(stack)-1]
+				}
+				stack = stack[:len(stack)-1]
+				validIsolate--
+			}
+			top = stack[len(stack)-1]
+			levels[i] = top.level
+			if top.override != bcON {
+				types[i] = top.override
+			}
+		case bcPDF:
+			if overflowIsolate == 0 {
+				if overflowEmbedding > 0 {
+					overflowEmbedding--
+				} else if !top.isolate && len(stack) > 1 {
+					stack = stack[:len(stack)-1]
+				}
+			}
+			levels[i], types[i] = top.level, bcBN
+		case bcB:
+			levels[i] = paraLevel
+		case bcBN:
+			levels[i] = top.level
+		default:
+			levels[i] = top.level
+			if top.override != bcON {
+				types[i] = top.override
+			}
+		}
+	}
+
+	// X9, X10: isolating run sequences of the characters that are kept.
+	var kept []int
+	for i, t := range types {
+		if t != bcBN {
+			kept = append(kept, i)
+		}
+	}
+	for _, seq := range isolatingRunSequences(classes, types, levels, kept, paraLevel) {
+		resolveSequence(runes, types, levels, seq)
+	}
+
+	// I1, I2: implicit levels.
+	for _, i := range kept {
+		t := types[i]
+		if levels[i]%2 == 0 {
+			if t == bcR {
+				levels[i]++
+			} else if t == bcAN || t == bcEN {
+				levels[i] += 2
+			}
+		} else if t == bcL || t == bcEN || t == bcAN {
+			levels[i]++
+		}
+	}
+
+	// L1: separators and trailing whitespace take the paragraph level.
+	trailing := true
+	for i := n - 1; i >= 0; i-- {
+		switch c := classes[i]; {
+		case c == bcS || c == bcB:
+			levels[i] = paraLevel
+			trailing = true
+		case trailing && (c == bcWS || isIsolateInitiator(c) || c == bcPDI || types[i] == bcBN):
+			levels[i] = paraLevel
+		default:
+			trailing = false
+		}
+	}
+	return levels, paraLevel == 1
+}
+
+// bidiSequence is an isolating run sequence with its start and end of
+// sequence types.
+type bidiSequence struct {
+	indexes  []int
+	sos, eos bidiClass
+	level    uint8
+}
+
+// isolatingRunSequences splits the kept characters into level runs and
+// joins the runs connected by matching isolate initiators and PDIs (BD13).
+func isolatingRunSequences(classes, types []bidiClass, levels []uint8, kept []int, paraLevel uint8) []bidiSequence {
+	var runs [][]int
+	for k, i := range kept {
+		if k == 0 || levels[i] != levels[kept[k-1]] {
+			runs = append(runs, nil)
+		}
+		runs[len(runs)-1] = append(runs[len(runs)-1], i)
+	}
+	runOf := make(map[int]int) // first index of a run -> run number
+	for r, run := range runs {
+		runOf[run[0]] = r
+	}
+	matched := make(map[int]bool) // PDIs that continue a sequence
+	for i, c := range classes {
+		if isIsolateInitiator(c) {
+			if j := matchingPDI(classes, i); j >= 0 {
+				matched[j] = true
+			}
+		}
+	}
+	pos := make(map[int]int) // character -> position in kept
+	for k, i := range kept {
+		pos[i] = k
+	}
+	typeOf := func(level uint8) bidiClass {
+		if level%2 == 1 {
+			return bcR
+		}
+		return bcL
+	}
+	maxLevel := func(a, b uint8) uint8 {
+		if a > b {
+			return a
+		}
+		return b
+	}
+	var seqs []bidiSequence
+	for _, run := range runs {
+		if classes[run[0]] == bcPDI && matched[run[0]] {
+			continue
+		}
+		var seq []int
+		for {
+			seq = append(seq, run...)
+			last := run[len(run)-1]
+			if !isIsolateInitiator(classes[last]) {
+				break
+			}
+			j := matchingPDI(classes, last)
+			r, ok := runOf[j]
+			if j < 0 || !ok {
+				break
+			}
+			run = runs[r]
+		}
+		level := levels[seq[0]]
+		prev, next := paraLevel, paraLevel
+		if k := pos[seq[0]]; k > 0 {
+			prev = levels[kept[k-1]]
+		}
+		last := seq[len(seq)-1]
+		if k := pos[last]; k < len(kept)-1 && !isIsolateInitiator(classes[last]) {
+			next = levels[kept[k+1]]
+		}
+		seqs = append(seqs, bidiSequence{
+			indexes: seq,
+			sos:     typeOf(maxLevel(prev, level)),
+			eos:     typeOf(maxLevel(next, levels[last])),
+			level:   level,
+		})
+	}
+	return seqs
+}
+
+// resolveSequence applies the weak type rules W1 to W7 and the neutral
+// type rules N0 to N2 to an isolating run sequence.
+func resolveSequence(runes []rune, types []bidiClass, levels []uint8, seq bidiSequence) {
+	idx := seq.indexes
+	n := len(idx)
+	t := func(k int) bidiClass { return types[idx[k]] }
+	set := func(k int, c bidiClass) { types[idx[k]] = c }
+
+	// W1: non-spacing marks take the type of the previous character.
+	for k := 0; k < n; k++ {
+		if t(k) != bcNSM {
+			continue
+		}
+		if k == 0 {
+			set(k, seq.sos)
+		} else if p := t(k - 1); isIsolateInitiator(p) || p == bcPDI {
+			set(k, bcON)
+		} else {
+			set(k, p)
+		}
+	}
+	// W2, W3: European numbers after Arabic letters are Arabic numbers.
+	strong := seq.sos
+	for k := 0; k < n; k++ {
+		switch t(k) {
+		case bcL, bcR:
+			strong = t(k)
+		case bcAL:
+			strong = bcAL
+			set(k, bcR)
+		case bcEN:
+			if strong == bcAL {
+				set(k, bcAN)
+			}
+		}
+	}
+	// W4: single separators between numbers.
+	for k := 1; k < n-1; k++ {
+		p, c, nx := t(k-1), t(k), t(k+1)
+		if c == bcES && p == bcEN && nx == bcEN || c == bcCS && p == bcEN && nx == bcEN {
+			set(k, bcEN)
+		} else if c == bcCS && p == bcAN && nx == bcAN {
+			set(k, bcAN)
+		}
+	}
+	// W5: terminators next to European numbers.
+	for k := 0; k < n; k++ {
+		if t(k) != bcET {
+			continue
+		}
+		end := k
+		for end < n && t(end) == bcET {
+			end++
+		}
+		if (k > 0 && t(k-1) == bcEN) || (end < n && t(end) == bcEN) {
+			for j := k; j < end; j++ {
+				set(j, bcEN)
+			}
+		}
+		k = end
+	}
+	// W6: remaining separators and terminators are neutral.
+	for k := 0; k < n; k++ {
+		if c := t(k); c == bcES || c == bcET || c == bcCS {
+			set(k, bcON)
+		}
+	}
+	// W7: European numbers after left-to-right text are left-to-right.
+	strong = seq.sos
+	for k := 0; k < n; k++ {
+		switch t(k) {
+		case bcL, bcR:
+			strong = t(k)
+		case bcEN:
+			if strong == bcL {
+				set(k, bcL)
+			}
+		}
+	}
+
+	embedding := bcL
+	if seq.level%2 == 1 {
+		embedding = bcR
+	}
+	strongDir := func(c bidiClass) bidiClass {
+		switch c {
+		case bcL:
+			return bcL
+		case bcR, bcEN, bcAN:
+			return bcR
+		}
+		return bcON
+	}
+
+	// N0: paired brackets take the direction of their content.
+	for _, pair := range bracketPairs(runes, types, idx) {
+		open, close := pair[0], pair[1]
+		found := bcON
+		for k := open + 1; k < close; k++ {
+			d := strongDir(t(k))
+			if d == embedding {
+				found = embedding
+				break
+			}
+			if d != bcON {
+				found = d
+			}
+		}
+		if found == bcON {
+			continue
+		}
+		if found != embedding {
+			before := seq.sos
+			for k := open - 1; k >= 0; k-- {
+				if d := strongDir(t(k)); d != bcON {
+					before = d
+					break
+				}
+			}
+			if before != found {
+				found = embedding
+			}
+		}
+		set(open, found)
+		set(close, found)
+		for _, k := range []int{open, close} {
+			for j := k + 1; j < n && classesNSM(runes[idx[j]]); j++ {
+				set(j, found)
+			}
+		}
+	}
+
+	// N1, N2: neutrals between strong types of the same direction take
+	// that direction, others the embedding direction.
+	for k := 0; k < n; k++ {
+		if !isNeutral(t(k)) {
+			continue
+		}
+		end := k
+		for end < n && isNeutral(t(end)) {
+			end++
+		}
+		before, after := seq.sos, seq.eos
+		if k > 0 {
+			before = strongDir(t(k - 1))
+		}
+		if end < n {
+			after = strongDir(t(end))
+		}
+		dir := embedding
+		if before == after && before != bcON {
+			dir = before
+		}
+		for j := k; j < end; j++ {
+			set(j, dir)
+		}
+		k = end
+	}
+}
+
+func classesNSM(r rune) bool {
+	return bidiClassOf(r) == bcNSM
+}
+
+// bracketPairs returns the positions in idx of the paired brackets of a
+// sequence (BD16), ordered by the opening bracket.
+func bracketPairs(runes []rune, types []bidiClass, idx []int) [][2]int {
+	type open struct {
+		close rune
+		pos   int
+	}
+	var stack []open
+	var pairs [][2]int
+	for k, i := range idx {
+		if types[i] != bcON {
+			continue
+		}
+		r := runes[i]
+		if c, ok := bidiBrackets[r]; ok {
+			if len(stack) == 63 {
+				break
+			}
+			stack = append(stack, open{c, k})
+			continue
+		}
+		for s := len(stack) - 1; s >= 0; s-- {
+			if stack[s].close == r {
+				pairs = append(pairs, [2]int{stack[s].pos, k})
+				stack = stack[:s]
+				break
+			}
+		}
+	}
+	// order by opening position
+	for a := 1; a < len(pairs); a++ {
+		for b := a; b > 0 && pairs[b][0] < pairs[b-1][0]; b-- {
+			pairs[b], pairs[b-1] = pairs[b-1], pairs[b]
+		}
+	}
+	return pairs
+}
+
+// bidiBrackets maps opening brackets to their closing brackets.
+var bidiBrackets = map[rune]rune{
+	'(': ')', '[': ']', '{': '}', 0x2045: 0x2046, 0x207D: 0x207E, 0x208D: 0x208E,
+	0x2329: 0x232A, 0x27E6: 0x27E7, 0x27E8: 0x27E9, 0x27EA: 0x27EB, 0x2983: 0x2984,
+	0x3008: 0x3009, 0x300A: 0x300B, 0x300C: 0x300D, 0x300E: 0x300F, 0x3010: 0x3011,
+	0x3014: 0x3015, 0x3016: 0x3017, 0x3018: 0x3019, 0x301A: 0x301B,
+	0xFF08: 0xFF09, 0xFF3B: 0xFF3D, 0xFF5B: 0xFF5D, 0xFF5F: 0xFF60, 0xFF62: 0xFF63,
+}
+
+// bidiMirrors maps characters to their mirrored glyphs (L4). Both
+// directions are listed.
+var bidiMirrors = func() map[rune]rune {
+	pairs := []rune{
+		'(', ')', '[', ']', '{', '}', '<', '>', 0x00AB, 0x00BB, 0x2039, 0x203A,
+		0x2045, 0x2046, 0x207D, 0x207E, 0x208D, 0x208E, 0x2208, 0x220B, 0x2264, 0x2265,
+		0x2266, 0x2267, 0x226A, 0x226B, 0x2282, 0x2283, 0x2286, 0x2287, 0x2329, 0x232A,
+		0x27E6, 0x27E7, 0x27E8, 0x27E9, 0x27EA, 0x27EB, 0x2983, 0x2984,
+		0x3008, 0x3009, 0x300A, 0x300B, 0x300C, 0x300D, 0x300E, 0x300F, 0x3010, 0x3011,
+		0x3014, 0x3015, 0x3016, 0x3017, 0x3018, 0x3019, 0x301A, 0x301B,
+		0xFF08, 0xFF09, 0xFF1C, 0xFF1E, 0xFF3B, 0xFF3D, 0xFF5B, 0xFF5D, 0xFF5F, 0xFF60, 0xFF62, 0xFF63,
+	}
+	m := make(map[rune]rune, len(pairs))
+	for i := 0; i < len(pairs); i += 2 {
+		m[pairs[i]], m[pairs[i+1]] = pairs[i+1], pairs[i]
+	}
+	return m
+}()
+
+// bidiReorder returns runes in visual order (L2), with formatting
+// characters removed and mirrored characters at odd levels mirrored (L4).
+func bidiReorder(runes []rune, levels []uint8) string {
+	var vis []rune
+	var lv []uint8
+	for i, r := range runes {
+		if c := bidiClassOf(r); c == bcBN || c >= bcLRE || r == 0x200E || r == 0x200F || r == 0x061C {
+			continue
+		}
+		if levels[i]%2 == 1 {
+			if m, ok := bidiMirrors[r]; ok {
+				r = m
+			}
+		}
+		vis = append(vis, r)
+		lv = append(lv, levels[i])
+	}
+	var highest, lowestOdd uint8 = 0, 255
+	for _, l := range lv {
+		if l > highest {
+			highest = l
+		}
+		if l%2 == 1 && l < lowestOdd {
+			lowestOdd = l
+		}
+	}
+	for level := highest; level >= lowestOdd && level > 0; level-- {
+		for i := 0; i < len(vis); {
+			if lv[i] < level {
+				i++
+				continue
+			}
+			j := i
+			for j < len(vis) && lv[j] >= level {
+				j++
+			}
+			for a, b := i, j-1; a < b; a, b = a+1, b-1 {
+				vis[a], vis[b] = vis[b], vis[a]
+				lv[a], lv[b] = lv[b], lv[a]
+			}
+			i = j
+		}
+	}
+	return string(vis)
+}
+
+// bidiLines reorders each line for display with the direction of the
+// paragraph text they were broken from.
+func bidiLines(lines []string, text string, dir TextDirection) []string {
+	if dir == TextDirectionNone {
+		return lines
+	}
+	dir = paragraphDirection(text, dir)
+	out := make([]string, len(lines))
+	for i, line := range lines {
+		out[i], _ = BidiVisual(line, dir)
+	}
+	return out
+}
+
+// hasHorizontalAlign reports whether align has a horizontal alignment.
+func hasHorizontalAlign(align int) bool {
+	return align&(Left|Right|Center|Justify) != 0
+}
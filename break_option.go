@@ -13,6 +13,12 @@ const (
 	// BreakIndicator. If no indicator sensitive break can be performed a strict break will be performed,
 	// potentially working with the given separator as a suffix.
 	BreakModeIndicatorSensitive
+
+	// BreakModeUnicode breaks lines at the break opportunities of the Unicode line breaking algorithm (UAX #14):
+	// after spaces and hyphens, between CJK ideographs and kana, and at zero width spaces, but never before
+	// closing punctuation and small kana or after opening brackets (kinsoku shori), and never at non-breaking
+	// spaces. Newlines are mandatory breaks. Words wider than the line are broken strictly with the separator.
+	BreakModeUnicode
 )
 
 var (
@@ -32,6 +38,14 @@ type BreakOption struct {
 	BreakIndicator rune
 	// Separator will act as a suffix for mid-word breaks when using strict mode
 	Separator string
+	// Hyphenator hyphenates words that do not fit into the line when using indicator sensitive or unicode mode.
+	// Words with soft hyphens (U+00AD) are only broken at their soft hyphens.
+	Hyphenator *Hyphenator
+	// HyphenMinLeft and HyphenMinRight are the minimum number of letters before and after a hyphenation point
+	// (default 2 and 3)
+	HyphenMinLeft, HyphenMinRight int
+	// Hyphen is appended to lines broken at a hyphenation point or soft hyphen (default '-')
+	Hyphen rune
 }
 
 func (bo BreakOption) HasSeparator() bool {
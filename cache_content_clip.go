@@ -22,3 +22,41 @@ func (c *cacheContentClipPolygon) write(w io.Writer, protection *PDFProtection)
 	fmt.Fprint(w, "h W n\n") // h=close path, W=clip, n=end path
 	return nil
 }
+
+// pathSegment is a single path construction operator used by clipping paths
+// that may contain curves.
+type pathSegment struct {
+	op     byte    // 'M' moveto, 'L' lineto, 'C' curveto, 'Z' closepath
+	points []Point // 1 point for M/L, 3 points for C, none for Z
+}
+
+type cacheContentClipPath struct {
+	pageHeight float64
+	segments   []pathSegment
+}
+
+func (c *cacheContentClipPath) write(w io.Writer, protection *PDFProtection) error {
+	writePathSegments(w, c.segments, c.pageHeight)
+	fmt.Fprint(w, "W n\n") // W=clip (nonzero), n=end path
+	return nil
+}
+
+// writePathSegments writes the path construction operators of segs,
+// flipping y coordinates from top-left origin to PDF user space.
+func writePathSegments(w io.Writer, segs []pathSegment, pageHeight float64) {
+	for _, s := range segs {
+		switch s.op {
+		case 'M':
+			fmt.Fprintf(w, "%.2f %.2f m ", s.points[0].X, pageHeight-s.points[0].Y)
+		case 'L':
+			fmt.Fprintf(w, "%.2f %.2f l ", s.points[0].X, pageHeight-s.points[0].Y)
+		case 'C':
+			fmt.Fprintf(w, "%.2f %.2f %.2f %.2f %.2f %.2f c ",
+				s.points[0].X, pageHeight-s.points[0].Y,
+				s.points[1].X, pageHeight-s.points[1].Y,
+				s.points[2].X, pageHeight-s.points[2].Y)
+		case 'Z':
+			fmt.Fprint(w, "h ")
+		}
+	}
+}
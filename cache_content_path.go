@@ -0,0 +1,35 @@
+package gopdf
+
+import (
+	"fmt"
+	"io"
+)
+
+// cacheContentPath paints a path made of lines and Bézier curves.
+type cacheContentPath struct {
+	pageHeight float64
+	style      string
+	segments   []pathSegment
+	opts       polygonOptions
+}
+
+func (c *cacheContentPath) write(w io.Writer, protection *PDFProtection) error {
+	fmt.Fprintf(w, "q\n")
+	for _, extGStateIndex := range c.opts.extGStateIndexes {
+		fmt.Fprintf(w, "/GS%d gs\n", extGStateIndex)
+	}
+
+	writePathSegments(w, c.segments, c.pageHeight)
+
+	// open subpaths stay open: only closepath segments close them
+	if c.style == "F" {
+		fmt.Fprintf(w, "f\n")
+	} else if c.style == "FD" || c.style == "DF" {
+		fmt.Fprintf(w, "B\n")
+	} else {
+		fmt.Fprintf(w, "S\n")
+	}
+
+	fmt.Fprintf(w, "Q\n")
+	return nil
+}
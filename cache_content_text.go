@@ -6,6 +6,8 @@ import (
 	"io"
 	"math"
 	"strconv"
+	"strings"
+	"unicode/utf8"
 )
 
 const defaultCoefLineHeight = float64(1)
@@ -30,6 +32,7 @@ type cacheContentText struct {
 	fontSize       float64
 	fontStyle      int
 	charSpacing    float64
+	textState      textState
 	setXCount      int //จำนวนครั้งที่ใช้ setX
 	x, y           float64
 	fontSubset     *SubsetFontObj
@@ -58,6 +61,7 @@ func (c *cacheContentText) isSame(cache cacheContentText) bool {
 		c.fontSize == cache.fontSize &&
 		c.fontStyle == cache.fontStyle &&
 		c.charSpacing == cache.charSpacing &&
+		c.textState == cache.textState &&
 		c.setXCount == cache.setXCount &&
 		c.y == cache.y &&
 		c.isPlaceHolder == cache.isPlaceHolder {
@@ -81,11 +85,11 @@ func convertTypoUnit(val float64, unitsPerEm uint, fontSize float64) float64 {
 }
 
 func (c *cacheContentText) calTypoAscender() float64 {
-	return convertTypoUnit(float64(c.fontSubset.ttfp.TypoAscender()), c.fontSubset.ttfp.UnitsPerEm(), float64(c.fontSize))
+	return convertTypoUnit(float64(c.fontSubset.typoAscender()), c.fontSubset.unitsPerEm(), float64(c.fontSize))
 }
 
 func (c *cacheContentText) calTypoDescender() float64 {
-	return convertTypoUnit(float64(c.fontSubset.ttfp.TypoDescender()), c.fontSubset.ttfp.UnitsPerEm(), float64(c.fontSize))
+	return convertTypoUnit(float64(c.fontSubset.typoDescender()), c.fontSubset.unitsPerEm(), float64(c.fontSize))
 }
 
 func (c *cacheContentText) calY() (float64, error) {
@@ -150,21 +154,39 @@ func (c *cacheContentText) write(w io.Writer, protection *PDFProtection) error {
 		}
 	}
 
+	isolated := c.textState.isolated()
+	if isolated {
+		if _, err := io.WriteString(w, "q\n"); err != nil {
+			return err
+		}
+	}
+	if err := c.textState.writeGraphics(w, protection); err != nil {
+		return err
+	}
+
 	if _, err := io.WriteString(w, "BT\n"); err != nil {
 		return err
 	}
 
+	// justification is in points of the page, and Tz scales the spacing
+	wordSpacing, charSpacing := c.justification()
+	scale := c.textState.horizontalScale()
+	wordSpacing, charSpacing = wordSpacing/scale, charSpacing/scale
 	fmt.Fprintf(w, "%0.2f %0.2f TD\n", x, y)
-	fmt.Fprintf(w, "/F%d %s Tf %s Tc\n", c.fontCountIndex, FormatFloatTrim(c.fontSize), FormatFloatTrim(c.charSpacing))
+	fmt.Fprintf(w, "/F%d %s Tf %s Tc\n", c.fontCountIndex, FormatFloatTrim(c.fontSize), FormatFloatTrim(c.charSpacing+charSpacing))
+	if err := c.textState.write(w); err != nil {
+		return err
+	}
 
 	if c.txtColorMode == "color" {
 		c.textColor.write(w, protection)
 	}
 	io.WriteString(w, "[<")
 
-	unitsPerEm := int(c.fontSubset.ttfp.UnitsPerEm())
+	unitsPerEm := int(c.fontSubset.unitsPerEm())
 	var leftRune rune
 	var leftRuneIndex uint
+	lastSpace := len(strings.TrimRight(c.text, " "))
 	for i, r := range c.text {
 
 		glyphindex, err := c.fontSubset.CharIndex(r)
@@ -174,6 +196,16 @@ func (c *cacheContentText) write(w io.Writer, protection *PDFProtection) error {
 			return err
 		}
 
+		if leftRune == ' ' { //word spacing, and justified word spacing between words
+			spacing := c.textState.wordSpacing
+			if i < lastSpace {
+				spacing += wordSpacing
+			}
+			if spacing != 0 {
+				fmt.Fprintf(w, ">%s<", FormatFloatTrim(-spacing*1000/c.fontSize))
+			}
+		}
+
 		pairvalPdfUnit := 0
 		if i > 0 && c.fontSubset.ttfFontOption.UseKerning { //kerning
 			pairval := kern(c.fontSubset, leftRune, r, leftRuneIndex, glyphindex)
@@ -183,7 +215,11 @@ func (c *cacheContentText) write(w io.Writer, protection *PDFProtection) error {
 			}
 		}
 
-		fmt.Fprintf(w, "%04X", glyphindex)
+		if c.fontSubset.standard != nil {
+			fmt.Fprintf(w, "%02X", glyphindex)
+		} else {
+			fmt.Fprintf(w, "%04X", glyphindex)
+		}
 		leftRune = r
 		leftRuneIndex = glyphindex
 	}
@@ -191,10 +227,30 @@ func (c *cacheContentText) write(w io.Writer, protection *PDFProtection) error {
 	io.WriteString(w, ">] TJ\n")
 	io.WriteString(w, "ET\n")
 
-	if c.fontStyle&Underline == Underline {
-		if err := c.underline(w); err != nil {
+	if c.textState.paints() {
+		if c.fontStyle&Underline == Underline {
+			if err := c.underline(w); err != nil {
+				return err
+			}
+		}
+		if c.fontStyle&Strikethrough == Strikethrough {
+			if err := c.strikethrough(w, y); err != nil {
+				return err
+			}
+		}
+		if c.fontStyle&Overline == Overline {
+			if err := c.overline(w, y); err != nil {
+				return err
+			}
+		}
+	}
+
+	if isolated {
+		if _, err := io.WriteString(w, "Q\n"); err != nil {
 			return err
 		}
+	} else if err := c.textState.reset(w); err != nil {
+		return err
 	}
 
 	c.drawBorder(w)
@@ -202,6 +258,27 @@ func (c *cacheContentText) write(w io.Writer, protection *PDFProtection) error {
 	return nil
 }
 
+// justification returns the space added after each space between words
+// and after each character of justified text, in points. Text without
+// spaces, such as CJK text, is spread between its characters.
+func (c *cacheContentText) justification() (wordSpacing, charSpacing float64) {
+	if c.cellOpt.Align&Justify != Justify || c.contentType != ContentTypeCell || c.rectangle == nil {
+		return 0, 0
+	}
+	slack := c.cellWidthPdfUnit - c.textWidthPdfUnit
+	if slack <= 0 {
+		return 0, 0
+	}
+	text := strings.TrimRight(c.text, " ")
+	if n := strings.Count(text, " "); n > 0 {
+		return slack / float64(n), 0
+	}
+	if n := utf8.RuneCountInString(text); n > 1 {
+		return 0, slack / float64(n-1)
+	}
+	return 0, 0
+}
+
 func (c *cacheContentText) drawBorder(w io.Writer) error {
 
 	//stream.WriteString(fmt.Sprintf("%.2f w\n", 0.1))
@@ -295,12 +372,40 @@ func (c *cacheContentText) underline(w io.Writer) error {
 	return nil
 }
 
+// strikethrough strikes the text through at the strikeout position of the
+// font above baseline, the y of the baseline of the text.
+func (c *cacheContentText) strikethrough(w io.Writer, baseline float64) error {
+	if c.fontSubset == nil {
+		return errors.New("error AppendStrikethrough not found font")
+	}
+	thickness := c.fontSubset.GetStrikeoutThicknessPx(c.fontSize)
+	y := baseline + c.textState.rise + c.fontSubset.GetStrikeoutPositionPx(c.fontSize) - thickness
+	_, err := fmt.Fprintf(w, "%0.2f %0.2f %0.2f %0.2f re f\n", c.x, y, c.cellWidthPdfUnit, thickness)
+	return err
+}
+
+// overline draws a line over the text on its typographic ascender above
+// baseline, the y of the baseline of the text.
+func (c *cacheContentText) overline(w io.Writer, baseline float64) error {
+	if c.fontSubset == nil {
+		return errors.New("error AppendOverline not found font")
+	}
+	thickness := c.fontSubset.GetUnderlineThicknessPx(c.fontSize)
+	y := baseline + c.textState.rise + c.calTypoAscender()
+	_, err := fmt.Fprintf(w, "%0.2f %0.2f %0.2f %0.2f re f\n", c.x, y, c.cellWidthPdfUnit, thickness)
+	return err
+}
+
 func (c *cacheContentText) createContent() (float64, float64, error) {
 
 	cellWidthPdfUnit, cellHeightPdfUnit, textWidthPdfUnit, err := createContent(c.fontSubset, c.text, c.fontSize, c.charSpacing, c.rectangle)
 	if err != nil {
 		return 0, 0, err
 	}
+	textWidthPdfUnit = c.textState.width(c.text, textWidthPdfUnit)
+	if c.rectangle == nil {
+		cellWidthPdfUnit = textWidthPdfUnit
+	}
 	c.cellWidthPdfUnit = cellWidthPdfUnit
 	c.cellHeightPdfUnit = cellHeightPdfUnit
 	c.textWidthPdfUnit = textWidthPdfUnit
@@ -309,7 +414,7 @@ func (c *cacheContentText) createContent() (float64, float64, error) {
 
 func createContent(f *SubsetFontObj, text string, fontSize float64, charSpacing float64, rectangle *Rect) (float64, float64, float64, error) {
 
-	unitsPerEm := int(f.ttfp.UnitsPerEm())
+	unitsPerEm := int(f.unitsPerEm())
 	var leftRune rune
 	var leftRuneIndex uint
 	sumWidth := int(0)
@@ -347,8 +452,8 @@ func createContent(f *SubsetFontObj, text string, fontSize float64, charSpacing
 	cellHeightPdfUnit := float64(0)
 	if rectangle == nil {
 		cellWidthPdfUnit = float64(sumWidth) * (float64(fontSize) / 1000.0)
-		typoAscender := convertTypoUnit(float64(f.ttfp.TypoAscender()), f.ttfp.UnitsPerEm(), float64(fontSize))
-		typoDescender := convertTypoUnit(float64(f.ttfp.TypoDescender()), f.ttfp.UnitsPerEm(), float64(fontSize))
+		typoAscender := convertTypoUnit(float64(f.typoAscender()), f.unitsPerEm(), float64(fontSize))
+		typoDescender := convertTypoUnit(float64(f.typoDescender()), f.unitsPerEm(), float64(fontSize))
 		cellHeightPdfUnit = typoAscender - typoDescender
 	} else {
 		cellWidthPdfUnit = rectangle.W
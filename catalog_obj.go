@@ -16,9 +16,15 @@ type CatalogObj struct { //impl IObj
 	markInfoObjID      int // index of MarkInfo object (-1 = none)
 	pageLayout         string
 	pageMode           string
+	openAction         *Action // /OpenAction
+	actions            *DocumentActions // /AA
+	threadObjIDs       []int            // /Threads
+	baseURI            string  // /URI /Base
+	getRoot            func() *GoPdf
 }
 
 func (c *CatalogObj) init(funcGetRoot func() *GoPdf) {
+	c.getRoot = funcGetRoot
 	c.outlinesObjID = -1
 	c.namesObjID = -1
 	c.pageLabelsObjID = -1
@@ -58,12 +64,35 @@ func (c *CatalogObj) write(w io.Writer, objID int) error {
 	if c.markInfoObjID >= 0 {
 		fmt.Fprintf(w, "  /MarkInfo %d 0 R\n", c.markInfoObjID)
 	}
+	if len(c.threadObjIDs) > 0 {
+		io.WriteString(w, "  /Threads [")
+		for _, id := range c.threadObjIDs {
+			fmt.Fprintf(w, "%d 0 R ", id)
+		}
+		io.WriteString(w, "]\n")
+	}
 	if c.pageLayout != "" {
 		fmt.Fprintf(w, "  /PageLayout /%s\n", c.pageLayout)
 	}
 	if c.pageMode != "" && c.outlinesObjID < 0 {
 		fmt.Fprintf(w, "  /PageMode /%s\n", c.pageMode)
 	}
+	if c.openAction != nil || c.baseURI != "" || c.actions != nil {
+		aw := actionWriter{c.getRoot(), objID}
+		if c.openAction != nil {
+			if a := aw.dict(*c.openAction); a != "" {
+				fmt.Fprintf(w, "  /OpenAction %s\n", a)
+			}
+		}
+		if c.baseURI != "" {
+			fmt.Fprintf(w, "  /URI << /Base %s >>\n", aw.str(c.baseURI))
+		}
+		if c.actions != nil {
+			if aa := aw.additionalActions(c.actions.entries()); aa != "" {
+				fmt.Fprintf(w, "  /AA %s\n", aa)
+			}
+		}
+	}
 	io.WriteString(w, ">>\n")
 	return nil
 }
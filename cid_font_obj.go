@@ -37,12 +37,16 @@ func (ci *CIDFontObj) write(w io.Writer, objID int) error {
 	io.WriteString(w, "/Subtype /CIDFontType2\n")
 	io.WriteString(w, "/Type /Font\n")
 	glyphIndexs := ci.PtrToSubsetFontObj.CharacterToGlyphIndex.AllVals()
+	glyphIndexs = append(glyphIndexs, ci.PtrToSubsetFontObj.verticalGlyphs()...)
 	io.WriteString(w, "/W [")
 	for _, v := range glyphIndexs {
 		width := ci.PtrToSubsetFontObj.GlyphIndexToPdfWidth(v)
 		fmt.Fprintf(w, "%d[%d]", v, width)
 	}
 	io.WriteString(w, "]\n")
+	if ci.PtrToSubsetFontObj.vertical != nil {
+		ci.writeVerticalMetrics(w, glyphIndexs)
+	}
 	io.WriteString(w, ">>\n")
 	return nil
 }
@@ -51,3 +55,19 @@ func (ci *CIDFontObj) write(w io.Writer, objID int) error {
 func (ci *CIDFontObj) SetPtrToSubsetFontObj(ptr *SubsetFontObj) {
 	ci.PtrToSubsetFontObj = ptr
 }
+
+// writeVerticalMetrics writes the vertical metrics of a font used in
+// vertical writing: the vertical origin at the ascender and an advance of
+// one em by default, and the metrics of the vmtx table of glyphIndexs.
+func (ci *CIDFontObj) writeVerticalMetrics(w io.Writer, glyphIndexs []uint) {
+	sub := ci.PtrToSubsetFontObj
+	ascender := sub.typoAscender() * 1000 / int(sub.unitsPerEm())
+	fmt.Fprintf(w, "/DW2 [%d -1000]\n", ascender)
+	io.WriteString(w, "/W2 [")
+	for _, v := range glyphIndexs {
+		if advance, originY, ok := verticalAdvance(sub, v); ok {
+			fmt.Fprintf(w, "%d[%d %d %d]", v, -advance, sub.GlyphIndexToPdfWidth(v)/2, originY)
+		}
+	}
+	io.WriteString(w, "]\n")
+}
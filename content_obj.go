@@ -124,6 +124,7 @@ func (c *ContentObj) appendStreamPlaceHolderText(placeHolderWidth float64) error
 		fontSize:       fontSize,
 		fontStyle:      fontStyle,
 		charSpacing:    charSpacing,
+		textState:      c.getRoot().curr.textState,
 		setXCount:      setXCount,
 		x:              x,
 		y:              y,
@@ -172,6 +173,7 @@ func (c *ContentObj) AppendStreamText(text string) error {
 		fontSize:       fontSize,
 		fontStyle:      fontStyle,
 		charSpacing:    charSpacing,
+		textState:      c.getRoot().curr.textState,
 		setXCount:      setXCount,
 		x:              x,
 		y:              y,
@@ -214,6 +216,7 @@ func (c *ContentObj) AppendStreamSubsetFont(rectangle *Rect, text string, cellOp
 		fontSize:       fontSize,
 		fontStyle:      fontStyle,
 		charSpacing:    charSpacing,
+		textState:      c.getRoot().curr.textState,
 		setXCount:      setXCount,
 		x:              x,
 		y:              y,
@@ -422,6 +425,17 @@ func (c *ContentObj) AppendStreamPolygon(points []Point, style string, opts poly
 	c.listCache.append(&cache)
 }
 
+// AppendStreamPath appends a path of lines and Bézier curves, painted
+// with style ("F", "D" or "FD").
+func (c *ContentObj) AppendStreamPath(segments []pathSegment, style string, opts polygonOptions) {
+	var cache cacheContentPath
+	cache.segments = segments
+	cache.style = style
+	cache.pageHeight = c.getRoot().curr.pageSize.H
+	cache.opts = opts
+	c.listCache.append(&cache)
+}
+
 // AppendStreamPolyline appends a polyline (open path, stroke only).
 func (c *ContentObj) AppendStreamPolyline(points []Point, opts polylineOptions) {
 	var cache cacheContentPolyline
@@ -453,6 +467,14 @@ func (c *ContentObj) AppendStreamClipPolygon(points []Point) {
 	c.listCache.append(&cache)
 }
 
+// AppendStreamClipPath sets a clipping path made of lines and Bézier curves.
+func (c *ContentObj) AppendStreamClipPath(segments []pathSegment) {
+	var cache cacheContentClipPath
+	cache.segments = segments
+	cache.pageHeight = c.getRoot().curr.pageSize.H
+	c.listCache.append(&cache)
+}
+
 // AppendStreamSaveGraphicsState saves the current graphics state (q operator).
 func (c *ContentObj) AppendStreamSaveGraphicsState() {
 	c.listCache.append(&cacheContentSaveGraphicsState{})
@@ -21,6 +21,8 @@ type Current struct {
 
 	CharSpacing float64
 
+	textState textState // render mode, rise, scaling, word spacing and stroke of text
+
 	FontISubset *SubsetFontObj // FontType == CURRENT_FONT_TYPE_SUBSET
 
 	//page
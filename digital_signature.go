@@ -334,16 +334,7 @@ func createPKCS7Signature(data []byte, cfg *SignatureConfig) ([]byte, error) {
 
 // findPageObjByNumber returns the PageObj for the given 1-based page number.
 func (gp *GoPdf) findPageObjByNumber(pageNo int) *PageObj {
-	count := 0
-	for _, obj := range gp.pdfObjs {
-		if p, ok := obj.(*PageObj); ok {
-			count++
-			if count == pageNo {
-				return p
-			}
-		}
-	}
-	return nil
+	return gp.findPageObj(pageNo)
 }
 
 // AddSignatureField adds an empty (unsigned) signature field to the current page.
@@ -96,7 +96,7 @@ func (gp *GoPdf) GetFonts() []FontInfo {
 			fonts = append(fonts, FontInfo{
 				Family:     o.GetFamily(),
 				Style:      0,
-				IsEmbedded: true,
+				IsEmbedded: o.standard == nil,
 				Index:      i,
 			})
 		}
@@ -0,0 +1,316 @@
+package gopdf
+
+import (
+	"unicode"
+)
+
+// ============================================================
+// Font fallback chains
+// ============================================================
+
+// SetFontFallback sets the families, in order, whose fonts draw the
+// characters that the fonts of family lack. Text, Cell, CellWithOption,
+// MultiCell and MeasureTextWidth split text into runs by glyph coverage
+// and switch fonts transparently. A fallback family is used in the style
+// of the current font when it is loaded in that style, and in Regular
+// otherwise. Characters that no font of the chain covers are substituted
+// by the current font (see TtfOption.OnGlyphNotFoundSubstitute). Calling
+// SetFontFallback without fallbacks removes the chain of family.
+//
+// Example:
+//
+//	pdf.AddTTFFont("Roboto", "Roboto-Regular.ttf")
+//	pdf.AddTTFFont("NotoSansCJK", "NotoSansCJKsc-Regular.ttf")
+//	pdf.AddTTFFont("NotoEmoji", "NotoEmoji-Regular.ttf")
+//	pdf.SetFontFallback("Roboto", "NotoSansCJK", "NotoEmoji")
+//	pdf.SetFont("Roboto", "", 14)
+//	pdf.Cell(nil, "Hello 世界")
+func (gp *GoPdf) SetFontFallback(family string, fallbacks ...string) error {
+	for _, f := range append([]string{family}, fallbacks...) {
+		if !gp.hasFontFamily(f) {
+			return ErrMissingFontFamily
+		}
+	}
+	if len(fallbacks) == 0 {
+		delete(gp.fontFallbacks, family)
+		return nil
+	}
+	if gp.fontFallbacks == nil {
+		gp.fontFallbacks = make(map[string][]string)
+	}
+	gp.fontFallbacks[family] = append([]string(nil), fallbacks...)
+	return nil
+}
+
+// GetFontFallback returns the fallback families of family set with
+// SetFontFallback.
+func (gp *GoPdf) GetFontFallback(family string) []string {
+	return append([]string(nil), gp.fontFallbacks[family]...)
+}
+
+// GlyphFontFamily reports which family of the current font and its
+// fallback chain contains a glyph for r. ok is false when none does.
+func (gp *GoPdf) GlyphFontFamily(r rune) (family string, ok bool, err error) {
+	for _, sub := range gp.fontChain() {
+		found, err := fontContainsGlyph(sub, r)
+		if err != nil {
+			return "", false, err
+		}
+		if found {
+			return sub.GetFamily(), true, nil
+		}
+	}
+	return "", false, nil
+}
+
+// hasFontFamily reports whether a font of family is loaded in any style.
+func (gp *GoPdf) hasFontFamily(family string) bool {
+	for _, obj := range gp.pdfObjs {
+		if sub, ok := obj.(*SubsetFontObj); ok && sub.GetFamily() == family {
+			return true
+		}
+	}
+	return false
+}
+
+// findSubsetFont returns the font of family in style, or nil.
+func (gp *GoPdf) findSubsetFont(family string, style int) *SubsetFontObj {
+	for _, obj := range gp.pdfObjs {
+		if sub, ok := obj.(*SubsetFontObj); ok && sub.GetFamily() == family && sub.GetTtfFontOption().Style == style&^decorations {
+			return sub
+		}
+	}
+	return nil
+}
+
+// fontChain returns the current font followed by the fonts of its
+// fallback families.
+func (gp *GoPdf) fontChain() []*SubsetFontObj {
+	primary := gp.curr.FontISubset
+	if primary == nil {
+		return nil
+	}
+	chain := []*SubsetFontObj{primary}
+	for _, family := range gp.fontFallbacks[primary.GetFamily()] {
+		sub := gp.findSubsetFont(family, gp.curr.FontStyle)
+		if sub == nil {
+			sub = gp.findSubsetFont(family, Regular)
+		}
+		if sub != nil {
+			chain = append(chain, sub)
+		}
+	}
+	return chain
+}
+
+// fontContainsGlyph reports whether sub has a glyph for r.
+func fontContainsGlyph(sub *SubsetFontObj, r rune) (bool, error) {
+	glyphIndex, err := sub.CharCodeToGlyphIndex(r)
+	if err == ErrGlyphNotFound {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	return glyphIndex != 0, nil
+}
+
+// fontRun is a run of text drawn with one font.
+type fontRun struct {
+	font *SubsetFontObj
+	text string
+}
+
+// fontRuns splits text into runs of the fonts of the current fallback
+// chain. The text of each run has been added to its font. Text without a
+// fallback chain is a single run of the current font.
+func (gp *GoPdf) fontRuns(text string) ([]fontRun, error) {
+	return chainFontRuns(gp.fontChain(), gp.curr.FontISubset, text)
+}
+
+// chainFontRuns splits text into runs of the fonts of chain, or into a
+// single run of primary when chain has no fallback fonts.
+func chainFontRuns(chain []*SubsetFontObj, primary *SubsetFontObj, text string) ([]fontRun, error) {
+	if len(chain) <= 1 || text == "" {
+		text, err := primary.AddChars(text)
+		if err != nil {
+			return nil, err
+		}
+		return []fontRun{{font: primary, text: text}}, nil
+	}
+	var err error
+	fonts, texts := splitFontRuns(text, len(chain), func(font int, r rune) bool {
+		if err != nil {
+			return false
+		}
+		var found bool
+		found, err = fontContainsGlyph(chain[font], r)
+		return found
+	})
+	if err != nil {
+		return nil, err
+	}
+	runs := make([]fontRun, len(texts))
+	for i, t := range texts {
+		t, err := chain[fonts[i]].AddChars(t)
+		if err != nil {
+			return nil, err
+		}
+		runs[i] = fontRun{font: chain[fonts[i]], text: t}
+	}
+	return runs, nil
+}
+
+// splitFontRuns assigns each rune of text to the first of n fonts that
+// covers it, or to font 0 when none does. Spaces, marks and format
+// characters stay in the run before them when its font covers them. It
+// returns the font and the text of each run.
+func splitFontRuns(text string, n int, covers func(font int, r rune) bool) (fonts []int, texts []string) {
+	start, cur := 0, -1
+	for i, r := range text {
+		font := -1
+		if cur >= 0 && (unicode.IsSpace(r) || unicode.In(r, unicode.Mn, unicode.Me, unicode.Cf)) && covers(cur, r) {
+			font = cur
+		}
+		for f := 0; font < 0 && f < n; f++ {
+			if covers(f, r) {
+				font = f
+			}
+		}
+		if font < 0 {
+			font = 0
+		}
+		if font != cur {
+			if cur >= 0 {
+				fonts = append(fonts, cur)
+				texts = append(texts, text[start:i])
+			}
+			start, cur = i, font
+		}
+	}
+	if cur >= 0 {
+		fonts = append(fonts, cur)
+		texts = append(texts, text[start:])
+	}
+	return fonts, texts
+}
+
+// withFont runs fn with sub as the current font.
+func (gp *GoPdf) withFont(sub *SubsetFontObj, fn func() error) error {
+	font, count := gp.curr.FontISubset, gp.curr.FontFontCount
+	gp.curr.FontISubset, gp.curr.FontFontCount = sub, sub.CountOfFont
+	defer func() { gp.curr.FontISubset, gp.curr.FontFontCount = font, count }()
+	return fn()
+}
+
+// runsWidth returns the width of runs in points.
+func (gp *GoPdf) runsWidth(runs []fontRun) ([]float64, float64, error) {
+	widths := make([]float64, len(runs))
+	total := 0.0
+	for i, run := range runs {
+		_, _, w, err := createContent(run.font, run.text, gp.curr.FontSize, gp.curr.CharSpacing, nil)
+		if err != nil {
+			return nil, 0, err
+		}
+		w = gp.curr.textState.width(run.text, w)
+		widths[i] = w
+		total += w
+	}
+	return widths, total, nil
+}
+
+// appendTextRuns draws runs of text from the current position, like
+// Text.
+func (gp *GoPdf) appendTextRuns(runs []fontRun) error {
+	for _, run := range runs {
+		run := run
+		if err := gp.withFont(run.font, func() error {
+			return gp.getContent().AppendStreamText(run.text)
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// appendCellRuns draws runs of text as one cell of rectangle (in points)
+// with opt. The cell, its border and underline are drawn with the current
+// font, and the runs are placed by the horizontal alignment of opt with
+// their baselines aligned to the baseline of the current font.
+func (gp *GoPdf) appendCellRuns(rectangle *Rect, runs []fontRun, opt CellOption) error {
+	widths, total, err := gp.runsWidth(runs)
+	if err != nil {
+		return err
+	}
+	primary := gp.curr.FontISubset
+	if rectangle == nil {
+		_, h, _, err := createContent(primary, "", gp.curr.FontSize, gp.curr.CharSpacing, nil)
+		if err != nil {
+			return err
+		}
+		rectangle = &Rect{W: total, H: h}
+	}
+
+	x, y := gp.curr.X, gp.curr.Y
+	if err := gp.getContent().AppendStreamSubsetFont(rectangle, "", opt); err != nil {
+		return err
+	}
+	endX, endY := gp.curr.X, gp.curr.Y
+
+	offset := 0.0
+	if opt.Align&Right == Right {
+		offset = rectangle.W - total
+	} else if opt.Align&Center == Center {
+		offset = (rectangle.W - total) / 2
+	}
+	runOpt := opt
+	runOpt.Align = opt.Align & (Top | Bottom | Middle)
+	runOpt.Border = 0
+	runOpt.Float = Right
+	style := gp.curr.FontStyle
+	gp.curr.FontStyle &^= decorations // drawn with the cell
+	defer func() { gp.curr.FontStyle = style }()
+	for i, run := range runs {
+		run := run
+		gp.curr.X = x + offset
+		gp.curr.Y = y + baselineShift(primary, run.font, gp.curr.FontSize, opt.Align)
+		if err := gp.withFont(run.font, func() error {
+			return gp.getContent().AppendStreamSubsetFont(&Rect{W: widths[i], H: rectangle.H}, run.text, runOpt)
+		}); err != nil {
+			return err
+		}
+		offset += widths[i]
+	}
+	gp.curr.X, gp.curr.Y = endX, endY
+	return nil
+}
+
+// baselineShift returns how far down to move a cell of font so that its
+// baseline meets the baseline of a cell of primary with the vertical
+// alignment of align.
+func baselineShift(primary, font *SubsetFontObj, fontSize float64, align int) float64 {
+	ascender := func(f *SubsetFontObj) float64 {
+		return convertTypoUnit(float64(f.typoAscender()), f.unitsPerEm(), fontSize)
+	}
+	descender := func(f *SubsetFontObj) float64 {
+		return convertTypoUnit(float64(f.typoDescender()), f.unitsPerEm(), fontSize)
+	}
+	if align&Bottom == Bottom {
+		return descender(primary) - descender(font)
+	} else if align&Middle == Middle {
+		return (ascender(primary) + descender(primary) - ascender(font) - descender(font)) / 2
+	}
+	return ascender(primary) - ascender(font)
+}
+
+// appendCell draws text as a cell of rectangle (in points) with opt,
+// switching to the fonts of the fallback chain where needed.
+func (gp *GoPdf) appendCell(rectangle *Rect, text string, opt CellOption) error {
+	runs, err := gp.fontRuns(text)
+	if err != nil {
+		return err
+	}
+	if len(runs) > 1 || runs[0].font != gp.curr.FontISubset {
+		return gp.appendCellRuns(rectangle, runs, opt)
+	}
+	return gp.getContent().AppendStreamSubsetFont(rectangle, runs[0].text, opt)
+}
@@ -12,6 +12,14 @@ const Italic = 1 //000001
 const Bold = 2 //000010
 // Underline - font style underline
 const Underline = 4 //000100
+// Strikethrough - font style strikethrough
+const Strikethrough = 8 //001000
+// Overline - font style overline
+const Overline = 16 //010000
+
+// decorations are the styles drawn as lines with the text rather than
+// chosen by font.
+const decorations = Underline | Strikethrough | Overline
 
 func getConvertedStyle(fontStyle string) (style int) {
 	fontStyle = strings.ToUpper(fontStyle)
@@ -24,5 +32,11 @@ func getConvertedStyle(fontStyle string) (style int) {
 	if strings.Contains(fontStyle, "U") {
 		style = style | Underline
 	}
+	if strings.Contains(fontStyle, "S") {
+		style = style | Strikethrough
+	}
+	if strings.Contains(fontStyle, "O") {
+		style = style | Overline
+	}
 	return
 }
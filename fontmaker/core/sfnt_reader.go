@@ -0,0 +1,110 @@
+package core
+
+import (
+	"encoding/binary"
+	"errors"
+)
+
+var ERROR_MALFORMED_TABLE = errors.New("Malformed font table")
+
+// sfntReader reads big-endian values from font data. Out of range reads
+// return zeros and set err.
+type sfntReader struct {
+	data []byte
+	pos  uint
+	err  error
+}
+
+func newSfntReader(data []byte, pos uint) *sfntReader {
+	return &sfntReader{data: data, pos: pos}
+}
+
+func (r *sfntReader) seek(pos uint) {
+	r.pos = pos
+}
+
+func (r *sfntReader) skip(n int) {
+	r.pos += uint(n)
+}
+
+func (r *sfntReader) bytes(n int) []byte {
+	if r.err != nil || r.pos+uint(n) > uint(len(r.data)) {
+		r.err = ERROR_MALFORMED_TABLE
+		return make([]byte, n)
+	}
+	b := r.data[r.pos : r.pos+uint(n)]
+	r.pos += uint(n)
+	return b
+}
+
+func (r *sfntReader) u8() uint8 {
+	return r.bytes(1)[0]
+}
+
+func (r *sfntReader) u16() uint16 {
+	return binary.BigEndian.Uint16(r.bytes(2))
+}
+
+func (r *sfntReader) u32() uint32 {
+	return binary.BigEndian.Uint32(r.bytes(4))
+}
+
+func (r *sfntReader) fixed() float64 {
+	return float64(int32(r.u32())) / 65536
+}
+
+func (r *sfntReader) f2dot14() float64 {
+	return float64(int16(r.u16())) / 16384
+}
+
+func (r *sfntReader) tuple(axisCount int) []float64 {
+	tuple := make([]float64, axisCount)
+	for i := range tuple {
+		tuple[i] = r.f2dot14()
+	}
+	return tuple
+}
+
+// points reads packed point numbers. It returns nil for all points.
+func (r *sfntReader) points(numPoints int) []int {
+	count := int(r.u8())
+	if count == 0 {
+		return nil
+	}
+	if count&pointsAreWords != 0 {
+		count = (count&pointRunCountMask)<<8 | int(r.u8())
+	}
+	points := make([]int, 0, count)
+	p := 0
+	for len(points) < count && r.err == nil {
+		control := r.u8()
+		for n := int(control&pointRunCountMask) + 1; n > 0 && len(points) < count; n-- {
+			if control&pointsAreWords != 0 {
+				p += int(r.u16())
+			} else {
+				p += int(r.u8())
+			}
+			points = append(points, p)
+		}
+	}
+	return points
+}
+
+// deltas reads count packed deltas.
+func (r *sfntReader) deltas(count int) []float64 {
+	deltas := make([]float64, 0, count)
+	for len(deltas) < count && r.err == nil {
+		control := r.u8()
+		for n := int(control&deltaRunCountMask) + 1; n > 0 && len(deltas) < count; n-- {
+			switch {
+			case control&deltasAreZero != 0:
+				deltas = append(deltas, 0)
+			case control&deltasAreWords != 0:
+				deltas = append(deltas, float64(int16(r.u16())))
+			default:
+				deltas = append(deltas, float64(int8(r.u8())))
+			}
+		}
+	}
+	return deltas
+}
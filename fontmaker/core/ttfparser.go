@@ -48,6 +48,13 @@ type TTFParser struct {
 	capHeight     int
 	sxHeight      int
 
+	subscriptSize     int
+	subscriptOffset   int
+	superscriptSize   int
+	superscriptOffset int
+	strikeoutSize     int
+	strikeoutPosition int
+
 	//post
 	italicAngle        int
 	underlinePosition  int
@@ -77,6 +84,15 @@ type TTFParser struct {
 	//kerning
 	useKerning bool //user config for use or not use kerning
 	kern       *KernTable
+
+	//collection face selection
+	faceIndex int
+	faceName  string
+
+	//variations
+	variation map[string]float64
+	axes      []VariationAxis
+	instance  *glyphInstance
 }
 
 var Symbolic = 1 << 2
@@ -171,6 +187,29 @@ func (t *TTFParser) CapHeight() int {
 	return t.capHeight
 }
 
+// StrikeoutPosition is the position of the top of the strikeout stroke
+// above the baseline, in font units.
+func (t *TTFParser) StrikeoutPosition() int {
+	return t.strikeoutPosition
+}
+
+// StrikeoutSize is the thickness of the strikeout stroke, in font units.
+func (t *TTFParser) StrikeoutSize() int {
+	return t.strikeoutSize
+}
+
+// SubscriptMetrics returns the font size and the offset below the baseline
+// recommended for subscripts, in font units.
+func (t *TTFParser) SubscriptMetrics() (size, offset int) {
+	return t.subscriptSize, t.subscriptOffset
+}
+
+// SuperscriptMetrics returns the font size and the offset above the
+// baseline recommended for superscripts, in font units.
+func (t *TTFParser) SuperscriptMetrics() (size, offset int) {
+	return t.superscriptSize, t.superscriptOffset
+}
+
 // NumGlyphs number of glyph
 func (t *TTFParser) NumGlyphs() uint {
 	return t.numGlyphs
@@ -221,6 +260,7 @@ func (t *TTFParser) ParseByReader(rd io.Reader) error {
 }
 
 // ParseFontData parses font data.
+// Of a TrueType Collection, the face selected with SetCollectionFace is parsed.
 func (t *TTFParser) ParseFontData(fontData []byte) error {
 	fd := bytes.NewReader(fontData)
 
@@ -228,48 +268,28 @@ func (t *TTFParser) ParseFontData(fontData []byte) error {
 	if err != nil {
 		return err
 	}
-	if !bytes.Equal(version, []byte{0x00, 0x01, 0x00, 0x00}) {
-		return errors.New("Unrecognized file (font) format")
-	}
-
-	i := uint(0)
-	numTables, err := t.ReadUShort(fd)
-	if err != nil {
-		return err
-	}
-	err = t.Skip(fd, 3*2) //searchRange, entrySelector, rangeShift
-	if err != nil {
-		return err
-	}
-
-	t.tables = make(map[string]TableDirectoryEntry)
-	for i < numTables {
-
-		tag, err := t.Read(fd, 4)
+	if bytes.Equal(version, []byte("ttcf")) {
+		offset, err := t.selectCollectionFace(fd)
 		if err != nil {
 			return err
 		}
-
-		checksum, err := t.ReadULong(fd)
-		if err != nil {
+		if _, err := fd.Seek(int64(offset), 0); err != nil {
 			return err
 		}
-
-		offset, err := t.ReadULong(fd)
-		if err != nil {
+		if version, err = t.Read(fd, 4); err != nil {
 			return err
 		}
+	}
+	if bytes.Equal(version, []byte("OTTO")) {
+		return ERROR_CFF_OUTLINES
+	}
+	if !bytes.Equal(version, []byte{0x00, 0x01, 0x00, 0x00}) && !bytes.Equal(version, []byte("true")) {
+		return errors.New("Unrecognized file (font) format")
+	}
 
-		length, err := t.ReadULong(fd)
-		if err != nil {
-			return err
-		}
-		var table TableDirectoryEntry
-		table.Offset = uint(offset)
-		table.CheckSum = checksum
-		table.Length = length
-		t.tables[t.BytesToString(tag)] = table
-		i++
+	err = t.parseTableDirectory(fd)
+	if err != nil {
+		return err
 	}
 
 	err = t.ParseHead(fd)
@@ -320,6 +340,61 @@ func (t *TTFParser) ParseFontData(fontData []byte) error {
 
 	t.cachedFontData = fontData
 
+	err = t.ParseFvar(fd)
+	if err != nil {
+		return err
+	}
+	if len(t.variation) > 0 {
+		err = t.instantiate()
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// parseTableDirectory reads the table directory after the sfnt version.
+func (t *TTFParser) parseTableDirectory(fd *bytes.Reader) error {
+	i := uint(0)
+	numTables, err := t.ReadUShort(fd)
+	if err != nil {
+		return err
+	}
+	err = t.Skip(fd, 3*2) //searchRange, entrySelector, rangeShift
+	if err != nil {
+		return err
+	}
+
+	t.tables = make(map[string]TableDirectoryEntry)
+	for i < numTables {
+
+		tag, err := t.Read(fd, 4)
+		if err != nil {
+			return err
+		}
+
+		checksum, err := t.ReadULong(fd)
+		if err != nil {
+			return err
+		}
+
+		offset, err := t.ReadULong(fd)
+		if err != nil {
+			return err
+		}
+
+		length, err := t.ReadULong(fd)
+		if err != nil {
+			return err
+		}
+		var table TableDirectoryEntry
+		table.Offset = uint(offset)
+		table.CheckSum = checksum
+		table.Length = length
+		t.tables[t.BytesToString(tag)] = table
+		i++
+	}
 	return nil
 }
 
@@ -433,7 +508,10 @@ func (t *TTFParser) ParseOS2(fd *bytes.Reader) error {
 	}
 	t.Embeddable = (fsType != 2) && ((fsType & 0x200) == 0)
 
-	err = t.Skip(fd, (11*2)+10+(4*4)+4)
+	if err = t.parseOS2ScriptMetrics(fd); err != nil {
+		return err
+	}
+	err = t.Skip(fd, 2+10+(4*4)+4) // sFamilyClass, panose, ulUnicodeRange, achVendID
 	if err != nil {
 		return err
 	}
@@ -495,6 +573,26 @@ func (t *TTFParser) ParseOS2(fd *bytes.Reader) error {
 	return nil
 }
 
+// parseOS2ScriptMetrics reads the subscript, superscript and strikeout
+// metrics of the OS/2 table, from ySubscriptXSize to yStrikeoutPosition.
+func (t *TTFParser) parseOS2ScriptMetrics(fd *bytes.Reader) error {
+	fields := []*int{
+		nil, &t.subscriptSize, nil, &t.subscriptOffset,
+		nil, &t.superscriptSize, nil, &t.superscriptOffset,
+		&t.strikeoutSize, &t.strikeoutPosition,
+	}
+	for _, field := range fields {
+		v, err := t.ReadShort(fd)
+		if err != nil {
+			return err
+		}
+		if field != nil {
+			*field = v
+		}
+	}
+	return nil
+}
+
 // ParseName parse name table https://www.microsoft.com/typography/otspec/name.htm
 func (t *TTFParser) ParseName(fd *bytes.Reader) error {
 
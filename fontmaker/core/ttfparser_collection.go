@@ -0,0 +1,113 @@
+package core
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+)
+
+var ERROR_CFF_OUTLINES = errors.New("CFF outlines (OTF) are not supported, only TrueType outlines")
+var ERROR_COLLECTION_FACE_NOT_FOUND = errors.New("Face not found in font collection")
+
+// SetCollectionFace selects the face of a TrueType Collection (.ttc) to
+// parse: by PostScript name when name is not empty, otherwise by index.
+func (t *TTFParser) SetCollectionFace(index int, name string) {
+	t.faceIndex = index
+	t.faceName = name
+}
+
+// PostScriptName is the PostScript name of the font.
+func (t *TTFParser) PostScriptName() string {
+	return t.postScriptName
+}
+
+// selectCollectionFace reads the header of a TrueType Collection after its
+// tag and returns the offset of the table directory of the selected face.
+func (t *TTFParser) selectCollectionFace(fd *bytes.Reader) (uint, error) {
+	offsets, err := t.collectionOffsets(fd)
+	if err != nil {
+		return 0, err
+	}
+	if t.faceName == "" {
+		if t.faceIndex < 0 || t.faceIndex >= len(offsets) {
+			return 0, fmt.Errorf("%w: index %d of %d faces", ERROR_COLLECTION_FACE_NOT_FOUND, t.faceIndex, len(offsets))
+		}
+		return offsets[t.faceIndex], nil
+	}
+	for _, offset := range offsets {
+		name, err := collectionFaceName(fd, offset)
+		if err != nil {
+			return 0, err
+		}
+		if name == t.faceName {
+			return offset, nil
+		}
+	}
+	return 0, fmt.Errorf("%w: %q", ERROR_COLLECTION_FACE_NOT_FOUND, t.faceName)
+}
+
+// collectionOffsets reads the offsets of the table directories of the
+// faces of a collection, after its tag.
+func (t *TTFParser) collectionOffsets(fd *bytes.Reader) ([]uint, error) {
+	if err := t.Skip(fd, 4); err != nil { // majorVersion, minorVersion
+		return nil, err
+	}
+	numFonts, err := t.ReadULong(fd)
+	if err != nil {
+		return nil, err
+	}
+	offsets := make([]uint, 0, numFonts)
+	for i := uint(0); i < numFonts; i++ {
+		offset, err := t.ReadULong(fd)
+		if err != nil {
+			return nil, err
+		}
+		offsets = append(offsets, offset)
+	}
+	return offsets, nil
+}
+
+// collectionFaceName returns the PostScript name of the face whose table
+// directory is at offset.
+func collectionFaceName(fd *bytes.Reader, offset uint) (string, error) {
+	var face TTFParser
+	if _, err := fd.Seek(int64(offset)+4, 0); err != nil {
+		return "", err
+	}
+	if err := face.parseTableDirectory(fd); err != nil {
+		return "", err
+	}
+	if err := face.ParseName(fd); err != nil {
+		return "", err
+	}
+	return face.postScriptName, nil
+}
+
+// CollectionPostScriptNames returns the PostScript names of the faces of a
+// TrueType Collection, in order, or of the single font of a TTF.
+func CollectionPostScriptNames(fontData []byte) ([]string, error) {
+	var t TTFParser
+	fd := bytes.NewReader(fontData)
+	tag, err := t.Read(fd, 4)
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(tag, []byte("ttcf")) {
+		name, err := collectionFaceName(fd, 0)
+		if err != nil {
+			return nil, err
+		}
+		return []string{name}, nil
+	}
+	offsets, err := t.collectionOffsets(fd)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, len(offsets))
+	for i, offset := range offsets {
+		if names[i], err = collectionFaceName(fd, offset); err != nil {
+			return nil, err
+		}
+	}
+	return names, nil
+}
@@ -0,0 +1,855 @@
+package core
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+)
+
+var ERROR_NOT_VARIABLE_FONT = errors.New("Font has no variation axes")
+var ERROR_UNKNOWN_VARIATION_AXIS = errors.New("Unknown variation axis")
+var ERROR_MALFORMED_VARIATIONS = errors.New("Malformed font variation data")
+
+// VariationAxis is an axis of a variable font, from its fvar table.
+type VariationAxis struct {
+	Tag     string //e.g. "wght", "wdth", "slnt"
+	Min     float64
+	Default float64
+	Max     float64
+}
+
+// SetVariation sets the axis values, by axis tag, at which a variable font
+// is instantiated when parsed. Axes without a value stay at their default.
+func (t *TTFParser) SetVariation(coords map[string]float64) {
+	t.variation = make(map[string]float64, len(coords))
+	for tag, v := range coords {
+		t.variation[tag] = v
+	}
+}
+
+// Axes returns the variation axes of the font, or nil for a static font.
+func (t *TTFParser) Axes() []VariationAxis {
+	return t.axes
+}
+
+// IsInstanced reports whether the font was instantiated at the values set
+// with SetVariation.
+func (t *TTFParser) IsInstanced() bool {
+	return t.instance != nil
+}
+
+// ParseFvar parse fvar table https://learn.microsoft.com/typography/opentype/spec/fvar
+func (t *TTFParser) ParseFvar(fd *bytes.Reader) error {
+	t.axes = nil
+	table, ok := t.tables["fvar"]
+	if !ok {
+		return nil
+	}
+	r := newSfntReader(t.cachedFontData, table.Offset)
+	r.skip(4) //majorVersion, minorVersion
+	axesArrayOffset := r.u16()
+	r.skip(2) //reserved
+	axisCount := int(r.u16())
+	axisSize := int(r.u16())
+	for i := 0; i < axisCount; i++ {
+		r.seek(table.Offset + uint(axesArrayOffset) + uint(i*axisSize))
+		var axis VariationAxis
+		axis.Tag = string(r.bytes(4))
+		axis.Min = r.fixed()
+		axis.Default = r.fixed()
+		axis.Max = r.fixed()
+		t.axes = append(t.axes, axis)
+	}
+	return r.err
+}
+
+// glyphInstance holds the state of a variable font instantiated at fixed
+// axis values.
+type glyphInstance struct {
+	coords       []float64 //normalized, by axis
+	sharedTuples [][]float64
+	dataOffset   uint   //absolute offset of the glyph variation data array
+	offsets      []uint //of the variation data of each glyph, relative to dataOffset
+	glyphs       map[int][]byte
+}
+
+// instantiate normalizes the axis values set with SetVariation, and applies
+// the advance width deltas of gvar (or HVAR) to the widths. Glyph outlines
+// are instantiated on demand by GlyphData.
+func (t *TTFParser) instantiate() error {
+	if len(t.axes) == 0 {
+		return ERROR_NOT_VARIABLE_FONT
+	}
+	for tag := range t.variation {
+		found := false
+		for _, axis := range t.axes {
+			found = found || axis.Tag == tag
+		}
+		if !found {
+			return fmt.Errorf("%w: %q", ERROR_UNKNOWN_VARIATION_AXIS, tag)
+		}
+	}
+
+	inst := &glyphInstance{glyphs: make(map[int][]byte)}
+	for _, axis := range t.axes {
+		v, ok := t.variation[axis.Tag]
+		if !ok {
+			v = axis.Default
+		}
+		inst.coords = append(inst.coords, normalizeAxisValue(axis, v))
+	}
+	if err := t.applyAvar(inst.coords); err != nil {
+		return err
+	}
+	if err := t.parseGvar(inst); err != nil {
+		return err
+	}
+	t.instance = inst
+
+	var hvar *itemVariationStore
+	if _, ok := t.tables["HVAR"]; ok {
+		var err error
+		if hvar, err = t.parseHvar(inst.coords); err != nil {
+			return err
+		}
+	}
+	for g := 0; g < len(t.widths); g++ {
+		var delta float64
+		if hvar != nil {
+			delta = hvar.delta(g)
+		} else if g+1 < len(inst.offsets) && inst.offsets[g] < inst.offsets[g+1] {
+			outline, err := t.decodeGlyph(g)
+			if err != nil {
+				return err
+			}
+			deltas, err := t.glyphDeltas(g, outline)
+			if err != nil {
+				return err
+			}
+			n := len(outline.x)
+			delta = deltas[n+1][0] - deltas[n][0]
+		}
+		t.widths[g] = uint(math.Max(0, math.Round(float64(t.widths[g])+delta)))
+	}
+	return nil
+}
+
+// normalizeAxisValue maps v to the normalized range -1..1 of axis.
+func normalizeAxisValue(axis VariationAxis, v float64) float64 {
+	v = math.Max(axis.Min, math.Min(axis.Max, v))
+	switch {
+	case v < axis.Default && axis.Default > axis.Min:
+		return (v - axis.Default) / (axis.Default - axis.Min)
+	case v > axis.Default && axis.Max > axis.Default:
+		return (v - axis.Default) / (axis.Max - axis.Default)
+	}
+	return 0
+}
+
+// applyAvar applies the segment maps of the avar table to normalized coords.
+func (t *TTFParser) applyAvar(coords []float64) error {
+	table, ok := t.tables["avar"]
+	if !ok {
+		return nil
+	}
+	r := newSfntReader(t.cachedFontData, table.Offset)
+	r.skip(6) //majorVersion, minorVersion, reserved
+	axisCount := int(r.u16())
+	for i := 0; i < axisCount && i < len(coords); i++ {
+		count := int(r.u16())
+		from := make([]float64, count)
+		to := make([]float64, count)
+		for j := 0; j < count; j++ {
+			from[j] = r.f2dot14()
+			to[j] = r.f2dot14()
+		}
+		if r.err != nil {
+			return r.err
+		}
+		for j := 1; j < count; j++ {
+			if coords[i] <= from[j] {
+				if from[j] > from[j-1] {
+					coords[i] = to[j-1] + (to[j]-to[j-1])*(coords[i]-from[j-1])/(from[j]-from[j-1])
+				} else {
+					coords[i] = to[j]
+				}
+				break
+			}
+		}
+	}
+	return r.err
+}
+
+// parseGvar reads the header of the gvar table https://learn.microsoft.com/typography/opentype/spec/gvar
+func (t *TTFParser) parseGvar(inst *glyphInstance) error {
+	table, ok := t.tables["gvar"]
+	if !ok {
+		return nil
+	}
+	r := newSfntReader(t.cachedFontData, table.Offset)
+	r.skip(4) //majorVersion, minorVersion
+	axisCount := int(r.u16())
+	sharedTupleCount := int(r.u16())
+	sharedTuplesOffset := r.u32()
+	glyphCount := int(r.u16())
+	flags := r.u16()
+	inst.dataOffset = table.Offset + uint(r.u32())
+	inst.offsets = make([]uint, glyphCount+1)
+	for i := range inst.offsets {
+		if flags&1 != 0 {
+			inst.offsets[i] = uint(r.u32())
+		} else {
+			inst.offsets[i] = uint(r.u16()) * 2
+		}
+	}
+	if axisCount != len(inst.coords) {
+		return ERROR_MALFORMED_VARIATIONS
+	}
+	r.seek(table.Offset + uint(sharedTuplesOffset))
+	for i := 0; i < sharedTupleCount; i++ {
+		inst.sharedTuples = append(inst.sharedTuples, r.tuple(axisCount))
+	}
+	return r.err
+}
+
+// glyphOutline is a decoded glyph: the points of a simple glyph, or the
+// offsets of the components of a composite glyph.
+type glyphOutline struct {
+	data      []byte
+	composite bool
+	endPts    []int //of contours
+	onCurve   []bool
+	overlap   bool
+	x, y      []float64
+}
+
+// decodeGlyph decodes the points of glyph.
+func (t *TTFParser) decodeGlyph(glyph int) (*glyphOutline, error) {
+	outline := &glyphOutline{data: t.rawGlyphData(glyph)}
+	if len(outline.data) == 0 {
+		return outline, nil
+	}
+	r := newSfntReader(outline.data, 0)
+	numberOfContours := int(int16(r.u16()))
+	r.skip(8) //xMin, yMin, xMax, yMax
+	if numberOfContours < 0 {
+		outline.composite = true
+		for {
+			flags := r.u16()
+			r.skip(2) //glyphIndex
+			var x, y int
+			if flags&arg1And2AreWords != 0 {
+				x, y = int(int16(r.u16())), int(int16(r.u16()))
+			} else {
+				x, y = int(int8(r.u8())), int(int8(r.u8()))
+			}
+			if flags&argsAreXYValues == 0 {
+				x, y = 0, 0 //point numbers, not moved by variations
+			}
+			outline.x = append(outline.x, float64(x))
+			outline.y = append(outline.y, float64(y))
+			r.skip(transformSize(flags))
+			if flags&moreComponents == 0 || r.err != nil {
+				break
+			}
+		}
+		return outline, r.err
+	}
+
+	for i := 0; i < numberOfContours; i++ {
+		outline.endPts = append(outline.endPts, int(r.u16()))
+	}
+	numPoints := 0
+	if numberOfContours > 0 {
+		numPoints = outline.endPts[numberOfContours-1] + 1
+	}
+	r.skip(int(r.u16())) //instructions
+	flags := make([]byte, 0, numPoints)
+	for len(flags) < numPoints && r.err == nil {
+		flag := r.u8()
+		flags = append(flags, flag)
+		if flag&repeatFlag != 0 {
+			for n := r.u8(); n > 0 && len(flags) < numPoints; n-- {
+				flags = append(flags, flag)
+			}
+		}
+	}
+	if r.err != nil {
+		return nil, r.err
+	}
+	outline.overlap = numPoints > 0 && flags[0]&overlapSimple != 0
+	readCoords := func(short, same byte) []float64 {
+		coords := make([]float64, numPoints)
+		v := 0
+		for i, flag := range flags {
+			if flag&short != 0 {
+				d := int(r.u8())
+				if flag&same == 0 {
+					d = -d
+				}
+				v += d
+			} else if flag&same == 0 {
+				v += int(int16(r.u16()))
+			}
+			coords[i] = float64(v)
+		}
+		return coords
+	}
+	outline.x = readCoords(xShortVector, xIsSameOrPositive)
+	outline.y = readCoords(yShortVector, yIsSameOrPositive)
+	for _, flag := range flags {
+		outline.onCurve = append(outline.onCurve, flag&onCurvePoint != 0)
+	}
+	return outline, r.err
+}
+
+// glyphDeltas returns the summed deltas of the points of outline, followed
+// by the deltas of its four phantom points.
+func (t *TTFParser) glyphDeltas(glyph int, outline *glyphOutline) ([][2]float64, error) {
+	inst := t.instance
+	numPoints := len(outline.x) + 4
+	deltas := make([][2]float64, numPoints)
+	if glyph+1 >= len(inst.offsets) || inst.offsets[glyph] >= inst.offsets[glyph+1] {
+		return deltas, nil
+	}
+	start := inst.dataOffset + inst.offsets[glyph]
+	r := newSfntReader(t.cachedFontData, start)
+	tupleVariationCount := r.u16()
+	serialized := start + uint(r.u16())
+	axisCount := len(inst.coords)
+
+	data := newSfntReader(t.cachedFontData, serialized)
+	var sharedPoints []int
+	if tupleVariationCount&sharedPointNumbers != 0 {
+		sharedPoints = data.points(numPoints)
+	}
+	for i := 0; i < int(tupleVariationCount&tupleCountMask); i++ {
+		size := uint(r.u16())
+		tupleIndex := r.u16()
+		var peak, startTuple, endTuple []float64
+		if tupleIndex&embeddedPeakTuple != 0 {
+			peak = r.tuple(axisCount)
+		} else if int(tupleIndex&tupleIndexMask) < len(inst.sharedTuples) {
+			peak = inst.sharedTuples[tupleIndex&tupleIndexMask]
+		} else {
+			return nil, ERROR_MALFORMED_VARIATIONS
+		}
+		if tupleIndex&intermediateRegion != 0 {
+			startTuple = r.tuple(axisCount)
+			endTuple = r.tuple(axisCount)
+		}
+		next := data.pos + size
+		scalar := tupleScalar(inst.coords, peak, startTuple, endTuple)
+		if scalar == 0 || r.err != nil {
+			data.seek(next)
+			continue
+		}
+
+		points := sharedPoints
+		if tupleIndex&privatePointNumbers != 0 {
+			points = data.points(numPoints)
+		}
+		count := len(points)
+		if points == nil {
+			count = numPoints
+		}
+		dx := data.deltas(count)
+		dy := data.deltas(count)
+		if data.err != nil {
+			return nil, data.err
+		}
+		tuple := make([][2]float64, numPoints)
+		touched := make([]bool, numPoints)
+		for j := 0; j < count; j++ {
+			p := j
+			if points != nil {
+				p = points[j]
+			}
+			if p < numPoints {
+				tuple[p] = [2]float64{dx[j] * scalar, dy[j] * scalar}
+				touched[p] = true
+			}
+		}
+		if points != nil && !outline.composite {
+			interpolateUntouched(outline, tuple, touched)
+		}
+		for p := range deltas {
+			deltas[p][0] += tuple[p][0]
+			deltas[p][1] += tuple[p][1]
+		}
+		data.seek(next)
+	}
+	return deltas, r.err
+}
+
+// tupleScalar returns the scalar of a tuple variation at coords.
+func tupleScalar(coords, peak, start, end []float64) float64 {
+	scalar := 1.0
+	for i, v := range coords {
+		p := peak[i]
+		if p == 0 || v == p {
+			continue
+		}
+		if start != nil {
+			if v < start[i] || v > end[i] {
+				return 0
+			}
+			if v < p {
+				scalar *= (v - start[i]) / (p - start[i])
+			} else {
+				scalar *= (end[i] - v) / (end[i] - p)
+			}
+			continue
+		}
+		if v == 0 || v < math.Min(0, p) || v > math.Max(0, p) {
+			return 0
+		}
+		scalar *= v / p
+	}
+	return scalar
+}
+
+// interpolateUntouched infers the deltas of the points of a simple glyph
+// that a tuple does not reference (IUP).
+func interpolateUntouched(outline *glyphOutline, deltas [][2]float64, touched []bool) {
+	first := 0
+	for _, last := range outline.endPts {
+		var refs []int
+		for p := first; p <= last; p++ {
+			if touched[p] {
+				refs = append(refs, p)
+			}
+		}
+		if len(refs) == 0 {
+			first = last + 1
+			continue
+		}
+		for p := first; p <= last; p++ {
+			if touched[p] {
+				continue
+			}
+			prev, next := refs[len(refs)-1], refs[0]
+			for _, ref := range refs {
+				if ref < p {
+					prev = ref
+				} else {
+					next = ref
+					break
+				}
+			}
+			deltas[p][0] = interpolateDelta(outline.x, deltas, 0, p, prev, next)
+			deltas[p][1] = interpolateDelta(outline.y, deltas, 1, p, prev, next)
+		}
+		first = last + 1
+	}
+}
+
+// interpolateDelta interpolates the delta of point p on axis (0 for x) from
+// the reference points prev and next.
+func interpolateDelta(coords []float64, deltas [][2]float64, axis, p, prev, next int) float64 {
+	c1, c2 := coords[prev], coords[next]
+	d1, d2 := deltas[prev][axis], deltas[next][axis]
+	if c1 > c2 {
+		c1, c2, d1, d2 = c2, c1, d2, d1
+	}
+	switch c := coords[p]; {
+	case c1 == c2:
+		if d1 == d2 {
+			return d1
+		}
+		return 0
+	case c <= c1:
+		return d1
+	case c >= c2:
+		return d2
+	default:
+		return d1 + (d2-d1)*(c-c1)/(c2-c1)
+	}
+}
+
+// rawGlyphData returns the glyf data of glyph in the font.
+func (t *TTFParser) rawGlyphData(glyph int) []byte {
+	if glyph+1 >= len(t.LocaTable) {
+		return nil
+	}
+	glyf := t.tables["glyf"]
+	start := glyf.Offset + t.LocaTable[glyph]
+	end := glyf.Offset + t.LocaTable[glyph+1]
+	if start > end || end > uint(len(t.cachedFontData)) {
+		return nil
+	}
+	return t.cachedFontData[start:end]
+}
+
+// GlyphData returns the glyf data of glyph, instantiated at the axis values
+// set with SetVariation. Instantiated glyphs have no hinting instructions.
+func (t *TTFParser) GlyphData(glyph int) []byte {
+	if t.instance == nil || t.instance.offsets == nil {
+		return t.rawGlyphData(glyph)
+	}
+	if data, ok := t.instance.glyphs[glyph]; ok {
+		return data
+	}
+	data, err := t.instantiateGlyph(glyph)
+	if err != nil {
+		data = t.rawGlyphData(glyph)
+	}
+	t.instance.glyphs[glyph] = data
+	return data
+}
+
+// instantiateGlyph applies the variation deltas to the points of glyph and
+// encodes it again.
+func (t *TTFParser) instantiateGlyph(glyph int) ([]byte, error) {
+	outline, err := t.decodeGlyph(glyph)
+	if err != nil || len(outline.data) == 0 {
+		return outline.data, err
+	}
+	deltas, err := t.glyphDeltas(glyph, outline)
+	if err != nil {
+		return nil, err
+	}
+	n := len(outline.x)
+	originX := deltas[n][0] //keeps the origin at 0 when it moves
+	x := make([]int, n)
+	y := make([]int, n)
+	for i := 0; i < n; i++ {
+		x[i] = int(math.Round(outline.x[i] + deltas[i][0] - originX))
+		y[i] = int(math.Round(outline.y[i] + deltas[i][1]))
+	}
+	if outline.composite {
+		return encodeCompositeGlyph(outline.data, x, y), nil
+	}
+	return encodeSimpleGlyph(outline, x, y), nil
+}
+
+// encodeSimpleGlyph encodes a simple glyph with the points x, y.
+func encodeSimpleGlyph(outline *glyphOutline, x, y []int) []byte {
+	xMin, yMin, xMax, yMax := 0, 0, 0, 0
+	for i := range x {
+		if i == 0 || x[i] < xMin {
+			xMin = x[i]
+		}
+		if i == 0 || x[i] > xMax {
+			xMax = x[i]
+		}
+		if i == 0 || y[i] < yMin {
+			yMin = y[i]
+		}
+		if i == 0 || y[i] > yMax {
+			yMax = y[i]
+		}
+	}
+	var buff []byte
+	put16 := func(v int) { buff = binary.BigEndian.AppendUint16(buff, uint16(v)) }
+	put16(len(outline.endPts))
+	put16(xMin)
+	put16(yMin)
+	put16(xMax)
+	put16(yMax)
+	for _, end := range outline.endPts {
+		put16(end)
+	}
+	put16(0) //instructionLength
+
+	var xs, ys []byte
+	encode := func(coords []byte, d int, short, same byte) ([]byte, byte) {
+		switch {
+		case d == 0:
+			return coords, same
+		case d > -256 && d < 256:
+			if d > 0 {
+				return append(coords, byte(d)), short | same
+			}
+			return append(coords, byte(-d)), short
+		}
+		return binary.BigEndian.AppendUint16(coords, uint16(d)), 0
+	}
+	for i := range x {
+		var flag, fx, fy byte
+		if outline.onCurve[i] {
+			flag |= onCurvePoint
+		}
+		if i == 0 && outline.overlap {
+			flag |= overlapSimple
+		}
+		px, py := 0, 0
+		if i > 0 {
+			px, py = x[i-1], y[i-1]
+		}
+		xs, fx = encode(xs, x[i]-px, xShortVector, xIsSameOrPositive)
+		ys, fy = encode(ys, y[i]-py, yShortVector, yIsSameOrPositive)
+		buff = append(buff, flag|fx|fy)
+	}
+	buff = append(append(buff, xs...), ys...)
+	return padGlyph(buff)
+}
+
+// encodeCompositeGlyph encodes a composite glyph with the offsets x, y of
+// its components.
+func encodeCompositeGlyph(data []byte, x, y []int) []byte {
+	r := newSfntReader(data, 10)
+	buff := append([]byte(nil), data[:10]...)
+	for i := 0; ; i++ {
+		flags := r.u16()
+		glyphIndex := r.u16()
+		var args []byte
+		if flags&arg1And2AreWords != 0 {
+			args = r.bytes(4)
+		} else {
+			args = r.bytes(2)
+		}
+		transform := r.bytes(transformSize(flags))
+		if flags&argsAreXYValues != 0 {
+			flags |= arg1And2AreWords
+			args = binary.BigEndian.AppendUint16(nil, uint16(x[i]))
+			args = binary.BigEndian.AppendUint16(args, uint16(y[i]))
+		}
+		flags &^= weHaveInstructions
+		buff = binary.BigEndian.AppendUint16(buff, flags)
+		buff = binary.BigEndian.AppendUint16(buff, glyphIndex)
+		buff = append(append(buff, args...), transform...)
+		if flags&moreComponents == 0 || r.err != nil || i+1 >= len(x) {
+			break
+		}
+	}
+	return padGlyph(buff)
+}
+
+// padGlyph pads glyph data to a multiple of 4 bytes, so that the offsets of
+// the loca table stay even.
+func padGlyph(data []byte) []byte {
+	for len(data)%4 != 0 {
+		data = append(data, 0)
+	}
+	return data
+}
+
+// transformSize is the size of the transformation of a glyph component.
+func transformSize(flags uint16) int {
+	switch {
+	case flags&weHaveAScale != 0:
+		return 2
+	case flags&weHaveAnXAndYScale != 0:
+		return 4
+	case flags&weHaveATwoByTwo != 0:
+		return 8
+	}
+	return 0
+}
+
+// HmtxData returns the hmtx table with the instantiated advance widths,
+// and the left side bearings of the glyphs instantiated by GlyphData.
+func (t *TTFParser) HmtxData() []byte {
+	table := t.tables["hmtx"]
+	var raw []byte
+	if end := table.Offset + table.Length; end <= uint(len(t.cachedFontData)) {
+		raw = t.cachedFontData[table.Offset:end]
+	}
+	data := append([]byte(nil), raw...)
+	for g := 0; g < int(t.numberOfHMetrics) && g < len(t.widths) && 4*g+4 <= len(data); g++ {
+		binary.BigEndian.PutUint16(data[4*g:], uint16(t.widths[g]))
+	}
+	if t.instance == nil {
+		return data
+	}
+	for g, glyph := range t.instance.glyphs {
+		if len(glyph) < 10 {
+			continue
+		}
+		pos := 4*g + 2
+		if g >= int(t.numberOfHMetrics) {
+			pos = 4*int(t.numberOfHMetrics) + 2*(g-int(t.numberOfHMetrics))
+		}
+		if pos+2 <= len(data) {
+			copy(data[pos:pos+2], glyph[2:4]) //xMin
+		}
+	}
+	return data
+}
+
+// hvar
+type itemVariationStore struct {
+	mapping  func(glyph int) (outer, inner int)
+	scalars  [][]float64 //by data, region index
+	deltaSet func(outer, inner int) []float64
+}
+
+// delta returns the advance width delta of glyph.
+func (s *itemVariationStore) delta(glyph int) float64 {
+	outer, inner := s.mapping(glyph)
+	if outer >= len(s.scalars) {
+		return 0
+	}
+	var delta float64
+	for i, d := range s.deltaSet(outer, inner) {
+		delta += d * s.scalars[outer][i]
+	}
+	return delta
+}
+
+// parseHvar parse HVAR table https://learn.microsoft.com/typography/opentype/spec/hvar
+func (t *TTFParser) parseHvar(coords []float64) (*itemVariationStore, error) {
+	table := t.tables["HVAR"]
+	data := t.cachedFontData
+	r := newSfntReader(data, table.Offset)
+	r.skip(4) //majorVersion, minorVersion
+	storeOffset := table.Offset + uint(r.u32())
+	mapOffset := uint(r.u32())
+
+	store := &itemVariationStore{mapping: func(glyph int) (int, int) { return 0, glyph }}
+	if mapOffset != 0 {
+		m := newSfntReader(data, table.Offset+mapOffset)
+		format := m.u8()
+		entryFormat := m.u8()
+		var mapCount int
+		if format == 0 {
+			mapCount = int(m.u16())
+		} else {
+			mapCount = int(m.u32())
+		}
+		entrySize := int(entryFormat>>4&3) + 1
+		innerBits := uint(entryFormat&0x0F) + 1
+		entries := m.pos
+		if m.err != nil || mapCount == 0 {
+			return nil, ERROR_MALFORMED_VARIATIONS
+		}
+		store.mapping = func(glyph int) (int, int) {
+			if glyph >= mapCount {
+				glyph = mapCount - 1
+			}
+			e := newSfntReader(data, entries+uint(glyph*entrySize))
+			entry := 0
+			for _, b := range e.bytes(entrySize) {
+				entry = entry<<8 | int(b)
+			}
+			return entry >> innerBits, entry & (1<<innerBits - 1)
+		}
+	}
+
+	r.seek(storeOffset)
+	r.skip(2) //format
+	regionListOffset := storeOffset + uint(r.u32())
+	dataCount := int(r.u16())
+	dataOffsets := make([]uint, dataCount)
+	for i := range dataOffsets {
+		dataOffsets[i] = storeOffset + uint(r.u32())
+	}
+
+	r.seek(regionListOffset)
+	axisCount := int(r.u16())
+	regionCount := int(r.u16())
+	regionScalars := make([]float64, regionCount)
+	for i := range regionScalars {
+		scalar := 1.0
+		for a := 0; a < axisCount; a++ {
+			start, peak, end := r.f2dot14(), r.f2dot14(), r.f2dot14()
+			if a < len(coords) {
+				scalar *= regionAxisScalar(coords[a], start, peak, end)
+			}
+		}
+		regionScalars[i] = scalar
+	}
+
+	type itemData struct {
+		pos              uint
+		wordCount        int
+		longWords        bool
+		regionIndexCount int
+	}
+	items := make([]itemData, dataCount)
+	store.scalars = make([][]float64, dataCount)
+	for i, offset := range dataOffsets {
+		r.seek(offset)
+		r.skip(2) //itemCount
+		wordDeltaCount := r.u16()
+		items[i].wordCount = int(wordDeltaCount & 0x7FFF)
+		items[i].longWords = wordDeltaCount&0x8000 != 0
+		items[i].regionIndexCount = int(r.u16())
+		for j := 0; j < items[i].regionIndexCount; j++ {
+			index := int(r.u16())
+			scalar := 0.0
+			if index < regionCount {
+				scalar = regionScalars[index]
+			}
+			store.scalars[i] = append(store.scalars[i], scalar)
+		}
+		items[i].pos = r.pos
+	}
+	store.deltaSet = func(outer, inner int) []float64 {
+		item := items[outer]
+		wordSize, smallSize := 2, 1
+		if item.longWords {
+			wordSize, smallSize = 4, 2
+		}
+		rowSize := item.wordCount*wordSize + (item.regionIndexCount-item.wordCount)*smallSize
+		d := newSfntReader(data, item.pos+uint(inner*rowSize))
+		deltas := make([]float64, item.regionIndexCount)
+		for j := range deltas {
+			size := smallSize
+			if j < item.wordCount {
+				size = wordSize
+			}
+			switch size {
+			case 1:
+				deltas[j] = float64(int8(d.u8()))
+			case 2:
+				deltas[j] = float64(int16(d.u16()))
+			default:
+				deltas[j] = float64(int32(d.u32()))
+			}
+		}
+		return deltas
+	}
+	return store, r.err
+}
+
+// regionAxisScalar returns the scalar of a variation region on one axis.
+func regionAxisScalar(v, start, peak, end float64) float64 {
+	switch {
+	case start > peak || peak > end || (start < 0 && end > 0) || peak == 0 || v == peak:
+		return 1
+	case v <= start || v >= end:
+		return 0
+	case v < peak:
+		return (v - start) / (peak - start)
+	}
+	return (end - v) / (end - peak)
+}
+
+// glyf flags
+const (
+	onCurvePoint      = 0x01
+	xShortVector      = 0x02
+	yShortVector      = 0x04
+	repeatFlag        = 0x08
+	xIsSameOrPositive = 0x10
+	yIsSameOrPositive = 0x20
+	overlapSimple     = 0x40
+
+	arg1And2AreWords   = 0x0001
+	argsAreXYValues    = 0x0002
+	weHaveAScale       = 0x0008
+	moreComponents     = 0x0020
+	weHaveAnXAndYScale = 0x0040
+	weHaveATwoByTwo    = 0x0080
+	weHaveInstructions = 0x0100
+)
+
+// gvar flags
+const (
+	sharedPointNumbers  = 0x8000
+	tupleCountMask      = 0x0FFF
+	embeddedPeakTuple   = 0x8000
+	intermediateRegion  = 0x4000
+	privatePointNumbers = 0x2000
+	tupleIndexMask      = 0x0FFF
+
+	pointsAreWords    = 0x80
+	pointRunCountMask = 0x7F
+	deltasAreZero     = 0x80
+	deltasAreWords    = 0x40
+	deltaRunCountMask = 0x3F
+)
@@ -0,0 +1,142 @@
+package core
+
+// VerticalSubstitutions returns the single substitutions of the GSUB
+// features for vertical writing, "vrt2" or else "vert", from glyph to
+// vertical alternate glyph. It returns an empty map when the font has none.
+// https://learn.microsoft.com/typography/opentype/spec/gsub
+func (t *TTFParser) VerticalSubstitutions() (map[uint]uint, error) {
+	substitutions := make(map[uint]uint)
+	table, ok := t.tables["GSUB"]
+	if !ok {
+		return substitutions, nil
+	}
+	data := t.cachedFontData
+	r := newSfntReader(data, table.Offset)
+	r.skip(6) //majorVersion, minorVersion, scriptListOffset
+	featureList := table.Offset + uint(r.u16())
+	lookupList := table.Offset + uint(r.u16())
+
+	r.seek(featureList)
+	lookups := make(map[string][]uint16)
+	featureCount := int(r.u16())
+	for i := 0; i < featureCount; i++ {
+		r.seek(featureList + 2 + uint(6*i))
+		tag := string(r.bytes(4))
+		if tag != "vert" && tag != "vrt2" {
+			continue
+		}
+		r.seek(featureList + uint(r.u16()) + 2) //featureParamsOffset
+		count := int(r.u16())
+		for j := 0; j < count; j++ {
+			lookups[tag] = append(lookups[tag], r.u16())
+		}
+	}
+	indices := lookups["vrt2"]
+	if len(indices) == 0 {
+		indices = lookups["vert"]
+	}
+
+	for _, index := range indices {
+		r.seek(lookupList + 2 + 2*uint(index))
+		lookup := lookupList + uint(r.u16())
+		r.seek(lookup)
+		lookupType := r.u16()
+		r.skip(2) //lookupFlag
+		subTableCount := int(r.u16())
+		for i := 0; i < subTableCount; i++ {
+			r.seek(lookup + 6 + 2*uint(i))
+			subTable := lookup + uint(r.u16())
+			subType := lookupType
+			if lookupType == 7 { //extension substitution
+				r.seek(subTable + 2)
+				subType = r.u16()
+				subTable += uint(r.u32())
+			}
+			if subType == 1 {
+				t.readSingleSubstitution(r, subTable, substitutions)
+			}
+		}
+	}
+	return substitutions, r.err
+}
+
+// readSingleSubstitution adds the substitutions of a single substitution
+// subtable to substitutions.
+func (t *TTFParser) readSingleSubstitution(r *sfntReader, subTable uint, substitutions map[uint]uint) {
+	r.seek(subTable)
+	format := r.u16()
+	coverage := readCoverage(r, subTable+uint(r.u16()))
+	r.seek(subTable + 4)
+	switch format {
+	case 1:
+		delta := int16(r.u16())
+		for _, glyph := range coverage {
+			substitutions[glyph] = uint(uint16(int(glyph) + int(delta)))
+		}
+	case 2:
+		count := int(r.u16())
+		for i := 0; i < count && i < len(coverage); i++ {
+			substitutions[coverage[i]] = uint(r.u16())
+		}
+	}
+}
+
+// readCoverage reads the glyphs of the coverage table at offset, in
+// coverage index order.
+func readCoverage(r *sfntReader, offset uint) []uint {
+	r.seek(offset)
+	var glyphs []uint
+	switch r.u16() {
+	case 1:
+		count := int(r.u16())
+		for i := 0; i < count; i++ {
+			glyphs = append(glyphs, uint(r.u16()))
+		}
+	case 2:
+		count := int(r.u16())
+		for i := 0; i < count && r.err == nil; i++ {
+			start, end := uint(r.u16()), uint(r.u16())
+			r.skip(2) //startCoverageIndex
+			for g := start; g <= end; g++ {
+				glyphs = append(glyphs, g)
+			}
+		}
+	}
+	return glyphs
+}
+
+// VerticalMetric returns the advance height of glyph and the y coordinate
+// of its vertical origin (top side bearing plus yMax), in font units, from
+// the vhea and vmtx tables. ok is false when the font has no vertical
+// metrics.
+func (t *TTFParser) VerticalMetric(glyph uint) (advance uint, originY int, ok bool) {
+	vhea, ok1 := t.tables["vhea"]
+	vmtx, ok2 := t.tables["vmtx"]
+	if !ok1 || !ok2 {
+		return 0, 0, false
+	}
+	r := newSfntReader(t.cachedFontData, vhea.Offset+34)
+	numOfLongVerMetrics := uint(r.u16())
+	if numOfLongVerMetrics == 0 {
+		return 0, 0, false
+	}
+	var tsb int
+	if glyph < numOfLongVerMetrics {
+		r.seek(vmtx.Offset + 4*glyph)
+		advance = uint(r.u16())
+		tsb = int(int16(r.u16()))
+	} else {
+		r.seek(vmtx.Offset + 4*(numOfLongVerMetrics-1))
+		advance = uint(r.u16())
+		r.seek(vmtx.Offset + 4*numOfLongVerMetrics + 2*(glyph-numOfLongVerMetrics))
+		tsb = int(int16(r.u16()))
+	}
+	if r.err != nil {
+		return 0, 0, false
+	}
+	originY = tsb
+	if data := t.GlyphData(int(glyph)); len(data) >= 10 {
+		originY += int(int16(uint16(data[8])<<8 | uint16(data[9]))) //yMax
+	}
+	return advance, originY, true
+}
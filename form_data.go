@@ -0,0 +1,628 @@
+package gopdf
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// ============================================================
+// Form data — field values and annotations exchanged as FDF
+// and XFDF with GoPdf documents and existing PDFs
+// ============================================================
+
+// FormData holds the field values and annotations of a document. It is
+// exported and imported as FDF with ExportFDF and ParseFDF, and as XFDF
+// with ExportXFDF and ParseXFDF.
+type FormData struct {
+	// File is the file name of the PDF the data belongs to.
+	File string
+	// Fields are the field values.
+	Fields []FormDataField
+	// Annotations are the markup annotations.
+	Annotations []FormDataAnnotation
+}
+
+// FormDataField is the value of a form field.
+type FormDataField struct {
+	// Name is the fully qualified field name, e.g. "applicant.address.city".
+	Name string
+	// Value is the field value. Checkboxes and radio buttons hold the
+	// selected state name, or "Off".
+	Value string
+	// Values holds all selected values of a multi-select list box.
+	Values []string
+	// Type is the field type. FDF stores the values of checkboxes and radio
+	// buttons as names, which ParseFDF reports as FormFieldCheckbox; XFDF
+	// does not record the type.
+	Type FormFieldType
+}
+
+// FormDataAnnotation is an annotation of a page.
+type FormDataAnnotation struct {
+	// Page is the zero-based page index.
+	Page int
+	// Name is the unique annotation name (/NM), if any.
+	Name string
+	// Rect is the annotation rectangle [x1 y1 x2 y2] as read from the
+	// file. When zero it is computed from Option.
+	Rect [4]float64
+	// Option describes the annotation. Unlike AnnotationOption elsewhere,
+	// its coordinates are in points in PDF user space as used by FDF and
+	// XFDF: the origin is the lower-left page corner and X, Y is the
+	// lower-left corner of the rectangle.
+	Option AnnotationOption
+}
+
+// FormDataReport describes the result of importing form data.
+type FormDataReport struct {
+	// FieldsSet is the number of fields whose value was set.
+	FieldsSet int
+	// UnmatchedFields are the names of the fields of the data that the
+	// document does not have.
+	UnmatchedFields []string
+	// AnnotationsUpdated is the number of annotations that replaced an
+	// annotation of the same type and rectangle on the same page.
+	AnnotationsUpdated int
+	// AnnotationsAdded is the number of annotations that were added.
+	AnnotationsAdded int
+	// UnmatchedAnnotations is the number of annotations whose page the
+	// document does not have.
+	UnmatchedAnnotations int
+}
+
+// annotationSubtypes are the /Subtype names of the annotation types.
+var annotationSubtypes = [...]string{
+	AnnotText:           "Text",
+	AnnotHighlight:      "Highlight",
+	AnnotUnderline:      "Underline",
+	AnnotStrikeOut:      "StrikeOut",
+	AnnotSquare:         "Square",
+	AnnotCircle:         "Circle",
+	AnnotFreeText:       "FreeText",
+	AnnotInk:            "Ink",
+	AnnotPolyline:       "PolyLine",
+	AnnotPolygon:        "Polygon",
+	AnnotLine:           "Line",
+	AnnotStamp:          "Stamp",
+	AnnotSquiggly:       "Squiggly",
+	AnnotCaret:          "Caret",
+	AnnotFileAttachment: "FileAttachment",
+	AnnotRedact:         "Redact",
+}
+
+// annotationTypeOf returns the annotation type of a /Subtype name.
+func annotationTypeOf(subtype string) (AnnotationType, bool) {
+	for t, name := range annotationSubtypes {
+		if strings.EqualFold(name, subtype) {
+			return AnnotationType(t), true
+		}
+	}
+	return 0, false
+}
+
+// hasPoints reports whether the rectangle of the annotation type is
+// derived from its points.
+func (t AnnotationType) hasPoints() bool {
+	return t == AnnotInk || t == AnnotPolyline || t == AnnotPolygon || t == AnnotLine
+}
+
+// flipAnnotation converts the coordinates of opt between the top-left
+// origin of AnnotationOption and the lower-left origin of PDF user space on
+// a page of height pageH. The conversion is its own inverse.
+func flipAnnotation(opt AnnotationOption, pageH float64) AnnotationOption {
+	flip := func(pts []Point) []Point {
+		if pts == nil {
+			return nil
+		}
+		out := make([]Point, len(pts))
+		for i, pt := range pts {
+			out[i] = Point{X: pt.X, Y: pageH - pt.Y}
+		}
+		return out
+	}
+	opt.Y = pageH - opt.Y - opt.H
+	if opt.InkList != nil {
+		ink := make([][]Point, len(opt.InkList))
+		for i, stroke := range opt.InkList {
+			ink[i] = flip(stroke)
+		}
+		opt.InkList = ink
+	}
+	opt.Vertices = flip(opt.Vertices)
+	if opt.Type == AnnotLine {
+		opt.LineStart.Y = pageH - opt.LineStart.Y
+		opt.LineEnd.Y = pageH - opt.LineEnd.Y
+	}
+	return opt
+}
+
+// rect returns the annotation rectangle [x1 y1 x2 y2] in PDF user space.
+func (a FormDataAnnotation) rect() [4]float64 {
+	if a.Rect != ([4]float64{}) {
+		return a.Rect
+	}
+	opt := a.Option
+	opt.defaults()
+	x, y, w, h := opt.rect()
+	return [4]float64{x, y, x + w, y + h}
+}
+
+// sameRect reports whether two rectangles match within half a point.
+func sameRect(a, b [4]float64) bool {
+	for i := range a {
+		if math.Abs(a[i]-b[i]) > 0.5 {
+			return false
+		}
+	}
+	return true
+}
+
+// ============================================================
+// GoPdf documents
+// ============================================================
+
+// GetFormData returns the values of the form fields and the annotations of
+// the document. Push buttons and signature fields have no value and are
+// left out.
+//
+// Example:
+//
+//	data := pdf.GetFormData()
+//	os.WriteFile("review.xfdf", data.ExportXFDF(), 0644)
+func (gp *GoPdf) GetFormData() *FormData {
+	d := &FormData{}
+	for _, ref := range gp.formFields {
+		field := ref.field
+		df := FormDataField{Name: field.Name, Type: field.Type}
+		switch field.Type {
+		case FormFieldText:
+			df.Value = field.Value
+		case FormFieldCheckbox:
+			df.Value = "Off"
+			if field.Checked {
+				df.Value = "Yes"
+			}
+		case FormFieldRadio:
+			df.Value = field.Value
+			if df.Value == "" {
+				df.Value = "Off"
+			}
+		case FormFieldChoice:
+			df.Value = field.Value
+			if len(field.Values) > 0 {
+				df.Value = field.Values[0]
+				if len(field.Values) > 1 {
+					df.Values = append([]string(nil), field.Values...)
+				}
+			}
+		default:
+			continue
+		}
+		d.Fields = append(d.Fields, df)
+	}
+	pageH := gp.config.PageSize.H
+	for i := 0; ; i++ {
+		page := gp.getPageByIndex(i)
+		if page == nil {
+			break
+		}
+		for _, objID := range page.LinkObjIds {
+			if a, ok := gp.pdfObjs[objID-1].(annotationObj); ok {
+				d.Annotations = append(d.Annotations, FormDataAnnotation{
+					Page:   i,
+					Option: flipAnnotation(a.opt, pageH),
+				})
+			}
+		}
+	}
+	return d
+}
+
+// ImportFormData sets the form fields of the document to the values of d by
+// name and adds its annotations to their pages. An annotation replaces an
+// existing annotation of the same type and rectangle on its page. Fields
+// that the document does not have are listed in the report.
+//
+// Example:
+//
+//	data, _ := os.ReadFile("submission.xfdf")
+//	fd, err := gopdf.ParseXFDF(data)
+//	if err != nil {
+//	    return err
+//	}
+//	report, err := pdf.ImportFormData(fd)
+func (gp *GoPdf) ImportFormData(d *FormData) (*FormDataReport, error) {
+	report := &FormDataReport{}
+	for _, df := range d.Fields {
+		idx := -1
+		for i, ref := range gp.formFields {
+			if ref.field.Name == df.Name && ref.field.Type != FormFieldButton && ref.field.Type != FormFieldSignature {
+				idx = i
+				break
+			}
+		}
+		if idx < 0 {
+			report.UnmatchedFields = append(report.UnmatchedFields, df.Name)
+			continue
+		}
+		if err := gp.setFormFieldData(idx, df); err != nil {
+			return report, err
+		}
+		report.FieldsSet++
+	}
+	pageH := gp.config.PageSize.H
+	for _, da := range d.Annotations {
+		page := gp.getPageByIndex(da.Page)
+		if page == nil {
+			report.UnmatchedAnnotations++
+			continue
+		}
+		opt := flipAnnotation(da.Option, pageH)
+		if gp.replaceAnnotation(page, da.rect(), opt) {
+			report.AnnotationsUpdated++
+			continue
+		}
+		gp.addAnnotationToPage(page, opt)
+		report.AnnotationsAdded++
+	}
+	return report, nil
+}
+
+// setFormFieldData sets the value of the field at index idx of
+// gp.formFields and redraws text and choice fields.
+func (gp *GoPdf) setFormFieldData(idx int, df FormDataField) error {
+	field := gp.formFields[idx].field
+	switch field.Type {
+	case FormFieldCheckbox:
+		field.Checked = df.Value != "" && df.Value != "Off"
+	case FormFieldRadio:
+		field.Value = df.Value
+		if field.Value == "Off" {
+			field.Value = ""
+		}
+	case FormFieldChoice:
+		field.Value, field.Values = df.Value, nil
+		if len(df.Values) > 1 {
+			if !field.MultiSelect {
+				return fmt.Errorf("form field %q does not allow multiple selections", field.Name)
+			}
+			field.Value, field.Values = "", append([]string(nil), df.Values...)
+		}
+	default:
+		field.Value = df.Value
+	}
+	gp.formFields[idx].field = field
+	objIdx := gp.formFields[idx].objIdx
+	if objIdx < 0 || objIdx >= len(gp.pdfObjs) {
+		return nil
+	}
+	ffObj, ok := gp.pdfObjs[objIdx].(formFieldObj)
+	if !ok {
+		return nil
+	}
+	ffObj.field.Value, ffObj.field.Values, ffObj.field.Checked = field.Value, field.Values, field.Checked
+	gp.pdfObjs[objIdx] = ffObj
+	apIdx := ffObj.ap.normal - 1
+	if (field.Type == FormFieldText || field.Type == FormFieldChoice) && apIdx >= 0 && apIdx < len(gp.pdfObjs) {
+		gp.pdfObjs[apIdx] = gp.textFieldAppearance(ffObj)
+	}
+	return nil
+}
+
+// replaceAnnotation replaces the option of the first annotation of page
+// with the type of opt and the PDF user space rectangle rect.
+func (gp *GoPdf) replaceAnnotation(page *PageObj, rect [4]float64, opt AnnotationOption) bool {
+	pageH := gp.config.PageSize.H
+	for _, objID := range page.LinkObjIds {
+		a, ok := gp.pdfObjs[objID-1].(annotationObj)
+		if !ok || a.opt.Type != opt.Type {
+			continue
+		}
+		existing := FormDataAnnotation{Option: flipAnnotation(a.opt, pageH)}
+		if sameRect(existing.rect(), rect) {
+			gp.addAnnotationChars(opt)
+			a.opt = opt
+			gp.pdfObjs[objID-1] = a
+			return true
+		}
+	}
+	return false
+}
+
+// ============================================================
+// Existing PDFs
+// ============================================================
+
+// ReadFormData returns the values of the form fields and the markup
+// annotations of the PDF in pdfData. Widget, link and popup annotations are
+// left out.
+//
+// Example:
+//
+//	data, _ := os.ReadFile("filled.pdf")
+//	fd, err := gopdf.ReadFormData(data)
+//	if err != nil {
+//	    return err
+//	}
+//	os.WriteFile("filled.fdf", fd.ExportFDF(), 0644)
+func ReadFormData(pdfData []byte) (*FormData, error) {
+	store, err := newPDFObjectStore(pdfData)
+	if err != nil {
+		return nil, err
+	}
+	d := &FormData{}
+	f, err := newFormFiller(store)
+	if err != nil && !errors.Is(err, ErrNoAcroForm) {
+		return nil, err
+	}
+	if f != nil {
+		for _, info := range f.Fields() {
+			if info.Type == FormFieldButton || info.Type == FormFieldSignature {
+				continue
+			}
+			d.Fields = append(d.Fields, FormDataField{
+				Name:   info.Name,
+				Value:  info.Value,
+				Values: info.Values,
+				Type:   info.Type,
+			})
+		}
+	}
+	for i, page := range store.pageNums() {
+		for _, num := range pageAnnotNums(store, page) {
+			dict := store.dict(num)
+			if opt, rect, ok := readAnnotationDict(store, dict); ok {
+				d.Annotations = append(d.Annotations, FormDataAnnotation{
+					Page:   i,
+					Name:   decodePDFTextString(store.resolve(pdfDictGet(dict, "/NM"))),
+					Rect:   rect,
+					Option: opt,
+				})
+			}
+		}
+	}
+	return d, nil
+}
+
+// ApplyFormData sets the form fields of the PDF in pdfData to the values of
+// d by fully qualified name, adds its annotations with appearance streams,
+// and returns the saved document. An annotation replaces an existing
+// annotation of the same type and rectangle on its page. Fields that the
+// document does not have are listed in the report.
+//
+// Example:
+//
+//	fd, _ := gopdf.ParseXFDF(xfdfData)
+//	out, report, err := gopdf.ApplyFormData(pdfData, fd, gopdf.FormFillOption{})
+//	if err == nil && len(report.UnmatchedFields) > 0 {
+//	    log.Printf("unknown fields: %v", report.UnmatchedFields)
+//	}
+func ApplyFormData(pdfData []byte, d *FormData, opt FormFillOption) ([]byte, *FormDataReport, error) {
+	store, err := newPDFObjectStore(pdfData)
+	if err != nil {
+		return nil, nil, err
+	}
+	f, err := newFormFiller(store)
+	if err != nil && !errors.Is(err, ErrNoAcroForm) {
+		return nil, nil, err
+	}
+	report := &FormDataReport{}
+	for _, df := range d.Fields {
+		if f == nil || f.byName[df.Name] == nil {
+			report.UnmatchedFields = append(report.UnmatchedFields, df.Name)
+			continue
+		}
+		if len(df.Values) > 1 {
+			err = f.SetValues(df.Name, df.Values)
+		} else {
+			err = f.SetValue(df.Name, df.Value)
+		}
+		if err != nil {
+			return nil, report, err
+		}
+		report.FieldsSet++
+	}
+	pages := store.pageNums()
+	for _, da := range d.Annotations {
+		if da.Page < 0 || da.Page >= len(pages) {
+			report.UnmatchedAnnotations++
+			continue
+		}
+		if applyAnnotation(store, pages[da.Page], da) {
+			report.AnnotationsUpdated++
+		} else {
+			report.AnnotationsAdded++
+		}
+	}
+	if f != nil {
+		out, err := f.Save(opt)
+		return out, report, err
+	}
+	if opt.Incremental {
+		return store.incremental(), report, nil
+	}
+	return store.rewrite(), report, nil
+}
+
+// pageAnnotNums returns the object numbers of the annotations of a page.
+func pageAnnotNums(store *pdfObjectStore, page int) []int {
+	return pdfRefs(store.resolve(pdfDictGet(store.dict(page), "/Annots")))
+}
+
+// applyAnnotation writes the annotation da with an appearance stream to
+// page. It replaces an annotation of the same type and rectangle and
+// reports whether it did.
+func applyAnnotation(store *pdfObjectStore, page int, da FormDataAnnotation) bool {
+	target := 0
+	for _, num := range pageAnnotNums(store, page) {
+		dict := store.dict(num)
+		t, ok := annotationTypeOf(pdfNameValue(pdfDictGet(dict, "/Subtype")))
+		rect := normalizeRect(pdfNumbers(store.resolve(pdfDictGet(dict, "/Rect"))))
+		if ok && t == da.Option.Type && sameRect(rect, da.rect()) {
+			target = num
+			break
+		}
+	}
+
+	// The annotation and its appearance are written as if on a page of
+	// height 0, which makes the top-left coordinates of the flipped option
+	// equal to PDF user space.
+	scratch := &GoPdf{}
+	flipped := flipAnnotation(da.Option, 0)
+	ap := newAnnotationAppearance(scratch, flipped)
+	apDict := fmt.Sprintf("<< /Type /XObject /Subtype /Form /FormType 1 /BBox [0 0 %.2f %.2f] /Matrix [1 0 0 1 0 0] %s>>",
+		ap.w, ap.h, ap.resources())
+	apNum := store.addStream(apDict, []byte(ap.buf.String()))
+	dict := annotationDict(scratch, flipped, apNum)
+	dict = pdfDictSet(dict, "/P", fmt.Sprintf("%d 0 R", page))
+	if da.Name != "" {
+		dict = pdfDictSet(dict, "/NM", encodePDFTextString(da.Name))
+	}
+
+	if target > 0 {
+		old := store.dict(target)
+		for _, key := range []string{"/NM", "/Popup", "/IRT"} {
+			if v := pdfDictGet(old, key); v != "" && pdfDictGet(dict, key) == "" {
+				dict = pdfDictSet(dict, key, v)
+			}
+		}
+		store.set(target, dict)
+		return true
+	}
+	num := store.add(dict)
+	ref := fmt.Sprintf("%d 0 R", num)
+	pd := store.dict(page)
+	annots := pdfDictGet(pd, "/Annots")
+	if arrNum, ok := pdfRef(annots); ok {
+		store.set(arrNum, appendPDFArray(store.resolve(annots), ref))
+	} else {
+		store.setDict(page, pdfDictSet(pd, "/Annots", appendPDFArray(annots, ref)))
+	}
+	return false
+}
+
+// appendPDFArray appends an item to an array value, which may be empty.
+func appendPDFArray(arr, item string) string {
+	items := append(parsePDFArray(arr), item)
+	return "[" + strings.Join(items, " ") + "]"
+}
+
+// annotationDict returns the annotation dictionary of opt as written by
+// annotationObj for the document gp.
+func annotationDict(gp *GoPdf, opt AnnotationOption, apObjID int) string {
+	var buf bytes.Buffer
+	a := annotationObj{opt: opt, apObjID: apObjID, getRoot: func() *GoPdf { return gp }}
+	a.write(&buf, 0)
+	return strings.TrimSpace(buf.String())
+}
+
+// pdfAnnotationDict returns the dictionary of an annotation in PDF user
+// space, without an appearance stream.
+func pdfAnnotationDict(opt AnnotationOption) string {
+	return annotationDict(&GoPdf{}, flipAnnotation(opt, 0), 0)
+}
+
+// readAnnotationDict reads a markup annotation dictionary into an option in
+// PDF user space and returns its rectangle. It reports false for widget,
+// link, popup and other unsupported annotations.
+func readAnnotationDict(store *pdfObjectStore, dict string) (AnnotationOption, [4]float64, bool) {
+	get := func(key string) string {
+		return store.resolve(pdfDictGet(dict, key))
+	}
+	t, ok := annotationTypeOf(pdfNameValue(get("/Subtype")))
+	if !ok {
+		return AnnotationOption{}, [4]float64{}, false
+	}
+	opt := AnnotationOption{Type: t}
+	rect := normalizeRect(pdfNumbers(get("/Rect")))
+	if !t.hasPoints() {
+		opt.X, opt.Y, opt.W, opt.H = rect[0], rect[1], rect[2]-rect[0], rect[3]-rect[1]
+	}
+	opt.Content = decodePDFTextString(get("/Contents"))
+	opt.Title = decodePDFTextString(get("/T"))
+	if c, ok := annotationColor(pdfNumbers(get("/C"))); ok {
+		opt.Color = c
+	}
+	if c, ok := annotationColor(pdfNumbers(get("/IC"))); ok {
+		opt.InteriorColor = &c
+	}
+	if v, err := strconv.ParseFloat(get("/CA"), 64); err == nil {
+		opt.Opacity = v
+	}
+	opt.Open = get("/Open") == "true"
+
+	if bs := store.resolveDict(pdfDictGet(dict, "/BS")); bs != "" {
+		opt.BorderWidth, _ = strconv.ParseFloat(store.resolve(pdfDictGet(bs, "/W")), 64)
+		opt.BorderStyle = AnnotationBorderStyle(pdfNameValue(pdfDictGet(bs, "/S")))
+		opt.DashPattern = pdfNumbers(store.resolve(pdfDictGet(bs, "/D")))
+	} else if border := pdfNumbers(get("/Border")); len(border) >= 3 {
+		opt.BorderWidth = border[2]
+	}
+
+	points := func(nums []float64) []Point {
+		var pts []Point
+		for i := 0; i+1 < len(nums); i += 2 {
+			pts = append(pts, Point{X: nums[i], Y: nums[i+1]})
+		}
+		return pts
+	}
+	switch t {
+	case AnnotInk:
+		for _, stroke := range parsePDFArray(get("/InkList")) {
+			opt.InkList = append(opt.InkList, points(pdfNumbers(store.resolve(stroke))))
+		}
+	case AnnotPolyline, AnnotPolygon:
+		opt.Vertices = points(pdfNumbers(get("/Vertices")))
+	case AnnotLine:
+		if l := pdfNumbers(get("/L")); len(l) == 4 {
+			opt.LineStart, opt.LineEnd = Point{X: l[0], Y: l[1]}, Point{X: l[2], Y: l[3]}
+		}
+	case AnnotStamp:
+		opt.Stamp = StampName(pdfNameValue(get("/Name")))
+	case AnnotFileAttachment:
+		if fs := store.resolveDict(pdfDictGet(dict, "/FS")); fs != "" {
+			opt.FileName = decodePDFTextString(store.resolve(pdfDictGet(fs, "/UF")))
+			if opt.FileName == "" {
+				opt.FileName = decodePDFTextString(store.resolve(pdfDictGet(fs, "/F")))
+			}
+		}
+	case AnnotRedact:
+		opt.OverlayText = decodePDFTextString(get("/OverlayText"))
+	}
+	if le := parsePDFArray(get("/LE")); len(le) == 2 {
+		opt.LineEndingStyles = [2]LineEndingStyle{
+			LineEndingStyle(pdfNameValue(le[0])), LineEndingStyle(pdfNameValue(le[1])),
+		}
+	}
+	if da := decodePDFTextString(get("/DA")); da != "" && (t == AnnotFreeText || t == AnnotRedact) {
+		// The text color of FreeText is the color of its default appearance.
+		_, size, color := parseDA(da)
+		opt.FontSize = size
+		if c, ok := annotationColor(pdfNumbers("[" + color + "]")); ok {
+			opt.Color = c
+		}
+	}
+	return opt, rect, true
+}
+
+// annotationColor converts a gray, RGB or CMYK color array to RGB.
+func annotationColor(c []float64) ([3]uint8, bool) {
+	channel := func(v float64) uint8 {
+		return uint8(math.Round(math.Max(0, math.Min(1, v)) * 255))
+	}
+	switch len(c) {
+	case 1:
+		g := channel(c[0])
+		return [3]uint8{g, g, g}, true
+	case 3:
+		return [3]uint8{channel(c[0]), channel(c[1]), channel(c[2])}, true
+	case 4:
+		k := 1 - c[3]
+		return [3]uint8{channel((1 - c[0]) * k), channel((1 - c[1]) * k), channel((1 - c[2]) * k)}, true
+	}
+	return [3]uint8{}, false
+}
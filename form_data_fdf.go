@@ -0,0 +1,197 @@
+package gopdf
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ============================================================
+// FDF — Forms Data Format (PDF 32000-1:2008, 12.7.8)
+// ============================================================
+
+// ErrInvalidFDF is returned when FDF data has no /FDF dictionary.
+var ErrInvalidFDF = errors.New("invalid FDF: no /FDF dictionary")
+
+// fdfNode is a node of the field name hierarchy of FDF and XFDF exports.
+type fdfNode struct {
+	name  string
+	field *FormDataField
+	kids  []*fdfNode
+}
+
+// fieldTree arranges the fields by the parts of their fully qualified
+// names, keeping the order of first appearance.
+func (d *FormData) fieldTree() []*fdfNode {
+	root := &fdfNode{}
+	for i := range d.Fields {
+		node := root
+		for _, part := range strings.Split(d.Fields[i].Name, ".") {
+			var next *fdfNode
+			for _, kid := range node.kids {
+				if kid.name == part {
+					next = kid
+					break
+				}
+			}
+			if next == nil {
+				next = &fdfNode{name: part}
+				node.kids = append(node.kids, next)
+			}
+			node = next
+		}
+		node.field = &d.Fields[i]
+	}
+	return root.kids
+}
+
+// ExportFDF returns the data as an FDF file. Field names are split into a
+// /Kids hierarchy at dots; annotations are written as annotation
+// dictionaries with their /Page.
+//
+// Example:
+//
+//	os.WriteFile("data.fdf", pdf.GetFormData().ExportFDF(), 0644)
+func (d *FormData) ExportFDF() []byte {
+	var buf bytes.Buffer
+	buf.WriteString("%FDF-1.2\n%\xe2\xe3\xcf\xd3\n1 0 obj\n<< /FDF <<\n")
+	if d.File != "" {
+		fmt.Fprintf(&buf, "/F %s\n", encodePDFTextString(d.File))
+	}
+	if len(d.Fields) > 0 {
+		buf.WriteString("/Fields [\n")
+		for _, node := range d.fieldTree() {
+			writeFDFField(&buf, node)
+		}
+		buf.WriteString("]\n")
+	}
+	if len(d.Annotations) > 0 {
+		buf.WriteString("/Annots [\n")
+		for _, a := range d.Annotations {
+			dict := pdfAnnotationDict(a.Option)
+			dict = pdfDictSet(dict, "/Page", fmt.Sprintf("%d", a.Page))
+			if a.Name != "" {
+				dict = pdfDictSet(dict, "/NM", encodePDFTextString(a.Name))
+			}
+			buf.WriteString(dict + "\n")
+		}
+		buf.WriteString("]\n")
+	}
+	buf.WriteString(">> >>\nendobj\ntrailer\n<< /Root 1 0 R >>\n%%EOF\n")
+	return buf.Bytes()
+}
+
+func writeFDFField(buf *bytes.Buffer, node *fdfNode) {
+	fmt.Fprintf(buf, "<< /T %s", encodePDFTextString(node.name))
+	if f := node.field; f != nil {
+		buf.WriteString(" /V ")
+		switch {
+		case len(f.Values) > 1:
+			items := make([]string, len(f.Values))
+			for i, v := range f.Values {
+				items[i] = encodePDFTextString(v)
+			}
+			buf.WriteString("[" + strings.Join(items, " ") + "]")
+		case f.Type == FormFieldCheckbox || f.Type == FormFieldRadio:
+			buf.WriteString(encodePDFName(f.Value))
+		default:
+			buf.WriteString(encodePDFTextString(f.Value))
+		}
+	}
+	if len(node.kids) > 0 {
+		buf.WriteString(" /Kids [\n")
+		for _, kid := range node.kids {
+			writeFDFField(buf, kid)
+		}
+		buf.WriteString("]")
+	}
+	buf.WriteString(" >>\n")
+}
+
+// ParseFDF parses an FDF file. Annotations without a /Page are placed on
+// the first page.
+//
+// Example:
+//
+//	data, _ := os.ReadFile("data.fdf")
+//	fd, err := gopdf.ParseFDF(data)
+func ParseFDF(data []byte) (*FormData, error) {
+	store, err := newPDFObjectStore(data)
+	if err != nil {
+		return nil, err
+	}
+	fdf := store.resolveDict(pdfDictGet(store.dict(store.catalog()), "/FDF"))
+	if fdf == "" {
+		return nil, ErrInvalidFDF
+	}
+	d := &FormData{}
+	file := store.resolve(pdfDictGet(fdf, "/F"))
+	if fs := store.resolveDict(file); fs != "" {
+		file = store.resolve(pdfDictGet(fs, "/F"))
+	}
+	d.File = decodePDFTextString(file)
+
+	var walk func(value, prefix string, depth int)
+	walk = func(value, prefix string, depth int) {
+		dict := store.resolveDict(value)
+		if dict == "" || depth > 64 {
+			return
+		}
+		name := prefix
+		if part := decodePDFTextString(store.resolve(pdfDictGet(dict, "/T"))); part != "" {
+			if name != "" {
+				name += "."
+			}
+			name += part
+		}
+		if v := store.resolve(pdfDictGet(dict, "/V")); v != "" {
+			d.Fields = append(d.Fields, fdfFieldValue(store, name, v))
+		}
+		for _, kid := range parsePDFArray(store.resolve(pdfDictGet(dict, "/Kids"))) {
+			walk(kid, name, depth+1)
+		}
+	}
+	for _, field := range parsePDFArray(store.resolve(pdfDictGet(fdf, "/Fields"))) {
+		walk(field, "", 0)
+	}
+
+	for _, item := range parsePDFArray(store.resolve(pdfDictGet(fdf, "/Annots"))) {
+		dict := store.resolveDict(item)
+		opt, rect, ok := readAnnotationDict(store, dict)
+		if !ok {
+			continue
+		}
+		a := FormDataAnnotation{
+			Name:   decodePDFTextString(store.resolve(pdfDictGet(dict, "/NM"))),
+			Rect:   rect,
+			Option: opt,
+		}
+		fmt.Sscan(store.resolve(pdfDictGet(dict, "/Page")), &a.Page)
+		d.Annotations = append(d.Annotations, a)
+	}
+	return d, nil
+}
+
+// fdfFieldValue decodes the /V value of an FDF field.
+func fdfFieldValue(store *pdfObjectStore, name, v string) FormDataField {
+	f := FormDataField{Name: name}
+	switch {
+	case strings.HasPrefix(v, "/"):
+		f.Type = FormFieldCheckbox
+		f.Value = pdfNameValue(v)
+	case strings.HasPrefix(v, "["):
+		for _, item := range parsePDFArray(v) {
+			f.Values = append(f.Values, decodePDFTextString(store.resolve(item)))
+		}
+		if len(f.Values) > 0 {
+			f.Value = f.Values[0]
+		}
+		if len(f.Values) < 2 {
+			f.Values = nil
+		}
+	default:
+		f.Value = decodePDFTextString(v)
+	}
+	return f
+}
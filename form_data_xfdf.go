@@ -0,0 +1,397 @@
+package gopdf
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// ============================================================
+// XFDF — XML Forms Data Format (ISO 19444-1)
+// ============================================================
+
+// ErrInvalidXFDF is returned when XML data has no xfdf root element.
+var ErrInvalidXFDF = errors.New("invalid XFDF: no xfdf element")
+
+// xfdfBorderStyles maps border styles to XFDF style attribute values.
+var xfdfBorderStyles = map[AnnotationBorderStyle]string{
+	AnnotBorderSolid:     "solid",
+	AnnotBorderDashed:    "dash",
+	AnnotBorderBeveled:   "bevelled",
+	AnnotBorderInset:     "inset",
+	AnnotBorderUnderline: "underline",
+}
+
+// xfdfNode is a generic XML element.
+type xfdfNode struct {
+	XMLName xml.Name
+	Attrs   []xml.Attr `xml:",any,attr"`
+	Nodes   []xfdfNode `xml:",any"`
+	Text    string     `xml:",chardata"`
+}
+
+func (n *xfdfNode) attr(name string) string {
+	for _, a := range n.Attrs {
+		if a.Name.Local == name {
+			return a.Value
+		}
+	}
+	return ""
+}
+
+func (n *xfdfNode) child(name string) *xfdfNode {
+	for i := range n.Nodes {
+		if n.Nodes[i].XMLName.Local == name {
+			return &n.Nodes[i]
+		}
+	}
+	return nil
+}
+
+// ExportXFDF returns the data as an XFDF document. Field names are split
+// into nested field elements at dots.
+//
+// Example:
+//
+//	os.WriteFile("data.xfdf", pdf.GetFormData().ExportXFDF(), 0644)
+func (d *FormData) ExportXFDF() []byte {
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+	buf.WriteString(`<xfdf xmlns="http://ns.adobe.com/xfdf/" xml:space="preserve">` + "\n")
+	if d.File != "" {
+		fmt.Fprintf(&buf, "<f href=\"%s\"/>\n", xmlEscape(d.File))
+	}
+	if len(d.Fields) > 0 {
+		buf.WriteString("<fields>\n")
+		for _, node := range d.fieldTree() {
+			writeXFDFField(&buf, node)
+		}
+		buf.WriteString("</fields>\n")
+	}
+	if len(d.Annotations) > 0 {
+		buf.WriteString("<annots>\n")
+		for i, a := range d.Annotations {
+			writeXFDFAnnotation(&buf, a, i)
+		}
+		buf.WriteString("</annots>\n")
+	}
+	buf.WriteString("</xfdf>\n")
+	return buf.Bytes()
+}
+
+func writeXFDFField(buf *bytes.Buffer, node *fdfNode) {
+	fmt.Fprintf(buf, "<field name=\"%s\">", xmlEscape(node.name))
+	if f := node.field; f != nil {
+		values := f.Values
+		if len(values) < 2 {
+			values = []string{f.Value}
+		}
+		for _, v := range values {
+			fmt.Fprintf(buf, "<value>%s</value>", xmlEscape(v))
+		}
+	}
+	if len(node.kids) > 0 {
+		buf.WriteString("\n")
+		for _, kid := range node.kids {
+			writeXFDFField(buf, kid)
+		}
+	}
+	buf.WriteString("</field>\n")
+}
+
+func writeXFDFAnnotation(buf *bytes.Buffer, a FormDataAnnotation, index int) {
+	opt := a.Option
+	opt.defaults()
+	element := strings.ToLower(annotationSubtypes[opt.Type])
+	name := a.Name
+	if name == "" {
+		name = fmt.Sprintf("annot%d", index+1)
+	}
+	r := a.rect()
+	attrs := [][2]string{
+		{"page", strconv.Itoa(a.Page)},
+		{"rect", xfdfNumbers(r[:]...)},
+		{"name", name},
+		{"flags", "print"},
+		{"color", xfdfColor(opt.Color)},
+		{"width", xfdfNumbers(opt.BorderWidth)},
+	}
+	add := func(key, value string) {
+		if value != "" {
+			attrs = append(attrs, [2]string{key, value})
+		}
+	}
+	add("title", opt.Title)
+	if opt.Opacity < 1 {
+		add("opacity", xfdfNumbers(opt.Opacity))
+	}
+	if opt.BorderStyle != "" {
+		add("style", xfdfBorderStyles[opt.BorderStyle])
+		if opt.BorderStyle == AnnotBorderDashed {
+			add("dashes", xfdfNumbers(opt.dashPattern()...))
+		}
+	}
+	if opt.InteriorColor != nil {
+		add("interior-color", xfdfColor(*opt.InteriorColor))
+	}
+	switch opt.Type {
+	case AnnotText:
+		add("icon", "Comment")
+		if opt.Open {
+			add("open", "yes")
+		}
+	case AnnotStamp:
+		add("icon", string(opt.Stamp))
+	case AnnotLine:
+		add("start", xfdfNumbers(opt.LineStart.X, opt.LineStart.Y))
+		add("end", xfdfNumbers(opt.LineEnd.X, opt.LineEnd.Y))
+	case AnnotFileAttachment:
+		add("file", opt.FileName)
+	case AnnotRedact:
+		add("overlay-text", opt.OverlayText)
+	case AnnotHighlight, AnnotUnderline, AnnotStrikeOut, AnnotSquiggly:
+		add("coords", xfdfNumbers(r[0], r[3], r[2], r[3], r[0], r[1], r[2], r[1]))
+	}
+	if opt.Type == AnnotLine || opt.Type == AnnotPolyline {
+		if start, end := opt.lineEndings(); start != LineEndNone || end != LineEndNone {
+			add("head", string(start))
+			add("tail", string(end))
+		}
+	}
+
+	fmt.Fprintf(buf, "<%s", element)
+	for _, kv := range attrs {
+		fmt.Fprintf(buf, " %s=\"%s\"", kv[0], xmlEscape(kv[1]))
+	}
+	buf.WriteString(">")
+	if opt.Content != "" {
+		fmt.Fprintf(buf, "<contents>%s</contents>", xmlEscape(opt.Content))
+	}
+	if opt.Type == AnnotFreeText {
+		fmt.Fprintf(buf, "<defaultappearance>%s</defaultappearance>",
+			xmlEscape(fmt.Sprintf("/Helv %s Tf %s", xfdfNumbers(opt.FontSize), xfdfColorOp(opt.Color))))
+	}
+	points := func(pts []Point) string {
+		parts := make([]string, len(pts))
+		for i, pt := range pts {
+			parts[i] = xfdfNumbers(pt.X, pt.Y)
+		}
+		return strings.Join(parts, ";")
+	}
+	switch opt.Type {
+	case AnnotInk:
+		buf.WriteString("<inklist>")
+		for _, stroke := range opt.InkList {
+			fmt.Fprintf(buf, "<gesture>%s</gesture>", points(stroke))
+		}
+		buf.WriteString("</inklist>")
+	case AnnotPolyline, AnnotPolygon:
+		fmt.Fprintf(buf, "<vertices>%s</vertices>", points(opt.Vertices))
+	}
+	fmt.Fprintf(buf, "</%s>\n", element)
+}
+
+// ParseXFDF parses an XFDF document.
+//
+// Example:
+//
+//	data, _ := os.ReadFile("data.xfdf")
+//	fd, err := gopdf.ParseXFDF(data)
+func ParseXFDF(data []byte) (*FormData, error) {
+	var root xfdfNode
+	if err := xml.Unmarshal(data, &root); err != nil {
+		return nil, fmt.Errorf("xfdf: %w", err)
+	}
+	if root.XMLName.Local != "xfdf" {
+		return nil, ErrInvalidXFDF
+	}
+	d := &FormData{}
+	if f := root.child("f"); f != nil {
+		d.File = f.attr("href")
+	}
+
+	var walk func(n *xfdfNode, prefix string)
+	walk = func(n *xfdfNode, prefix string) {
+		name := n.attr("name")
+		if prefix != "" {
+			name = prefix + "." + name
+		}
+		var values []string
+		for i := range n.Nodes {
+			switch n.Nodes[i].XMLName.Local {
+			case "value":
+				values = append(values, n.Nodes[i].Text)
+			case "value-richtext":
+				values = append(values, strings.TrimSpace(xfdfPlainText(&n.Nodes[i])))
+			}
+		}
+		if len(values) > 0 {
+			f := FormDataField{Name: name, Value: values[0]}
+			if len(values) > 1 {
+				f.Values = values
+			}
+			d.Fields = append(d.Fields, f)
+		}
+		for i := range n.Nodes {
+			if n.Nodes[i].XMLName.Local == "field" {
+				walk(&n.Nodes[i], name)
+			}
+		}
+	}
+	if fields := root.child("fields"); fields != nil {
+		for i := range fields.Nodes {
+			if fields.Nodes[i].XMLName.Local == "field" {
+				walk(&fields.Nodes[i], "")
+			}
+		}
+	}
+
+	if annots := root.child("annots"); annots != nil {
+		for i := range annots.Nodes {
+			if a, ok := parseXFDFAnnotation(&annots.Nodes[i]); ok {
+				d.Annotations = append(d.Annotations, a)
+			}
+		}
+	}
+	return d, nil
+}
+
+// parseXFDFAnnotation reads an annotation element. It reports false for
+// unsupported annotation types.
+func parseXFDFAnnotation(n *xfdfNode) (FormDataAnnotation, bool) {
+	t, ok := annotationTypeOf(n.XMLName.Local)
+	if !ok {
+		return FormDataAnnotation{}, false
+	}
+	a := FormDataAnnotation{Name: n.attr("name")}
+	a.Page, _ = strconv.Atoi(n.attr("page"))
+	a.Rect = normalizeRect(xfdfParseNumbers(n.attr("rect")))
+	opt := AnnotationOption{Type: t, Title: n.attr("title")}
+	if !t.hasPoints() {
+		opt.X, opt.Y, opt.W, opt.H = a.Rect[0], a.Rect[1], a.Rect[2]-a.Rect[0], a.Rect[3]-a.Rect[1]
+	}
+	if c, ok := parseXFDFColor(n.attr("color")); ok {
+		opt.Color = c
+	}
+	if c, ok := parseXFDFColor(n.attr("interior-color")); ok {
+		opt.InteriorColor = &c
+	}
+	opt.Opacity, _ = strconv.ParseFloat(n.attr("opacity"), 64)
+	opt.BorderWidth, _ = strconv.ParseFloat(n.attr("width"), 64)
+	for style, name := range xfdfBorderStyles {
+		if name == n.attr("style") {
+			opt.BorderStyle = style
+		}
+	}
+	opt.DashPattern = xfdfParseNumbers(n.attr("dashes"))
+	opt.Open = n.attr("open") == "yes" || n.attr("open") == "true"
+	if c := n.child("contents"); c != nil {
+		opt.Content = c.Text
+	} else if c := n.child("contents-richtext"); c != nil {
+		opt.Content = strings.TrimSpace(xfdfPlainText(c))
+	}
+	if head, tail := n.attr("head"), n.attr("tail"); head != "" || tail != "" {
+		opt.LineEndingStyles = [2]LineEndingStyle{LineEndingStyle(head), LineEndingStyle(tail)}
+	}
+
+	points := func(s string) []Point {
+		nums := xfdfParseNumbers(s)
+		var pts []Point
+		for i := 0; i+1 < len(nums); i += 2 {
+			pts = append(pts, Point{X: nums[i], Y: nums[i+1]})
+		}
+		return pts
+	}
+	switch t {
+	case AnnotInk:
+		if ink := n.child("inklist"); ink != nil {
+			for i := range ink.Nodes {
+				if ink.Nodes[i].XMLName.Local == "gesture" {
+					opt.InkList = append(opt.InkList, points(ink.Nodes[i].Text))
+				}
+			}
+		}
+	case AnnotPolyline, AnnotPolygon:
+		if v := n.child("vertices"); v != nil {
+			opt.Vertices = points(v.Text)
+		}
+	case AnnotLine:
+		if start := points(n.attr("start")); len(start) == 1 {
+			opt.LineStart = start[0]
+		}
+		if end := points(n.attr("end")); len(end) == 1 {
+			opt.LineEnd = end[0]
+		}
+	case AnnotStamp:
+		opt.Stamp = StampName(n.attr("icon"))
+	case AnnotFileAttachment:
+		opt.FileName = n.attr("file")
+	case AnnotRedact:
+		opt.OverlayText = n.attr("overlay-text")
+	case AnnotFreeText:
+		if da := n.child("defaultappearance"); da != nil {
+			_, size, color := parseDA(da.Text)
+			opt.FontSize = size
+			if c, ok := annotationColor(pdfNumbers("[" + color + "]")); ok {
+				opt.Color = c
+			}
+		}
+	}
+	a.Option = opt
+	return a, true
+}
+
+// xfdfPlainText returns the character data of an element and its
+// descendants, such as the XHTML body of rich text.
+func xfdfPlainText(n *xfdfNode) string {
+	var sb strings.Builder
+	sb.WriteString(n.Text)
+	for i := range n.Nodes {
+		sb.WriteString(xfdfPlainText(&n.Nodes[i]))
+	}
+	return sb.String()
+}
+
+// xfdfNumbers formats numbers separated by commas.
+func xfdfNumbers(nums ...float64) string {
+	parts := make([]string, len(nums))
+	for i, v := range nums {
+		parts[i] = strconv.FormatFloat(math.Round(v*100)/100, 'f', -1, 64)
+	}
+	return strings.Join(parts, ",")
+}
+
+// xfdfParseNumbers parses numbers separated by commas, semicolons or
+// spaces.
+func xfdfParseNumbers(s string) []float64 {
+	var nums []float64
+	for _, f := range strings.FieldsFunc(s, func(r rune) bool {
+		return r == ',' || r == ';' || r == ' ' || r == '\n' || r == '\r' || r == '\t'
+	}) {
+		if v, err := strconv.ParseFloat(f, 64); err == nil {
+			nums = append(nums, v)
+		}
+	}
+	return nums
+}
+
+func xfdfColor(c [3]uint8) string {
+	return fmt.Sprintf("#%02X%02X%02X", c[0], c[1], c[2])
+}
+
+func xfdfColorOp(c [3]uint8) string {
+	return fmt.Sprintf("%s %s %s rg", xfdfNumbers(float64(c[0])/255), xfdfNumbers(float64(c[1])/255), xfdfNumbers(float64(c[2])/255))
+}
+
+func parseXFDFColor(s string) ([3]uint8, bool) {
+	s = strings.TrimPrefix(strings.TrimSpace(s), "#")
+	v, err := strconv.ParseUint(s, 16, 32)
+	if len(s) != 6 || err != nil {
+		return [3]uint8{}, false
+	}
+	return [3]uint8{uint8(v >> 16), uint8(v >> 8), uint8(v)}, true
+}
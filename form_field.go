@@ -85,6 +85,42 @@ type FormField struct {
 	HasFill bool
 	// Checked is the initial state for checkboxes.
 	Checked bool
+	// Buttons are the buttons of a radio group. Each button is a separate
+	// widget; the button whose Value equals the field Value is selected.
+	// Without Buttons a radio field has a single button at X, Y, W, H.
+	Buttons []RadioButton
+	// ListBox shows a Choice field as a list box instead of a combo box.
+	ListBox bool
+	// MultiSelect allows selecting several options of a list box.
+	MultiSelect bool
+	// Editable allows typing a value that is not among the options of a
+	// combo box.
+	Editable bool
+	// TopIndex is the index of the first visible option of a list box.
+	TopIndex int
+	// Values are the selected options of a multi-select list box.
+	Values []string
+	// Caption is the label of a push button.
+	Caption string
+	// Actions are the actions run when the field is activated, entered
+	// with the mouse, focused and so on. Activate is the action of a
+	// push button.
+	Actions *FieldActions
+	// Align is the text alignment of text and choice fields: Left
+	// (default), Center or Right.
+	Align int
+	// Comb divides a text field into MaxLen equally spaced cells, one
+	// per character.
+	Comb bool
+	// CheckStyle is the mark of a checked check box or a selected radio
+	// button.
+	CheckStyle CheckStyle
+	// Format is the number, percentage or date format of a text field.
+	Format *FieldFormat
+	// Validate restricts a numeric text field to a range.
+	Validate *FieldRange
+	// Calculate computes the value of a text field from other fields.
+	Calculate *FieldCalculation
 	// PageNo is the 1-based page number (set internally).
 	pageNo int
 }
@@ -95,6 +131,9 @@ type formFieldObj struct {
 	pageRef  int // page object ID (1-based)
 	fontRef  string // font resource name like "/F1"
 	fontObjID int  // font object ID (1-based)
+	kids     []int // widget object IDs of a radio group (1-based)
+	ap       widgetAppearance
+	getRoot  func() *GoPdf
 }
 
 func (f formFieldObj) init(fn func() *GoPdf) {}
@@ -105,6 +144,9 @@ func (f formFieldObj) getType() string {
 
 func (f formFieldObj) write(w io.Writer, objID int) error {
 	field := f.field
+	if field.Type == FormFieldRadio {
+		return f.writeRadioGroup(w)
+	}
 	x1 := field.X
 	y1 := field.Y
 	x2 := field.X + field.W
@@ -117,7 +159,7 @@ func (f formFieldObj) write(w io.Writer, objID int) error {
 	fmt.Fprintf(w, "/P %d 0 R\n", f.pageRef)
 	fmt.Fprintf(w, "/T (%s)\n", escapeAnnotString(field.Name))
 
-	if field.Value != "" {
+	if field.Value != "" && (field.Type == FormFieldText || (field.Type == FormFieldChoice && len(field.Values) == 0)) {
 		fmt.Fprintf(w, "/V (%s)\n", escapeAnnotString(field.Value))
 		fmt.Fprintf(w, "/DV (%s)\n", escapeAnnotString(field.Value))
 	}
@@ -140,6 +182,9 @@ func (f formFieldObj) write(w io.Writer, objID int) error {
 		if field.MaxLen > 0 {
 			fmt.Fprintf(w, "/MaxLen %d\n", field.MaxLen)
 		}
+		if field.Comb && field.MaxLen > 0 {
+			ff |= 1 << 24 // bit 25 = comb
+		}
 	case FormFieldCheckbox:
 		io.WriteString(w, "/FT /Btn\n")
 		if field.Checked {
@@ -147,10 +192,6 @@ func (f formFieldObj) write(w io.Writer, objID int) error {
 		} else {
 			io.WriteString(w, "/V /Off\n/AS /Off\n")
 		}
-	case FormFieldRadio:
-		io.WriteString(w, "/FT /Btn\n")
-		ff |= 1 << 15 // bit 16 = radio
-		ff |= 1 << 14 // bit 15 = NoToggleToOff
 	case FormFieldChoice:
 		io.WriteString(w, "/FT /Ch\n")
 		if len(field.Options) > 0 {
@@ -160,6 +201,20 @@ func (f formFieldObj) write(w io.Writer, objID int) error {
 			}
 			io.WriteString(w, "]\n")
 		}
+		if field.ListBox {
+			if field.MultiSelect {
+				ff |= 1 << 21 // bit 22 = multi-select
+			}
+			if field.TopIndex > 0 {
+				fmt.Fprintf(w, "/TI %d\n", field.TopIndex)
+			}
+		} else {
+			ff |= 1 << 17 // bit 18 = combo
+			if field.Editable {
+				ff |= 1 << 18 // bit 19 = edit
+			}
+		}
+		f.writeChoiceSelection(w)
 	case FormFieldButton:
 		io.WriteString(w, "/FT /Btn\n")
 		ff |= 1 << 16 // bit 17 = pushbutton
@@ -186,9 +241,14 @@ func (f formFieldObj) write(w io.Writer, objID int) error {
 			float64(field.FillColor[2])/255)
 	}
 	if field.Type == FormFieldCheckbox {
-		io.WriteString(w, "  /CA (4)\n") // checkmark character
+		glyph, _ := field.CheckStyle.glyph(false)
+		fmt.Fprintf(w, "  /CA (%s)\n", glyph)
+	}
+	if field.Type == FormFieldButton && field.Caption != "" {
+		fmt.Fprintf(w, "  /CA (%s)\n", escapeAnnotString(field.Caption))
 	}
 	io.WriteString(w, ">>\n")
+	f.ap.write(w)
 
 	// Border style
 	if field.HasBorder {
@@ -211,6 +271,13 @@ func (f formFieldObj) write(w io.Writer, objID int) error {
 			fmt.Fprintf(w, "/DA (/Helv %.1f Tf %.4f %.4f %.4f rg)\n",
 				fontSize, r, g, b)
 		}
+		if q := field.quadding(); q > 0 {
+			fmt.Fprintf(w, "/Q %d\n", q)
+		}
+	}
+	field.writeAdditionalActions(w, actionWriter{f.getRoot(), objID})
+	if field.Type == FormFieldCheckbox {
+		fmt.Fprintf(w, "/DA (/ZaDb 0 Tf %s rg)\n", formColor(field.Color))
 	}
 
 	io.WriteString(w, ">>\n")
@@ -244,6 +311,15 @@ func (gp *GoPdf) AddFormField(field FormField) error {
 	if field.W <= 0 || field.H <= 0 {
 		return fmt.Errorf("form field width and height must be positive")
 	}
+	if field.Comb && field.MaxLen <= 0 {
+		return fmt.Errorf("comb field %q needs MaxLen", field.Name)
+	}
+
+	if field.Actions != nil {
+		a := *field.Actions
+		gp.actionsToPoints(&a.Activate, &a.Enter, &a.Exit, &a.Down, &a.Up, &a.Focus, &a.Blur)
+		field.Actions = &a
+	}
 
 	// Find current page object
 	pageObjID := gp.findCurrentPageObjID()
@@ -276,8 +352,22 @@ func (gp *GoPdf) AddFormField(field FormField) error {
 		pageRef:  pageObjID,
 		fontRef:  fontRef,
 		fontObjID: fontObjID,
+		getRoot:  func() *GoPdf { return gp },
+	}
+	if field.Type == FormFieldRadio {
+		return gp.addRadioGroup(ffObj)
 	}
+	// The appearance streams follow the field object.
+	var streams []IObj
+	ffObj.ap, streams = gp.fieldAppearances(ffObj).layout(len(gp.pdfObjs) + 2)
 	idx := gp.addObj(ffObj)
+	ref := formFieldRef{
+		field:  field,
+		objIdx: idx,
+	}
+	for _, stream := range streams {
+		ref.extraIdx = append(ref.extraIdx, gp.addObj(stream))
+	}
 
 	// Add to page's annotation list
 	page := gp.findCurrentPageObj()
@@ -285,10 +375,7 @@ func (gp *GoPdf) AddFormField(field FormField) error {
 		page.LinkObjIds = append(page.LinkObjIds, idx+1)
 	}
 
-	gp.formFields = append(gp.formFields, formFieldRef{
-		field:  field,
-		objIdx: idx,
-	})
+	gp.formFields = append(gp.formFields, ref)
 
 	return nil
 }
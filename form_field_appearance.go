@@ -0,0 +1,375 @@
+package gopdf
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// ============================================================
+// Appearance streams of form field widgets
+// ============================================================
+
+// CheckStyle is the mark of a checked check box or a selected radio button.
+type CheckStyle int
+
+const (
+	// CheckStyleDefault is a check mark for check boxes and a circle for
+	// radio buttons.
+	CheckStyleDefault CheckStyle = iota
+	// CheckStyleCheck is a check mark.
+	CheckStyleCheck
+	// CheckStyleCross is a cross.
+	CheckStyleCross
+	// CheckStyleCircle is a filled circle.
+	CheckStyleCircle
+	// CheckStyleSquare is a filled square.
+	CheckStyleSquare
+	// CheckStyleDiamond is a filled diamond.
+	CheckStyleDiamond
+	// CheckStyleStar is a filled star.
+	CheckStyleStar
+)
+
+// glyph returns the ZapfDingbats character of the style and its width in
+// 1/1000 em.
+func (s CheckStyle) glyph(radio bool) (string, float64) {
+	if s == CheckStyleDefault {
+		s = CheckStyleCheck
+		if radio {
+			s = CheckStyleCircle
+		}
+	}
+	switch s {
+	case CheckStyleCross:
+		return "8", 677
+	case CheckStyleCircle:
+		return "l", 791
+	case CheckStyleSquare:
+		return "n", 761
+	case CheckStyleDiamond:
+		return "u", 759
+	case CheckStyleStar:
+		return "H", 816
+	}
+	return "4", 846
+}
+
+// Font dictionaries of the standard fonts used by widget appearances and
+// the AcroForm default resources.
+const (
+	helvFontDict = "<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>"
+	zaDbFontDict = "<< /Type /Font /Subtype /Type1 /BaseFont /ZapfDingbats >>"
+)
+
+// appearanceState is the interaction state an appearance stream is drawn for.
+type appearanceState int
+
+const (
+	appearanceNormal appearanceState = iota
+	appearanceRollover
+	appearanceDown
+)
+
+// widgetAppearance holds the 1-based object IDs of the appearance streams
+// of a widget. Check boxes and radio buttons have an on and an off stream
+// for the normal and down states; push buttons have normal, rollover and
+// down streams; text and choice fields only a normal stream.
+type widgetAppearance struct {
+	onState                string // on state name of check boxes and radio buttons
+	normal, rollover, down int
+	offNormal, offDown     int
+}
+
+func (a widgetAppearance) write(w io.Writer) {
+	if a.normal == 0 {
+		return
+	}
+	if a.onState != "" {
+		state := encodePDFName(a.onState)
+		fmt.Fprintf(w, "/AP << /N << %s %d 0 R /Off %d 0 R >> /D << %s %d 0 R /Off %d 0 R >> >>\n",
+			state, a.normal, a.offNormal, state, a.down, a.offDown)
+		return
+	}
+	fmt.Fprintf(w, "/AP << /N %d 0 R", a.normal)
+	if a.rollover > 0 {
+		fmt.Fprintf(w, " /R %d 0 R", a.rollover)
+	}
+	if a.down > 0 {
+		fmt.Fprintf(w, " /D %d 0 R", a.down)
+	}
+	io.WriteString(w, " >>\n")
+}
+
+// appearanceSet holds the appearance streams of a widget before they are
+// added to the document.
+type appearanceSet struct {
+	onState                string
+	normal, rollover, down *formAppearanceObj
+	offNormal, offDown     *formAppearanceObj
+}
+
+// layout numbers the streams of the set consecutively from the object ID
+// first and returns their IDs and the streams in that order.
+func (s appearanceSet) layout(first int) (widgetAppearance, []IObj) {
+	ap := widgetAppearance{onState: s.onState}
+	var objs []IObj
+	assign := func(id *int, obj *formAppearanceObj) {
+		if obj != nil {
+			*id = first + len(objs)
+			objs = append(objs, obj)
+		}
+	}
+	assign(&ap.normal, s.normal)
+	assign(&ap.rollover, s.rollover)
+	assign(&ap.down, s.down)
+	assign(&ap.offNormal, s.offNormal)
+	assign(&ap.offDown, s.offDown)
+	return ap, objs
+}
+
+// fieldAppearances returns the appearance streams of the widget of a field
+// other than a radio group.
+func (gp *GoPdf) fieldAppearances(f formFieldObj) appearanceSet {
+	switch f.field.Type {
+	case FormFieldCheckbox:
+		return gp.toggleAppearances(f.field, f.field.W, f.field.H, "Yes")
+	case FormFieldButton:
+		return gp.pushButtonAppearances(f)
+	}
+	return appearanceSet{normal: gp.textFieldAppearance(f)}
+}
+
+func (gp *GoPdf) newFormAppearance(w, h float64, content, resources string) *formAppearanceObj {
+	return &formAppearanceObj{
+		w:         w,
+		h:         h,
+		content:   content,
+		resources: resources,
+		getRoot:   func() *GoPdf { return gp },
+	}
+}
+
+// textFieldAppearance returns the normal appearance of a text, choice or
+// signature field showing its current value in the field font.
+func (gp *GoPdf) textFieldAppearance(f formFieldObj) *formAppearanceObj {
+	field := f.field
+	var buf strings.Builder
+	bw := drawFieldFrame(&buf, field, field.W, field.H, fieldBackground(field, appearanceNormal), false)
+	if field.Type == FormFieldSignature {
+		return gp.newFormAppearance(field.W, field.H, buf.String(), "")
+	}
+
+	value, red := field.displayValue()
+	text := value
+	if field.Type == FormFieldChoice && field.ListBox {
+		text = strings.Join(field.Options, "")
+	}
+	font, resources := gp.fieldFormFont(f, text)
+	color := formColor(field.Color) + " rg"
+	if red {
+		color = "1 0 0 rg"
+	}
+	size := field.FontSize
+	if size == 0 {
+		size = 12
+	}
+	comb := field.Type == FormFieldText && field.Comb && field.MaxLen > 0
+	if comb && bw > 0 {
+		// cell dividers
+		fmt.Fprintf(&buf, "%s RG\n%.2f w\n", formColor(field.BorderColor), bw)
+		cell := field.W / float64(field.MaxLen)
+		for i := 1; i < field.MaxLen; i++ {
+			fmt.Fprintf(&buf, "%.2f 0 m %.2f %.2f l S\n", float64(i)*cell, float64(i)*cell, field.H)
+		}
+	}
+
+	buf.WriteString("/Tx BMC\nq\n")
+	fmt.Fprintf(&buf, "%.2f %.2f %.2f %.2f re W n\n", bw, bw, field.W-2*bw, field.H-2*bw)
+	layout := fieldTextLayout{buf: &buf, font: font, w: field.W, h: field.H, bw: bw,
+		size: size, color: color, q: field.quadding()}
+	switch {
+	case field.Type == FormFieldChoice && field.ListBox:
+		selected := make(map[int]bool)
+		for i, opt := range field.Options {
+			for _, v := range append([]string{field.Value}, field.Values...) {
+				if v != "" && v == opt {
+					selected[i] = true
+				}
+			}
+		}
+		layout.listBox(field.Options, selected, field.TopIndex)
+	case value == "":
+	case comb:
+		layout.comb(value, field.MaxLen)
+	case field.Type == FormFieldText && field.Multiline:
+		layout.multiline(value)
+	default:
+		layout.singleLine(value)
+	}
+	buf.WriteString("Q\nEMC\n")
+	return gp.newFormAppearance(field.W, field.H, buf.String(), resources)
+}
+
+// toggleAppearances returns the on and off appearances of a check box or
+// radio button of size w×h in the normal and down states.
+func (gp *GoPdf) toggleAppearances(field FormField, w, h float64, onState string) appearanceSet {
+	radio := field.Type == FormFieldRadio
+	glyph, gw := field.CheckStyle.glyph(radio)
+	stream := func(state appearanceState, on bool) *formAppearanceObj {
+		var buf strings.Builder
+		bw := drawFieldFrame(&buf, field, w, h, fieldBackground(field, state), radio)
+		if inner := min(w, h) - 2*bw - 2; on && inner > 0 {
+			size := inner * 0.8
+			if gw*size/1000 > inner {
+				size = inner * 1000 / gw
+			}
+			fmt.Fprintf(&buf, "q\nBT\n/ZaDb %.2f Tf\n%s rg\n%.2f %.2f Td\n(%s) Tj\nET\nQ\n",
+				size, formColor(field.Color), (w-gw*size/1000)/2, (h-0.7*size)/2, glyph)
+		}
+		return gp.newFormAppearance(w, h, buf.String(), "/Font << /ZaDb "+zaDbFontDict+" >>")
+	}
+	return appearanceSet{
+		onState:   onState,
+		normal:    stream(appearanceNormal, true),
+		down:      stream(appearanceDown, true),
+		offNormal: stream(appearanceNormal, false),
+		offDown:   stream(appearanceDown, false),
+	}
+}
+
+// pushButtonAppearances returns the normal, rollover and down appearances
+// of a push button: the background, border and centered caption. The
+// caption moves by one point while the button is pressed.
+func (gp *GoPdf) pushButtonAppearances(f formFieldObj) appearanceSet {
+	field := f.field
+	font, resources := gp.fieldFormFont(f, field.Caption)
+	size := field.FontSize
+	if size == 0 {
+		size = 12
+	}
+	stream := func(state appearanceState) *formAppearanceObj {
+		var buf strings.Builder
+		bw := drawFieldFrame(&buf, field, field.W, field.H, fieldBackground(field, state), false)
+		if field.Caption != "" {
+			buf.WriteString("q\n")
+			if state == appearanceDown {
+				buf.WriteString("1 0 0 1 1 -1 cm\n")
+			}
+			layout := fieldTextLayout{buf: &buf, font: font, w: field.W, h: field.H, bw: bw,
+				size: size, color: formColor(field.Color) + " rg", q: 1}
+			layout.singleLine(field.Caption)
+			buf.WriteString("Q\n")
+		}
+		return gp.newFormAppearance(field.W, field.H, buf.String(), resources)
+	}
+	return appearanceSet{
+		normal:   stream(appearanceNormal),
+		rollover: stream(appearanceRollover),
+		down:     stream(appearanceDown),
+	}
+}
+
+// fieldBackground returns the fill operator of the background of a widget
+// in a state, or "" for none. Rollover lightens and down darkens the fill;
+// widgets without a fill get a gray background in those states.
+func fieldBackground(field FormField, state appearanceState) string {
+	switch state {
+	case appearanceRollover:
+		if !field.HasFill {
+			return "0.9 g"
+		}
+		return mixColor(field.FillColor, 1, 0.3)
+	case appearanceDown:
+		if !field.HasFill {
+			return "0.75 g"
+		}
+		return mixColor(field.FillColor, 0, 0.25)
+	}
+	if !field.HasFill {
+		return ""
+	}
+	return formColor(field.FillColor) + " rg"
+}
+
+// mixColor mixes c with the gray level gray in proportion t and returns
+// the fill operator.
+func mixColor(c [3]uint8, gray, t float64) string {
+	var v [3]float64
+	for i := range c {
+		v[i] = float64(c[i])/255*(1-t) + gray*t
+	}
+	return fmt.Sprintf("%.4f %.4f %.4f rg", v[0], v[1], v[2])
+}
+
+// drawFieldFrame draws the background bg and the border of a widget of
+// size w×h and returns the border width. Radio buttons are drawn as
+// circles.
+func drawFieldFrame(buf *strings.Builder, field FormField, w, h float64, bg string, circle bool) float64 {
+	bw := 0.0
+	if field.HasBorder {
+		bw = 1
+	}
+	if circle {
+		r := min(w, h) / 2
+		if bg != "" {
+			fmt.Fprintf(buf, "%s\n", bg)
+			ellipsePath(buf, w/2, h/2, r-bw/2, r-bw/2)
+			buf.WriteString("f\n")
+		}
+		if bw > 0 {
+			fmt.Fprintf(buf, "%s RG\n%.2f w\n", formColor(field.BorderColor), bw)
+			ellipsePath(buf, w/2, h/2, r-bw/2, r-bw/2)
+			buf.WriteString("S\n")
+		}
+		return bw
+	}
+	if bg != "" {
+		fmt.Fprintf(buf, "%s\n0 0 %.2f %.2f re f\n", bg, w, h)
+	}
+	if bw > 0 {
+		fmt.Fprintf(buf, "%s RG\n%.2f w\n%.2f %.2f %.2f %.2f re S\n",
+			formColor(field.BorderColor), bw, bw/2, bw/2, w-bw, h-bw)
+	}
+	return bw
+}
+
+// quadding returns the /Q value of the field alignment.
+func (field FormField) quadding() int {
+	switch {
+	case field.Align&Center == Center:
+		return 1
+	case field.Align&Right == Right:
+		return 2
+	}
+	return 0
+}
+
+// fieldFormFont returns the font of the appearance of a field and the
+// resources referencing it: the TTF subset of the field font family with
+// the characters of text added, otherwise Helvetica.
+func (gp *GoPdf) fieldFormFont(f formFieldObj, text string) (*formFont, string) {
+	if f.fontObjID > 0 && f.fontObjID <= len(gp.pdfObjs) {
+		if sub, ok := gp.pdfObjs[f.fontObjID-1].(*SubsetFontObj); ok && sub.standard == nil {
+			sub.AddChars(text)
+			font := &formFont{
+				name:      strings.TrimPrefix(f.fontRef, "/"),
+				ref:       fmt.Sprintf("%d 0 R", f.fontObjID),
+				codes:     make(map[rune]uint16),
+				cidWidths: make(map[uint16]float64),
+				ascent:    sub.GetAscenderPx(1000),
+				descent:   sub.GetDescenderPx(1000),
+			}
+			for _, r := range text {
+				if glyph, err := sub.CharIndex(r); err == nil {
+					font.codes[r] = uint16(glyph)
+					font.cidWidths[uint16(glyph)] = float64(sub.GlyphIndexToPdfWidth(glyph))
+				}
+			}
+			return font, fmt.Sprintf("/Font << /%s %s >>", font.name, font.ref)
+		}
+	}
+	font := standardFontMetrics("Helv", "Helvetica")
+	font.ref = helvFontDict
+	return font, "/Font << /Helv " + helvFontDict + " >>"
+}
@@ -31,21 +31,25 @@ func (gp *GoPdf) DeleteFormField(name string) error {
 
 	ref := gp.formFields[idx]
 
-	// Null out the PDF object.
-	if ref.objIdx >= 0 && ref.objIdx < len(gp.pdfObjs) {
-		gp.pdfObjs[ref.objIdx] = nullObj{}
+	// Null out the PDF objects of the field, its widgets and appearances.
+	removed := make(map[int]bool)
+	for _, objIdx := range append([]int{ref.objIdx}, ref.extraIdx...) {
+		if objIdx >= 0 && objIdx < len(gp.pdfObjs) {
+			gp.pdfObjs[objIdx] = nullObj{}
+			removed[objIdx+1] = true
+		}
 	}
 
 	// Remove from page's annotation list.
-	objID := ref.objIdx + 1
 	for _, obj := range gp.pdfObjs {
 		if page, ok := obj.(*PageObj); ok {
-			for j, id := range page.LinkObjIds {
-				if id == objID {
-					page.LinkObjIds = append(page.LinkObjIds[:j], page.LinkObjIds[j+1:]...)
-					break
+			kept := page.LinkObjIds[:0]
+			for _, id := range page.LinkObjIds {
+				if !removed[id] {
+					kept = append(kept, id)
 				}
 			}
+			page.LinkObjIds = kept
 		}
 	}
 
@@ -69,7 +73,14 @@ func (gp *GoPdf) ModifyFormFieldValue(name, value string) error {
 			if ref.objIdx >= 0 && ref.objIdx < len(gp.pdfObjs) {
 				if ffObj, ok := gp.pdfObjs[ref.objIdx].(formFieldObj); ok {
 					ffObj.field.Value = value
+					ffObj.field.Values = nil
 					gp.pdfObjs[ref.objIdx] = ffObj
+					// Redraw the value of text and choice fields.
+					apIdx := ffObj.ap.normal - 1
+					if (ffObj.field.Type == FormFieldText || ffObj.field.Type == FormFieldChoice) &&
+						apIdx >= 0 && apIdx < len(gp.pdfObjs) {
+						gp.pdfObjs[apIdx] = gp.textFieldAppearance(ffObj)
+					}
 				}
 			}
 			return nil
@@ -122,12 +133,22 @@ func (gp *GoPdf) BakeAnnotations() {
 
 			switch a := gp.pdfObjs[objIdx].(type) {
 			case formFieldObj:
-				// Bake form field as static text.
-				bakedContent.WriteString(bakeFormField(a))
+				apObjID := a.ap.normal
+				if a.field.Type == FormFieldCheckbox && !a.field.Checked {
+					apObjID = a.ap.offNormal
+				}
+				bakedContent.WriteString(gp.bakeFormAppearance(apObjID, a.field.X, a.field.Y))
+				gp.pdfObjs[objIdx] = nullObj{}
+			case formRadioWidgetObj:
+				apObjID := a.ap.offNormal
+				if a.selected() {
+					apObjID = a.ap.normal
+				}
+				bakedContent.WriteString(gp.bakeFormAppearance(apObjID, a.button.X, a.button.Y))
 				gp.pdfObjs[objIdx] = nullObj{}
 			case annotationObj:
-				// Bake annotation as static drawing.
-				bakedContent.WriteString(bakeAnnotation(a))
+				// Bake annotation as its appearance stream.
+				bakedContent.WriteString(gp.bakeAnnotation(a))
 				gp.pdfObjs[objIdx] = nullObj{}
 			}
 		}
@@ -141,102 +162,48 @@ func (gp *GoPdf) BakeAnnotations() {
 		page.LinkObjIds = nil
 	}
 
-	// Clear form fields.
+	// Clear form fields. Radio group parents are not page annotations.
+	for _, ref := range gp.formFields {
+		if ref.field.Type == FormFieldRadio && ref.objIdx >= 0 && ref.objIdx < len(gp.pdfObjs) {
+			gp.pdfObjs[ref.objIdx] = nullObj{}
+		}
+	}
 	gp.formFields = nil
 }
 
-// bakeFormField converts a form field to static PDF drawing commands.
-func bakeFormField(f formFieldObj) string {
-	field := f.field
-	var buf strings.Builder
-
-	buf.WriteString("q\n")
-
-	// Draw background if filled.
-	if field.HasFill {
-		fmt.Fprintf(&buf, "%.4f %.4f %.4f rg\n",
-			float64(field.FillColor[0])/255,
-			float64(field.FillColor[1])/255,
-			float64(field.FillColor[2])/255)
-		fmt.Fprintf(&buf, "%.2f %.2f %.2f %.2f re f\n",
-			field.X, field.Y, field.W, field.H)
-	}
-
-	// Draw border if present.
-	if field.HasBorder {
-		fmt.Fprintf(&buf, "%.4f %.4f %.4f RG\n",
-			float64(field.BorderColor[0])/255,
-			float64(field.BorderColor[1])/255,
-			float64(field.BorderColor[2])/255)
-		fmt.Fprintf(&buf, "1 w\n")
-		fmt.Fprintf(&buf, "%.2f %.2f %.2f %.2f re S\n",
-			field.X, field.Y, field.W, field.H)
-	}
-
-	// Draw text value.
-	if field.Value != "" && (field.Type == FormFieldText || field.Type == FormFieldChoice) {
-		fontSize := field.FontSize
-		if fontSize == 0 {
-			fontSize = 12
-		}
-		fmt.Fprintf(&buf, "BT\n")
-		fmt.Fprintf(&buf, "%.4f %.4f %.4f rg\n",
-			float64(field.Color[0])/255,
-			float64(field.Color[1])/255,
-			float64(field.Color[2])/255)
-		fmt.Fprintf(&buf, "/Helv %.1f Tf\n", fontSize)
-		fmt.Fprintf(&buf, "%.2f %.2f Td\n", field.X+2, field.Y+field.H-fontSize-2)
-		fmt.Fprintf(&buf, "(%s) Tj\n", escapeAnnotString(field.Value))
-		fmt.Fprintf(&buf, "ET\n")
+// bakeAnnotation draws the appearance stream of an annotation. The
+// appearance keeps a copy of the option because the annotation object is
+// removed.
+func (gp *GoPdf) bakeAnnotation(a annotationObj) string {
+	apIdx := a.apObjID - 1
+	if apIdx < 0 || apIdx >= len(gp.pdfObjs) {
+		return ""
 	}
-
-	// Draw checkmark for checked checkboxes.
-	if field.Type == FormFieldCheckbox && field.Checked {
-		cx := field.X + field.W*0.2
-		cy := field.Y + field.H*0.2
-		fmt.Fprintf(&buf, "0 0 0 RG\n2 w\n")
-		fmt.Fprintf(&buf, "%.2f %.2f m %.2f %.2f l S\n",
-			cx, cy+field.H*0.3, cx+field.W*0.3, cy)
-		fmt.Fprintf(&buf, "%.2f %.2f m %.2f %.2f l S\n",
-			cx+field.W*0.3, cy, cx+field.W*0.6, cy+field.H*0.6)
+	apObj, ok := gp.pdfObjs[apIdx].(*annotationAppearanceObj)
+	if !ok || gp.indexOfProcSet == -1 {
+		return ""
 	}
-
-	buf.WriteString("Q\n")
-	return buf.String()
-}
-
-// bakeAnnotation converts an annotation to static PDF drawing commands.
-func bakeAnnotation(a annotationObj) string {
 	opt := a.opt
-	var buf strings.Builder
-
-	buf.WriteString("q\n")
-
-	// Draw annotation rectangle with color.
-	r := float64(opt.Color[0]) / 255
-	g := float64(opt.Color[1]) / 255
-	b := float64(opt.Color[2]) / 255
-	fmt.Fprintf(&buf, "%.4f %.4f %.4f RG\n", r, g, b)
-	fmt.Fprintf(&buf, "1 w\n")
-	fmt.Fprintf(&buf, "%.2f %.2f %.2f %.2f re S\n",
-		opt.X, opt.Y, opt.W, opt.H)
+	apObj.baked = &opt
+	procset := gp.pdfObjs[gp.indexOfProcSet].(*ProcSetObj)
+	procset.RelateXobjs = append(procset.RelateXobjs, RelateXobject{IndexOfObj: apIdx})
+
+	opt.defaults()
+	x, y, _, h := opt.rect()
+	pageH := gp.config.PageSize.H
+	return fmt.Sprintf("q\n1 0 0 1 %.2f %.2f cm\n/I%d Do\nQ\n", x, pageH-(y+h), apIdx+1)
+}
 
-	// Draw content text if present.
-	if opt.Content != "" {
-		fmt.Fprintf(&buf, "BT\n")
-		fontSize := opt.FontSize
-		if fontSize == 0 {
-			fontSize = 10
-		}
-		fmt.Fprintf(&buf, "%.4f %.4f %.4f rg\n", r, g, b)
-		fmt.Fprintf(&buf, "/Helv %.1f Tf\n", fontSize)
-		fmt.Fprintf(&buf, "%.2f %.2f Td\n", opt.X+2, opt.Y+opt.H-fontSize-2)
-		fmt.Fprintf(&buf, "(%s) Tj\n", escapeAnnotString(opt.Content))
-		fmt.Fprintf(&buf, "ET\n")
+// bakeFormAppearance draws the appearance stream apObjID of a form field
+// widget at x, y.
+func (gp *GoPdf) bakeFormAppearance(apObjID int, x, y float64) string {
+	apIdx := apObjID - 1
+	if apIdx < 0 || apIdx >= len(gp.pdfObjs) || gp.indexOfProcSet == -1 {
+		return ""
 	}
-
-	buf.WriteString("Q\n")
-	return buf.String()
+	procset := gp.pdfObjs[gp.indexOfProcSet].(*ProcSetObj)
+	procset.RelateXobjs = append(procset.RelateXobjs, RelateXobject{IndexOfObj: apIdx})
+	return fmt.Sprintf("q\n1 0 0 1 %.2f %.2f cm\n/I%d Do\nQ\n", x, y, apIdx+1)
 }
 
 // appendContentToPage appends raw content stream data to a page.
@@ -0,0 +1,355 @@
+package gopdf
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+)
+
+// ============================================================
+// Format, keystroke, validate and calculate scripts of form fields
+// ============================================================
+
+// FieldFormatType is a standard Acrobat format of a text field value.
+type FieldFormatType int
+
+const (
+	// FieldFormatNumber formats numbers (AFNumber_Format).
+	FieldFormatNumber FieldFormatType = iota + 1
+	// FieldFormatPercent formats numbers as percentages (AFPercent_Format).
+	FieldFormatPercent
+	// FieldFormatDate formats dates (AFDate_FormatEx).
+	FieldFormatDate
+)
+
+// NumberSeparator is the digit grouping and decimal separator style of a
+// number format.
+type NumberSeparator int
+
+const (
+	// SeparatorCommaDot formats 1234.5 as 1,234.50.
+	SeparatorCommaDot NumberSeparator = iota
+	// SeparatorDot formats 1234.5 as 1234.50.
+	SeparatorDot
+	// SeparatorDotComma formats 1234.5 as 1.234,50.
+	SeparatorDotComma
+	// SeparatorComma formats 1234.5 as 1234,50.
+	SeparatorComma
+)
+
+// NegativeStyle is how a number format shows negative numbers.
+type NegativeStyle int
+
+const (
+	// NegativeMinus shows a minus sign.
+	NegativeMinus NegativeStyle = iota
+	// NegativeRed shows the number in red.
+	NegativeRed
+	// NegativeParens shows the number in parentheses.
+	NegativeParens
+	// NegativeParensRed shows the number in parentheses and in red.
+	NegativeParensRed
+)
+
+// FieldFormat is the format of a text field. Viewers format the value when
+// the field loses focus and only accept matching keystrokes.
+type FieldFormat struct {
+	// Type is the format type.
+	Type FieldFormatType
+	// Decimals is the number of decimal places of a number or percentage.
+	Decimals int
+	// Separator is the separator style of a number or percentage.
+	Separator NumberSeparator
+	// Negative is the negative number style of a number.
+	Negative NegativeStyle
+	// Currency is the currency symbol of a number, e.g. "$".
+	Currency string
+	// CurrencyPrepend puts the currency symbol before the number.
+	CurrencyPrepend bool
+	// DateFormat is the date pattern of a date, e.g. "yyyy-mm-dd"
+	// (default "mm/dd/yyyy").
+	DateFormat string
+}
+
+// FieldRange validates that a numeric field value lies in a range.
+type FieldRange struct {
+	// Min is the smallest accepted value when HasMin is set.
+	Min    float64
+	HasMin bool
+	// Max is the largest accepted value when HasMax is set.
+	Max    float64
+	HasMax bool
+}
+
+// CalculationOp is the operation of a calculated field.
+type CalculationOp int
+
+const (
+	// CalcSum adds the values.
+	CalcSum CalculationOp = iota + 1
+	// CalcProduct multiplies the values.
+	CalcProduct
+	// CalcAverage averages the values.
+	CalcAverage
+	// CalcMin takes the smallest value.
+	CalcMin
+	// CalcMax takes the largest value.
+	CalcMax
+)
+
+// FieldCalculation computes the value of a field from other fields
+// (AFSimple_Calculate). Viewers recalculate fields in the order set by
+// SetCalculationOrder.
+type FieldCalculation struct {
+	// Op is the operation.
+	Op CalculationOp
+	// Fields are the names of the fields the value is computed from.
+	Fields []string
+}
+
+// scripts returns the keystroke and format scripts of the format.
+func (ff *FieldFormat) scripts() (keystroke, format string) {
+	switch ff.Type {
+	case FieldFormatNumber:
+		args := fmt.Sprintf("%d, %d, %d, 0, %s, %t",
+			ff.Decimals, ff.Separator, ff.Negative, jsString(ff.Currency), ff.CurrencyPrepend)
+		return "AFNumber_Keystroke(" + args + ");", "AFNumber_Format(" + args + ");"
+	case FieldFormatPercent:
+		args := fmt.Sprintf("%d, %d", ff.Decimals, ff.Separator)
+		return "AFPercent_Keystroke(" + args + ");", "AFPercent_Format(" + args + ");"
+	case FieldFormatDate:
+		pattern := jsString(ff.dateFormat())
+		return "AFDate_KeystrokeEx(" + pattern + ");", "AFDate_FormatEx(" + pattern + ");"
+	}
+	return "", ""
+}
+
+func (ff *FieldFormat) dateFormat() string {
+	if ff.DateFormat == "" {
+		return "mm/dd/yyyy"
+	}
+	return ff.DateFormat
+}
+
+// script returns the validate script of the range.
+func (r *FieldRange) script() string {
+	return fmt.Sprintf("AFRange_Validate(%t, %s, %t, %s);",
+		r.HasMin, strconv.FormatFloat(r.Min, 'f', -1, 64),
+		r.HasMax, strconv.FormatFloat(r.Max, 'f', -1, 64))
+}
+
+// script returns the calculate script of the calculation.
+func (c *FieldCalculation) script() string {
+	ops := map[CalculationOp]string{
+		CalcSum: "SUM", CalcProduct: "PRD", CalcAverage: "AVG", CalcMin: "MIN", CalcMax: "MAX",
+	}
+	op, ok := ops[c.Op]
+	if !ok {
+		return ""
+	}
+	names := make([]string, len(c.Fields))
+	for i, name := range c.Fields {
+		names[i] = jsString(name)
+	}
+	return fmt.Sprintf("AFSimple_Calculate(%s, new Array (%s));", jsString(op), strings.Join(names, ", "))
+}
+
+// writeAdditionalActions writes the /A action of a field and the /AA
+// dictionary with its mouse and focus actions and the keystroke, format,
+// validate and calculate scripts.
+func (field FormField) writeAdditionalActions(w io.Writer, aw actionWriter) {
+	var entries []string
+	add := func(key, js string) {
+		if js != "" {
+			entries = append(entries, fmt.Sprintf("%s << /S /JavaScript /JS (%s) >>", key, escapeAnnotString(js)))
+		}
+	}
+	if a := field.Actions; a != nil {
+		if a.Activate != nil {
+			if d := aw.dict(*a.Activate); d != "" {
+				fmt.Fprintf(w, "/A %s\n", d)
+			}
+		}
+		for _, t := range []struct {
+			key    string
+			action *Action
+		}{
+			{"/E", a.Enter}, {"/X", a.Exit}, {"/D", a.Down}, {"/U", a.Up},
+			{"/Fo", a.Focus}, {"/Bl", a.Blur},
+		} {
+			if t.action != nil {
+				if d := aw.dict(*t.action); d != "" {
+					entries = append(entries, t.key+" "+d)
+				}
+			}
+		}
+	}
+	if field.Format != nil {
+		keystroke, format := field.Format.scripts()
+		add("/K", keystroke)
+		add("/F", format)
+	}
+	if field.Validate != nil {
+		add("/V", field.Validate.script())
+	}
+	if field.Calculate != nil {
+		add("/C", field.Calculate.script())
+	}
+	if len(entries) > 0 {
+		fmt.Fprintf(w, "/AA << %s >>\n", strings.Join(entries, " "))
+	}
+}
+
+// jsString returns s as a double-quoted JavaScript string literal with
+// non-ASCII characters escaped.
+func jsString(s string) string {
+	var sb strings.Builder
+	sb.WriteByte('"')
+	for _, r := range s {
+		switch {
+		case r == '"' || r == '\\':
+			sb.WriteByte('\\')
+			sb.WriteRune(r)
+		case r == '\n':
+			sb.WriteString(`\n`)
+		case r == '\r':
+			sb.WriteString(`\r`)
+		case r < 0x20 || r > 0x7E:
+			for _, u := range utf16.Encode([]rune{r}) {
+				fmt.Fprintf(&sb, `\u%04X`, u)
+			}
+		default:
+			sb.WriteRune(r)
+		}
+	}
+	sb.WriteByte('"')
+	return sb.String()
+}
+
+// displayValue returns the value of a field as shown by its number or
+// percentage format, and whether it is shown in red. Other values are
+// shown unchanged.
+func (field FormField) displayValue() (string, bool) {
+	ff := field.Format
+	if ff == nil || field.Value == "" {
+		return field.Value, false
+	}
+	v, err := strconv.ParseFloat(strings.TrimSpace(field.Value), 64)
+	if err != nil {
+		return field.Value, false
+	}
+	switch ff.Type {
+	case FieldFormatNumber:
+		s := formatFieldNumber(math.Abs(v), ff.Decimals, ff.Separator)
+		if ff.Currency != "" {
+			if ff.CurrencyPrepend {
+				s = ff.Currency + s
+			} else {
+				s += ff.Currency
+			}
+		}
+		if v >= 0 {
+			return s, false
+		}
+		switch ff.Negative {
+		case NegativeParens, NegativeParensRed:
+			s = "(" + s + ")"
+		default:
+			s = "-" + s
+		}
+		return s, ff.Negative == NegativeRed || ff.Negative == NegativeParensRed
+	case FieldFormatPercent:
+		s := formatFieldNumber(math.Abs(v*100), ff.Decimals, ff.Separator) + "%"
+		if v < 0 {
+			s = "-" + s
+		}
+		return s, false
+	}
+	return field.Value, false
+}
+
+// formatFieldNumber formats a non-negative number with decimals places,
+// rounding halves up like viewers do, and the separators of sep.
+func formatFieldNumber(v float64, decimals int, sep NumberSeparator) string {
+	decimals = max(decimals, 0)
+	scale := math.Pow(10, float64(decimals))
+	s := strconv.FormatFloat(math.Round(v*scale)/scale, 'f', decimals, 64)
+	intPart, frac, _ := strings.Cut(s, ".")
+	group, point := ",", "."
+	switch sep {
+	case SeparatorDot:
+		group = ""
+	case SeparatorDotComma:
+		group, point = ".", ","
+	case SeparatorComma:
+		group, point = "", ","
+	}
+	if group != "" && len(intPart) > 3 {
+		var sb strings.Builder
+		for i, c := range intPart {
+			if i > 0 && (len(intPart)-i)%3 == 0 {
+				sb.WriteString(group)
+			}
+			sb.WriteRune(c)
+		}
+		intPart = sb.String()
+	}
+	if frac == "" {
+		return intPart
+	}
+	return intPart + point + frac
+}
+
+// SetCalculationOrder sets the order in which viewers recalculate the
+// named fields (/CO). Calculated fields that are not named follow in the
+// order they were added.
+//
+// Example:
+//
+//	pdf.SetCalculationOrder("subtotal", "tax", "total")
+func (gp *GoPdf) SetCalculationOrder(names ...string) error {
+	for _, name := range names {
+		found := false
+		for _, ref := range gp.formFields {
+			if ref.field.Name != name {
+				continue
+			}
+			if ref.field.Calculate == nil {
+				return fmt.Errorf("form field %q has no calculation", name)
+			}
+			found = true
+		}
+		if !found {
+			return fmt.Errorf("form field %q not found", name)
+		}
+	}
+	gp.formCalcOrder = names
+	return nil
+}
+
+// calculationOrder returns the object IDs of the calculated fields in
+// calculation order.
+func (gp *GoPdf) calculationOrder() []int {
+	seen := make(map[int]bool)
+	var ids []int
+	add := func(ref formFieldRef) {
+		if ref.field.Calculate != nil && !seen[ref.objIdx] {
+			seen[ref.objIdx] = true
+			ids = append(ids, ref.objIdx+1)
+		}
+	}
+	for _, name := range gp.formCalcOrder {
+		for _, ref := range gp.formFields {
+			if ref.field.Name == name {
+				add(ref)
+			}
+		}
+	}
+	for _, ref := range gp.formFields {
+		add(ref)
+	}
+	return ids
+}
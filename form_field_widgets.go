@@ -0,0 +1,354 @@
+package gopdf
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// ============================================================
+// Radio groups, list boxes and push buttons
+// ============================================================
+
+// RadioButton is one button of a radio group.
+type RadioButton struct {
+	// X, Y are the top-left corner position.
+	X, Y float64
+	// W, H are the width and height.
+	W, H float64
+	// Value is the export value of the button.
+	Value string
+}
+
+// SubmitFormat is the data format of a submit-form action.
+type SubmitFormat int
+
+const (
+	// SubmitFDF submits the fields as FDF.
+	SubmitFDF SubmitFormat = iota
+	// SubmitHTML submits the fields as an HTML form (application/x-www-form-urlencoded).
+	SubmitHTML
+	// SubmitXFDF submits the fields as XFDF.
+	SubmitXFDF
+	// SubmitPDF submits the whole document.
+	SubmitPDF
+)
+
+// flags returns the /Flags bits of a submit-form action.
+func (f SubmitFormat) flags() int {
+	switch f {
+	case SubmitHTML:
+		return 1 << 2 // ExportFormat
+	case SubmitXFDF:
+		return 1 << 5 // XFDF
+	case SubmitPDF:
+		return 1 << 8 // SubmitPDF
+	}
+	return 0
+}
+
+// pageObjIDByNumber returns the object ID of the n-th page (1-based), or 0.
+func (gp *GoPdf) pageObjIDByNumber(pageNo int) int {
+	pages := gp.pageObjIndexes()
+	if pageNo < 1 || pageNo > len(pages) {
+		return 0
+	}
+	return pages[pageNo-1] + 1
+}
+
+// writeChoiceSelection writes the selected values (/V) of a multi-select
+// choice field and the selected option indexes (/I) of a list box.
+func (f formFieldObj) writeChoiceSelection(w io.Writer) {
+	field := f.field
+	selected := field.Values
+	switch {
+	case len(selected) == 1:
+		fmt.Fprintf(w, "/V (%s)\n", escapeAnnotString(selected[0]))
+	case len(selected) > 1:
+		io.WriteString(w, "/V [")
+		for _, v := range selected {
+			fmt.Fprintf(w, "(%s) ", escapeAnnotString(v))
+		}
+		io.WriteString(w, "]\n")
+	case field.Value != "":
+		selected = []string{field.Value}
+	}
+	if !field.ListBox || len(selected) == 0 {
+		return
+	}
+	var indexes []string
+	for i, opt := range field.Options {
+		for _, v := range selected {
+			if opt == v {
+				indexes = append(indexes, fmt.Sprint(i))
+				break
+			}
+		}
+	}
+	if len(indexes) > 0 {
+		fmt.Fprintf(w, "/I [%s]\n", strings.Join(indexes, " "))
+	}
+}
+
+// writeRadioGroup writes the parent field of a radio group. The buttons
+// are separate widget annotations listed in /Kids.
+func (f formFieldObj) writeRadioGroup(w io.Writer) error {
+	field := f.field
+	ff := 1<<15 | 1<<14 // bit 16 = radio, bit 15 = NoToggleToOff
+	if field.ReadOnly {
+		ff |= 1
+	}
+	if field.Required {
+		ff |= 2
+	}
+	io.WriteString(w, "<<\n")
+	io.WriteString(w, "/FT /Btn\n")
+	fmt.Fprintf(w, "/T (%s)\n", escapeAnnotString(field.Name))
+	fmt.Fprintf(w, "/Ff %d\n", ff)
+	if field.Value != "" {
+		fmt.Fprintf(w, "/V %s\n", encodePDFName(field.Value))
+		fmt.Fprintf(w, "/DV %s\n", encodePDFName(field.Value))
+	}
+	io.WriteString(w, "/Kids [")
+	for _, id := range f.kids {
+		fmt.Fprintf(w, "%d 0 R ", id)
+	}
+	io.WriteString(w, "]\n")
+	io.WriteString(w, ">>\n")
+	return nil
+}
+
+// radioButtons returns the buttons of a radio field, or a single button
+// covering the field rectangle.
+func (field FormField) radioButtons() []RadioButton {
+	if len(field.Buttons) > 0 {
+		return field.Buttons
+	}
+	value := field.Value
+	if value == "" {
+		value = "Choice1"
+	}
+	return []RadioButton{{X: field.X, Y: field.Y, W: field.W, H: field.H, Value: value}}
+}
+
+// addRadioGroup adds the parent field of a radio group followed by a
+// widget and its appearance streams for each button.
+func (gp *GoPdf) addRadioGroup(group formFieldObj) error {
+	buttons := group.field.radioButtons()
+	for _, b := range buttons {
+		if b.Value == "" || b.W <= 0 || b.H <= 0 {
+			return fmt.Errorf("radio button of %q needs a value and a positive size", group.field.Name)
+		}
+	}
+	parentIdx := len(gp.pdfObjs)
+	widgets := make([]formRadioWidgetObj, len(buttons))
+	streams := make([][]IObj, len(buttons))
+	nextID := parentIdx + 2
+	for i, b := range buttons {
+		widgets[i] = formRadioWidgetObj{
+			parentIdx: parentIdx,
+			pageRef:   group.pageRef,
+			button:    b,
+			field:     group.field,
+			getRoot:   group.getRoot,
+		}
+		widgets[i].ap, streams[i] = gp.toggleAppearances(group.field, b.W, b.H, b.Value).layout(nextID + 1)
+		group.kids = append(group.kids, nextID)
+		nextID += 1 + len(streams[i])
+	}
+	gp.addObj(group)
+	ref := formFieldRef{field: group.field, objIdx: parentIdx}
+	page := gp.findCurrentPageObj()
+	for i, widget := range widgets {
+		widgetIdx := gp.addObj(widget)
+		ref.extraIdx = append(ref.extraIdx, widgetIdx)
+		for _, stream := range streams[i] {
+			ref.extraIdx = append(ref.extraIdx, gp.addObj(stream))
+		}
+		if page != nil {
+			page.LinkObjIds = append(page.LinkObjIds, widgetIdx+1)
+		}
+	}
+	gp.formFields = append(gp.formFields, ref)
+	return nil
+}
+
+// formRadioWidgetObj is the widget annotation of one radio button. Its
+// state is read from the parent field when written, so that
+// ModifyFormFieldValue selects another button.
+type formRadioWidgetObj struct {
+	parentIdx int
+	pageRef   int
+	button    RadioButton
+	field     FormField
+	ap        widgetAppearance
+	getRoot   func() *GoPdf
+}
+
+func (r formRadioWidgetObj) init(fn func() *GoPdf) {}
+
+func (r formRadioWidgetObj) getType() string {
+	return "FormField"
+}
+
+// selected reports whether the parent field value selects this button.
+func (r formRadioWidgetObj) selected() bool {
+	gp := r.getRoot()
+	if r.parentIdx < 0 || r.parentIdx >= len(gp.pdfObjs) {
+		return false
+	}
+	parent, ok := gp.pdfObjs[r.parentIdx].(formFieldObj)
+	return ok && parent.field.Value == r.button.Value
+}
+
+func (r formRadioWidgetObj) write(w io.Writer, objID int) error {
+	b := r.button
+	state := "/Off"
+	if r.selected() {
+		state = encodePDFName(b.Value)
+	}
+	io.WriteString(w, "<<\n")
+	io.WriteString(w, "/Type /Annot\n")
+	io.WriteString(w, "/Subtype /Widget\n")
+	fmt.Fprintf(w, "/Parent %d 0 R\n", r.parentIdx+1)
+	fmt.Fprintf(w, "/Rect [%.2f %.2f %.2f %.2f]\n", b.X, b.Y, b.X+b.W, b.Y+b.H)
+	fmt.Fprintf(w, "/P %d 0 R\n", r.pageRef)
+	fmt.Fprintf(w, "/AS %s\n", state)
+	io.WriteString(w, "/MK <<\n")
+	if r.field.HasBorder {
+		fmt.Fprintf(w, "  /BC [%s]\n", formColor(r.field.BorderColor))
+	}
+	if r.field.HasFill {
+		fmt.Fprintf(w, "  /BG [%s]\n", formColor(r.field.FillColor))
+	}
+	glyph, _ := r.field.CheckStyle.glyph(true)
+	fmt.Fprintf(w, "  /CA (%s)\n", glyph)
+	io.WriteString(w, ">>\n")
+	if r.field.HasBorder {
+		io.WriteString(w, "/BS << /W 1 /S /S >>\n")
+	}
+	r.ap.write(w)
+	fmt.Fprintf(w, "/DA (/ZaDb 0 Tf %s rg)\n", formColor(r.field.Color))
+	r.field.writeAdditionalActions(w, actionWriter{r.getRoot(), objID})
+	io.WriteString(w, ">>\n")
+	return nil
+}
+
+// formColor formats an RGB color for a /MK entry.
+func formColor(c [3]uint8) string {
+	return fmt.Sprintf("%.4f %.4f %.4f", float64(c[0])/255, float64(c[1])/255, float64(c[2])/255)
+}
+
+// formAppearanceObj is an appearance stream (Form XObject) of a form field
+// widget.
+type formAppearanceObj struct {
+	w, h      float64
+	content   string
+	resources string // resource dictionary entries, e.g. "/Font << ... >>"
+	getRoot   func() *GoPdf
+}
+
+func (a *formAppearanceObj) init(f func() *GoPdf) {
+	a.getRoot = f
+}
+
+func (a *formAppearanceObj) getType() string {
+	return "XObject"
+}
+
+func (a *formAppearanceObj) write(w io.Writer, objID int) error {
+	stream := []byte(a.content)
+	if gp := a.getRoot(); gp.protection() != nil {
+		tmp, err := rc4Cip(gp.protection().objectkey(objID), stream)
+		if err != nil {
+			return err
+		}
+		stream = tmp
+	}
+	io.WriteString(w, "<<\n")
+	io.WriteString(w, "/Type /XObject\n")
+	io.WriteString(w, "/Subtype /Form\n")
+	io.WriteString(w, "/FormType 1\n")
+	fmt.Fprintf(w, "/BBox [0 0 %.2f %.2f]\n", a.w, a.h)
+	io.WriteString(w, "/Matrix [1 0 0 1 0 0]\n")
+	fmt.Fprintf(w, "/Resources << %s >>\n", a.resources)
+	fmt.Fprintf(w, "/Length %d\n", len(stream))
+	io.WriteString(w, ">>\n")
+	io.WriteString(w, "stream\n")
+	w.Write(stream)
+	io.WriteString(w, "\nendstream\n")
+	return nil
+}
+
+// AddRadioGroup adds a radio button group. value selects the initially
+// checked button and may be empty.
+//
+// Example:
+//
+//	pdf.AddRadioGroup("size", []gopdf.RadioButton{
+//	    {X: 50, Y: 100, W: 12, H: 12, Value: "S"},
+//	    {X: 80, Y: 100, W: 12, H: 12, Value: "M"},
+//	    {X: 110, Y: 100, W: 12, H: 12, Value: "L"},
+//	}, "M")
+func (gp *GoPdf) AddRadioGroup(name string, buttons []RadioButton, value string) error {
+	if len(buttons) == 0 {
+		return fmt.Errorf("radio group %q needs at least one button", name)
+	}
+	return gp.AddFormField(FormField{
+		Type:        FormFieldRadio,
+		Name:        name,
+		X:           buttons[0].X,
+		Y:           buttons[0].Y,
+		W:           buttons[0].W,
+		H:           buttons[0].H,
+		Value:       value,
+		Buttons:     buttons,
+		HasBorder:   true,
+		BorderColor: [3]uint8{0, 0, 0},
+	})
+}
+
+// AddListBox is a convenience method for adding a list box choice field.
+func (gp *GoPdf) AddListBox(name string, x, y, w, h float64, options []string, multiSelect bool) error {
+	return gp.AddFormField(FormField{
+		Type:        FormFieldChoice,
+		Name:        name,
+		X:           x,
+		Y:           y,
+		W:           w,
+		H:           h,
+		Options:     options,
+		ListBox:     true,
+		MultiSelect: multiSelect,
+		FontSize:    12,
+		HasBorder:   true,
+		BorderColor: [3]uint8{0, 0, 0},
+	})
+}
+
+// AddPushButton is a convenience method for adding a push button with a
+// caption and an action.
+//
+// Example:
+//
+//	pdf.AddPushButton("submit", "Submit", 50, 700, 80, 24, gopdf.Action{
+//	    Type:   gopdf.ActionSubmitForm,
+//	    URI:    "https://example.com/forms",
+//	    Format: gopdf.SubmitXFDF,
+//	})
+func (gp *GoPdf) AddPushButton(name, caption string, x, y, w, h float64, action Action) error {
+	return gp.AddFormField(FormField{
+		Type:        FormFieldButton,
+		Name:        name,
+		X:           x,
+		Y:           y,
+		W:           w,
+		H:           h,
+		Caption:     caption,
+		Actions:     &FieldActions{Activate: &action},
+		HasBorder:   true,
+		BorderColor: [3]uint8{0, 0, 0},
+		HasFill:     true,
+		FillColor:   [3]uint8{0xD4, 0xD0, 0xC8},
+	})
+}
@@ -5,4 +5,5 @@ go 1.24.1
 require (
 	github.com/ledongthuc/pdf v0.0.0-20250511090121-5959a4027728
 	go.mozilla.org/pkcs7 v0.9.0
+	golang.org/x/image v0.36.0
 )
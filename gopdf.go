@@ -96,6 +96,12 @@ type GoPdf struct {
 	headerFunc func()
 	footerFunc func()
 
+	//header and footer text with page tokens, resolved on write
+	inHeaderFooter     bool
+	pageTokensEnabled  bool
+	pageTokens         []*pageTokenText
+	headerFooterFields map[string]string
+
 	// PDF page importer (replaces gofpdi)
 	fpdi *fpdiImporter
 
@@ -105,6 +111,34 @@ type GoPdf struct {
 	//embedded files
 	embeddedFiles []embeddedFileRef
 
+	//named destinations (in points)
+	namedDests map[string]Destination
+
+	//document-level JavaScript
+	javaScripts []documentScript
+
+	//article threads
+	articleThreads []ArticleThread
+
+	//base direction of bidirectional text
+	textDirection TextDirection
+
+	//vertical writing and its longest run of horizontal digits
+	writingMode WritingMode
+	tateChuYoko int
+
+	//fallback families of font families
+	fontFallbacks map[string][]string
+
+	//metrics of standard 14 fonts loaded from AFM files
+	standardFonts map[string]*standardFont
+
+	//page order when pages were inserted (nil = order of creation)
+	pageOrder []*PageObj
+
+	//pages reserved for a printed table of contents
+	tocPages *tocReservation
+
 	//pdf version
 	pdfVersion PDFVersion
 
@@ -125,6 +159,8 @@ type GoPdf struct {
 
 	//form fields (AcroForm)
 	formFields []formFieldRef
+	// names of calculated fields in calculation order
+	formCalcOrder []string
 
 	//mark info
 	markInfo *MarkInfo
@@ -135,8 +171,9 @@ type GoPdf struct {
 
 // formFieldRef stores a form field and its object index.
 type formFieldRef struct {
-	field   FormField
-	objIdx  int // index in pdfObjs
+	field    FormField
+	objIdx   int   // index in pdfObjs
+	extraIdx []int // indexes of widgets and appearance streams of the field
 }
 
 type DrawableRectOptions struct {
@@ -753,6 +790,17 @@ func (gp *GoPdf) imageByHolder(img ImageHolder, opts ImageOptions) error {
 			imgobj.imginfo.deviceRGBObjID = gp.addObj(dRGB)
 		}
 
+		if imgobj.haveICCProfile() {
+			iccObj, err := imgobj.createICCProfile()
+			if err != nil {
+				return err
+			}
+			iccObj.init(func() *GoPdf {
+				return gp
+			})
+			imgobj.imginfo.iccObjID = gp.addObj(iccObj)
+		}
+
 	} else { //same img
 		if opts.Rect == nil {
 			opts.Rect = gp.curr.ImgCaches[cacheImageIndex].Rect
@@ -871,6 +919,7 @@ func (gp *GoPdf) AddPageWithOption(opt PageOption) {
 	gp.indexOfContent = -1
 	gp.resetCurrXY()
 
+	gp.inHeaderFooter = true
 	if gp.headerFunc != nil {
 		gp.headerFunc()
 		gp.resetCurrXY()
@@ -880,6 +929,7 @@ func (gp *GoPdf) AddPageWithOption(opt PageOption) {
 		gp.footerFunc()
 		gp.resetCurrXY()
 	}
+	gp.inHeaderFooter = false
 }
 
 func (gp *GoPdf) AddOutline(title string) {
@@ -892,11 +942,15 @@ func (gp *GoPdf) AddOutlineWithPosition(title string) *OutlineObj {
 }
 
 // AddHeader - add a header function, if present this will be automatically called by AddPage()
+// Text and cells drawn by f may contain page tokens such as {page} and {pages}
+// once EnablePageTokens is called.
 func (gp *GoPdf) AddHeader(f func()) {
 	gp.headerFunc = f
 }
 
 // AddFooter - add a footer function, if present this will be automatically called by AddPage()
+// Text and cells drawn by f may contain page tokens such as {page} and {pages}
+// once EnablePageTokens is called.
 func (gp *GoPdf) AddFooter(f func()) {
 	gp.footerFunc = f
 }
@@ -981,9 +1035,12 @@ func convertNumericToFloat64(size interface{}) (fontSize float64, err error) {
 	}
 }
 
-// SetFontWithStyle : set font style support Regular or Underline
+// SetFontWithStyle : set font style support Regular, Underline,
+// Strikethrough or Overline
 // for Bold|Italic should be loaded appropriate fonts with same styles defined
 // size MUST be uint*, int* or float64*
+// The standard 14 fonts (Helvetica, Times, Courier, Symbol and ZapfDingbats)
+// are available without loading a TTF, see LoadStandardFontAFM.
 func (gp *GoPdf) SetFontWithStyle(family string, style int, size interface{}) error {
 	fontSize, err := convertNumericToFloat64(size)
 	if err != nil {
@@ -997,7 +1054,7 @@ func (gp *GoPdf) SetFontWithStyle(family string, style int, size interface{}) er
 			obj := gp.pdfObjs[i]
 			sub, ok := obj.(*SubsetFontObj)
 			if ok {
-				if sub.GetFamily() == family && sub.GetTtfFontOption().Style == style&^Underline {
+				if sub.GetFamily() == family && sub.GetTtfFontOption().Style == style&^decorations {
 					gp.curr.FontSize = fontSize
 					gp.curr.FontStyle = style
 					gp.curr.FontFontCount = sub.CountOfFont
@@ -1011,13 +1068,21 @@ func (gp *GoPdf) SetFontWithStyle(family string, style int, size interface{}) er
 	}
 
 	if !found {
-		return ErrMissingFontFamily
+		sub, ok := gp.addStandardFont(family, style)
+		if !ok {
+			return ErrMissingFontFamily
+		}
+		gp.curr.FontSize = fontSize
+		gp.curr.FontStyle = style
+		gp.curr.FontFontCount = sub.CountOfFont
+		gp.curr.FontISubset = sub
 	}
 
 	return nil
 }
 
-// SetFont : set font style support "" or "U"
+// SetFont : set font style support "", "U" (underline), "S" (strikethrough)
+// or "O" (overline)
 // for "B" and "I" should be loaded appropriate fonts with same styles defined
 // size MUST be uint*, int* or float64*
 func (gp *GoPdf) SetFont(family string, style string, size interface{}) error {
@@ -1073,6 +1138,9 @@ func (gp *GoPdf) Close() error {
 }
 
 func (gp *GoPdf) compilePdf(w io.Writer) (n int64, err error) {
+	if err := gp.resolvePageTokens(); err != nil {
+		return 0, err
+	}
 	gp.prepare()
 	err = gp.Close()
 	if err != nil {
@@ -1138,16 +1206,29 @@ func (gp *GoPdf) GetBytesPdf() []byte {
 
 // Text write text start at current x,y ( current y is the baseline of text )
 func (gp *GoPdf) Text(text string) error {
-
-	text, err := gp.curr.FontISubset.AddChars(text)
+	if gp.isVertical() {
+		return gp.verticalText(text)
+	}
+	template := text
+	text, tokens := gp.beginPageTokens(text)
+	text, _ = gp.bidiText(text, TextDirectionNone)
+	runs, err := gp.fontRuns(text)
 	if err != nil {
 		return err
 	}
-
-	err = gp.getContent().AppendStreamText(text)
+	if len(runs) > 1 {
+		err = gp.appendTextRuns(runs)
+	} else {
+		err = gp.withFont(runs[0].font, func() error {
+			return gp.getContent().AppendStreamText(runs[0].text)
+		})
+	}
 	if err != nil {
 		return err
 	}
+	if tokens {
+		gp.endPageTokens(template, TextDirectionNone)
+	}
 
 	return nil
 }
@@ -1164,13 +1245,21 @@ func (gp *GoPdf) CellWithOption(rectangle *Rect, text string, opt CellOption) er
 	}
 
 	rectangle = rectangle.UnitsToPoints(gp.config.Unit)
-	text, err = gp.curr.FontISubset.AddChars(text)
-	if err != nil {
-		return err
+	if gp.isVertical() {
+		return gp.verticalCell(rectangle, text, opt)
+	}
+	template := text
+	text, tokens := gp.beginPageTokens(text)
+	text, rtl := gp.bidiText(text, opt.Direction)
+	if rtl && !hasHorizontalAlign(opt.Align) {
+		opt.Align |= Right
 	}
-	if err := gp.getContent().AppendStreamSubsetFont(rectangle, text, opt); err != nil {
+	if err := gp.appendCell(rectangle, text, opt); err != nil {
 		return err
 	}
+	if tokens {
+		gp.endPageTokens(template, opt.Direction)
+	}
 
 	return nil
 }
@@ -1184,106 +1273,96 @@ func (gp *GoPdf) Cell(rectangle *Rect, text string) error {
 		Border: 0,
 		Float:  Right,
 	}
+	if gp.isVertical() {
+		return gp.verticalCell(rectangle, text, defaultopt)
+	}
 
-	text, err := gp.curr.FontISubset.AddChars(text)
-	if err != nil {
-		return err
+	template := text
+	text, tokens := gp.beginPageTokens(text)
+	text, rtl := gp.bidiText(text, TextDirectionNone)
+	if rtl {
+		defaultopt.Align = Right | Top
 	}
-	err = gp.getContent().AppendStreamSubsetFont(rectangle, text, defaultopt)
-	if err != nil {
+	if err := gp.appendCell(rectangle, text, defaultopt); err != nil {
 		return err
 	}
+	if tokens {
+		gp.endPageTokens(template, TextDirectionNone)
+	}
 
 	return nil
 }
 
 // MultiCell : create of text with line breaks ( use current x,y is upper-left corner of cell)
+// Lines break at the opportunities of the Unicode line breaking algorithm (see BreakModeUnicode).
 func (gp *GoPdf) MultiCell(rectangle *Rect, text string) error {
-	var line []rune
+	if gp.isVertical() {
+		return gp.verticalMultiCell(rectangle, text, CellOption{Align: Top})
+	}
 	x := gp.GetX()
 	var totalLineHeight float64
-	length := len([]rune(text))
+
+	// the lines take the direction of the whole text
+	if dir := gp.textDirection; dir != TextDirectionNone {
+		gp.textDirection = paragraphDirection(text, dir)
+		defer func() { gp.textDirection = dir }()
+	}
 
 	// get lineHeight
-	text, err := gp.curr.FontISubset.AddChars(text)
+	itext, err := gp.curr.FontISubset.AddChars(text)
 	if err != nil {
 		return err
 	}
-	_, lineHeight, _, err := createContent(gp.curr.FontISubset, text, gp.curr.FontSize, gp.curr.CharSpacing, nil)
+	_, lineHeight, _, err := createContent(gp.curr.FontISubset, itext, gp.curr.FontSize, gp.curr.CharSpacing, nil)
 	if err != nil {
 		return err
 	}
 	gp.PointsToUnitsVar(&lineHeight)
 
-	for i, v := range []rune(text) {
+	lines, err := gp.splitTextUnicode(text, rectangle.W, &DefaultBreakOption)
+	if err != nil {
+		return err
+	}
+	for _, line := range lines {
 		if totalLineHeight+lineHeight > rectangle.H {
 			break
 		}
-		lineWidth, _ := gp.MeasureTextWidth(string(line))
-		runeWidth, _ := gp.MeasureTextWidth(string(v))
-
-		if lineWidth+runeWidth > rectangle.W {
-			gp.Cell(&Rect{W: rectangle.W, H: lineHeight}, string(line))
-			gp.Br(lineHeight)
-			gp.SetX(x)
-			totalLineHeight = totalLineHeight + lineHeight
-			line = nil
-		}
-
-		line = append(line, v)
-
-		if i == length-1 {
-			gp.Cell(&Rect{W: rectangle.W, H: lineHeight}, string(line))
-			gp.Br(lineHeight)
-			gp.SetX(x)
-		}
+		gp.Cell(&Rect{W: rectangle.W, H: lineHeight}, line)
+		gp.Br(lineHeight)
+		gp.SetX(x)
+		totalLineHeight += lineHeight
 	}
 	return nil
 }
 
 // IsFitMultiCell : check whether the rectangle's area is big enough for the text
 func (gp *GoPdf) IsFitMultiCell(rectangle *Rect, text string) (bool, float64, error) {
-	var line []rune
 	var totalLineHeight float64
-	length := len([]rune(text))
 
 	// get lineHeight
-	text, err := gp.curr.FontISubset.AddChars(text)
+	itext, err := gp.curr.FontISubset.AddChars(text)
 	if err != nil {
 		return false, totalLineHeight, err
 	}
-	_, lineHeight, _, err := createContent(gp.curr.FontISubset, text, gp.curr.FontSize, gp.curr.CharSpacing, nil)
+	_, lineHeight, _, err := createContent(gp.curr.FontISubset, itext, gp.curr.FontSize, gp.curr.CharSpacing, nil)
 
 	if err != nil {
 		return false, totalLineHeight, err
 	}
 	gp.PointsToUnitsVar(&lineHeight)
 
-	for i, v := range []rune(text) {
+	lines, err := gp.splitTextUnicode(text, rectangle.W, &DefaultBreakOption)
+	if err != nil {
+		return false, totalLineHeight, err
+	}
+	for range lines {
 		if totalLineHeight+lineHeight > rectangle.H {
 			return false, totalLineHeight, nil
 		}
-		lineWidth, _ := gp.MeasureTextWidth(string(line))
-		runeWidth, _ := gp.MeasureTextWidth(string(v))
-
-		if lineWidth+runeWidth > rectangle.W {
-			totalLineHeight += lineHeight
-			line = nil
-		}
-
-		line = append(line, v)
-
-		if i == length-1 {
-			totalLineHeight += lineHeight
-		}
+		totalLineHeight += lineHeight
 	}
 
-	ok := true
-	if totalLineHeight > rectangle.H {
-		ok = false
-	}
-
-	return ok, totalLineHeight, nil
+	return true, totalLineHeight, nil
 }
 
 // IsFitMultiCellWithNewline : similar to IsFitMultiCell, but process char newline as Br
@@ -1303,9 +1382,23 @@ func (gp *GoPdf) IsFitMultiCellWithNewline(rectangle *Rect, text string) (bool,
 }
 
 // MultiCellWithOption create of text with line breaks ( use current x,y is upper-left corner of cell)
+// Without a BreakOption, lines break with BreakModeUnicode. Justified text (Align Justify) and a Paragraph
+// option select the paragraph layout, see ParagraphOption.
 func (gp *GoPdf) MultiCellWithOption(rectangle *Rect, text string, opt CellOption) error {
+	if gp.isVertical() {
+		return gp.verticalMultiCell(rectangle, text, opt)
+	}
+	if opt.Paragraph != nil || opt.Align&Justify == Justify {
+		return gp.multiCellParagraphs(rectangle, text, opt)
+	}
 	if opt.BreakOption == nil {
-		opt.BreakOption = &DefaultBreakOption
+		opt.BreakOption = &BreakOption{Mode: BreakModeUnicode}
+	}
+	if opt.Direction == TextDirectionNone {
+		opt.Direction = gp.textDirection
+	}
+	if opt.Direction != TextDirectionNone {
+		opt.Direction = paragraphDirection(text, opt.Direction)
 	}
 
 	transparency, err := gp.getCachedTransparency(opt.Transparency)
@@ -1330,7 +1423,7 @@ func (gp *GoPdf) MultiCellWithOption(rectangle *Rect, text string, opt CellOptio
 	}
 	gp.PointsToUnitsVar(&lineHeight)
 
-	textSplits, err := gp.SplitTextWithOption(text, rectangle.W, opt.BreakOption)
+	textSplits, err := gp.splitTextWithOption(text, rectangle.W, opt.BreakOption)
 	if err != nil {
 		return err
 	}
@@ -1369,7 +1462,17 @@ func (gp *GoPdf) SplitTextWithWordWrap(text string, width float64) ([]string, er
 
 // SplitTextWithOption splits a text into multiple lines based on the current font size of the document.
 // BreakOptions allow to define the behavior of the split (strict or sensitive). For more information see BreakOption.
+// With a text direction set by SetTextDirection, the lines are returned in visual order.
 func (gp *GoPdf) SplitTextWithOption(text string, width float64, opt *BreakOption) ([]string, error) {
+	lines, err := gp.splitTextWithOption(text, width, opt)
+	if err != nil {
+		return lines, err
+	}
+	return bidiLines(lines, text, gp.textDirection), nil
+}
+
+// splitTextWithOption splits a text into lines in logical order.
+func (gp *GoPdf) splitTextWithOption(text string, width float64, opt *BreakOption) ([]string, error) {
 	// fallback to default break option
 	if opt == nil {
 		opt = &DefaultBreakOption
@@ -1381,6 +1484,12 @@ func (gp *GoPdf) SplitTextWithOption(text string, width float64, opt *BreakOptio
 	if utf8TextsLen == 0 {
 		return lineTexts, ErrEmptyString
 	}
+	if opt.Mode == BreakModeUnicode {
+		return gp.splitTextUnicode(text, width, opt)
+	}
+	if opt.Mode == BreakModeIndicatorSensitive && opt.Hyphenator != nil {
+		return gp.splitTextSegments(indicatorSegments(text, opt.BreakIndicator), width, 0, opt)
+	}
 	separatorWidth, err := gp.MeasureTextWidth(opt.Separator)
 	if err != nil {
 		return nil, err
@@ -1744,7 +1853,7 @@ func (gp *GoPdf) ImportTemplates(tpls map[string]int) {
 func (gp *GoPdf) AddExternalLink(url string, x, y, w, h float64) {
 	gp.UnitsToPointsVar(&x, &y, &w, &h)
 
-	linkOpt := linkOption{x, gp.config.PageSize.H - y, w, h, url, ""}
+	linkOpt := linkOption{x, gp.config.PageSize.H - y, w, h, url, "", nil, nil}
 	gp.addLink(linkOpt)
 }
 
@@ -1752,7 +1861,7 @@ func (gp *GoPdf) AddExternalLink(url string, x, y, w, h float64) {
 func (gp *GoPdf) AddInternalLink(anchor string, x, y, w, h float64) {
 	gp.UnitsToPointsVar(&x, &y, &w, &h)
 
-	linkOpt := linkOption{x, gp.config.PageSize.H - y, w, h, "", anchor}
+	linkOpt := linkOption{x, gp.config.PageSize.H - y, w, h, "", anchor, nil, nil}
 	gp.addLink(linkOpt)
 }
 
@@ -1764,7 +1873,8 @@ func (gp *GoPdf) addLink(option linkOption) {
 	page.LinkObjIds = append(page.LinkObjIds, linkObj+1)
 }
 
-// SetAnchor creates a new anchor.
+// SetAnchor creates a new anchor. The anchor is also written as a named
+// destination, so that it can be opened with file.pdf#nameddest=name.
 func (gp *GoPdf) SetAnchor(name string) {
 	y := gp.config.PageSize.H - gp.curr.Y + float64(gp.curr.FontSize)
 	gp.anchors[name] = anchorOption{gp.curr.IndexOfPageObj, y}
@@ -1853,8 +1963,8 @@ func (gp *GoPdf) setSubsetFontObject(subsetFont *SubsetFontObj, family string, o
 
 	if gp.indexOfProcSet != -1 {
 		procset := gp.pdfObjs[gp.indexOfProcSet].(*ProcSetObj)
-		if !procset.Relates.IsContainsFamilyAndStyle(family, option.Style&^Underline) {
-			procset.Relates = append(procset.Relates, RelateFont{Family: family, IndexOfObj: index, CountOfFont: gp.curr.CountOfFont, Style: option.Style &^ Underline})
+		if !procset.Relates.IsContainsFamilyAndStyle(family, option.Style&^decorations) {
+			procset.Relates = append(procset.Relates, RelateFont{Family: family, IndexOfObj: index, CountOfFont: gp.curr.CountOfFont, Style: option.Style &^ decorations})
 			subsetFont.CountOfFont = gp.curr.CountOfFont
 			gp.curr.CountOfFont++
 		}
@@ -1945,13 +2055,20 @@ func (gp *GoPdf) SetFillColorCMYK(c, m, y, k uint8) {
 
 // MeasureTextWidth : measure Width of text (use current font)
 func (gp *GoPdf) MeasureTextWidth(text string) (float64, error) {
+	if gp.isVertical() {
+		runs, err := gp.verticalRuns(text)
+		if err != nil {
+			return 0, err
+		}
+		return pointsToUnits(gp.config, verticalRunsLength(runs)), nil
+	}
 
-	text, err := gp.curr.FontISubset.AddChars(text) //AddChars for create CharacterToGlyphIndex
+	runs, err := gp.fontRuns(text) //AddChars for create CharacterToGlyphIndex
 	if err != nil {
 		return 0, err
 	}
 
-	_, _, textWidthPdfUnit, err := createContent(gp.curr.FontISubset, text, gp.curr.FontSize, gp.curr.CharSpacing, nil)
+	_, textWidthPdfUnit, err := gp.runsWidth(runs)
 	if err != nil {
 		return 0, err
 	}
@@ -2261,6 +2378,7 @@ func (gp *GoPdf) init() {
 	gp.curr.transparencyMap = NewTransparencyMap()
 	gp.anchors = make(map[string]anchorOption)
 	gp.curr.txtColorMode = "gray"
+	gp.tateChuYoko = defaultTateChuYoko
 
 	//init index
 	gp.indexOfPagesObj = -1
@@ -2338,10 +2456,14 @@ func (gp *GoPdf) prepare() {
 		catalogObj.SetIndexObjOutlines(gp.indexOfOutlinesObj)
 	}
 
-	// Add Names dictionary for embedded files.
-	if len(gp.embeddedFiles) > 0 {
+	// Add Names dictionary for embedded files, named destinations and
+	// document-level JavaScript.
+	if len(gp.embeddedFiles) > 0 || len(gp.namedDests) > 0 || len(gp.anchors) > 0 || len(gp.javaScripts) > 0 {
 		namesIdx := gp.addObj(namesObj{
 			embeddedFiles: gp.embeddedFiles,
+			dests:         gp.destNames(),
+			javaScripts:   gp.sortedJavaScripts(),
+			getRoot:       func() *GoPdf { return gp },
 		})
 		catalogObj := gp.pdfObjs[gp.indexOfCatalogObj].(*CatalogObj)
 		catalogObj.SetIndexObjNames(namesIdx)
@@ -2381,7 +2503,7 @@ func (gp *GoPdf) prepare() {
 
 	// Add AcroForm for interactive form fields.
 	if len(gp.formFields) > 0 {
-		af := acroFormObj{needAppearances: true}
+		af := acroFormObj{calcObjIDs: gp.calculationOrder()}
 		// Collect field object IDs and font references
 		fontSeen := make(map[string]bool)
 		for _, ref := range gp.formFields {
@@ -2409,7 +2531,6 @@ func (gp *GoPdf) prepare() {
 			objtype := gp.pdfObjs[i].getType()
 			switch objtype {
 			case "Page":
-				pagesObj.Kids = fmt.Sprintf("%s %d 0 R ", pagesObj.Kids, i+1)
 				pagesObj.PageCount++
 				indexCurrPage = i
 			case "Content":
@@ -2436,6 +2557,9 @@ func (gp *GoPdf) prepare() {
 			}
 			i++
 		}
+		for _, i := range gp.pageObjIndexes() {
+			pagesObj.Kids = fmt.Sprintf("%s %d 0 R ", pagesObj.Kids, i+1)
+		}
 	}
 }
 
@@ -2612,28 +2736,19 @@ func (gp *GoPdf) saveTransparency(transparency *Transparency) (*Transparency, er
 
 // IsCurrFontContainGlyph defines is current font contains to a glyph
 // r:           any rune
+// See GlyphFontFamily for the fonts of the fallback chain.
 func (gp *GoPdf) IsCurrFontContainGlyph(r rune) (bool, error) {
 	fontISubset := gp.curr.FontISubset
 	if fontISubset == nil {
 		return false, nil
 	}
 
-	glyphIndex, err := fontISubset.CharCodeToGlyphIndex(r)
-	if err == ErrGlyphNotFound {
-		return false, nil
-	} else if err != nil {
-		return false, err
-	}
-
-	if glyphIndex == 0 {
-		return false, nil
-	}
-
-	return true, nil
+	return fontContainsGlyph(fontISubset, r)
 }
 
 // SetPage set current page
 func (gp *GoPdf) SetPage(pageno int) error {
+	pageno = gp.naturalPageNo(pageno)
 	var pageIndex int
 	for i := 0; i < len(gp.pdfObjs); i++ {
 		switch gp.pdfObjs[i].(type) {
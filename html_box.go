@@ -0,0 +1,731 @@
+package gopdf
+
+import (
+	"strconv"
+	"strings"
+)
+
+// htmlEdges holds top, right, bottom and left lengths (in document units).
+type htmlEdges struct {
+	top, right, bottom, left float64
+}
+
+// htmlEdgeColors holds top, right, bottom and left border colors.
+type htmlEdgeColors struct {
+	top, right, bottom, left RGBColor
+}
+
+// htmlBox is the CSS box model of a block element: margins, borders,
+// padding, background and an optional content width.
+type htmlBox struct {
+	margin        htmlEdges
+	hasMargin     bool
+	padding       htmlEdges
+	border        htmlEdges
+	borderColor   htmlEdgeColors
+	background    RGBColor
+	hasBackground bool
+	width         float64
+	hasWidth      bool
+}
+
+func (b htmlBox) hasBorder() bool {
+	return b.border.top > 0 || b.border.right > 0 || b.border.bottom > 0 || b.border.left > 0
+}
+
+// nodeStyles returns the CSS declarations that apply to node. The returned
+// map must not be modified.
+func (r *htmlRenderer) nodeStyles(node *htmlNode) map[string]string {
+	if node.css != nil {
+		return node.css
+	}
+	if styleStr, ok := node.Attrs["style"]; ok {
+		return parseInlineStyle(styleStr)
+	}
+	return map[string]string{}
+}
+
+// cssLength converts a CSS length to document units. Percentages are
+// relative to relativeTo (in document units).
+func (r *htmlRenderer) cssLength(val string, relativeTo float64) (float64, bool) {
+	conv := r.unitConversion()
+	v, ok := parseDimension(val, relativeTo*conv)
+	if !ok {
+		return 0, false
+	}
+	return v / conv, true
+}
+
+// cssEdges parses a 1-4 value shorthand such as "margin: 4px 8px".
+func (r *htmlRenderer) cssEdges(val string, relativeTo float64) (htmlEdges, bool) {
+	var v []float64
+	for _, f := range strings.Fields(val) {
+		l, ok := r.cssLength(f, relativeTo)
+		if !ok {
+			if f != "auto" {
+				return htmlEdges{}, false
+			}
+			l = 0
+		}
+		v = append(v, l)
+	}
+	switch len(v) {
+	case 1:
+		return htmlEdges{v[0], v[0], v[0], v[0]}, true
+	case 2:
+		return htmlEdges{v[0], v[1], v[0], v[1]}, true
+	case 3:
+		return htmlEdges{v[0], v[1], v[2], v[1]}, true
+	case 4:
+		return htmlEdges{v[0], v[1], v[2], v[3]}, true
+	}
+	return htmlEdges{}, false
+}
+
+// cssEdgeColors parses a 1-4 value shorthand such as
+// "border-color: red blue".
+func cssEdgeColors(val string) (htmlEdgeColors, bool) {
+	var v []RGBColor
+	for _, f := range strings.Fields(val) {
+		cr, cg, cb, ok := parseCSSColor(f)
+		if !ok {
+			return htmlEdgeColors{}, false
+		}
+		v = append(v, RGBColor{cr, cg, cb})
+	}
+	switch len(v) {
+	case 1:
+		return htmlEdgeColors{v[0], v[0], v[0], v[0]}, true
+	case 2:
+		return htmlEdgeColors{v[0], v[1], v[0], v[1]}, true
+	case 3:
+		return htmlEdgeColors{v[0], v[1], v[2], v[1]}, true
+	case 4:
+		return htmlEdgeColors{v[0], v[1], v[2], v[3]}, true
+	}
+	return htmlEdgeColors{}, false
+}
+
+// cssBorder parses a border shorthand ("1px solid #ccc") into a width in
+// document units and a color.
+func (r *htmlRenderer) cssBorder(val string) (float64, RGBColor, bool) {
+	width := 1 / r.unitConversion() // medium ~ 1pt
+	color := RGBColor{}
+	found := false
+	for _, f := range strings.Fields(val) {
+		switch f {
+		case "none", "hidden":
+			return 0, color, true
+		case "solid", "dashed", "dotted", "double", "groove", "ridge", "inset", "outset":
+			found = true
+		case "thin":
+			width, found = 0.5/r.unitConversion(), true
+		case "medium":
+			width, found = 1/r.unitConversion(), true
+		case "thick":
+			width, found = 2/r.unitConversion(), true
+		default:
+			if cr, cg, cb, ok := parseCSSColor(f); ok {
+				color, found = RGBColor{cr, cg, cb}, true
+			} else if l, ok := r.cssLength(f, 0); ok {
+				width, found = l, true
+			}
+		}
+	}
+	return width, color, found
+}
+
+// parseBox reads the box model properties from styles on top of defaults.
+// relativeTo is the containing block width used for percentages.
+func (r *htmlRenderer) parseBox(styles map[string]string, defaults htmlBox, relativeTo float64) htmlBox {
+	box := defaults
+	if v, ok := styles["margin"]; ok {
+		if e, ok := r.cssEdges(v, relativeTo); ok {
+			box.margin, box.hasMargin = e, true
+		}
+	}
+	// sides in a fixed order, so that the result does not depend on map
+	// iteration
+	sides := []struct {
+		name                   string
+		margin, padding, width *float64
+		color                  *RGBColor
+	}{
+		{"top", &box.margin.top, &box.padding.top, &box.border.top, &box.borderColor.top},
+		{"right", &box.margin.right, &box.padding.right, &box.border.right, &box.borderColor.right},
+		{"bottom", &box.margin.bottom, &box.padding.bottom, &box.border.bottom, &box.borderColor.bottom},
+		{"left", &box.margin.left, &box.padding.left, &box.border.left, &box.borderColor.left},
+	}
+	for _, side := range sides {
+		if v, ok := styles["margin-"+side.name]; ok {
+			if l, ok := r.cssLength(v, relativeTo); ok {
+				*side.margin, box.hasMargin = l, true
+			}
+		}
+	}
+	if v, ok := styles["padding"]; ok {
+		if e, ok := r.cssEdges(v, relativeTo); ok {
+			box.padding = e
+		}
+	}
+	for _, side := range sides {
+		if v, ok := styles["padding-"+side.name]; ok {
+			if l, ok := r.cssLength(v, relativeTo); ok {
+				*side.padding = l
+			}
+		}
+	}
+	if v, ok := styles["border"]; ok {
+		if w, c, ok := r.cssBorder(v); ok {
+			box.border = htmlEdges{w, w, w, w}
+			box.borderColor = htmlEdgeColors{c, c, c, c}
+		}
+	}
+	for _, side := range sides {
+		if v, ok := styles["border-"+side.name]; ok {
+			if w, c, ok := r.cssBorder(v); ok {
+				*side.width, *side.color = w, c
+			}
+		}
+	}
+	if v, ok := styles["border-width"]; ok {
+		if e, ok := r.cssEdges(v, 0); ok {
+			box.border = e
+		}
+	}
+	if v, ok := styles["border-color"]; ok {
+		if c, ok := cssEdgeColors(v); ok {
+			box.borderColor = c
+		}
+	}
+	for _, side := range sides {
+		if v, ok := styles["border-"+side.name+"-color"]; ok {
+			if cr, cg, cb, ok := parseCSSColor(v); ok {
+				*side.color = RGBColor{cr, cg, cb}
+			}
+		}
+	}
+	bg, ok := styles["background-color"]
+	if !ok {
+		bg, ok = styles["background"]
+	}
+	if ok {
+		if cr, cg, cb, ok := parseCSSColor(bg); ok {
+			box.background, box.hasBackground = RGBColor{cr, cg, cb}, true
+		} else if strings.TrimSpace(bg) == "transparent" || strings.TrimSpace(bg) == "none" {
+			box.hasBackground = false
+		}
+	}
+	if v, ok := styles["width"]; ok {
+		if l, ok := r.cssLength(v, relativeTo); ok {
+			box.width, box.hasWidth = l, true
+		}
+	}
+	return box
+}
+
+// drawBoxBorder draws the borders of a box occupying x, y, w, h with the
+// same primitive as the table layout, each edge in its own color.
+func (r *htmlRenderer) drawBoxBorder(box htmlBox, x, y, w, h float64) error {
+	sides := []struct {
+		width float64
+		color RGBColor
+		style BorderStyle
+	}{
+		{box.border.top, box.borderColor.top, BorderStyle{Top: true}},
+		{box.border.right, box.borderColor.right, BorderStyle{Right: true}},
+		{box.border.bottom, box.borderColor.bottom, BorderStyle{Bottom: true}},
+		{box.border.left, box.borderColor.left, BorderStyle{Left: true}},
+	}
+	for _, s := range sides {
+		if s.width <= 0 {
+			continue
+		}
+		half := s.width / 2
+		s.style.Width = s.width
+		s.style.RGBColor = s.color
+		if err := r.gp.drawBorder(x+half, y+half, x+w-half, y+h-half, s.style); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// fillBox paints a background rectangle.
+func (r *htmlRenderer) fillBox(c RGBColor, x, y, w, h float64) {
+	if w <= 0 || h <= 0 {
+		return
+	}
+	r.gp.SetFillColor(c.R, c.G, c.B)
+	r.gp.RectFromUpperLeftWithStyle(x, y, w, h, "F")
+}
+
+// measureNodes returns the height nodes would occupy when rendered with
+// state into a column at x with width w, without drawing anything.
+func (r *htmlRenderer) measureNodes(nodes []*htmlNode, state htmlRenderState, x, w float64) (float64, error) {
+	m := *r
+	m.measure = true
+	m.boxX, m.boxW = x, w
+	m.boxY, m.boxH = 0, htmlUnbounded
+	m.cursorX, m.cursorY = x, 0
+	if err := m.renderNodes(nodes, state); err != nil {
+		return 0, err
+	}
+	if m.cursorX > m.boxX {
+		m.newLine(state)
+	}
+	return m.cursorY, nil
+}
+
+// htmlUnbounded is used as box height where content must not be cut off.
+const htmlUnbounded = 1e9
+
+// renderBlock renders a block element applying its CSS box model.
+// defaults carries the tag's default margins; outer is the parent state.
+func (r *htmlRenderer) renderBlock(node *htmlNode, outer, inner htmlRenderState, defaults htmlBox) error {
+	box := r.parseBox(r.nodeStyles(node), defaults, r.boxW)
+	if node.continued() {
+		// the top edge was drawn with the previous fragment
+		box.margin.top, box.border.top, box.padding.top = 0, 0, 0
+	}
+	if r.cursorX > r.boxX {
+		r.newLine(outer)
+	}
+	r.cursorY += box.margin.top
+
+	oldBoxX, oldBoxW := r.boxX, r.boxW
+	bx := r.boxX + box.margin.left
+	bw := r.boxW - box.margin.left - box.margin.right
+	if box.hasWidth {
+		bw = box.width + box.padding.left + box.padding.right + box.border.left + box.border.right
+	}
+	contentX := bx + box.border.left + box.padding.left
+	contentW := bw - box.border.left - box.border.right - box.padding.left - box.padding.right
+	top := r.cursorY
+	frame := box.border.top + box.padding.top + box.padding.bottom + box.border.bottom
+
+	// move the whole block to the next frame if not even its first line fits
+	if top+box.border.top+box.padding.top+r.lineHeight(inner) > r.boxY+r.boxH && !r.mustDraw() {
+		r.cursorY -= box.margin.top
+		r.breakBefore([]*htmlNode{node})
+		r.restSelf = true
+		return nil
+	}
+
+	if box.hasBackground && !r.measure {
+		h, err := r.measureNodes(node.Children, inner, contentX, contentW)
+		if err != nil {
+			return err
+		}
+		h += frame
+		if avail := r.boxY + r.boxH - top; h > avail && avail > 0 {
+			h = avail // the rest is painted with the next fragment
+		}
+		r.fillBox(box.background, bx, top, bw, h)
+	}
+
+	r.boxX, r.boxW = contentX, contentW
+	r.cursorX = contentX
+	r.cursorY = top + box.border.top + box.padding.top
+	err := r.renderNodes(node.Children, inner)
+	if err == nil && r.cursorX > r.boxX && !r.overflow {
+		r.newLine(inner)
+	}
+	if r.overflow {
+		// the bottom edge belongs to the last fragment
+		box.border.bottom = 0
+		if end := r.boxY + r.boxH; r.cursorY < end {
+			r.cursorY = end
+		}
+	} else {
+		r.cursorY += box.padding.bottom + box.border.bottom
+	}
+	r.boxX, r.boxW = oldBoxX, oldBoxW
+	r.cursorX = r.boxX
+	if err != nil {
+		return err
+	}
+
+	if box.hasBorder() && !r.measure {
+		if err := r.drawBoxBorder(box, bx, top, bw, r.cursorY-top); err != nil {
+			return err
+		}
+	}
+	if !r.overflow {
+		r.cursorY += box.margin.bottom
+	}
+	return nil
+}
+
+// blockDefaults returns the default vertical margins of a block element.
+func (r *htmlRenderer) blockDefaults(vertical float64) htmlBox {
+	v := vertical / r.unitConversion()
+	return htmlBox{margin: htmlEdges{top: v, bottom: v}}
+}
+
+// ---- tables ----
+
+// htmlTableCell is a <td> or <th> placed on the table grid.
+type htmlTableCell struct {
+	node             *htmlNode
+	row, col         int
+	rowspan, colspan int
+	header           bool
+	box              htmlBox
+	state            htmlRenderState
+	valign           string
+	height           float64 // content height including padding and borders
+}
+
+// renderTable lays out and draws a <table> with colspan/rowspan support.
+// Column widths come from width attributes/styles of single-column cells;
+// the remaining table width is shared equally by the other columns.
+func (r *htmlRenderer) renderTable(node *htmlNode, state htmlRenderState) error {
+	if r.cursorX > r.boxX {
+		r.newLine(state)
+	}
+	tableStyles := r.nodeStyles(node)
+	tbox := r.parseBox(tableStyles, r.blockDefaults(state.fontSize*0.3), r.boxW)
+	if w, ok := node.Attrs["width"]; ok && !tbox.hasWidth {
+		if l, ok := r.cssLength(w, r.boxW); ok {
+			tbox.width, tbox.hasWidth = l, true
+		}
+	}
+	if bg, ok := node.Attrs["bgcolor"]; ok && !tbox.hasBackground {
+		if cr, cg, cb, ok := parseCSSColor(bg); ok {
+			tbox.background, tbox.hasBackground = RGBColor{cr, cg, cb}, true
+		}
+	}
+
+	// HTML presentational attributes become defaults for every cell.
+	cellDefaults := htmlBox{}
+	if v, ok := node.Attrs["border"]; ok {
+		n, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		if v == "" || (err == nil && n > 0) {
+			if v == "" {
+				n = 1
+			}
+			w := n * 0.75 / r.unitConversion()
+			cellDefaults.border = htmlEdges{w, w, w, w}
+			if !tbox.hasBorder() {
+				tbox.border = cellDefaults.border
+			}
+		}
+	}
+	pad := 1.5 / r.unitConversion()
+	if v, ok := node.Attrs["cellpadding"]; ok {
+		if l, ok := r.cssLength(v, 0); ok {
+			pad = l
+		}
+	}
+	cellDefaults.padding = htmlEdges{pad, pad, pad, pad}
+
+	if node.continued() {
+		tbox.margin.top = 0
+	}
+	r.cursorY += tbox.margin.top
+	tableX := r.boxX + tbox.margin.left
+	tableW := r.boxW - tbox.margin.left - tbox.margin.right
+	if tbox.hasWidth && tbox.width < tableW {
+		tableW = tbox.width
+	}
+	innerX := tableX + tbox.border.left
+	innerW := tableW - tbox.border.left - tbox.border.right
+
+	// collect rows, including those inside row groups
+	type htmlTableRow struct {
+		node   *htmlNode
+		styles map[string]string
+	}
+	var rows []htmlTableRow
+	var heads []*htmlNode // leading <thead> groups, repeated when the table is split
+	headerRows := 0
+	var collect func(nodes []*htmlNode)
+	collect = func(nodes []*htmlNode) {
+		for _, n := range nodes {
+			if n.Type != htmlNodeElement {
+				continue
+			}
+			switch n.Tag {
+			case "tr":
+				rows = append(rows, htmlTableRow{node: n, styles: r.nodeStyles(n)})
+			case "thead", "tbody", "tfoot":
+				if n.Tag == "thead" && headerRows == len(rows) {
+					heads = append(heads, n)
+					collect(n.Children)
+					headerRows = len(rows)
+					continue
+				}
+				collect(n.Children)
+			}
+		}
+	}
+	collect(node.Children)
+
+	// place cells on the grid
+	var cells []*htmlTableCell
+	occupied := make(map[[2]int]bool)
+	numCols := 0
+	for ri, row := range rows {
+		col := 0
+		for _, n := range row.node.Children {
+			if n.Type != htmlNodeElement || (n.Tag != "td" && n.Tag != "th") {
+				continue
+			}
+			for occupied[[2]int{ri, col}] {
+				col++
+			}
+			c := &htmlTableCell{node: n, row: ri, col: col, rowspan: 1, colspan: 1, header: n.Tag == "th"}
+			if v, err := strconv.Atoi(n.Attrs["colspan"]); err == nil && v > 1 {
+				c.colspan = v
+			}
+			if v, err := strconv.Atoi(n.Attrs["rowspan"]); err == nil && v > 1 {
+				c.rowspan = v
+				if ri+v > len(rows) {
+					c.rowspan = len(rows) - ri
+				}
+			}
+			for dr := 0; dr < c.rowspan; dr++ {
+				for dc := 0; dc < c.colspan; dc++ {
+					occupied[[2]int{ri + dr, col + dc}] = true
+				}
+			}
+			col += c.colspan
+			if col > numCols {
+				numCols = col
+			}
+			cells = append(cells, c)
+		}
+	}
+	if numCols == 0 {
+		r.cursorY += tbox.margin.bottom
+		return nil
+	}
+
+	// column widths
+	colW := make([]float64, numCols)
+	fixed := make([]bool, numCols)
+	for _, c := range cells {
+		if c.colspan != 1 || fixed[c.col] {
+			continue
+		}
+		w, ok := r.nodeStyles(c.node)["width"]
+		if !ok {
+			w, ok = c.node.Attrs["width"]
+		}
+		if !ok {
+			continue
+		}
+		if l, ok := r.cssLength(w, innerW); ok && l > 0 {
+			colW[c.col], fixed[c.col] = l, true
+		}
+	}
+	used, free := 0.0, 0
+	for i := range colW {
+		if fixed[i] {
+			used += colW[i]
+		} else {
+			free++
+		}
+	}
+	if free > 0 {
+		share := (innerW - used) / float64(free)
+		if share < 0 {
+			share = 0
+		}
+		for i := range colW {
+			if !fixed[i] {
+				colW[i] = share
+			}
+		}
+	} else if used > innerW || tbox.hasWidth {
+		for i := range colW {
+			colW[i] *= innerW / used
+		}
+	}
+	colX := make([]float64, numCols+1)
+	colX[0] = innerX
+	for i, w := range colW {
+		colX[i+1] = colX[i] + w
+	}
+
+	// measure cells
+	rowH := make([]float64, len(rows))
+	for _, c := range cells {
+		row := rows[c.row]
+		styles := r.nodeStyles(c.node)
+		cellState := state
+		if c.header {
+			cellState.fontStyle |= Bold
+			cellState.align = Center
+		}
+		if v, ok := row.node.Attrs["align"]; ok {
+			cellState = applyHTMLAlign(cellState, v)
+		}
+		if v, ok := c.node.Attrs["align"]; ok {
+			cellState = applyHTMLAlign(cellState, v)
+		}
+		cellState = r.applyStyleAttr(row.node, cellState)
+		cellState = r.applyStyleAttr(c.node, cellState)
+		c.state = cellState
+
+		defaults := cellDefaults
+		rowBox := r.parseBox(row.styles, htmlBox{}, innerW)
+		if rowBox.hasBackground {
+			defaults.background, defaults.hasBackground = rowBox.background, true
+		}
+		if bg, ok := c.node.Attrs["bgcolor"]; ok {
+			if cr, cg, cb, ok := parseCSSColor(bg); ok {
+				defaults.background, defaults.hasBackground = RGBColor{cr, cg, cb}, true
+			}
+		}
+		c.box = r.parseBox(styles, defaults, innerW)
+		c.valign = "middle"
+		if v, ok := c.node.Attrs["valign"]; ok {
+			c.valign = strings.ToLower(v)
+		}
+		if v, ok := styles["vertical-align"]; ok {
+			c.valign = strings.ToLower(v)
+		}
+
+		x, w := r.cellContentArea(c, colX)
+		h, err := r.measureNodes(c.node.Children, c.state, x, w)
+		if err != nil {
+			return err
+		}
+		if h == 0 {
+			h = r.lineHeight(c.state)
+		}
+		c.height = h + c.box.padding.top + c.box.padding.bottom + c.box.border.top + c.box.border.bottom
+		if c.rowspan == 1 && c.height > rowH[c.row] {
+			rowH[c.row] = c.height
+		}
+	}
+	for _, c := range cells {
+		if c.rowspan == 1 {
+			continue
+		}
+		span := 0.0
+		for i := c.row; i < c.row+c.rowspan; i++ {
+			span += rowH[i]
+		}
+		if c.height > span {
+			rowH[c.row+c.rowspan-1] += c.height - span
+		}
+	}
+
+	// draw
+	top := r.cursorY
+	rowY := make([]float64, len(rows)+1)
+	rowY[0] = top + tbox.border.top
+	for i, h := range rowH {
+		rowY[i+1] = rowY[i] + h
+	}
+	// rows beyond the box are left for the next frame; a row group joined
+	// by rowspan is never split
+	validCut := func(k int) bool {
+		for _, c := range cells {
+			if c.row < k && c.row+c.rowspan > k {
+				return false
+			}
+		}
+		return true
+	}
+	limit := r.boxY + r.boxH - tbox.border.bottom
+	lastRow := len(rows)
+	for lastRow > 0 && (rowY[lastRow] > limit || !validCut(lastRow)) {
+		lastRow--
+	}
+	if lastRow < len(rows) && lastRow <= headerRows {
+		if !r.mustDraw() {
+			r.cursorY = top
+			r.breakBefore([]*htmlNode{node})
+			r.restSelf = true
+			return nil
+		}
+		for lastRow = headerRows + 1; lastRow < len(rows) && !validCut(lastRow); lastRow++ {
+		}
+	}
+	tableH := rowY[lastRow] - top + tbox.border.bottom
+
+	if !r.measure {
+		if tbox.hasBackground {
+			r.fillBox(tbox.background, tableX, top, tableW, tableH)
+		}
+		oldBoxX, oldBoxY, oldBoxW, oldBoxH := r.boxX, r.boxY, r.boxW, r.boxH
+		for _, c := range cells {
+			if c.row >= lastRow {
+				continue
+			}
+			endRow := c.row + c.rowspan
+			if endRow > lastRow {
+				endRow = lastRow
+			}
+			x, y := colX[c.col], rowY[c.row]
+			w, h := colX[c.col+c.colspan]-x, rowY[endRow]-y
+			if c.box.hasBackground {
+				r.fillBox(c.box.background, x, y, w, h)
+			}
+
+			cx, cw := r.cellContentArea(c, colX)
+			cy := y + c.box.border.top + c.box.padding.top
+			switch c.valign {
+			case "middle", "center":
+				cy += (h - c.height) / 2
+			case "bottom":
+				cy += h - c.height
+			}
+			r.boxX, r.boxW = cx, cw
+			r.boxY, r.boxH = y, htmlUnbounded
+			r.cursorX, r.cursorY = cx, cy
+			if err := r.renderNodes(c.node.Children, c.state); err != nil {
+				return err
+			}
+			if err := r.drawBoxBorder(c.box, x, y, w, h); err != nil {
+				return err
+			}
+		}
+		r.boxX, r.boxY, r.boxW, r.boxH = oldBoxX, oldBoxY, oldBoxW, oldBoxH
+		if err := r.drawBoxBorder(tbox, tableX, top, tableW, tableH); err != nil {
+			return err
+		}
+	}
+
+	r.drawn = true
+	r.cursorX = r.boxX
+	if lastRow < len(rows) {
+		r.cursorY = top + tableH
+		rest := &htmlNode{Type: htmlNodeElement, Tag: "tbody"}
+		for _, row := range rows[lastRow:] {
+			rest.Children = append(rest.Children, row.node)
+		}
+		r.breakBefore([]*htmlNode{node.continuedWith(append(heads[:len(heads):len(heads)], rest))})
+		r.restSelf = true
+		return nil
+	}
+	r.cursorY = top + tableH + tbox.margin.bottom
+	return nil
+}
+
+// cellContentArea returns the x position and width of the content box of c.
+func (r *htmlRenderer) cellContentArea(c *htmlTableCell, colX []float64) (float64, float64) {
+	x := colX[c.col] + c.box.border.left + c.box.padding.left
+	w := colX[c.col+c.colspan] - colX[c.col] - c.box.border.left - c.box.border.right - c.box.padding.left - c.box.padding.right
+	if w < 0 {
+		w = 0
+	}
+	return x, w
+}
+
+// applyHTMLAlign applies the legacy align attribute.
+func applyHTMLAlign(state htmlRenderState, v string) htmlRenderState {
+	switch strings.ToLower(strings.TrimSpace(v)) {
+	case "center":
+		state.align = Center
+	case "right":
+		state.align = Right
+	case "left":
+		state.align = Left
+	}
+	return state
+}
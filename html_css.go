@@ -0,0 +1,354 @@
+package gopdf
+
+import (
+	"sort"
+	"strings"
+)
+
+// cssCompound is one compound selector such as "p.note#intro".
+type cssCompound struct {
+	tag     string // "" or "*" matches any element
+	id      string
+	classes []string
+}
+
+// cssSelector is a complex selector, stored right to left: parts[0] is the
+// subject and combinators[i] joins parts[i] to parts[i+1] (' ' for
+// descendant, '>' for child).
+type cssSelector struct {
+	parts       []cssCompound
+	combinators []byte
+}
+
+// specificity returns the (id, class, type) weight packed into one int.
+func (s cssSelector) specificity() int {
+	ids, classes, tags := 0, 0, 0
+	for _, p := range s.parts {
+		if p.id != "" {
+			ids++
+		}
+		classes += len(p.classes)
+		if p.tag != "" && p.tag != "*" {
+			tags++
+		}
+	}
+	return ids<<16 | classes<<8 | tags
+}
+
+// cssRule is a style rule with a single selector; rules with a selector list
+// are split into one cssRule per selector.
+type cssRule struct {
+	selector    cssSelector
+	specificity int
+	order       int
+	decls       map[string]string
+	important   map[string]string
+}
+
+// parseStyleSheet parses CSS text into rules. At-rules and selectors using
+// unsupported syntax (attribute selectors, pseudo-classes, sibling
+// combinators) are skipped.
+func parseStyleSheet(css string, order int) []cssRule {
+	css = stripCSSComments(css)
+	var rules []cssRule
+	for {
+		open := strings.IndexByte(css, '{')
+		if open < 0 {
+			break
+		}
+		prelude := strings.TrimSpace(css[:open])
+		end := strings.IndexByte(css[open:], '}')
+		if end < 0 {
+			break
+		}
+		body := css[open+1 : open+end]
+		css = css[open+end+1:]
+
+		if strings.HasPrefix(prelude, "@") {
+			// skip nested blocks of at-rules such as @media
+			depth := strings.Count(body, "{")
+			for depth > 0 {
+				close := strings.IndexByte(css, '}')
+				if close < 0 {
+					css = ""
+					break
+				}
+				depth += strings.Count(css[:close], "{") - 1
+				css = css[close+1:]
+			}
+			continue
+		}
+
+		decls, important := parseCSSDeclarations(body)
+		for _, sel := range strings.Split(prelude, ",") {
+			selector, ok := parseCSSSelector(sel)
+			if !ok {
+				continue
+			}
+			rules = append(rules, cssRule{
+				selector:    selector,
+				specificity: selector.specificity(),
+				order:       order,
+				decls:       decls,
+				important:   important,
+			})
+			order++
+		}
+	}
+	return rules
+}
+
+func stripCSSComments(css string) string {
+	var sb strings.Builder
+	for {
+		start := strings.Index(css, "/*")
+		if start < 0 {
+			sb.WriteString(css)
+			return sb.String()
+		}
+		sb.WriteString(css[:start])
+		end := strings.Index(css[start+2:], "*/")
+		if end < 0 {
+			return sb.String()
+		}
+		css = css[start+2+end+2:]
+	}
+}
+
+// parseCSSDeclarations splits a declaration block into normal and
+// !important declarations.
+func parseCSSDeclarations(block string) (map[string]string, map[string]string) {
+	decls := parseInlineStyle(block)
+	important := make(map[string]string)
+	for k, v := range decls {
+		if idx := strings.Index(v, "!"); idx >= 0 && strings.EqualFold(strings.TrimSpace(v[idx+1:]), "important") {
+			important[k] = strings.TrimSpace(v[:idx])
+			delete(decls, k)
+		}
+	}
+	return decls, important
+}
+
+// parseCSSSelector parses a complex selector made of type, class, id and
+// universal selectors joined by descendant or child combinators.
+func parseCSSSelector(sel string) (cssSelector, bool) {
+	sel = strings.TrimSpace(sel)
+	if sel == "" {
+		return cssSelector{}, false
+	}
+	var parts []cssCompound
+	var combinators []byte
+	pending := byte(0)
+	for _, tok := range strings.Fields(strings.ReplaceAll(sel, ">", " > ")) {
+		if tok == ">" {
+			if len(parts) == 0 || pending == '>' {
+				return cssSelector{}, false
+			}
+			pending = '>'
+			continue
+		}
+		c, ok := parseCSSCompound(tok)
+		if !ok {
+			return cssSelector{}, false
+		}
+		if len(parts) > 0 {
+			if pending == 0 {
+				pending = ' '
+			}
+			combinators = append(combinators, pending)
+		}
+		parts = append(parts, c)
+		pending = 0
+	}
+	if len(parts) == 0 || pending != 0 {
+		return cssSelector{}, false
+	}
+	// reverse to right-to-left order
+	for i, j := 0, len(parts)-1; i < j; i, j = i+1, j-1 {
+		parts[i], parts[j] = parts[j], parts[i]
+	}
+	for i, j := 0, len(combinators)-1; i < j; i, j = i+1, j-1 {
+		combinators[i], combinators[j] = combinators[j], combinators[i]
+	}
+	return cssSelector{parts: parts, combinators: combinators}, true
+}
+
+func parseCSSCompound(tok string) (cssCompound, bool) {
+	var c cssCompound
+	i := 0
+	readName := func() string {
+		start := i
+		for i < len(tok) && tok[i] != '.' && tok[i] != '#' {
+			ch := tok[i]
+			if !(ch == '-' || ch == '_' || ch == '*' || ch >= '0' && ch <= '9' || ch >= 'a' && ch <= 'z' || ch >= 'A' && ch <= 'Z' || ch >= 0x80) {
+				return ""
+			}
+			i++
+		}
+		return tok[start:i]
+	}
+	if tok[0] != '.' && tok[0] != '#' {
+		c.tag = strings.ToLower(readName())
+		if c.tag == "" || (strings.Contains(c.tag, "*") && c.tag != "*") {
+			return c, false
+		}
+	}
+	for i < len(tok) {
+		kind := tok[i]
+		i++
+		name := readName()
+		if name == "" || strings.Contains(name, "*") {
+			return c, false
+		}
+		if kind == '#' {
+			c.id = name
+		} else {
+			c.classes = append(c.classes, name)
+		}
+	}
+	return c, true
+}
+
+func (c cssCompound) matches(n *htmlNode) bool {
+	if c.tag != "" && c.tag != "*" && c.tag != n.Tag {
+		return false
+	}
+	if c.id != "" && n.Attrs["id"] != c.id {
+		return false
+	}
+	if len(c.classes) > 0 {
+		classes := strings.Fields(n.Attrs["class"])
+		for _, want := range c.classes {
+			found := false
+			for _, cl := range classes {
+				if cl == want {
+					found = true
+					break
+				}
+			}
+			if !found {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// matches reports whether the selector matches n; ancestors lists the
+// ancestors of n from the root down to its parent.
+func (s cssSelector) matches(n *htmlNode, ancestors []*htmlNode) bool {
+	if !s.parts[0].matches(n) {
+		return false
+	}
+	return s.matchAncestors(1, ancestors)
+}
+
+func (s cssSelector) matchAncestors(part int, ancestors []*htmlNode) bool {
+	if part == len(s.parts) {
+		return true
+	}
+	switch s.combinators[part-1] {
+	case '>':
+		if len(ancestors) == 0 {
+			return false
+		}
+		parent := ancestors[len(ancestors)-1]
+		return s.parts[part].matches(parent) && s.matchAncestors(part+1, ancestors[:len(ancestors)-1])
+	default:
+		for i := len(ancestors) - 1; i >= 0; i-- {
+			if s.parts[part].matches(ancestors[i]) && s.matchAncestors(part+1, ancestors[:i]) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// applyStyleSheets removes <style> elements (and <head>) from nodes and
+// resolves the cascade of extraCSS followed by the <style> blocks for every
+// element. The resolved declarations, including the inline style attribute,
+// are stored on the nodes and returned by htmlRenderer.nodeStyles.
+// Inherited properties flow down through htmlRenderState.
+func applyStyleSheets(nodes []*htmlNode, extraCSS string) []*htmlNode {
+	var sheets []string
+	if strings.TrimSpace(extraCSS) != "" {
+		sheets = append(sheets, extraCSS)
+	}
+	nodes = extractStyleElements(nodes, &sheets)
+	if len(sheets) == 0 {
+		return nodes
+	}
+	var rules []cssRule
+	for _, sheet := range sheets {
+		rules = append(rules, parseStyleSheet(sheet, len(rules))...)
+	}
+	sort.SliceStable(rules, func(i, j int) bool {
+		if rules[i].specificity != rules[j].specificity {
+			return rules[i].specificity < rules[j].specificity
+		}
+		return rules[i].order < rules[j].order
+	})
+	resolveStyles(nodes, nil, rules)
+	return nodes
+}
+
+func extractStyleElements(nodes []*htmlNode, sheets *[]string) []*htmlNode {
+	kept := nodes[:0:0]
+	for _, n := range nodes {
+		if n.Type == htmlNodeElement {
+			switch n.Tag {
+			case "style":
+				var sb strings.Builder
+				for _, c := range n.Children {
+					sb.WriteString(c.Text)
+				}
+				*sheets = append(*sheets, sb.String())
+				continue
+			case "head":
+				extractStyleElements(n.Children, sheets)
+				continue
+			}
+			n.Children = extractStyleElements(n.Children, sheets)
+		}
+		kept = append(kept, n)
+	}
+	return kept
+}
+
+// resolveStyles computes the cascaded declarations of every element:
+// stylesheet rules by specificity and source order, then the style
+// attribute, then !important rules and !important inline declarations.
+func resolveStyles(nodes []*htmlNode, ancestors []*htmlNode, rules []cssRule) {
+	for _, n := range nodes {
+		if n.Type != htmlNodeElement {
+			continue
+		}
+		css := make(map[string]string)
+		var important []map[string]string
+		for _, rule := range rules {
+			if !rule.selector.matches(n, ancestors) {
+				continue
+			}
+			for k, v := range rule.decls {
+				css[k] = v
+			}
+			if len(rule.important) > 0 {
+				important = append(important, rule.important)
+			}
+		}
+		inline, inlineImportant := parseCSSDeclarations(n.Attrs["style"])
+		for k, v := range inline {
+			css[k] = v
+		}
+		for _, imp := range important {
+			for k, v := range imp {
+				css[k] = v
+			}
+		}
+		for k, v := range inlineImportant {
+			css[k] = v
+		}
+		n.css = css
+		resolveStyles(n.Children, append(ancestors, n), rules)
+	}
+}
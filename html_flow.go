@@ -0,0 +1,300 @@
+package gopdf
+
+import (
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ErrHTMLFlowNoProgress is returned when content of an HTMLFlow does not fit
+// even into an empty page.
+var ErrHTMLFlowNoProgress = errors.New("html flow: content does not fit into an empty frame")
+
+// HTMLFrame is a rectangular area (in document units) that receives part of
+// an HTMLFlow.
+type HTMLFrame struct {
+	// Page is the 1-based page to draw on. 0 keeps the current page.
+	Page int
+	// X, Y, W, H describe the frame, like the box of InsertHTMLBox.
+	X, Y, W, H float64
+}
+
+// HTMLFlow renders HTML content that may not fit into a single box. Every
+// render call draws as much content as fits and keeps the rest, so a long
+// document can continue into linked frames (e.g. newsletter columns) or onto
+// new pages.
+//
+// Content is split between lines of text, list items, table rows (the
+// <thead> rows are repeated) and images. The CSS properties
+// page-break-before, page-break-after and page-break-inside (or their
+// break-before, break-after and break-inside equivalents) control where
+// frames end.
+type HTMLFlow struct {
+	gp    *GoPdf
+	opt   HTMLBoxOption
+	nodes []*htmlNode // content not rendered yet
+}
+
+// NewHTMLFlow parses htmlStr for rendering with the HTMLFlow render methods.
+func (gp *GoPdf) NewHTMLFlow(htmlStr string, opt HTMLBoxOption) (*HTMLFlow, error) {
+	if opt.DefaultFontSize <= 0 {
+		opt.DefaultFontSize = 12
+	}
+	if opt.DefaultFontFamily == "" {
+		return nil, ErrMissingFontFamily
+	}
+	return &HTMLFlow{
+		gp:    gp,
+		opt:   opt,
+		nodes: applyStyleSheets(parseHTML(htmlStr), opt.CSS),
+	}, nil
+}
+
+// Done reports whether all content has been rendered.
+func (f *HTMLFlow) Done() bool {
+	return len(f.nodes) == 0
+}
+
+// Remainder returns the content not rendered yet as HTML. Styles from
+// <style> elements and HTMLBoxOption.CSS are written into the style
+// attributes of the elements. Elements split across frames are returned as
+// plain elements, so rendering the HTML again repeats their top margin,
+// border and padding.
+func (f *HTMLFlow) Remainder() string {
+	return htmlString(f.nodes)
+}
+
+// RenderBox renders as much of the remaining content as fits into the box
+// and returns the Y position after the last rendered content.
+func (f *HTMLFlow) RenderBox(x, y, w, h float64) (float64, error) {
+	endY, _, err := f.render(x, y, w, h, false)
+	return endY, err
+}
+
+// RenderFrames renders the remaining content into frames in order, moving to
+// the next frame when one is full. It stops when the flow is done and
+// returns the Y position after the last rendered content of the last frame
+// used. Content left when all frames are full stays in the flow.
+func (f *HTMLFlow) RenderFrames(frames []HTMLFrame) (float64, error) {
+	endY := 0.0
+	for _, frame := range frames {
+		if f.Done() {
+			break
+		}
+		if frame.Page > 0 {
+			if err := f.gp.SetPage(frame.Page); err != nil {
+				return endY, err
+			}
+		}
+		var err error
+		if endY, _, err = f.render(frame.X, frame.Y, frame.W, frame.H, false); err != nil {
+			return endY, err
+		}
+	}
+	return endY, nil
+}
+
+// RenderPages renders all remaining content, starting at the current Y
+// position of the current page and adding pages with AddPage as needed.
+// Content is kept inside the page margins; headers and footers registered
+// with AddHeader and AddFooter are drawn on every new page and should be
+// placed in the margins. The current position is left after the content.
+func (f *HTMLFlow) RenderPages() (float64, error) {
+	gp := f.gp
+	if gp.GetNumberOfPages() == 0 {
+		gp.AddPage()
+	}
+	first := true
+	for {
+		pageW := gp.PointsToUnits(gp.curr.pageSize.W)
+		pageH := gp.PointsToUnits(gp.curr.pageSize.H)
+		left, top, right, bottom := gp.Margins()
+		y := top
+		if first && gp.GetY() > y {
+			y = gp.GetY()
+		}
+		// on a fresh page something must be rendered to guarantee progress
+		force := !first || y == top
+		endY, progressed, err := f.render(left, y, pageW-left-right, pageH-top-bottom-(y-top), force)
+		if err != nil {
+			return endY, err
+		}
+		if f.Done() {
+			gp.SetXY(left, endY)
+			return endY, nil
+		}
+		if force && !progressed {
+			return endY, ErrHTMLFlowNoProgress
+		}
+		gp.AddPage()
+		first = false
+	}
+}
+
+// render draws the remaining content into one frame and keeps the rest.
+func (f *HTMLFlow) render(x, y, w, h float64, force bool) (float64, bool, error) {
+	r := newHTMLRenderer(f.gp, f.opt, x, y, w, h)
+	r.flow, r.force = true, force
+	if err := r.renderNodes(f.nodes, r.initialState()); err != nil {
+		return r.cursorY, r.drawn, err
+	}
+	progressed := r.drawn || !r.overflow || len(r.rest) != len(f.nodes) ||
+		(len(r.rest) > 0 && r.rest[0] != f.nodes[0])
+	if r.overflow {
+		f.nodes = r.rest
+	} else {
+		f.nodes = nil
+	}
+	return r.cursorY, progressed, nil
+}
+
+// mustDraw reports whether content has to be drawn even if it does not fit,
+// so that an empty frame always makes progress.
+func (r *htmlRenderer) mustDraw() bool {
+	return r.force && !r.drawn
+}
+
+// breakBefore ends the frame; rest is the content to continue with.
+func (r *htmlRenderer) breakBefore(rest []*htmlNode) {
+	r.overflow = true
+	r.rest = rest
+	r.restSelf = false
+}
+
+// breakList ends the frame inside a list; items are the list items left and
+// start is the number of the first of them.
+func (r *htmlRenderer) breakList(list *htmlNode, items []*htmlNode, start int) {
+	if len(items) == 0 {
+		r.breakBefore(nil)
+	} else {
+		rest := list.continuedWith(items)
+		if list.Tag == "ol" {
+			rest.Attrs["start"] = strconv.Itoa(start)
+		}
+		r.breakBefore([]*htmlNode{rest})
+	}
+	r.restSelf = true
+}
+
+// pageBreakBefore ends the frame before node if its CSS requests a break
+// before it, or if it must not be split but does not fit in the frame.
+func (r *htmlRenderer) pageBreakBefore(node *htmlNode, state htmlRenderState) bool {
+	if r.measure || r.boxH >= htmlUnbounded || !r.drawn {
+		return false
+	}
+	styles := r.nodeStyles(node)
+	if isCSSPageBreak(styles["page-break-before"]) || isCSSPageBreak(styles["break-before"]) {
+		r.breakBefore([]*htmlNode{node})
+		return true
+	}
+	if styles["page-break-inside"] == "avoid" || strings.HasPrefix(styles["break-inside"], "avoid") {
+		h, err := r.measureNodes([]*htmlNode{node}, state, r.boxX, r.boxW)
+		if err == nil && r.cursorY+h > r.boxY+r.boxH && h <= r.boxH {
+			r.breakBefore([]*htmlNode{node})
+			return true
+		}
+	}
+	return false
+}
+
+// pageBreakAfter ends the frame after node if its CSS requests a break.
+func (r *htmlRenderer) pageBreakAfter(node *htmlNode) {
+	if r.measure || r.boxH >= htmlUnbounded {
+		return
+	}
+	styles := r.nodeStyles(node)
+	if isCSSPageBreak(styles["page-break-after"]) || isCSSPageBreak(styles["break-after"]) {
+		r.breakBefore(nil)
+	}
+}
+
+func isCSSPageBreak(val string) bool {
+	switch strings.TrimSpace(val) {
+	case "always", "page", "left", "right", "column":
+		return true
+	}
+	return false
+}
+
+// continued reports whether n is the remainder of a split element. Its top
+// margin, border and padding, and list markers were already drawn with the
+// previous fragment.
+func (n *htmlNode) continued() bool {
+	return n.split
+}
+
+// continuedWith returns a copy of n marked as continued with the given children.
+func (n *htmlNode) continuedWith(children []*htmlNode) *htmlNode {
+	c := *n
+	c.Attrs = make(map[string]string, len(n.Attrs))
+	for k, v := range n.Attrs {
+		c.Attrs[k] = v
+	}
+	c.split = true
+	c.Children = children
+	return &c
+}
+
+// htmlString serializes nodes back to HTML. Elements styled by stylesheets
+// get their cascaded declarations as style attribute, so the HTML renders
+// the same without the stylesheets.
+func htmlString(nodes []*htmlNode) string {
+	var sb strings.Builder
+	writeHTMLNodes(&sb, nodes)
+	return sb.String()
+}
+
+func writeHTMLNodes(sb *strings.Builder, nodes []*htmlNode) {
+	textEscaper := strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+	attrEscaper := strings.NewReplacer("&", "&amp;", "\"", "&quot;")
+	for _, n := range nodes {
+		if n.Type == htmlNodeText {
+			sb.WriteString(textEscaper.Replace(n.Text))
+			continue
+		}
+		sb.WriteString("<" + n.Tag)
+		attrs := n.Attrs
+		if n.css != nil {
+			// the stylesheets are gone: write the cascaded declarations
+			attrs = make(map[string]string, len(n.Attrs)+1)
+			for k, v := range n.Attrs {
+				attrs[k] = v
+			}
+			delete(attrs, "style")
+			if style := cssDeclarationString(n.css); style != "" {
+				attrs["style"] = style
+			}
+		}
+		keys := make([]string, 0, len(attrs))
+		for k := range attrs {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			sb.WriteString(" " + k + "=\"" + attrEscaper.Replace(attrs[k]) + "\"")
+		}
+		if n.SelfClose || isVoidElement(n.Tag) {
+			sb.WriteString(" />")
+			continue
+		}
+		sb.WriteString(">")
+		writeHTMLNodes(sb, n.Children)
+		sb.WriteString("</" + n.Tag + ">")
+	}
+}
+
+// cssDeclarationString returns css as the value of a style attribute, with
+// the properties in alphabetical order.
+func cssDeclarationString(css map[string]string) string {
+	keys := make([]string, 0, len(css))
+	for k := range css {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	decls := make([]string, len(keys))
+	for i, k := range keys {
+		decls[i] = k + ": " + css[k]
+	}
+	return strings.Join(decls, "; ")
+}
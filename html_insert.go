@@ -29,13 +29,18 @@ type HTMLBoxOption struct {
 
 	// BoldItalicFontFamily is the font family to use for bold+italic text.
 	BoldItalicFontFamily string
+
+	// CSS is an optional style sheet applied before the <style> elements of
+	// the HTML. Type, class, id, descendant and child selectors are supported.
+	CSS string
 }
 
 // htmlRenderState tracks the current rendering state while walking the HTML tree.
 type htmlRenderState struct {
 	fontFamily string
 	fontSize   float64
-	fontStyle  int // Regular, Bold, Italic, Underline
+	fontStyle  int     // Regular, Bold, Italic, Underline, Strikethrough, Overline
+	rise       float64 // text rise in points, for <sub> and <sup>
 	colorR     uint8
 	colorG     uint8
 	colorB     uint8
@@ -52,6 +57,15 @@ type htmlRenderer struct {
 	boxH    float64 // box height (units)
 	cursorX float64 // current X position (units)
 	cursorY float64 // current Y position (units)
+	measure bool    // advance the cursor without drawing
+
+	// pagination state, see html_flow.go
+	flow     bool        // rendering an HTMLFlow, which keeps what does not fit
+	force    bool        // render at least one line/row/image even if it does not fit
+	drawn    bool        // something has been drawn into the box
+	overflow bool        // the box is full; rest holds the unrendered content
+	rest     []*htmlNode // unrendered content replacing the node(s) being rendered
+	restSelf bool        // rest already replaces the current element itself
 }
 
 // InsertHTMLBox renders simplified HTML content into a rectangular area on the PDF.
@@ -60,7 +74,7 @@ type htmlRenderer struct {
 //   - <b>, <strong>: Bold text
 //   - <i>, <em>: Italic text
 //   - <u>: Underlined text
-//   - <s>, <strike>, <del>: Strikethrough (rendered as underline for simplicity)
+//   - <s>, <strike>, <del>: Strikethrough
 //   - <br>, <br/>: Line break
 //   - <p>: Paragraph (adds vertical spacing)
 //   - <h1> to <h6>: Headings with automatic font sizing
@@ -71,7 +85,12 @@ type htmlRenderer struct {
 //   - <center>: Centered text
 //   - <ul>, <ol>, <li>: Lists (basic bullet/number)
 //   - <a href="...">: Links (rendered as colored text, link annotation added)
-//   - <sub>, <sup>: Subscript/superscript (approximated with smaller font)
+//   - <sub>, <sup>: Subscript/superscript, sized and shifted by the font metrics
+//   - <table>, <thead>, <tbody>, <tfoot>, <tr>, <td>, <th>: Tables with colspan/rowspan
+//   - <style>: Style sheets with type, class, id, descendant and child selectors
+//
+// Block elements (<p>, <div>, <blockquote>, <h1>-<h6>, <table>, <td>, <th>)
+// honor the CSS box model: margin, padding, border, background-color and width.
 //
 // Parameters:
 //   - x, y: Top-left corner of the box (in document units)
@@ -80,6 +99,8 @@ type htmlRenderer struct {
 //   - opt: Rendering options (font families, default size, colors, etc.)
 //
 // Returns the Y position after the last rendered content (in document units).
+// Content that does not fit into the box is dropped; use NewHTMLFlow to
+// continue it in other boxes or on new pages.
 func (gp *GoPdf) InsertHTMLBox(x, y, w, h float64, htmlStr string, opt HTMLBoxOption) (float64, error) {
 	if opt.DefaultFontSize <= 0 {
 		opt.DefaultFontSize = 12
@@ -88,9 +109,18 @@ func (gp *GoPdf) InsertHTMLBox(x, y, w, h float64, htmlStr string, opt HTMLBoxOp
 		return y, ErrMissingFontFamily
 	}
 
-	nodes := parseHTML(htmlStr)
+	nodes := applyStyleSheets(parseHTML(htmlStr), opt.CSS)
+
+	r := newHTMLRenderer(gp, opt, x, y, w, h)
+	if err := r.renderNodes(nodes, r.initialState()); err != nil {
+		return r.cursorY, err
+	}
+
+	return r.cursorY, nil
+}
 
-	r := &htmlRenderer{
+func newHTMLRenderer(gp *GoPdf, opt HTMLBoxOption, x, y, w, h float64) *htmlRenderer {
+	return &htmlRenderer{
 		gp:      gp,
 		opt:     opt,
 		boxX:    x,
@@ -100,32 +130,36 @@ func (gp *GoPdf) InsertHTMLBox(x, y, w, h float64, htmlStr string, opt HTMLBoxOp
 		cursorX: x,
 		cursorY: y,
 	}
+}
 
-	state := htmlRenderState{
-		fontFamily: opt.DefaultFontFamily,
-		fontSize:   opt.DefaultFontSize,
+func (r *htmlRenderer) initialState() htmlRenderState {
+	return htmlRenderState{
+		fontFamily: r.opt.DefaultFontFamily,
+		fontSize:   r.opt.DefaultFontSize,
 		fontStyle:  Regular,
-		colorR:     opt.DefaultColor[0],
-		colorG:     opt.DefaultColor[1],
-		colorB:     opt.DefaultColor[2],
+		colorR:     r.opt.DefaultColor[0],
+		colorG:     r.opt.DefaultColor[1],
+		colorB:     r.opt.DefaultColor[2],
 		align:      Left,
 	}
-
-	if err := r.renderNodes(nodes, state); err != nil {
-		return r.cursorY, err
-	}
-
-	return r.cursorY, nil
 }
 
 func (r *htmlRenderer) renderNodes(nodes []*htmlNode, state htmlRenderState) error {
-	for _, node := range nodes {
-		if r.cursorY-r.boxY >= r.boxH {
-			break // exceeded box height
+	for i, node := range nodes {
+		if r.cursorY-r.boxY >= r.boxH && !r.mustDraw() {
+			// exceeded box height
+			r.breakBefore(nodes[i:])
+			return nil
 		}
 		if err := r.renderNode(node, state); err != nil {
 			return err
 		}
+		if r.overflow {
+			rest := make([]*htmlNode, 0, len(r.rest)+len(nodes)-i-1)
+			rest = append(rest, r.rest...)
+			r.rest = append(rest, nodes[i+1:]...)
+			return nil
+		}
 	}
 	return nil
 }
@@ -134,8 +168,24 @@ func (r *htmlRenderer) renderNode(node *htmlNode, state htmlRenderState) error {
 	if node.Type == htmlNodeText {
 		return r.renderText(node.Text, state)
 	}
+	if r.pageBreakBefore(node, state) {
+		return nil
+	}
+	if err := r.renderElement(node, state); err != nil {
+		return err
+	}
+	if r.overflow {
+		if !r.restSelf && len(r.rest) > 0 {
+			r.rest = []*htmlNode{node.continuedWith(r.rest)}
+		}
+		r.restSelf = false
+		return nil
+	}
+	r.pageBreakAfter(node)
+	return nil
+}
 
-	// element node
+func (r *htmlRenderer) renderElement(node *htmlNode, state htmlRenderState) error {
 	newState := state
 
 	switch node.Tag {
@@ -146,8 +196,7 @@ func (r *htmlRenderer) renderNode(node *htmlNode, state htmlRenderState) error {
 	case "u", "ins":
 		newState.fontStyle |= Underline
 	case "s", "strike", "del":
-		// approximate strikethrough with underline
-		newState.fontStyle |= Underline
+		newState.fontStyle |= Strikethrough
 	case "br":
 		r.newLine(state)
 		return nil
@@ -155,34 +204,16 @@ func (r *htmlRenderer) renderNode(node *htmlNode, state htmlRenderState) error {
 		return r.renderHR(state)
 	case "p", "div":
 		newState = r.applyStyleAttr(node, newState)
-		if r.cursorX > r.boxX {
-			r.newLine(state)
-		}
-		r.addVerticalSpace(state.fontSize * 0.3)
-		if err := r.renderNodes(node.Children, newState); err != nil {
-			return err
-		}
-		if r.cursorX > r.boxX {
-			r.newLine(state)
-		}
-		r.addVerticalSpace(state.fontSize * 0.3)
-		return nil
+		return r.renderBlock(node, state, newState, r.blockDefaults(state.fontSize*0.3))
 	case "h1", "h2", "h3", "h4", "h5", "h6":
 		newState.fontSize = headingFontSize(node.Tag)
 		newState.fontStyle |= Bold
 		newState = r.applyStyleAttr(node, newState)
-		if r.cursorX > r.boxX {
-			r.newLine(state)
-		}
-		r.addVerticalSpace(newState.fontSize * 0.4)
-		if err := r.renderNodes(node.Children, newState); err != nil {
-			return err
-		}
-		if r.cursorX > r.boxX {
-			r.newLine(newState)
-		}
-		r.addVerticalSpace(newState.fontSize * 0.3)
-		return nil
+		defaults := r.blockDefaults(newState.fontSize * 0.3)
+		defaults.margin.top = newState.fontSize * 0.4 / r.unitConversion()
+		return r.renderBlock(node, state, newState, defaults)
+	case "table":
+		return r.renderTable(node, r.applyStyleAttr(node, newState))
 	case "font":
 		if color, ok := node.Attrs["color"]; ok {
 			if cr, cg, cb, cok := parseCSSColor(color); cok {
@@ -197,10 +228,9 @@ func (r *htmlRenderer) renderNode(node *htmlNode, state htmlRenderState) error {
 		if face, ok := node.Attrs["face"]; ok {
 			newState.fontFamily = face
 		}
-	case "span":
-		newState = r.applyStyleAttr(node, newState)
 	case "center":
 		newState.align = Center
+		newState = r.applyStyleAttr(node, newState)
 		if r.cursorX > r.boxX {
 			r.newLine(state)
 		}
@@ -215,6 +245,7 @@ func (r *htmlRenderer) renderNode(node *htmlNode, state htmlRenderState) error {
 		// render link text in blue with underline, then add PDF link annotation
 		newState.colorR, newState.colorG, newState.colorB = 0, 0, 255
 		newState.fontStyle |= Underline
+		newState = r.applyStyleAttr(node, newState)
 		href := node.Attrs["href"]
 
 		// record position before rendering link text
@@ -227,7 +258,7 @@ func (r *htmlRenderer) renderNode(node *htmlNode, state htmlRenderState) error {
 		}
 
 		// add clickable link annotation if href is present
-		if href != "" {
+		if href != "" && !r.measure {
 			endX := r.cursorX
 			// convert to points for the annotation
 			ax := r.gp.UnitsToPoints(startX)
@@ -242,39 +273,20 @@ func (r *htmlRenderer) renderNode(node *htmlNode, state htmlRenderState) error {
 	case "img":
 		return r.renderImage(node, state)
 	case "ul", "ol":
-		return r.renderList(node, state, node.Tag == "ol")
+		return r.renderList(node, r.applyStyleAttr(node, state), node.Tag == "ol")
 	case "li":
 		// handled by renderList
-	case "sub":
-		newState.fontSize = state.fontSize * 0.7
-	case "sup":
-		newState.fontSize = state.fontSize * 0.7
+	case "sub", "sup":
+		newState = r.scriptState(state, node.Tag == "sub")
 	case "blockquote":
 		newState = r.applyStyleAttr(node, newState)
-		if r.cursorX > r.boxX {
-			r.newLine(state)
-		}
-		oldBoxX := r.boxX
-		oldBoxW := r.boxW
-		indent := state.fontSize * 1.5
-		r.boxX += indent
-		r.boxW -= indent
-		r.cursorX = r.boxX
-		r.addVerticalSpace(state.fontSize * 0.3)
-		if err := r.renderNodes(node.Children, newState); err != nil {
-			return err
-		}
-		if r.cursorX > r.boxX {
-			r.newLine(newState)
-		}
-		r.addVerticalSpace(state.fontSize * 0.3)
-		r.boxX = oldBoxX
-		r.boxW = oldBoxW
-		r.cursorX = r.boxX
-		return nil
+		defaults := r.blockDefaults(state.fontSize * 0.3)
+		defaults.margin.left = state.fontSize * 1.5
+		return r.renderBlock(node, state, newState, defaults)
 	}
 
 	// render children with updated state
+	newState = r.applyStyleAttr(node, newState)
 	if err := r.renderNodes(node.Children, newState); err != nil {
 		return err
 	}
@@ -283,11 +295,7 @@ func (r *htmlRenderer) renderNode(node *htmlNode, state htmlRenderState) error {
 }
 
 func (r *htmlRenderer) applyStyleAttr(node *htmlNode, state htmlRenderState) htmlRenderState {
-	styleStr, ok := node.Attrs["style"]
-	if !ok {
-		return state
-	}
-	styles := parseInlineStyle(styleStr)
+	styles := r.nodeStyles(node)
 
 	if color, ok := styles["color"]; ok {
 		if cr, cg, cb, cok := parseCSSColor(color); cok {
@@ -316,6 +324,12 @@ func (r *htmlRenderer) applyStyleAttr(node *htmlNode, state htmlRenderState) htm
 		if strings.Contains(td, "underline") {
 			state.fontStyle |= Underline
 		}
+		if strings.Contains(td, "line-through") {
+			state.fontStyle |= Strikethrough
+		}
+		if strings.Contains(td, "overline") {
+			state.fontStyle |= Overline
+		}
 	}
 	if ta, ok := styles["text-align"]; ok {
 		switch ta {
@@ -332,10 +346,9 @@ func (r *htmlRenderer) applyStyleAttr(node *htmlNode, state htmlRenderState) htm
 
 func (r *htmlRenderer) applyFont(state htmlRenderState) error {
 	family := r.resolveFontFamily(state)
-	style := state.fontStyle &^ Underline // strip underline for font lookup
-	if err := r.gp.SetFontWithStyle(family, style, state.fontSize); err != nil {
+	if err := r.gp.SetFontWithStyle(family, state.fontStyle, state.fontSize); err != nil {
 		// fallback to default family
-		if err2 := r.gp.SetFontWithStyle(r.opt.DefaultFontFamily, Regular, state.fontSize); err2 != nil {
+		if err2 := r.gp.SetFontWithStyle(r.opt.DefaultFontFamily, state.fontStyle&decorations, state.fontSize); err2 != nil {
 			return err
 		}
 	}
@@ -343,6 +356,23 @@ func (r *htmlRenderer) applyFont(state htmlRenderState) error {
 	return nil
 }
 
+// scriptState returns state for the text of <sub>, or of <sup> when sub is
+// not set, in state: smaller and shifted from the baseline of state by the
+// subscript or superscript metrics of the font. Text is set at the top of
+// its line, so the shift makes up for the smaller ascender too.
+func (r *htmlRenderer) scriptState(state htmlRenderState, sub bool) htmlRenderState {
+	if err := r.applyFont(state); err != nil {
+		state.fontSize *= 0.7
+		return state
+	}
+	font := r.gp.curr.FontISubset
+	size, rise := font.scriptMetrics(state.fontSize, sub)
+	ascender := convertTypoUnit(float64(font.typoAscender()), font.unitsPerEm(), state.fontSize-size)
+	state.rise += rise - ascender
+	state.fontSize = size
+	return state
+}
+
 func (r *htmlRenderer) resolveFontFamily(state htmlRenderState) string {
 	isBold := state.fontStyle&Bold == Bold
 	isItalic := state.fontStyle&Italic == Italic
@@ -399,6 +429,9 @@ func (r *htmlRenderer) renderText(text string, state htmlRenderState) error {
 	if err := r.applyFont(state); err != nil {
 		return err
 	}
+	rise := r.gp.curr.textState.rise
+	r.gp.curr.textState.rise = state.rise
+	defer func() { r.gp.curr.textState.rise = rise }()
 
 	// collapse whitespace
 	text = collapseWhitespace(text)
@@ -406,18 +439,18 @@ func (r *htmlRenderer) renderText(text string, state htmlRenderState) error {
 		return nil
 	}
 
-	words := splitWords(text)
+	segs := lineBreakSegments(text)
 	lh := r.lineHeight(state)
 	spaceWidth, err := r.gp.MeasureTextWidth(" ")
 	if err != nil {
 		return err
 	}
 
-	for i, word := range words {
-		if r.cursorY-r.boxY+lh > r.boxH {
-			break // exceeded box height
+	for i, seg := range segs {
+		word := seg.text
+		if word == "" {
+			continue
 		}
-
 		wordWidth, err := r.gp.MeasureTextWidth(word)
 		if err != nil {
 			return err
@@ -425,9 +458,26 @@ func (r *htmlRenderer) renderText(text string, state htmlRenderState) error {
 
 		// check if word fits on current line
 		if r.cursorX > r.boxX && r.cursorX+wordWidth > r.boxX+r.boxW {
+			// a line broken at a soft hyphen shows a hyphen
+			if i > 0 && segs[i-1].soft && !r.measure {
+				hw, err := r.gp.MeasureTextWidth("-")
+				if err != nil {
+					return err
+				}
+				r.gp.SetXY(r.cursorX, r.cursorY)
+				if err := r.gp.CellWithOption(&Rect{W: hw, H: lh}, "-", CellOption{Align: Left | Top}); err != nil {
+					return err
+				}
+			}
 			r.newLine(state)
 		}
 
+		if r.cursorY-r.boxY+lh > r.boxH && !r.mustDraw() {
+			// exceeded box height
+			r.breakBefore([]*htmlNode{{Type: htmlNodeText, Text: joinLineSegments(segs[i:])}})
+			return nil
+		}
+
 		// if a single word is wider than the box, force-render it
 		if wordWidth > r.boxW {
 			// render character by character with wrapping
@@ -439,32 +489,35 @@ func (r *htmlRenderer) renderText(text string, state htmlRenderState) error {
 
 		// handle alignment for new lines
 		if r.cursorX == r.boxX && state.align == Center {
-			lineWidth := r.measureLineWidth(words[i:], spaceWidth, state)
+			lineWidth := r.measureLineWidth(segs[i:], spaceWidth, state)
 			if lineWidth < r.boxW {
 				r.cursorX = r.boxX + (r.boxW-lineWidth)/2
 			}
 		} else if r.cursorX == r.boxX && state.align == Right {
-			lineWidth := r.measureLineWidth(words[i:], spaceWidth, state)
+			lineWidth := r.measureLineWidth(segs[i:], spaceWidth, state)
 			if lineWidth < r.boxW {
 				r.cursorX = r.boxX + r.boxW - lineWidth
 			}
 		}
 
-		r.gp.SetXY(r.cursorX, r.cursorY)
+		if !r.measure {
+			r.gp.SetXY(r.cursorX, r.cursorY)
 
-		cellOpt := CellOption{
-			Align: Left | Top,
-		}
+			cellOpt := CellOption{
+				Align: Left | Top,
+			}
 
-		rect := &Rect{W: wordWidth, H: lh}
-		if err := r.gp.CellWithOption(rect, word, cellOpt); err != nil {
-			return err
+			rect := &Rect{W: wordWidth, H: lh}
+			if err := r.gp.CellWithOption(rect, word, cellOpt); err != nil {
+				return err
+			}
 		}
+		r.drawn = true
 
 		r.cursorX += wordWidth
 
 		// add space after word (except last)
-		if i < len(words)-1 {
+		if seg.spaces != "" && i < len(segs)-1 {
 			r.cursorX += spaceWidth
 		}
 	}
@@ -483,20 +536,22 @@ func (r *htmlRenderer) renderLongWord(word string, state htmlRenderState) error
 		if r.cursorX+chWidth > r.boxX+r.boxW && r.cursorX > r.boxX {
 			r.newLine(state)
 		}
-		r.gp.SetXY(r.cursorX, r.cursorY)
-		rect := &Rect{W: chWidth, H: lh}
-		if err := r.gp.CellWithOption(rect, s, CellOption{Align: Left | Top}); err != nil {
-			return err
+		if !r.measure {
+			r.gp.SetXY(r.cursorX, r.cursorY)
+			rect := &Rect{W: chWidth, H: lh}
+			if err := r.gp.CellWithOption(rect, s, CellOption{Align: Left | Top}); err != nil {
+				return err
+			}
 		}
 		r.cursorX += chWidth
 	}
 	return nil
 }
 
-func (r *htmlRenderer) measureLineWidth(words []string, spaceWidth float64, state htmlRenderState) float64 {
+func (r *htmlRenderer) measureLineWidth(segs []lineSegment, spaceWidth float64, state htmlRenderState) float64 {
 	total := 0.0
-	for i, word := range words {
-		ww, err := r.gp.MeasureTextWidth(word)
+	for i, seg := range segs {
+		ww, err := r.gp.MeasureTextWidth(seg.text)
 		if err != nil {
 			break
 		}
@@ -504,7 +559,7 @@ func (r *htmlRenderer) measureLineWidth(words []string, spaceWidth float64, stat
 			break
 		}
 		total += ww
-		if i < len(words)-1 {
+		if seg.spaces != "" && i < len(segs)-1 {
 			total += spaceWidth
 		}
 	}
@@ -518,9 +573,12 @@ func (r *htmlRenderer) renderHR(state htmlRenderState) error {
 	r.addVerticalSpace(state.fontSize * 0.3)
 
 	y := r.cursorY + r.lineHeight(state)*0.5
-	r.gp.SetStrokeColor(128, 128, 128)
-	r.gp.SetLineWidth(0.5)
-	r.gp.Line(r.boxX, y, r.boxX+r.boxW, y)
+	if !r.measure {
+		r.gp.SetStrokeColor(128, 128, 128)
+		r.gp.SetLineWidth(0.5)
+		r.gp.Line(r.boxX, y, r.boxX+r.boxW, y)
+		r.drawn = true
+	}
 
 	r.cursorY = y + r.lineHeight(state)*0.5
 	r.cursorX = r.boxX
@@ -571,19 +629,34 @@ func (r *htmlRenderer) renderImage(node *htmlNode, state htmlRenderState) error
 	}
 
 	// check if image fits vertically
-	if r.cursorY-r.boxY+imgH > r.boxH {
-		return nil // skip image if it doesn't fit
+	if avail := r.boxH - (r.cursorY - r.boxY); imgH > avail {
+		if !r.flow {
+			return nil // skip image if it doesn't fit
+		}
+		if !r.mustDraw() {
+			r.breakBefore([]*htmlNode{node})
+			r.restSelf = true
+			return nil
+		}
+		if avail > 0 {
+			// shrink to the frame when the image is taller than an empty frame
+			imgW *= avail / imgH
+			imgH = avail
+		}
 	}
 
-	imgHolder, err := ImageHolderByPath(src)
-	if err != nil {
-		return err
-	}
+	if !r.measure {
+		imgHolder, err := ImageHolderByPath(src)
+		if err != nil {
+			return err
+		}
 
-	rect := &Rect{W: imgW, H: imgH}
-	if err := r.gp.ImageByHolder(imgHolder, r.cursorX, r.cursorY, rect); err != nil {
-		return err
+		rect := &Rect{W: imgW, H: imgH}
+		if err := r.gp.ImageByHolder(imgHolder, r.cursorX, r.cursorY, rect); err != nil {
+			return err
+		}
 	}
+	r.drawn = true
 
 	r.cursorY += imgH
 	r.cursorX = r.boxX
@@ -594,19 +667,25 @@ func (r *htmlRenderer) renderList(node *htmlNode, state htmlRenderState, ordered
 	if r.cursorX > r.boxX {
 		r.newLine(state)
 	}
-	r.addVerticalSpace(state.fontSize * 0.2)
+	if !node.continued() {
+		r.addVerticalSpace(state.fontSize * 0.2)
+	}
 
 	indent := state.fontSize * 1.2 / r.unitConversion()
 	counter := 0
+	if start, err := strconv.Atoi(node.Attrs["start"]); err == nil {
+		counter = start - 1
+	}
 
-	for _, child := range node.Children {
+	for i, child := range node.Children {
 		if child.Type != htmlNodeElement || child.Tag != "li" {
 			continue
 		}
 		counter++
 
-		if r.cursorY-r.boxY+r.lineHeight(state) > r.boxH {
-			break
+		if r.cursorY-r.boxY+r.lineHeight(state) > r.boxH && !r.mustDraw() {
+			r.breakList(node, node.Children[i:], counter)
+			return nil
 		}
 
 		// render bullet or number
@@ -627,10 +706,13 @@ func (r *htmlRenderer) renderList(node *htmlNode, state htmlRenderState, ordered
 			marker = "• "
 		}
 
-		markerWidth, _ := r.gp.MeasureTextWidth(marker)
-		rect := &Rect{W: markerWidth, H: r.lineHeight(state)}
-		if err := r.gp.CellWithOption(rect, marker, CellOption{Align: Left | Top}); err != nil {
-			return err
+		if !r.measure && !child.continued() {
+			markerWidth, _ := r.gp.MeasureTextWidth(marker)
+			rect := &Rect{W: markerWidth, H: r.lineHeight(state)}
+			if err := r.gp.CellWithOption(rect, marker, CellOption{Align: Left | Top}); err != nil {
+				return err
+			}
+			r.drawn = true
 		}
 
 		// render list item content with indent
@@ -645,13 +727,22 @@ func (r *htmlRenderer) renderList(node *htmlNode, state htmlRenderState, ordered
 			return err
 		}
 
-		if r.cursorX > r.boxX {
+		if r.cursorX > r.boxX && !r.overflow {
 			r.newLine(state)
 		}
 
 		r.boxX = oldBoxX
 		r.boxW = oldBoxW
 		r.cursorX = r.boxX
+		if r.overflow {
+			if len(r.rest) == 0 {
+				r.breakList(node, node.Children[i+1:], counter+1)
+			} else {
+				items := []*htmlNode{child.continuedWith(r.rest)}
+				r.breakList(node, append(items, node.Children[i+1:]...), counter)
+			}
+			return nil
+		}
 	}
 
 	r.addVerticalSpace(state.fontSize * 0.2)
@@ -676,7 +767,17 @@ func collapseWhitespace(s string) string {
 	return strings.TrimSpace(result.String())
 }
 
-// splitWords splits text into words by spaces.
-func splitWords(text string) []string {
-	return strings.Fields(text)
+// joinLineSegments joins line segments back into text.
+func joinLineSegments(segs []lineSegment) string {
+	var sb strings.Builder
+	for _, seg := range segs {
+		sb.WriteString(seg.text)
+		if seg.soft {
+			sb.WriteRune(0xAD)
+		}
+		if seg.spaces != "" {
+			sb.WriteByte(' ')
+		}
+	}
+	return strings.TrimSpace(sb.String())
 }
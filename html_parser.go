@@ -22,6 +22,9 @@ type htmlNode struct {
 	Attrs      map[string]string
 	Children   []*htmlNode
 	SelfClose  bool // e.g. <br/>, <img ... />
+
+	css   map[string]string // cascaded declarations, set by applyStyleSheets
+	split bool              // remainder of an element split across frames, see html_flow.go
 }
 
 // parseHTML parses a simplified HTML string into a tree of htmlNode.
@@ -453,7 +456,8 @@ func headingFontSize(tag string) float64 {
 // isBlockElement returns true if the tag is a block-level element.
 func isBlockElement(tag string) bool {
 	switch tag {
-	case "p", "div", "h1", "h2", "h3", "h4", "h5", "h6", "ul", "ol", "li", "hr", "center", "blockquote":
+	case "p", "div", "h1", "h2", "h3", "h4", "h5", "h6", "ul", "ol", "li", "hr", "center", "blockquote",
+		"table", "thead", "tbody", "tfoot", "tr", "td", "th":
 		return true
 	}
 	return false
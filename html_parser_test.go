@@ -212,3 +212,59 @@ func TestIsBlockElement(t *testing.T) {
 		}
 	}
 }
+
+func TestParseCSSSelector_Specificity(t *testing.T) {
+	tests := []struct {
+		sel  string
+		spec int
+	}{
+		{"p", 1},
+		{"div > p.note", 1<<8 | 2},
+		{"#main .a.b span", 1<<16 | 2<<8 | 1},
+		{"*", 0},
+	}
+	for _, tt := range tests {
+		sel, ok := parseCSSSelector(tt.sel)
+		if !ok {
+			t.Fatalf("parseCSSSelector(%q) failed", tt.sel)
+		}
+		if got := sel.specificity(); got != tt.spec {
+			t.Errorf("specificity(%q) = %x, want %x", tt.sel, got, tt.spec)
+		}
+	}
+	for _, bad := range []string{"a:hover", "input[type=text]", "h1 + p", "> p", "p >"} {
+		if _, ok := parseCSSSelector(bad); ok {
+			t.Errorf("expected %q to be rejected", bad)
+		}
+	}
+}
+
+func TestApplyStyleSheets_Cascade(t *testing.T) {
+	html := `<style>
+  /* comment */
+  p { color: red; font-size: 10pt }
+  .note { color: green }
+  div > p { margin: 4pt }
+  @media print { p { color: black } }
+  #intro { color: blue !important }
+  section p { font-size: 11pt }
+</style><div><p id="intro" class="note" style="color: gray">a</p><section><p class="note">b</p></section></div>`
+	nodes := applyStyleSheets(parseHTML(html), "p { font-family: Serif; color: purple }")
+	if len(nodes) != 1 || nodes[0].Tag != "div" {
+		t.Fatalf("style element not removed: %s", debugHTMLTree(nodes, 0))
+	}
+	intro := nodes[0].Children[0]
+	if got := intro.css["color"]; got != "blue" {
+		t.Errorf("intro color = %q, want blue (!important beats inline)", got)
+	}
+	if intro.css["margin"] != "4pt" || intro.css["font-family"] != "Serif" || intro.css["font-size"] != "10pt" {
+		t.Errorf("unexpected intro styles %v", intro.css)
+	}
+	nested := nodes[0].Children[1].Children[0]
+	if nested.css["color"] != "green" || nested.css["font-size"] != "11pt" {
+		t.Errorf("unexpected nested styles %v", nested.css)
+	}
+	if _, ok := nested.css["margin"]; ok {
+		t.Error("child selector must not match a grandchild")
+	}
+}
@@ -0,0 +1,253 @@
+package gopdf
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"unicode"
+)
+
+// ============================================================
+// Hyphenation with TeX (Liang) patterns
+// ============================================================
+
+// ErrNoHyphenationPatterns is returned when a pattern file holds no
+// patterns.
+var ErrNoHyphenationPatterns = errors.New("no hyphenation patterns")
+
+// Hyphenator finds hyphenation points in words with the patterns of
+// Frank Liang's algorithm used by TeX. Load one per language with
+// LoadHyphenator and set it on BreakOption.
+type Hyphenator struct {
+	// Language is the language of the patterns, for example "en-us".
+	Language string
+
+	patterns   map[string][]int // letters -> inter-letter values
+	maxLen     int              // longest pattern, in letters
+	exceptions map[string][]int // word -> hyphenation points
+}
+
+// LoadHyphenator reads TeX hyphenation patterns for a language. The input
+// is either a TeX file with \patterns{...} and optional \hyphenation{...}
+// exceptions, such as hyph-en-us.tex, or plain whitespace separated
+// patterns, such as hyph-en-us.pat.txt. Comments start with %.
+//
+// Example:
+//
+//	f, _ := os.Open("hyph-en-us.tex")
+//	h, err := gopdf.LoadHyphenator("en-us", f)
+//	lines, err := pdf.SplitTextWithOption(text, 120, &gopdf.BreakOption{
+//	    Mode:       gopdf.BreakModeUnicode,
+//	    Hyphenator: h,
+//	})
+func LoadHyphenator(language string, r io.Reader) (*Hyphenator, error) {
+	var sb strings.Builder
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if i := strings.IndexByte(line, '%'); i >= 0 {
+			line = line[:i]
+		}
+		sb.WriteString(line)
+		sb.WriteByte('\n')
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	text := sb.String()
+
+	h := &Hyphenator{
+		Language:   language,
+		patterns:   make(map[string][]int),
+		exceptions: make(map[string][]int),
+	}
+	patterns, ok := texGroup(text, `\patterns`)
+	if !ok {
+		patterns = text
+	}
+	for _, p := range strings.Fields(patterns) {
+		h.addPattern(p)
+	}
+	if exceptions, ok := texGroup(text, `\hyphenation`); ok {
+		for _, e := range strings.Fields(exceptions) {
+			h.AddException(e)
+		}
+	}
+	if len(h.patterns) == 0 {
+		return nil, ErrNoHyphenationPatterns
+	}
+	return h, nil
+}
+
+// LoadHyphenatorFile reads TeX hyphenation patterns from a file. See
+// LoadHyphenator.
+func LoadHyphenatorFile(language, path string) (*Hyphenator, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return LoadHyphenator(language, f)
+}
+
+// texGroup returns the contents of the braces after the TeX command cmd.
+func texGroup(text, cmd string) (string, bool) {
+	i := strings.Index(text, cmd)
+	if i < 0 {
+		return "", false
+	}
+	text = text[i+len(cmd):]
+	open := strings.IndexByte(text, '{')
+	if open < 0 {
+		return "", false
+	}
+	end := strings.IndexByte(text[open:], '}')
+	if end < 0 {
+		return text[open+1:], true
+	}
+	return text[open+1 : open+end], true
+}
+
+// addPattern adds a pattern such as ".ach4" or "4b1c".
+func (h *Hyphenator) addPattern(p string) {
+	var letters []rune
+	values := []int{0}
+	for _, r := range strings.ToLower(p) {
+		if r >= '0' && r <= '9' {
+			values[len(values)-1] = int(r - '0')
+			continue
+		}
+		letters = append(letters, r)
+		values = append(values, 0)
+	}
+	if len(letters) == 0 {
+		return
+	}
+	h.patterns[string(letters)] = values
+	if len(letters) > h.maxLen {
+		h.maxLen = len(letters)
+	}
+}
+
+// AddException adds a word with its hyphenation points marked by hyphens,
+// such as "ta-ble", which overrides the patterns.
+func (h *Hyphenator) AddException(word string) {
+	var points []int
+	n := 0
+	for _, r := range strings.ToLower(word) {
+		if r == '-' {
+			points = append(points, n)
+			continue
+		}
+		n++
+	}
+	h.exceptions[strings.ReplaceAll(strings.ToLower(word), "-", "")] = points
+}
+
+// Hyphenate splits word at its hyphenation points, keeping at least
+// minLeft letters before and minRight letters after each point. Values
+// below 1 default to 2 and 3.
+func (h *Hyphenator) Hyphenate(word string, minLeft, minRight int) []string {
+	runes := []rune(word)
+	var parts []string
+	start := 0
+	for _, p := range h.points(runes, minLeft, minRight) {
+		parts = append(parts, string(runes[start:p]))
+		start = p
+	}
+	return append(parts, string(runes[start:]))
+}
+
+// points returns the hyphenation points of word as rune offsets, in
+// ascending order. Only runs of letters are hyphenated, so punctuation
+// and hard hyphens are kept with the letters next to them.
+func (h *Hyphenator) points(word []rune, minLeft, minRight int) []int {
+	if minLeft < 1 {
+		minLeft = 2
+	}
+	if minRight < 1 {
+		minRight = 3
+	}
+	var points []int
+	for start := 0; start < len(word); {
+		if !unicode.IsLetter(word[start]) {
+			start++
+			continue
+		}
+		end := start
+		for end < len(word) && unicode.IsLetter(word[end]) {
+			end++
+		}
+		for _, p := range h.letterPoints(word[start:end]) {
+			if p >= minLeft && p <= end-start-minRight {
+				points = append(points, start+p)
+			}
+		}
+		start = end
+	}
+	return points
+}
+
+// letterPoints returns the hyphenation points of a word of letters.
+func (h *Hyphenator) letterPoints(word []rune) []int {
+	lower := []rune(strings.ToLower(string(word)))
+	if points, ok := h.exceptions[string(lower)]; ok {
+		return points
+	}
+	w := make([]rune, 0, len(lower)+2)
+	w = append(append(append(w, '.'), lower...), '.')
+	values := make([]int, len(w)+1)
+	for i := range w {
+		for j := i + 1; j <= len(w) && j-i <= h.maxLen; j++ {
+			pattern, ok := h.patterns[string(w[i:j])]
+			if !ok {
+				continue
+			}
+			for k, v := range pattern {
+				if v > values[i+k] {
+					values[i+k] = v
+				}
+			}
+		}
+	}
+	// values[k] lies between w[k-1] and w[k]; the point before word[p]
+	// is values[p+1].
+	var points []int
+	for p := 1; p < len(lower); p++ {
+		if values[p+1]%2 == 1 {
+			points = append(points, p)
+		}
+	}
+	return points
+}
+
+// hyphen returns the character appended to hyphenated lines.
+func (bo BreakOption) hyphen() rune {
+	if bo.Hyphen != 0 {
+		return bo.Hyphen
+	}
+	return '-'
+}
+
+// hyphenateToFit returns the longest head of word before a hyphenation
+// point that fits on the line after prefix with a hyphen, and the rest of
+// word. head is empty if no hyphenation point fits.
+func (gp *GoPdf) hyphenateToFit(prefix, word string, width float64, opt *BreakOption) (head, tail string, err error) {
+	runes := []rune(word)
+	points := opt.Hyphenator.points(runes, opt.HyphenMinLeft, opt.HyphenMinRight)
+	hyphen := string(opt.hyphen())
+	for i := len(points) - 1; i >= 0; i-- {
+		head := string(runes[:points[i]])
+		w, err := gp.MeasureTextWidth(prefix + head + hyphen)
+		if err != nil {
+			return "", word, err
+		}
+		if w <= width {
+			return head, string(runes[points[i]:]), nil
+		}
+	}
+	return "", word, nil
+}
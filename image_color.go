@@ -0,0 +1,203 @@
+package gopdf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"fmt"
+	"image"
+	"image/png"
+	"io"
+	"strings"
+)
+
+// ICCProfileObj is the ICC profile stream of an ICCBased color space of an
+// image.
+type ICCProfileObj struct {
+	data    []byte // zlib compressed profile
+	n       int    // number of color components
+	getRoot func() *GoPdf
+}
+
+func (i *ICCProfileObj) init(funcGetRoot func() *GoPdf) {
+	i.getRoot = funcGetRoot
+}
+
+func (i *ICCProfileObj) protection() *PDFProtection {
+	return i.getRoot().protection()
+}
+
+func (i *ICCProfileObj) getType() string {
+	return "ICCProfile"
+}
+
+func (i *ICCProfileObj) write(w io.Writer, objID int) error {
+	alternate := map[int]string{1: "DeviceGray", 3: "DeviceRGB", 4: "DeviceCMYK"}[i.n]
+	fmt.Fprintf(w, "<<\n/N %d\n/Alternate /%s\n/Filter /FlateDecode\n/Length %d\n>>\n", i.n, alternate, len(i.data))
+	io.WriteString(w, "stream\n")
+	if i.protection() != nil {
+		tmp, err := rc4Cip(i.protection().objectkey(objID), i.data)
+		if err != nil {
+			return err
+		}
+		w.Write(tmp)
+		io.WriteString(w, "\n")
+	} else {
+		w.Write(i.data)
+	}
+	io.WriteString(w, "\nendstream\n")
+	return nil
+}
+
+// imgColorSpace returns the color space of the samples of imginfo: the ICC
+// profile or calibrated color space of imginfo, or else colorSpace.
+func imgColorSpace(imginfo imgInfo, colorSpace string) string {
+	if imginfo.icc != nil {
+		return fmt.Sprintf("[/ICCBased %d 0 R]", imginfo.iccObjID+1)
+	}
+	if imginfo.calColorSpace != "" {
+		return imginfo.calColorSpace
+	}
+	return "/" + colorSpace
+}
+
+// iccComponents returns the number of color components of the samples, or
+// of the palette entries, of an image in colspace.
+func iccComponents(colspace string) int {
+	switch colspace {
+	case "DeviceGray":
+		return 1
+	case "DeviceCMYK":
+		return 4
+	}
+	return 3
+}
+
+// iccProfileMatches reports whether the zlib compressed ICC profile is for
+// the color components of colspace, by the color space of its header.
+func iccProfileMatches(profile []byte, colspace string) bool {
+	zr, err := zlib.NewReader(bytes.NewReader(profile))
+	if err != nil {
+		return false
+	}
+	defer zr.Close()
+	header := make([]byte, 20)
+	if _, err := io.ReadFull(zr, header); err != nil {
+		return false
+	}
+	spaces := map[string]int{"GRAY": 1, "RGB ": 3, "CMYK": 4}
+	return spaces[string(header[16:20])] == iccComponents(colspace)
+}
+
+// pngColorProfile is the color space of the samples of a PNG, from its
+// iCCP, sRGB, gAMA and cHRM chunks.
+type pngColorProfile struct {
+	icc   []byte    // zlib compressed profile of iCCP
+	srgb  bool      // sRGB
+	gamma float64   // decoding gamma of gAMA, 0 if none
+	chrm  []float64 // white point and red, green and blue chromaticities of cHRM
+}
+
+func (p *pngColorProfile) isChunk(typ string) bool {
+	return typ == "iCCP" || typ == "sRGB" || typ == "gAMA" || typ == "cHRM"
+}
+
+func (p *pngColorProfile) read(typ string, chunk []byte) {
+	switch typ {
+	case "iCCP": // name, 0, compression method 0, zlib stream
+		if i := bytes.IndexByte(chunk, 0); i >= 0 && i+2 < len(chunk) && chunk[i+1] == 0 {
+			p.icc = chunk[i+2:]
+		}
+	case "sRGB":
+		p.srgb = true
+	case "gAMA":
+		if len(chunk) == 4 && binary.BigEndian.Uint32(chunk) != 0 {
+			p.gamma = 100000 / float64(binary.BigEndian.Uint32(chunk))
+		}
+	case "cHRM":
+		if len(chunk) == 32 {
+			p.chrm = make([]float64, 8)
+			for i := range p.chrm {
+				p.chrm[i] = float64(binary.BigEndian.Uint32(chunk[4*i:])) / 100000
+			}
+		}
+	}
+}
+
+// apply sets the ICC profile or the calibrated color space of info. As in
+// the PNG specification, an ICC profile takes precedence over sRGB, which
+// is how viewers show DeviceRGB, and sRGB over gAMA and cHRM.
+func (p *pngColorProfile) apply(info *imgInfo) {
+	if p.icc != nil && iccProfileMatches(p.icc, info.colspace) {
+		info.icc = p.icc
+		return
+	}
+	if p.srgb || (p.gamma == 0 && p.chrm == nil) {
+		return
+	}
+	info.calColorSpace = calColorSpace(info.colspace, p.gamma, p.chrm)
+}
+
+// calColorSpace returns the CalGray or CalRGB color space of samples in
+// colspace with gamma and the chromaticities chrm of a PNG. Missing values
+// are those of sRGB: a gamma of 2.2, the D65 white point and the primaries
+// of ITU-R BT.709.
+func calColorSpace(colspace string, gamma float64, chrm []float64) string {
+	if gamma == 0 {
+		gamma = 2.2
+	}
+	if chrm == nil {
+		chrm = []float64{0.3127, 0.3290, 0.64, 0.33, 0.30, 0.60, 0.15, 0.06}
+	}
+	xyz := func(x, y float64) [3]float64 {
+		return [3]float64{x / y, 1, (1 - x - y) / y}
+	}
+	white := xyz(chrm[0], chrm[1])
+	whitePoint := fmt.Sprintf("/WhitePoint [%.4f 1 %.4f]", white[0], white[2])
+	switch colspace {
+	case "DeviceGray":
+		return fmt.Sprintf("[/CalGray << %s /Gamma %.4f >>]", whitePoint, gamma)
+	case "DeviceRGB", "Indexed":
+	default:
+		return ""
+	}
+
+	// scale the primaries so that they add up to the white point
+	r, g, b := xyz(chrm[2], chrm[3]), xyz(chrm[4], chrm[5]), xyz(chrm[6], chrm[7])
+	det := func(a, b, c [3]float64) float64 {
+		return a[0]*(b[1]*c[2]-b[2]*c[1]) - b[0]*(a[1]*c[2]-a[2]*c[1]) + c[0]*(a[1]*b[2]-a[2]*b[1])
+	}
+	d := det(r, g, b)
+	if d == 0 {
+		return ""
+	}
+	scales := []float64{det(white, g, b) / d, det(r, white, b) / d, det(r, g, white) / d}
+	matrix := make([]string, 0, 9)
+	for i, primary := range [][3]float64{r, g, b} {
+		for _, v := range primary {
+			matrix = append(matrix, fmt.Sprintf("%.4f", v*scales[i]))
+		}
+	}
+	return fmt.Sprintf("[/CalRGB << %s /Gamma [%.4f %.4f %.4f] /Matrix [%s] >>]", whitePoint, gamma, gamma, gamma, strings.Join(matrix, " "))
+}
+
+// parseInterlacedPng parses the interlaced PNG f into info re-encoded
+// without interlacing, at the same bit depth.
+func parseInterlacedPng(f *bytes.Reader, info *imgInfo) error {
+	f.Seek(0, 0)
+	img, err := png.Decode(f)
+	if err != nil {
+		return err
+	}
+	*info, err = parseDecodedImg(img)
+	return err
+}
+
+// parseDecodedImg parses img encoded as PNG, losslessly.
+func parseDecodedImg(img image.Image) (imgInfo, error) {
+	pngBuf := new(bytes.Buffer)
+	if err := png.Encode(pngBuf, img); err != nil {
+		return imgInfo{}, err
+	}
+	return parseImg(bytes.NewReader(pngBuf.Bytes()))
+}
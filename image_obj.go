@@ -21,6 +21,7 @@ type ImageObj struct {
 	IsMask        bool
 	SplittedMask  bool
 	rawImgReader  *bytes.Reader
+	page          int // page of a multi-page image
 	imginfo       imgInfo
 	pdfProtection *PDFProtection
 	//getRoot func() *GoPdf
@@ -99,13 +100,24 @@ func (i *ImageObj) createSMask() (*SMask, error) {
 	smk.w = i.imginfo.w
 	smk.h = i.imginfo.h
 	smk.colspace = "DeviceGray"
-	smk.bitsPerComponent = "8"
+	smk.bitsPerComponent = i.imginfo.bitsPerComponent
 	smk.filter = i.imginfo.filter
 	smk.data = i.imginfo.smask
-	smk.decodeParms = fmt.Sprintf("/Predictor 15 /Colors 1 /BitsPerComponent 8 /Columns %d", i.imginfo.w)
+	smk.decodeParms = fmt.Sprintf("/Predictor 15 /Colors 1 /BitsPerComponent %s /Columns %d", i.imginfo.bitsPerComponent, i.imginfo.w)
 	return &smk, nil
 }
 
+func (i *ImageObj) haveICCProfile() bool {
+	return i.imginfo.icc != nil
+}
+
+func (i *ImageObj) createICCProfile() (*ICCProfileObj, error) {
+	var icc ICCProfileObj
+	icc.data = i.imginfo.icc
+	icc.n = iccComponents(i.imginfo.colspace)
+	return &icc, nil
+}
+
 func (i *ImageObj) createDeviceRGB() (*DeviceRGBObj, error) {
 	var dRGB DeviceRGBObj
 	dRGB.data = i.imginfo.pal
@@ -140,6 +152,9 @@ func (i *ImageObj) SetImage(r io.Reader) error {
 		return err
 	}
 	i.rawImgReader = bytes.NewReader(data)
+	if p, ok := r.(*imagePage); ok {
+		i.page = p.page
+	}
 
 	return nil
 }
@@ -161,7 +176,15 @@ func (i *ImageObj) GetRect() *Rect {
 func (i *ImageObj) getRect() (*Rect, error) {
 
 	i.rawImgReader.Seek(0, 0)
-	m, _, err := image.Decode(i.rawImgReader)
+	var r io.Reader = i.rawImgReader
+	if i.page != 0 {
+		data, err := tiffPage(i.rawImgReader, i.page)
+		if err != nil {
+			return nil, err
+		}
+		r = bytes.NewReader(data)
+	}
+	m, _, err := image.Decode(r)
 	if err != nil {
 		return nil, err
 	}
@@ -193,7 +216,7 @@ func (i *ImageObj) getRect() (*Rect, error) {
 func (i *ImageObj) parse() error {
 
 	i.rawImgReader.Seek(0, 0)
-	imginfo, err := parseImg(i.rawImgReader)
+	imginfo, err := parseImgPage(i.rawImgReader, i.page)
 	if err != nil {
 		return err
 	}
@@ -9,7 +9,6 @@ import (
 	"image"
 	"image/color"
 	_ "image/gif"
-	"image/png"
 	"io"
 	"os"
 	"strings"
@@ -22,14 +21,16 @@ const (
 )
 
 func writeMaskImgProps(w io.Writer, imginfo imgInfo) error {
-	if err := writeBaseImgProps(w, imginfo, DeviceGray); err != nil {
+	mask := imginfo // the alpha samples, which have no color
+	mask.icc, mask.calColorSpace, mask.decode = nil, "", ""
+	if err := writeBaseImgProps(w, mask, DeviceGray); err != nil {
 		return err
 	}
 
 	decode := "\t/DecodeParms <<\n"
 	decode += "\t\t/Predictor 15\n"
 	decode += "\t\t/Colors 1\n"
-	decode += "\t\t/BitsPerComponent 8\n"
+	decode += fmt.Sprintf("\t\t/BitsPerComponent %s\n", imginfo.bitsPerComponent)
 	decode += fmt.Sprintf("\t\t/Columns %d\n", imginfo.w)
 	decode += "\t>>\n"
 
@@ -91,12 +92,12 @@ func writeBaseImgProps(w io.Writer, imginfo imgInfo, colorSpace string) error {
 
 	if isColspaceIndexed(imginfo) {
 		size := len(imginfo.pal)/3 - 1
-		content += fmt.Sprintf("\t/ColorSpace [/Indexed /DeviceRGB %d %d 0 R]\n", size, imginfo.deviceRGBObjID+1)
+		content += fmt.Sprintf("\t/ColorSpace [/Indexed %s %d %d 0 R]\n", imgColorSpace(imginfo, "DeviceRGB"), size, imginfo.deviceRGBObjID+1)
 	} else {
-		content += fmt.Sprintf("\t/ColorSpace /%s\n", colorSpace)
-		if imginfo.colspace == "DeviceCMYK" {
-			content += "\t/Decode [1 0 1 0 1 0 1 0]\n"
-		}
+		content += fmt.Sprintf("\t/ColorSpace %s\n", imgColorSpace(imginfo, colorSpace))
+	}
+	if imginfo.decode != "" {
+		content += fmt.Sprintf("\t/Decode %s\n", imginfo.decode)
 	}
 
 	content += fmt.Sprintf("\t/BitsPerComponent %s\n", imginfo.bitsPerComponent)
@@ -135,6 +136,12 @@ func parseImgByPath(path string) (imgInfo, error) {
 }
 
 func parseImg(raw *bytes.Reader) (imgInfo, error) {
+	return parseImgPage(raw, 0)
+}
+
+// parseImgPage parses page of a multi-page image, a TIFF, or else the
+// image, page 0.
+func parseImgPage(raw *bytes.Reader, page int) (imgInfo, error) {
 	// fmt.Printf("----------\n")
 	var info imgInfo
 	raw.Seek(0, 0)
@@ -161,7 +168,12 @@ func parseImg(raw *bytes.Reader) (imgInfo, error) {
 		if err != nil {
 			return info, err
 		}
-	} else if formatname == "gif" {
+	} else if formatname == "tiff" {
+		err = parseTIFF(raw, &info, page)
+		if err != nil {
+			return info, err
+		}
+	} else if formatname == "gif" || formatname == "bmp" || formatname == "webp" {
 		// Convert to png
 		raw.Seek(0, 0)
 		var img image.Image
@@ -169,18 +181,16 @@ func parseImg(raw *bytes.Reader) (imgInfo, error) {
 		if err != nil {
 			return info, err
 		}
-		pngBuf := new(bytes.Buffer)
-		err = png.Encode(pngBuf, img)
-		if err != nil {
-			return info, err
-		}
-		info, err = parseImg(bytes.NewReader(pngBuf.Bytes()))
+		info, err = parseDecodedImg(img)
 		if err != nil {
 			return info, err
 		}
 	} else {
 		return info, fmt.Errorf("Image format %v is not supported", formatname)
 	}
+	if page != 0 && formatname != "tiff" {
+		return info, fmt.Errorf("%w: page %d of a %s image", ErrImagePageNotFound, page, formatname)
+	}
 
 	// fmt.Printf("%#v\n", info)
 
@@ -195,6 +205,7 @@ func parseImgJpg(info *imgInfo, imgConfig image.Config) error {
 		info.colspace = "DeviceGray"
 	case color.CMYKModel:
 		info.colspace = "DeviceCMYK"
+		info.decode = "[1 0 1 0 1 0 1 0]" // Adobe CMYK JPEGs are inverted
 	default:
 		return errors.New("color model not support")
 	}
@@ -245,8 +256,8 @@ func parsePng(f *bytes.Reader, info *imgInfo, imgConfig image.Config) error {
 		return err
 	}
 
-	if bpc[0] > 8 {
-		return errors.New("16-bit depth not supported")
+	if bpc[0] > 16 {
+		return errors.New("Unknown bit depth")
 	}
 
 	ct, err := readBytes(f, 1)
@@ -286,9 +297,6 @@ func parsePng(f *bytes.Reader, info *imgInfo, imgConfig image.Config) error {
 	if err != nil {
 		return err
 	}
-	if interlacing[0] != 0 {
-		return errors.New("Interlacing not supported")
-	}
 
 	_, err = f.Seek(4, 1) // skip
 	if err != nil {
@@ -298,8 +306,9 @@ func parsePng(f *bytes.Reader, info *imgInfo, imgConfig image.Config) error {
 	// decodeParms := "/Predictor 15 /Colors '.($colspace=='DeviceRGB' ? 3 : 1).' /BitsPerComponent '.$bpc.' /Columns '.$w;
 
 	var pal []byte
-	var trns []byte
+	var trns []int
 	var data []byte
+	var profile pngColorProfile
 	for {
 		un, err := readUInt(f)
 		if err != nil {
@@ -329,14 +338,14 @@ func parsePng(f *bytes.Reader, info *imgInfo, imgConfig image.Config) error {
 				return err
 			}
 
-			if ct[0] == 0 {
-				trns = []byte{(t[1])}
-			} else if ct[0] == 2 {
-				trns = []byte{t[1], t[3], t[5]}
+			if ct[0] == 0 && len(t) >= 2 {
+				trns = []int{int(binary.BigEndian.Uint16(t))}
+			} else if ct[0] == 2 && len(t) >= 6 {
+				trns = []int{int(binary.BigEndian.Uint16(t)), int(binary.BigEndian.Uint16(t[2:])), int(binary.BigEndian.Uint16(t[4:]))}
 			} else {
 				pos := strings.Index(string(t), "\x00")
 				if pos >= 0 {
-					trns = []byte{byte(pos)}
+					trns = []int{pos}
 				}
 			}
 
@@ -359,6 +368,17 @@ func parsePng(f *bytes.Reader, info *imgInfo, imgConfig image.Config) error {
 			}
 		} else if string(typ) == "IEND" {
 			break
+		} else if profile.isChunk(string(typ)) {
+			var chunk []byte
+			chunk, err = readBytes(f, n)
+			if err != nil {
+				return err
+			}
+			profile.read(string(typ), chunk)
+			_, err = f.Seek(int64(4), 1) // skip
+			if err != nil {
+				return err
+			}
 		} else {
 			_, err = f.Seek(int64(n+4), 1) // skip
 			if err != nil {
@@ -381,6 +401,16 @@ func parsePng(f *bytes.Reader, info *imgInfo, imgConfig image.Config) error {
 		return errors.New("Missing palette")
 	}
 
+	if interlacing[0] != 0 {
+		// PDF predictors cannot undo Adam7 interlacing, so re-encode the
+		// image without it
+		if err := parseInterlacedPng(f, info); err != nil {
+			return err
+		}
+		profile.apply(info)
+		return nil
+	}
+
 	info.w = w
 	info.h = h
 	info.colspace = colspace
@@ -406,48 +436,32 @@ func parsePng(f *bytes.Reader, info *imgInfo, imgConfig image.Config) error {
 			return err
 		}
 
+		// the filters of PNG predict each byte from the same byte of the
+		// pixels around it, so the color and alpha of filtered rows can be
+		// split and still decoded by Predictor 15
+		sampleBytes := int(bpc[0]) / 8
+		colorBytes := colors * sampleBytes
+		pixelBytes := colorBytes + sampleBytes
+		length := pixelBytes * w
+		if len(afterZipData) < (1+length)*h {
+			return errors.New("Truncated PNG image data")
+		}
 		var color []byte
 		var alpha []byte
-		if ct[0] == 4 {
-			// Gray image
-			length := 2 * w
-			i := 0
-			for i < h {
-				pos := (1 + length) * i
-				color = append(color, afterZipData[pos])
-				alpha = append(alpha, afterZipData[pos])
-				line := afterZipData[pos+1 : pos+length+1]
-				j := 0
-				max := len(line)
-				for j < max {
-					color = append(color, line[j])
-					j++
-					alpha = append(alpha, line[j])
-					j++
-				}
-				i++
-			}
-			// fmt.Print("aaaaa")
-
-		} else {
-			// RGB image
-			length := 4 * w
-			i := 0
-			for i < h {
-				pos := (1 + length) * i
-				color = append(color, afterZipData[pos])
-				alpha = append(alpha, afterZipData[pos])
-				line := afterZipData[pos+1 : pos+length+1]
-				j := 0
-				max := len(line)
-				for j < max {
-					color = append(color, line[j:j+3]...)
-					alpha = append(alpha, line[j+3])
-					j = j + 4
-				}
-
-				i++
+		i := 0
+		for i < h {
+			pos := (1 + length) * i
+			color = append(color, afterZipData[pos])
+			alpha = append(alpha, afterZipData[pos])
+			line := afterZipData[pos+1 : pos+length+1]
+			j := 0
+			max := len(line)
+			for j < max {
+				color = append(color, line[j:j+colorBytes]...)
+				alpha = append(alpha, line[j+colorBytes:j+pixelBytes]...)
+				j = j + pixelBytes
 			}
+			i++
 		}
 
 		info.smask, err = compress(alpha)
@@ -463,6 +477,7 @@ func parsePng(f *bytes.Reader, info *imgInfo, imgConfig image.Config) error {
 	} else {
 		info.data = data
 	}
+	profile.apply(info)
 
 	return nil
 }
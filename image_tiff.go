@@ -0,0 +1,405 @@
+package gopdf
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math/bits"
+
+	// registers TIFF, BMP and WebP with image.Decode
+	_ "golang.org/x/image/bmp"
+	"golang.org/x/image/tiff"
+	_ "golang.org/x/image/webp"
+)
+
+// ErrImagePageNotFound is returned for a page of an image that does not
+// have it.
+var ErrImagePageNotFound = errors.New("image page not found")
+
+// TIFF tags
+const (
+	tiffImageWidth      = 256
+	tiffImageLength     = 257
+	tiffBitsPerSample   = 258
+	tiffCompression     = 259
+	tiffPhotometric     = 262
+	tiffFillOrder       = 266
+	tiffStripOffsets    = 273
+	tiffSamplesPerPixel = 277
+	tiffRowsPerStrip    = 278
+	tiffStripByteCounts = 279
+	tiffT4Options       = 292
+	tiffPlanarConfig    = 284
+	tiffPredictor       = 317
+	tiffColorMap        = 320
+	tiffTileWidth       = 322
+	tiffInkSet          = 332
+	tiffExtraSamples    = 338
+	tiffSampleFormat    = 339
+	tiffICCProfile      = 34675
+)
+
+// TIFF compressions
+const (
+	tiffCompressionNone       = 1
+	tiffCompressionG3         = 3
+	tiffCompressionG4         = 4
+	tiffCompressionLZW        = 5
+	tiffCompressionDeflate    = 8
+	tiffCompressionDeflateOld = 32946
+)
+
+// imagePage is an ImageHolder of a page of a multi-page image.
+type imagePage struct {
+	imageBuff
+	page int
+}
+
+// ImageHolderByTIFFPage creates an ImageHolder of page (from 0) of the
+// multi-page TIFF b, such as the pages of a scanned document. Use
+// TIFFPageCount for the number of pages.
+func ImageHolderByTIFFPage(b []byte, page int) (ImageHolder, error) {
+	n, err := TIFFPageCount(b)
+	if err != nil {
+		return nil, err
+	}
+	if page < 0 || page >= n {
+		return nil, fmt.Errorf("%w: page %d of %d", ErrImagePageNotFound, page, n)
+	}
+	buff, err := newImageBuff(b)
+	if err != nil {
+		return nil, err
+	}
+	buff.id = fmt.Sprintf("%s#%d", buff.id, page)
+	return &imagePage{imageBuff: *buff, page: page}, nil
+}
+
+// TIFFPageCount returns the number of pages (images) of the TIFF b.
+func TIFFPageCount(b []byte) (int, error) {
+	t, err := newTIFFFile(b)
+	if err != nil {
+		return 0, err
+	}
+	pages, err := t.pages()
+	return len(pages), err
+}
+
+// tiffFile reads the structure of a TIFF.
+type tiffFile struct {
+	data  []byte
+	order binary.ByteOrder
+}
+
+// tiffField is a field of an IFD of a TIFF.
+type tiffField struct {
+	typ   uint16
+	count uint32
+	value []byte
+}
+
+func newTIFFFile(data []byte) (*tiffFile, error) {
+	if len(data) < 8 {
+		return nil, errors.New("Not a TIFF file")
+	}
+	t := &tiffFile{data: data}
+	switch string(data[:4]) {
+	case "II\x2a\x00":
+		t.order = binary.LittleEndian
+	case "MM\x00\x2a":
+		t.order = binary.BigEndian
+	default:
+		return nil, errors.New("Not a TIFF file")
+	}
+	return t, nil
+}
+
+// pages returns the offsets of the IFDs of the pages.
+func (t *tiffFile) pages() ([]uint32, error) {
+	var offsets []uint32
+	seen := make(map[uint32]bool)
+	offset := t.order.Uint32(t.data[4:])
+	for offset != 0 {
+		if seen[offset] || uint64(offset)+2 > uint64(len(t.data)) {
+			return nil, errors.New("Incorrect TIFF IFD offset")
+		}
+		seen[offset] = true
+		offsets = append(offsets, offset)
+		next := uint64(offset) + 2 + 12*uint64(t.order.Uint16(t.data[offset:]))
+		if next+4 > uint64(len(t.data)) {
+			return nil, errors.New("Incorrect TIFF IFD")
+		}
+		offset = t.order.Uint32(t.data[next:])
+	}
+	return offsets, nil
+}
+
+// readIFD returns the fields of the IFD at offset.
+func (t *tiffFile) readIFD(offset uint32) (map[uint16]tiffField, error) {
+	sizes := map[uint16]uint32{1: 1, 2: 1, 3: 2, 4: 4, 5: 8, 6: 1, 7: 1, 8: 2, 9: 4, 10: 8, 11: 4, 12: 8}
+	fields := make(map[uint16]tiffField)
+	n := int(t.order.Uint16(t.data[offset:]))
+	for i := 0; i < n; i++ {
+		entry := t.data[offset+2+uint32(12*i):]
+		f := tiffField{typ: t.order.Uint16(entry[2:]), count: t.order.Uint32(entry[4:])}
+		size := uint64(sizes[f.typ]) * uint64(f.count)
+		if size <= 4 {
+			f.value = entry[8 : 8+size]
+		} else {
+			start := uint64(t.order.Uint32(entry[8:]))
+			if start+size > uint64(len(t.data)) {
+				return nil, errors.New("Incorrect TIFF field")
+			}
+			f.value = t.data[start : start+size]
+		}
+		fields[t.order.Uint16(entry)] = f
+	}
+	return fields, nil
+}
+
+// uints returns the values of a BYTE, SHORT or LONG field.
+func (t *tiffFile) uints(f tiffField) []uint32 {
+	values := make([]uint32, 0, f.count)
+	for i := 0; i < int(f.count); i++ {
+		switch f.typ {
+		case 1:
+			values = append(values, uint32(f.value[i]))
+		case 3:
+			values = append(values, uint32(t.order.Uint16(f.value[2*i:])))
+		case 4:
+			values = append(values, t.order.Uint32(f.value[4*i:]))
+		}
+	}
+	return values
+}
+
+// uint returns the first value of the field tag of ifd, or def when ifd
+// does not have it.
+func (t *tiffFile) uint(ifd map[uint16]tiffField, tag uint16, def uint32) uint32 {
+	if f, ok := ifd[tag]; ok {
+		if values := t.uints(f); len(values) > 0 {
+			return values[0]
+		}
+	}
+	return def
+}
+
+// parseTIFF parses page of the TIFF raw. CCITT G3 and G4, LZW and Deflate
+// compressed data of one strip are embedded as they are, with the PDF
+// filters of the same compressions, and uncompressed data is compressed
+// losslessly. Other TIFFs are decoded and embedded like PNGs.
+func parseTIFF(raw *bytes.Reader, info *imgInfo, page int) error {
+	raw.Seek(0, 0)
+	data, err := io.ReadAll(raw)
+	if err != nil {
+		return err
+	}
+	t, err := newTIFFFile(data)
+	if err != nil {
+		return err
+	}
+	pages, err := t.pages()
+	if err != nil {
+		return err
+	}
+	if page < 0 || page >= len(pages) {
+		return fmt.Errorf("%w: page %d of %d", ErrImagePageNotFound, page, len(pages))
+	}
+	ifd, err := t.readIFD(pages[page])
+	if err != nil {
+		return err
+	}
+
+	ok, err := t.parseEmbeddable(ifd, info)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		img, err := tiff.Decode(bytes.NewReader(tiffPageData(t, pages[page])))
+		if err != nil {
+			return err
+		}
+		if *info, err = parseDecodedImg(img); err != nil {
+			return err
+		}
+	}
+	info.formatName = "tiff"
+
+	if f, ok := ifd[tiffICCProfile]; ok {
+		profile, err := compress(f.value)
+		if err != nil {
+			return err
+		}
+		if iccProfileMatches(profile, info.colspace) {
+			info.icc = profile
+		}
+	}
+	return nil
+}
+
+// tiffPage returns the TIFF raw with page as its first page.
+func tiffPage(raw *bytes.Reader, page int) ([]byte, error) {
+	raw.Seek(0, 0)
+	data, err := io.ReadAll(raw)
+	if err != nil {
+		return nil, err
+	}
+	t, err := newTIFFFile(data)
+	if err != nil {
+		return nil, err
+	}
+	pages, err := t.pages()
+	if err != nil {
+		return nil, err
+	}
+	if page < 0 || page >= len(pages) {
+		return nil, fmt.Errorf("%w: page %d of %d", ErrImagePageNotFound, page, len(pages))
+	}
+	return tiffPageData(t, pages[page]), nil
+}
+
+// tiffPageData returns a copy of the TIFF of t with the IFD at offset as
+// its first page, for decoders that read only the first page.
+func tiffPageData(t *tiffFile, offset uint32) []byte {
+	data := append([]byte(nil), t.data...)
+	t.order.PutUint32(data[4:], offset)
+	return data
+}
+
+// parseEmbeddable sets info to the image of ifd with its data as it is in
+// the TIFF, and reports whether the image can be embedded so.
+func (t *tiffFile) parseEmbeddable(ifd map[uint16]tiffField, info *imgInfo) (bool, error) {
+	w, h := int(t.uint(ifd, tiffImageWidth, 0)), int(t.uint(ifd, tiffImageLength, 0))
+	samples := int(t.uint(ifd, tiffSamplesPerPixel, 1))
+	compression := t.uint(ifd, tiffCompression, tiffCompressionNone)
+	photometric := t.uint(ifd, tiffPhotometric, 0)
+	predictor := t.uint(ifd, tiffPredictor, 1)
+	if w <= 0 || h <= 0 {
+		return false, errors.New("Incorrect TIFF image size")
+	}
+	if _, tiled := ifd[tiffTileWidth]; tiled || t.uint(ifd, tiffPlanarConfig, 1) != 1 ||
+		t.uint(ifd, tiffSampleFormat, 1) != 1 || predictor > 2 {
+		return false, nil
+	}
+	bps := t.uint(ifd, tiffBitsPerSample, 1)
+	for _, b := range t.uints(ifd[tiffBitsPerSample]) {
+		if b != bps {
+			return false, nil
+		}
+	}
+	if bps != 1 && bps != 2 && bps != 4 && bps != 8 && bps != 16 {
+		return false, nil
+	}
+
+	switch {
+	case (photometric == 0 || photometric == 1) && samples == 1:
+		info.colspace = "DeviceGray"
+		if photometric == 0 { // WhiteIsZero
+			info.decode = "[1 0]"
+		}
+	case photometric == 2 && samples == 3:
+		info.colspace = "DeviceRGB"
+	case photometric == 3 && samples == 1:
+		colorMap := t.uints(ifd[tiffColorMap])
+		n := 1 << bps
+		if bps > 8 || len(colorMap) != 3*n {
+			return false, nil
+		}
+		info.colspace = "Indexed"
+		info.pal = make([]byte, 0, 3*n)
+		for i := 0; i < n; i++ {
+			info.pal = append(info.pal, byte(colorMap[i]>>8), byte(colorMap[n+i]>>8), byte(colorMap[2*n+i]>>8))
+		}
+	case photometric == 5 && samples == 4 && t.uint(ifd, tiffInkSet, 1) == 1:
+		info.colspace = "DeviceCMYK"
+	default:
+		return false, nil
+	}
+
+	offsets, counts := t.uints(ifd[tiffStripOffsets]), t.uints(ifd[tiffStripByteCounts])
+	if len(offsets) == 0 || len(offsets) != len(counts) {
+		return false, errors.New("Incorrect TIFF strips")
+	}
+	strips := make([][]byte, len(offsets))
+	for i := range offsets {
+		if uint64(offsets[i])+uint64(counts[i]) > uint64(len(t.data)) {
+			return false, errors.New("Incorrect TIFF strips")
+		}
+		strips[i] = t.data[offsets[i] : offsets[i]+counts[i]]
+	}
+	bigEndian := t.order == binary.BigEndian
+	fillOrder := t.uint(ifd, tiffFillOrder, 1)
+
+	info.w, info.h = w, h
+	info.bitsPerComponent = fmt.Sprintf("%d", bps)
+	switch compression {
+	case tiffCompressionNone:
+		if predictor != 1 || fillOrder != 1 {
+			return false, nil
+		}
+		rowBytes := (w*samples*int(bps) + 7) / 8
+		raw := bytes.Join(strips, nil)
+		if len(raw) < rowBytes*h {
+			return false, errors.New("Truncated TIFF image data")
+		}
+		raw = raw[:rowBytes*h]
+		if bps == 16 && !bigEndian {
+			raw = append([]byte(nil), raw...)
+			for i := 0; i+1 < len(raw); i += 2 {
+				raw[i], raw[i+1] = raw[i+1], raw[i]
+			}
+		}
+		data, err := compress(raw)
+		if err != nil {
+			return false, err
+		}
+		info.data = data
+		info.filter = "FlateDecode"
+	case tiffCompressionG3, tiffCompressionG4:
+		if len(strips) != 1 || bps != 1 || samples != 1 {
+			return false, nil
+		}
+		k, byteAlign := -1, false
+		if compression == tiffCompressionG3 {
+			options := t.uint(ifd, tiffT4Options, 0)
+			k, byteAlign = int(options&1), options&4 != 0
+		}
+		info.data = strips[0]
+		if fillOrder == 2 {
+			info.data = make([]byte, len(strips[0]))
+			for i, b := range strips[0] {
+				info.data[i] = bits.Reverse8(b)
+			}
+		}
+		info.filter = "CCITTFaxDecode"
+		info.decodeParms = fmt.Sprintf("/K %d /Columns %d /Rows %d /EncodedByteAlign %t", k, w, h, byteAlign)
+		// CCITTFaxDecode decodes white runs to 1, white in DeviceGray, where
+		// TIFF decodes them to 0, black unless the image is WhiteIsZero
+		if photometric == 1 {
+			info.decode = "[1 0]"
+		} else {
+			info.decode = ""
+		}
+	case tiffCompressionLZW, tiffCompressionDeflate, tiffCompressionDeflateOld:
+		if len(strips) != 1 || fillOrder != 1 || (bps == 16 && !bigEndian) {
+			return false, nil
+		}
+		if compression == tiffCompressionLZW {
+			// TIFF 6 LZW starts with the clear code MSB first, like PDF
+			if len(strips[0]) == 0 || strips[0][0] != 0x80 {
+				return false, nil
+			}
+			info.filter = "LZWDecode"
+		} else {
+			info.filter = "FlateDecode"
+		}
+		info.data = strips[0]
+		if predictor == 2 {
+			info.decodeParms = fmt.Sprintf("/Predictor 2 /Colors %d /BitsPerComponent %d /Columns %d", samples, bps, w)
+		}
+	default:
+		return false, nil
+	}
+	return true, nil
+}
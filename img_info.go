@@ -8,10 +8,14 @@ type imgInfo struct {
 	bitsPerComponent string
 	filter           string
 	decodeParms      string
-	trns             []byte
+	decode           string // Decode array, to invert samples
+	trns             []int
 	smask            []byte
 	smarkObjID       int
 	pal              []byte
 	deviceRGBObjID   int
+	icc              []byte // zlib compressed ICC profile of the samples
+	iccObjID         int
+	calColorSpace    string // CalRGB or CalGray color space of the samples, from PNG gAMA and cHRM
 	data             []byte
 }
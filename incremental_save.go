@@ -30,6 +30,9 @@ import (
 //	result, _ := pdf.IncrementalSave(original, nil)
 //	os.WriteFile("output.pdf", result, 0644)
 func (gp *GoPdf) IncrementalSave(originalData []byte, modifiedIndices []int) ([]byte, error) {
+	if err := gp.resolvePageTokens(); err != nil {
+		return nil, err
+	}
 	gp.prepare()
 	if err := gp.Close(); err != nil {
 		return nil, err
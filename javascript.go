@@ -0,0 +1,425 @@
+package gopdf
+
+import (
+	"errors"
+	"sort"
+	"strings"
+)
+
+// ============================================================
+// Document-level JavaScript and additional actions
+// (PDF 32000-1:2008, 12.6.3 and 12.6.4.16)
+// ============================================================
+
+// documentScript is a named document-level script.
+type documentScript struct {
+	name   string
+	script string
+}
+
+// AddJavaScript adds a named document-level script, written to the
+// /JavaScript name tree of the document. Viewers run the scripts when the
+// document is opened, in name order; they typically define functions used
+// by field and page actions. A script with the same name is replaced.
+//
+// Example:
+//
+//	pdf.AddJavaScript("init", `function stamp() { this.getField("printed").value = util.printd("yyyy-mm-dd", new Date()); }`)
+//	pdf.SetDocumentActions(gopdf.DocumentActions{
+//	    WillPrint: &gopdf.Action{Type: gopdf.ActionJavaScript, JavaScript: "stamp();"},
+//	})
+func (gp *GoPdf) AddJavaScript(name, script string) error {
+	if name == "" {
+		return errors.New("document JavaScript needs a name")
+	}
+	for i := range gp.javaScripts {
+		if gp.javaScripts[i].name == name {
+			gp.javaScripts[i].script = script
+			return nil
+		}
+	}
+	gp.javaScripts = append(gp.javaScripts, documentScript{name: name, script: script})
+	return nil
+}
+
+// sortedJavaScripts returns the document-level scripts sorted by name, as
+// required by the name tree.
+func (gp *GoPdf) sortedJavaScripts() []documentScript {
+	scripts := append([]documentScript(nil), gp.javaScripts...)
+	sort.Slice(scripts, func(i, j int) bool { return scripts[i].name < scripts[j].name })
+	return scripts
+}
+
+// SetDocumentActions sets the additional actions of the document, run
+// before it is closed, before and after it is saved and before and after
+// it is printed (/AA of the catalog).
+func (gp *GoPdf) SetDocumentActions(actions DocumentActions) {
+	gp.actionsToPoints(&actions.WillClose, &actions.WillSave, &actions.DidSave,
+		&actions.WillPrint, &actions.DidPrint)
+	gp.pdfObjs[gp.indexOfCatalogObj].(*CatalogObj).actions = &actions
+}
+
+// ============================================================
+// Listing and removing the JavaScript of existing PDFs
+// ============================================================
+
+// JavaScriptLocation is where a script of a PDF is attached.
+type JavaScriptLocation string
+
+const (
+	// JavaScriptDocument is a named document-level script, the open
+	// action or a document additional action.
+	JavaScriptDocument JavaScriptLocation = "document"
+	// JavaScriptPage is a page additional action.
+	JavaScriptPage JavaScriptLocation = "page"
+	// JavaScriptAnnotation is an action of an annotation, e.g. a link.
+	JavaScriptAnnotation JavaScriptLocation = "annotation"
+	// JavaScriptField is an action of a form field or of its widget.
+	JavaScriptField JavaScriptLocation = "field"
+	// JavaScriptOutline is the action of an outline item.
+	JavaScriptOutline JavaScriptLocation = "outline"
+)
+
+// JavaScriptInfo describes a script of a PDF.
+type JavaScriptInfo struct {
+	// Location is where the script is attached.
+	Location JavaScriptLocation
+	// Name is the name of a document-level script, the fully qualified
+	// name of a field or the title of an outline item.
+	Name string
+	// Trigger is the key of the action: /A, /OpenAction or an additional
+	// action such as /WP, /O or /K. Empty for document-level scripts.
+	Trigger string
+	// PageIndex is the 0-based page of page, annotation and widget scripts,
+	// or -1.
+	PageIndex int
+	// ObjNum is the object number of the page, annotation, field, outline
+	// item or catalog the action belongs to.
+	ObjNum int
+	// Script is the JavaScript source.
+	Script string
+}
+
+// ListJavaScript returns the scripts of pdfData: document-level scripts,
+// the open action, document and page additional actions and the actions
+// of annotations, form fields and outline items, including the actions
+// chained with /Next.
+func ListJavaScript(pdfData []byte) ([]JavaScriptInfo, error) {
+	store, err := newPDFObjectStore(pdfData)
+	if err != nil {
+		return nil, err
+	}
+	s := newJSScanner(store, false)
+	s.scan()
+	return s.found, nil
+}
+
+// RemoveJavaScript removes every script of pdfData listed by
+// ListJavaScript and returns the rewritten document. Other actions chained
+// after a script with /Next are kept: the script is spliced out of the
+// chain, and the action before it runs the actions that followed it.
+//
+// Example:
+//
+//	scripts, _ := gopdf.ListJavaScript(data)
+//	if len(scripts) > 0 {
+//	    data, err = gopdf.RemoveJavaScript(data)
+//	}
+func RemoveJavaScript(pdfData []byte) ([]byte, error) {
+	store, err := newPDFObjectStore(pdfData)
+	if err != nil {
+		return nil, err
+	}
+	s := newJSScanner(store, true)
+	s.scan()
+	if len(s.found) == 0 {
+		return pdfData, nil
+	}
+	return store.rewrite(), nil
+}
+
+// jsScanner walks the actions of a document, recording its scripts and
+// optionally removing them.
+type jsScanner struct {
+	store  *pdfObjectStore
+	remove bool
+	found  []JavaScriptInfo
+	seen   map[int]bool // visited action holders
+}
+
+func newJSScanner(store *pdfObjectStore, remove bool) *jsScanner {
+	return &jsScanner{store: store, remove: remove, seen: make(map[int]bool)}
+}
+
+func (s *jsScanner) scan() {
+	catalog := s.store.catalog()
+	s.documentScripts(catalog)
+	s.holder(catalog, JavaScriptInfo{Location: JavaScriptDocument, PageIndex: -1}, "/OpenAction")
+
+	fieldNames := make(map[int]string)
+	var fields []int
+	var walkFields func(num int, prefix string, depth int)
+	walkFields = func(num int, prefix string, depth int) {
+		if depth > 32 {
+			return
+		}
+		if _, ok := fieldNames[num]; ok {
+			return
+		}
+		dict := s.store.dict(num)
+		name := prefix
+		if t := decodePDFTextString(s.store.resolve(pdfDictGet(dict, "/T"))); t != "" {
+			if name != "" {
+				name += "."
+			}
+			name += t
+		}
+		fieldNames[num] = name
+		fields = append(fields, num)
+		for _, kid := range pdfRefs(s.store.resolve(pdfDictGet(dict, "/Kids"))) {
+			walkFields(kid, name, depth+1)
+		}
+	}
+	acro := s.store.resolveDict(pdfDictGet(s.store.dict(catalog), "/AcroForm"))
+	for _, num := range pdfRefs(s.store.resolve(pdfDictGet(acro, "/Fields"))) {
+		walkFields(num, "", 0)
+	}
+
+	for i, page := range s.store.pageNums() {
+		s.holder(page, JavaScriptInfo{Location: JavaScriptPage, PageIndex: i})
+		for _, num := range pageAnnotNums(s.store, page) {
+			info := JavaScriptInfo{Location: JavaScriptAnnotation, PageIndex: i}
+			if name, ok := fieldNames[num]; ok || pdfNameValue(pdfDictGet(s.store.dict(num), "/Subtype")) == "Widget" {
+				info.Location, info.Name = JavaScriptField, name
+			}
+			s.holder(num, info, "/A")
+		}
+	}
+	for _, num := range fields {
+		s.holder(num, JavaScriptInfo{Location: JavaScriptField, Name: fieldNames[num], PageIndex: -1}, "/A")
+	}
+
+	outlines := s.store.resolveDict(pdfDictGet(s.store.dict(catalog), "/Outlines"))
+	s.outlines(pdfDictGet(outlines, "/First"), 0)
+}
+
+// documentScripts records the /JavaScript name tree and removes it.
+func (s *jsScanner) documentScripts(catalog int) {
+	namesValue := pdfDictGet(s.store.dict(catalog), "/Names")
+	names := s.store.resolveDict(namesValue)
+	tree := pdfDictGet(names, "/JavaScript")
+	if tree == "" {
+		return
+	}
+	var walk func(value string, depth int)
+	walk = func(value string, depth int) {
+		node := s.store.resolveDict(value)
+		if node == "" || depth > 32 {
+			return
+		}
+		items := parsePDFArray(s.store.resolve(pdfDictGet(node, "/Names")))
+		for i := 0; i+1 < len(items); i += 2 {
+			s.action(items[i+1], JavaScriptInfo{
+				Location:  JavaScriptDocument,
+				Name:      decodePDFTextString(s.store.resolve(items[i])),
+				PageIndex: -1,
+				ObjNum:    catalog,
+			}, 0)
+		}
+		for _, kid := range parsePDFArray(s.store.resolve(pdfDictGet(node, "/Kids"))) {
+			walk(kid, depth+1)
+		}
+	}
+	walk(tree, 0)
+	if !s.remove {
+		return
+	}
+	names = pdfDictDelete(names, "/JavaScript")
+	if num, ok := pdfRef(namesValue); ok {
+		s.store.setDict(num, names)
+	} else {
+		s.store.setDict(catalog, pdfDictSet(s.store.dict(catalog), "/Names", names))
+	}
+}
+
+// outlines visits the outline items from first, with their children.
+func (s *jsScanner) outlines(first string, depth int) {
+	for value := first; depth <= 32; {
+		num, ok := pdfRef(value)
+		if !ok || s.seen[num] {
+			return
+		}
+		dict := s.store.dict(num)
+		s.holder(num, JavaScriptInfo{
+			Location:  JavaScriptOutline,
+			Name:      decodePDFTextString(s.store.resolve(pdfDictGet(dict, "/Title"))),
+			PageIndex: -1,
+		}, "/A")
+		s.outlines(pdfDictGet(dict, "/First"), depth+1)
+		value = pdfDictGet(dict, "/Next")
+	}
+}
+
+// holder visits the actions of the object num under keys and its
+// additional actions.
+func (s *jsScanner) holder(num int, info JavaScriptInfo, keys ...string) {
+	if num <= 0 || s.seen[num] {
+		return
+	}
+	s.seen[num] = true
+	info.ObjNum = num
+	dict := s.store.dict(num)
+	orig := dict
+	for _, key := range keys {
+		value := pdfDictGet(dict, key)
+		if value == "" {
+			continue
+		}
+		info.Trigger = key
+		if v := s.action(value, info, 0); v == "" {
+			dict = pdfDictDelete(dict, key)
+		} else if v != value {
+			dict = pdfDictSet(dict, key, v)
+		}
+	}
+	dict = s.additionalActions(dict, info)
+	if dict != orig {
+		s.store.setDict(num, dict)
+	}
+}
+
+// additionalActions visits the /AA dictionary of dict and returns dict
+// without the removed scripts.
+func (s *jsScanner) additionalActions(dict string, info JavaScriptInfo) string {
+	aaValue := pdfDictGet(dict, "/AA")
+	aa := s.store.resolveDict(aaValue)
+	if aa == "" {
+		return dict
+	}
+	changed := aa
+	for _, e := range parsePDFDict(aa) {
+		info.Trigger = e.key
+		if v := s.action(e.value, info, 0); v == "" {
+			changed = pdfDictDelete(changed, e.key)
+		} else if v != e.value {
+			changed = pdfDictSet(changed, e.key, v)
+		}
+	}
+	switch {
+	case changed == aa:
+		return dict
+	case len(parsePDFDict(changed)) == 0:
+		return pdfDictDelete(dict, "/AA")
+	}
+	if num, ok := pdfRef(aaValue); ok {
+		s.store.setDict(num, changed)
+		return dict
+	}
+	return pdfDictSet(dict, "/AA", changed)
+}
+
+// action records the scripts of the action value and its /Next chain. It
+// returns value, the action without the removed scripts of its chain, or
+// "" when the chain holds nothing but removed scripts. A removed script at
+// the head of the chain is replaced by the actions that followed it.
+func (s *jsScanner) action(value string, info JavaScriptInfo, depth int) string {
+	kept := s.actions(value, info, depth)
+	switch len(kept) {
+	case 0:
+		return ""
+	case 1:
+		return kept[0]
+	}
+	return s.appendNext(kept[0], kept[1:])
+}
+
+// actions records the scripts of the action value and its /Next chain and
+// returns the actions that take its place: value itself, or the actions
+// that followed it when value is a removed script. Removed scripts of the
+// chain are spliced out, so their predecessors run their successors.
+func (s *jsScanner) actions(value string, info JavaScriptInfo, depth int) []string {
+	dict := s.store.resolveDict(value)
+	if dict == "" || depth > 32 {
+		return []string{value}
+	}
+	removed := false
+	if pdfNameValue(pdfDictGet(dict, "/S")) == "JavaScript" {
+		info.Script = s.script(pdfDictGet(dict, "/JS"))
+		s.found = append(s.found, info)
+		removed = s.remove
+	}
+	next := pdfDictGet(dict, "/Next")
+	var kept []string
+	newNext := next
+	if arr := s.store.resolve(next); strings.HasPrefix(arr, "[") {
+		for _, item := range parsePDFArray(arr) {
+			kept = append(kept, s.actions(item, info, depth+1)...)
+		}
+		newNext = ""
+		if len(kept) > 0 {
+			newNext = "[" + strings.Join(kept, " ") + "]"
+		}
+		if newNext == arr {
+			newNext = next
+		}
+	} else if next != "" {
+		kept = s.actions(next, info, depth+1)
+		switch len(kept) {
+		case 0:
+			newNext = ""
+		case 1:
+			newNext = kept[0]
+		default:
+			newNext = "[" + strings.Join(kept, " ") + "]"
+		}
+	}
+	if removed {
+		return kept
+	}
+	if newNext == next {
+		return []string{value}
+	}
+	if newNext == "" {
+		dict = pdfDictDelete(dict, "/Next")
+	} else {
+		dict = pdfDictSet(dict, "/Next", newNext)
+	}
+	if num, ok := pdfRef(value); ok {
+		s.store.setDict(num, dict)
+		return []string{value}
+	}
+	return []string{dict}
+}
+
+// appendNext returns the action value with the actions rest run after its
+// own /Next chain.
+func (s *jsScanner) appendNext(value string, rest []string) string {
+	dict := s.store.resolveDict(value)
+	var next []string
+	if v := pdfDictGet(dict, "/Next"); v != "" {
+		if arr := s.store.resolve(v); strings.HasPrefix(arr, "[") {
+			next = parsePDFArray(arr)
+		} else {
+			next = []string{v}
+		}
+	}
+	dict = pdfDictSet(dict, "/Next", "["+strings.Join(append(next, rest...), " ")+"]")
+	if num, ok := pdfRef(value); ok {
+		s.store.setDict(num, dict)
+		return value
+	}
+	return dict
+}
+
+// script returns the source of a /JS value, a text string or a stream.
+func (s *jsScanner) script(value string) string {
+	if num, ok := pdfRef(value); ok {
+		if _, changed := s.store.changed[num]; !changed {
+			if obj, ok := s.store.parser.objects[num]; ok && obj.stream != nil {
+				return string(obj.stream)
+			}
+		}
+		value = s.store.resolve(value)
+	}
+	return decodePDFTextString(value)
+}
@@ -0,0 +1,514 @@
+package gopdf
+
+import (
+	"strings"
+	"unicode"
+)
+
+// ============================================================
+// Unicode line breaking (UAX #14) with CJK kinsoku shori
+// ============================================================
+
+// lbClass is a line breaking class of UAX #14. Classes that are not
+// distinguished here are folded into AL (alphabetic) or ID (ideographic).
+type lbClass int
+
+const (
+	lbAL  lbClass = iota // alphabetic and symbols
+	lbBK                 // mandatory break
+	lbCR                 // carriage return
+	lbLF                 // line feed
+	lbNL                 // next line
+	lbSP                 // space
+	lbZW                 // zero width space
+	lbWJ                 // word joiner
+	lbGL                 // non-breaking ("glue")
+	lbCM                 // combining mark
+	lbZWJ                // zero width joiner
+	lbOP                 // opening punctuation
+	lbCL                 // closing punctuation
+	lbCP                 // closing parenthesis
+	lbQU                 // quotation
+	lbEX                 // exclamation and interrogation
+	lbIS                 // infix numeric separator
+	lbSY                 // symbols allowing break after
+	lbNS                 // non-starter (kinsoku: no line start)
+	lbIN                 // inseparable
+	lbHY                 // hyphen
+	lbBA                 // break after
+	lbBB                 // break before
+	lbB2                 // break opportunity before and after
+	lbPR                 // prefix numeric
+	lbPO                 // postfix numeric
+	lbNU                 // numeric
+	lbID                 // ideographic
+	lbRI                 // regional indicator
+)
+
+// lbAction is the break action between two characters.
+type lbAction int
+
+const (
+	lbProhibited lbAction = iota
+	lbAllowed
+	lbMandatory
+)
+
+// lineBreakClass returns the line breaking class of r. Small kana and the
+// prolonged sound mark are non-starters, as in strict Japanese line
+// breaking, so they never start a line.
+func lineBreakClass(r rune) lbClass {
+	switch r {
+	case '\n':
+		return lbLF
+	case '\r':
+		return lbCR
+	case 0x0B, 0x0C, 0x2028, 0x2029:
+		return lbBK
+	case 0x85:
+		return lbNL
+	case ' ':
+		return lbSP
+	case 0x200B:
+		return lbZW
+	case 0x2060, 0xFEFF:
+		return lbWJ
+	case 0x200D:
+		return lbZWJ
+	case 0x00A0, 0x202F, 0x2007, 0x2011, 0x034F, 0x180E, 0x0F08, 0x0F0C, 0x0F12:
+		return lbGL
+	case '(', '[', '{', 0x00A1, 0x00BF, 0x201A, 0x201E,
+		0x3008, 0x300A, 0x300C, 0x300E, 0x3010, 0x3014, 0x3016, 0x3018, 0x301A, 0x301D,
+		0xFE59, 0xFE5B, 0xFE5D, 0xFF08, 0xFF3B, 0xFF5B, 0xFF5F, 0xFF62:
+		return lbOP
+	case ')', ']', 0xFF09, 0xFF3D:
+		return lbCP
+	case '}', 0x3001, 0x3002, 0x3009, 0x300B, 0x300D, 0x300F, 0x3011, 0x3015, 0x3017, 0x3019,
+		0x301B, 0x301E, 0x301F, 0xFE50, 0xFE52, 0xFE5A, 0xFE5C, 0xFE5E, 0xFF0C, 0xFF0E,
+		0xFF5D, 0xFF60, 0xFF61, 0xFF63, 0xFF64:
+		return lbCL
+	case '"', '\'', 0x00AB, 0x00BB, 0x2018, 0x2019, 0x201B, 0x201C, 0x201D, 0x201F, 0x2039, 0x203A:
+		return lbQU
+	case '!', '?', 0x05C6, 0x061B, 0x061F, 0xFE15, 0xFE16, 0xFE56, 0xFE57, 0xFF01, 0xFF1F:
+		return lbEX
+	case ',', '.', ':', ';', 0x037E, 0x0589, 0x060C, 0x060D, 0x2044, 0xFE10, 0xFE13, 0xFE14:
+		return lbIS
+	case '/':
+		return lbSY
+	case '-':
+		return lbHY
+	case '\t', '|', 0x00AD, 0x058A, 0x2010, 0x2012, 0x2013, 0x2027, 0x3000:
+		return lbBA
+	case 0x00B4, 0x02C8, 0x02CC, 0x02DF:
+		return lbBB
+	case 0x2014:
+		return lbB2
+	case 0x2024, 0x2025, 0x2026, 0xFE19:
+		return lbIN
+	case '$', '+', '\\', 0x00A3, 0x00A5, 0x00B1, 0x20AC, 0x2116, 0x2212, 0xFF04, 0xFFE1, 0xFFE5, 0xFFE6:
+		return lbPR
+	case '%', 0x00A2, 0x00B0, 0x2030, 0x2031, 0x2032, 0x2033, 0x2034, 0x2035, 0x2036, 0x2037,
+		0x2103, 0x2109, 0xFF05, 0xFFE0:
+		return lbPO
+	// Kinsoku: characters that must not start a line.
+	case 0x203C, 0x203D, 0x2047, 0x2048, 0x2049, 0x3005, 0x301C, 0x303B, 0x309B, 0x309C,
+		0x309D, 0x309E, 0x30A0, 0x30FB, 0x30FC, 0x30FD, 0x30FE, 0xFF1A, 0xFF1B, 0xFF65, 0xFF70,
+		0x3041, 0x3043, 0x3045, 0x3047, 0x3049, 0x3063, 0x3083, 0x3085, 0x3087, 0x308E, 0x3095, 0x3096,
+		0x30A1, 0x30A3, 0x30A5, 0x30A7, 0x30A9, 0x30C3, 0x30E3, 0x30E5, 0x30E7, 0x30EE, 0x30F5, 0x30F6:
+		return lbNS
+	}
+	switch {
+	case r >= 0x31F0 && r <= 0x31FF, r >= 0xFF67 && r <= 0xFF6F:
+		return lbNS // small katakana
+	case r >= 0x1F1E6 && r <= 0x1F1FF:
+		return lbRI
+	case r >= 0x20A0 && r <= 0x20CF:
+		return lbPR // currency symbols
+	case unicode.In(r, unicode.Mn, unicode.Me, unicode.Mc):
+		return lbCM
+	case r >= 0xFF10 && r <= 0xFF19:
+		return lbID // fullwidth digits
+	case unicode.Is(unicode.Nd, r):
+		return lbNU
+	case isIdeographicBreak(r):
+		return lbID
+	}
+	return lbAL
+}
+
+// isIdeographicBreak reports whether r is a CJK character, kana, Hangul or
+// emoji, around which lines may break.
+func isIdeographicBreak(r rune) bool {
+	switch {
+	case r >= 0x2E80 && r <= 0x2FFF, // radicals, ideographic description
+		r >= 0x3003 && r <= 0x303F,   // CJK symbols
+		r >= 0x3040 && r <= 0x31FF,   // kana, bopomofo, Hangul compatibility jamo
+		r >= 0x3200 && r <= 0x4DBF,   // enclosed CJK, compatibility, extension A
+		r >= 0x4E00 && r <= 0x9FFF,   // unified ideographs
+		r >= 0xA000 && r <= 0xA4CF,   // Yi
+		r >= 0xAC00 && r <= 0xD7A3,   // Hangul syllables
+		r >= 0xF900 && r <= 0xFAFF,   // compatibility ideographs
+		r >= 0xFE30 && r <= 0xFE4F,   // CJK compatibility forms
+		r >= 0xFF00 && r <= 0xFF60,   // fullwidth forms
+		r >= 0xFF66 && r <= 0xFF9F,   // halfwidth katakana
+		r >= 0x1F000 && r <= 0x1FAFF, // emoji and pictographs
+		r >= 0x20000 && r <= 0x3FFFD: // supplementary ideographs
+		return true
+	}
+	return false
+}
+
+// isEastAsianWide reports whether the punctuation r is fullwidth.
+func isEastAsianWide(r rune) bool {
+	return r >= 0x3000 && r <= 0x303F || r >= 0xFE30 && r <= 0xFE6F || r >= 0xFF00 && r <= 0xFF60
+}
+
+// lineBreaks returns the break action before each rune of runes; the
+// action before the first rune is lbProhibited.
+func lineBreaks(runes []rune) []lbAction {
+	n := len(runes)
+	raw := make([]lbClass, n)
+	cls := make([]lbClass, n)
+	attached := make([]bool, n)
+	for i, r := range runes {
+		raw[i] = lineBreakClass(r)
+		cls[i] = raw[i]
+		// LB9, LB10: combining marks take the class of their base.
+		if cls[i] == lbCM || cls[i] == lbZWJ {
+			if i > 0 && !isLBSpaceOrBreak(cls[i-1]) {
+				cls[i], attached[i] = cls[i-1], true
+			} else {
+				cls[i] = lbAL
+			}
+		}
+	}
+	breaks := make([]lbAction, n)
+	for i := 1; i < n; i++ {
+		breaks[i] = lineBreakAt(runes, raw, cls, attached, i)
+	}
+	return breaks
+}
+
+func isLBSpaceOrBreak(c lbClass) bool {
+	switch c {
+	case lbBK, lbCR, lbLF, lbNL, lbSP, lbZW:
+		return true
+	}
+	return false
+}
+
+// lineBreakAt applies the rules LB4 to LB31 to the position before
+// runes[i].
+func lineBreakAt(runes []rune, raw, cls []lbClass, attached []bool, i int) lbAction {
+	a, b := cls[i-1], cls[i]
+	switch a {
+	case lbBK, lbLF, lbNL: // LB4, LB5
+		return lbMandatory
+	case lbCR:
+		if b == lbLF {
+			return lbProhibited
+		}
+		return lbMandatory
+	}
+	switch b {
+	case lbBK, lbCR, lbLF, lbNL, lbSP, lbZW: // LB6, LB7
+		return lbProhibited
+	}
+	// The class before any spaces, for the rules with SP*.
+	j := i - 1
+	for j >= 0 && cls[j] == lbSP {
+		j--
+	}
+	prev := lbClass(-1)
+	if j >= 0 {
+		prev = cls[j]
+	}
+	switch {
+	case prev == lbZW: // LB8
+		return lbAllowed
+	case raw[i-1] == lbZWJ, attached[i]: // LB8a, LB9
+		return lbProhibited
+	case a == lbWJ || b == lbWJ: // LB11
+		return lbProhibited
+	case a == lbGL: // LB12
+		return lbProhibited
+	case b == lbGL && a != lbSP && a != lbBA && a != lbHY: // LB12a
+		return lbProhibited
+	case b == lbCL || b == lbCP || b == lbEX || b == lbIS || b == lbSY: // LB13
+		return lbProhibited
+	case prev == lbOP: // LB14
+		return lbProhibited
+	case prev == lbQU && b == lbOP: // LB15
+		return lbProhibited
+	case (prev == lbCL || prev == lbCP) && b == lbNS: // LB16
+		return lbProhibited
+	case prev == lbB2 && b == lbB2: // LB17
+		return lbProhibited
+	case a == lbSP: // LB18
+		return lbAllowed
+	case a == lbQU || b == lbQU: // LB19
+		return lbProhibited
+	case b == lbBA || b == lbHY || b == lbNS || a == lbBB: // LB21
+		return lbProhibited
+	case b == lbIN: // LB22
+		return lbProhibited
+	case a == lbAL && b == lbNU, a == lbNU && b == lbAL: // LB23
+		return lbProhibited
+	case a == lbPR && b == lbID, a == lbID && b == lbPO: // LB23a
+		return lbProhibited
+	case (a == lbPR || a == lbPO) && b == lbAL, a == lbAL && (b == lbPR || b == lbPO): // LB24
+		return lbProhibited
+	case (a == lbCL || a == lbCP || a == lbNU) && (b == lbPO || b == lbPR), // LB25
+		(a == lbPO || a == lbPR) && b == lbOP,
+		(a == lbPO || a == lbPR || a == lbHY || a == lbIS || a == lbNU || a == lbSY) && b == lbNU:
+		return lbProhibited
+	case a == lbAL && b == lbAL: // LB28
+		return lbProhibited
+	case a == lbIS && b == lbAL: // LB29
+		return lbProhibited
+	case (a == lbAL || a == lbNU) && b == lbOP && !isEastAsianWide(runes[i]), // LB30
+		a == lbCP && !isEastAsianWide(runes[i-1]) && (b == lbAL || b == lbNU):
+		return lbProhibited
+	case a == lbRI && b == lbRI: // LB30a
+		count := 0
+		for k := i - 1; k >= 0 && cls[k] == lbRI; k-- {
+			count++
+		}
+		if count%2 == 1 {
+			return lbProhibited
+		}
+	}
+	return lbAllowed // LB31
+}
+
+// lineSegment is the text between two break opportunities.
+type lineSegment struct {
+	text      string // without trailing spaces, line breaks and soft hyphen
+	spaces    string // trailing spaces
+	soft      bool   // ends at a soft hyphen
+	mandatory bool   // followed by a mandatory break
+}
+
+// lineBreakSegments splits text at its line break opportunities. Zero
+// width spaces, word joiners and soft hyphens are removed.
+func lineBreakSegments(text string) []lineSegment {
+	runes := []rune(text)
+	breaks := lineBreaks(runes)
+	var segs []lineSegment
+	start := 0
+	for i := 1; i <= len(runes); i++ {
+		if i < len(runes) && breaks[i] == lbProhibited {
+			continue
+		}
+		mandatory := i < len(runes) && breaks[i] == lbMandatory
+		seg := runes[start:i]
+		end := len(seg)
+		for end > 0 {
+			c := lineBreakClass(seg[end-1])
+			if c == lbSP || c == lbZW {
+				end--
+			} else if c == lbBK || c == lbCR || c == lbLF || c == lbNL {
+				end--
+				mandatory = true
+			} else {
+				break
+			}
+		}
+		segs = append(segs, lineSegment{
+			text:      stripInvisible(string(seg[:end])),
+			spaces:    strings.Repeat(" ", strings.Count(string(seg[end:]), " ")),
+			soft:      end > 0 && seg[end-1] == 0xAD,
+			mandatory: mandatory,
+		})
+		start = i
+	}
+	return segs
+}
+
+// indicatorSegments splits text after each break indicator, at soft
+// hyphens and at newlines. A space indicator is kept as trailing space.
+func indicatorSegments(text string, indicator rune) []lineSegment {
+	var segs []lineSegment
+	var seg lineSegment
+	var sb strings.Builder
+	flush := func() {
+		seg.text = sb.String()
+		segs = append(segs, seg)
+		seg = lineSegment{}
+		sb.Reset()
+	}
+	for _, r := range text {
+		switch {
+		case r == '\n':
+			seg.mandatory = true
+			flush()
+		case r == 0xAD:
+			seg.soft = true
+			flush()
+		case r == indicator && r == ' ':
+			seg.spaces += " "
+		default:
+			if seg.spaces != "" {
+				flush()
+			}
+			sb.WriteRune(r)
+			if r == indicator {
+				flush()
+			}
+		}
+	}
+	if sb.Len() > 0 || seg.spaces != "" {
+		flush()
+	}
+	return segs
+}
+
+// runeSegments splits text between characters, for strict breaking.
+// Spaces are kept as trailing spaces.
+func runeSegments(text string) []lineSegment {
+	var segs []lineSegment
+	for _, r := range text {
+		switch {
+		case r == ' ':
+			if len(segs) == 0 {
+				segs = append(segs, lineSegment{})
+			}
+			segs[len(segs)-1].spaces += " "
+		case r == '\n':
+			if len(segs) == 0 || segs[len(segs)-1].mandatory {
+				segs = append(segs, lineSegment{})
+			}
+			segs[len(segs)-1].mandatory = true
+		default:
+			segs = append(segs, lineSegment{text: string(r)})
+		}
+	}
+	return segs
+}
+
+// stripInvisible removes zero width spaces, word joiners and soft hyphens,
+// which are not drawn.
+func stripInvisible(s string) string {
+	if !strings.ContainsAny(s, "\u200b\u2060\ufeff\u00ad") {
+		return s
+	}
+	return strings.Map(func(r rune) rune {
+		if r == 0x200B || r == 0x2060 || r == 0xFEFF || r == 0xAD {
+			return -1
+		}
+		return r
+	}, s)
+}
+
+// splitTextUnicode splits text into lines of at most width at the line
+// break opportunities of UAX #14.
+func (gp *GoPdf) splitTextUnicode(text string, width float64, opt *BreakOption) ([]string, error) {
+	return gp.splitTextSegments(lineBreakSegments(text), width, 0, opt)
+}
+
+// splitTextSegments fills lines of at most width, and width-indent for
+// the first line, with segs. Spaces at the end of a line are dropped. A segment that does not fit is hyphenated
+// with the Hyphenator of opt, if any, or moved to the next line. Segments
+// wider than width are broken between characters, with the separator of
+// opt.
+func (gp *GoPdf) splitTextSegments(segs []lineSegment, fullWidth, indent float64, opt *BreakOption) ([]string, error) {
+	hyphen := string(opt.hyphen())
+	width := fullWidth - indent
+	var lines []string
+	line, pending := "", ""       // pending spaces after line
+	hasLine, soft := false, false // soft: the line ends at a soft hyphen
+	endLine := func() {
+		if soft {
+			line += hyphen
+		}
+		lines = append(lines, line)
+		line, pending, hasLine, soft = "", "", false, false
+		width = fullWidth
+	}
+	for i, seg := range segs {
+		text, suffix := seg.text, ""
+		if seg.soft {
+			suffix = hyphen
+		}
+		// Words with soft hyphens are only broken there.
+		hyphenate := opt.Hyphenator != nil && !seg.soft && (i == 0 || !segs[i-1].soft || segs[i-1].spaces != "")
+		for text != "" {
+			w, err := gp.MeasureTextWidth(line + pending + text + suffix)
+			if err != nil {
+				return nil, err
+			}
+			if w <= width {
+				break
+			}
+			if hyphenate {
+				head, tail, err := gp.hyphenateToFit(line+pending, text, width, opt)
+				if err != nil {
+					return nil, err
+				}
+				if head != "" {
+					line += pending + head
+					soft = true
+					endLine()
+					text = tail
+					continue
+				}
+			}
+			if line != "" {
+				endLine()
+				continue
+			}
+			pending = ""
+			pieces, err := gp.splitTextRunes(text, width, opt)
+			if err != nil {
+				return nil, err
+			}
+			lines = append(lines, pieces[:len(pieces)-1]...)
+			text = pieces[len(pieces)-1]
+			if len(pieces) > 1 {
+				width = fullWidth
+			}
+			break
+		}
+		if text != "" {
+			line += pending + text
+			pending = ""
+			soft = seg.soft
+		}
+		hasLine = true
+		if seg.spaces != "" {
+			pending += seg.spaces
+			soft = false
+		}
+		if seg.mandatory {
+			soft = false
+			endLine()
+		}
+	}
+	if hasLine {
+		soft = false
+		endLine()
+	}
+	return lines, nil
+}
+
+// splitTextRunes breaks text between characters into lines of at most
+// width, appending the separator of opt to every line but the last. Every
+// line holds at least one character.
+func (gp *GoPdf) splitTextRunes(text string, width float64, opt *BreakOption) ([]string, error) {
+	var lines []string
+	var line []rune
+	for _, r := range text {
+		if len(line) > 0 {
+			w, err := gp.MeasureTextWidth(string(line) + string(r) + opt.Separator)
+			if err != nil {
+				return nil, err
+			}
+			if w > width {
+				lines = append(lines, string(line)+opt.Separator)
+				line = line[:0]
+			}
+		}
+		line = append(line, r)
+	}
+	return append(lines, string(line)), nil
+}
@@ -19,6 +19,10 @@ type LinkInfo struct {
 	Anchor string
 	// IsExternal is true for external URL links, false for internal anchors.
 	IsExternal bool
+	// Action is the action of links added with AddActionLink.
+	Action *Action
+	// Dest is the destination of links added with AddDestLink.
+	Dest *Destination
 }
 
 // GetLinks returns all links on the current page.
@@ -60,7 +64,13 @@ func (gp *GoPdf) getLinksFromPage(page *PageObj) []LinkInfo {
 				W:     gp.PointsToUnits(v.w),
 				H:     gp.PointsToUnits(v.h),
 			}
-			if v.url != "" {
+			if v.action != nil {
+				action := gp.actionToUnits(*v.action)
+				li.Action = &action
+			} else if v.dest != nil {
+				dest := gp.destToUnits(*v.dest)
+				li.Dest = &dest
+			} else if v.url != "" {
 				li.URL = v.url
 				li.IsExternal = true
 			} else if v.anchor != "" {
@@ -153,8 +163,13 @@ type ExtractedLink struct {
 	Rect [4]float64
 	// URI is the external URL (if any).
 	URI string
-	// Destination is the internal destination (if any).
+	// Destination is the internal destination (if any), with named
+	// destinations resolved to their page and view.
 	Destination string
+	// NamedDest is the name of a named destination (if any).
+	NamedDest string
+	// DestPageIndex is the 0-based page index of the destination, or -1.
+	DestPageIndex int
 	// IsExternal is true for URI links.
 	IsExternal bool
 }
@@ -182,9 +197,10 @@ func ExtractLinks(pdfData []byte) ([]ExtractedLink, error) {
 		return nil, err
 	}
 
+	dests := extractNamedDests(pdfData)
 	var results []ExtractedLink
 	for pageIdx, page := range parser.pages {
-		links := extractLinksFromPage(parser, page, pageIdx)
+		links := extractLinksFromPage(parser, page, pageIdx, dests)
 		results = append(results, links...)
 	}
 	return results, nil
@@ -199,10 +215,19 @@ func ExtractLinksFromPage(pdfData []byte, pageIndex int) ([]ExtractedLink, error
 	if pageIndex < 0 || pageIndex >= len(parser.pages) {
 		return nil, fmt.Errorf("page index %d out of range", pageIndex)
 	}
-	return extractLinksFromPage(parser, parser.pages[pageIndex], pageIndex), nil
+	return extractLinksFromPage(parser, parser.pages[pageIndex], pageIndex, extractNamedDests(pdfData)), nil
 }
 
-func extractLinksFromPage(parser *rawPDFParser, page rawPDFPage, pageIdx int) []ExtractedLink {
+// extractNamedDests returns the named destinations of a PDF, or nil.
+func extractNamedDests(pdfData []byte) map[string]string {
+	store, err := newPDFObjectStore(pdfData)
+	if err != nil {
+		return nil
+	}
+	return readNamedDests(store)
+}
+
+func extractLinksFromPage(parser *rawPDFParser, page rawPDFPage, pageIdx int, dests map[string]string) []ExtractedLink {
 	var results []ExtractedLink
 
 	// Get the page object dictionary.
@@ -223,7 +248,7 @@ func extractLinksFromPage(parser *rawPDFParser, page rawPDFPage, pageIdx int) []
 			continue
 		}
 
-		link := ExtractedLink{PageIndex: pageIdx}
+		link := ExtractedLink{PageIndex: pageIdx, DestPageIndex: -1}
 
 		// Extract rectangle.
 		if m := reLinkRect.FindStringSubmatch(dict); m != nil {
@@ -239,8 +264,35 @@ func extractLinksFromPage(parser *rawPDFParser, page rawPDFPage, pageIdx int) []
 			link.IsExternal = true
 		}
 
-		// Extract destination.
-		if m := reLinkDest.FindStringSubmatch(dict); m != nil {
+		// Extract destination, from /Dest or a GoTo action.
+		dest := pdfDictGet(dict, "/Dest")
+		if action := pdfDictGet(dict, "/A"); dest == "" && action != "" {
+			if num, ok := pdfRef(action); ok {
+				action = parser.objects[num].dict
+			}
+			if pdfNameValue(pdfDictGet(action, "/S")) == "GoTo" {
+				dest = pdfDictGet(action, "/D")
+			}
+		}
+		if num, ok := pdfRef(dest); ok {
+			dest = strings.TrimSpace(string(parser.objects[num].body))
+		}
+		if strings.HasPrefix(dest, "<<") {
+			dest = pdfDictGet(dest, "/D")
+		}
+		switch {
+		case strings.HasPrefix(dest, "/"):
+			link.NamedDest = pdfNameValue(dest)
+		case strings.HasPrefix(dest, "("), strings.HasPrefix(dest, "<"):
+			link.NamedDest = decodePDFTextString(dest)
+		}
+		if link.NamedDest != "" {
+			dest = dests[link.NamedDest]
+		}
+		if strings.HasPrefix(dest, "[") {
+			link.Destination = strings.TrimSpace(strings.TrimSuffix(dest[1:], "]"))
+			link.DestPageIndex = destPageIndex(parser, link.Destination)
+		} else if m := reLinkDest.FindStringSubmatch(dict); m != nil {
 			link.Destination = strings.TrimSpace(m[1])
 		}
 
@@ -249,3 +301,24 @@ func extractLinksFromPage(parser *rawPDFParser, page rawPDFPage, pageIdx int) []
 
 	return results
 }
+
+// destPageIndex returns the 0-based index of the page of an explicit
+// destination, given without brackets, or -1.
+func destPageIndex(parser *rawPDFParser, dest string) int {
+	items := parsePDFArray("[" + dest + "]")
+	if len(items) == 0 {
+		return -1
+	}
+	if num, ok := pdfRef(items[0]); ok {
+		for i, page := range parser.pages {
+			if page.objNum == num {
+				return i
+			}
+		}
+		return -1
+	}
+	if n, err := strconv.Atoi(items[0]); err == nil && n >= 0 && n < len(parser.pages) {
+		return n
+	}
+	return -1
+}
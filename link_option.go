@@ -9,4 +9,6 @@ type linkOption struct {
 	x, y, w, h float64
 	url        string
 	anchor     string
+	action     *Action
+	dest       *Destination
 }
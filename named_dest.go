@@ -0,0 +1,181 @@
+package gopdf
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// ============================================================
+// Named destinations (PDF 32000-1:2008, 12.3.2.3)
+// ============================================================
+
+// ErrInvalidDestination is returned when a destination has no page.
+var ErrInvalidDestination = errors.New("destination needs a page number")
+
+// AddNamedDest adds a named destination, written to the /Dests name tree of
+// the document, so that viewers can open it with file.pdf#nameddest=name
+// and links and outline items can refer to it with Destination.Name.
+// Positions are in document units from the top-left page corner.
+//
+// Example:
+//
+//	pdf.AddNamedDest("section-4.2", gopdf.Destination{
+//	    Page: 7, Top: 120, Zoom: 1.25,
+//	})
+func (gp *GoPdf) AddNamedDest(name string, dest Destination) error {
+	if name == "" {
+		return fmt.Errorf("named destination needs a name")
+	}
+	if dest.Page < 1 {
+		return ErrInvalidDestination
+	}
+	if gp.namedDests == nil {
+		gp.namedDests = make(map[string]Destination)
+	}
+	dest.Name = ""
+	gp.namedDests[name] = gp.destToPoints(dest)
+	return nil
+}
+
+// GetNamedDests returns the names of the named destinations and anchors,
+// sorted.
+func (gp *GoPdf) GetNamedDests() []string {
+	return gp.destNames()
+}
+
+// destNames returns the sorted names of the named destinations and anchors.
+func (gp *GoPdf) destNames() []string {
+	var names []string
+	for name := range gp.namedDests {
+		names = append(names, name)
+	}
+	for name := range gp.anchors {
+		if _, ok := gp.namedDests[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// namedDest returns the destination of a name of the /Dests tree. Anchors
+// are returned by name and resolved by the action writer.
+func (gp *GoPdf) namedDest(name string) Destination {
+	if d, ok := gp.namedDests[name]; ok {
+		return d
+	}
+	return Destination{Name: name}
+}
+
+// destToPoints converts the coordinates of a destination from document
+// units to points.
+func (gp *GoPdf) destToPoints(d Destination) Destination {
+	gp.UnitsToPointsVar(&d.Left, &d.Top, &d.Right, &d.Bottom)
+	return d
+}
+
+// destToUnits converts the coordinates of a destination from points to
+// document units.
+func (gp *GoPdf) destToUnits(d Destination) Destination {
+	d.Left = gp.PointsToUnits(d.Left)
+	d.Top = gp.PointsToUnits(d.Top)
+	d.Right = gp.PointsToUnits(d.Right)
+	d.Bottom = gp.PointsToUnits(d.Bottom)
+	return d
+}
+
+// AddDestLink adds a link on the current page that goes to a destination
+// of the document, either explicit (page and view) or named.
+//
+// Example:
+//
+//	pdf.AddDestLink(gopdf.Destination{Page: 3, Fit: gopdf.DestFitH, Top: 100}, 50, 100, 120, 20)
+//	pdf.AddDestLink(gopdf.Destination{Name: "section-4.2"}, 50, 130, 120, 20)
+func (gp *GoPdf) AddDestLink(dest Destination, x, y, w, h float64) {
+	gp.UnitsToPointsVar(&x, &y, &w, &h)
+	dest = gp.destToPoints(dest)
+	gp.addLink(linkOption{x: x, y: gp.config.PageSize.H - y, w: w, h: h, dest: &dest})
+}
+
+// AddOutlineWithDest adds an outline item that goes to a destination of the
+// document instead of the current page.
+//
+// Example:
+//
+//	pdf.AddOutlineWithDest("Overview", gopdf.Destination{Page: 1, Fit: gopdf.DestFit})
+func (gp *GoPdf) AddOutlineWithDest(title string, dest Destination) *OutlineObj {
+	o := gp.AddOutlineWithPosition(title)
+	o.setDestination(gp.destToPoints(dest), func() *GoPdf { return gp })
+	return o
+}
+
+func (o *OutlineObj) setDestination(dest Destination, getRoot func() *GoPdf) {
+	o.destination = &dest
+	o.getRoot = getRoot
+	if dest.Page > 0 && dest.Name == "" {
+		if id := getRoot().pageObjIDByNumber(dest.Page); id > 0 {
+			o.dest = id
+		}
+	}
+}
+
+func (o annotObj) writeDestLink(w io.Writer, l linkOption, objID int) error {
+	dest := actionWriter{o.GetRoot(), objID}.dest(*l.dest, true)
+	if dest == "" {
+		return nil
+	}
+	_, err := fmt.Fprintf(w, "<</Type /Annot /Subtype /Link /Rect [%.2f %.2f %.2f %.2f] /Border [0 0 0] /Dest %s>>\n",
+		l.x, l.y, l.x+l.w, l.y-l.h, dest)
+	return err
+}
+
+// ============================================================
+// Reading named destinations
+// ============================================================
+
+// readNamedDests returns the explicit destination arrays of the named
+// destinations of a PDF, from the /Dests name tree of the /Names
+// dictionary and the /Dests dictionary of the catalog.
+func readNamedDests(store *pdfObjectStore) map[string]string {
+	dests := make(map[string]string)
+	explicit := func(value string) string {
+		value = store.resolve(value)
+		if dict := store.resolveDict(value); dict != "" {
+			value = store.resolve(pdfDictGet(dict, "/D"))
+		}
+		if !strings.HasPrefix(strings.TrimSpace(value), "[") {
+			return ""
+		}
+		return strings.TrimSpace(value)
+	}
+	catalog := store.dict(store.catalog())
+	if old := store.resolveDict(pdfDictGet(catalog, "/Dests")); old != "" {
+		for _, e := range parsePDFDict(old) {
+			if d := explicit(e.value); d != "" {
+				dests[pdfNameValue(e.key)] = d
+			}
+		}
+	}
+	names := store.resolveDict(pdfDictGet(catalog, "/Names"))
+	var walk func(value string, depth int)
+	walk = func(value string, depth int) {
+		node := store.resolveDict(value)
+		if node == "" || depth > 32 {
+			return
+		}
+		items := parsePDFArray(store.resolve(pdfDictGet(node, "/Names")))
+		for i := 0; i+1 < len(items); i += 2 {
+			if d := explicit(items[i+1]); d != "" {
+				dests[decodePDFTextString(store.resolve(items[i]))] = d
+			}
+		}
+		for _, kid := range parsePDFArray(store.resolve(pdfDictGet(node, "/Kids"))) {
+			walk(kid, depth+1)
+		}
+	}
+	walk(pdfDictGet(names, "/Dests"), 0)
+	return dests
+}
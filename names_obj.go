@@ -6,9 +6,12 @@ import (
 )
 
 // namesObj is the PDF Names dictionary object.
-// It holds the EmbeddedFiles name tree.
+// It holds the EmbeddedFiles, Dests and JavaScript name trees.
 type namesObj struct {
 	embeddedFiles []embeddedFileRef
+	dests         []string         // sorted destination names
+	javaScripts   []documentScript // sorted by name
+	getRoot       func() *GoPdf
 }
 
 func (n namesObj) init(f func() *GoPdf) {}
@@ -28,6 +31,28 @@ func (n namesObj) write(w io.Writer, objID int) error {
 		io.WriteString(w, "    ]\n")
 		io.WriteString(w, "  >>\n")
 	}
+	if len(n.dests) > 0 {
+		aw := actionWriter{n.getRoot(), objID}
+		io.WriteString(w, "  /Dests <<\n")
+		io.WriteString(w, "    /Names [\n")
+		for _, name := range n.dests {
+			if d := aw.dest(n.getRoot().namedDest(name), true); d != "" {
+				fmt.Fprintf(w, "      %s %s\n", aw.str(name), d)
+			}
+		}
+		io.WriteString(w, "    ]\n")
+		io.WriteString(w, "  >>\n")
+	}
+	if len(n.javaScripts) > 0 {
+		aw := actionWriter{n.getRoot(), objID}
+		io.WriteString(w, "  /JavaScript <<\n")
+		io.WriteString(w, "    /Names [\n")
+		for _, js := range n.javaScripts {
+			fmt.Fprintf(w, "      %s << /S /JavaScript /JS %s >>\n", aw.str(js.name), aw.str(js.script))
+		}
+		io.WriteString(w, "    ]\n")
+		io.WriteString(w, "  >>\n")
+	}
 	io.WriteString(w, ">>\n")
 	return nil
 }
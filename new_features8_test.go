@@ -0,0 +1,3884 @@
+package gopdf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"math"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/VantageDataChat/GoPDF2/fontmaker/core"
+	"golang.org/x/image/bmp"
+	"golang.org/x/image/tiff"
+)
+
+// ============================================================
+// Tests for the second batch of features:
+// - SVG text, clipPath, mask and embedded images
+// - HTML tables and CSS box model in InsertHTMLBox
+// - HTML flow across boxes, frames and pages
+// - HTML style sheets
+// - Annotation appearance streams
+// - Filling AcroForms of existing PDFs
+// - Radio groups, list boxes and push buttons
+// - Form field appearance streams and scripts
+// - FDF and XFDF form data import and export
+// - Typed actions for links, outlines, form fields and pages
+// - Named destinations and destination view modes
+// - Printed table of contents pages
+// - Page tokens in headers and footers
+// - Document-level JavaScript and additional actions
+// - Page transitions, auto-advance and article threads
+// - Unicode line breaking with kinsoku rules
+// - Hyphenation with TeX patterns
+// - Justification and paragraph layout
+// - Bidirectional text
+// - Font fallback chains
+// - Standard 14 fonts
+// - TrueType collections and variable fonts
+// - Vertical writing
+// - Text render modes, rise, scaling, word spacing and decorations
+// - 16-bit, interlaced and ICC-tagged PNG, TIFF and BMP images
+// ============================================================
+
+// ============================================================
+// SVG text / clipPath / mask / image tests
+// ============================================================
+
+func TestImageSVG_TextAndTspan(t *testing.T) {
+	ensureOutDir(t)
+	pdf := newPDFWithFont(t)
+	pdf.SetNoCompression()
+	pdf.AddPage()
+
+	svgData := []byte(`<svg xmlns="http://www.w3.org/2000/svg" width="200" height="100">
+  <g font-family="Helvetica, sans-serif" font-size="14" fill="#336699">
+    <text x="100" y="20" text-anchor="middle">Total <tspan font-weight="bold" fill="red">42</tspan></text>
+    <text x="190" y="60" text-anchor="end">Right</text>
+  </g>
+</svg>`)
+
+	err := pdf.ImageSVGFromBytes(svgData, SVGOption{X: 50, Y: 50, Width: 200, FontFamily: fontFamily})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pdf.curr.FontSize != 14 {
+		t.Errorf("font state not restored, size = %v", pdf.curr.FontSize)
+	}
+
+	out, err := pdf.GetBytesPdfReturnErr()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Contains(out, []byte(" Tf")) {
+		t.Error("expected text operators in content stream")
+	}
+	if err := os.WriteFile(resOutDir+"/test_svg_text.pdf", out, 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestImageSVG_TextWithoutFontIsSkipped(t *testing.T) {
+	pdf := &GoPdf{}
+	pdf.Start(Config{PageSize: *PageSizeA4})
+	pdf.AddPage()
+
+	svgData := []byte(`<svg xmlns="http://www.w3.org/2000/svg" width="100" height="100">
+  <text x="10" y="20">Hello</text>
+</svg>`)
+	if err := pdf.ImageSVGFromBytes(svgData, SVGOption{X: 0, Y: 0}); err != nil {
+		t.Fatalf("expected text to be skipped without error, got %v", err)
+	}
+}
+
+func TestParseSVGTextRuns(t *testing.T) {
+	doc, err := parseSVG([]byte(`<svg xmlns="http://www.w3.org/2000/svg" width="100" height="100">
+  <text x="10" y="20" font-size="12">
+    Hello <tspan dx="5" font-style="italic">big</tspan> world
+  </text>
+</svg>`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(doc.elements) != 1 {
+		t.Fatalf("expected 1 element, got %d", len(doc.elements))
+	}
+	runs := doc.elements[0].runs
+	if len(runs) != 3 {
+		t.Fatalf("expected 3 runs, got %d", len(runs))
+	}
+	if runs[0].text != "Hello " || !runs[0].hasX || runs[0].x != 10 || runs[0].y != 20 {
+		t.Errorf("unexpected first run: %+v", runs[0])
+	}
+	if runs[1].text != "big" || runs[1].dx != 5 || !runs[1].style.italic {
+		t.Errorf("unexpected tspan run: %+v", runs[1])
+	}
+	if runs[2].text != " world" || runs[2].style.italic {
+		t.Errorf("unexpected last run: %+v", runs[2])
+	}
+}
+
+func TestImageSVG_ClipPath(t *testing.T) {
+	pdf := &GoPdf{}
+	pdf.Start(Config{PageSize: *PageSizeA4})
+	pdf.SetNoCompression()
+	pdf.AddPage()
+
+	svgData := []byte(`<svg xmlns="http://www.w3.org/2000/svg" width="100" height="100">
+  <defs>
+    <clipPath id="round"><circle cx="50" cy="50" r="40"/></clipPath>
+  </defs>
+  <rect x="0" y="0" width="100" height="100" fill="blue" clip-path="url(#round)"/>
+</svg>`)
+	if err := pdf.ImageSVGFromBytes(svgData, SVGOption{X: 10, Y: 10}); err != nil {
+		t.Fatal(err)
+	}
+	out, err := pdf.GetBytesPdfReturnErr()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Contains(out, []byte(" c ")) || !bytes.Contains(out, []byte("W n")) {
+		t.Error("expected curved clipping path in content stream")
+	}
+}
+
+func TestImageSVG_Mask(t *testing.T) {
+	pdf := &GoPdf{}
+	pdf.Start(Config{PageSize: *PageSizeA4})
+	pdf.SetNoCompression()
+	pdf.AddPage()
+
+	svgData := []byte(`<svg xmlns="http://www.w3.org/2000/svg" width="100" height="100">
+  <mask id="m"><rect x="0" y="0" width="50" height="100" fill="white"/></mask>
+  <rect x="0" y="0" width="100" height="100" fill="red" mask="url(#m)"/>
+</svg>`)
+	if err := pdf.ImageSVGFromBytes(svgData, SVGOption{X: 10, Y: 10}); err != nil {
+		t.Fatal(err)
+	}
+	out, err := pdf.GetBytesPdfReturnErr()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Contains(out, []byte("/SMask")) || !bytes.Contains(out, []byte("/Luminosity")) {
+		t.Error("expected luminosity soft mask")
+	}
+	if !bytes.Contains(out, []byte("1.000 g")) {
+		t.Error("expected white mask shape painted at full luminance")
+	}
+}
+
+func TestImageSVG_DataURIImage(t *testing.T) {
+	png, err := os.ReadFile(resPNGPath2)
+	if err != nil {
+		t.Skipf("image not available: %v", err)
+	}
+	pdf := &GoPdf{}
+	pdf.Start(Config{PageSize: *PageSizeA4})
+	pdf.AddPage()
+
+	svgData := []byte(`<svg xmlns="http://www.w3.org/2000/svg" xmlns:xlink="http://www.w3.org/1999/xlink" width="200" height="200">
+  <image x="10" y="10" width="100" height="50" xlink:href="data:image/png;base64,` +
+		base64.StdEncoding.EncodeToString(png) + `"/>
+</svg>`)
+	if err := pdf.ImageSVGFromBytes(svgData, SVGOption{X: 0, Y: 0}); err != nil {
+		t.Fatal(err)
+	}
+	if pdf.curr.CountOfImg != 1 {
+		t.Errorf("expected 1 image, got %d", pdf.curr.CountOfImg)
+	}
+}
+
+func TestImageSVG_ImageHrefs(t *testing.T) {
+	png, err := os.ReadFile(resPNGPath2)
+	if err != nil {
+		t.Skipf("image not available: %v", err)
+	}
+	dir := t.TempDir()
+	if err := os.WriteFile(dir+"/a.png", png, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	svgData := []byte(`<svg xmlns="http://www.w3.org/2000/svg" width="200" height="200">
+  <image x="0" y="0" width="50" height="50" href="https://example.com/a.png"/>
+  <image x="0" y="0" width="50" height="50" href="../a.png"/>
+  <image x="0" y="0" width="50" height="50" href="` + dir + `/a.png"/>
+  <image x="0" y="0" width="50" height="50" href="missing.png"/>
+  <image x="0" y="0" width="50" height="50" href="a.png"/>
+  <rect x="0" y="0" width="10" height="10" fill="red"/>
+</svg>`)
+
+	for _, tc := range []struct {
+		baseDir string
+		images  int
+	}{{"", 0}, {dir, 1}} {
+		pdf := &GoPdf{}
+		pdf.Start(Config{PageSize: *PageSizeA4})
+		pdf.AddPage()
+		if err := pdf.ImageSVGFromBytes(svgData, SVGOption{ImageBaseDir: tc.baseDir}); err != nil {
+			t.Fatalf("base dir %q: %v", tc.baseDir, err)
+		}
+		if pdf.curr.CountOfImg != tc.images {
+			t.Errorf("base dir %q: %d images, want %d", tc.baseDir, pdf.curr.CountOfImg, tc.images)
+		}
+	}
+}
+
+func TestSVGPathSegments(t *testing.T) {
+	segs := svgPathSegments("M10 10 h 20 v20 Q 40 40 50 30 T 70 30 a5 5 0 0 1 5 5 z")
+	ops := ""
+	for _, s := range segs {
+		ops += string(s.op)
+	}
+	if ops != "MLLCCCZ" {
+		t.Fatalf("unexpected ops %q", ops)
+	}
+	if end := segs[4].points[2]; end.X != 70 || end.Y != 30 {
+		t.Errorf("unexpected T end point %+v", end)
+	}
+	if end := segs[5].points[2]; end.X != 75 || end.Y != 35 {
+		t.Errorf("unexpected arc end point %+v", end)
+	}
+}
+
+func TestSVGArcCurves(t *testing.T) {
+	// the upper half of the circle of radius 50 around (50, 50)
+	segs := svgPathSegments("M0 50 A50 50 0 0 1 100 50")
+	if len(segs) != 3 {
+		t.Fatalf("%d segments, want a move and two quarter arcs", len(segs))
+	}
+	near := func(p Point, x, y float64) bool {
+		return math.Abs(p.X-x) < 1e-6 && math.Abs(p.Y-y) < 1e-6
+	}
+	if top := segs[1].points[2]; !near(top, 50, 0) {
+		t.Errorf("arc passes through %+v, want (50, 0)", top)
+	}
+	if c := segs[1].points[0]; !near(c, 0, 50-50*svgKappa) {
+		t.Errorf("first control point = %+v", c)
+	}
+	// the large arc of the other sweep goes around the bottom instead
+	segs = svgPathSegments("M0 50 A50 50 0 1 0 100 50")
+	if bottom := segs[1].points[2]; !near(bottom, 50, 100) {
+		t.Errorf("arc passes through %+v, want (50, 100)", bottom)
+	}
+	// radii too small for the end points are scaled up
+	segs = svgPathSegments("M0 0 A1 1 0 0 1 10 0")
+	if mid := segs[1].points[2]; !near(mid, 5, -5) {
+		t.Errorf("scaled arc passes through %+v, want (5, -5)", mid)
+	}
+
+	pdf := &GoPdf{}
+	pdf.Start(Config{PageSize: *PageSizeA4})
+	pdf.SetNoCompression()
+	pdf.AddPage()
+	svgData := []byte(`<svg xmlns="http://www.w3.org/2000/svg" width="100" height="100">
+  <path d="M0 50 A50 50 0 0 1 100 50 Z" fill="red" stroke="blue"/>
+  <path d="M0 0 L 10 10" stroke="blue" fill="none"/>
+</svg>`)
+	if err := pdf.ImageSVGFromBytes(svgData, SVGOption{}); err != nil {
+		t.Fatal(err)
+	}
+	out := string(pdf.GetBytesPdf())
+	for _, want := range []string{" c h B\n", "0.00 842.00 m 10.00 832.00 l S\n"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output lacks %q", want)
+		}
+	}
+}
+
+// ============================================================
+// HTML tables / box model tests
+// ============================================================
+
+func TestInsertHTMLBox_Table(t *testing.T) {
+	ensureOutDir(t)
+	pdf := newPDFWithFont(t)
+	pdf.SetNoCompression()
+	pdf.AddPage()
+
+	html := `<table border="1" style="width: 400px; background-color: #eeeeee">
+  <thead><tr><th>Name</th><th colspan="2">Score</th></tr></thead>
+  <tbody>
+    <tr style="background-color: #ffeecc"><td rowspan="2">Alice</td><td>10</td><td>20</td></tr>
+    <tr><td style="width: 50%">30</td><td valign="bottom">40</td></tr>
+  </tbody>
+</table><p>After table</p>`
+	endY, err := pdf.InsertHTMLBox(50, 50, 495, 700, html, HTMLBoxOption{DefaultFontFamily: fontFamily})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if endY <= 100 {
+		t.Errorf("expected table to advance cursor, got %f", endY)
+	}
+	if err := pdf.WritePdf(resOutDir + "/html_table.pdf"); err != nil {
+		t.Fatal(err)
+	}
+	b := pdf.GetBytesPdf()
+	for _, want := range []string{"0.933 0.933 0.933 rg", "1.000 0.933 0.800 rg"} {
+		if !bytes.Contains(b, []byte(want)) {
+			t.Errorf("missing background fill %q", want)
+		}
+	}
+	text, err := ExtractPageText(b, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"Name", "Score", "Alice", "40", "After table"} {
+		if !bytes.Contains([]byte(text), []byte(want)) {
+			t.Errorf("page text missing %q: %q", want, text)
+		}
+	}
+}
+
+func TestInsertHTMLBox_BoxModel(t *testing.T) {
+	pdf := newPDFWithFont(t)
+	pdf.SetNoCompression()
+	pdf.AddPage()
+
+	plainY, err := pdf.InsertHTMLBox(50, 50, 400, 700, "<div>Text</div>", HTMLBoxOption{DefaultFontFamily: fontFamily})
+	if err != nil {
+		t.Fatal(err)
+	}
+	boxedY, err := pdf.InsertHTMLBox(50, 50, 400, 700,
+		`<div style="margin: 10pt 0; padding: 5pt; border: 2pt solid red; background: yellow">Text</div>`,
+		HTMLBoxOption{DefaultFontFamily: fontFamily})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// margins replace the default spacing: 2*10 + 2*5 + 2*2 versus 2*0.3*12
+	if got, want := boxedY-plainY, 34-2*0.3*12; got < want-0.01 || got > want+0.01 {
+		t.Errorf("box model height difference = %f, want %f", got, want)
+	}
+	b := pdf.GetBytesPdf()
+	if !bytes.Contains(b, []byte("1.000 1.000 0.000 rg")) {
+		t.Error("missing yellow background")
+	}
+	if !bytes.Contains(b, []byte("1.000 0.000 0.000 RG")) {
+		t.Error("missing red border")
+	}
+}
+
+func TestHTMLRenderer_ParseBox(t *testing.T) {
+	pdf := newPDFWithFont(t)
+	r := &htmlRenderer{gp: pdf}
+	box := r.parseBox(map[string]string{
+		"margin":        "1pt 2pt 3pt",
+		"padding-left":  "8px",
+		"border-bottom": "thick dashed #00ff00",
+		"width":         "50%",
+	}, htmlBox{}, 200)
+	if box.margin != (htmlEdges{1, 2, 3, 2}) {
+		t.Errorf("margin = %+v", box.margin)
+	}
+	if box.padding.left != 6 {
+		t.Errorf("padding-left = %f", box.padding.left)
+	}
+	if box.border.bottom != 2 || box.borderColor.bottom != (RGBColor{0, 255, 0}) {
+		t.Errorf("border = %+v %+v", box.border, box.borderColor)
+	}
+	if !box.hasWidth || box.width != 100 {
+		t.Errorf("width = %f", box.width)
+	}
+
+	box = r.parseBox(map[string]string{
+		"border-top":         "1px solid red",
+		"border-left":        "2px solid blue",
+		"border-right-color": "#00ff00",
+	}, htmlBox{}, 200)
+	want := htmlEdgeColors{top: RGBColor{255, 0, 0}, right: RGBColor{0, 255, 0}, left: RGBColor{0, 0, 255}}
+	if box.borderColor != want {
+		t.Errorf("border colors = %+v, want %+v", box.borderColor, want)
+	}
+	box = r.parseBox(map[string]string{"border-color": "red blue"}, htmlBox{}, 200)
+	want = htmlEdgeColors{RGBColor{255, 0, 0}, RGBColor{0, 0, 255}, RGBColor{255, 0, 0}, RGBColor{0, 0, 255}}
+	if box.borderColor != want {
+		t.Errorf("border-color shorthand = %+v, want %+v", box.borderColor, want)
+	}
+}
+
+func TestHTMLBoxMixedBorderColors(t *testing.T) {
+	html := `<div style="border-top: 2px solid red; border-left: 3px solid blue">x</div>`
+	var first string
+	for i := 0; i < 20; i++ {
+		pdf := newPDFWithFont(t)
+		pdf.SetNoCompression()
+		pdf.AddPage()
+		if _, err := pdf.InsertHTMLBox(10, 10, 300, 200, html, HTMLBoxOption{DefaultFontFamily: fontFamily}); err != nil {
+			t.Fatal(err)
+		}
+		out := string(pdf.GetBytesPdf())
+		red := strings.Index(out, "1.000 0.000 0.000 RG")
+		blue := strings.Index(out, "0.000 0.000 1.000 RG")
+		if red < 0 || blue < 0 || red > blue {
+			t.Fatalf("top border not red and left border not blue (red at %d, blue at %d)", red, blue)
+		}
+		if i == 0 {
+			first = out[red:]
+		} else if out[red:] != first {
+			t.Fatal("border output differs between runs")
+		}
+	}
+}
+
+// ============================================================
+// HTML flow tests
+// ============================================================
+
+func TestHTMLFlow_RenderBoxRemainder(t *testing.T) {
+	pdf := newPDFWithFont(t)
+	pdf.AddPage()
+
+	var sb strings.Builder
+	sb.WriteString("<ol>")
+	for i := 1; i <= 6; i++ {
+		sb.WriteString("<li>Item</li>")
+	}
+	sb.WriteString("</ol><table><thead><tr><th>Head</th></tr></thead>")
+	for i := 1; i <= 6; i++ {
+		sb.WriteString("<tr><td>Row</td></tr>")
+	}
+	sb.WriteString("</table><p>one two three four five six seven eight nine ten</p>")
+	flow, err := pdf.NewHTMLFlow(sb.String(), HTMLBoxOption{DefaultFontFamily: fontFamily})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := flow.RenderBox(50, 50, 100, 60); err != nil {
+		t.Fatal(err)
+	}
+	if flow.Done() {
+		t.Fatal("expected content left after the first box")
+	}
+	if rest := flow.Remainder(); !strings.HasPrefix(rest, `<ol start="5"><li>Item</li><li>Item</li></ol>`) {
+		t.Errorf("unexpected remainder %q", rest)
+	}
+	if len(flow.nodes) == 0 || !flow.nodes[0].continued() {
+		t.Error("the split list is not kept as continued in the flow")
+	}
+
+	tableSplit := false
+	for i := 0; i < 20 && !flow.Done(); i++ {
+		if _, err := flow.RenderBox(50, 50, 100, 60); err != nil {
+			t.Fatal(err)
+		}
+		rest := flow.Remainder()
+		if strings.HasPrefix(rest, `<table><thead>`) {
+			tableSplit = true
+		}
+	}
+	if !flow.Done() {
+		t.Fatalf("flow not done, remainder %q", flow.Remainder())
+	}
+	if !tableSplit {
+		t.Error("expected the table to be split with a repeated header")
+	}
+}
+
+func TestHTMLFlow_RenderFrames(t *testing.T) {
+	pdf := newPDFWithFont(t)
+	pdf.SetNoCompression()
+	pdf.AddPage()
+
+	text := strings.Repeat("newsletter column text ", 40)
+	flow, err := pdf.NewHTMLFlow("<p>"+text+"</p>", HTMLBoxOption{DefaultFontFamily: fontFamily})
+	if err != nil {
+		t.Fatal(err)
+	}
+	frames := []HTMLFrame{
+		{X: 50, Y: 50, W: 150, H: 100},
+		{X: 220, Y: 50, W: 150, H: 100},
+		{X: 390, Y: 50, W: 150, H: 750},
+	}
+	if _, err := flow.RenderFrames(frames); err != nil {
+		t.Fatal(err)
+	}
+	if !flow.Done() {
+		t.Fatalf("flow not done, remainder %q", flow.Remainder())
+	}
+	got, err := ExtractPageText(pdf.GetBytesPdf(), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := strings.Count(got, "newsletter"); n != 40 {
+		t.Errorf("expected 40 words across the frames, got %d", n)
+	}
+}
+
+func TestHTMLFlow_RenderPages(t *testing.T) {
+	ensureOutDir(t)
+	pdf := newPDFWithFont(t)
+	pdf.SetMargins(40, 60, 40, 60)
+	headers := 0
+	pdf.AddHeader(func() {
+		headers++
+		pdf.SetXY(40, 20)
+		pdf.Cell(nil, "Header")
+	})
+
+	var sb strings.Builder
+	for i := 0; i < 60; i++ {
+		sb.WriteString("<p>Paragraph text that fills the page.</p>")
+	}
+	sb.WriteString(`<h2 style="page-break-before: always">Appendix</h2><p>Last</p>`)
+	flow, err := pdf.NewHTMLFlow(sb.String(), HTMLBoxOption{DefaultFontFamily: fontFamily})
+	if err != nil {
+		t.Fatal(err)
+	}
+	endY, err := flow.RenderPages()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !flow.Done() {
+		t.Fatal("flow not done")
+	}
+	pages := pdf.GetNumberOfPages()
+	if pages < 3 {
+		t.Fatalf("expected at least 3 pages, got %d", pages)
+	}
+	if headers != pages {
+		t.Errorf("header drawn %d times for %d pages", headers, pages)
+	}
+	if pdf.GetY() != endY {
+		t.Errorf("current Y %f, want %f", pdf.GetY(), endY)
+	}
+	if err := pdf.WritePdf(resOutDir + "/html_flow_pages.pdf"); err != nil {
+		t.Fatal(err)
+	}
+	last, err := ExtractPageText(pdf.GetBytesPdf(), pages-1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(last, "Appendix") || strings.Contains(last, "Paragraph") {
+		t.Errorf("expected the appendix alone on the last page, got %q", last)
+	}
+}
+
+func TestInsertHTMLBox_TallImageSkipped(t *testing.T) {
+	if _, err := os.Stat(resJPEGPath); err != nil {
+		t.Skipf("image not available: %v", err)
+	}
+	pdf := newPDFWithFont(t)
+	pdf.SetNoCompression()
+	pdf.AddPage()
+	// the image is taller than the whole box, so it is skipped and the
+	// text after it is still rendered
+	html := `<p>Before</p><img src="` + resJPEGPath + `" width="50" height="500"><p>After</p>`
+	if _, err := pdf.InsertHTMLBox(50, 50, 300, 100, html, HTMLBoxOption{DefaultFontFamily: fontFamily}); err != nil {
+		t.Fatal(err)
+	}
+	if pdf.curr.CountOfImg != 0 {
+		t.Errorf("%d images drawn, want the tall image skipped", pdf.curr.CountOfImg)
+	}
+	text, err := ExtractPageText(pdf.GetBytesPdf(), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(text, "Before") || !strings.Contains(text, "After") {
+		t.Errorf("page text = %q, want the text around the image", text)
+	}
+
+	// an HTMLFlow keeps the image for the next frame instead
+	flow, err := pdf.NewHTMLFlow(html, HTMLBoxOption{DefaultFontFamily: fontFamily})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := flow.RenderBox(50, 200, 300, 100); err != nil {
+		t.Fatal(err)
+	}
+	if rest := flow.Remainder(); !strings.HasPrefix(rest, "<img ") {
+		t.Errorf("remainder = %q, want the image first", rest)
+	}
+}
+
+func TestHTMLFlow_RemainderKeepsStyles(t *testing.T) {
+	pdf := newPDFWithFont(t)
+	pdf.AddPage()
+	html := `<style>p.big { font-size: 30px; color: red }</style>
+<p class="big">First</p><p class="big" style="margin-top: 2px">Second</p>`
+	flow, err := pdf.NewHTMLFlow(html, HTMLBoxOption{DefaultFontFamily: fontFamily, CSS: "p { background-color: #00ff00 }"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := flow.RenderBox(50, 50, 300, 45); err != nil {
+		t.Fatal(err)
+	}
+	rest := flow.Remainder()
+	if want := `<p class="big" style="background-color: #00ff00; color: red; font-size: 30px; margin-top: 2px">Second</p>`; !strings.Contains(rest, want) || strings.Contains(rest, "First") {
+		t.Fatalf("remainder = %q, want %q", rest, want)
+	}
+
+	// The remainder renders with the styles, without the style sheets.
+	again := newPDFWithFont(t)
+	again.SetNoCompression()
+	again.AddPage()
+	if _, err := again.InsertHTMLBox(50, 50, 300, 200, rest, HTMLBoxOption{DefaultFontFamily: fontFamily}); err != nil {
+		t.Fatal(err)
+	}
+	b := again.GetBytesPdf()
+	for _, want := range []string{"1.000 0.000 0.000 rg", "0.000 1.000 0.000 rg", " 22.5 Tf"} {
+		if !bytes.Contains(b, []byte(want)) {
+			t.Errorf("re-rendered remainder lacks %q", want)
+		}
+	}
+}
+
+// ============================================================
+// HTML style sheet tests
+// ============================================================
+
+func TestInsertHTMLBox_StyleSheet(t *testing.T) {
+	pdf := newPDFWithFont(t)
+	pdf.SetNoCompression()
+	pdf.AddPage()
+
+	html := `<style>.warn { color: #ff0000 } div.box > p { background-color: #00ff00 }</style>
+<div class="box"><p>Plain <b class="warn">alert</b></p></div>`
+	_, err := pdf.InsertHTMLBox(50, 50, 400, 300, html, HTMLBoxOption{
+		DefaultFontFamily: fontFamily,
+		CSS:               "p { font-size: 20pt }",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := pdf.GetBytesPdf()
+	if !bytes.Contains(b, []byte("1.000 0.000 0.000 rg")) {
+		t.Error("class color not applied")
+	}
+	if !bytes.Contains(b, []byte("0.000 1.000 0.000 rg")) {
+		t.Error("child selector background not applied")
+	}
+	if !bytes.Contains(b, []byte(" 20 Tf")) {
+		t.Error("external CSS font size not applied")
+	}
+	if bytes.Contains(b, []byte("warn")) {
+		t.Error("style element rendered as text")
+	}
+}
+
+// ============================================================
+// Annotation appearance stream tests
+// ============================================================
+
+func TestAnnotationAppearance_AllTypes(t *testing.T) {
+	pdf := newPDFWithFont(t)
+	pdf.SetNoCompression()
+	pdf.AddPage()
+
+	ic := [3]uint8{200, 220, 255}
+	opts := []AnnotationOption{
+		{Type: AnnotText, X: 20, Y: 20, W: 24, H: 24, Content: "note"},
+		{Type: AnnotHighlight, X: 60, Y: 20, W: 100, H: 14},
+		{Type: AnnotUnderline, X: 60, Y: 40, W: 100, H: 14},
+		{Type: AnnotStrikeOut, X: 60, Y: 60, W: 100, H: 14},
+		{Type: AnnotSquiggly, X: 60, Y: 80, W: 100, H: 14},
+		{Type: AnnotSquare, X: 20, Y: 100, W: 60, H: 40, InteriorColor: &ic},
+		{Type: AnnotCircle, X: 100, Y: 100, W: 60, H: 40},
+		{Type: AnnotFreeText, X: 20, Y: 150, W: 150, H: 40, Content: "free text"},
+		{Type: AnnotInk, X: 20, Y: 200, W: 50, H: 50, InkList: [][]Point{{{X: 20, Y: 200}, {X: 70, Y: 250}}}},
+		{Type: AnnotPolyline, Vertices: []Point{{X: 100, Y: 200}, {X: 150, Y: 250}, {X: 200, Y: 200}},
+			LineEndingStyles: [2]LineEndingStyle{LineEndNone, LineEndClosedArrow}},
+		{Type: AnnotPolygon, Vertices: []Point{{X: 220, Y: 200}, {X: 270, Y: 250}, {X: 320, Y: 200}}},
+		{Type: AnnotLine, LineStart: Point{X: 20, Y: 300}, LineEnd: Point{X: 200, Y: 300},
+			LineEndingStyles: [2]LineEndingStyle{LineEndCircle, LineEndOpenArrow}},
+		{Type: AnnotStamp, X: 20, Y: 320, W: 180, H: 50, Stamp: StampApproved},
+		{Type: AnnotCaret, X: 220, Y: 320, W: 10, H: 12},
+		{Type: AnnotFileAttachment, X: 240, Y: 320, W: 24, H: 24, FileName: "a.txt"},
+		{Type: AnnotRedact, X: 280, Y: 320, W: 60, H: 20, Color: [3]uint8{255, 0, 0}},
+	}
+	for _, opt := range opts {
+		pdf.AddAnnotation(opt)
+	}
+
+	b := pdf.GetBytesPdf()
+	if n := bytes.Count(b, []byte("/AP << /N ")); n != len(opts) {
+		t.Errorf("expected %d appearance streams, got %d", len(opts), n)
+	}
+	if n := bytes.Count(b, []byte("/Subtype /Form")); n != len(opts) {
+		t.Errorf("expected %d form xobjects, got %d", len(opts), n)
+	}
+	if !bytes.Contains(b, []byte("(APPROVED) Tj")) || !bytes.Contains(b, []byte("/BaseFont /Helvetica-Bold")) {
+		t.Error("stamp artwork missing")
+	}
+	if !bytes.Contains(b, []byte("/BM /Multiply")) {
+		t.Error("highlight should use the multiply blend mode")
+	}
+	// the horizontal line gets a rectangle that contains its endings
+	if bytes.Contains(b, []byte("/BBox [0 0 180.00 0.00]")) {
+		t.Error("line appearance has an empty bounding box")
+	}
+}
+
+func TestAnnotationAppearance_FreeTextTTF(t *testing.T) {
+	pdf := newPDFWithFont(t)
+	pdf.SetNoCompression()
+	pdf.AddPage()
+
+	pdf.AddAnnotation(AnnotationOption{
+		Type:       AnnotFreeText,
+		X:          50,
+		Y:          50,
+		W:          120,
+		H:          80,
+		Content:    "The quick brown fox jumps over the lazy dog",
+		FontFamily: fontFamily,
+		FontSize:   12,
+		Color:      [3]uint8{0, 0, 128},
+	})
+	b := pdf.GetBytesPdf()
+	if !bytes.Contains(b, []byte("/Font << /F1 ")) {
+		t.Fatal("TTF font not referenced by the appearance")
+	}
+	if bytes.Contains(b, []byte("/BaseFont /Helvetica ")) {
+		t.Error("Helvetica used although a TTF family was given")
+	}
+	if n := bytes.Count(b, []byte(" Tm\n<")); n < 2 {
+		t.Errorf("expected the text to wrap onto several lines, got %d", n)
+	}
+
+	widths := func(s string) float64 { return float64(len(s)) }
+	lines := wrapAnnotationText("aa bb cccccc\ndd", 5, widths)
+	want := []string{"aa bb", "ccccc", "c", "dd"}
+	if fmt.Sprint(lines) != fmt.Sprint(want) {
+		t.Errorf("wrapAnnotationText = %q, want %q", lines, want)
+	}
+}
+
+func TestAnnotationAppearance_OpacityAndBorderStyle(t *testing.T) {
+	pdf := newPDFWithFont(t)
+	pdf.SetNoCompression()
+	pdf.AddPage()
+
+	pdf.AddAnnotation(AnnotationOption{
+		Type:        AnnotSquare,
+		X:           50,
+		Y:           50,
+		W:           100,
+		H:           60,
+		Color:       [3]uint8{0, 0, 255},
+		Opacity:     0.5,
+		BorderWidth: 2,
+		BorderStyle: AnnotBorderDashed,
+		DashPattern: []float64{4, 2},
+	})
+	b := pdf.GetBytesPdf()
+	for _, want := range []string{
+		"/CA 0.5000 /ca 0.5000",
+		"/BS << /W 2.00 /S /D /D [4.00 2.00] >>",
+		"[4.00 2.00] 0 d",
+		"0.0000 0.0000 1.0000 RG",
+	} {
+		if !bytes.Contains(b, []byte(want)) {
+			t.Errorf("missing %q", want)
+		}
+	}
+
+	// the appearance follows modifications
+	if err := pdf.ModifyAnnotation(0, 0, AnnotationOption{Color: [3]uint8{255, 0, 0}}); err != nil {
+		t.Fatal(err)
+	}
+	b = pdf.GetBytesPdf()
+	if !bytes.Contains(b, []byte("1.0000 0.0000 0.0000 RG")) {
+		t.Error("appearance not regenerated after ModifyAnnotation")
+	}
+}
+
+func TestBakeAnnotations_Appearance(t *testing.T) {
+	pdf := newPDFWithFont(t)
+	pdf.SetNoCompression()
+	pdf.AddPage()
+	pdf.AddStampAnnotation(100, 100, 150, 40, StampConfidential)
+	pdf.BakeAnnotations()
+
+	b := pdf.GetBytesPdf()
+	if bytes.Contains(b, []byte("/Subtype /Stamp")) {
+		t.Error("stamp annotation not removed")
+	}
+	if !bytes.Contains(b, []byte("(CONFIDENTIAL) Tj")) {
+		t.Error("stamp appearance not kept")
+	}
+	if !bytes.Contains(b, []byte("cm\n/I")) || !bytes.Contains(b, []byte(" Do\nQ")) {
+		t.Error("baked appearance not drawn in the page content")
+	}
+}
+
+func TestRenderPageToImage_AnnotationAppearance(t *testing.T) {
+	pdf := &GoPdf{}
+	pdf.Start(Config{PageSize: *PageSizeLetter})
+	pdf.AddPage()
+	pdf.AddAnnotation(AnnotationOption{
+		Type:  AnnotSquare,
+		X:     100,
+		Y:     100,
+		W:     50,
+		H:     50,
+		Color: [3]uint8{255, 0, 0},
+	})
+
+	img, err := RenderPageToImage(pdf.GetBytesPdf(), 0, RenderOption{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, g, bl, _ := img.At(100, 125).RGBA()
+	if got := (color.RGBA{uint8(r >> 8), uint8(g >> 8), uint8(bl >> 8), 255}); got != (color.RGBA{255, 0, 0, 255}) {
+		t.Errorf("annotation border not rendered, pixel = %v", got)
+	}
+}
+
+// ============================================================
+// AcroForm filling tests
+// ============================================================
+
+// buildTestPDF writes objs as objects 1..n with a classic xref table.
+func buildTestPDF(objs []string) []byte {
+	var buf bytes.Buffer
+	buf.WriteString("%PDF-1.7\n")
+	offsets := make([]int, len(objs))
+	for i, o := range objs {
+		offsets[i] = buf.Len()
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", i+1, o)
+	}
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(objs)+1)
+	for _, off := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", off)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objs)+1, xref)
+	return buf.Bytes()
+}
+
+// testFormObjects is a one-page form with a field hierarchy, a radio
+// group with separate widgets and a multi-select list box.
+var testFormObjects = []string{
+	"<< /Type /Catalog /Pages 2 0 R /AcroForm 16 0 R >>",
+	"<< /Type /Pages /Kids [3 0 R] /Count 1 /MediaBox [0 0 612 792] >>",
+	"<< /Type /Page /Parent 2 0 R /Contents 13 0 R /Annots [5 0 R 6 0 R 8 0 R 9 0 R 11 0 R] >>",
+	"<< /T (applicant) /Kids [5 0 R 6 0 R] >>",
+	"<< /T (name) /Parent 4 0 R /FT /Tx /MaxLen 20 /Subtype /Widget /Rect [50 700 250 720] /DA (/Helv 12 Tf 0 0 1 rg) /MK << /BC [0 0 0] /BG [1 1 0.8] >> >>",
+	"<< /T (city) /Parent 4 0 R /FT /Tx /Ff 4096 /Subtype /Widget /Rect [50 600 250 680] /Q 1 /V (Paris) >>",
+	"<< /T (color) /FT /Btn /Ff 49152 /V /red /Kids [8 0 R 9 0 R] >>",
+	"<< /Parent 7 0 R /Subtype /Widget /Rect [50 550 65 565] /AS /red /AP << /N << /red 14 0 R /Off 15 0 R >> >> >>",
+	"<< /Parent 7 0 R /Subtype /Widget /Rect [80 550 95 565] /AS /Off /AP << /N << /blue 14 0 R /Off 15 0 R >> >> >>",
+	"<< /T (langs) /FT /Ch /Ff 2097152 /Opt [[(en) (English)] [(fr) (French)] [(de) (German)]] /Kids [11 0 R] >>",
+	"<< /Parent 10 0 R /Subtype /Widget /Rect [300 500 450 560] /DA (/Helv 10 Tf 0 g) >>",
+	"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>",
+	"<< /Length 0 >>\nstream\n\nendstream",
+	"<< /Type /XObject /Subtype /Form /BBox [0 0 15 15] /Length 23 >>\nstream\n0 0 1 rg 2 2 11 11 re f\nendstream",
+	"<< /Type /XObject /Subtype /Form /BBox [0 0 15 15] /Length 0 >>\nstream\n\nendstream",
+	"<< /Fields [4 0 R 7 0 R 10 0 R] /DR << /Font << /Helv 12 0 R >> >> /DA (/Helv 0 Tf 0 g) /XFA 13 0 R >>",
+}
+
+func formFieldByName(t *testing.T, fields []FormFieldInfo, name string) FormFieldInfo {
+	t.Helper()
+	for _, f := range fields {
+		if f.Name == name {
+			return f
+		}
+	}
+	t.Fatalf("field %q not listed", name)
+	return FormFieldInfo{}
+}
+
+func TestListFormFields(t *testing.T) {
+	fields, err := ListFormFields(buildTestPDF(testFormObjects))
+	if err != nil {
+		t.Fatalf("ListFormFields: %v", err)
+	}
+	if len(fields) != 4 {
+		t.Fatalf("expected 4 fields, got %d", len(fields))
+	}
+
+	name := formFieldByName(t, fields, "applicant.name")
+	if name.Type != FormFieldText || name.MaxLen != 20 || len(name.Pages) != 1 || name.Pages[0] != 0 {
+		t.Errorf("unexpected applicant.name: %+v", name)
+	}
+	city := formFieldByName(t, fields, "applicant.city")
+	if city.Value != "Paris" || !city.Multiline {
+		t.Errorf("unexpected applicant.city: %+v", city)
+	}
+	color := formFieldByName(t, fields, "color")
+	if color.Type != FormFieldRadio || color.Value != "red" ||
+		strings.Join(color.Options, ",") != "red,blue" {
+		t.Errorf("unexpected color: %+v", color)
+	}
+	langs := formFieldByName(t, fields, "langs")
+	if langs.Type != FormFieldChoice || !langs.MultiSelect ||
+		strings.Join(langs.Options, ",") != "English,French,German" ||
+		strings.Join(langs.ExportValues, ",") != "en,fr,de" {
+		t.Errorf("unexpected langs: %+v", langs)
+	}
+
+	if _, err := ListFormFields(buildTestPDF(testFormObjects[:3])); err != ErrNoAcroForm {
+		t.Errorf("expected ErrNoAcroForm, got %v", err)
+	}
+}
+
+func TestFormFiller_Incremental(t *testing.T) {
+	data := buildTestPDF(testFormObjects)
+	filler, err := NewFormFiller(data)
+	if err != nil {
+		t.Fatalf("NewFormFiller: %v", err)
+	}
+	if err := filler.SetValue("applicant.name", "Jane (Doe)"); err != nil {
+		t.Fatalf("SetValue text: %v", err)
+	}
+	if err := filler.SetValue("applicant.city", "Zoë lives in a city with a rather long name"); err != nil {
+		t.Fatalf("SetValue multiline: %v", err)
+	}
+	if err := filler.SetValue("color", "blue"); err != nil {
+		t.Fatalf("SetValue radio: %v", err)
+	}
+	if err := filler.SetValues("langs", []string{"en", "German"}); err != nil {
+		t.Fatalf("SetValues: %v", err)
+	}
+	out, err := filler.Save(FormFillOption{Incremental: true})
+	if err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	if !bytes.HasPrefix(out, data) {
+		t.Fatal("incremental update must keep the original bytes")
+	}
+	update := string(out[len(data):])
+	if !strings.Contains(update, "/Prev ") || strings.Contains(update, "/XFA") {
+		t.Errorf("unexpected update trailer or AcroForm:\n%s", update)
+	}
+	if !strings.Contains(update, "(Jane \\(Doe\\)) Tj") || !strings.Contains(update, "0 0 1 rg") {
+		t.Error("text appearance should show the value in the /DA color")
+	}
+	if !strings.Contains(update, "/Tx BMC") || !strings.Contains(update, listBoxHighlight) {
+		t.Error("appearances should be marked content with highlighted list rows")
+	}
+
+	fields, err := ListFormFields(out)
+	if err != nil {
+		t.Fatalf("ListFormFields: %v", err)
+	}
+	if v := formFieldByName(t, fields, "applicant.name").Value; v != "Jane (Doe)" {
+		t.Errorf("name = %q", v)
+	}
+	if v := formFieldByName(t, fields, "applicant.city").Value; !strings.HasPrefix(v, "Zoë") {
+		t.Errorf("city = %q", v)
+	}
+	if v := formFieldByName(t, fields, "color").Value; v != "blue" {
+		t.Errorf("color = %q", v)
+	}
+	if v := formFieldByName(t, fields, "langs").Values; strings.Join(v, ",") != "en,de" {
+		t.Errorf("langs = %v", v)
+	}
+	if !strings.Contains(update, "/AS /blue") || !strings.Contains(update, "/AS /Off") {
+		t.Error("radio widgets should switch their appearance states")
+	}
+}
+
+func TestFormFiller_FlattenRewrite(t *testing.T) {
+	out, err := FillForm(buildTestPDF(testFormObjects), map[string]string{
+		"applicant.name": "Jane",
+		"color":          "blue",
+	}, FormFillOption{Flatten: true})
+	if err != nil {
+		t.Fatalf("FillForm: %v", err)
+	}
+	s := string(out)
+	if strings.Contains(s, "/AcroForm") || strings.Contains(s, "/Widget") || strings.Contains(s, "/Annots") {
+		t.Error("flattened output should not contain the form")
+	}
+	if strings.Count(s, " Do\n") != 5 {
+		t.Errorf("expected 5 flattened widgets, got %d", strings.Count(s, " Do\n"))
+	}
+	if IsFormPDF(out) {
+		t.Error("IsFormPDF should be false after flattening")
+	}
+	if _, err := RenderPageToImage(out, 0, RenderOption{DPI: 36}); err != nil {
+		t.Errorf("RenderPageToImage: %v", err)
+	}
+}
+
+func TestFormFiller_Errors(t *testing.T) {
+	filler, err := NewFormFiller(buildTestPDF(testFormObjects))
+	if err != nil {
+		t.Fatalf("NewFormFiller: %v", err)
+	}
+	if err := filler.SetValue("missing", "x"); err == nil {
+		t.Error("expected error for an unknown field")
+	}
+	if err := filler.SetValue("color", "green"); err == nil {
+		t.Error("expected error for an unknown radio state")
+	}
+	if err := filler.SetValue("langs", "Spanish"); err == nil {
+		t.Error("expected error for an unknown list option")
+	}
+	if err := filler.SetValue("applicant.name", strings.Repeat("x", 21)); err == nil {
+		t.Error("expected error for a value longer than MaxLen")
+	}
+	if err := filler.SetChecked("applicant.name", true); err == nil {
+		t.Error("expected error for SetChecked on a text field")
+	}
+}
+
+func TestFormFiller_GoPdfForm(t *testing.T) {
+	pdf := newPDFWithFont(t)
+	pdf.AddPage()
+	if err := pdf.AddTextField("name", 50, 50, 200, 20); err != nil {
+		t.Fatalf("AddTextField: %v", err)
+	}
+	if err := pdf.AddCheckbox("agree", 50, 100, 15, false); err != nil {
+		t.Fatalf("AddCheckbox: %v", err)
+	}
+	data, err := pdf.GetBytesPdfReturnErr()
+	if err != nil {
+		t.Fatalf("GetBytesPdfReturnErr: %v", err)
+	}
+	filler, err := NewFormFiller(data)
+	if err != nil {
+		t.Fatalf("NewFormFiller: %v", err)
+	}
+	if err := filler.SetValue("name", "Jane"); err != nil {
+		t.Fatalf("SetValue: %v", err)
+	}
+	if err := filler.SetChecked("agree", true); err != nil {
+		t.Fatalf("SetChecked: %v", err)
+	}
+	out, err := filler.Save(FormFillOption{})
+	if err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	s := string(out)
+	if !strings.Contains(s, "/BaseFont /ZapfDingbats") || !strings.Contains(s, "/N << /Yes ") {
+		t.Error("checkbox without appearances should get on and off appearances")
+	}
+	fields, err := ListFormFields(out)
+	if err != nil {
+		t.Fatalf("ListFormFields: %v", err)
+	}
+	if v := formFieldByName(t, fields, "agree").Value; v != "Yes" {
+		t.Errorf("agree = %q", v)
+	}
+	if v := formFieldByName(t, fields, "name").Value; v != "Jane" {
+		t.Errorf("name = %q", v)
+	}
+}
+
+func TestFormFiller_ObjectStreams(t *testing.T) {
+	// Fields stored in an object stream, indexed by a cross-reference stream.
+	acroForm := "<< /Fields [4 0 R] /DA (/Helv 0 Tf 0 g) >>\n"
+	widget := "<< /T (code) /FT /Tx /Ff 16777216 /MaxLen 4 /Subtype /Widget /Rect [50 700 130 720] /P 3 0 R >>\n"
+	header := fmt.Sprintf("5 0 4 %d ", len(acroForm))
+	objStm := fmt.Sprintf("<< /Type /ObjStm /N 2 /First %d /Length %d >>\nstream\n%s%s%s\nendstream",
+		len(header), len(header)+len(acroForm)+len(widget), header, acroForm, widget)
+
+	var buf bytes.Buffer
+	buf.WriteString("%PDF-1.7\n")
+	objs := []string{
+		"<< /Type /Catalog /Pages 2 0 R /AcroForm 5 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 /MediaBox [0 0 612 792] >>",
+		"<< /Type /Page /Parent 2 0 R /Annots [4 0 R] >>",
+	}
+	offsets := map[int]int{}
+	for i, o := range objs {
+		offsets[i+1] = buf.Len()
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", i+1, o)
+	}
+	offsets[6] = buf.Len()
+	fmt.Fprintf(&buf, "6 0 obj\n%s\nendobj\n", objStm)
+	xrefOffset := buf.Len()
+	var xref []byte
+	for num := 0; num <= 7; num++ {
+		switch {
+		case num == 4 || num == 5:
+			xref = append(xref, 2, 0, 0, 0, 6, 0, byte(5-num))
+		case num == 7:
+			xref = append(xref, 1, byte(xrefOffset>>24), byte(xrefOffset>>16), byte(xrefOffset>>8), byte(xrefOffset), 0, 0)
+		case num == 0:
+			xref = append(xref, 0, 0, 0, 0, 0, 0xFF, 0xFF)
+		default:
+			off := offsets[num]
+			xref = append(xref, 1, byte(off>>24), byte(off>>16), byte(off>>8), byte(off), 0, 0)
+		}
+	}
+	fmt.Fprintf(&buf, "7 0 obj\n<< /Type /XRef /Size 8 /W [1 4 2] /Root 1 0 R /Length %d >>\nstream\n", len(xref))
+	buf.Write(xref)
+	fmt.Fprintf(&buf, "\nendstream\nendobj\nstartxref\n%d\n%%%%EOF\n", xrefOffset)
+	data := buf.Bytes()
+
+	out, err := FillForm(data, map[string]string{"code": "AB12"}, FormFillOption{Incremental: true})
+	if err != nil {
+		t.Fatalf("FillForm: %v", err)
+	}
+	update := string(out[len(data):])
+	if !strings.Contains(update, "/Type /XRef") || !strings.Contains(update, fmt.Sprintf("/Prev %d", xrefOffset)) {
+		t.Errorf("update should end with a cross-reference stream:\n%s", update)
+	}
+	if strings.Count(update, " Tm\n") != 4 {
+		t.Error("comb field should place each character in its own cell")
+	}
+	fields, err := ListFormFields(out)
+	if err != nil {
+		t.Fatalf("ListFormFields: %v", err)
+	}
+	if v := formFieldByName(t, fields, "code").Value; v != "AB12" {
+		t.Errorf("code = %q", v)
+	}
+}
+
+// ============================================================
+// Radio group / list box / push button tests
+// ============================================================
+
+func TestAddRadioGroup(t *testing.T) {
+	pdf := newPDFWithFont(t)
+	pdf.AddPage()
+	buttons := []RadioButton{
+		{X: 50, Y: 100, W: 12, H: 12, Value: "S"},
+		{X: 80, Y: 100, W: 12, H: 12, Value: "M"},
+		{X: 110, Y: 100, W: 12, H: 12, Value: "L"},
+	}
+	if err := pdf.AddRadioGroup("size", buttons, "M"); err != nil {
+		t.Fatalf("AddRadioGroup: %v", err)
+	}
+	if err := pdf.AddRadioGroup("empty", nil, ""); err == nil {
+		t.Error("expected error for a group without buttons")
+	}
+	data, err := pdf.GetBytesPdfReturnErr()
+	if err != nil {
+		t.Fatalf("GetBytesPdfReturnErr: %v", err)
+	}
+	s := string(data)
+	if strings.Count(s, "/Subtype /Widget") != 3 || !strings.Contains(s, "/Kids [") {
+		t.Error("expected one parent field with three widgets")
+	}
+	if !strings.Contains(s, "/AS /M") || strings.Count(s, "/AS /Off") != 2 {
+		t.Error("only the selected button should be on")
+	}
+	if !strings.Contains(s, "/N << /L ") || !strings.Contains(s, "/Off ") {
+		t.Error("widgets need on and off appearances")
+	}
+
+	fields, err := ListFormFields(data)
+	if err != nil {
+		t.Fatalf("ListFormFields: %v", err)
+	}
+	size := formFieldByName(t, fields, "size")
+	if size.Type != FormFieldRadio || size.Value != "M" || strings.Join(size.Options, ",") != "S,M,L" {
+		t.Errorf("unexpected radio field: %+v", size)
+	}
+
+	if err := pdf.ModifyFormFieldValue("size", "L"); err != nil {
+		t.Fatalf("ModifyFormFieldValue: %v", err)
+	}
+	data, _ = pdf.GetBytesPdfReturnErr()
+	if !strings.Contains(string(data), "/AS /L") || strings.Contains(string(data), "/AS /M") {
+		t.Error("ModifyFormFieldValue should select another button")
+	}
+}
+
+func TestAddRadioGroup_DeleteAndBake(t *testing.T) {
+	pdf := newPDFWithFont(t)
+	pdf.AddPage()
+	buttons := []RadioButton{{X: 50, Y: 100, W: 12, H: 12, Value: "yes"}, {X: 80, Y: 100, W: 12, H: 12, Value: "no"}}
+	pdf.AddRadioGroup("a", buttons, "yes")
+	pdf.AddRadioGroup("b", buttons, "no")
+	if err := pdf.DeleteFormField("a"); err != nil {
+		t.Fatalf("DeleteFormField: %v", err)
+	}
+	data, _ := pdf.GetBytesPdfReturnErr()
+	if n := strings.Count(string(data), "/Subtype /Widget"); n != 2 {
+		t.Errorf("expected 2 widgets after delete, got %d", n)
+	}
+
+	pdf = newPDFWithFont(t)
+	pdf.AddPage()
+	pdf.AddRadioGroup("b", buttons, "no")
+	pdf.BakeAnnotations()
+	data, _ = pdf.GetBytesPdfReturnErr()
+	s := string(data)
+	if strings.Contains(s, "/Widget") || strings.Contains(s, "/AcroForm") {
+		t.Error("baked document should not contain form fields")
+	}
+	if !strings.Contains(s, "/XObject") {
+		t.Error("baked radio buttons should draw their appearances")
+	}
+}
+
+func TestAddFormField_ChoiceVariants(t *testing.T) {
+	pdf := newPDFWithFont(t)
+	pdf.AddPage()
+	pdf.AddDropdown("combo", 50, 50, 150, 20, []string{"A", "B"})
+	pdf.AddFormField(FormField{
+		Type: FormFieldChoice, Name: "editable", X: 50, Y: 100, W: 150, H: 20,
+		Options: []string{"A", "B"}, Editable: true, Value: "Other",
+	})
+	pdf.AddFormField(FormField{
+		Type: FormFieldChoice, Name: "list", X: 50, Y: 150, W: 150, H: 60,
+		Options: []string{"Red", "Green", "Blue", "Cyan"}, ListBox: true, MultiSelect: true,
+		TopIndex: 1, Values: []string{"Green", "Cyan"},
+	})
+	if err := pdf.AddListBox("single", 250, 150, 100, 60, []string{"X", "Y"}, false); err != nil {
+		t.Fatalf("AddListBox: %v", err)
+	}
+	data, err := pdf.GetBytesPdfReturnErr()
+	if err != nil {
+		t.Fatalf("GetBytesPdfReturnErr: %v", err)
+	}
+	s := string(data)
+	if !strings.Contains(s, "/V [(Green) (Cyan) ]") || !strings.Contains(s, "/I [1 3]") || !strings.Contains(s, "/TI 1") {
+		t.Error("multi-select list box should write /V, /I and /TI")
+	}
+
+	fields, err := ListFormFields(data)
+	if err != nil {
+		t.Fatalf("ListFormFields: %v", err)
+	}
+	if f := formFieldByName(t, fields, "combo"); !f.Combo || f.Editable {
+		t.Errorf("combo flags: %+v", f)
+	}
+	if f := formFieldByName(t, fields, "editable"); !f.Combo || !f.Editable || f.Value != "Other" {
+		t.Errorf("editable combo: %+v", f)
+	}
+	if f := formFieldByName(t, fields, "list"); f.Combo || !f.MultiSelect || strings.Join(f.Values, ",") != "Green,Cyan" {
+		t.Errorf("list box: %+v", f)
+	}
+	if f := formFieldByName(t, fields, "single"); f.Combo || f.MultiSelect {
+		t.Errorf("single list box: %+v", f)
+	}
+}
+
+func TestAddPushButton_Actions(t *testing.T) {
+	pdf := newPDFWithFont(t)
+	pdf.AddPage()
+	pdf.AddPage()
+	actions := map[string]Action{
+		"reset":  {Type: ActionResetForm, Fields: []string{"name"}},
+		"submit": {Type: ActionSubmitForm, URI: "https://example.com/submit", Format: SubmitXFDF},
+		"html":   {Type: ActionSubmitForm, URI: "https://example.com/html", Format: SubmitHTML},
+		"goto":   {Type: ActionGoTo, Dest: Destination{Page: 1, Top: 100}},
+	}
+	y := 50.0
+	for _, name := range []string{"reset", "submit", "html", "goto"} {
+		if err := pdf.AddPushButton(name, strings.ToUpper(name), 50, y, 80, 24, actions[name]); err != nil {
+			t.Fatalf("AddPushButton: %v", err)
+		}
+		y += 40
+	}
+	data, err := pdf.GetBytesPdfReturnErr()
+	if err != nil {
+		t.Fatalf("GetBytesPdfReturnErr: %v", err)
+	}
+	s := string(data)
+	for _, want := range []string{
+		"/S /ResetForm /Fields [(name) ]",
+		"/S /SubmitForm /F << /FS /URL /F (https://example.com/submit) >> /Flags 32",
+		"/F (https://example.com/html) >> /Flags 4",
+		"/S /GoTo /D [",
+		"/MK <<\n  /BC [0.0000 0.0000 0.0000]\n  /BG [0.8314 0.8157 0.7843]\n  /CA (SUBMIT)",
+		"(SUBMIT) Tj",
+	} {
+		if !strings.Contains(s, want) {
+			t.Errorf("missing %q", want)
+		}
+	}
+	fields, err := ListFormFields(data)
+	if err != nil {
+		t.Fatalf("ListFormFields: %v", err)
+	}
+	if f := formFieldByName(t, fields, "goto"); f.Type != FormFieldButton {
+		t.Errorf("push button type = %v", f.Type)
+	}
+}
+
+// ============================================================
+// Form field appearance stream and script tests
+// ============================================================
+
+func TestAddFormField_Appearances(t *testing.T) {
+	pdf := newPDFWithFont(t)
+	pdf.AddPage()
+	pdf.AddFormField(FormField{
+		Type: FormFieldText, Name: "name", X: 50, Y: 50, W: 200, H: 20,
+		Value: "Ada", FontFamily: fontFamily, Align: Center, HasBorder: true,
+	})
+	pdf.AddFormField(FormField{
+		Type: FormFieldText, Name: "zip", X: 50, Y: 100, W: 100, H: 20,
+		Value: "1234", MaxLen: 5, Comb: true, HasBorder: true,
+	})
+	pdf.AddFormField(FormField{
+		Type: FormFieldCheckbox, Name: "cross", X: 50, Y: 150, W: 12, H: 12,
+		Checked: true, CheckStyle: CheckStyleCross, HasBorder: true,
+	})
+	pdf.AddPushButton("ok", "OK", 50, 200, 60, 20, Action{Type: ActionResetForm})
+	if err := pdf.AddFormField(FormField{Type: FormFieldText, Name: "bad", W: 10, H: 10, Comb: true}); err == nil {
+		t.Error("expected error for a comb field without MaxLen")
+	}
+
+	data, err := pdf.GetBytesPdfReturnErr()
+	if err != nil {
+		t.Fatalf("GetBytesPdfReturnErr: %v", err)
+	}
+	s := string(data)
+	for _, want := range []string{
+		"/Q 1\n",
+		"/Ff 16777216",
+		"/CA (8)",
+		"/DA (/ZaDb 0 Tf",
+		"(8) Tj",
+		"/D << /Yes ",
+		"(4) Tj", // comb cells
+		"/DR << /Font << /Helv ",
+	} {
+		if !strings.Contains(s, want) {
+			t.Errorf("missing %q", want)
+		}
+	}
+	if strings.Contains(s, "/NeedAppearances") {
+		t.Error("fields with appearances should not need generated appearances")
+	}
+	if n := strings.Count(s, "/AP << /N "); n != 4 {
+		t.Errorf("expected 4 widgets with appearances, got %d", n)
+	}
+	if !strings.Contains(s, " /R ") {
+		t.Error("push button should have a rollover appearance")
+	}
+
+	// The TTF field appearance uses the subset font of its family.
+	sub, objID := pdf.annotationFont(fontFamily)
+	if !strings.Contains(s, fmt.Sprintf("/Font << /F1 %d 0 R >>", objID)) {
+		t.Error("text appearance should reference the TTF font")
+	}
+	if _, err := sub.CharIndex('A'); err != nil {
+		t.Error("field value should be added to the font subset")
+	}
+
+	if err := pdf.ModifyFormFieldValue("zip", "98765"); err != nil {
+		t.Fatalf("ModifyFormFieldValue: %v", err)
+	}
+	data, _ = pdf.GetBytesPdfReturnErr()
+	if !strings.Contains(string(data), "(9) Tj") {
+		t.Error("ModifyFormFieldValue should redraw the appearance")
+	}
+}
+
+func TestAddFormField_Scripts(t *testing.T) {
+	pdf := newPDFWithFont(t)
+	pdf.AddPage()
+	money := &FieldFormat{Type: FieldFormatNumber, Decimals: 2, Negative: NegativeParensRed, Currency: "$", CurrencyPrepend: true}
+	pdf.AddFormField(FormField{Type: FormFieldText, Name: "price", X: 50, Y: 50, W: 100, H: 20,
+		Value: "-1234.5", Format: money, Validate: &FieldRange{Min: -5000, HasMin: true}})
+	pdf.AddFormField(FormField{Type: FormFieldText, Name: "qty", X: 50, Y: 80, W: 100, H: 20, Value: "2"})
+	pdf.AddFormField(FormField{Type: FormFieldText, Name: "total", X: 50, Y: 110, W: 100, H: 20, ReadOnly: true,
+		Format: money, Calculate: &FieldCalculation{Op: CalcProduct, Fields: []string{"price", "qty"}}})
+	pdf.AddFormField(FormField{Type: FormFieldText, Name: "sum", X: 50, Y: 140, W: 100, H: 20,
+		Calculate: &FieldCalculation{Op: CalcSum, Fields: []string{"price", "total"}}})
+	pdf.AddFormField(FormField{Type: FormFieldText, Name: "due", X: 50, Y: 170, W: 100, H: 20,
+		Format: &FieldFormat{Type: FieldFormatDate, DateFormat: "yyyy-mm-dd"}})
+
+	if err := pdf.SetCalculationOrder("qty"); err == nil {
+		t.Error("expected error for a field without calculation")
+	}
+	if err := pdf.SetCalculationOrder("missing"); err == nil {
+		t.Error("expected error for an unknown field")
+	}
+	if err := pdf.SetCalculationOrder("sum", "total"); err != nil {
+		t.Fatalf("SetCalculationOrder: %v", err)
+	}
+	data, err := pdf.GetBytesPdfReturnErr()
+	if err != nil {
+		t.Fatalf("GetBytesPdfReturnErr: %v", err)
+	}
+	s := string(data)
+	for _, want := range []string{
+		`/K << /S /JavaScript /JS (AFNumber_Keystroke\(2, 0, 3, 0, "$", true\);) >>`,
+		`/F << /S /JavaScript /JS (AFNumber_Format\(2, 0, 3, 0, "$", true\);) >>`,
+		`/V << /S /JavaScript /JS (AFRange_Validate\(true, -5000, false, 0\);) >>`,
+		`/C << /S /JavaScript /JS (AFSimple_Calculate\("PRD", new Array \("price", "qty"\)\);) >>`,
+		`AFDate_FormatEx\("yyyy-mm-dd"\);`,
+		"1 0 0 rg", // negative in red
+		"(\\($1,234.50\\)) Tj",
+	} {
+		if !strings.Contains(s, want) {
+			t.Errorf("missing %q", want)
+		}
+	}
+	var sumID, totalID int
+	for _, ref := range pdf.formFields {
+		switch ref.field.Name {
+		case "sum":
+			sumID = ref.objIdx + 1
+		case "total":
+			totalID = ref.objIdx + 1
+		}
+	}
+	if co := fmt.Sprintf("/CO [%d 0 R %d 0 R ]", sumID, totalID); !strings.Contains(s, co) {
+		t.Errorf("missing calculation order %q", co)
+	}
+}
+
+func TestFormatFieldNumber(t *testing.T) {
+	tests := []struct {
+		v    float64
+		dec  int
+		sep  NumberSeparator
+		want string
+	}{
+		{1234567.891, 2, SeparatorCommaDot, "1,234,567.89"},
+		{1234.5, 2, SeparatorDot, "1234.50"},
+		{1234.5, 1, SeparatorDotComma, "1.234,5"},
+		{1234.5, 0, SeparatorComma, "1235"},
+		{12, 0, SeparatorCommaDot, "12"},
+	}
+	for _, tt := range tests {
+		if got := formatFieldNumber(tt.v, tt.dec, tt.sep); got != tt.want {
+			t.Errorf("formatFieldNumber(%v, %d, %d) = %q, want %q", tt.v, tt.dec, tt.sep, got, tt.want)
+		}
+	}
+	if got := jsString(`a"b\é`); got != `"a\"b\\\u00E9"` {
+		t.Errorf("jsString = %s", got)
+	}
+}
+
+// ============================================================
+// FDF and XFDF form data tests
+// ============================================================
+
+// newFormDataPDF returns a document with a text field, a checkbox, a
+// multi-select list box and two annotations.
+func newFormDataPDF(t *testing.T) *GoPdf {
+	t.Helper()
+	pdf := newPDFWithFont(t)
+	pdf.AddPage()
+	pdf.AddFormField(FormField{Type: FormFieldText, Name: "applicant.name", X: 50, Y: 50, W: 200, H: 20, Value: "Ada <Lovelace>"})
+	pdf.AddFormField(FormField{Type: FormFieldCheckbox, Name: "agree", X: 50, Y: 100, W: 12, H: 12, Checked: true})
+	pdf.AddFormField(FormField{
+		Type: FormFieldChoice, Name: "langs", X: 50, Y: 150, W: 100, H: 60, ListBox: true, MultiSelect: true,
+		Options: []string{"en", "fr", "de"}, Values: []string{"en", "de"},
+	})
+	pdf.AddAnnotation(AnnotationOption{Type: AnnotSquare, X: 300, Y: 100, W: 50, H: 40, Content: "Check", Color: [3]uint8{255, 0, 0}})
+	pdf.AddAnnotation(AnnotationOption{Type: AnnotInk, InkList: [][]Point{{{X: 300, Y: 300}, {X: 350, Y: 320}}}})
+	return pdf
+}
+
+func checkFormData(t *testing.T, d *FormData) {
+	t.Helper()
+	want := map[string]string{"applicant.name": "Ada <Lovelace>", "agree": "Yes", "langs": "en"}
+	if len(d.Fields) != len(want) {
+		t.Fatalf("expected %d fields, got %+v", len(want), d.Fields)
+	}
+	for _, f := range d.Fields {
+		if f.Value != want[f.Name] {
+			t.Errorf("field %q = %q, want %q", f.Name, f.Value, want[f.Name])
+		}
+		if f.Name == "langs" && strings.Join(f.Values, ",") != "en,de" {
+			t.Errorf("langs values = %v", f.Values)
+		}
+	}
+	if len(d.Annotations) != 2 {
+		t.Fatalf("expected 2 annotations, got %d", len(d.Annotations))
+	}
+	sq := d.Annotations[0].Option
+	pageH := PageSizeA4.H
+	if sq.Type != AnnotSquare || sq.Content != "Check" || sq.Color != [3]uint8{255, 0, 0} ||
+		math.Abs(sq.X-300) > 0.01 || math.Abs(sq.Y-(pageH-140)) > 0.01 || math.Abs(sq.W-50) > 0.01 {
+		t.Errorf("square annotation = %+v", sq)
+	}
+	ink := d.Annotations[1].Option
+	if ink.Type != AnnotInk || len(ink.InkList) != 1 || len(ink.InkList[0]) != 2 ||
+		math.Abs(ink.InkList[0][1].Y-(pageH-320)) > 0.01 {
+		t.Errorf("ink annotation = %+v", ink)
+	}
+}
+
+func TestFormData_FDFAndXFDFRoundTrip(t *testing.T) {
+	d := newFormDataPDF(t).GetFormData()
+	d.File = "form.pdf"
+	checkFormData(t, d)
+
+	fdf := d.ExportFDF()
+	if !bytes.HasPrefix(fdf, []byte("%FDF-1.2")) || !bytes.Contains(fdf, []byte("/T (applicant) /Kids [")) ||
+		!bytes.Contains(fdf, []byte("/V /Yes")) {
+		t.Errorf("unexpected FDF:\n%s", fdf)
+	}
+	parsed, err := ParseFDF(fdf)
+	if err != nil {
+		t.Fatalf("ParseFDF: %v", err)
+	}
+	if parsed.File != "form.pdf" {
+		t.Errorf("FDF file = %q", parsed.File)
+	}
+	checkFormData(t, parsed)
+
+	xfdf := d.ExportXFDF()
+	if !bytes.Contains(xfdf, []byte(`<field name="applicant">`)) || !bytes.Contains(xfdf, []byte("&lt;Lovelace&gt;")) ||
+		!bytes.Contains(xfdf, []byte("<gesture>")) {
+		t.Errorf("unexpected XFDF:\n%s", xfdf)
+	}
+	parsed, err = ParseXFDF(xfdf)
+	if err != nil {
+		t.Fatalf("ParseXFDF: %v", err)
+	}
+	checkFormData(t, parsed)
+
+	if _, err := ParseXFDF([]byte("<fields/>")); err != ErrInvalidXFDF {
+		t.Errorf("expected ErrInvalidXFDF, got %v", err)
+	}
+}
+
+func TestImportFormData(t *testing.T) {
+	d := newFormDataPDF(t).GetFormData()
+	d.Fields[0].Value = "Grace"
+	d.Fields[1].Value = "Off"
+	d.Fields = append(d.Fields, FormDataField{Name: "unknown", Value: "x"})
+	d.Annotations[0].Option.Content = "Checked"
+	d.Annotations = append(d.Annotations, FormDataAnnotation{
+		Page:   0,
+		Option: AnnotationOption{Type: AnnotText, X: 20, Y: 700, W: 24, H: 24, Content: "New"},
+	}, FormDataAnnotation{Page: 5, Option: AnnotationOption{Type: AnnotText}})
+
+	pdf := newFormDataPDF(t)
+	report, err := pdf.ImportFormData(d)
+	if err != nil {
+		t.Fatalf("ImportFormData: %v", err)
+	}
+	if report.FieldsSet != 3 || strings.Join(report.UnmatchedFields, ",") != "unknown" {
+		t.Errorf("field report = %+v", report)
+	}
+	if report.AnnotationsUpdated != 2 || report.AnnotationsAdded != 1 || report.UnmatchedAnnotations != 1 {
+		t.Errorf("annotation report = %+v", report)
+	}
+
+	got := pdf.GetFormData()
+	if got.Fields[0].Value != "Grace" || got.Fields[1].Value != "Off" {
+		t.Errorf("imported fields = %+v", got.Fields)
+	}
+	annots := pdf.GetAnnotationsOnPage(1)
+	if len(annots) != 3 || annots[0].Option.Content != "Checked" || annots[2].Option.Y != 118 {
+		t.Errorf("imported annotations = %+v", annots)
+	}
+	if _, err := pdf.GetBytesPdfReturnErr(); err != nil {
+		t.Fatalf("GetBytesPdfReturnErr: %v", err)
+	}
+}
+
+func TestApplyFormData(t *testing.T) {
+	xfdf := `<?xml version="1.0" encoding="UTF-8"?>
+<xfdf xmlns="http://ns.adobe.com/xfdf/" xml:space="preserve">
+<fields>
+<field name="applicant"><field name="name"><value>Jane</value></field></field>
+<field name="color"><value>blue</value></field>
+<field name="langs"><value>fr</value><value>de</value></field>
+<field name="missing"><value>x</value></field>
+</fields>
+<annots>
+<square page="0" rect="100,100,200,150" color="#0000FF" title="Reviewer" name="r1"><contents>Looks good</contents></square>
+<polygon page="0" rect="0,0,0,0" color="#00FF00"><vertices>300,300;350,300;325,340</vertices></polygon>
+<text page="3" rect="10,10,34,34"/>
+</annots>
+</xfdf>`
+	d, err := ParseXFDF([]byte(xfdf))
+	if err != nil {
+		t.Fatalf("ParseXFDF: %v", err)
+	}
+	out, report, err := ApplyFormData(buildTestPDF(testFormObjects), d, FormFillOption{})
+	if err != nil {
+		t.Fatalf("ApplyFormData: %v", err)
+	}
+	if report.FieldsSet != 3 || strings.Join(report.UnmatchedFields, ",") != "missing" ||
+		report.AnnotationsAdded != 2 || report.UnmatchedAnnotations != 1 {
+		t.Errorf("report = %+v", report)
+	}
+
+	fields, err := ListFormFields(out)
+	if err != nil {
+		t.Fatalf("ListFormFields: %v", err)
+	}
+	if v := formFieldByName(t, fields, "applicant.name").Value; v != "Jane" {
+		t.Errorf("applicant.name = %q", v)
+	}
+	if v := formFieldByName(t, fields, "color").Value; v != "blue" {
+		t.Errorf("color = %q", v)
+	}
+
+	read, err := ReadFormData(out)
+	if err != nil {
+		t.Fatalf("ReadFormData: %v", err)
+	}
+	if len(read.Annotations) != 2 {
+		t.Fatalf("expected 2 annotations, got %+v", read.Annotations)
+	}
+	sq := read.Annotations[0]
+	if sq.Name != "r1" || sq.Option.Title != "Reviewer" || sq.Option.Content != "Looks good" ||
+		sq.Option.Color != [3]uint8{0, 0, 255} || sq.Rect != [4]float64{100, 100, 200, 150} {
+		t.Errorf("square annotation = %+v", sq)
+	}
+	if len(read.Annotations[1].Option.Vertices) != 3 {
+		t.Errorf("polygon vertices = %+v", read.Annotations[1].Option.Vertices)
+	}
+	if !bytes.Contains(out, []byte("/Subtype /Form")) || !bytes.Contains(out, []byte("/P 3 0 R")) {
+		t.Error("added annotations should have appearances and a page")
+	}
+
+	// Applying the data again replaces the annotations.
+	d.Annotations[0].Option.Content = "Changed"
+	out, report, err = ApplyFormData(out, d, FormFillOption{Incremental: true})
+	if err != nil {
+		t.Fatalf("ApplyFormData: %v", err)
+	}
+	if report.AnnotationsUpdated != 2 || report.AnnotationsAdded != 0 {
+		t.Errorf("second report = %+v", report)
+	}
+	read, _ = ReadFormData(out)
+	if len(read.Annotations) != 2 || read.Annotations[0].Option.Content != "Changed" {
+		t.Errorf("updated annotations = %+v", read.Annotations)
+	}
+
+	// The data of a PDF round-trips through FDF into another copy.
+	fdf, err := ParseFDF(read.ExportFDF())
+	if err != nil {
+		t.Fatalf("ParseFDF: %v", err)
+	}
+	if _, report, err = ApplyFormData(out, fdf, FormFillOption{}); err != nil || len(report.UnmatchedFields) != 0 ||
+		report.AnnotationsUpdated != 2 {
+		t.Errorf("FDF apply: %+v, %v", report, err)
+	}
+}
+
+// ============================================================
+// Action tests
+// ============================================================
+
+func TestAddActionLink(t *testing.T) {
+	pdf := newPDFWithFont(t)
+	pdf.SetNoCompression()
+	pdf.AddPage()
+	pdf.SetY(200)
+	pdf.SetAnchor("intro")
+	pdf.AddPage()
+	pdf.AddOCG(OCG{Name: "notes", On: true})
+	links := []Action{
+		{Type: ActionGoTo, Dest: Destination{Page: 1, Fit: DestFitR, Left: 10, Top: 20, Right: 110, Bottom: 220}},
+		{Type: ActionGoTo, Dest: Destination{Page: 2, Top: 100, Zoom: 1.5}},
+		{Type: ActionGoTo, Dest: Destination{Name: "intro"}},
+		{Type: ActionGoToR, File: "other.pdf", Dest: Destination{Page: 3, Fit: DestFitH, Top: 500}, NewWindow: true},
+		{Type: ActionGoToE, Embedded: "att.pdf", Dest: Destination{Page: 1, Fit: DestFit}},
+		{Type: ActionLaunch, File: "readme.txt"},
+		{Type: ActionNamed, Named: NamedActionNextPage},
+		{Type: ActionJavaScript, JavaScript: `app.alert("hi")`, Next: []Action{
+			{Type: ActionNamed, Named: NamedActionPrint},
+		}},
+		{Type: ActionURI, URI: "help.html", Next: []Action{
+			{Type: ActionNamed, Named: NamedActionFirstPage},
+			{Type: ActionResetForm, Fields: []string{"a"}, ExcludeFields: true},
+		}},
+		{Type: ActionSetOCGState, OCGStates: []OCGStateItem{{Name: "notes", Change: OCGToggle}, {Name: "missing", Change: OCGTurnOn}}},
+		{Type: ActionHide, Fields: []string{"a", "b"}, Show: true},
+		{Type: ActionGoTo, Dest: Destination{Page: 9}},
+	}
+	for i, a := range links {
+		pdf.AddActionLink(a, 50, 50+float64(i)*30, 100, 20)
+	}
+	pdf.SetBaseURI("https://example.com/docs/")
+	pdf.SetOpenAction(Action{Type: ActionGoTo, Dest: Destination{Page: 2, Fit: DestFitH, Top: 0}})
+	pdf.SetPageOpenAction(Action{Type: ActionJavaScript, JavaScript: "open()"})
+	pdf.SetPageCloseAction(Action{Type: ActionNamed, Named: NamedActionGoBack})
+
+	if got := pdf.GetLinks(); len(got) != len(links) || got[6].Action == nil || got[6].Action.Named != NamedActionNextPage {
+		t.Fatalf("GetLinks = %+v", got)
+	}
+
+	data, err := pdf.GetBytesPdfReturnErr()
+	if err != nil {
+		t.Fatalf("GetBytesPdfReturnErr: %v", err)
+	}
+	s := string(data)
+	page1, page2 := pdf.pageObjIDByNumber(1), pdf.pageObjIDByNumber(2)
+	h := PageSizeA4.H
+	for _, want := range []string{
+		fmt.Sprintf("/A << /S /GoTo /D [%d 0 R /FitR 10.00 %.2f 110.00 %.2f] >>", page1, h-220, h-20),
+		fmt.Sprintf("/D [%d 0 R /XYZ 0.00 %.2f 1.5] >>", page2, h-100),
+		"/S /GoToR /F (other.pdf) /D [2 /FitH 500.00] /NewWindow true",
+		"/S /GoToE /T << /R /C /N (att.pdf) >> /D [0 /Fit]",
+		"/S /Launch /F (readme.txt)",
+		"/A << /S /Named /N /NextPage >>",
+		`/S /JavaScript /JS (app.alert\("hi"\)) /Next << /S /Named /N /Print >>`,
+		"/S /URI /URI (help.html) /Next [<< /S /Named /N /FirstPage >> << /S /ResetForm /Fields [(a) ] /Flags 1 >>]",
+		fmt.Sprintf("/S /SetOCGState /State [/Toggle %d 0 R ]", pdf.ocgObjID("notes")),
+		"/S /Hide /T [(a) (b) ] /H false",
+		fmt.Sprintf("/OpenAction << /S /GoTo /D [%d 0 R /FitH %.2f] >>", page2, h),
+		"/URI << /Base (https://example.com/docs/) >>",
+		" /AA << /O << /S /JavaScript /JS (open\\(\\)) >> /C << /S /Named /N /GoBack >> >>",
+	} {
+		if !strings.Contains(s, want) {
+			t.Errorf("missing %q", want)
+		}
+	}
+	if strings.Count(s, "/Subtype /Link") != len(links)-1 {
+		t.Errorf("link with unresolved destination written: %d links", strings.Count(s, "/Subtype /Link"))
+	}
+}
+
+func TestAddOutlineWithAction(t *testing.T) {
+	pdf := newPDFWithFont(t)
+	pdf.SetNoCompression()
+	pdf.AddPage()
+	pdf.AddOutline("Start")
+	pdf.AddOutlineWithAction("Website", Action{Type: ActionURI, URI: "https://example.com"})
+	pdf.AddOutlineWithAction("Nowhere", Action{Type: ActionGoTo, Dest: Destination{Page: 5}})
+	data, err := pdf.GetBytesPdfReturnErr()
+	if err != nil {
+		t.Fatalf("GetBytesPdfReturnErr: %v", err)
+	}
+	s := string(data)
+	if !strings.Contains(s, "/A << /S /URI /URI (https://example.com) >>") {
+		t.Error("outline action missing")
+	}
+	if n := strings.Count(s, "/Dest [ "); n != 2 {
+		t.Errorf("outline /Dest count = %d, want 2", n)
+	}
+}
+
+func TestFormField_Actions(t *testing.T) {
+	pdf := newPDFWithFont(t)
+	pdf.SetNoCompression()
+	pdf.AddPage()
+	err := pdf.AddFormField(FormField{
+		Type: FormFieldText, Name: "amount", X: 50, Y: 700, W: 150, H: 24,
+		Format: &FieldFormat{Type: FieldFormatNumber, Decimals: 2},
+		Actions: &FieldActions{
+			Focus: &Action{Type: ActionJavaScript, JavaScript: "focus()"},
+			Enter: &Action{Type: ActionHide, Fields: []string{"tip"}, Show: true},
+		},
+	})
+	if err != nil {
+		t.Fatalf("AddFormField: %v", err)
+	}
+	err = pdf.AddFormField(FormField{
+		Type: FormFieldButton, Name: "go", X: 50, Y: 600, W: 80, H: 24, Caption: "Go",
+		Actions: &FieldActions{Activate: &Action{Type: ActionNamed, Named: NamedActionLastPage}},
+	})
+	if err != nil {
+		t.Fatalf("AddFormField: %v", err)
+	}
+	err = pdf.AddFormField(FormField{
+		Type: FormFieldRadio, Name: "choice", Value: "a", X: 50, Y: 500, W: 14, H: 14,
+		Buttons: []RadioButton{
+			{X: 50, Y: 500, W: 14, H: 14, Value: "a"},
+			{X: 80, Y: 500, W: 14, H: 14, Value: "b"},
+		},
+		Actions: &FieldActions{Up: &Action{Type: ActionJavaScript, JavaScript: "up()"}},
+	})
+	if err != nil {
+		t.Fatalf("AddFormField: %v", err)
+	}
+	data, err := pdf.GetBytesPdfReturnErr()
+	if err != nil {
+		t.Fatalf("GetBytesPdfReturnErr: %v", err)
+	}
+	s := string(data)
+	for _, want := range []string{
+		"/AA << /E << /S /Hide /T [(tip) ] /H false >> /Fo << /S /JavaScript /JS (focus\\(\\)) >> /K << /S /JavaScript",
+		"/A << /S /Named /N /LastPage >>",
+	} {
+		if !strings.Contains(s, want) {
+			t.Errorf("missing %q", want)
+		}
+	}
+	if n := strings.Count(s, "/AA << /U << /S /JavaScript /JS (up\\(\\)) >> >>"); n != 2 {
+		t.Errorf("radio widget actions = %d, want 2", n)
+	}
+}
+
+// ============================================================
+// Named destination tests
+// ============================================================
+
+func TestNamedDests(t *testing.T) {
+	pdf := newPDFWithFont(t)
+	pdf.SetNoCompression()
+	pdf.AddPage()
+	pdf.SetY(300)
+	pdf.SetAnchor("intro")
+	pdf.AddPage()
+	if err := pdf.AddNamedDest("section-4.2", Destination{Page: 2, Top: 100, Zoom: 1.25}); err != nil {
+		t.Fatalf("AddNamedDest: %v", err)
+	}
+	if err := pdf.AddNamedDest("", Destination{Page: 1}); err == nil {
+		t.Error("expected error for an empty name")
+	}
+	if err := pdf.AddNamedDest("nopage", Destination{}); err != ErrInvalidDestination {
+		t.Errorf("AddNamedDest without page = %v", err)
+	}
+	pdf.AddDestLink(Destination{Name: "section-4.2"}, 50, 50, 100, 20)
+	pdf.AddDestLink(Destination{Page: 1, Fit: DestFitB}, 50, 80, 100, 20)
+	pdf.AddActionLink(Action{Type: ActionGoTo, Dest: Destination{Name: "section-4.2"}}, 50, 110, 100, 20)
+	if got := pdf.GetNamedDests(); strings.Join(got, ",") != "intro,section-4.2" {
+		t.Errorf("GetNamedDests = %v", got)
+	}
+	if links := pdf.GetLinks(); len(links) != 3 || links[1].Dest == nil || links[1].Dest.Fit != DestFitB {
+		t.Errorf("GetLinks = %+v", links)
+	}
+
+	data, err := pdf.GetBytesPdfReturnErr()
+	if err != nil {
+		t.Fatalf("GetBytesPdfReturnErr: %v", err)
+	}
+	s := string(data)
+	page1, page2 := pdf.pageObjIDByNumber(1), pdf.pageObjIDByNumber(2)
+	for _, want := range []string{
+		fmt.Sprintf("/Dests <<\n    /Names [\n      (intro) [%d 0 R /XYZ 0 ", page1),
+		fmt.Sprintf("(section-4.2) [%d 0 R /XYZ 0.00 %.2f 1.25]", page2, PageSizeA4.H-100),
+		"/Dest (section-4.2)>>",
+		fmt.Sprintf("/Dest [%d 0 R /FitB]>>", page1),
+	} {
+		if !strings.Contains(s, want) {
+			t.Errorf("missing %q", want)
+		}
+	}
+
+	links, err := ExtractLinks(data)
+	if err != nil {
+		t.Fatalf("ExtractLinks: %v", err)
+	}
+	if len(links) != 3 {
+		t.Fatalf("ExtractLinks found %d links", len(links))
+	}
+	for _, i := range []int{0, 2} {
+		l := links[i]
+		if l.NamedDest != "section-4.2" || l.DestPageIndex != 1 || !strings.Contains(l.Destination, "/XYZ") {
+			t.Errorf("link %d = %+v", i, l)
+		}
+	}
+	if l := links[1]; l.NamedDest != "" || l.DestPageIndex != 0 || !strings.HasSuffix(l.Destination, "/FitB") {
+		t.Errorf("link 1 = %+v", l)
+	}
+}
+
+func TestOutlineAndTOCDests(t *testing.T) {
+	pdf := newPDFWithFont(t)
+	pdf.SetNoCompression()
+	pdf.AddPage()
+	pdf.AddPage()
+	pdf.AddOutlineWithDest("Overview", Destination{Page: 1, Fit: DestFit})
+	if toc := pdf.GetTOC(); len(toc) != 1 || toc[0].PageNo != 1 || toc[0].Dest == nil || toc[0].Dest.Fit != DestFit {
+		t.Errorf("GetTOC = %+v", toc)
+	}
+	err := pdf.SetTOC([]TOCItem{
+		{Level: 1, Title: "One", PageNo: 1},
+		{Level: 2, Title: "Wide", PageNo: 2, Dest: &Destination{Fit: DestFitH, Top: 50}},
+		{Level: 2, Title: "Box", Dest: &Destination{Page: 1, Fit: DestFitR, Left: 10, Top: 10, Right: 200, Bottom: 100}},
+		{Level: 1, Title: "Named", Dest: &Destination{Name: "later"}},
+	})
+	if err != nil {
+		t.Fatalf("SetTOC: %v", err)
+	}
+	toc := pdf.GetTOC()
+	if len(toc) != 4 || toc[1].Dest == nil || toc[1].Dest.Page != 2 || toc[1].Dest.Top != 50 || toc[2].PageNo != 1 {
+		t.Fatalf("GetTOC = %+v", toc)
+	}
+	data, err := pdf.GetBytesPdfReturnErr()
+	if err != nil {
+		t.Fatalf("GetBytesPdfReturnErr: %v", err)
+	}
+	s := string(data)
+	h := PageSizeA4.H
+	for _, want := range []string{
+		fmt.Sprintf("/Dest [%d 0 R /FitH %.2f]", pdf.pageObjIDByNumber(2), h-50),
+		fmt.Sprintf("/Dest [%d 0 R /FitR 10.00 %.2f 200.00 %.2f]", pdf.pageObjIDByNumber(1), h-100, h-10),
+		"/Dest (later)",
+	} {
+		if !strings.Contains(s, want) {
+			t.Errorf("missing %q", want)
+		}
+	}
+}
+
+func TestExtractLinks_NamedDests(t *testing.T) {
+	data := buildTestPDF([]string{
+		"<< /Type /Catalog /Pages 2 0 R /Dests 6 0 R /Names << /Dests << /Kids [7 0 R] >> >> >>",
+		"<< /Type /Pages /Kids [3 0 R 4 0 R] /Count 2 >>",
+		"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 200 200] /Annots [5 0 R 8 0 R 9 0 R] >>",
+		"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 200 200] >>",
+		"<< /Type /Annot /Subtype /Link /Rect [0 0 10 10] /Dest /old >>",
+		"<< /old << /D [4 0 R /Fit] >> >>",
+		"<< /Names [(new) [3 0 R /XYZ 0 100 null]] >>",
+		"<< /Type /Annot /Subtype /Link /Rect [0 20 10 30] /A << /S /GoTo /D (new) >> >>",
+		"<< /Type /Annot /Subtype /Link /Rect [0 40 10 50] /A << /S /GoToR /F (x.pdf) /D (new) >> >>",
+	})
+	links, err := ExtractLinks(data)
+	if err != nil {
+		t.Fatalf("ExtractLinks: %v", err)
+	}
+	if len(links) != 3 {
+		t.Fatalf("ExtractLinks found %d links", len(links))
+	}
+	if l := links[0]; l.NamedDest != "old" || l.Destination != "4 0 R /Fit" || l.DestPageIndex != 1 {
+		t.Errorf("link 0 = %+v", l)
+	}
+	if l := links[1]; l.NamedDest != "new" || l.Destination != "3 0 R /XYZ 0 100 null" || l.DestPageIndex != 0 {
+		t.Errorf("link 1 = %+v", l)
+	}
+	if l := links[2]; l.NamedDest != "" || l.DestPageIndex != -1 {
+		t.Errorf("remote link = %+v", l)
+	}
+}
+
+// ============================================================
+// Printed table of contents tests
+// ============================================================
+
+// pageTexts returns the text of a page (0-based) of data, with line breaks
+// between lines.
+func pageTexts(t *testing.T, data []byte, page int) string {
+	t.Helper()
+	texts, err := ExtractTextFromPage(data, page)
+	if err != nil {
+		t.Fatalf("ExtractTextFromPage: %v", err)
+	}
+	var sb strings.Builder
+	for i, txt := range texts {
+		if i > 0 && math.Abs(txt.Y-texts[i-1].Y) > 1 {
+			sb.WriteString("\n")
+		}
+		sb.WriteString(strings.TrimRight(txt.Text, "\uFFFD"))
+	}
+	return sb.String()
+}
+
+func TestRenderTOC(t *testing.T) {
+	pdf := newPDFWithFont(t)
+	pdf.SetNoCompression()
+	if _, err := pdf.RenderTOC(); err != ErrNoTOCPages {
+		t.Errorf("RenderTOC without pages = %v", err)
+	}
+	pdf.AddPage()
+	pdf.Cell(nil, "Cover")
+	if err := pdf.ReserveTOCPages(1, TOCPageOption{Title: "Contents", MaxLevel: 2}); err != nil {
+		t.Fatalf("ReserveTOCPages: %v", err)
+	}
+	for i := 1; i <= 3; i++ {
+		pdf.AddPage()
+		pdf.Cell(nil, fmt.Sprintf("Chapter %d", i))
+	}
+	err := pdf.SetTOC([]TOCItem{
+		{Level: 1, Title: "Introduction", PageNo: 3},
+		{Level: 2, Title: "Scope", PageNo: 3, Y: 500},
+		{Level: 3, Title: "Hidden", PageNo: 3},
+		{Level: 1, Title: "Results", PageNo: 5, Dest: &Destination{Fit: DestFit}},
+	})
+	if err != nil {
+		t.Fatalf("SetTOC: %v", err)
+	}
+	pdf.SetPageLabels([]PageLabel{
+		{PageIndex: 0, Style: PageLabelRomanLower, Start: 1},
+		{PageIndex: 2, Style: PageLabelDecimal, Start: 1},
+	})
+	pdf.SetPage(3)
+	n, err := pdf.RenderTOC()
+	if err != nil || n != 1 {
+		t.Fatalf("RenderTOC = %d, %v", n, err)
+	}
+	if _, err := pdf.RenderTOC(); err != ErrTOCRendered {
+		t.Errorf("second RenderTOC = %v", err)
+	}
+	if pdf.GetNumberOfPages() != 5 {
+		t.Errorf("pages = %d, want 5", pdf.GetNumberOfPages())
+	}
+	links := pdf.GetLinksOnPage(2)
+	if len(links) != 3 || links[2].Dest == nil || links[2].Dest.Page != 5 || links[2].Dest.Fit != DestFit {
+		t.Errorf("TOC links = %+v", links)
+	}
+	data, err := pdf.GetBytesPdfReturnErr()
+	if err != nil {
+		t.Fatalf("GetBytesPdfReturnErr: %v", err)
+	}
+	text := pageTexts(t, data, 1)
+	for _, want := range []string{"Contents\n", "\nIntroduction....", "...3\n", "\nScope...", "\nResults....", "...5"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("TOC page missing %q in %q", want, text)
+		}
+	}
+	if strings.Contains(text, "Hidden") {
+		t.Error("level 3 entry listed despite MaxLevel 2")
+	}
+}
+
+func TestRenderTOC_Overflow(t *testing.T) {
+	pdf := newPDFWithFont(t)
+	pdf.SetNoCompression()
+	pdf.AddPage()
+	if err := pdf.ReserveTOCPages(1, TOCPageOption{UsePageLabels: true}); err != nil {
+		t.Fatalf("ReserveTOCPages: %v", err)
+	}
+	pdf.AddPage()
+	pdf.Cell(nil, "Body")
+	for i := 0; i < 70; i++ {
+		pdf.AddOutline(fmt.Sprintf("Entry %d", i+1))
+	}
+	pdf.AddPage()
+	pdf.SetAnchor("end")
+	if err := pdf.AddNamedDest("body", Destination{Page: 3}); err != nil {
+		t.Fatal(err)
+	}
+	pdf.SetPageLabels([]PageLabel{
+		{PageIndex: 0, Style: PageLabelRomanLower},
+		{PageIndex: 2, Style: PageLabelDecimal, Prefix: "P-"},
+	})
+	n, err := pdf.RenderTOC()
+	if err != nil {
+		t.Fatalf("RenderTOC: %v", err)
+	}
+	if n != 2 || pdf.GetNumberOfPages() != 5 {
+		t.Fatalf("RenderTOC = %d pages, document has %d", n, pdf.GetNumberOfPages())
+	}
+	if toc := pdf.GetTOC(); toc[0].PageNo != 4 {
+		t.Errorf("outline page = %d, want 4", toc[0].PageNo)
+	}
+	if labels := pdf.GetPageLabels(); labels[1].PageIndex != 3 {
+		t.Errorf("page labels = %+v", labels)
+	}
+	data, err := pdf.GetBytesPdfReturnErr()
+	if err != nil {
+		t.Fatalf("GetBytesPdfReturnErr: %v", err)
+	}
+	first, second := pageTexts(t, data, 1), pageTexts(t, data, 2)
+	if !strings.HasPrefix(first, "Entry1...") || !strings.Contains(second, "\nEntry70...") || !strings.HasSuffix(second, "...P-1") {
+		t.Errorf("TOC pages = %q / %q", first, second)
+	}
+	if !strings.Contains(pageTexts(t, data, 3), "Body") {
+		t.Error("body page not after the TOC pages")
+	}
+	if !strings.Contains(string(data), fmt.Sprintf("(body) [%d 0 R", pdf.pageObjIDByNumber(4))) {
+		t.Error("named destination not shifted")
+	}
+	links, err := ExtractLinks(data)
+	if err != nil {
+		t.Fatalf("ExtractLinks: %v", err)
+	}
+	if len(links) != 70 || links[0].PageIndex != 1 || links[69].PageIndex != 2 || links[69].DestPageIndex != 3 {
+		t.Errorf("links: %d, first %+v, last %+v", len(links), links[0], links[len(links)-1])
+	}
+}
+
+// ============================================================
+// Header and footer page token tests
+// ============================================================
+
+func TestHeaderFooterPageTokens(t *testing.T) {
+	pdf := newPDFWithFont(t)
+	pdf.SetNoCompression()
+	pdf.SetInfo(PdfInfo{Title: "Manual", CreationDate: time.Date(2024, 5, 6, 0, 0, 0, 0, time.UTC)})
+	pdf.EnablePageTokens()
+	pdf.SetHeaderFooterField("author", "Ann")
+	pdf.AddHeader(func() {
+		pdf.SetY(20)
+		pdf.Cell(nil, "{title}|{section}|{author}|{x}")
+	})
+	pdf.AddFooter(func() {
+		pdf.SetXY(20, 800)
+		pdf.CellWithOption(nil, "Page {page} of {pages}", CellOption{Align: Right})
+		pdf.SetXY(300, 800)
+		pdf.Cell(&Rect{W: 200, H: 20}, "{label} {date}")
+	})
+	pdf.AddPage()
+	pdf.AddOutline("Intro")
+	pdf.AddPage()
+	pdf.AddPage()
+	pdf.AddOutline("Usage")
+	if pdf.SetY(20); pdf.Cell(nil, "{page}") != nil {
+		t.Fatal("Cell failed")
+	}
+	pdf.SetPageLabels([]PageLabel{{PageIndex: 0, Style: PageLabelRomanUpper}})
+
+	data, err := pdf.GetBytesPdfReturnErr()
+	if err != nil {
+		t.Fatalf("GetBytesPdfReturnErr: %v", err)
+	}
+	for i, want := range []string{
+		"Manual|Intro|Ann|{x}\nPage1of3I2024-05-06",
+		"Manual|Intro|Ann|{x}\nPage2of3II2024-05-06",
+		"Manual|Usage|Ann|{x}\nPage3of3III2024-05-06\n{page}",
+	} {
+		if got := pageTexts(t, data, i); got != want {
+			t.Errorf("page %d text = %q, want %q", i+1, got, want)
+		}
+	}
+
+	// Right alignment is re-applied within the width reserved for
+	// "Page 1 of 000".
+	tokens := pdf.pageTokens[1]
+	if c := tokens.caches[0]; c.x <= tokens.x || c.x-tokens.x != tokens.reserved-c.textWidthPdfUnit {
+		t.Errorf("footer x = %v, drawn at %v with %v reserved", c.x, tokens.x, tokens.reserved)
+	}
+}
+
+func TestHeaderFooterPageTokens_RTL(t *testing.T) {
+	pdf := newPDFWithFont(t)
+	pdf.SetNoCompression()
+	pdf.EnablePageTokens()
+	pdf.SetTextDirection(TextDirectionRTL)
+	pdf.AddHeader(func() {
+		pdf.SetXY(20, 20)
+		pdf.Text("\u05e2\u05de\u05d5\u05d3 {page}")
+		pdf.SetXY(20, 40)
+		pdf.Cell(nil, "\u05e2\u05de\u05d5\u05d3 {page}")
+	})
+	pdf.AddPage()
+	pdf.SetXY(20, 60)
+	pdf.Text("\u05e2\u05de\u05d5\u05d3 1")
+
+	data, err := pdf.GetBytesPdfReturnErr()
+	if err != nil {
+		t.Fatalf("GetBytesPdfReturnErr: %v", err)
+	}
+	want := "1\u05d3\u05d5\u05de\u05e2"
+	if got := pageTexts(t, data, 0); got != want+"\n"+want+"\n"+want {
+		t.Errorf("page text = %q, want three lines of %q", got, want)
+	}
+}
+
+func TestHeaderFooterPageTokens_Fallback(t *testing.T) {
+	pdf := newPDFWithFont(t)
+	pdf.SetNoCompression()
+	pdf.EnablePageTokens()
+	if err := pdf.SetFont("Symbol", "", 14); err != nil {
+		t.Fatalf("SetFont(Symbol): %v", err)
+	}
+	pdf.SetFont(fontFamily, "", 14)
+	if err := pdf.SetFontFallback(fontFamily, "Symbol"); err != nil {
+		t.Fatalf("SetFontFallback: %v", err)
+	}
+	pdf.AddHeader(func() {
+		pdf.SetXY(20, 20)
+		pdf.Text("Page {pages} \u2228 {page}")
+		pdf.SetXY(20, 50)
+		pdf.CellWithOption(&Rect{W: 200, H: 20}, "{page} \u2228 {pages}", CellOption{Align: Right})
+	})
+	pdf.AddPage()
+	pdf.AddPage()
+
+	data, err := pdf.GetBytesPdfReturnErr()
+	if err != nil {
+		t.Fatalf("GetBytesPdfReturnErr: %v", err)
+	}
+	// The Symbol code of \u2228 is extracted as \u00da.
+	for i, want := range []string{"Page2\u00da1\n1\u00da2", "Page2\u00da2\n2\u00da2"} {
+		if got := pageTexts(t, data, i); got != want {
+			t.Errorf("page %d text = %q, want %q", i+1, got, want)
+		}
+	}
+
+	// The runs of the right-aligned cell end at the right edge.
+	cell := pdf.pageTokens[1]
+	if len(cell.caches) != 4 {
+		t.Fatalf("cell caches = %d, want a frame and 3 runs", len(cell.caches))
+	}
+	x := cell.caches[1].x
+	for _, c := range cell.caches[1:] {
+		if math.Abs(c.x-x) > 1e-9 {
+			t.Errorf("run %q at %v, want %v", c.text, c.x, x)
+		}
+		x += c.rectangle.W
+	}
+	if math.Abs(x-220) > 1e-9 {
+		t.Errorf("cell runs end at %v, want 220", x)
+	}
+}
+
+func TestHeaderFooterPageTokens_Disabled(t *testing.T) {
+	pdf := newPDFWithFont(t)
+	pdf.SetNoCompression()
+	pdf.SetInfo(PdfInfo{Title: "Manual"})
+	pdf.AddHeader(func() {
+		pdf.SetY(20)
+		pdf.Cell(nil, "{title} {date}")
+	})
+	pdf.AddFooter(func() {
+		pdf.SetXY(20, 800)
+		pdf.Text("Page {page} of {pages}")
+	})
+	pdf.AddPage()
+
+	data, err := pdf.GetBytesPdfReturnErr()
+	if err != nil {
+		t.Fatalf("GetBytesPdfReturnErr: %v", err)
+	}
+	if got, want := pageTexts(t, data, 0), "{title}{date}\nPage{page}of{pages}"; got != want {
+		t.Errorf("page text = %q, want %q", got, want)
+	}
+	if len(pdf.pageTokens) != 0 {
+		t.Errorf("%d page tokens tracked while disabled", len(pdf.pageTokens))
+	}
+}
+
+// ============================================================
+// Document JavaScript and additional action tests
+// ============================================================
+
+func TestDocumentJavaScript(t *testing.T) {
+	pdf := newPDFWithFont(t)
+	pdf.SetNoCompression()
+	js := func(script string) *Action { return &Action{Type: ActionJavaScript, JavaScript: script} }
+	if err := pdf.AddJavaScript("zeta", "var z = 1;"); err != nil {
+		t.Fatal(err)
+	}
+	pdf.AddJavaScript("alpha", "old")
+	pdf.AddJavaScript("alpha", "function stamp() {}")
+	if pdf.AddJavaScript("", "x") == nil {
+		t.Error("AddJavaScript without name succeeded")
+	}
+	pdf.SetDocumentActions(DocumentActions{WillPrint: js("stamp();"), DidPrint: js("done();")})
+	pdf.SetOpenAction(Action{Type: ActionGoTo, Dest: Destination{Page: 1}})
+	pdf.AddPage()
+	pdf.SetPageOpenAction(*js("opened();"))
+	pdf.AddAnnotation(AnnotationOption{
+		Type: AnnotText, X: 50, Y: 50, W: 20, H: 20, Content: "note",
+		Actions: &AnnotationActions{PageVisible: js("seen();")},
+	})
+	pdf.AddActionLink(Action{Type: ActionNamed, Named: NamedActionNextPage, Next: []Action{*js("next();")}}, 50, 100, 50, 20)
+	err := pdf.AddFormField(FormField{
+		Type: FormFieldText, Name: "printed", X: 50, Y: 200, W: 150, H: 24,
+		Actions: &FieldActions{Blur: js("blur();")},
+	})
+	if err != nil {
+		t.Fatalf("AddFormField: %v", err)
+	}
+	pdf.AddOutlineWithAction("Run", *js("run();"))
+
+	data, err := pdf.GetBytesPdfReturnErr()
+	if err != nil {
+		t.Fatalf("GetBytesPdfReturnErr: %v", err)
+	}
+	s := string(data)
+	for _, want := range []string{
+		"/JavaScript <<\n    /Names [\n      (alpha) << /S /JavaScript /JS (function stamp\\(\\) {}) >>\n      (zeta)",
+		"/AA << /WP << /S /JavaScript /JS (stamp\\(\\);) >> /DP << /S /JavaScript /JS (done\\(\\);) >> >>",
+		"/AA << /PV << /S /JavaScript /JS (seen\\(\\);) >> >>",
+	} {
+		if !strings.Contains(s, want) {
+			t.Errorf("output missing %q", want)
+		}
+	}
+
+	scripts, err := ListJavaScript(data)
+	if err != nil {
+		t.Fatalf("ListJavaScript: %v", err)
+	}
+	var got []string
+	for _, js := range scripts {
+		got = append(got, fmt.Sprintf("%s %s %s %d %s", js.Location, js.Name, js.Trigger, js.PageIndex, js.Script))
+	}
+	want := []string{
+		"document alpha  -1 function stamp() {}",
+		"document zeta  -1 var z = 1;",
+		"document  /WP -1 stamp();",
+		"document  /DP -1 done();",
+		"page  /O 0 opened();",
+		"annotation  /PV 0 seen();",
+		"annotation  /A 0 next();",
+		"field printed /Bl 0 blur();",
+		"outline Run /A -1 run();",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("scripts =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+
+	cleaned, err := RemoveJavaScript(data)
+	if err != nil {
+		t.Fatalf("RemoveJavaScript: %v", err)
+	}
+	if scripts, _ := ListJavaScript(cleaned); len(scripts) != 0 {
+		t.Errorf("scripts left: %+v", scripts)
+	}
+	if bytes.Contains(cleaned, []byte("/JavaScript")) || !bytes.Contains(cleaned, []byte("/OpenAction")) || !bytes.Contains(cleaned, []byte("/S /Named")) {
+		t.Error("RemoveJavaScript removed the wrong actions")
+	}
+	if n, err := GetSourcePDFPageCountFromBytes(cleaned); err != nil || n != 1 {
+		t.Errorf("cleaned page count = %d, %v", n, err)
+	}
+	if same, _ := RemoveJavaScript(cleaned); !bytes.Equal(same, cleaned) {
+		t.Error("RemoveJavaScript changed a document without scripts")
+	}
+}
+
+func TestRemoveJavaScript_NextChain(t *testing.T) {
+	pdf := newPDFWithFont(t)
+	pdf.SetNoCompression()
+	pdf.AddPage()
+	pdf.AddPage()
+	uri := Action{Type: ActionURI, URI: "https://example.com/"}
+	pdf.AddActionLink(Action{
+		Type: ActionGoTo, Dest: Destination{Page: 2},
+		Next: []Action{{Type: ActionJavaScript, JavaScript: "log();", Next: []Action{uri}}},
+	}, 50, 100, 50, 20)
+	pdf.AddOutlineWithAction("Run", Action{
+		Type: ActionJavaScript, JavaScript: "run();",
+		Next: []Action{uri, {Type: ActionNamed, Named: NamedActionFirstPage}},
+	})
+	data, err := pdf.GetBytesPdfReturnErr()
+	if err != nil {
+		t.Fatalf("GetBytesPdfReturnErr: %v", err)
+	}
+
+	cleaned, err := RemoveJavaScript(data)
+	if err != nil {
+		t.Fatalf("RemoveJavaScript: %v", err)
+	}
+	s := strings.Join(strings.Fields(string(cleaned)), " ")
+	if strings.Contains(s, "/JavaScript") {
+		t.Error("scripts left after RemoveJavaScript")
+	}
+	if !regexp.MustCompile(`/S /GoTo /D \[[^\]]*\] /Next << /S /URI /URI \(https://example.com/\) >>`).MatchString(s) {
+		t.Error("GoTo action does not run the URI action after the removed script")
+	}
+	if want := "/A << /S /URI /URI (https://example.com/) /Next [<< /S /Named /N /FirstPage >>] >>"; !strings.Contains(s, want) {
+		t.Errorf("cleaned output missing %q", want)
+	}
+}
+
+// ============================================================
+// Page transition and article thread tests
+// ============================================================
+
+func TestPageTransitions(t *testing.T) {
+	pdf := newPDFWithFont(t)
+	pdf.SetNoCompression()
+	pdf.SetPageMode(PageModeFullScreen)
+	pdf.AddPage()
+	pdf.AddPage()
+	pdf.AddPage()
+	for _, bad := range []PageTransition{
+		{Style: "Spin"},
+		{Style: TransitionWipe, Direction: 45},
+		{Style: TransitionWipe, Direction: 315},
+		{Style: TransitionPush, Direction: TransitionDirectionNone},
+		{Style: TransitionFade, Duration: -1},
+	} {
+		if err := pdf.SetPageTransition(1, bad); err != ErrInvalidTransition {
+			t.Errorf("SetPageTransition(%+v) = %v", bad, err)
+		}
+	}
+	if err := pdf.SetPageTransition(4, PageTransition{}); err != ErrPageOutOfRange {
+		t.Errorf("SetPageTransition on missing page = %v", err)
+	}
+	tests := []PageTransition{
+		{Style: TransitionSplit, Vertical: true, Inward: true, Duration: 2},
+		{Style: TransitionFly, Direction: TransitionDirectionNone, Scale: 0.5, Opaque: true},
+		{Style: TransitionGlitter, Direction: 315, Vertical: true},
+	}
+	for i, tr := range tests {
+		if err := pdf.SetPageTransition(i+1, tr); err != nil {
+			t.Fatalf("SetPageTransition(%d): %v", i+1, err)
+		}
+	}
+	if tr, _ := pdf.GetPageTransition(2); tr == nil || *tr != tests[1] {
+		t.Errorf("GetPageTransition = %+v", tr)
+	}
+	if err := pdf.SetPageDuration(1, 4.5); err != nil {
+		t.Fatal(err)
+	}
+	if d, _ := pdf.GetPageDuration(1); d != 4.5 {
+		t.Errorf("GetPageDuration = %v", d)
+	}
+	data, err := pdf.GetBytesPdfReturnErr()
+	if err != nil {
+		t.Fatalf("GetBytesPdfReturnErr: %v", err)
+	}
+	for _, want := range []string{
+		" /Trans << /Type /Trans /S /Split /D 2 /Dm /V /M /I >>\n /Dur 4.5\n",
+		" /Trans << /Type /Trans /S /Fly /Di /None /SS 0.5 /B true >>\n",
+		" /Trans << /Type /Trans /S /Glitter /Di 315 >>\n",
+		"/PageMode /FullScreen",
+	} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("output missing %q", want)
+		}
+	}
+}
+
+func TestArticleThreads(t *testing.T) {
+	pdf := newPDFWithFont(t)
+	pdf.SetNoCompression()
+	pdf.AddPage()
+	pdf.AddPage()
+	if err := pdf.AddArticleThread(ArticleThread{Beads: []ArticleBead{{Page: 3, W: 10, H: 10}}}); err != ErrPageOutOfRange {
+		t.Errorf("bead on missing page: %v", err)
+	}
+	if pdf.AddArticleThread(ArticleThread{Title: "Empty"}) == nil {
+		t.Error("thread without beads accepted")
+	}
+	err := pdf.AddArticleThread(ArticleThread{
+		Title:  "Lead story",
+		Author: "Desk",
+		Beads: []ArticleBead{
+			{Page: 1, X: 40, Y: 100, W: 250, H: 600},
+			{Page: 1, X: 300, Y: 100, W: 250, H: 600},
+			{Page: 2, X: 40, Y: 60, W: 510, H: 300},
+		},
+	})
+	if err != nil {
+		t.Fatalf("AddArticleThread: %v", err)
+	}
+	if threads := pdf.GetArticleThreads(); len(threads) != 1 || len(threads[0].Beads) != 3 {
+		t.Errorf("GetArticleThreads = %+v", threads)
+	}
+	data, err := pdf.GetBytesPdfReturnErr()
+	if err != nil {
+		t.Fatalf("GetBytesPdfReturnErr: %v", err)
+	}
+	store, err := newPDFObjectStore(data)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	threads := pdfRefs(pdfDictGet(store.dict(store.catalog()), "/Threads"))
+	if len(threads) != 1 {
+		t.Fatalf("/Threads = %v", threads)
+	}
+	thread := store.dict(threads[0])
+	if got := decodePDFTextString(pdfDictGet(store.resolveDict(pdfDictGet(thread, "/I")), "/Title")); got != "Lead story" {
+		t.Errorf("thread title = %q", got)
+	}
+	pages := store.pageNums()
+	first, _ := pdfRef(pdfDictGet(thread, "/F"))
+	bead := first
+	var beadPages []int
+	for i := 0; i < 3; i++ {
+		dict := store.dict(bead)
+		p, _ := pdfRef(pdfDictGet(dict, "/P"))
+		beadPages = append(beadPages, p)
+		bead, _ = pdfRef(pdfDictGet(dict, "/N"))
+	}
+	if bead != first || fmt.Sprint(beadPages) != fmt.Sprint([]int{pages[0], pages[0], pages[1]}) {
+		t.Errorf("bead chain pages = %v, back to first = %v", beadPages, bead == first)
+	}
+	if r := pdfNumbers(pdfDictGet(store.dict(first), "/R")); len(r) != 4 || r[0] != 40 || math.Abs(r[3]-(PageSizeA4.H-100)) > 0.01 {
+		t.Errorf("first bead rect = %v", r)
+	}
+	if b := pdfRefs(pdfDictGet(store.dict(pages[0]), "/B")); len(b) != 2 || b[0] != first {
+		t.Errorf("page 1 /B = %v", b)
+	}
+}
+
+// ============================================================
+// Unicode line breaking tests
+// ============================================================
+
+func segmentTexts(text string) []string {
+	var out []string
+	for _, seg := range lineBreakSegments(text) {
+		s := seg.text
+		if seg.spaces != "" {
+			s += " "
+		}
+		if seg.mandatory {
+			s += "|"
+		}
+		out = append(out, s)
+	}
+	return out
+}
+
+func TestLineBreakSegments(t *testing.T) {
+	tests := []struct {
+		in   string
+		want []string
+	}{
+		{"hello world", []string{"hello ", "world"}},
+		{"well-known (test)", []string{"well-", "known ", "(test)"}},
+		{"a b c", []string{"a b ", "c"}},
+		{"one\u200btwo", []string{"one", "two"}},
+		{"a\nb\r\nc", []string{"a|", "b|", "c"}},
+		{"$ 100 or 50%!", []string{"$ ", "100 ", "or ", "50%!"}},
+		{"漢字です。", []string{"漢", "字", "で", "す。"}},
+		{"「東京」へ", []string{"「東", "京」", "へ"}},
+		{"ちょっと", []string{"ちょっ", "と"}},
+		{"データー", []string{"デー", "ター"}},
+		{"日本語text", []string{"日", "本", "語", "text"}},
+	}
+	for _, tt := range tests {
+		if got := segmentTexts(tt.in); fmt.Sprint(got) != fmt.Sprint(tt.want) {
+			t.Errorf("segments(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestSplitText_Unicode(t *testing.T) {
+	pdf := newPDFWithFont(t)
+	pdf.AddPage()
+	opt := &BreakOption{Mode: BreakModeUnicode, Separator: "-"}
+	lines, err := pdf.SplitTextWithOption("The quick brown fox jumps over the lazy dog", 100, opt)
+	if err != nil {
+		t.Fatalf("SplitTextWithOption: %v", err)
+	}
+	if len(lines) < 2 {
+		t.Fatalf("lines = %q", lines)
+	}
+	for _, line := range lines {
+		if strings.HasPrefix(line, " ") || strings.HasSuffix(line, " ") {
+			t.Errorf("line %q has edge spaces", line)
+		}
+		if w, _ := pdf.MeasureTextWidth(line); w > 100 {
+			t.Errorf("line %q is %.1f wide", line, w)
+		}
+	}
+	if got := strings.Join(lines, " "); got != "The quick brown fox jumps over the lazy dog" {
+		t.Errorf("joined lines = %q", got)
+	}
+
+	lines, _ = pdf.SplitTextWithOption("Supercalifragilisticexpialidocious", 60, opt)
+	if len(lines) < 2 || !strings.HasSuffix(lines[0], "-") {
+		t.Errorf("emergency break = %q", lines)
+	}
+	lines, _ = pdf.SplitTextWithOption("a\n\nb\n", 100, opt)
+	if fmt.Sprint(lines) != fmt.Sprint([]string{"a", "", "b"}) {
+		t.Errorf("newlines = %q", lines)
+	}
+
+	ok, h, err := pdf.IsFitMultiCell(&Rect{W: 100, H: 200}, "The quick brown fox jumps over the lazy dog")
+	if err != nil || !ok || h <= 0 {
+		t.Errorf("IsFitMultiCell = %v, %v, %v", ok, h, err)
+	}
+	if ok, _, _ := pdf.IsFitMultiCell(&Rect{W: 100, H: 20}, "The quick brown fox jumps over the lazy dog"); ok {
+		t.Error("IsFitMultiCell fits a too small rectangle")
+	}
+	if err := pdf.MultiCell(&Rect{W: 100, H: 200}, "first line\nsecond line"); err != nil {
+		t.Fatalf("MultiCell: %v", err)
+	}
+	if err := pdf.MultiCellWithOption(&Rect{W: 100, H: 200}, "with option text", CellOption{}); err != nil {
+		t.Fatalf("MultiCellWithOption: %v", err)
+	}
+}
+
+// ============================================================
+// Hyphenation tests
+// ============================================================
+
+const testHyphenPatterns = `% Liang's example patterns
+\patterns{ hy3ph he2n hena4 hen5at 1na n2at 1tio 2io o2n }
+\hyphenation{ ta-ble }`
+
+func TestHyphenator(t *testing.T) {
+	h, err := LoadHyphenator("en-us", strings.NewReader(testHyphenPatterns))
+	if err != nil {
+		t.Fatalf("LoadHyphenator: %v", err)
+	}
+	if got := h.Hyphenate("hyphenation", 0, 0); fmt.Sprint(got) != "[hy phen ation]" {
+		t.Errorf("Hyphenate = %q", got)
+	}
+	if got := h.Hyphenate("Hyphenation,", 3, 3); fmt.Sprint(got) != "[Hyphen ation,]" {
+		t.Errorf("Hyphenate with minimums = %q", got)
+	}
+	if got := h.Hyphenate("tables", 1, 1); fmt.Sprint(got) != "[tables]" {
+		t.Errorf("Hyphenate non-exception = %q", got)
+	}
+	if got := h.Hyphenate("table", 1, 1); fmt.Sprint(got) != "[ta ble]" {
+		t.Errorf("Hyphenate exception = %q", got)
+	}
+	plain, err := LoadHyphenator("en-us", strings.NewReader("hy3ph he2n hena4 hen5at 1na n2at 1tio 2io o2n\n"))
+	if err != nil || fmt.Sprint(plain.Hyphenate("hyphenation", 2, 3)) != "[hy phen ation]" {
+		t.Errorf("plain patterns: %v", err)
+	}
+	if _, err := LoadHyphenator("xx", strings.NewReader("% nothing")); err != ErrNoHyphenationPatterns {
+		t.Errorf("empty patterns: %v", err)
+	}
+
+	pdf := newPDFWithFont(t)
+	pdf.AddPage()
+	for _, mode := range []BreakMode{BreakModeUnicode, BreakModeIndicatorSensitive} {
+		opt := &BreakOption{Mode: mode, BreakIndicator: ' ', Hyphenator: h}
+		w, _ := pdf.MeasureTextWidth("on hyphen-")
+		lines, err := pdf.SplitTextWithOption("on hyphenation", w+1, opt)
+		if err != nil {
+			t.Fatalf("SplitTextWithOption: %v", err)
+		}
+		if fmt.Sprint(lines) != "[on hyphen- ation]" {
+			t.Errorf("mode %d: lines = %q", mode, lines)
+		}
+	}
+	// Words with soft hyphens break only there.
+	w, _ := pdf.MeasureTextWidth("hyphena=")
+	lines, _ := pdf.SplitTextWithOption("hyphena\u00adtion", w, &BreakOption{Mode: BreakModeUnicode, Hyphenator: h, Hyphen: '='})
+	if fmt.Sprint(lines) != "[hyphena= tion]" {
+		t.Errorf("soft hyphen lines = %q", lines)
+	}
+	lines, _ = pdf.SplitTextWithOption("hyphena\u00adtion", 500, &BreakOption{Mode: BreakModeUnicode})
+	if fmt.Sprint(lines) != "[hyphenation]" {
+		t.Errorf("unbroken soft hyphen = %q", lines)
+	}
+}
+
+// ============================================================
+// Paragraph layout tests
+// ============================================================
+
+func TestParagraphLinesOnPage(t *testing.T) {
+	tests := []struct {
+		rest, avail, orphans, widows int
+		empty                        bool
+		want                         int
+	}{
+		{5, 10, 2, 2, false, 5},
+		{10, 5, 0, 0, false, 5},
+		{10, 9, 2, 2, false, 8},  // keep two widows
+		{10, 1, 2, 2, false, 0},  // a single orphan moves the paragraph
+		{3, 2, 2, 2, false, 0},   // cannot satisfy both
+		{50, 40, 2, 3, true, 40}, // a fresh page takes what fits
+		{50, 48, 2, 3, true, 47},
+		{5, 0, 0, 0, true, 1},
+	}
+	for _, tt := range tests {
+		if got := paragraphLinesOnPage(tt.rest, tt.avail, tt.orphans, tt.widows, tt.empty); got != tt.want {
+			t.Errorf("paragraphLinesOnPage(%d, %d, %d, %d, %v) = %d, want %d",
+				tt.rest, tt.avail, tt.orphans, tt.widows, tt.empty, got, tt.want)
+		}
+	}
+}
+
+func TestMultiCellWithOption_Justify(t *testing.T) {
+	pdf := newPDFWithFont(t)
+	pdf.SetNoCompression()
+	pdf.AddPage()
+	text := "The quick brown fox jumps over the lazy dog and keeps running far away.\nSecond paragraph."
+	pdf.SetXY(50, 50)
+	err := pdf.MultiCellWithOption(&Rect{W: 200, H: 100}, text, CellOption{
+		Align: Justify | Top,
+		Paragraph: &ParagraphOption{
+			FirstLineIndent:  20,
+			LineHeight:       20,
+			ParagraphSpacing: 10,
+			LastLineAlign:    Right,
+		},
+	})
+	if err != nil {
+		t.Fatalf("MultiCellWithOption: %v", err)
+	}
+	lines, _ := pdf.SplitTextWithOption("The quick brown fox jumps over the lazy dog and keeps running far away.", 200, &BreakOption{Mode: BreakModeUnicode})
+	wantY := 50 + float64(len(lines))*20 + 10 + 20
+	if got := pdf.GetY(); math.Abs(got-wantY) > 0.01 {
+		t.Errorf("y after paragraphs = %.2f, want %.2f", got, wantY)
+	}
+	first := pdf.getContent().listCache.caches[0].(*cacheContentText)
+	if first.x != 70 || first.cellWidthPdfUnit != 180 {
+		t.Errorf("first line x = %.2f width = %.2f", first.x, first.cellWidthPdfUnit)
+	}
+	if ws, _ := first.justification(); ws <= 0 {
+		t.Errorf("first line word spacing = %.2f", ws)
+	}
+	var buf bytes.Buffer
+	if err := first.write(&buf, nil); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if !strings.Contains(buf.String(), ">-") {
+		t.Errorf("justified line has no TJ adjustments: %s", buf.String())
+	}
+	caches := pdf.getContent().listCache.caches
+	last := caches[len(caches)-1].(*cacheContentText)
+	if ws, cs := last.justification(); ws != 0 || cs != 0 || last.cellOpt.Align&Right != Right {
+		t.Errorf("last line align = %d, spacing %.2f %.2f", last.cellOpt.Align, ws, cs)
+	}
+
+	// CJK text without spaces is spread between characters.
+	cjk := &cacheContentText{text: "ABCD", contentType: ContentTypeCell, rectangle: &Rect{W: 100},
+		cellWidthPdfUnit: 100, textWidthPdfUnit: 70, cellOpt: CellOption{Align: Justify}}
+	if ws, cs := cjk.justification(); ws != 0 || cs != 10 {
+		t.Errorf("character spacing = %.2f %.2f", ws, cs)
+	}
+}
+
+func TestMultiCellWithOption_WidowsAndOrphans(t *testing.T) {
+	pdf := newPDFWithFont(t)
+	pdf.AddPage()
+	pdf.SetY(PageSizeA4.H - 75) // room for three 20pt lines above the 10pt bottom margin
+	pdf.SetMarginBottom(10)
+	var words []string
+	for i := 0; i < 40; i++ {
+		words = append(words, "word")
+	}
+	err := pdf.MultiCellWithOption(&Rect{W: 100, H: 20}, strings.Join(words, " "), CellOption{
+		Paragraph: &ParagraphOption{LineHeight: 20, Orphans: 2, Widows: 2},
+	})
+	if err != nil {
+		t.Fatalf("MultiCellWithOption: %v", err)
+	}
+	if pdf.GetNumberOfPages() != 2 {
+		t.Fatalf("pages = %d", pdf.GetNumberOfPages())
+	}
+	lines, _ := pdf.SplitTextWithOption(strings.Join(words, " "), 100, &BreakOption{Mode: BreakModeUnicode})
+	if want := pdf.MarginTop() + float64(len(lines)-3)*20; math.Abs(pdf.GetY()-want) > 0.01 {
+		t.Errorf("y on page 2 = %.2f, want %.2f (%d lines)", pdf.GetY(), want, len(lines))
+	}
+}
+
+// ============================================================
+// Bidirectional text tests
+// ============================================================
+
+func TestBidiVisual(t *testing.T) {
+	tests := []struct {
+		in   string
+		dir  TextDirection
+		want string
+		rtl  bool
+	}{
+		{"hello world", TextDirectionAuto, "hello world", false},
+		{"abc אבג def", TextDirectionAuto, "abc גבא def", false},
+		{"שלום 123", TextDirectionAuto, "123 םולש", true},
+		{"שלום abc 12", TextDirectionLTR, "םולש abc 12", false},
+		{"א(ב)", TextDirectionRTL, "(ב)א", true},
+		{"אב [cd] ג", TextDirectionRTL, "ג [cd] בא", true},
+		{"price: 10.5% אבג", TextDirectionRTL, "גבא price: 10.5%", true},
+		{"a\u202ebc\u202cd", TextDirectionLTR, "acbd", false},
+		{"א \u2066ab\u2069 ב", TextDirectionRTL, "ב ab א", true},
+		{"abc", TextDirectionNone, "abc", false},
+	}
+	for _, tt := range tests {
+		got, rtl := BidiVisual(tt.in, tt.dir)
+		if got != tt.want || rtl != tt.rtl {
+			t.Errorf("BidiVisual(%q, %d) = %q, %v; want %q, %v", tt.in, tt.dir, got, rtl, tt.want, tt.rtl)
+		}
+	}
+	shaped := []rune(shapeArabic("فاتورة"))
+	for i, j := 0, len(shaped)-1; i < j; i, j = i+1, j-1 {
+		shaped[i], shaped[j] = shaped[j], shaped[i]
+	}
+	if got, want := ToArabic("Invoice #1234 فاتورة"), string(shaped)+" Invoice #1234"; got != want {
+		t.Errorf("ToArabic = %q, want %q", got, want)
+	}
+}
+
+func TestTextDirection_CellAndSplitText(t *testing.T) {
+	pdf := newPDFWithFont(t)
+	pdf.AddPage()
+	pdf.SetTextDirection(TextDirectionAuto)
+	if pdf.GetTextDirection() != TextDirectionAuto {
+		t.Fatal("GetTextDirection")
+	}
+	if err := pdf.CellWithOption(&Rect{W: 200, H: 20}, "abc אבג", CellOption{}); err != nil {
+		t.Fatalf("CellWithOption: %v", err)
+	}
+	if err := pdf.CellWithOption(&Rect{W: 200, H: 20}, "אבג abc", CellOption{Align: Left}); err != nil {
+		t.Fatalf("CellWithOption: %v", err)
+	}
+	caches := pdf.getContent().listCache.caches
+	ltr, rtl := caches[len(caches)-2].(*cacheContentText), caches[len(caches)-1].(*cacheContentText)
+	if ltr.cellOpt.Align&Right == Right {
+		t.Error("left-to-right cell aligned right")
+	}
+	if rtl.cellOpt.Align&Right == Right {
+		t.Error("explicit left alignment overridden")
+	}
+	if err := pdf.Cell(&Rect{W: 200, H: 20}, "שלום"); err != nil {
+		t.Fatalf("Cell: %v", err)
+	}
+	caches = pdf.getContent().listCache.caches
+	if c := caches[len(caches)-1].(*cacheContentText); c.cellOpt.Align&Right != Right {
+		t.Error("right-to-left cell not right-aligned")
+	}
+
+	lines, err := pdf.SplitTextWithOption("one two אבג דהו three", 60, &BreakOption{Mode: BreakModeUnicode})
+	if err != nil {
+		t.Fatalf("SplitTextWithOption: %v", err)
+	}
+	if !strings.Contains(strings.Join(lines, "|"), "והד") {
+		t.Errorf("lines not in visual order: %q", lines)
+	}
+	pdf.SetTextDirection(TextDirectionNone)
+	lines, _ = pdf.SplitTextWithOption("אבג דהו", 500, &BreakOption{Mode: BreakModeUnicode})
+	if fmt.Sprint(lines) != "[אבג דהו]" {
+		t.Errorf("lines without direction = %q", lines)
+	}
+}
+
+// ============================================================
+// Font fallback tests
+// ============================================================
+
+func TestSplitFontRuns(t *testing.T) {
+	// font 0 covers ASCII, font 1 covers CJK and spaces, font 2 covers all
+	covers := func(font int, r rune) bool {
+		switch font {
+		case 0:
+			return r < 0x80
+		case 1:
+			return r == ' ' || r >= 0x4E00 && r <= 0x9FFF
+		}
+		return r != 0x10FFFF
+	}
+	fonts, texts := splitFontRuns("Hi 世界 ok \u00e9\U0010FFFF", 3, covers)
+	if fmt.Sprint(fonts) != "[0 1 0 2 0]" || fmt.Sprintf("%q", texts) != `["Hi " "世界 " "ok " "é" "\U0010ffff"]` {
+		t.Errorf("splitFontRuns = %v %q", fonts, texts)
+	}
+	if fonts, texts := splitFontRuns("", 3, covers); fonts != nil || texts != nil {
+		t.Errorf("empty text = %v %q", fonts, texts)
+	}
+}
+
+func TestFontFallback(t *testing.T) {
+	pdf := newPDFWithFont(t)
+	pdf.AddPage()
+	if err := pdf.AddTTFFont("Fallback", resFontPath); err != nil {
+		t.Fatalf("AddTTFFont: %v", err)
+	}
+	if err := pdf.SetFontFallback(fontFamily, "Missing"); err != ErrMissingFontFamily {
+		t.Errorf("SetFontFallback with a missing family = %v", err)
+	}
+	if err := pdf.SetFontFallback(fontFamily, "Fallback"); err != nil {
+		t.Fatalf("SetFontFallback: %v", err)
+	}
+	if got := pdf.GetFontFallback(fontFamily); fmt.Sprint(got) != "[Fallback]" {
+		t.Errorf("GetFontFallback = %v", got)
+	}
+	if family, ok, err := pdf.GlyphFontFamily('A'); err != nil || !ok || family != fontFamily {
+		t.Errorf("GlyphFontFamily('A') = %q %v %v", family, ok, err)
+	}
+	if _, ok, err := pdf.GlyphFontFamily('\u4e16'); err != nil || ok {
+		t.Errorf("GlyphFontFamily of a missing glyph = %v %v", ok, err)
+	}
+
+	// runs of two fonts drawn as one right-aligned cell
+	fallback := pdf.findSubsetFont("Fallback", Regular)
+	if _, err := fallback.AddChars("cd"); err != nil {
+		t.Fatalf("AddChars: %v", err)
+	}
+	runs := []fontRun{{font: pdf.curr.FontISubset, text: "ab"}, {font: fallback, text: "cd"}}
+	widths, total, err := pdf.runsWidth(runs)
+	if err != nil {
+		t.Fatalf("runsWidth: %v", err)
+	}
+	pdf.SetXY(10, 20)
+	if err := pdf.appendCellRuns(&Rect{W: 200, H: 20}, runs, CellOption{Align: Right | Middle, Border: AllBorders}); err != nil {
+		t.Fatalf("appendCellRuns: %v", err)
+	}
+	caches := pdf.getContent().listCache.caches
+	frame := caches[len(caches)-3].(*cacheContentText)
+	first, second := caches[len(caches)-2].(*cacheContentText), caches[len(caches)-1].(*cacheContentText)
+	if frame.text != "" || frame.cellOpt.Border != AllBorders {
+		t.Errorf("frame cell = %q border %d", frame.text, frame.cellOpt.Border)
+	}
+	if first.fontSubset == second.fontSubset || second.fontCountIndex != fallback.CountOfFont+1 {
+		t.Error("runs not drawn with their fonts")
+	}
+	if math.Abs(first.x-(10+200-total)) > 1e-9 || math.Abs(second.x-(first.x+widths[0])) > 1e-9 {
+		t.Errorf("run x = %v %v, total %v", first.x, second.x, total)
+	}
+	if pdf.curr.FontISubset == fallback || pdf.GetX() != 210 {
+		t.Errorf("current font or x not restored: x = %v", pdf.GetX())
+	}
+
+	w, err := pdf.MeasureTextWidth("Hello")
+	if err != nil || w <= 0 {
+		t.Errorf("MeasureTextWidth = %v %v", w, err)
+	}
+	if err := pdf.Cell(nil, "Hello \u4e16"); err != nil {
+		t.Errorf("Cell: %v", err)
+	}
+	if err := pdf.SetFontFallback(fontFamily); err != nil || pdf.GetFontFallback(fontFamily) != nil {
+		t.Errorf("removing the chain = %v", err)
+	}
+}
+
+// ============================================================
+// Standard 14 font tests
+// ============================================================
+
+func TestStandardFonts(t *testing.T) {
+	pdf := &GoPdf{}
+	pdf.Start(Config{PageSize: *PageSizeA4})
+	pdf.AddPage()
+	if err := pdf.SetFont("Helvetica", "B", 12); err != nil {
+		t.Fatalf("SetFont: %v", err)
+	}
+	if w, err := pdf.MeasureTextWidth("Hello"); err != nil || math.Abs(w-29.34) > 1e-9 {
+		t.Errorf("MeasureTextWidth(Hello) = %v %v, want 29.34", w, err)
+	}
+	if err := pdf.Cell(nil, "Caf\u00e9 \u20ac5"); err != nil {
+		t.Fatalf("Cell: %v", err)
+	}
+	if err := pdf.SetFont("Helvetica", "", 12); err != nil {
+		t.Fatalf("SetFont: %v", err)
+	}
+	if w, err := pdf.MeasureTextWidth("AV"); err != nil || math.Abs(w-(667+667-70)*0.012) > 1e-9 {
+		t.Errorf("kerned MeasureTextWidth(AV) = %v %v", w, err)
+	}
+	for _, k := range []struct {
+		family, style string
+		want          float64
+	}{
+		{"Helvetica", "B", (722 + 667 - 80) * 0.012},
+		{"Helvetica", "BI", (722 + 667 - 80) * 0.012},
+		{"Times", "", (722 + 722 - 135) * 0.012},
+		{"Times", "B", (722 + 722 - 145) * 0.012},
+		{"Times", "I", (611 + 611 - 105) * 0.012},
+		{"Courier", "", 1200 * 0.012},
+	} {
+		if err := pdf.SetFont(k.family, k.style, 12); err != nil {
+			t.Fatalf("SetFont(%s, %s): %v", k.family, k.style, err)
+		}
+		if w, err := pdf.MeasureTextWidth("AV"); err != nil || math.Abs(w-k.want) > 1e-9 {
+			t.Errorf("%s %s: kerned MeasureTextWidth(AV) = %v %v, want %v", k.family, k.style, w, err, k.want)
+		}
+	}
+	if err := pdf.Text("\u65e5\u672c"); !errors.Is(err, ErrCharNotEncodable) {
+		t.Errorf("Text with CJK = %v, want ErrCharNotEncodable", err)
+	}
+	if err := pdf.SetFont("Symbol", "", 12); err != nil {
+		t.Fatalf("SetFont(Symbol): %v", err)
+	}
+	if w, err := pdf.MeasureTextWidth("\u03b1\u2264"); err != nil || math.Abs(w-(631+549)*0.012) > 1e-9 {
+		t.Errorf("MeasureTextWidth(Symbol) = %v %v", w, err)
+	}
+	if err := pdf.Cell(nil, "\u03b1\u2264\u03b2"); err != nil {
+		t.Fatalf("Cell(Symbol): %v", err)
+	}
+	if err := pdf.SetFont("Symbol", "B", 12); err != ErrMissingFontFamily {
+		t.Errorf("SetFont(Symbol, B) = %v", err)
+	}
+
+	out := string(pdf.GetBytesPdf())
+	for _, want := range []string{"/BaseFont /Helvetica-Bold", "/Encoding /WinAnsiEncoding", "/BaseFont /Symbol"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output lacks %q", want)
+		}
+	}
+	if strings.Contains(out, "/FontFile2") {
+		t.Error("standard font embedded")
+	}
+}
+
+func TestLoadStandardFontAFM(t *testing.T) {
+	pdf := &GoPdf{}
+	pdf.Start(Config{PageSize: *PageSizeA4})
+	pdf.AddPage()
+	afm := "StartFontMetrics 4.1\nFontName Times-Roman\nC 65 ; WX 700 ; N A ; B 0 0 700 674 ;\nKPX A V -100\nEndFontMetrics\n"
+	if err := pdf.LoadStandardFontAFM(strings.NewReader(afm)); err != nil {
+		t.Fatalf("LoadStandardFontAFM: %v", err)
+	}
+	if err := pdf.SetFont("Times", "", 10); err != nil {
+		t.Fatalf("SetFont: %v", err)
+	}
+	if w, err := pdf.MeasureTextWidth("AV"); err != nil || math.Abs(w-(700+722-100)*0.01) > 1e-9 {
+		t.Errorf("MeasureTextWidth(AV) = %v %v", w, err)
+	}
+	if err := pdf.LoadStandardFontAFM(strings.NewReader("FontName Arial\n")); !errors.Is(err, ErrNotStandardFont) {
+		t.Errorf("LoadStandardFontAFM(Arial) = %v", err)
+	}
+}
+
+// ============================================================
+// TrueType collection and variable font tests
+// ============================================================
+
+// sfntTables returns the tables of the font at path, by tag.
+func sfntTables(t *testing.T, path string) map[string][]byte {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var p core.TTFParser
+	if err := p.ParseFontData(data); err != nil {
+		t.Fatal(err)
+	}
+	tables := make(map[string][]byte)
+	for tag, entry := range p.GetTables() {
+		tables[tag] = data[entry.Offset : entry.Offset+entry.Length]
+	}
+	return tables
+}
+
+// buildSfnt builds a font of tables whose table offsets start at base.
+func buildSfnt(tables map[string][]byte, base int) []byte {
+	var tags []string
+	for tag := range tables {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+	head := make([]byte, 12+16*len(tags))
+	binary.BigEndian.PutUint32(head, 0x00010000)
+	binary.BigEndian.PutUint16(head[4:], uint16(len(tags)))
+	var body []byte
+	for i, tag := range tags {
+		entry := head[12+16*i:]
+		copy(entry, tag)
+		binary.BigEndian.PutUint32(entry[8:], uint32(base+len(head)+len(body)))
+		binary.BigEndian.PutUint32(entry[12:], uint32(len(tables[tag])))
+		body = append(body, tables[tag]...)
+		for len(body)%4 != 0 {
+			body = append(body, 0)
+		}
+	}
+	return append(head, body...)
+}
+
+func TestTrueTypeCollection(t *testing.T) {
+	tables := sfntTables(t, "test/res/LiberationSerif-Regular.ttf")
+	renamed := make(map[string][]byte)
+	for tag, data := range tables {
+		renamed[tag] = data
+	}
+	utf16 := func(s string) []byte {
+		var b []byte
+		for _, c := range s {
+			b = append(b, 0, byte(c))
+		}
+		return b
+	}
+	name := bytes.ReplaceAll(tables["name"], []byte("LiberationSerif"), []byte("LiberationSerix"))
+	renamed["name"] = bytes.ReplaceAll(name, utf16("LiberationSerif"), utf16("LiberationSerix"))
+
+	ttc := []byte("ttcf\x00\x01\x00\x00\x00\x00\x00\x02")
+	first := buildSfnt(tables, 20)
+	second := buildSfnt(renamed, 20+len(first))
+	ttc = binary.BigEndian.AppendUint32(ttc, 20)
+	ttc = binary.BigEndian.AppendUint32(ttc, uint32(20+len(first)))
+	ttc = append(append(ttc, first...), second...)
+
+	names, err := CollectionFontNames(ttc)
+	if err != nil || len(names) != 2 || names[0] != "LiberationSerif" || names[1] != "LiberationSerix" {
+		t.Fatalf("CollectionFontNames = %v %v", names, err)
+	}
+
+	pdf := &GoPdf{}
+	pdf.Start(Config{PageSize: *PageSizeA4})
+	pdf.AddPage()
+	for i, opt := range []TtfOption{{CollectionIndex: 1}, {CollectionFontName: "LiberationSerif"}} {
+		family := fmt.Sprintf("face%d", i)
+		if err := pdf.AddTTFFontDataWithOption(family, ttc, opt); err != nil {
+			t.Fatalf("AddTTFFontDataWithOption(%+v): %v", opt, err)
+		}
+		if err := pdf.SetFont(family, "", 14); err != nil {
+			t.Fatal(err)
+		}
+		if err := pdf.Cell(nil, "Collection"); err != nil {
+			t.Fatal(err)
+		}
+		if got := pdf.curr.FontISubset.GetTTFParser().PostScriptName(); got != names[1-i] {
+			t.Errorf("face %d = %q, want %q", i, got, names[1-i])
+		}
+	}
+	for _, opt := range []TtfOption{{CollectionIndex: 2}, {CollectionFontName: "Missing"}} {
+		if err := pdf.AddTTFFontDataWithOption("missing", ttc, opt); !errors.Is(err, core.ERROR_COLLECTION_FACE_NOT_FOUND) {
+			t.Errorf("AddTTFFontDataWithOption(%+v) = %v", opt, err)
+		}
+	}
+	if len(pdf.GetBytesPdf()) == 0 {
+		t.Error("empty output")
+	}
+}
+
+// variableLiberation returns Liberation Serif with a wght axis (100-900,
+// default 400) at whose maximum the glyph of A is 100 units wider and 10
+// units higher.
+func variableLiberation(t *testing.T) (font []byte, glyph int, width uint) {
+	t.Helper()
+	data, err := os.ReadFile("test/res/LiberationSerif-Regular.ttf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var p core.TTFParser
+	if err := p.ParseFontData(data); err != nil {
+		t.Fatal(err)
+	}
+	glyph = int(p.Chars()['A'])
+	outline := p.GlyphData(glyph)
+	contours := int(int16(binary.BigEndian.Uint16(outline)))
+	if contours <= 0 {
+		t.Fatalf("A has %d contours", contours)
+	}
+	numPoints := int(binary.BigEndian.Uint16(outline[10+2*(contours-1):])) + 1 + 4
+
+	fvar := []byte{0, 1, 0, 0, 0, 16, 0, 2, 0, 1, 0, 20, 0, 0, 0, 8}
+	fvar = append(fvar, "wght"...)
+	for _, v := range []uint32{100, 400, 900} {
+		fvar = binary.BigEndian.AppendUint32(fvar, v<<16)
+	}
+	fvar = append(fvar, 0, 0, 1, 0)
+
+	packWords := func(b []byte, values []int) []byte {
+		for len(values) > 0 {
+			n := len(values)
+			if n > 64 {
+				n = 64
+			}
+			b = append(b, byte(0x40|(n-1)))
+			for _, v := range values[:n] {
+				b = binary.BigEndian.AppendUint16(b, uint16(int16(v)))
+			}
+			values = values[n:]
+		}
+		return b
+	}
+	dx := make([]int, numPoints)
+	dx[numPoints-3] = 100 // second phantom point: advance width
+	dy := make([]int, numPoints)
+	for i := 0; i < numPoints-4; i++ {
+		dy[i] = 10
+	}
+	deltas := packWords(packWords(nil, dx), dy)
+	glyphData := []byte{0, 1, 0, 10}
+	glyphData = binary.BigEndian.AppendUint16(glyphData, uint16(len(deltas)))
+	glyphData = append(glyphData, 0x80, 0, 0x40, 0) // embedded peak wght=1
+	glyphData = append(glyphData, deltas...)
+
+	glyphCount := int(p.NumGlyphs())
+	gvar := []byte{0, 1, 0, 0, 0, 1, 0, 0}
+	headerSize := 20 + 4*(glyphCount+1)
+	gvar = binary.BigEndian.AppendUint32(gvar, uint32(headerSize))
+	gvar = binary.BigEndian.AppendUint16(gvar, uint16(glyphCount))
+	gvar = binary.BigEndian.AppendUint16(gvar, 1)
+	gvar = binary.BigEndian.AppendUint32(gvar, uint32(headerSize))
+	for g := 0; g <= glyphCount; g++ {
+		offset := 0
+		if g > glyph {
+			offset = len(glyphData)
+		}
+		gvar = binary.BigEndian.AppendUint32(gvar, uint32(offset))
+	}
+	gvar = append(gvar, glyphData...)
+
+	tables := sfntTables(t, "test/res/LiberationSerif-Regular.ttf")
+	tables["fvar"] = fvar
+	tables["gvar"] = gvar
+	return buildSfnt(tables, 0), glyph, p.Widths()[glyph]
+}
+
+func TestVariableFontInstance(t *testing.T) {
+	font, glyph, width := variableLiberation(t)
+
+	var p core.TTFParser
+	if err := p.ParseFontData(font); err != nil {
+		t.Fatal(err)
+	}
+	if axes := p.Axes(); len(axes) != 1 || axes[0] != (core.VariationAxis{Tag: "wght", Min: 100, Default: 400, Max: 900}) {
+		t.Errorf("Axes() = %+v", axes)
+	}
+	if p.IsInstanced() {
+		t.Error("instanced without variation")
+	}
+	yMin := int16(binary.BigEndian.Uint16(p.GlyphData(glyph)[4:]))
+
+	for _, tc := range []struct {
+		wght  float64
+		width uint
+		yMin  int16
+	}{{900, width + 100, yMin + 10}, {650, width + 50, yMin + 5}, {400, width, yMin}, {100, width, yMin}} {
+		var p core.TTFParser
+		p.SetVariation(map[string]float64{"wght": tc.wght})
+		if err := p.ParseFontData(font); err != nil {
+			t.Fatal(err)
+		}
+		if got := p.Widths()[glyph]; got != tc.width {
+			t.Errorf("wght %v: width = %d, want %d", tc.wght, got, tc.width)
+		}
+		if got := int16(binary.BigEndian.Uint16(p.GlyphData(glyph)[4:])); got != tc.yMin {
+			t.Errorf("wght %v: yMin = %d, want %d", tc.wght, got, tc.yMin)
+		}
+		if hmtx := p.HmtxData(); binary.BigEndian.Uint16(hmtx[4*glyph:]) != uint16(tc.width) {
+			t.Errorf("wght %v: hmtx advance = %d", tc.wght, binary.BigEndian.Uint16(hmtx[4*glyph:]))
+		}
+	}
+
+	pdf := &GoPdf{}
+	pdf.Start(Config{PageSize: *PageSizeA4})
+	pdf.AddPage()
+	for family, wght := range map[string]float64{"regular": 400, "black": 900} {
+		opt := defaultTtfFontOption()
+		opt.Variations = map[string]float64{"wght": wght}
+		if err := pdf.AddTTFFontDataWithOption(family, font, opt); err != nil {
+			t.Fatalf("AddTTFFontDataWithOption(%s): %v", family, err)
+		}
+	}
+	widths := make(map[string]float64)
+	for _, family := range []string{"regular", "black"} {
+		if err := pdf.SetFont(family, "", 20); err != nil {
+			t.Fatal(err)
+		}
+		if err := pdf.Cell(nil, "AAA"); err != nil {
+			t.Fatal(err)
+		}
+		widths[family], _ = pdf.MeasureTextWidth("A")
+	}
+	if want := 20 * 100 / float64(p.UnitsPerEm()); math.Abs(widths["black"]-widths["regular"]-want) > 0.021 {
+		t.Errorf("width difference = %v, want %v", widths["black"]-widths["regular"], want)
+	}
+	ensureOutDir(t)
+	if err := pdf.WritePdf(resOutDir + "/variable_font.pdf"); err != nil {
+		t.Fatal(err)
+	}
+
+	opt := defaultTtfFontOption()
+	opt.Variations = map[string]float64{"wdth": 75}
+	if err := pdf.AddTTFFontDataWithOption("wdth", font, opt); !errors.Is(err, core.ERROR_UNKNOWN_VARIATION_AXIS) {
+		t.Errorf("unknown axis = %v", err)
+	}
+	opt.Variations = map[string]float64{"wght": 700}
+	if err := pdf.AddTTFFontWithOption("static", "test/res/LiberationSerif-Regular.ttf", opt); err != core.ERROR_NOT_VARIABLE_FONT {
+		t.Errorf("static font = %v", err)
+	}
+}
+
+// ============================================================
+// Vertical writing tests
+// ============================================================
+
+// verticalLiberation returns Liberation Serif with vertical metrics of
+// half an em (top side bearing 100) and a GSUB "vert" feature, in an
+// extension lookup, that substitutes ÷ for ×.
+func verticalLiberation(t *testing.T) []byte {
+	t.Helper()
+	tables := sfntTables(t, "test/res/LiberationSerif-Regular.ttf")
+	var p core.TTFParser
+	if err := p.ParseFontData(buildSfnt(tables, 0)); err != nil {
+		t.Fatal(err)
+	}
+	u16 := func(b []byte, vs ...int) []byte {
+		for _, v := range vs {
+			b = binary.BigEndian.AppendUint16(b, uint16(v))
+		}
+		return b
+	}
+	gsub := u16(nil, 1, 0, 10, 12, 26)
+	gsub = u16(gsub, 0)                                      // ScriptList
+	gsub = append(u16(gsub, 1), "vert"...)                   // FeatureList
+	gsub = u16(gsub, 8, 0, 1, 0)                             // Feature
+	gsub = u16(gsub, 1, 4)                                   // LookupList
+	gsub = u16(gsub, 7, 0, 1, 8)                             // Lookup
+	gsub = binary.BigEndian.AppendUint32(u16(gsub, 1, 1), 8) // Extension
+	gsub = u16(gsub, 2, 8, 1, int(p.Chars()['÷']))           // SingleSubst format 2
+	gsub = u16(gsub, 1, 1, int(p.Chars()['×']))              // Coverage
+	vhea := u16(nil, 1, 0x1000, 880, -120, 0, 2048, 0, 0, 2048, 1, 0, 0, 0, 0, 0, 0, 0, 1)
+	vmtx := u16(nil, int(p.UnitsPerEm())/2)
+	for g := uint(0); g < p.NumGlyphs(); g++ {
+		vmtx = u16(vmtx, 100)
+	}
+	tables["GSUB"] = gsub
+	tables["vhea"] = vhea
+	tables["vmtx"] = vmtx
+	return buildSfnt(tables, 0)
+}
+
+func TestVerticalSubstitutionsAndMetrics(t *testing.T) {
+	var p core.TTFParser
+	if err := p.ParseFontData(verticalLiberation(t)); err != nil {
+		t.Fatal(err)
+	}
+	subs, err := p.VerticalSubstitutions()
+	if err != nil || len(subs) != 1 || subs[p.Chars()['×']] != p.Chars()['÷'] {
+		t.Errorf("VerticalSubstitutions() = %v %v", subs, err)
+	}
+	glyph := p.Chars()['A']
+	yMax := int(int16(binary.BigEndian.Uint16(p.GlyphData(int(glyph))[8:])))
+	if advance, originY, ok := p.VerticalMetric(glyph); !ok || advance != p.UnitsPerEm()/2 || originY != 100+yMax {
+		t.Errorf("VerticalMetric(A) = %d %d %v", advance, originY, ok)
+	}
+}
+
+func TestVerticalRuns(t *testing.T) {
+	pdf := &GoPdf{}
+	pdf.Start(Config{PageSize: *PageSizeA4})
+	pdf.AddPage()
+	if err := pdf.AddTTFFontData("vertical", verticalLiberation(t)); err != nil {
+		t.Fatal(err)
+	}
+	if err := pdf.SetFont("vertical", "", 10); err != nil {
+		t.Fatal(err)
+	}
+	pdf.SetWritingMode(WritingModeVertical)
+	runs, err := pdf.verticalRuns("§12×abc2024…")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, run := range runs {
+		got = append(got, fmt.Sprintf("%d:%s", run.kind, run.text))
+	}
+	if want := "0:§|2:12|0:×|1:abc2024|0:…"; strings.Join(got, "|") != want {
+		t.Errorf("runs = %s, want %s", strings.Join(got, "|"), want)
+	}
+	sub := pdf.curr.FontISubset
+	if divide, _ := sub.CharCodeToGlyphIndex('÷'); runs[2].glyphs[0] != divide {
+		t.Errorf("× not substituted: %v", runs[2].glyphs)
+	}
+	if runs[0].advance != 5 || runs[1].advance != 10 {
+		t.Errorf("advances = %v %v, want 5 10", runs[0].advance, runs[1].advance)
+	}
+
+	pdf.SetTateChuYoko(0)
+	if runs, _ := pdf.verticalRuns("12"); len(runs) != 1 || runs[0].kind != verticalRotated {
+		t.Errorf("runs without tate-chu-yoko = %+v", runs)
+	}
+	for _, r := range "漢あ©…" {
+		if !isVerticalUpright(r) {
+			t.Errorf("%q rotated", r)
+		}
+	}
+	for _, r := range "a1—→" {
+		if isVerticalUpright(r) {
+			t.Errorf("%q upright", r)
+		}
+	}
+}
+
+func TestVerticalWritingMode(t *testing.T) {
+	pdf := &GoPdf{}
+	pdf.Start(Config{PageSize: *PageSizeA4})
+	pdf.SetNoCompression()
+	pdf.AddPage()
+	if err := pdf.AddTTFFontData("vertical", verticalLiberation(t)); err != nil {
+		t.Fatal(err)
+	}
+	if err := pdf.SetFont("vertical", "", 10); err != nil {
+		t.Fatal(err)
+	}
+	pdf.SetWritingMode(WritingModeVertical)
+	if pdf.GetWritingMode() != WritingModeVertical {
+		t.Fatal("GetWritingMode")
+	}
+
+	length, err := pdf.MeasureTextWidth("§×12")
+	if err != nil || length != 20 {
+		t.Errorf("MeasureTextWidth = %v %v, want 20", length, err)
+	}
+	pdf.SetXY(500, 50)
+	if err := pdf.Text("§×12"); err != nil {
+		t.Fatal(err)
+	}
+	if x, y := pdf.GetX(), pdf.GetY(); x != 500 || y != 70 {
+		t.Errorf("position after Text = %v %v, want 500 70", x, y)
+	}
+
+	pdf.SetXY(400, 50)
+	if err := pdf.CellWithOption(&Rect{W: 20, H: 100}, "Page 12", CellOption{Align: Middle, Border: AllBorders}); err != nil {
+		t.Fatal(err)
+	}
+	if x, y := pdf.GetX(), pdf.GetY(); x != 400 || y != 150 {
+		t.Errorf("position after Cell = %v %v, want 400 150", x, y)
+	}
+
+	pdf.SetXY(100, 200)
+	text := strings.Repeat("§×", 30)
+	if err := pdf.MultiCell(&Rect{W: 200, H: 100}, text); err != nil {
+		t.Fatal(err)
+	}
+	_, columnWidth, _, _ := createContent(pdf.curr.FontISubset, "", 10, 0, nil)
+	if want := 300 - 3*columnWidth; math.Abs(pdf.GetX()-want) > 1e-9 || pdf.GetY() != 200 {
+		t.Errorf("position after MultiCell = %v %v, want %v 200", pdf.GetX(), pdf.GetY(), want)
+	}
+
+	out := string(pdf.GetBytesPdf())
+	for _, want := range []string{"/Encoding /Identity-V", "/Encoding /Identity-H", "/DW2 [", "/W2 [", "0 -1 1 0 ", " Tz\n"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output lacks %q", want)
+		}
+	}
+	ensureOutDir(t)
+	if err := pdf.WritePdf(resOutDir + "/vertical_text.pdf"); err != nil {
+		t.Fatal(err)
+	}
+
+	pdf.SetFont("Helvetica", "", 10)
+	if err := pdf.Text("abc"); err != ErrVerticalFont {
+		t.Errorf("Text with a standard font = %v, want ErrVerticalFont", err)
+	}
+}
+
+// ============================================================
+// Text state and decoration tests
+// ============================================================
+
+// textStateStream returns the output of a page with "a b" drawn by Text
+// at (10, 100) in Liberation Serif 10 with setup applied before.
+func textStateStream(t *testing.T, style string, setup func(pdf *GoPdf)) string {
+	t.Helper()
+	pdf := newPDFWithFont(t)
+	pdf.SetNoCompression()
+	pdf.AddPage()
+	if err := pdf.SetFont(fontFamily, style, 10); err != nil {
+		t.Fatal(err)
+	}
+	setup(pdf)
+	pdf.SetXY(10, 100)
+	if err := pdf.Text("a b"); err != nil {
+		t.Fatal(err)
+	}
+	return string(pdf.GetBytesPdf())
+}
+
+func TestTextStateMeasure(t *testing.T) {
+	pdf := newPDFWithFont(t)
+	pdf.AddPage()
+	w, err := pdf.MeasureTextWidth("a b c")
+	if err != nil {
+		t.Fatal(err)
+	}
+	pdf.SetWordSpacing(2)
+	if err := pdf.SetTextHorizontalScale(50); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := pdf.MeasureTextWidth("a b c"); err != nil || math.Abs(got-(w+4)/2) > 1e-9 {
+		t.Errorf("MeasureTextWidth = %v %v, want %v", got, err, (w+4)/2)
+	}
+	pdf.SetXY(10, 10)
+	if err := pdf.Text("a b c"); err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(pdf.GetX()-(10+(w+4)/2)) > 1e-9 {
+		t.Errorf("X after Text = %v, want %v", pdf.GetX(), 10+(w+4)/2)
+	}
+	if err := pdf.SetTextHorizontalScale(0); err != ErrTextHorizontalScale {
+		t.Errorf("SetTextHorizontalScale(0) = %v", err)
+	}
+}
+
+func TestTextStateOperators(t *testing.T) {
+	out := textStateStream(t, "", func(pdf *GoPdf) {
+		pdf.SetTextRenderMode(TextRenderFillStroke)
+		pdf.SetTextStrokeColor(255, 0, 0)
+		pdf.SetTextStrokeWidth(0.5)
+		pdf.SetTextRise(3)
+		pdf.SetTextHorizontalScale(80)
+		pdf.SetWordSpacing(2)
+	})
+	for _, want := range []string{"q\n1.000 0.000 0.000 RG\n0.5 w\nBT\n", " Tc\n2 Tr\n3 Ts\n80 Tz\n", ">-200<", "ET\nQ\n"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output lacks %q", want)
+		}
+	}
+
+	// clipping text is not isolated, and the text state is reset after it
+	out = textStateStream(t, "U", func(pdf *GoPdf) {
+		pdf.SetTextRenderMode(TextRenderClip)
+		pdf.SetTextStrokeWidth(0.5)
+	})
+	if !strings.Contains(out, "ET\n0 Tr\n") || strings.Contains(out, "q\n0.5 w") {
+		t.Error("clipping text state not written as expected")
+	}
+	if strings.Contains(out, "re f") {
+		t.Error("invisible text underlined")
+	}
+
+	out = textStateStream(t, "", func(pdf *GoPdf) {})
+	for _, op := range []string{" Tr\n", " Ts\n", " Tz\n", " w\n"} {
+		if strings.Contains(out, op) {
+			t.Errorf("default text state writes %q", op)
+		}
+	}
+}
+
+func TestStrikethroughAndOverline(t *testing.T) {
+	if style := getConvertedStyle("BSO"); style != Bold|Strikethrough|Overline {
+		t.Errorf("getConvertedStyle(BSO) = %d", style)
+	}
+	var sub *SubsetFontObj
+	out := textStateStream(t, "SO", func(pdf *GoPdf) { sub = pdf.curr.FontISubset })
+	ttfp := sub.GetTTFParser()
+	if ttfp.StrikeoutSize() != 100 || ttfp.StrikeoutPosition() != 420 {
+		t.Errorf("strikeout = %d %d, want 100 420", ttfp.StrikeoutSize(), ttfp.StrikeoutPosition())
+	}
+
+	baseline := PageSizeA4.H - 100
+	_, _, width, err := createContent(sub, "a b", 10, 0, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	strike := fmt.Sprintf("10.00 %0.2f %0.2f %0.2f re f\n", baseline+(420.0-100)/2048*10, width, 100.0/2048*10)
+	over := fmt.Sprintf("10.00 %0.2f %0.2f %0.2f re f\n", baseline+float64(ttfp.TypoAscender())/2048*10, width, float64(ttfp.UnderlineThickness())/2048*10)
+	for _, want := range []string{strike, over} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output lacks %q", want)
+		}
+	}
+}
+
+func TestScriptMetrics(t *testing.T) {
+	pdf := newPDFWithFont(t)
+	pdf.AddPage()
+	size, rise := pdf.curr.FontISubset.scriptMetrics(12, false)
+	if math.Abs(size-12*1331.0/2048) > 1e-9 || math.Abs(rise-12*928.0/2048) > 1e-9 {
+		t.Errorf("superscript = %v %v", size, rise)
+	}
+	size, rise = pdf.curr.FontISubset.scriptMetrics(12, true)
+	if math.Abs(size-12*1331.0/2048) > 1e-9 || math.Abs(rise+12*293.0/2048) > 1e-9 {
+		t.Errorf("subscript = %v %v", size, rise)
+	}
+	pdf.SetFont("Helvetica", "", 12)
+	if size, rise := pdf.curr.FontISubset.scriptMetrics(12, false); math.Abs(size-8.4) > 1e-9 || rise != 4 {
+		t.Errorf("standard superscript = %v %v", size, rise)
+	}
+}
+
+func TestHTMLStrikethroughAndScripts(t *testing.T) {
+	pdf := newPDFWithFont(t)
+	pdf.SetNoCompression()
+	pdf.AddPage()
+	opt := HTMLBoxOption{DefaultFontFamily: fontFamily, DefaultFontSize: 12}
+	r := newHTMLRenderer(pdf, opt, 10, 10, 300, 200)
+	state := r.initialState()
+	sup := r.scriptState(state, false)
+	sub := r.scriptState(state, true)
+	if sup.rise <= 0 || sub.rise >= 0 || sup.fontSize >= 12 {
+		t.Errorf("script states = %+v %+v", sup, sub)
+	}
+
+	html := `<p>H<sub>2</sub>O x<sup>2</sup> <del>old</del> <span style="text-decoration: overline">new</span></p>`
+	if _, err := pdf.InsertHTMLBox(10, 10, 300, 200, html, opt); err != nil {
+		t.Fatal(err)
+	}
+	if pdf.curr.textState.rise != 0 {
+		t.Errorf("text rise after InsertHTMLBox = %v", pdf.curr.textState.rise)
+	}
+	out := string(pdf.GetBytesPdf())
+	for _, want := range []string{FormatFloatTrim(sup.rise) + " Ts\n", FormatFloatTrim(sub.rise) + " Ts\n"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output lacks %q", want)
+		}
+	}
+	if n := strings.Count(out, " re f\n"); n != 2 {
+		t.Errorf("%d decoration lines, want 2", n)
+	}
+}
+
+// ============================================================
+// 16-bit, interlaced and ICC-tagged PNG, TIFF and BMP images
+// ============================================================
+
+// pngChunk returns the PNG chunk typ with data.
+func pngChunk(typ string, data []byte) []byte {
+	chunk := binary.BigEndian.AppendUint32(nil, uint32(len(data)))
+	chunk = append(chunk, typ...)
+	chunk = append(chunk, data...)
+	return binary.BigEndian.AppendUint32(chunk, crc32.ChecksumIEEE(chunk[4:]))
+}
+
+// withPNGChunk returns the PNG b with chunk inserted after IHDR.
+func withPNGChunk(b, chunk []byte) []byte {
+	return append(append(append([]byte(nil), b[:33]...), chunk...), b[33:]...)
+}
+
+func encodePNG(t *testing.T, img image.Image) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func inflate(t *testing.T, data []byte) []byte {
+	t.Helper()
+	zr, err := zlib.NewReader(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := io.ReadAll(zr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+// imagePDF returns the uncompressed output of a page with the images of
+// holders.
+func imagePDF(t *testing.T, holders ...ImageHolder) string {
+	t.Helper()
+	pdf := &GoPdf{}
+	pdf.Start(Config{PageSize: *PageSizeA4})
+	pdf.SetNoCompression()
+	pdf.AddPage()
+	for _, h := range holders {
+		if err := pdf.ImageByHolder(h, 10, 10, &Rect{W: 50, H: 50}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return string(pdf.GetBytesPdf())
+}
+
+func TestPNG16Bit(t *testing.T) {
+	img := image.NewNRGBA64(image.Rect(0, 0, 5, 3))
+	for y := 0; y < 3; y++ {
+		for x := 0; x < 5; x++ {
+			img.SetNRGBA64(x, y, color.NRGBA64{R: uint16(x * 9000), G: 0x1234, B: uint16(y * 20000), A: uint16(0xffff - x*1000)})
+		}
+	}
+	info, err := parseImg(bytes.NewReader(encodePNG(t, img)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.bitsPerComponent != "16" || info.colspace != "DeviceRGB" {
+		t.Errorf("bits = %s, color space = %s", info.bitsPerComponent, info.colspace)
+	}
+	if n := len(inflate(t, info.data)); n != 3*(1+5*6) {
+		t.Errorf("%d bytes of color, want %d", n, 3*(1+5*6))
+	}
+	if n := len(inflate(t, info.smask)); n != 3*(1+5*2) {
+		t.Errorf("%d bytes of alpha, want %d", n, 3*(1+5*2))
+	}
+
+	h, err := ImageHolderByBytes(encodePNG(t, img))
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := imagePDF(t, h)
+	if n := strings.Count(out, "/BitsPerComponent 16"); n < 3 {
+		t.Errorf("%d /BitsPerComponent 16, want image, mask and their predictors", n)
+	}
+}
+
+func TestInterlacedPNG(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 9, 7))
+	for y := 0; y < 7; y++ {
+		for x := 0; x < 9; x++ {
+			img.Set(x, y, color.RGBA{uint8(x * 28), uint8(y * 36), 100, 255})
+		}
+	}
+	// Adam7 passes of the pixels, unfiltered
+	var raw []byte
+	for _, p := range [][4]int{{0, 0, 8, 8}, {4, 0, 8, 8}, {0, 4, 4, 8}, {2, 0, 4, 4}, {0, 2, 2, 4}, {1, 0, 2, 2}, {0, 1, 1, 2}} {
+		if p[0] >= 9 || p[1] >= 7 {
+			continue
+		}
+		for y := p[1]; y < 7; y += p[3] {
+			raw = append(raw, 0)
+			for x := p[0]; x < 9; x += p[2] {
+				c := img.RGBAAt(x, y)
+				raw = append(raw, c.R, c.G, c.B)
+			}
+		}
+	}
+	var idat bytes.Buffer
+	zw := zlib.NewWriter(&idat)
+	zw.Write(raw)
+	zw.Close()
+	ihdr := []byte{0, 0, 0, 9, 0, 0, 0, 7, 8, 2, 0, 0, 1}
+	b := []byte("\x89PNG\r\n\x1a\n")
+	b = append(b, pngChunk("IHDR", ihdr)...)
+	b = append(b, pngChunk("IDAT", idat.Bytes())...)
+	b = append(b, pngChunk("IEND", nil)...)
+
+	decoded, err := png.Decode(bytes.NewReader(b))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r, g, _, _ := decoded.At(8, 6).RGBA(); r>>8 != 8*28 || g>>8 != 6*36 {
+		t.Fatalf("interlaced fixture decodes to %v", decoded.At(8, 6))
+	}
+	info, err := parseImg(bytes.NewReader(b))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.w != 9 || info.h != 7 || info.colspace != "DeviceRGB" || info.bitsPerComponent != "8" {
+		t.Errorf("info = %dx%d %s %s", info.w, info.h, info.colspace, info.bitsPerComponent)
+	}
+}
+
+func TestPNGColorProfiles(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 2, 2))
+	b := encodePNG(t, img)
+	profile := make([]byte, 128)
+	copy(profile[16:], "RGB ")
+	var zprofile bytes.Buffer
+	zw := zlib.NewWriter(&zprofile)
+	zw.Write(profile)
+	zw.Close()
+	iccp := pngChunk("iCCP", append([]byte("test\x00\x00"), zprofile.Bytes()...))
+
+	h, err := ImageHolderByBytes(withPNGChunk(b, iccp))
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := imagePDF(t, h)
+	for _, want := range []string{"/ColorSpace [/ICCBased ", "/N 3\n/Alternate /DeviceRGB\n"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output lacks %q", want)
+		}
+	}
+
+	// a profile of other color components is ignored
+	gray := encodePNG(t, image.NewGray(image.Rect(0, 0, 2, 2)))
+	info, err := parseImg(bytes.NewReader(withPNGChunk(gray, iccp)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.icc != nil {
+		t.Error("RGB profile kept for a gray image")
+	}
+
+	gama := pngChunk("gAMA", binary.BigEndian.AppendUint32(nil, 45455))
+	info, err = parseImg(bytes.NewReader(withPNGChunk(b, gama)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(info.calColorSpace, "[/CalRGB << /WhitePoint [0.9505 1 1.0891] /Gamma [2.2000 2.2000 2.2000] /Matrix [0.4124 0.2126 0.0193 ") {
+		t.Errorf("color space = %s", info.calColorSpace)
+	}
+	info, err = parseImg(bytes.NewReader(withPNGChunk(withPNGChunk(b, gama), pngChunk("sRGB", []byte{0}))))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.calColorSpace != "" {
+		t.Errorf("sRGB image color space = %s", info.calColorSpace)
+	}
+}
+
+// tiffTestPage is a page of buildTIFF.
+type tiffTestPage struct {
+	w, h, bps, samples, compression, photometric int
+	strip                                        []byte
+	extra                                        [][2]int // more LONG fields
+}
+
+// buildTIFF returns a little-endian TIFF of pages, each of one strip.
+func buildTIFF(pages []tiffTestPage) []byte {
+	b := []byte("II\x2a\x00\x00\x00\x00\x00")
+	next := 4 // offset of the offset of the next IFD
+	for _, p := range pages {
+		stripOffset := len(b)
+		b = append(b, p.strip...)
+		if len(b)%2 == 1 {
+			b = append(b, 0)
+		}
+		bpsOffset := len(b)
+		for i := 0; i < p.samples; i++ {
+			b = binary.LittleEndian.AppendUint16(b, uint16(p.bps))
+		}
+		binary.LittleEndian.PutUint32(b[next:], uint32(len(b)))
+		entries := [][2]int{
+			{tiffImageWidth, p.w}, {tiffImageLength, p.h}, {tiffBitsPerSample, p.bps},
+			{tiffCompression, p.compression}, {tiffPhotometric, p.photometric},
+			{tiffStripOffsets, stripOffset}, {tiffSamplesPerPixel, p.samples},
+			{tiffRowsPerStrip, p.h}, {tiffStripByteCounts, len(p.strip)},
+		}
+		entries = append(entries, p.extra...)
+		b = binary.LittleEndian.AppendUint16(b, uint16(len(entries)))
+		for _, e := range entries {
+			b = binary.LittleEndian.AppendUint16(b, uint16(e[0]))
+			b = binary.LittleEndian.AppendUint16(b, 4) // LONG
+			if e[0] == tiffBitsPerSample && p.samples > 2 {
+				b = binary.LittleEndian.AppendUint16(b[:len(b)-2], 3) // SHORT
+				b = binary.LittleEndian.AppendUint32(b, uint32(p.samples))
+				b = binary.LittleEndian.AppendUint32(b, uint32(bpsOffset))
+				continue
+			}
+			b = binary.LittleEndian.AppendUint32(b, 1)
+			b = binary.LittleEndian.AppendUint32(b, uint32(e[1]))
+		}
+		next = len(b)
+		b = append(b, 0, 0, 0, 0)
+	}
+	return b
+}
+
+func TestTIFFPages(t *testing.T) {
+	gray := []byte{0, 50, 100, 150, 200, 250, 255, 0}
+	rgb := bytes.Repeat([]byte{255, 0, 0, 1, 0, 255}, 2) // differenced
+	var zrgb bytes.Buffer
+	zw := zlib.NewWriter(&zrgb)
+	zw.Write(rgb)
+	zw.Close()
+	b := buildTIFF([]tiffTestPage{
+		{w: 4, h: 2, bps: 8, samples: 1, compression: 1, photometric: 1, strip: gray},
+		// every row of a white image is the vertical mode code 1 in G4
+		{w: 16, h: 8, bps: 1, samples: 1, compression: 4, photometric: 0, strip: []byte{0xff}},
+		{w: 2, h: 2, bps: 8, samples: 3, compression: 8, photometric: 2, strip: zrgb.Bytes(), extra: [][2]int{{tiffPredictor, 2}}},
+	})
+
+	n, err := TIFFPageCount(b)
+	if err != nil || n != 3 {
+		t.Fatalf("TIFFPageCount = %d, %v", n, err)
+	}
+	info, err := parseImgPage(bytes.NewReader(b), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.colspace != "DeviceGray" || info.filter != "FlateDecode" || !bytes.Equal(inflate(t, info.data), gray) {
+		t.Errorf("page 0 = %s %s", info.colspace, info.filter)
+	}
+	info, err = parseImgPage(bytes.NewReader(b), 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.filter != "CCITTFaxDecode" || info.decodeParms != "/K -1 /Columns 16 /Rows 8 /EncodedByteAlign false" || info.decode != "" || info.bitsPerComponent != "1" {
+		t.Errorf("page 1 = %s %q %q", info.filter, info.decodeParms, info.decode)
+	}
+	info, err = parseImgPage(bytes.NewReader(b), 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.colspace != "DeviceRGB" || !bytes.Equal(info.data, zrgb.Bytes()) {
+		t.Errorf("page 2 = %s, data not passed through", info.colspace)
+	}
+	if info.decodeParms != "/Predictor 2 /Colors 3 /BitsPerComponent 8 /Columns 2" {
+		t.Errorf("page 2 decode parameters = %q", info.decodeParms)
+	}
+	if _, err := parseImgPage(bytes.NewReader(b), 3); !errors.Is(err, ErrImagePageNotFound) {
+		t.Errorf("page 3 error = %v", err)
+	}
+	if _, err := ImageHolderByTIFFPage(b, 3); !errors.Is(err, ErrImagePageNotFound) {
+		t.Errorf("ImageHolderByTIFFPage(3) error = %v", err)
+	}
+
+	var holders []ImageHolder
+	for page := 0; page < n; page++ {
+		h, err := ImageHolderByTIFFPage(b, page)
+		if err != nil {
+			t.Fatal(err)
+		}
+		holders = append(holders, h)
+	}
+	out := imagePDF(t, holders...)
+	if n := strings.Count(out, "/Subtype /Image"); n != 3 {
+		t.Errorf("%d images, want 3", n)
+	}
+	if !strings.Contains(out, "/Filter /CCITTFaxDecode") {
+		t.Error("G4 page is not embedded as CCITTFaxDecode")
+	}
+}
+
+func TestTIFFAndBMPEncoded(t *testing.T) {
+	gray := image.NewGray(image.Rect(0, 0, 6, 4))
+	for i := range gray.Pix {
+		gray.Pix[i] = uint8(i * 10)
+	}
+	var buf bytes.Buffer
+	if err := tiff.Encode(&buf, gray, &tiff.Options{Compression: tiff.Deflate}); err != nil {
+		t.Fatal(err)
+	}
+	info, err := parseImg(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.formatName != "tiff" || info.filter != "FlateDecode" || info.decodeParms != "" || !bytes.Equal(inflate(t, info.data), gray.Pix) {
+		t.Errorf("deflate TIFF = %s %s %q", info.formatName, info.filter, info.decodeParms)
+	}
+
+	// little-endian 16-bit compressed samples are decoded
+	gray16 := image.NewGray16(image.Rect(0, 0, 3, 3))
+	buf.Reset()
+	if err := tiff.Encode(&buf, gray16, &tiff.Options{Compression: tiff.Deflate}); err != nil {
+		t.Fatal(err)
+	}
+	info, err = parseImg(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.formatName != "tiff" || info.bitsPerComponent != "16" || info.colspace != "DeviceGray" {
+		t.Errorf("16-bit TIFF = %s %s %s", info.formatName, info.bitsPerComponent, info.colspace)
+	}
+
+	buf.Reset()
+	if err := bmp.Encode(&buf, gray); err != nil {
+		t.Fatal(err)
+	}
+	h, err := ImageHolderByBytes(buf.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out := imagePDF(t, h); !strings.Contains(out, "/Subtype /Image") {
+		t.Error("BMP image not embedded")
+	}
+}
@@ -87,19 +87,22 @@ func (o *OutlinesObj) Count() int {
 
 // OutlineObj include attribute of outline
 type OutlineObj struct { //impl IObj
-	title     string
-	index     int
-	dest      int
-	parent    int
-	prev      int
-	next      int
-	first     int
-	last      int
-	height    float64
-	color     [3]float64 // /C array [R G B] (0.0-1.0)
-	bold      bool       // /F bit 1
-	italic    bool       // /F bit 0
-	collapsed bool       // negative /Count
+	title       string
+	index       int
+	dest        int
+	parent      int
+	prev        int
+	next        int
+	first       int
+	last        int
+	height      float64
+	color       [3]float64   // /C array [R G B] (0.0-1.0)
+	bold        bool         // /F bit 1
+	italic      bool         // /F bit 0
+	collapsed   bool         // negative /Count
+	action      *Action      // /A instead of /Dest
+	destination *Destination // explicit or named /Dest
+	getRoot     func() *GoPdf
 }
 
 func (o *OutlineObj) init(funcGetRoot func() *GoPdf) {
@@ -167,7 +170,21 @@ func (o *OutlineObj) write(w io.Writer, objID int) error {
 	if o.first > 0 && o.collapsed {
 		fmt.Fprintf(w, "  /Count -%d\n", o.countChildren())
 	}
-	fmt.Fprintf(w, "  /Dest [ %d 0 R /XYZ 90 %f 0 ]\n", o.dest, o.height)
+	action := ""
+	if o.action != nil {
+		action = actionWriter{o.getRoot(), objID}.dict(*o.action)
+	}
+	dest := ""
+	if o.destination != nil {
+		dest = actionWriter{o.getRoot(), objID}.dest(*o.destination, true)
+	}
+	if action != "" {
+		fmt.Fprintf(w, "  /A %s\n", action)
+	} else if dest != "" {
+		fmt.Fprintf(w, "  /Dest %s\n", dest)
+	} else {
+		fmt.Fprintf(w, "  /Dest [ %d 0 R /XYZ 90 %f 0 ]\n", o.dest, o.height)
+	}
 	fmt.Fprintf(w, "  /Title <FEFF%s>\n", encodeUtf8(o.title))
 	// Color (non-black).
 	if o.color != [3]float64{} {
@@ -145,6 +145,7 @@ func (gp *GoPdf) swapFrom(other *GoPdf) {
 	gp.margins = other.margins
 	gp.anchors = other.anchors
 	gp.placeHolderTexts = other.placeHolderTexts
+	gp.pageTokens = other.pageTokens
 	gp.embeddedFiles = other.embeddedFiles
 	gp.pdfVersion = other.pdfVersion
 	gp.pageLabels = other.pageLabels
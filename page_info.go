@@ -40,16 +40,86 @@ func (gp *GoPdf) GetPageSize(pageNo int) (w, h float64, err error) {
 
 // findPageObj finds the n-th PageObj (1-based) in the pdfObjs slice.
 func (gp *GoPdf) findPageObj(pageNo int) *PageObj {
-	count := 0
-	for _, obj := range gp.pdfObjs {
+	pages := gp.pageObjIndexes()
+	if pageNo < 1 || pageNo > len(pages) {
+		return nil
+	}
+	return gp.pdfObjs[pages[pageNo-1]].(*PageObj)
+}
+
+// pageObjIndexes returns the indexes in pdfObjs of the pages in document
+// order. Pages are in the order they were added, except for pages moved by
+// insertPagesAfter.
+func (gp *GoPdf) pageObjIndexes() []int {
+	var natural []int
+	pos := make(map[*PageObj]int)
+	for i, obj := range gp.pdfObjs {
 		if p, ok := obj.(*PageObj); ok {
-			count++
-			if count == pageNo {
-				return p
+			natural = append(natural, i)
+			pos[p] = i
+		}
+	}
+	if len(gp.pageOrder) == 0 {
+		return natural
+	}
+	ordered := make([]int, 0, len(natural))
+	used := make(map[int]bool, len(natural))
+	for _, p := range gp.pageOrder {
+		if i, ok := pos[p]; ok && !used[i] {
+			ordered = append(ordered, i)
+			used[i] = true
+		}
+	}
+	for _, i := range natural {
+		if !used[i] {
+			ordered = append(ordered, i)
+		}
+	}
+	return ordered
+}
+
+// naturalPageNo returns the position (1-based) in the order pages were
+// added of the page numbered pageNo, or pageNo if it does not exist.
+func (gp *GoPdf) naturalPageNo(pageNo int) int {
+	if len(gp.pageOrder) == 0 {
+		return pageNo
+	}
+	pages := gp.pageObjIndexes()
+	if pageNo < 1 || pageNo > len(pages) {
+		return pageNo
+	}
+	n := 0
+	for i, obj := range gp.pdfObjs {
+		if _, ok := obj.(*PageObj); ok {
+			n++
+			if i == pages[pageNo-1] {
+				return n
 			}
 		}
 	}
-	return nil
+	return pageNo
+}
+
+// insertPagesAfter moves the pages to follow page number after, and shifts
+// the page numbers of destinations, named destinations and page labels
+// that refer to later pages.
+func (gp *GoPdf) insertPagesAfter(after int, pages []*PageObj) {
+	moved := make(map[*PageObj]bool, len(pages))
+	for _, p := range pages {
+		moved[p] = true
+	}
+	var order []*PageObj
+	for n, i := range gp.pageObjIndexes() {
+		p := gp.pdfObjs[i].(*PageObj)
+		if !moved[p] {
+			order = append(order, p)
+		}
+		if n+1 == after {
+			order = append(order, pages...)
+		}
+	}
+	gp.pageOrder = order
+	gp.shiftPageRefs(after, len(pages))
 }
 
 // GetAllPageSizes returns the sizes of all pages in the document.
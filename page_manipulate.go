@@ -33,18 +33,8 @@ func (gp *GoPdf) DeletePage(pageNo int) error {
 	}
 
 	// Find the page object and its associated content object.
-	pageCount := 0
-	pageIdx := -1
-	for i, obj := range gp.pdfObjs {
-		if _, ok := obj.(*PageObj); ok {
-			pageCount++
-			if pageCount == pageNo {
-				pageIdx = i
-				break
-			}
-		}
-	}
-	if pageIdx == -1 {
+	pageIdx := gp.pageObjIDByNumber(pageNo) - 1
+	if pageIdx < 0 {
 		return ErrPageOutOfRange
 	}
 
@@ -12,8 +12,13 @@ type PageObj struct { //impl IObj
 	ResourcesRelate string
 	pageOption      PageOption
 	LinkObjIds      []int
-	rotation        int // page display rotation (0, 90, 180, 270)
-	cropBox         *Box // optional CropBox (visible area)
+	rotation        int             // page display rotation (0, 90, 180, 270)
+	cropBox         *Box            // optional CropBox (visible area)
+	openAction      *Action         // /AA /O
+	closeAction     *Action         // /AA /C
+	transition      *PageTransition // /Trans
+	duration        float64         // /Dur, 0 = none
+	beadObjIDs      []int           // /B, article beads on the page
 	getRoot         func() *GoPdf
 }
 
@@ -70,6 +75,29 @@ func (p *PageObj) write(w io.Writer, objID int) error {
 	if p.rotation != 0 {
 		fmt.Fprintf(w, " /Rotate %d\n", p.rotation)
 	}
+	p.writePresentation(w)
+	if len(p.beadObjIDs) > 0 {
+		io.WriteString(w, " /B [")
+		for _, id := range p.beadObjIDs {
+			fmt.Fprintf(w, "%d 0 R ", id)
+		}
+		io.WriteString(w, "]\n")
+	}
+	if p.openAction != nil || p.closeAction != nil {
+		aw := actionWriter{p.getRoot(), objID}
+		io.WriteString(w, " /AA <<")
+		if p.openAction != nil {
+			if a := aw.dict(*p.openAction); a != "" {
+				fmt.Fprintf(w, " /O %s", a)
+			}
+		}
+		if p.closeAction != nil {
+			if a := aw.dict(*p.closeAction); a != "" {
+				fmt.Fprintf(w, " /C %s", a)
+			}
+		}
+		io.WriteString(w, " >>\n")
+	}
 	io.WriteString(w, ">>\n")
 	return nil
 }
@@ -0,0 +1,315 @@
+package gopdf
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ============================================================
+// Page tokens in headers and footers
+// ============================================================
+
+// Page tokens are replaced in text drawn with Text, Cell and CellWithOption
+// inside the functions set with AddHeader and AddFooter, after
+// EnablePageTokens is called. They are resolved when the document is
+// written, so that the total page count and the final page numbers are
+// known.
+const (
+	// PageTokenPage is the 1-based number of the page.
+	PageTokenPage = "{page}"
+	// PageTokenPages is the number of pages of the document.
+	PageTokenPages = "{pages}"
+	// PageTokenLabel is the page label set with SetPageLabels, or the page
+	// number.
+	PageTokenLabel = "{label}"
+	// PageTokenSection is the title of the last top-level outline item on
+	// or before the page.
+	PageTokenSection = "{section}"
+	// PageTokenTitle is the title of the document info.
+	PageTokenTitle = "{title}"
+	// PageTokenDate is the creation date of the document info (or the
+	// current date), formatted as 2006-01-02.
+	PageTokenDate = "{date}"
+)
+
+// pageTokenText is text with page tokens drawn in a header or footer.
+type pageTokenText struct {
+	caches   []*cacheContentText // the cell frame, if any, and one cache per font run
+	chain    []*SubsetFontObj    // font fallback chain of the text
+	primary  *SubsetFontObj      // font of the text
+	template string
+	page     *PageObj
+	dir      TextDirection // base direction of the text
+	x        float64       // x of the first cache when drawn
+	reserved float64       // width of the provisional text, in points
+}
+
+// EnablePageTokens turns on the replacement of page tokens in header and
+// footer text drawn from now on. Without it, braces in header and footer
+// text are printed as they are.
+//
+// Example:
+//
+//	pdf.EnablePageTokens()
+//	pdf.AddFooter(func() {
+//	    pdf.Cell(nil, "Page {page} of {pages}")
+//	})
+func (gp *GoPdf) EnablePageTokens() {
+	gp.pageTokensEnabled = true
+}
+
+// SetHeaderFooterField sets the value of a custom token {name} of header
+// and footer text. Fields named title or date replace the built-in values.
+//
+// Example:
+//
+//	pdf.EnablePageTokens()
+//	pdf.SetHeaderFooterField("author", "Jane Doe")
+//	pdf.AddFooter(func() {
+//	    pdf.Cell(nil, "{author} - page {page} of {pages}")
+//	})
+func (gp *GoPdf) SetHeaderFooterField(name, value string) {
+	if gp.headerFooterFields == nil {
+		gp.headerFooterFields = make(map[string]string)
+	}
+	gp.headerFooterFields[name] = value
+}
+
+// hasPageTokens reports whether text contains a {name} token.
+func hasPageTokens(text string) bool {
+	for {
+		open := strings.IndexByte(text, '{')
+		if open < 0 {
+			return false
+		}
+		text = text[open+1:]
+		end := strings.IndexAny(text, "{}")
+		if end > 0 && text[end] == '}' {
+			return true
+		}
+	}
+}
+
+// expandTokens replaces the {name} tokens of text for which value returns
+// true. Other tokens are kept as they are.
+func expandTokens(text string, value func(name string) (string, bool)) string {
+	var sb strings.Builder
+	for {
+		open := strings.IndexByte(text, '{')
+		if open < 0 {
+			break
+		}
+		end := strings.IndexAny(text[open+1:], "{}")
+		if end < 0 {
+			break
+		}
+		end += open + 1
+		if text[end] == '{' {
+			sb.WriteString(text[:end])
+			text = text[end:]
+			continue
+		}
+		if v, ok := value(text[open+1 : end]); ok {
+			sb.WriteString(text[:open])
+			sb.WriteString(v)
+		} else {
+			sb.WriteString(text[:end+1])
+		}
+		text = text[end+1:]
+	}
+	sb.WriteString(text)
+	return sb.String()
+}
+
+// beginPageTokens returns the provisional text to draw for text with page
+// tokens in a header or footer, laid out with the values known now and
+// the widest likely page count, and whether the text must be tracked with
+// endPageTokens. The drawn text gets a content cache of its own.
+func (gp *GoPdf) beginPageTokens(text string) (string, bool) {
+	if !gp.inHeaderFooter || !gp.pageTokensEnabled || !hasPageTokens(text) {
+		return text, false
+	}
+	gp.curr.setXCount++
+	page, _ := gp.pdfObjs[gp.curr.IndexOfPageObj].(*PageObj)
+	return gp.pageTokenValues(text, page, true), true
+}
+
+// endPageTokens tracks the text just drawn by the current content stream,
+// to be resolved when the document is written: every cache drawn since
+// beginPageTokens, one per font run when a fallback font was needed. dir
+// is the direction the text was drawn with, as passed to bidiText.
+func (gp *GoPdf) endPageTokens(template string, dir TextDirection) {
+	if dir == TextDirectionNone {
+		dir = gp.textDirection
+	}
+	list := gp.getContent().listCache.caches
+	var caches []*cacheContentText
+	for i := len(list) - 1; i >= 0; i-- {
+		c, ok := list[i].(*cacheContentText)
+		if !ok || c.setXCount != gp.curr.setXCount {
+			break
+		}
+		caches = append([]*cacheContentText{c}, caches...)
+	}
+	gp.curr.setXCount++
+	if len(caches) == 0 {
+		return
+	}
+	page, _ := gp.pdfObjs[gp.curr.IndexOfPageObj].(*PageObj)
+	t := &pageTokenText{
+		caches:   caches,
+		chain:    gp.fontChain(),
+		primary:  gp.curr.FontISubset,
+		template: template,
+		page:     page,
+		dir:      dir,
+		x:        caches[0].x,
+		reserved: caches[0].textWidthPdfUnit,
+	}
+	if frame, _ := t.frame(); frame != nil {
+		t.reserved = frame.rectangle.W
+	}
+	gp.pageTokens = append(gp.pageTokens, t)
+}
+
+// frame returns the cell frame of text drawn as a cell of several font
+// runs, and the caches of the runs.
+func (t *pageTokenText) frame() (*cacheContentText, []*cacheContentText) {
+	if len(t.caches) > 1 && t.caches[0].contentType == ContentTypeCell {
+		return t.caches[0], t.caches[1:]
+	}
+	return nil, t.caches
+}
+
+// pageTokenValues expands the page tokens of text for page. Provisional
+// values reserve at least three digits for the page count.
+func (gp *GoPdf) pageTokenValues(text string, page *PageObj, provisional bool) string {
+	pageNo := gp.pageNoOf(page)
+	return expandTokens(text, func(name string) (string, bool) {
+		switch name {
+		case "page":
+			return strconv.Itoa(pageNo), true
+		case "pages":
+			if provisional {
+				n := len(strconv.Itoa(gp.GetNumberOfPages()))
+				if n < 3 {
+					n = 3
+				}
+				return strings.Repeat("0", n), true
+			}
+			return strconv.Itoa(gp.GetNumberOfPages()), true
+		case "label":
+			return gp.computePageLabel(pageNo - 1), true
+		case "section":
+			return gp.pageSection(pageNo), true
+		}
+		if v, ok := gp.headerFooterFields[name]; ok {
+			return v, true
+		}
+		switch name {
+		case "title":
+			if gp.info != nil {
+				return gp.info.Title, true
+			}
+			return "", true
+		case "date":
+			date := time.Now()
+			if gp.info != nil && !gp.info.CreationDate.IsZero() {
+				date = gp.info.CreationDate
+			}
+			return date.Format("2006-01-02"), true
+		}
+		return "", false
+	})
+}
+
+// pageSection returns the title of the last top-level outline item on or
+// before page pageNo.
+func (gp *GoPdf) pageSection(pageNo int) string {
+	title, at := "", 0
+	for _, item := range gp.GetTOC() {
+		if item.Level == 1 && item.PageNo <= pageNo && item.PageNo >= at {
+			title, at = item.Title, item.PageNo
+		}
+	}
+	return title
+}
+
+// resolvePageTokens replaces the provisional text of the headers and
+// footers with the final values, in visual order. The text is split into
+// font runs again, and the runs are placed one after the other from where
+// the text was drawn; the alignment of cells without a rectangle, or of
+// cells of several runs, is re-applied within the reserved width. The
+// final values must need the same fonts as the provisional ones.
+func (gp *GoPdf) resolvePageTokens() error {
+	for _, t := range gp.pageTokens {
+		if gp.pageNoOf(t.page) == 0 {
+			continue // deleted page
+		}
+		text, _ := BidiVisual(gp.pageTokenValues(t.template, t.page, false), t.dir)
+		runs, err := chainFontRuns(t.chain, t.primary, text)
+		if err != nil {
+			return err
+		}
+		frame, caches := t.frame()
+		if !sameRunFonts(runs, caches) {
+			return fmt.Errorf("page tokens of %q: final text needs other fonts than the provisional text", t.template)
+		}
+		widths := make([]float64, len(runs))
+		total := 0.0
+		for i, run := range runs {
+			c := caches[i]
+			c.text = run.text
+			if _, _, err := c.createContent(); err != nil {
+				return err
+			}
+			widths[i] = c.textWidthPdfUnit
+			if frame != nil {
+				widths[i] = c.textState.width(c.text, widths[i])
+			}
+			total += widths[i]
+		}
+
+		align := caches[0].cellOpt.Align
+		if frame != nil {
+			align = frame.cellOpt.Align
+		} else if caches[0].contentType != ContentTypeCell || caches[0].rectangle != nil {
+			align = Left
+		}
+		x := t.x
+		if frame != nil {
+			x = frame.x
+		}
+		if align&Right == Right {
+			x += t.reserved - total
+		} else if align&Center == Center {
+			x += (t.reserved - total) / 2
+		}
+		for i, c := range caches {
+			c.x = x
+			if frame != nil {
+				c.rectangle = &Rect{W: widths[i], H: c.rectangle.H}
+				if _, _, err := c.createContent(); err != nil {
+					return err
+				}
+			}
+			x += widths[i]
+		}
+	}
+	return nil
+}
+
+// sameRunFonts reports whether runs have the fonts of caches, one by one.
+func sameRunFonts(runs []fontRun, caches []*cacheContentText) bool {
+	if len(runs) != len(caches) {
+		return false
+	}
+	for i, run := range runs {
+		if run.font != caches[i].fontSubset {
+			return false
+		}
+	}
+	return true
+}
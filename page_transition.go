@@ -0,0 +1,208 @@
+package gopdf
+
+import (
+	"errors"
+	"fmt"
+	"io"
+)
+
+// ============================================================
+// Page transitions and auto-advance (PDF 32000-1:2008, 12.4.4)
+// ============================================================
+
+// ErrInvalidTransition is returned for a transition with an unknown style
+// or an invalid direction, dimension, motion or duration.
+var ErrInvalidTransition = errors.New("invalid page transition")
+
+// TransitionStyle is the effect shown when moving to a page in
+// presentation (full screen) mode.
+type TransitionStyle string
+
+const (
+	// TransitionReplace replaces the old page with the new one (default).
+	TransitionReplace TransitionStyle = "R"
+	// TransitionSplit sweeps two lines across the screen.
+	TransitionSplit TransitionStyle = "Split"
+	// TransitionBlinds sweeps multiple lines across the screen.
+	TransitionBlinds TransitionStyle = "Blinds"
+	// TransitionBox sweeps a rectangular box inward or outward.
+	TransitionBox TransitionStyle = "Box"
+	// TransitionWipe sweeps a single line in the direction.
+	TransitionWipe TransitionStyle = "Wipe"
+	// TransitionDissolve reveals the new page in a random pattern.
+	TransitionDissolve TransitionStyle = "Dissolve"
+	// TransitionGlitter is a dissolve that sweeps in the direction.
+	TransitionGlitter TransitionStyle = "Glitter"
+	// TransitionFly flies the changes in or out.
+	TransitionFly TransitionStyle = "Fly"
+	// TransitionPush pushes the old page off the screen.
+	TransitionPush TransitionStyle = "Push"
+	// TransitionCover slides the new page over the old one.
+	TransitionCover TransitionStyle = "Cover"
+	// TransitionUncover slides the old page off to reveal the new one.
+	TransitionUncover TransitionStyle = "Uncover"
+	// TransitionFade fades the old page into the new one.
+	TransitionFade TransitionStyle = "Fade"
+)
+
+// TransitionDirectionNone is the Direction of a Fly transition with a
+// Scale other than 1 that has no direction.
+const TransitionDirectionNone = -1
+
+// PageTransition is the transition effect to a page.
+type PageTransition struct {
+	// Style is the transition effect. Default: TransitionReplace.
+	Style TransitionStyle
+	// Duration is the duration of the effect in seconds. Default: 1.
+	Duration float64
+	// Vertical selects vertical lines instead of horizontal lines for
+	// Split and Blinds (/Dm /V).
+	Vertical bool
+	// Inward selects a motion from the edges toward the center for Split,
+	// Box and Fly (/M /I); the default is outward.
+	Inward bool
+	// Direction is the direction of Wipe, Glitter, Fly, Push, Cover and
+	// Uncover in degrees counterclockwise from left to right: 0, 90, 180,
+	// 270 or 315 (Glitter only), or TransitionDirectionNone for Fly.
+	Direction int
+	// Scale is the starting or ending scale of Fly (/SS). Default: 1.
+	Scale float64
+	// Opaque makes the flying area of Fly opaque (/B).
+	Opaque bool
+}
+
+func (t PageTransition) validate() error {
+	switch t.Style {
+	case "", TransitionReplace, TransitionSplit, TransitionBlinds, TransitionBox, TransitionWipe,
+		TransitionDissolve, TransitionGlitter, TransitionFly, TransitionPush, TransitionCover,
+		TransitionUncover, TransitionFade:
+	default:
+		return ErrInvalidTransition
+	}
+	if t.Duration < 0 || t.Scale < 0 {
+		return ErrInvalidTransition
+	}
+	switch t.Direction {
+	case 0, 90, 180, 270:
+	case 315:
+		if t.Style != TransitionGlitter {
+			return ErrInvalidTransition
+		}
+	case TransitionDirectionNone:
+		if t.Style != TransitionFly {
+			return ErrInvalidTransition
+		}
+	default:
+		return ErrInvalidTransition
+	}
+	return nil
+}
+
+// dict returns the transition dictionary.
+func (t PageTransition) dict() string {
+	style := t.Style
+	if style == "" {
+		style = TransitionReplace
+	}
+	s := fmt.Sprintf("<< /Type /Trans /S /%s", style)
+	if t.Duration > 0 && t.Duration != 1 {
+		s += " /D " + FormatFloatTrim(t.Duration)
+	}
+	switch style {
+	case TransitionSplit, TransitionBlinds:
+		if t.Vertical {
+			s += " /Dm /V"
+		}
+	}
+	switch style {
+	case TransitionSplit, TransitionBox, TransitionFly:
+		if t.Inward {
+			s += " /M /I"
+		}
+	}
+	switch style {
+	case TransitionWipe, TransitionGlitter, TransitionFly, TransitionPush, TransitionCover, TransitionUncover:
+		if t.Direction == TransitionDirectionNone {
+			s += " /Di /None"
+		} else if t.Direction != 0 {
+			s += fmt.Sprintf(" /Di %d", t.Direction)
+		}
+	}
+	if style == TransitionFly {
+		if t.Scale > 0 && t.Scale != 1 {
+			s += " /SS " + FormatFloatTrim(t.Scale)
+		}
+		if t.Opaque {
+			s += " /B true"
+		}
+	}
+	return s + " >>"
+}
+
+// SetPageTransition sets the transition effect shown when moving to a page
+// in presentation mode. pageNo is 1-based.
+//
+// Example:
+//
+//	pdf.SetPageMode(gopdf.PageModeFullScreen)
+//	pdf.SetPageTransition(2, gopdf.PageTransition{Style: gopdf.TransitionPush, Direction: 270, Duration: 0.5})
+func (gp *GoPdf) SetPageTransition(pageNo int, t PageTransition) error {
+	if err := t.validate(); err != nil {
+		return err
+	}
+	page := gp.findPageObj(pageNo)
+	if page == nil {
+		return ErrPageOutOfRange
+	}
+	page.transition = &t
+	return nil
+}
+
+// GetPageTransition returns the transition effect of a page, or nil if
+// none is set. pageNo is 1-based.
+func (gp *GoPdf) GetPageTransition(pageNo int) (*PageTransition, error) {
+	page := gp.findPageObj(pageNo)
+	if page == nil {
+		return nil, ErrPageOutOfRange
+	}
+	if page.transition == nil {
+		return nil, nil
+	}
+	t := *page.transition
+	return &t, nil
+}
+
+// SetPageDuration sets the number of seconds a page is displayed in
+// presentation mode before the viewer advances to the next page (/Dur).
+// A duration of 0 turns auto-advance off. pageNo is 1-based.
+func (gp *GoPdf) SetPageDuration(pageNo int, seconds float64) error {
+	if seconds < 0 {
+		return ErrInvalidTransition
+	}
+	page := gp.findPageObj(pageNo)
+	if page == nil {
+		return ErrPageOutOfRange
+	}
+	page.duration = seconds
+	return nil
+}
+
+// GetPageDuration returns the auto-advance duration of a page in seconds,
+// or 0. pageNo is 1-based.
+func (gp *GoPdf) GetPageDuration(pageNo int) (float64, error) {
+	page := gp.findPageObj(pageNo)
+	if page == nil {
+		return 0, ErrPageOutOfRange
+	}
+	return page.duration, nil
+}
+
+// writePresentation writes the /Trans and /Dur entries of a page.
+func (p *PageObj) writePresentation(w io.Writer) {
+	if p.transition != nil {
+		fmt.Fprintf(w, " /Trans %s\n", p.transition.dict())
+	}
+	if p.duration > 0 {
+		fmt.Fprintf(w, " /Dur %s\n", FormatFloatTrim(p.duration))
+	}
+}
@@ -0,0 +1,193 @@
+package gopdf
+
+import (
+	"math"
+	"strings"
+)
+
+// ============================================================
+// Paragraph layout of MultiCellWithOption
+// ============================================================
+
+// ParagraphOption controls the paragraph layout of MultiCellWithOption.
+// Paragraphs are separated by newlines. Lengths are in document units.
+type ParagraphOption struct {
+	// LastLineAlign is the horizontal alignment of the last line of each
+	// paragraph of justified text: Left (default), Center, Right or
+	// Justify.
+	LastLineAlign int
+	// FirstLineIndent indents the first line of each paragraph.
+	FirstLineIndent float64
+	// LineHeight is the distance between lines. Default: the height of
+	// the font plus Leading.
+	LineHeight float64
+	// Leading is the space added between lines when LineHeight is not set.
+	Leading float64
+	// ParagraphSpacing is the space added between paragraphs.
+	ParagraphSpacing float64
+	// Orphans is the minimum number of lines of a paragraph left at the
+	// bottom of a page, and Widows the minimum number carried over to the
+	// next page, when a paragraph is split across pages. Default: 1.
+	Orphans, Widows int
+}
+
+// paragraphLine is a laid out line of a paragraph.
+type paragraphLine struct {
+	text   string
+	indent float64
+	last   bool          // last line of the paragraph
+	dir    TextDirection // direction of the paragraph
+}
+
+// multiCellParagraphs lays out text with the paragraph options of opt: the
+// lines of each paragraph are aligned or justified in rectangle.W and
+// continued on new pages at the bottom margin. The vertical alignment of
+// opt places the text in rectangle.H when it fits on the page.
+func (gp *GoPdf) multiCellParagraphs(rectangle *Rect, text string, opt CellOption) error {
+	para := ParagraphOption{}
+	if opt.Paragraph != nil {
+		para = *opt.Paragraph
+	}
+	breakOpt := opt.BreakOption
+	if breakOpt == nil {
+		breakOpt = &BreakOption{Mode: BreakModeUnicode}
+	}
+
+	// get lineHeight
+	itext, err := gp.curr.FontISubset.AddChars(text)
+	if err != nil {
+		return err
+	}
+	_, lineHeight, _, err := createContent(gp.curr.FontISubset, itext, gp.curr.FontSize, gp.curr.CharSpacing, nil)
+	if err != nil {
+		return err
+	}
+	gp.PointsToUnitsVar(&lineHeight)
+	if para.LineHeight > 0 {
+		lineHeight = para.LineHeight
+	} else {
+		lineHeight += para.Leading
+	}
+
+	dir := opt.Direction
+	if dir == TextDirectionNone {
+		dir = gp.textDirection
+	}
+	var paragraphs [][]paragraphLine
+	for _, p := range strings.Split(text, "\n") {
+		lines, err := gp.paragraphLines(p, rectangle.W, para.FirstLineIndent, breakOpt)
+		if err != nil {
+			return err
+		}
+		if dir != TextDirectionNone {
+			pdir := paragraphDirection(p, dir)
+			for i := range lines {
+				lines[i].dir = pdir
+			}
+		}
+		paragraphs = append(paragraphs, lines)
+	}
+
+	x, y := gp.GetX(), gp.GetY()
+	bottom := gp.PointsToUnits(gp.curr.pageSize.H) - gp.MarginBottom()
+	total := -para.ParagraphSpacing
+	for _, lines := range paragraphs {
+		total += float64(len(lines))*lineHeight + para.ParagraphSpacing
+	}
+	if y+total <= bottom {
+		if opt.Align&Bottom == Bottom {
+			y += math.Max(rectangle.H-total, 0)
+		} else if opt.Align&Middle == Middle {
+			y += math.Max(rectangle.H-total, 0) / 2
+		}
+	}
+
+	for n, lines := range paragraphs {
+		if n > 0 {
+			y += para.ParagraphSpacing
+		}
+		for i := 0; i < len(lines); {
+			k := paragraphLinesOnPage(len(lines)-i, int((bottom-y)/lineHeight+1e-9), para.Orphans, para.Widows, y <= gp.MarginTop())
+			for _, line := range lines[i : i+k] {
+				if err := gp.paragraphLine(line, x, y, rectangle.W, lineHeight, opt, para); err != nil {
+					return err
+				}
+				y += lineHeight
+			}
+			if i += k; i < len(lines) {
+				gp.AddPage()
+				y = gp.MarginTop()
+			}
+		}
+	}
+	gp.SetXY(x, y)
+	return nil
+}
+
+// paragraphLines breaks a paragraph into lines of width, the first line
+// indented by indent. An empty paragraph is an empty line.
+func (gp *GoPdf) paragraphLines(text string, width, indent float64, opt *BreakOption) ([]paragraphLine, error) {
+	var segs []lineSegment
+	switch opt.Mode {
+	case BreakModeIndicatorSensitive:
+		segs = indicatorSegments(text, opt.BreakIndicator)
+	case BreakModeUnicode:
+		segs = lineBreakSegments(text)
+	default:
+		segs = runeSegments(text)
+	}
+	texts, err := gp.splitTextSegments(segs, width, indent, opt)
+	if err != nil {
+		return nil, err
+	}
+	if len(texts) == 0 {
+		texts = []string{""}
+	}
+	lines := make([]paragraphLine, len(texts))
+	for i, t := range texts {
+		lines[i] = paragraphLine{text: t}
+	}
+	lines[0].indent = indent
+	lines[len(lines)-1].last = true
+	return lines, nil
+}
+
+// paragraphLinesOnPage returns how many of the rest lines of a paragraph
+// to place in the avail lines left on the page, keeping orphans lines at
+// the bottom of the page and widows lines on the next page. A page that
+// has nothing else on it takes as many lines as fit.
+func paragraphLinesOnPage(rest, avail, orphans, widows int, emptyPage bool) int {
+	if rest <= avail {
+		return rest
+	}
+	k := avail
+	if rest-k < widows {
+		k = rest - widows
+	}
+	if k < orphans || k < 0 {
+		k = 0
+	}
+	if k == 0 && emptyPage {
+		k = int(math.Max(float64(avail), 1))
+	}
+	return k
+}
+
+// paragraphLine draws a line of a paragraph at x, y. Lines without an
+// alignment are aligned to the start of their direction, and right-to-left
+// first lines are indented from the right.
+func (gp *GoPdf) paragraphLine(line paragraphLine, x, y, width, lineHeight float64, opt CellOption, para ParagraphOption) error {
+	align := opt.Align &^ (Top | Bottom | Middle)
+	if line.last && align&Justify == Justify {
+		align = para.LastLineAlign
+	}
+	opt.Align = align | Middle
+	opt.BreakOption = nil
+	opt.Paragraph = nil
+	opt.Direction = line.dir
+	if line.dir != TextDirectionRTL {
+		x += line.indent
+	}
+	gp.SetXY(x, y)
+	return gp.CellWithOption(&Rect{W: width - line.indent, H: lineHeight}, line.text, opt)
+}
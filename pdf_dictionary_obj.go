@@ -158,27 +158,12 @@ func (p *PdfDictionaryObj) makeGlyfAndLocaTable() ([]byte, []int, error) {
 }
 
 func (p *PdfDictionaryObj) getGlyphSize(glyph int) int {
-
-	ttfp := p.PtrToSubsetFontObj.GetTTFParser()
-	glyf := ttfp.GetTables()["glyf"]
-	start := int(glyf.Offset + ttfp.LocaTable[glyph])
-	next := int(glyf.Offset + ttfp.LocaTable[glyph+1])
-	return next - start
+	return len(p.getGlyphData(glyph))
 }
 
 func (p *PdfDictionaryObj) getGlyphData(glyph int) []byte {
 	ttfp := p.PtrToSubsetFontObj.GetTTFParser()
-	glyf := ttfp.GetTables()["glyf"]
-	start := int(glyf.Offset + ttfp.LocaTable[glyph])
-	next := int(glyf.Offset + ttfp.LocaTable[glyph+1])
-	count := next - start
-	var data []byte
-	i := 0
-	for i < count {
-		data = append(data, ttfp.FontData()[start+i])
-		i++
-	}
-	return data
+	return ttfp.GlyphData(glyph) // instantiated for variable fonts
 }
 
 func (p *PdfDictionaryObj) makeFont() ([]byte, error) {
@@ -257,6 +242,13 @@ func (p *PdfDictionaryObj) makeFont() ([]byte, error) {
 			}
 			entry.CheckSum = CheckSum(data)
 			WriteBytes(&buff, data, 0, len(data))
+		} else if tags[idx] == "hmtx" && ttfp.IsInstanced() {
+			hmtx := ttfp.HmtxData()
+			entry.Length = uint(len(hmtx))
+			data := make([]byte, entry.PaddedLength())
+			copy(data, hmtx)
+			entry.CheckSum = CheckSum(data)
+			WriteBytes(&buff, data, 0, len(data))
 		} else {
 			WriteBytes(&buff, ttfp.FontData(), int(entry.Offset), entry.PaddedLength())
 		}
@@ -282,7 +274,7 @@ func (p *PdfDictionaryObj) completeGlyphClosure(mapOfglyphs *MapOfCharacterToGly
 	var glyphArray []int
 	//copy
 	isContainZero := false
-	glyphs := mapOfglyphs.AllVals()
+	glyphs := append(mapOfglyphs.AllVals(), p.PtrToSubsetFontObj.verticalGlyphs()...)
 	for _, v := range glyphs {
 		glyphArray = append(glyphArray, int(v))
 		if v == 0 {
@@ -0,0 +1,692 @@
+package gopdf
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+)
+
+var reStartXref = regexp.MustCompile(`startxref\s+(\d+)`)
+
+// ============================================================
+// PDF object store — edits the objects of an existing PDF and
+// writes the result as an incremental update or a full rewrite.
+// ============================================================
+
+// pdfObjectStore holds the objects of an existing PDF. Objects are read
+// through the raw parser; changed and added objects are kept as new bodies.
+type pdfObjectStore struct {
+	data       []byte
+	parser     *rawPDFParser
+	changed    map[int]string // object number -> new body
+	nextNum    int
+	trailer    string // dictionary of the last trailer or cross-reference stream
+	prevXref   int    // offset of the last cross-reference section
+	xrefStream bool   // the last cross-reference section is a stream
+}
+
+func newPDFObjectStore(data []byte) (*pdfObjectStore, error) {
+	parser, err := newRawPDFParser(data)
+	if err != nil {
+		return nil, err
+	}
+	s := &pdfObjectStore{
+		data:    data,
+		parser:  parser,
+		changed: make(map[int]string),
+	}
+	for num := range parser.objects {
+		if num >= s.nextNum {
+			s.nextNum = num + 1
+		}
+	}
+	s.readTrailer()
+	if size, err := strconv.Atoi(pdfDictGet(s.trailer, "/Size")); err == nil && size > s.nextNum {
+		s.nextNum = size
+	}
+	if pdfDictGet(s.trailer, "/Encrypt") != "" {
+		return nil, ErrEncryptedPDF
+	}
+	if parser.root <= 0 {
+		return nil, fmt.Errorf("pdf parse: no catalog found")
+	}
+	return s, nil
+}
+
+// readTrailer locates the last cross-reference section and its trailer.
+func (s *pdfObjectStore) readTrailer() {
+	idx := bytes.LastIndex(s.data, []byte("startxref"))
+	if idx < 0 {
+		return
+	}
+	m := reStartXref.FindSubmatch(s.data[idx:])
+	if m == nil {
+		return
+	}
+	s.prevXref, _ = strconv.Atoi(string(m[1]))
+	if s.prevXref <= 0 || s.prevXref >= len(s.data) {
+		s.prevXref = 0
+	} else if !bytes.HasPrefix(s.data[s.prevXref:], []byte("xref")) {
+		s.xrefStream = true
+	}
+	if s.xrefStream {
+		if m := reObjHeader.FindSubmatch(s.data[s.prevXref:]); m != nil {
+			num, _ := strconv.Atoi(string(m[1]))
+			s.trailer = s.parser.objects[num].dict
+		}
+		return
+	}
+	if t := bytes.LastIndex(s.data[:idx], []byte("trailer")); t >= 0 {
+		s.trailer = extractDict(s.data[t:idx])
+	}
+}
+
+// body returns the current body of an object.
+func (s *pdfObjectStore) body(num int) string {
+	if b, ok := s.changed[num]; ok {
+		return b
+	}
+	return string(s.parser.objects[num].body)
+}
+
+// dict returns the dictionary of an object, or "" if it is not a dictionary.
+func (s *pdfObjectStore) dict(num int) string {
+	b := strings.TrimSpace(s.body(num))
+	if !strings.HasPrefix(b, "<<") {
+		return ""
+	}
+	return b[:scanPDFValue(b, 0)]
+}
+
+// resolve returns the value itself, or the body of the object it refers to.
+func (s *pdfObjectStore) resolve(value string) string {
+	if num, ok := pdfRef(value); ok {
+		return strings.TrimSpace(s.body(num))
+	}
+	return value
+}
+
+// resolveDict returns a dictionary value or the dictionary it refers to.
+func (s *pdfObjectStore) resolveDict(value string) string {
+	v := s.resolve(value)
+	if !strings.HasPrefix(v, "<<") {
+		return ""
+	}
+	return v[:scanPDFValue(v, 0)]
+}
+
+// set replaces the body of an object.
+func (s *pdfObjectStore) set(num int, body string) {
+	s.changed[num] = body
+}
+
+// setDict replaces the dictionary of an object and keeps its stream.
+func (s *pdfObjectStore) setDict(num int, dict string) {
+	b := strings.TrimSpace(s.body(num))
+	rest := ""
+	if strings.HasPrefix(b, "<<") {
+		rest = b[scanPDFValue(b, 0):]
+	}
+	s.set(num, dict+rest)
+}
+
+// add adds a new object and returns its number.
+func (s *pdfObjectStore) add(body string) int {
+	num := s.nextNum
+	s.nextNum++
+	s.changed[num] = body
+	return num
+}
+
+// addStream adds a new stream object with the given dictionary entries.
+func (s *pdfObjectStore) addStream(dict string, data []byte) int {
+	dict = pdfDictSet(dict, "/Length", strconv.Itoa(len(data)))
+	return s.add(dict + "\nstream\n" + string(data) + "\nendstream")
+}
+
+// catalog returns the object number of the document catalog.
+func (s *pdfObjectStore) catalog() int {
+	return s.parser.root
+}
+
+// pageNums returns the object numbers of the pages in order.
+func (s *pdfObjectStore) pageNums() []int {
+	var nums []int
+	visited := make(map[int]bool)
+	var walk func(num int)
+	walk = func(num int) {
+		if visited[num] {
+			return
+		}
+		visited[num] = true
+		dict := s.dict(num)
+		if kids := pdfDictGet(dict, "/Kids"); kids != "" && pdfDictGet(dict, "/Type") != "/Page" {
+			for _, kid := range pdfRefs(s.resolve(kids)) {
+				walk(kid)
+			}
+			return
+		}
+		nums = append(nums, num)
+	}
+	if root, ok := pdfRef(pdfDictGet(s.dict(s.catalog()), "/Pages")); ok {
+		walk(root)
+	}
+	return nums
+}
+
+func (s *pdfObjectStore) changedNums() []int {
+	nums := make([]int, 0, len(s.changed))
+	for num := range s.changed {
+		nums = append(nums, num)
+	}
+	sort.Ints(nums)
+	return nums
+}
+
+// incremental appends the changed objects to the original data as an
+// incremental update. The cross-reference section is a stream when the
+// original file uses cross-reference streams.
+func (s *pdfObjectStore) incremental() []byte {
+	var buf bytes.Buffer
+	buf.Write(s.data)
+	if len(s.data) > 0 && s.data[len(s.data)-1] != '\n' {
+		buf.WriteByte('\n')
+	}
+	offsets := make(map[int]int)
+	for _, num := range s.changedNums() {
+		offsets[num] = buf.Len()
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", num, s.changed[num])
+	}
+
+	trailer := s.newTrailer()
+	if s.prevXref > 0 {
+		trailer = pdfDictSet(trailer, "/Prev", strconv.Itoa(s.prevXref))
+	}
+	if s.xrefStream {
+		s.writeXrefStream(&buf, offsets, trailer)
+	} else {
+		xrefOffset := buf.Len()
+		buf.WriteString("xref\n")
+		nums := make([]int, 0, len(offsets))
+		for num := range offsets {
+			nums = append(nums, num)
+		}
+		sort.Ints(nums)
+		for i := 0; i < len(nums); {
+			j := i
+			for j+1 < len(nums) && nums[j+1] == nums[j]+1 {
+				j++
+			}
+			fmt.Fprintf(&buf, "%d %d\n", nums[i], j-i+1)
+			for k := i; k <= j; k++ {
+				fmt.Fprintf(&buf, "%010d 00000 n \n", offsets[nums[k]])
+			}
+			i = j + 1
+		}
+		fmt.Fprintf(&buf, "trailer\n%s\nstartxref\n%d\n%%%%EOF\n", trailer, xrefOffset)
+	}
+	return buf.Bytes()
+}
+
+// writeXrefStream writes a cross-reference stream for the objects at offsets.
+func (s *pdfObjectStore) writeXrefStream(buf *bytes.Buffer, offsets map[int]int, trailer string) {
+	xrefNum := s.nextNum
+	s.nextNum++
+	offsets[xrefNum] = buf.Len()
+
+	nums := make([]int, 0, len(offsets))
+	for num := range offsets {
+		nums = append(nums, num)
+	}
+	sort.Ints(nums)
+	var index []string
+	var data []byte
+	for i := 0; i < len(nums); {
+		j := i
+		for j+1 < len(nums) && nums[j+1] == nums[j]+1 {
+			j++
+		}
+		index = append(index, fmt.Sprintf("%d %d", nums[i], j-i+1))
+		for k := i; k <= j; k++ {
+			entry := make([]byte, 7)
+			entry[0] = 1
+			binary.BigEndian.PutUint32(entry[1:5], uint32(offsets[nums[k]]))
+			data = append(data, entry...)
+		}
+		i = j + 1
+	}
+
+	dict := pdfDictSet(trailer, "/Type", "/XRef")
+	dict = pdfDictSet(dict, "/Size", strconv.Itoa(s.nextNum))
+	dict = pdfDictSet(dict, "/W", "[1 4 2]")
+	dict = pdfDictSet(dict, "/Index", "["+strings.Join(index, " ")+"]")
+	dict = pdfDictSet(dict, "/Length", strconv.Itoa(len(data)))
+	fmt.Fprintf(buf, "%d 0 obj\n%s\nstream\n", xrefNum, dict)
+	buf.Write(data)
+	fmt.Fprintf(buf, "\nendstream\nendobj\nstartxref\n%d\n%%%%EOF\n", offsets[xrefNum])
+}
+
+// newTrailer returns the trailer entries kept from the original trailer.
+func (s *pdfObjectStore) newTrailer() string {
+	trailer := pdfDictSet("<<>>", "/Size", strconv.Itoa(s.nextNum))
+	trailer = pdfDictSet(trailer, "/Root", fmt.Sprintf("%d 0 R", s.catalog()))
+	for _, key := range []string{"/Info", "/ID"} {
+		if v := pdfDictGet(s.trailer, key); v != "" {
+			trailer = pdfDictSet(trailer, key, v)
+		}
+	}
+	return trailer
+}
+
+// rewrite writes a new file containing the current version of every object
+// reachable from the trailer. Objects of object streams are written as
+// plain objects.
+func (s *pdfObjectStore) rewrite() []byte {
+	nums := s.reachable()
+	sorted := make([]int, 0, len(nums))
+	for num := range nums {
+		sorted = append(sorted, num)
+	}
+	sort.Ints(sorted)
+
+	var buf bytes.Buffer
+	version := "1.7"
+	if m := regexp.MustCompile(`^%PDF-(\d\.\d)`).FindSubmatch(s.data); m != nil {
+		version = string(m[1])
+	}
+	fmt.Fprintf(&buf, "%%PDF-%s\n%%\xe2\xe3\xcf\xd3\n", version)
+	offsets := make(map[int]int)
+	for _, num := range sorted {
+		offsets[num] = buf.Len()
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", num, strings.TrimSpace(s.body(num)))
+	}
+
+	xrefOffset := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n", s.nextNum)
+	buf.WriteString("0000000000 65535 f \n")
+	for num := 1; num < s.nextNum; num++ {
+		if off, ok := offsets[num]; ok {
+			fmt.Fprintf(&buf, "%010d 00000 n \n", off)
+		} else {
+			buf.WriteString("0000000000 00000 f \n")
+		}
+	}
+	fmt.Fprintf(&buf, "trailer\n%s\nstartxref\n%d\n%%%%EOF\n", s.newTrailer(), xrefOffset)
+	return buf.Bytes()
+}
+
+// reachable returns the objects referenced directly or indirectly by the
+// trailer. Stream data is not searched for references.
+func (s *pdfObjectStore) reachable() map[int]bool {
+	nums := make(map[int]bool)
+	queue := []string{s.newTrailer()}
+	for len(queue) > 0 {
+		body := queue[len(queue)-1]
+		queue = queue[:len(queue)-1]
+		if b := strings.TrimSpace(body); strings.HasPrefix(b, "<<") {
+			body = b[:scanPDFValue(b, 0)]
+		}
+		for _, m := range reObjRef.FindAllStringSubmatch(body, -1) {
+			num, _ := strconv.Atoi(m[1])
+			if nums[num] {
+				continue
+			}
+			if _, ok := s.changed[num]; !ok {
+				if _, ok := s.parser.objects[num]; !ok {
+					continue
+				}
+			}
+			nums[num] = true
+			queue = append(queue, s.body(num))
+		}
+	}
+	return nums
+}
+
+// ============================================================
+// PDF syntax helpers working on object bodies as text
+// ============================================================
+
+func isPDFSpace(c byte) bool {
+	return c == ' ' || c == '\n' || c == '\r' || c == '\t' || c == '\f' || c == 0
+}
+
+func isPDFDelimiter(c byte) bool {
+	return strings.IndexByte("()<>[]{}/%", c) >= 0
+}
+
+// skipPDFSpace skips white space and comments.
+func skipPDFSpace(s string, i int) int {
+	for i < len(s) {
+		switch {
+		case isPDFSpace(s[i]):
+			i++
+		case s[i] == '%':
+			for i < len(s) && s[i] != '\n' && s[i] != '\r' {
+				i++
+			}
+		default:
+			return i
+		}
+	}
+	return i
+}
+
+// scanPDFValue returns the offset just after the PDF value starting at or
+// after s[i] (skipping white space). References "N G R" are one value.
+func scanPDFValue(s string, i int) int {
+	i = skipPDFSpace(s, i)
+	if i >= len(s) {
+		return i
+	}
+	switch c := s[i]; {
+	case c == '/':
+		j := i + 1
+		for j < len(s) && !isPDFSpace(s[j]) && !isPDFDelimiter(s[j]) {
+			j++
+		}
+		return j
+	case c == '(':
+		depth := 0
+		for j := i; j < len(s); j++ {
+			switch s[j] {
+			case '\\':
+				j++
+			case '(':
+				depth++
+			case ')':
+				depth--
+				if depth == 0 {
+					return j + 1
+				}
+			}
+		}
+		return len(s)
+	case c == '<' && i+1 < len(s) && s[i+1] == '<':
+		return scanPDFContainer(s, i+2, ">>")
+	case c == '<':
+		if j := strings.IndexByte(s[i:], '>'); j >= 0 {
+			return i + j + 1
+		}
+		return len(s)
+	case c == '[':
+		return scanPDFContainer(s, i+1, "]")
+	case c == '{':
+		return scanPDFContainer(s, i+1, "}")
+	case isPDFDelimiter(c):
+		return i + 1
+	}
+	j := i
+	for j < len(s) && !isPDFSpace(s[j]) && !isPDFDelimiter(s[j]) {
+		j++
+	}
+	if isPDFInteger(s[i:j]) {
+		k := skipPDFSpace(s, j)
+		l := k
+		for l < len(s) && s[l] >= '0' && s[l] <= '9' {
+			l++
+		}
+		if l > k {
+			r := skipPDFSpace(s, l)
+			if r < len(s) && s[r] == 'R' && (r+1 == len(s) || isPDFSpace(s[r+1]) || isPDFDelimiter(s[r+1])) {
+				return r + 1
+			}
+		}
+	}
+	return j
+}
+
+func scanPDFContainer(s string, i int, closing string) int {
+	for {
+		i = skipPDFSpace(s, i)
+		if i >= len(s) {
+			return len(s)
+		}
+		if strings.HasPrefix(s[i:], closing) {
+			return i + len(closing)
+		}
+		next := scanPDFValue(s, i)
+		if next <= i {
+			next = i + 1
+		}
+		i = next
+	}
+}
+
+func isPDFInteger(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// pdfDictEntry is a key and raw value of a PDF dictionary.
+type pdfDictEntry struct {
+	key   string // with the leading slash
+	value string
+}
+
+// parsePDFDict returns the top-level entries of a dictionary "<< ... >>".
+func parsePDFDict(dict string) []pdfDictEntry {
+	dict = strings.TrimSpace(dict)
+	if !strings.HasPrefix(dict, "<<") {
+		return nil
+	}
+	var entries []pdfDictEntry
+	i := 2
+	for {
+		i = skipPDFSpace(dict, i)
+		if i >= len(dict) || strings.HasPrefix(dict[i:], ">>") {
+			return entries
+		}
+		keyEnd := scanPDFValue(dict, i)
+		if dict[i] != '/' {
+			// malformed entry: skip the token
+			i = keyEnd
+			if i <= keyEnd && keyEnd == i {
+				i++
+			}
+			continue
+		}
+		key := dict[i:keyEnd]
+		valStart := skipPDFSpace(dict, keyEnd)
+		valEnd := scanPDFValue(dict, valStart)
+		entries = append(entries, pdfDictEntry{key: key, value: dict[valStart:valEnd]})
+		i = valEnd
+	}
+}
+
+// pdfDictGet returns the raw value of a top-level key such as "/Type", or "".
+func pdfDictGet(dict, key string) string {
+	for _, e := range parsePDFDict(dict) {
+		if e.key == key {
+			return e.value
+		}
+	}
+	return ""
+}
+
+// pdfDictSet returns dict with key set to value.
+func pdfDictSet(dict, key, value string) string {
+	entries := parsePDFDict(dict)
+	found := false
+	for i := range entries {
+		if entries[i].key == key {
+			entries[i].value = value
+			found = true
+		}
+	}
+	if !found {
+		entries = append(entries, pdfDictEntry{key: key, value: value})
+	}
+	return formatPDFDict(entries)
+}
+
+// pdfDictDelete returns dict without key.
+func pdfDictDelete(dict, key string) string {
+	entries := parsePDFDict(dict)
+	kept := entries[:0]
+	for _, e := range entries {
+		if e.key != key {
+			kept = append(kept, e)
+		}
+	}
+	return formatPDFDict(kept)
+}
+
+func formatPDFDict(entries []pdfDictEntry) string {
+	var sb strings.Builder
+	sb.WriteString("<<")
+	for _, e := range entries {
+		sb.WriteString("\n" + e.key + " " + e.value)
+	}
+	sb.WriteString("\n>>")
+	return sb.String()
+}
+
+// parsePDFArray returns the raw items of an array "[ ... ]".
+func parsePDFArray(arr string) []string {
+	arr = strings.TrimSpace(arr)
+	if !strings.HasPrefix(arr, "[") {
+		return nil
+	}
+	var items []string
+	i := 1
+	for {
+		i = skipPDFSpace(arr, i)
+		if i >= len(arr) || arr[i] == ']' {
+			return items
+		}
+		end := scanPDFValue(arr, i)
+		if end <= i {
+			end = i + 1
+		}
+		items = append(items, arr[i:end])
+		i = end
+	}
+}
+
+// pdfRef parses a reference "N G R".
+func pdfRef(value string) (int, bool) {
+	f := strings.Fields(value)
+	if len(f) != 3 || f[2] != "R" {
+		return 0, false
+	}
+	num, err := strconv.Atoi(f[0])
+	return num, err == nil
+}
+
+// pdfRefs returns the object numbers of the references in an array.
+func pdfRefs(arr string) []int {
+	var nums []int
+	for _, item := range parsePDFArray(arr) {
+		if num, ok := pdfRef(item); ok {
+			nums = append(nums, num)
+		}
+	}
+	return nums
+}
+
+// pdfNumbers parses an array of numbers.
+func pdfNumbers(arr string) []float64 {
+	var nums []float64
+	for _, item := range parsePDFArray(arr) {
+		if v, err := strconv.ParseFloat(item, 64); err == nil {
+			nums = append(nums, v)
+		}
+	}
+	return nums
+}
+
+// pdfNameValue decodes a name object such as "/Off" or "/A#20B".
+func pdfNameValue(value string) string {
+	if !strings.HasPrefix(value, "/") {
+		return ""
+	}
+	name := value[1:]
+	if !strings.Contains(name, "#") {
+		return name
+	}
+	var sb strings.Builder
+	for i := 0; i < len(name); i++ {
+		if name[i] == '#' && i+2 < len(name) {
+			if b, err := strconv.ParseUint(name[i+1:i+3], 16, 8); err == nil {
+				sb.WriteByte(byte(b))
+				i += 2
+				continue
+			}
+		}
+		sb.WriteByte(name[i])
+	}
+	return sb.String()
+}
+
+// encodePDFName encodes s as a name object.
+func encodePDFName(s string) string {
+	var sb strings.Builder
+	sb.WriteByte('/')
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c < '!' || c > '~' || c == '#' || isPDFDelimiter(c) {
+			fmt.Fprintf(&sb, "#%02X", c)
+		} else {
+			sb.WriteByte(c)
+		}
+	}
+	return sb.String()
+}
+
+// decodePDFTextString decodes a literal or hex string in PDFDocEncoding or
+// UTF-16BE with a byte order mark.
+func decodePDFTextString(value string) string {
+	value = strings.TrimSpace(value)
+	var b []byte
+	switch {
+	case strings.HasPrefix(value, "("):
+		b = decodeLiteralString(value)
+	case strings.HasPrefix(value, "<") && !strings.HasPrefix(value, "<<"):
+		b = decodeHexString(strings.TrimSuffix(value[1:], ">"))
+	default:
+		return ""
+	}
+	if len(b) >= 2 && b[0] == 0xFE && b[1] == 0xFF {
+		return decodeUTF16BE(b[2:])
+	}
+	runes := make([]rune, len(b))
+	for i, c := range b {
+		runes[i] = rune(c)
+	}
+	return string(runes)
+}
+
+// encodePDFTextString encodes s as a literal string, or as a UTF-16BE hex
+// string when it contains characters outside ASCII.
+func encodePDFTextString(s string) string {
+	ascii := true
+	for _, r := range s {
+		if r > 126 {
+			ascii = false
+			break
+		}
+	}
+	if ascii {
+		return "(" + escapeAnnotString(s) + ")"
+	}
+	var sb strings.Builder
+	sb.WriteString("<FEFF")
+	for _, c := range utf16.Encode([]rune(s)) {
+		fmt.Fprintf(&sb, "%04X", c)
+	}
+	sb.WriteString(">")
+	return sb.String()
+}
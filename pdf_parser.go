@@ -37,6 +37,7 @@ type rawPDFObject struct {
 	num    int
 	dict   string // dictionary content between << >>
 	stream []byte // decompressed stream content (nil if not a stream)
+	body   []byte // raw object body between "obj" and "endobj"
 }
 
 // rawPDFPage holds parsed page info.
@@ -72,20 +73,26 @@ func (p *rawPDFParser) parse() error {
 	return nil
 }
 
-// parseObjects finds all "N 0 obj ... endobj" blocks.
+// parseObjects finds all "N 0 obj ... endobj" blocks, then the objects
+// stored in object streams.
 func (p *rawPDFParser) parseObjects() {
 	matches := reObjHeader.FindAllSubmatchIndex(p.data, -1)
+	lastEnd := 0
 	for _, m := range matches {
+		if m[0] < lastEnd {
+			// "N 0 obj" inside the stream of the previous object
+			continue
+		}
 		numStr := string(p.data[m[2]:m[3]])
 		num, _ := strconv.Atoi(numStr)
 		objStart := m[0]
-		// find endobj
-		endIdx := bytes.Index(p.data[objStart:], []byte("endobj"))
+		endIdx := findObjectEnd(p.data, m[1])
 		if endIdx < 0 {
 			continue
 		}
-		objData := p.data[objStart : objStart+endIdx+6]
-		obj := rawPDFObject{num: num}
+		lastEnd = endIdx
+		objData := p.data[objStart : endIdx+6]
+		obj := rawPDFObject{num: num, body: p.data[m[1]:endIdx]}
 		// extract dictionary
 		if dictStart := bytes.Index(objData, []byte("<<")); dictStart >= 0 {
 			obj.dict = extractDict(objData[dictStart:])
@@ -117,6 +124,72 @@ func (p *rawPDFParser) parseObjects() {
 		}
 		p.objects[num] = obj
 	}
+	p.parseObjectStreams()
+}
+
+// findObjectEnd returns the offset of the "endobj" keyword closing the object
+// whose body starts at start, or -1. Stream data is skipped so that binary
+// content containing "endobj" does not end the object early.
+func findObjectEnd(data []byte, start int) int {
+	end := bytes.Index(data[start:], []byte("endobj"))
+	if end < 0 {
+		return -1
+	}
+	end += start
+	s := bytes.Index(data[start:end], []byte("stream"))
+	if s < 0 || bytes.Contains(data[start+s:end], []byte("endstream")) {
+		return end
+	}
+	es := bytes.Index(data[start+s:], []byte("endstream"))
+	if es < 0 {
+		return end
+	}
+	e := bytes.Index(data[start+s+es:], []byte("endobj"))
+	if e < 0 {
+		return end
+	}
+	return start + s + es + e
+}
+
+// parseObjectStreams adds the objects stored in object streams (/Type
+// /ObjStm) that are not defined directly in the file.
+func (p *rawPDFParser) parseObjectStreams() {
+	var streams []rawPDFObject
+	for _, obj := range p.objects {
+		if obj.stream != nil && pdfDictGet(obj.dict, "/Type") == "/ObjStm" {
+			streams = append(streams, obj)
+		}
+	}
+	for _, obj := range streams {
+		n, _ := strconv.Atoi(pdfDictGet(obj.dict, "/N"))
+		first, _ := strconv.Atoi(pdfDictGet(obj.dict, "/First"))
+		if first <= 0 || first > len(obj.stream) {
+			continue
+		}
+		header := strings.Fields(string(obj.stream[:first]))
+		for i := 0; i < n && 2*i+1 < len(header); i++ {
+			num, err1 := strconv.Atoi(header[2*i])
+			off, err2 := strconv.Atoi(header[2*i+1])
+			if err1 != nil || err2 != nil || first+off > len(obj.stream) {
+				continue
+			}
+			end := len(obj.stream)
+			if 2*i+3 < len(header) {
+				if next, err := strconv.Atoi(header[2*i+3]); err == nil && first+next <= end && next >= off {
+					end = first + next
+				}
+			}
+			if _, ok := p.objects[num]; ok {
+				continue
+			}
+			body := bytes.TrimSpace(obj.stream[first+off : end])
+			inner := rawPDFObject{num: num, body: body}
+			if bytes.HasPrefix(body, []byte("<<")) {
+				inner.dict = extractDict(body)
+			}
+			p.objects[num] = inner
+		}
+	}
 }
 
 // extractDict extracts the outermost <<...>> from data.
@@ -81,12 +81,47 @@ func RenderPageToImage(pdfData []byte, pageIndex int, opt RenderOption) (image.I
 	// Parse and render content stream
 	stream := parser.getPageContentStream(pageIndex)
 	if len(stream) > 0 {
-		renderContentStream(img, stream, parser, page, scale, pageH)
+		renderContentStream(img, stream, parser, page, scale, pageH, 0, 0, 0)
 	}
+	renderAnnotationAppearances(img, parser, page, scale, pageH)
 
 	return img, nil
 }
 
+// renderAnnotationAppearances draws the normal appearance streams of the
+// page annotations at their rectangles.
+func renderAnnotationAppearances(img *image.RGBA, parser *rawPDFParser, page rawPDFPage, scale, pageH float64) {
+	pageObj, ok := parser.objects[page.objNum]
+	if !ok {
+		return
+	}
+	for _, ref := range extractRefArray(pageObj.dict, "/Annots") {
+		annot, ok := parser.objects[ref]
+		if !ok {
+			continue
+		}
+		apIdx := strings.Index(annot.dict, "/AP")
+		if apIdx < 0 {
+			continue
+		}
+		form, ok := parser.objects[extractRef(annot.dict[apIdx:], "/N")]
+		if !ok || form.stream == nil {
+			continue
+		}
+		m := reLinkRect.FindStringSubmatch(annot.dict)
+		if m == nil {
+			continue
+		}
+		x1, _ := strconv.ParseFloat(m[1], 64)
+		y1, _ := strconv.ParseFloat(m[2], 64)
+		x2, _ := strconv.ParseFloat(m[3], 64)
+		y2, _ := strconv.ParseFloat(m[4], 64)
+		formPage := rawPDFPage{resources: parser.extractResources(form.dict, 0)}
+		renderContentStream(img, form.stream, parser, formPage, scale, pageH,
+			math.Min(x1, x2), math.Min(y1, y2), 1)
+	}
+}
+
 // RenderAllPagesToImages renders all pages to images.
 func RenderAllPagesToImages(pdfData []byte, opt RenderOption) ([]image.Image, error) {
 	opt.defaults()
@@ -107,7 +142,9 @@ func RenderAllPagesToImages(pdfData []byte, opt RenderOption) ([]image.Image, er
 }
 
 // renderContentStream interprets a PDF content stream and draws onto the image.
-func renderContentStream(img *image.RGBA, stream []byte, parser *rawPDFParser, page rawPDFPage, scale, pageH float64) {
+// offX and offY translate the stream, e.g. the content of a form XObject, and
+// depth is the form XObject nesting level.
+func renderContentStream(img *image.RGBA, stream []byte, parser *rawPDFParser, page rawPDFPage, scale, pageH, offX, offY float64, depth int) {
 	tokens := tokenize(stream)
 
 	var stack []float64
@@ -144,7 +181,7 @@ func renderContentStream(img *image.RGBA, stream []byte, parser *rawPDFParser, p
 				rw := stack[len(stack)-2]
 				rh := stack[len(stack)-1]
 				stack = stack[:len(stack)-4]
-				drawRectOnImage(img, rx, pageH-ry-rh, rw, rh, scale, strokeColor)
+				drawRectOnImage(img, offX+rx, pageH-offY-ry-rh, rw, rh, scale, strokeColor)
 			}
 
 		case "m":
@@ -196,10 +233,10 @@ func renderContentStream(img *image.RGBA, stream []byte, parser *rawPDFParser, p
 			}
 
 		case "Do":
-			// Draw XObject (image)
+			// Draw XObject (image or form)
 			if i >= 1 && strings.HasPrefix(tokens[i-1], "/") {
 				name := tokens[i-1]
-				renderXObject(img, parser, page, name, ctmA, ctmD, ctmE, ctmF, scale, pageH)
+				renderXObject(img, parser, page, name, ctmA, ctmD, offX+ctmE, offY+ctmF, scale, pageH, depth)
 			}
 
 		case "S", "s":
@@ -269,9 +306,9 @@ func drawRectOnImage(img *image.RGBA, x, y, w, h, scale float64, c color.RGBA) {
 	}
 }
 
-// renderXObject renders an image XObject onto the target image.
+// renderXObject renders an image or form XObject onto the target image.
 func renderXObject(img *image.RGBA, parser *rawPDFParser, page rawPDFPage, name string,
-	ctmA, ctmD, ctmE, ctmF, scale, pageH float64) {
+	ctmA, ctmD, ctmE, ctmF, scale, pageH float64, depth int) {
 
 	objNum, ok := page.resources.xobjs[name]
 	if !ok {
@@ -281,6 +318,14 @@ func renderXObject(img *image.RGBA, parser *rawPDFParser, page rawPDFPage, name
 	if !ok {
 		return
 	}
+	if strings.Contains(obj.dict, "/Subtype /Form") || strings.Contains(obj.dict, "/Subtype/Form") {
+		// forms drawing forms are limited to guard against cycles
+		if obj.stream != nil && depth < 8 {
+			formPage := rawPDFPage{resources: parser.extractResources(obj.dict, 0)}
+			renderContentStream(img, obj.stream, parser, formPage, scale, pageH, ctmE, ctmF, depth+1)
+		}
+		return
+	}
 	if !strings.Contains(obj.dict, "/Subtype /Image") &&
 		!strings.Contains(obj.dict, "/Subtype/Image") {
 		return
@@ -122,15 +122,8 @@ func (s *signatureFieldObj) write(w io.Writer, objID int) error {
 	// Find the page object reference for /P
 	if s.cfg.Visible {
 		gp := s.getRoot()
-		count := 0
-		for i, obj := range gp.pdfObjs {
-			if _, ok := obj.(*PageObj); ok {
-				count++
-				if count == s.cfg.PageNo {
-					fmt.Fprintf(w, "/P %d 0 R\n", i+1)
-					break
-				}
-			}
+		if id := gp.pageObjIDByNumber(s.cfg.PageNo); id > 0 {
+			fmt.Fprintf(w, "/P %d 0 R\n", id)
 		}
 	}
 
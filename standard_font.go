@@ -0,0 +1,373 @@
+package gopdf
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/VantageDataChat/GoPDF2/fontmaker/core"
+)
+
+// ============================================================
+// Standard 14 fonts
+// ============================================================
+
+// ErrCharNotEncodable is returned when text drawn with a standard 14 font
+// holds a character that the encoding of the font lacks.
+var ErrCharNotEncodable = errors.New("character not encodable in standard font")
+
+// ErrNotStandardFont is returned when AFM metrics are loaded for a font
+// that is not one of the standard 14 fonts.
+var ErrNotStandardFont = errors.New("not a standard 14 font")
+
+// standardFontFamilies maps the families of the standard 14 fonts to their
+// PostScript names by style: Regular, Italic, Bold and Bold|Italic.
+var standardFontFamilies = map[string][4]string{
+	"Helvetica":    {"Helvetica", "Helvetica-Oblique", "Helvetica-Bold", "Helvetica-BoldOblique"},
+	"Times":        {"Times-Roman", "Times-Italic", "Times-Bold", "Times-BoldItalic"},
+	"Courier":      {"Courier", "Courier-Oblique", "Courier-Bold", "Courier-BoldOblique"},
+	"Symbol":       {"Symbol"},
+	"ZapfDingbats": {"ZapfDingbats"},
+}
+
+// standardFont holds the metrics of a standard 14 font. Its glyph indexes
+// are the codes of its encoding.
+type standardFont struct {
+	name                                  string
+	builtin                               bool // built-in encoding instead of WinAnsiEncoding
+	widths                                [256]uint16
+	ascent, descent                       int
+	underlinePosition, underlineThickness int
+	kerning                               core.KernMap
+}
+
+// standardFontName returns the PostScript name of the standard 14 font of
+// family in style. A PostScript name is a family of its own in Regular.
+func standardFontName(family string, style int) (string, bool) {
+	if names, ok := standardFontFamilies[family]; ok {
+		name := names[style&(Bold|Italic)]
+		return name, name != ""
+	}
+	for _, names := range standardFontFamilies {
+		for _, name := range names {
+			if name == family && style&(Bold|Italic) == Regular {
+				return name, true
+			}
+		}
+	}
+	return "", false
+}
+
+// newStandardFont returns the bundled metrics of the standard 14 font name.
+func newStandardFont(name string) *standardFont {
+	f := &standardFont{name: name, underlinePosition: -100, underlineThickness: 50}
+	switch name {
+	case "Helvetica":
+		f.widths, f.ascent, f.descent = helveticaWinAnsiWidths, 718, -207
+	case "Helvetica-Oblique":
+		f.widths, f.ascent, f.descent = helveticaObliqueWinAnsiWidths, 718, -207
+	case "Helvetica-Bold":
+		f.widths, f.ascent, f.descent = helveticaBoldWinAnsiWidths, 718, -207
+	case "Helvetica-BoldOblique":
+		f.widths, f.ascent, f.descent = helveticaBoldObliqueWinAnsiWidths, 718, -207
+	case "Times-Roman":
+		f.widths, f.ascent, f.descent = timesRomanWinAnsiWidths, 683, -217
+	case "Times-Bold":
+		f.widths, f.ascent, f.descent = timesBoldWinAnsiWidths, 683, -217
+	case "Times-Italic":
+		f.widths, f.ascent, f.descent = timesItalicWinAnsiWidths, 683, -217
+	case "Times-BoldItalic":
+		f.widths, f.ascent, f.descent = timesBoldItalicWinAnsiWidths, 683, -217
+	case "Courier", "Courier-Oblique", "Courier-Bold", "Courier-BoldOblique":
+		f.ascent, f.descent = 629, -157
+		for code, w := range helveticaWinAnsiWidths {
+			if w != 0 {
+				f.widths[code] = 600
+			}
+		}
+	case "Symbol":
+		f.builtin, f.widths, f.ascent, f.descent = true, symbolWidths, 1010, -293
+	case "ZapfDingbats":
+		f.builtin, f.widths, f.ascent, f.descent = true, zapfDingbatsWidths, 820, -143
+	default:
+		return nil
+	}
+	if kerning := standardFontKerning[name]; kerning != "" {
+		f.parseAFM(strings.NewReader(kerning))
+	}
+	return f
+}
+
+// encode returns the code of r in the encoding of the font. Symbol maps
+// Greek letters and mathematical symbols to its codes, and ZapfDingbats
+// takes the codes of its built-in encoding.
+func (f *standardFont) encode(r rune) (byte, bool) {
+	var code byte
+	var ok bool
+	switch f.name {
+	case "Symbol":
+		code, ok = symbolCodes[r]
+	case "ZapfDingbats":
+		code, ok = byte(r), r >= 32 && r <= 255
+	default:
+		code, ok = winAnsiByte(r)
+	}
+	return code, ok && f.widths[code] != 0
+}
+
+// runeWidth returns the width of r in 1/1000 em, or false if r is not
+// encodable in the font.
+func (f *standardFont) runeWidth(r rune) (uint16, bool) {
+	code, ok := f.encode(r)
+	if !ok {
+		return 0, false
+	}
+	return f.widths[code], true
+}
+
+// write writes the font dictionary of a standard font.
+func (f *standardFont) write(w io.Writer) {
+	io.WriteString(w, "<<\n")
+	io.WriteString(w, "/Type /Font\n")
+	io.WriteString(w, "/Subtype /Type1\n")
+	fmt.Fprintf(w, "/BaseFont /%s\n", f.name)
+	if !f.builtin {
+		io.WriteString(w, "/Encoding /WinAnsiEncoding\n")
+	}
+	io.WriteString(w, ">>\n")
+}
+
+// parseAFM reads the metrics of an Adobe Font Metrics file: the font
+// name, ascender, descender, underline, glyph widths and kerning pairs.
+// Glyphs of WinAnsi encoded fonts are found by their names.
+func (f *standardFont) parseAFM(r io.Reader) error {
+	codes := make(map[string][]byte)
+	if !f.builtin {
+		for code, name := range winAnsiGlyphNames {
+			if name != "" {
+				codes[name] = append(codes[name], byte(code))
+			}
+		}
+	}
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 {
+			continue
+		}
+		switch fields[0] {
+		case "FontName":
+			f.name = fields[1]
+		case "Ascender":
+			f.ascent, _ = strconv.Atoi(fields[1])
+		case "Descender":
+			f.descent, _ = strconv.Atoi(fields[1])
+		case "UnderlinePosition":
+			f.underlinePosition, _ = strconv.Atoi(fields[1])
+		case "UnderlineThickness":
+			f.underlineThickness, _ = strconv.Atoi(fields[1])
+		case "C":
+			f.parseAFMChar(scanner.Text(), codes)
+		case "KPX":
+			if len(fields) < 4 {
+				continue
+			}
+			value, err := strconv.Atoi(fields[3])
+			if err != nil {
+				continue
+			}
+			for _, left := range codes[fields[1]] {
+				for _, right := range codes[fields[2]] {
+					if f.kerning == nil {
+						f.kerning = make(core.KernMap)
+					}
+					if f.kerning[uint(left)] == nil {
+						f.kerning[uint(left)] = make(core.KernValue)
+					}
+					f.kerning[uint(left)][uint(right)] = int16(value)
+				}
+			}
+		}
+	}
+	return scanner.Err()
+}
+
+// parseAFMChar reads a character metrics line such as
+// "C 65 ; WX 667 ; N A ; B 14 0 654 718 ;".
+func (f *standardFont) parseAFMChar(line string, codes map[string][]byte) {
+	code, width, name := -1, -1, ""
+	for _, item := range strings.Split(line, ";") {
+		fields := strings.Fields(item)
+		if len(fields) < 2 {
+			continue
+		}
+		switch fields[0] {
+		case "C":
+			code, _ = strconv.Atoi(fields[1])
+		case "WX", "W0X":
+			width, _ = strconv.Atoi(fields[1])
+		case "N":
+			name = fields[1]
+		}
+	}
+	if width < 0 {
+		return
+	}
+	if f.builtin {
+		if code < 0 || code > 255 {
+			return
+		}
+		f.widths[code] = uint16(width)
+		if name != "" {
+			codes[name] = append(codes[name], byte(code))
+		}
+		return
+	}
+	for _, c := range codes[name] {
+		f.widths[c] = uint16(width)
+	}
+}
+
+// LoadStandardFontAFM replaces the bundled metrics of a standard 14 font
+// with those of its Adobe Font Metrics file, such as Times-Bold.afm from
+// the Adobe Core14 AFM package, including all of its kerning pairs. The
+// font is named by the FontName of the file; glyphs missing from the file
+// keep their bundled widths.
+func (gp *GoPdf) LoadStandardFontAFM(r io.Reader) error {
+	f := &standardFont{}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if fields := strings.Fields(line); len(fields) >= 2 && fields[0] == "FontName" {
+			f.name = fields[1]
+			break
+		}
+	}
+	base := newStandardFont(f.name)
+	if base == nil {
+		return fmt.Errorf("%w: %q", ErrNotStandardFont, f.name)
+	}
+	*f = *base
+	f.kerning = nil
+	if err := f.parseAFM(strings.NewReader(string(data))); err != nil {
+		return err
+	}
+	if gp.standardFonts == nil {
+		gp.standardFonts = make(map[string]*standardFont)
+	}
+	gp.standardFonts[f.name] = f
+	for _, obj := range gp.pdfObjs {
+		if sub, ok := obj.(*SubsetFontObj); ok && sub.standard != nil && sub.standard.name == f.name {
+			sub.standard = f
+		}
+	}
+	return nil
+}
+
+// addStandardFont adds the standard 14 font of family in style, so that
+// SetFont works without a TTF for these families.
+func (gp *GoPdf) addStandardFont(family string, style int) (*SubsetFontObj, bool) {
+	name, ok := standardFontName(family, style)
+	if !ok {
+		return nil, false
+	}
+	metrics := gp.standardFonts[name]
+	if metrics == nil {
+		metrics = newStandardFont(name)
+	}
+	option := defaultTtfFontOption()
+	option.Style = style &^ decorations
+	option.UseKerning = true
+	sub := new(SubsetFontObj)
+	sub.init(func() *GoPdf {
+		return gp
+	})
+	sub.SetTtfFontOption(option)
+	sub.SetFamily(family)
+	sub.standard = metrics
+	index := gp.addObj(sub)
+	if gp.indexOfProcSet != -1 {
+		procset := gp.pdfObjs[gp.indexOfProcSet].(*ProcSetObj)
+		procset.Relates = append(procset.Relates, RelateFont{Family: family, IndexOfObj: index, CountOfFont: gp.curr.CountOfFont, Style: option.Style})
+		sub.CountOfFont = gp.curr.CountOfFont
+		gp.curr.CountOfFont++
+	}
+	return sub, true
+}
+
+// symbolCodes maps characters to the codes of the Symbol font.
+var symbolCodes = func() map[rune]byte {
+	m := make(map[rune]byte)
+	for _, r := range " !#%&()+,./0123456789:;<=>?[]_{|}" {
+		m[r] = byte(r)
+	}
+	greek := "ΑΒΧΔΕΦΓΗΙϑΚΛΜΝΟΠΘΡΣΤΥςΩΞΨΖ"
+	i := 0
+	for _, r := range greek {
+		m[r] = byte('A' + i)
+		i++
+	}
+	i = 0
+	for _, r := range "αβχδεφγηιϕκλμνοπθρστυϖωξψζ" {
+		m[r] = byte('a' + i)
+		i++
+	}
+	for r, code := range map[rune]byte{
+		'-': 0x2D, '∀': 0x22, '∃': 0x24, '∋': 0x27, '∗': 0x2A, '−': 0x2D, '≅': 0x40,
+		'∴': 0x5C, '⊥': 0x5E, '∼': 0x7E, '€': 0xA0, 'ϒ': 0xA1, '′': 0xA2, '≤': 0xA3,
+		'⁄': 0xA4, '∞': 0xA5, 'ƒ': 0xA6, '♣': 0xA7, '♦': 0xA8, '♥': 0xA9, '♠': 0xAA,
+		'↔': 0xAB, '←': 0xAC, '↑': 0xAD, '→': 0xAE, '↓': 0xAF, '°': 0xB0, '±': 0xB1,
+		'″': 0xB2, '≥': 0xB3, '×': 0xB4, '∝': 0xB5, '∂': 0xB6, '•': 0xB7, '÷': 0xB8,
+		'≠': 0xB9, '≡': 0xBA, '≈': 0xBB, '…': 0xBC, '↵': 0xBF, 'ℵ': 0xC0, 'ℑ': 0xC1,
+		'ℜ': 0xC2, '℘': 0xC3, '⊗': 0xC4, '⊕': 0xC5, '∅': 0xC6, '∩': 0xC7, '∪': 0xC8,
+		'⊃': 0xC9, '⊇': 0xCA, '⊄': 0xCB, '⊂': 0xCC, '⊆': 0xCD, '∈': 0xCE, '∉': 0xCF,
+		'∠': 0xD0, '∇': 0xD1, '∏': 0xD5, '√': 0xD6, '⋅': 0xD7, '¬': 0xD8, '∧': 0xD9,
+		'∨': 0xDA, '⇔': 0xDB, '⇐': 0xDC, '⇑': 0xDD, '⇒': 0xDE, '⇓': 0xDF, '◊': 0xE0,
+		'〈': 0xE1, '∑': 0xE5, '〉': 0xF1, '∫': 0xF2,
+	} {
+		m[r] = code
+	}
+	return m
+}()
+
+// winAnsiGlyphNames are the glyph names of the codes of WinAnsiEncoding.
+var winAnsiGlyphNames = func() [256]string {
+	var names [256]string
+	ascii := []string{
+		"space", "exclam", "quotedbl", "numbersign", "dollar", "percent", "ampersand", "quotesingle",
+		"parenleft", "parenright", "asterisk", "plus", "comma", "hyphen", "period", "slash",
+		"zero", "one", "two", "three", "four", "five", "six", "seven", "eight", "nine",
+		"colon", "semicolon", "less", "equal", "greater", "question", "at",
+	}
+	copy(names[32:], ascii)
+	for c := 'A'; c <= 'Z'; c++ {
+		names[c] = string(c)
+		names[c+32] = string(c + 32)
+	}
+	copy(names[91:], []string{"bracketleft", "backslash", "bracketright", "asciicircum", "underscore", "grave"})
+	copy(names[123:], []string{"braceleft", "bar", "braceright", "asciitilde"})
+	copy(names[128:], []string{
+		"Euro", "", "quotesinglbase", "florin", "quotedblbase", "ellipsis", "dagger", "daggerdbl",
+		"circumflex", "perthousand", "Scaron", "guilsinglleft", "OE", "", "Zcaron", "",
+		"", "quoteleft", "quoteright", "quotedblleft", "quotedblright", "bullet", "endash", "emdash",
+		"tilde", "trademark", "scaron", "guilsinglright", "oe", "", "zcaron", "Ydieresis",
+		"space", "exclamdown", "cent", "sterling", "currency", "yen", "brokenbar", "section",
+		"dieresis", "copyright", "ordfeminine", "guillemotleft", "logicalnot", "hyphen", "registered", "macron",
+		"degree", "plusminus", "twosuperior", "threesuperior", "acute", "mu", "paragraph", "periodcentered",
+		"cedilla", "onesuperior", "ordmasculine", "guillemotright", "onequarter", "onehalf", "threequarters", "questiondown",
+		"Agrave", "Aacute", "Acircumflex", "Atilde", "Adieresis", "Aring", "AE", "Ccedilla",
+		"Egrave", "Eacute", "Ecircumflex", "Edieresis", "Igrave", "Iacute", "Icircumflex", "Idieresis",
+		"Eth", "Ntilde", "Ograve", "Oacute", "Ocircumflex", "Otilde", "Odieresis", "multiply",
+		"Oslash", "Ugrave", "Uacute", "Ucircumflex", "Udieresis", "Yacute", "Thorn", "germandbls",
+		"agrave", "aacute", "acircumflex", "atilde", "adieresis", "aring", "ae", "ccedilla",
+		"egrave", "eacute", "ecircumflex", "edieresis", "igrave", "iacute", "icircumflex", "idieresis",
+		"eth", "ntilde", "ograve", "oacute", "ocircumflex", "otilde", "odieresis", "divide",
+		"oslash", "ugrave", "uacute", "ucircumflex", "udieresis", "yacute", "thorn", "ydieresis",
+	})
+	return names
+}()
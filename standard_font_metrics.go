@@ -0,0 +1,1381 @@
+package gopdf
+
+// ============================================================
+// Metrics of the standard 14 fonts
+// ============================================================
+
+// The glyph widths of the standard 14 fonts from the Adobe Core14 AFM
+// files, in 1/1000 em, indexed by the codes of the font encoding:
+// WinAnsiEncoding for the Helvetica, Times and Courier families and the
+// built-in encodings of Symbol and ZapfDingbats. Codes without a glyph
+// have width 0.
+
+// helveticaWinAnsiWidths are the Helvetica widths.
+var helveticaWinAnsiWidths = [256]uint16{
+	0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
+	278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333, 278, 278,
+	556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 278, 278, 584, 584, 584, 556,
+	1015, 667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833, 722, 778,
+	667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 278, 278, 278, 469, 556,
+	333, 556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833, 556, 556,
+	556, 556, 333, 500, 278, 556, 500, 722, 500, 500, 500, 334, 260, 334, 584, 0,
+	556, 0, 222, 556, 333, 1000, 556, 556, 333, 1000, 667, 333, 1000, 0, 611, 0,
+	0, 222, 222, 333, 333, 350, 556, 1000, 333, 1000, 500, 333, 944, 0, 500, 667,
+	278, 333, 556, 556, 556, 556, 260, 556, 333, 737, 370, 556, 584, 333, 737, 333,
+	400, 584, 333, 333, 333, 556, 537, 278, 333, 333, 365, 556, 834, 834, 834, 611,
+	667, 667, 667, 667, 667, 667, 1000, 722, 667, 667, 667, 667, 278, 278, 278, 278,
+	722, 722, 778, 778, 778, 778, 778, 584, 778, 722, 722, 722, 722, 667, 667, 611,
+	556, 556, 556, 556, 556, 556, 889, 500, 556, 556, 556, 556, 278, 278, 278, 278,
+	556, 556, 556, 556, 556, 556, 556, 584, 611, 556, 556, 556, 556, 500, 556, 500,
+}
+
+// helveticaBoldWinAnsiWidths are the Helvetica-Bold widths.
+var helveticaBoldWinAnsiWidths = [256]uint16{
+	0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
+	278, 333, 474, 556, 556, 889, 722, 238, 333, 333, 389, 584, 278, 333, 278, 278,
+	556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 333, 333, 584, 584, 584, 611,
+	975, 722, 722, 722, 722, 667, 611, 778, 722, 278, 556, 722, 611, 833, 722, 778,
+	667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 333, 278, 333, 584, 556,
+	333, 556, 611, 556, 611, 556, 333, 611, 611, 278, 278, 556, 278, 889, 611, 611,
+	611, 611, 389, 556, 333, 611, 556, 778, 556, 556, 500, 389, 280, 389, 584, 0,
+	556, 0, 278, 556, 500, 1000, 556, 556, 333, 1000, 667, 333, 1000, 0, 611, 0,
+	0, 278, 278, 500, 500, 350, 556, 1000, 333, 1000, 556, 333, 944, 0, 500, 667,
+	278, 333, 556, 556, 556, 556, 280, 556, 333, 737, 370, 556, 584, 333, 737, 333,
+	400, 584, 333, 333, 333, 611, 556, 278, 333, 333, 365, 556, 834, 834, 834, 611,
+	722, 722, 722, 722, 722, 722, 1000, 722, 667, 667, 667, 667, 278, 278, 278, 278,
+	722, 722, 778, 778, 778, 778, 778, 584, 778, 722, 722, 722, 722, 667, 667, 611,
+	556, 556, 556, 556, 556, 556, 889, 556, 556, 556, 556, 556, 278, 278, 278, 278,
+	611, 611, 611, 611, 611, 611, 611, 584, 611, 611, 611, 611, 611, 556, 611, 556,
+}
+
+// helveticaObliqueWinAnsiWidths are the Helvetica-Oblique widths.
+var helveticaObliqueWinAnsiWidths = [256]uint16{
+	0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
+	278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333, 278, 278,
+	556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 278, 278, 584, 584, 584, 556,
+	1015, 667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833, 722, 778,
+	667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 278, 278, 278, 469, 556,
+	333, 556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833, 556, 556,
+	556, 556, 333, 500, 278, 556, 500, 722, 500, 500, 500, 334, 260, 334, 584, 0,
+	556, 0, 222, 556, 333, 1000, 556, 556, 333, 1000, 667, 333, 1000, 0, 611, 0,
+	0, 222, 222, 333, 333, 350, 556, 1000, 333, 1000, 500, 333, 944, 0, 500, 667,
+	278, 333, 556, 556, 556, 556, 260, 556, 333, 737, 370, 556, 584, 333, 737, 333,
+	400, 584, 333, 333, 333, 556, 537, 278, 333, 333, 365, 556, 834, 834, 834, 611,
+	667, 667, 667, 667, 667, 667, 1000, 722, 667, 667, 667, 667, 278, 278, 278, 278,
+	722, 722, 778, 778, 778, 778, 778, 584, 778, 722, 722, 722, 722, 667, 667, 611,
+	556, 556, 556, 556, 556, 556, 889, 500, 556, 556, 556, 556, 278, 278, 278, 278,
+	556, 556, 556, 556, 556, 556, 556, 584, 611, 556, 556, 556, 556, 500, 556, 500,
+}
+
+// helveticaBoldObliqueWinAnsiWidths are the Helvetica-BoldOblique widths.
+var helveticaBoldObliqueWinAnsiWidths = [256]uint16{
+	0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
+	278, 333, 474, 556, 556, 889, 722, 238, 333, 333, 389, 584, 278, 333, 278, 278,
+	556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 333, 333, 584, 584, 584, 611,
+	975, 722, 722, 722, 722, 667, 611, 778, 722, 278, 556, 722, 611, 833, 722, 778,
+	667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 333, 278, 333, 584, 556,
+	333, 556, 611, 556, 611, 556, 333, 611, 611, 278, 278, 556, 278, 889, 611, 611,
+	611, 611, 389, 556, 333, 611, 556, 778, 556, 556, 500, 389, 280, 389, 584, 0,
+	556, 0, 278, 556, 500, 1000, 556, 556, 333, 1000, 667, 333, 1000, 0, 611, 0,
+	0, 278, 278, 500, 500, 350, 556, 1000, 333, 1000, 556, 333, 944, 0, 500, 667,
+	278, 333, 556, 556, 556, 556, 280, 556, 333, 737, 370, 556, 584, 333, 737, 333,
+	400, 584, 333, 333, 333, 611, 556, 278, 333, 333, 365, 556, 834, 834, 834, 611,
+	722, 722, 722, 722, 722, 722, 1000, 722, 667, 667, 667, 667, 278, 278, 278, 278,
+	722, 722, 778, 778, 778, 778, 778, 584, 778, 722, 722, 722, 722, 667, 667, 611,
+	556, 556, 556, 556, 556, 556, 889, 556, 556, 556, 556, 556, 278, 278, 278, 278,
+	611, 611, 611, 611, 611, 611, 611, 584, 611, 611, 611, 611, 611, 556, 611, 556,
+}
+
+// timesRomanWinAnsiWidths are the Times-Roman widths.
+var timesRomanWinAnsiWidths = [256]uint16{
+	0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
+	250, 333, 408, 500, 500, 833, 778, 180, 333, 333, 500, 564, 250, 333, 250, 278,
+	500, 500, 500, 500, 500, 500, 500, 500, 500, 500, 278, 278, 564, 564, 564, 444,
+	921, 722, 667, 667, 722, 611, 556, 722, 722, 333, 389, 722, 611, 889, 722, 722,
+	556, 722, 667, 556, 611, 722, 722, 944, 722, 722, 611, 333, 278, 333, 469, 500,
+	333, 444, 500, 444, 500, 444, 333, 500, 500, 278, 278, 500, 278, 778, 500, 500,
+	500, 500, 333, 389, 278, 500, 500, 722, 500, 500, 444, 480, 200, 480, 541, 0,
+	500, 0, 333, 500, 444, 1000, 500, 500, 333, 1000, 556, 333, 889, 0, 611, 0,
+	0, 333, 333, 444, 444, 350, 500, 1000, 333, 980, 389, 333, 722, 0, 444, 722,
+	250, 333, 500, 500, 500, 500, 200, 500, 333, 760, 276, 500, 564, 333, 760, 333,
+	400, 564, 300, 300, 333, 500, 453, 250, 333, 300, 310, 500, 750, 750, 750, 444,
+	722, 722, 722, 722, 722, 722, 889, 667, 611, 611, 611, 611, 333, 333, 333, 333,
+	722, 722, 722, 722, 722, 722, 722, 564, 722, 722, 722, 722, 722, 722, 556, 500,
+	444, 444, 444, 444, 444, 444, 667, 444, 444, 444, 444, 444, 278, 278, 278, 278,
+	500, 500, 500, 500, 500, 500, 500, 564, 500, 500, 500, 500, 500, 500, 500, 500,
+}
+
+// timesBoldWinAnsiWidths are the Times-Bold widths.
+var timesBoldWinAnsiWidths = [256]uint16{
+	0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
+	250, 333, 555, 500, 500, 1000, 833, 278, 333, 333, 500, 570, 250, 333, 250, 278,
+	500, 500, 500, 500, 500, 500, 500, 500, 500, 500, 333, 333, 570, 570, 570, 500,
+	930, 722, 667, 722, 722, 667, 611, 778, 778, 389, 500, 778, 667, 944, 722, 778,
+	611, 778, 722, 556, 667, 722, 722, 1000, 722, 722, 667, 333, 278, 333, 581, 500,
+	333, 500, 556, 444, 556, 444, 333, 500, 556, 278, 333, 556, 278, 833, 556, 500,
+	556, 556, 444, 389, 333, 556, 500, 722, 500, 500, 444, 394, 220, 394, 520, 0,
+	500, 0, 333, 500, 500, 1000, 500, 500, 333, 1000, 556, 333, 1000, 0, 667, 0,
+	0, 333, 333, 500, 500, 350, 500, 1000, 333, 1000, 389, 333, 722, 0, 444, 722,
+	250, 333, 500, 500, 500, 500, 220, 500, 333, 747, 300, 500, 570, 333, 747, 333,
+	400, 570, 300, 300, 333, 556, 540, 250, 333, 300, 330, 500, 750, 750, 750, 500,
+	722, 722, 722, 722, 722, 722, 1000, 722, 667, 667, 667, 667, 389, 389, 389, 389,
+	722, 722, 778, 778, 778, 778, 778, 570, 778, 722, 722, 722, 722, 722, 611, 556,
+	500, 500, 500, 500, 500, 500, 722, 444, 444, 444, 444, 444, 278, 278, 278, 278,
+	500, 556, 500, 500, 500, 500, 500, 570, 500, 556, 556, 556, 556, 500, 556, 500,
+}
+
+// timesItalicWinAnsiWidths are the Times-Italic widths.
+var timesItalicWinAnsiWidths = [256]uint16{
+	0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
+	250, 333, 420, 500, 500, 833, 778, 214, 333, 333, 500, 675, 250, 333, 250, 278,
+	500, 500, 500, 500, 500, 500, 500, 500, 500, 500, 333, 333, 675, 675, 675, 500,
+	920, 611, 611, 667, 722, 611, 611, 722, 722, 333, 444, 667, 556, 833, 667, 722,
+	611, 722, 611, 500, 556, 722, 611, 833, 611, 556, 556, 389, 278, 389, 422, 500,
+	333, 500, 500, 444, 500, 444, 278, 500, 500, 278, 278, 444, 278, 722, 500, 500,
+	500, 500, 389, 389, 278, 500, 444, 667, 444, 444, 389, 400, 275, 400, 541, 0,
+	500, 0, 333, 500, 556, 889, 500, 500, 333, 1000, 500, 333, 944, 0, 556, 0,
+	0, 333, 333, 556, 556, 350, 500, 889, 333, 980, 389, 333, 667, 0, 389, 556,
+	250, 389, 500, 500, 500, 500, 275, 500, 333, 760, 276, 500, 675, 333, 760, 333,
+	400, 675, 300, 300, 333, 500, 523, 250, 333, 300, 310, 500, 750, 750, 750, 500,
+	611, 611, 611, 611, 611, 611, 889, 667, 611, 611, 611, 611, 333, 333, 333, 333,
+	722, 667, 722, 722, 722, 722, 722, 675, 722, 722, 722, 722, 722, 556, 611, 500,
+	500, 500, 500, 500, 500, 500, 667, 444, 444, 444, 444, 444, 278, 278, 278, 278,
+	500, 500, 500, 500, 500, 500, 500, 675, 500, 500, 500, 500, 500, 444, 500, 444,
+}
+
+// timesBoldItalicWinAnsiWidths are the Times-BoldItalic widths.
+var timesBoldItalicWinAnsiWidths = [256]uint16{
+	0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
+	250, 389, 555, 500, 500, 833, 778, 278, 333, 333, 500, 570, 250, 333, 250, 278,
+	500, 500, 500, 500, 500, 500, 500, 500, 500, 500, 333, 333, 570, 570, 570, 500,
+	832, 667, 667, 667, 722, 667, 667, 722, 778, 389, 500, 667, 611, 889, 722, 722,
+	611, 722, 667, 556, 611, 722, 667, 889, 667, 611, 611, 333, 278, 333, 570, 500,
+	333, 500, 500, 444, 500, 444, 333, 500, 556, 278, 278, 500, 278, 778, 556, 500,
+	500, 500, 389, 389, 278, 556, 444, 667, 500, 444, 389, 348, 220, 348, 570, 0,
+	500, 0, 333, 500, 500, 1000, 500, 500, 333, 1000, 556, 333, 944, 0, 611, 0,
+	0, 333, 333, 500, 500, 350, 500, 1000, 333, 1000, 389, 333, 722, 0, 389, 611,
+	250, 389, 500, 500, 500, 500, 220, 500, 333, 747, 266, 500, 606, 333, 747, 333,
+	400, 570, 300, 300, 333, 576, 500, 250, 333, 300, 300, 500, 750, 750, 750, 500,
+	667, 667, 667, 667, 667, 667, 944, 667, 667, 667, 667, 667, 389, 389, 389, 389,
+	722, 722, 722, 722, 722, 722, 722, 570, 722, 722, 722, 722, 722, 611, 611, 500,
+	500, 500, 500, 500, 500, 500, 722, 444, 444, 444, 444, 444, 278, 278, 278, 278,
+	500, 556, 500, 500, 500, 500, 500, 570, 500, 556, 556, 556, 556, 444, 500, 444,
+}
+
+// zapfDingbatsWidths are the ZapfDingbats widths.
+var zapfDingbatsWidths = [256]uint16{
+	0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
+	278, 974, 961, 974, 980, 719, 789, 790, 791, 690, 960, 939, 549, 855, 911, 933,
+	911, 945, 974, 755, 846, 762, 761, 571, 677, 763, 760, 759, 754, 494, 552, 537,
+	577, 692, 786, 788, 788, 790, 793, 794, 816, 823, 789, 841, 823, 833, 816, 831,
+	923, 744, 723, 749, 790, 792, 695, 776, 768, 792, 759, 707, 708, 682, 701, 826,
+	815, 789, 789, 707, 687, 696, 689, 786, 787, 713, 791, 785, 791, 873, 761, 762,
+	762, 759, 759, 892, 892, 788, 784, 438, 138, 277, 415, 392, 392, 668, 668, 0,
+	390, 390, 317, 317, 276, 276, 509, 509, 410, 410, 234, 234, 334, 334, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
+	0, 732, 544, 544, 910, 667, 760, 760, 776, 595, 694, 626, 788, 788, 788, 788,
+	788, 788, 788, 788, 788, 788, 788, 788, 788, 788, 788, 788, 788, 788, 788, 788,
+	788, 788, 788, 788, 788, 788, 788, 788, 788, 788, 788, 788, 788, 788, 788, 788,
+	788, 788, 788, 788, 894, 838, 1016, 458, 748, 924, 748, 918, 927, 928, 928, 834,
+	873, 828, 924, 924, 917, 930, 931, 463, 883, 836, 836, 867, 867, 696, 696, 874,
+	0, 874, 760, 946, 771, 865, 771, 888, 967, 888, 831, 873, 927, 970, 918, 0,
+}
+
+// symbolWidths are the Symbol widths.
+var symbolWidths = [256]uint16{
+	0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
+	250, 333, 713, 500, 549, 833, 778, 439, 333, 333, 500, 549, 250, 549, 250, 278,
+	500, 500, 500, 500, 500, 500, 500, 500, 500, 500, 278, 278, 549, 549, 549, 444,
+	549, 722, 667, 722, 612, 611, 763, 603, 722, 333, 631, 722, 686, 889, 722, 722,
+	768, 741, 556, 592, 611, 690, 439, 768, 645, 795, 611, 333, 863, 333, 658, 500,
+	500, 631, 549, 549, 494, 439, 521, 411, 603, 329, 603, 549, 549, 576, 521, 549,
+	549, 521, 549, 603, 439, 576, 713, 686, 493, 686, 494, 480, 200, 480, 549, 0,
+	0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
+	750, 620, 247, 549, 167, 713, 500, 753, 753, 753, 753, 1042, 987, 603, 987, 603,
+	400, 549, 411, 549, 549, 713, 494, 460, 549, 549, 549, 549, 1000, 603, 1000, 658,
+	823, 686, 795, 987, 768, 768, 823, 768, 768, 713, 713, 713, 713, 713, 713, 713,
+	768, 713, 790, 790, 890, 823, 549, 250, 713, 603, 603, 1042, 987, 603, 987, 603,
+	494, 329, 790, 790, 786, 713, 384, 384, 384, 384, 384, 384, 494, 494, 494, 494,
+	0, 329, 274, 686, 686, 686, 384, 384, 384, 384, 384, 384, 494, 494, 494, 0,
+}
+
+// standardFontKerning maps the standard 14 fonts to their kerning pairs
+// between unaccented characters, as KPX lines of the Adobe Core14 AFM
+// files. The Courier family, Symbol and ZapfDingbats have none.
+var standardFontKerning = map[string]string{
+	"Helvetica":             helveticaKerning,
+	"Helvetica-Oblique":     helveticaKerning,
+	"Helvetica-Bold":        helveticaBoldKerning,
+	"Helvetica-BoldOblique": helveticaBoldKerning,
+	"Times-Roman":           timesRomanKerning,
+	"Times-Bold":            timesBoldKerning,
+	"Times-Italic":          timesItalicKerning,
+	"Times-BoldItalic":      timesBoldItalicKerning,
+}
+
+// helveticaKerning are the kerning pairs of Helvetica and
+// Helvetica-Oblique.
+const helveticaKerning = `
+KPX A C -30
+KPX A G -30
+KPX A O -30
+KPX A Q -30
+KPX A T -120
+KPX A U -50
+KPX A V -70
+KPX A W -50
+KPX A Y -100
+KPX A u -30
+KPX A v -40
+KPX A w -40
+KPX A y -40
+KPX B U -10
+KPX B comma -20
+KPX B period -20
+KPX C comma -30
+KPX C period -30
+KPX D A -40
+KPX D V -70
+KPX D W -40
+KPX D Y -90
+KPX D comma -70
+KPX D period -70
+KPX F A -80
+KPX F a -50
+KPX F comma -150
+KPX F e -30
+KPX F o -30
+KPX F period -150
+KPX F r -45
+KPX J A -20
+KPX J a -20
+KPX J comma -30
+KPX J period -30
+KPX J u -20
+KPX K O -50
+KPX K e -40
+KPX K o -40
+KPX K u -30
+KPX K y -50
+KPX L T -110
+KPX L V -110
+KPX L W -70
+KPX L Y -140
+KPX L quotedblright -140
+KPX L quoteright -160
+KPX L y -30
+KPX O A -20
+KPX O T -40
+KPX O V -50
+KPX O W -30
+KPX O X -60
+KPX O Y -70
+KPX O comma -40
+KPX O period -40
+KPX P A -120
+KPX P a -40
+KPX P comma -180
+KPX P e -50
+KPX P o -50
+KPX P period -180
+KPX Q U -10
+KPX R O -20
+KPX R T -30
+KPX R U -40
+KPX R V -50
+KPX R W -30
+KPX R Y -50
+KPX S comma -20
+KPX S period -20
+KPX T A -120
+KPX T O -40
+KPX T a -120
+KPX T colon -20
+KPX T comma -120
+KPX T e -120
+KPX T hyphen -140
+KPX T o -120
+KPX T period -120
+KPX T r -120
+KPX T semicolon -20
+KPX T u -120
+KPX T w -120
+KPX T y -120
+KPX U A -40
+KPX U comma -40
+KPX U period -40
+KPX V A -80
+KPX V G -40
+KPX V O -40
+KPX V a -70
+KPX V colon -40
+KPX V comma -125
+KPX V e -80
+KPX V hyphen -80
+KPX V o -80
+KPX V period -125
+KPX V semicolon -40
+KPX V u -70
+KPX W A -50
+KPX W O -20
+KPX W a -40
+KPX W comma -80
+KPX W e -30
+KPX W hyphen -40
+KPX W o -30
+KPX W period -80
+KPX W u -30
+KPX W y -20
+KPX Y A -110
+KPX Y O -85
+KPX Y a -140
+KPX Y colon -60
+KPX Y comma -140
+KPX Y e -140
+KPX Y hyphen -140
+KPX Y i -20
+KPX Y o -140
+KPX Y period -140
+KPX Y semicolon -60
+KPX Y u -110
+KPX Y v -110
+KPX a v -20
+KPX a w -20
+KPX a y -30
+KPX b b -10
+KPX b comma -40
+KPX b l -20
+KPX b period -40
+KPX b u -20
+KPX b v -20
+KPX b y -20
+KPX c comma -15
+KPX c k -20
+KPX colon space -50
+KPX comma quotedblright -100
+KPX comma quoteright -100
+KPX e comma -15
+KPX e period -15
+KPX e v -30
+KPX e w -20
+KPX e x -30
+KPX e y -20
+KPX f a -30
+KPX f comma -30
+KPX f e -30
+KPX f o -30
+KPX f period -30
+KPX f quotedblright 60
+KPX f quoteright 50
+KPX g r -10
+KPX h y -30
+KPX k e -20
+KPX k o -20
+KPX m u -10
+KPX m y -15
+KPX n u -10
+KPX n v -20
+KPX n y -15
+KPX o comma -40
+KPX o period -40
+KPX o v -15
+KPX o w -15
+KPX o x -30
+KPX o y -30
+KPX p comma -35
+KPX p period -35
+KPX p y -30
+KPX period quotedblright -100
+KPX period quoteright -100
+KPX period space -60
+KPX quotedblright space -40
+KPX quoteleft quoteleft -57
+KPX quoteright d -50
+KPX quoteright quoteright -57
+KPX quoteright r -50
+KPX quoteright s -50
+KPX quoteright space -70
+KPX r a -10
+KPX r colon 30
+KPX r comma -50
+KPX r hyphen -20
+KPX r period -50
+KPX r semicolon 30
+KPX s comma -15
+KPX s period -15
+KPX s w -30
+KPX semicolon space -50
+KPX space T -50
+KPX space V -50
+KPX space W -40
+KPX space Y -90
+KPX space quotedblleft -30
+KPX space quoteleft -60
+KPX v a -25
+KPX v comma -80
+KPX v e -25
+KPX v o -25
+KPX v period -80
+KPX w a -15
+KPX w comma -60
+KPX w e -10
+KPX w o -10
+KPX w period -60
+KPX x e -30
+KPX y a -20
+KPX y comma -100
+KPX y e -20
+KPX y o -20
+KPX y period -100
+KPX z e -15
+KPX z o -15
+`
+
+// helveticaBoldKerning are the kerning pairs of Helvetica-Bold and
+// Helvetica-BoldOblique.
+const helveticaBoldKerning = `
+KPX A C -40
+KPX A G -50
+KPX A O -40
+KPX A Q -40
+KPX A T -90
+KPX A U -50
+KPX A V -80
+KPX A W -60
+KPX A Y -110
+KPX A u -30
+KPX A v -40
+KPX A w -30
+KPX A y -30
+KPX B A -30
+KPX B U -10
+KPX D A -40
+KPX D V -40
+KPX D W -40
+KPX D Y -70
+KPX D comma -30
+KPX D period -30
+KPX F A -80
+KPX F a -20
+KPX F comma -100
+KPX F period -100
+KPX J A -20
+KPX J comma -20
+KPX J period -20
+KPX J u -20
+KPX K O -30
+KPX K e -15
+KPX K o -35
+KPX K u -30
+KPX K y -40
+KPX L T -90
+KPX L V -110
+KPX L W -80
+KPX L Y -120
+KPX L quotedblright -140
+KPX L quoteright -140
+KPX L y -30
+KPX O A -50
+KPX O T -40
+KPX O V -50
+KPX O W -50
+KPX O X -50
+KPX O Y -70
+KPX O comma -40
+KPX O period -40
+KPX P A -100
+KPX P a -30
+KPX P comma -120
+KPX P e -30
+KPX P o -40
+KPX P period -120
+KPX Q U -10
+KPX R O -20
+KPX R T -20
+KPX R U -20
+KPX R V -50
+KPX R W -40
+KPX R Y -50
+KPX T A -90
+KPX T O -40
+KPX T a -80
+KPX T colon -40
+KPX T comma -80
+KPX T e -60
+KPX T hyphen -120
+KPX T o -80
+KPX T period -80
+KPX T r -80
+KPX T semicolon -40
+KPX T u -90
+KPX T w -60
+KPX T y -60
+KPX U A -50
+KPX U comma -30
+KPX U period -30
+KPX V A -80
+KPX V G -50
+KPX V O -50
+KPX V a -60
+KPX V colon -40
+KPX V comma -120
+KPX V e -50
+KPX V hyphen -80
+KPX V o -90
+KPX V period -120
+KPX V semicolon -40
+KPX V u -60
+KPX W A -60
+KPX W O -20
+KPX W a -40
+KPX W colon -10
+KPX W comma -80
+KPX W e -35
+KPX W hyphen -40
+KPX W o -60
+KPX W period -80
+KPX W semicolon -10
+KPX W u -45
+KPX W y -20
+KPX Y A -110
+KPX Y O -70
+KPX Y a -90
+KPX Y colon -50
+KPX Y comma -100
+KPX Y e -80
+KPX Y o -100
+KPX Y period -100
+KPX Y semicolon -50
+KPX Y u -100
+KPX a g -10
+KPX a v -15
+KPX a w -15
+KPX a y -20
+KPX b b -10
+KPX b l -10
+KPX b u -20
+KPX b v -20
+KPX b y -20
+KPX c h -10
+KPX c k -20
+KPX c l -20
+KPX c y -10
+KPX colon space -40
+KPX comma quotedblright -120
+KPX comma quoteright -120
+KPX comma space -40
+KPX d d -10
+KPX d v -15
+KPX d w -15
+KPX d y -15
+KPX e comma 10
+KPX e period 20
+KPX e v -15
+KPX e w -15
+KPX e x -15
+KPX e y -15
+KPX f comma -10
+KPX f e -10
+KPX f o -20
+KPX f period -10
+KPX f quotedblright 30
+KPX f quoteright 30
+KPX g e 10
+KPX g g -10
+KPX h y -20
+KPX k o -15
+KPX l w -15
+KPX l y -15
+KPX m u -20
+KPX m y -30
+KPX n u -10
+KPX n v -40
+KPX n y -20
+KPX o v -20
+KPX o w -15
+KPX o x -30
+KPX o y -20
+KPX p y -15
+KPX period quotedblright -120
+KPX period quoteright -120
+KPX period space -40
+KPX quotedblright space -80
+KPX quoteleft quoteleft -46
+KPX quoteright d -80
+KPX quoteright l -20
+KPX quoteright quoteright -46
+KPX quoteright r -40
+KPX quoteright s -60
+KPX quoteright space -80
+KPX quoteright v -20
+KPX r c -20
+KPX r comma -60
+KPX r d -20
+KPX r g -15
+KPX r hyphen -20
+KPX r o -20
+KPX r period -60
+KPX r q -20
+KPX r s -15
+KPX r t 20
+KPX r v 10
+KPX r y 10
+KPX s w -15
+KPX semicolon space -40
+KPX space T -100
+KPX space V -80
+KPX space W -80
+KPX space Y -120
+KPX space quotedblleft -80
+KPX space quoteleft -60
+KPX v a -20
+KPX v comma -80
+KPX v o -30
+KPX v period -80
+KPX w comma -40
+KPX w o -20
+KPX w period -40
+KPX x e -10
+KPX y a -30
+KPX y comma -80
+KPX y e -10
+KPX y o -25
+KPX y period -80
+KPX z e 10
+`
+
+// timesRomanKerning are the kerning pairs of Times-Roman.
+const timesRomanKerning = `
+KPX A C -40
+KPX A G -40
+KPX A O -55
+KPX A Q -55
+KPX A T -111
+KPX A U -55
+KPX A V -135
+KPX A W -90
+KPX A Y -105
+KPX A quoteright -111
+KPX A v -74
+KPX A w -92
+KPX A y -92
+KPX B A -35
+KPX B U -10
+KPX D A -40
+KPX D V -40
+KPX D W -30
+KPX D Y -55
+KPX F A -74
+KPX F a -15
+KPX F comma -80
+KPX F o -15
+KPX F period -80
+KPX J A -60
+KPX K O -30
+KPX K e -25
+KPX K o -35
+KPX K u -15
+KPX K y -25
+KPX L T -92
+KPX L V -100
+KPX L W -74
+KPX L Y -100
+KPX L quoteright -92
+KPX L y -55
+KPX N A -35
+KPX O A -35
+KPX O T -40
+KPX O V -50
+KPX O W -35
+KPX O X -40
+KPX O Y -50
+KPX P A -92
+KPX P a -15
+KPX P comma -111
+KPX P period -111
+KPX Q U -10
+KPX R O -40
+KPX R T -60
+KPX R U -40
+KPX R V -80
+KPX R W -55
+KPX R Y -65
+KPX T A -93
+KPX T O -18
+KPX T a -80
+KPX T colon -50
+KPX T comma -74
+KPX T e -70
+KPX T hyphen -92
+KPX T i -35
+KPX T o -80
+KPX T period -74
+KPX T r -35
+KPX T semicolon -55
+KPX T u -45
+KPX T w -80
+KPX T y -80
+KPX U A -40
+KPX V A -135
+KPX V G -15
+KPX V O -40
+KPX V a -111
+KPX V colon -74
+KPX V comma -129
+KPX V e -111
+KPX V hyphen -100
+KPX V i -60
+KPX V o -129
+KPX V period -129
+KPX V semicolon -74
+KPX V u -75
+KPX W A -120
+KPX W O -10
+KPX W a -80
+KPX W colon -37
+KPX W comma -92
+KPX W e -80
+KPX W hyphen -65
+KPX W i -40
+KPX W o -80
+KPX W period -92
+KPX W semicolon -37
+KPX W u -50
+KPX W y -73
+KPX Y A -120
+KPX Y O -30
+KPX Y a -100
+KPX Y colon -92
+KPX Y comma -129
+KPX Y e -100
+KPX Y hyphen -111
+KPX Y i -55
+KPX Y o -110
+KPX Y period -129
+KPX Y semicolon -92
+KPX Y u -111
+KPX a v -20
+KPX a w -15
+KPX b period -40
+KPX b u -20
+KPX b v -15
+KPX c y -15
+KPX comma quotedblright -70
+KPX comma quoteright -70
+KPX e g -15
+KPX e v -25
+KPX e w -25
+KPX e x -15
+KPX e y -15
+KPX f a -10
+KPX f f -25
+KPX f i -20
+KPX f quoteright 55
+KPX g a -5
+KPX h y -5
+KPX i v -25
+KPX k e -10
+KPX k o -10
+KPX k y -15
+KPX l w -10
+KPX n v -40
+KPX n y -15
+KPX o v -15
+KPX o w -25
+KPX o y -10
+KPX p y -10
+KPX period quotedblright -70
+KPX period quoteright -70
+KPX quotedblleft A -80
+KPX quoteleft A -80
+KPX quoteleft quoteleft -74
+KPX quoteright d -50
+KPX quoteright l -10
+KPX quoteright quoteright -74
+KPX quoteright r -50
+KPX quoteright s -55
+KPX quoteright space -74
+KPX quoteright t -18
+KPX quoteright v -50
+KPX r comma -40
+KPX r g -18
+KPX r hyphen -20
+KPX r period -55
+KPX space A -55
+KPX space T -18
+KPX space V -50
+KPX space W -30
+KPX space Y -90
+KPX v a -25
+KPX v comma -65
+KPX v e -15
+KPX v o -20
+KPX v period -65
+KPX w a -10
+KPX w comma -65
+KPX w o -10
+KPX w period -65
+KPX x e -15
+KPX y comma -65
+KPX y period -65
+`
+
+// timesBoldKerning are the kerning pairs of Times-Bold.
+const timesBoldKerning = `
+KPX A C -55
+KPX A G -55
+KPX A O -45
+KPX A Q -45
+KPX A T -95
+KPX A U -50
+KPX A V -145
+KPX A W -130
+KPX A Y -100
+KPX A p -25
+KPX A quoteright -74
+KPX A u -50
+KPX A v -100
+KPX A w -90
+KPX A y -74
+KPX B A -30
+KPX B U -10
+KPX D A -35
+KPX D V -40
+KPX D W -40
+KPX D Y -40
+KPX D period -20
+KPX F A -90
+KPX F a -25
+KPX F comma -92
+KPX F e -25
+KPX F o -25
+KPX F period -110
+KPX J A -30
+KPX J a -15
+KPX J e -15
+KPX J o -15
+KPX J period -20
+KPX J u -15
+KPX K O -30
+KPX K e -25
+KPX K o -25
+KPX K u -15
+KPX K y -45
+KPX L T -92
+KPX L V -92
+KPX L W -92
+KPX L Y -92
+KPX L quotedblright -20
+KPX L quoteright -110
+KPX L y -55
+KPX N A -20
+KPX O A -40
+KPX O T -40
+KPX O V -50
+KPX O W -50
+KPX O X -40
+KPX O Y -50
+KPX P A -74
+KPX P a -10
+KPX P comma -92
+KPX P e -20
+KPX P o -20
+KPX P period -110
+KPX Q U -10
+KPX Q period -20
+KPX R O -30
+KPX R T -40
+KPX R U -30
+KPX R V -55
+KPX R W -35
+KPX R Y -35
+KPX T A -90
+KPX T O -18
+KPX T a -92
+KPX T colon -74
+KPX T comma -74
+KPX T e -92
+KPX T hyphen -92
+KPX T i -18
+KPX T o -92
+KPX T period -90
+KPX T r -74
+KPX T semicolon -74
+KPX T u -92
+KPX T w -74
+KPX T y -34
+KPX U A -60
+KPX U comma -50
+KPX U period -50
+KPX V A -135
+KPX V G -30
+KPX V O -45
+KPX V a -92
+KPX V colon -92
+KPX V comma -129
+KPX V e -100
+KPX V hyphen -74
+KPX V i -37
+KPX V o -100
+KPX V period -145
+KPX V semicolon -92
+KPX V u -92
+KPX W A -120
+KPX W O -10
+KPX W a -65
+KPX W colon -55
+KPX W comma -92
+KPX W e -65
+KPX W hyphen -37
+KPX W i -18
+KPX W o -75
+KPX W period -92
+KPX W semicolon -55
+KPX W u -50
+KPX W y -60
+KPX Y A -110
+KPX Y O -35
+KPX Y a -85
+KPX Y colon -92
+KPX Y comma -92
+KPX Y e -111
+KPX Y hyphen -92
+KPX Y i -37
+KPX Y o -111
+KPX Y period -92
+KPX Y semicolon -92
+KPX Y u -92
+KPX a v -25
+KPX b b -10
+KPX b period -40
+KPX b u -20
+KPX b v -15
+KPX comma quotedblright -45
+KPX comma quoteright -55
+KPX d w -15
+KPX e v -15
+KPX f comma -15
+KPX f i -25
+KPX f o -25
+KPX f period -15
+KPX f quotedblright 50
+KPX f quoteright 55
+KPX g period -15
+KPX h y -15
+KPX i v -10
+KPX k e -10
+KPX k o -15
+KPX k y -15
+KPX n v -40
+KPX o v -10
+KPX o w -10
+KPX period quotedblright -55
+KPX period quoteright -55
+KPX quotedblleft A -10
+KPX quoteleft A -10
+KPX quoteleft quoteleft -63
+KPX quoteright d -20
+KPX quoteright quoteright -63
+KPX quoteright r -20
+KPX quoteright s -37
+KPX quoteright space -74
+KPX quoteright v -20
+KPX r c -18
+KPX r comma -92
+KPX r e -18
+KPX r g -10
+KPX r hyphen -37
+KPX r n -15
+KPX r o -18
+KPX r p -10
+KPX r period -100
+KPX r q -18
+KPX r v -10
+KPX space A -55
+KPX space T -30
+KPX space V -45
+KPX space W -30
+KPX space Y -55
+KPX v a -10
+KPX v comma -55
+KPX v e -10
+KPX v o -10
+KPX v period -70
+KPX w comma -55
+KPX w o -10
+KPX w period -70
+KPX y comma -55
+KPX y e -10
+KPX y o -25
+KPX y period -70
+`
+
+// timesItalicKerning are the kerning pairs of Times-Italic.
+const timesItalicKerning = `
+KPX A C -30
+KPX A G -35
+KPX A O -40
+KPX A Q -40
+KPX A T -37
+KPX A U -50
+KPX A V -105
+KPX A W -95
+KPX A Y -55
+KPX A quoteright -37
+KPX A u -20
+KPX A v -55
+KPX A w -55
+KPX A y -55
+KPX B A -25
+KPX B U -10
+KPX D A -35
+KPX D V -40
+KPX D W -40
+KPX D Y -40
+KPX F A -115
+KPX F a -75
+KPX F comma -135
+KPX F e -75
+KPX F i -45
+KPX F o -105
+KPX F period -135
+KPX F r -55
+KPX J A -40
+KPX J a -35
+KPX J comma -25
+KPX J e -25
+KPX J o -25
+KPX J period -25
+KPX J u -35
+KPX K O -50
+KPX K e -35
+KPX K o -40
+KPX K u -40
+KPX K y -40
+KPX L T -20
+KPX L V -55
+KPX L W -55
+KPX L Y -20
+KPX L quoteright -37
+KPX L y -30
+KPX N A -27
+KPX O A -55
+KPX O T -40
+KPX O V -50
+KPX O W -50
+KPX O X -40
+KPX O Y -50
+KPX P A -90
+KPX P a -80
+KPX P comma -135
+KPX P e -80
+KPX P o -80
+KPX P period -135
+KPX Q U -10
+KPX R O -40
+KPX R U -40
+KPX R V -18
+KPX R W -18
+KPX R Y -18
+KPX T A -50
+KPX T O -18
+KPX T a -92
+KPX T colon -55
+KPX T comma -74
+KPX T e -92
+KPX T hyphen -74
+KPX T i -55
+KPX T o -92
+KPX T period -74
+KPX T r -55
+KPX T semicolon -65
+KPX T u -55
+KPX T w -74
+KPX T y -74
+KPX U A -40
+KPX U comma -25
+KPX U period -25
+KPX V A -60
+KPX V O -30
+KPX V a -111
+KPX V colon -65
+KPX V comma -129
+KPX V e -111
+KPX V hyphen -55
+KPX V i -74
+KPX V o -111
+KPX V period -129
+KPX V semicolon -74
+KPX V u -74
+KPX W A -60
+KPX W O -25
+KPX W a -92
+KPX W colon -65
+KPX W comma -92
+KPX W e -92
+KPX W hyphen -37
+KPX W i -55
+KPX W o -92
+KPX W period -92
+KPX W semicolon -65
+KPX W u -55
+KPX W y -70
+KPX Y A -50
+KPX Y O -15
+KPX Y a -92
+KPX Y colon -65
+KPX Y comma -92
+KPX Y e -92
+KPX Y hyphen -74
+KPX Y i -74
+KPX Y o -92
+KPX Y period -92
+KPX Y semicolon -65
+KPX Y u -92
+KPX a g -10
+KPX b period -40
+KPX b u -20
+KPX c h -15
+KPX c k -20
+KPX comma quotedblright -140
+KPX comma quoteright -140
+KPX e b -10
+KPX e comma -10
+KPX e g -40
+KPX e period -15
+KPX e v -15
+KPX e w -15
+KPX e x -20
+KPX e y -30
+KPX f comma -10
+KPX f f -18
+KPX f i -20
+KPX f period -15
+KPX f quoteright 92
+KPX g comma -10
+KPX g e -10
+KPX g g -10
+KPX g period -15
+KPX k e -10
+KPX k o -10
+KPX k y -10
+KPX n v -40
+KPX o g -10
+KPX o v -10
+KPX period quotedblright -140
+KPX period quoteright -140
+KPX quoteleft quoteleft -111
+KPX quoteright d -25
+KPX quoteright quoteright -111
+KPX quoteright r -25
+KPX quoteright s -40
+KPX quoteright space -111
+KPX quoteright t -30
+KPX quoteright v -10
+KPX r a -15
+KPX r c -37
+KPX r comma -111
+KPX r d -37
+KPX r e -37
+KPX r g -37
+KPX r hyphen -20
+KPX r o -45
+KPX r period -111
+KPX r q -37
+KPX r s -10
+KPX space A -18
+KPX space T -18
+KPX space V -35
+KPX space W -40
+KPX space Y -75
+KPX v comma -74
+KPX v period -74
+KPX w comma -74
+KPX w period -74
+KPX y comma -55
+KPX y period -55
+`
+
+// timesBoldItalicKerning are the kerning pairs of Times-BoldItalic.
+const timesBoldItalicKerning = `
+KPX A C -65
+KPX A G -60
+KPX A O -50
+KPX A Q -55
+KPX A T -55
+KPX A U -50
+KPX A V -95
+KPX A W -100
+KPX A Y -70
+KPX A quoteright -74
+KPX A u -30
+KPX A v -74
+KPX A w -74
+KPX A y -74
+KPX B A -25
+KPX B U -10
+KPX D A -25
+KPX D V -50
+KPX D W -40
+KPX D Y -50
+KPX F A -100
+KPX F a -95
+KPX F comma -129
+KPX F e -100
+KPX F i -40
+KPX F o -70
+KPX F period -129
+KPX F r -50
+KPX J A -25
+KPX J a -40
+KPX J comma -10
+KPX J e -40
+KPX J o -40
+KPX J period -10
+KPX J u -40
+KPX K O -30
+KPX K e -25
+KPX K o -25
+KPX K u -20
+KPX K y -20
+KPX L T -18
+KPX L V -37
+KPX L W -37
+KPX L Y -37
+KPX L quoteright -55
+KPX L y -37
+KPX N A -30
+KPX O A -40
+KPX O T -40
+KPX O V -50
+KPX O W -50
+KPX O X -40
+KPX O Y -50
+KPX P A -85
+KPX P a -40
+KPX P comma -129
+KPX P e -50
+KPX P o -55
+KPX P period -129
+KPX Q U -10
+KPX R O -40
+KPX R T -30
+KPX R U -40
+KPX R V -18
+KPX R W -18
+KPX R Y -18
+KPX T A -55
+KPX T O -18
+KPX T a -92
+KPX T colon -74
+KPX T comma -92
+KPX T e -92
+KPX T hyphen -92
+KPX T i -37
+KPX T o -95
+KPX T period -92
+KPX T r -37
+KPX T semicolon -74
+KPX T u -37
+KPX T w -37
+KPX T y -37
+KPX U A -45
+KPX V A -85
+KPX V G -10
+KPX V O -30
+KPX V a -111
+KPX V colon -74
+KPX V comma -129
+KPX V e -111
+KPX V hyphen -70
+KPX V i -55
+KPX V o -111
+KPX V period -129
+KPX V semicolon -74
+KPX V u -55
+KPX W A -74
+KPX W O -15
+KPX W a -85
+KPX W colon -55
+KPX W comma -74
+KPX W e -90
+KPX W hyphen -50
+KPX W i -37
+KPX W o -80
+KPX W period -74
+KPX W semicolon -55
+KPX W u -55
+KPX W y -55
+KPX Y A -74
+KPX Y O -25
+KPX Y a -92
+KPX Y colon -92
+KPX Y comma -92
+KPX Y e -111
+KPX Y hyphen -92
+KPX Y i -55
+KPX Y o -111
+KPX Y period -74
+KPX Y semicolon -92
+KPX Y u -92
+KPX b b -10
+KPX b period -40
+KPX b u -20
+KPX c h -10
+KPX c k -10
+KPX comma quotedblright -95
+KPX comma quoteright -95
+KPX e b -10
+KPX f comma -10
+KPX f e -10
+KPX f f -18
+KPX f o -10
+KPX f period -10
+KPX f quoteright 55
+KPX k e -30
+KPX k o -10
+KPX n v -40
+KPX o v -15
+KPX o w -25
+KPX o x -10
+KPX o y -10
+KPX period quotedblright -95
+KPX period quoteright -95
+KPX quoteleft quoteleft -74
+KPX quoteright d -15
+KPX quoteright quoteright -74
+KPX quoteright r -15
+KPX quoteright s -74
+KPX quoteright space -74
+KPX quoteright t -37
+KPX quoteright v -15
+KPX r comma -65
+KPX r period -65
+KPX space A -37
+KPX space V -70
+KPX space W -70
+KPX space Y -70
+KPX v comma -37
+KPX v e -15
+KPX v o -15
+KPX v period -37
+KPX w a -10
+KPX w comma -37
+KPX w e -10
+KPX w o -15
+KPX w period -37
+KPX x e -10
+KPX y comma -37
+KPX y period -37
+`
@@ -26,6 +26,8 @@ type SubsetFontObj struct {
 	funcKernOverride      FuncKernOverride
 	funcGetRoot           func() *GoPdf
 	addCharsBuff          []rune
+	standard              *standardFont // metrics of a standard 14 font, which has no TTF
+	vertical              *verticalFont // Identity-V font for vertical writing, once used
 }
 
 func (s *SubsetFontObj) init(funcGetRoot func() *GoPdf) {
@@ -36,6 +38,10 @@ func (s *SubsetFontObj) init(funcGetRoot func() *GoPdf) {
 }
 
 func (s *SubsetFontObj) write(w io.Writer, objID int) error {
+	if s.standard != nil {
+		s.standard.write(w)
+		return nil
+	}
 	//me.AddChars("จ")
 	io.WriteString(w, "<<\n")
 	fmt.Fprintf(w, "/BaseFont /%s\n", CreateEmbeddedFontSubsetName(s.Family))
@@ -88,6 +94,13 @@ func (s *SubsetFontObj) KernValueByLeft(left uint) (bool, *core.KernValue) {
 		return false, nil
 	}
 
+	if s.standard != nil {
+		if kval, ok := s.standard.kerning[left]; ok {
+			return true, &kval
+		}
+		return false, nil
+	}
+
 	k := s.ttfp.Kern()
 	if k == nil {
 		return false, nil
@@ -100,10 +113,16 @@ func (s *SubsetFontObj) KernValueByLeft(left uint) (bool, *core.KernValue) {
 	return false, nil
 }
 
+// setupTTFParser passes the options of the font to the parser.
+func (s *SubsetFontObj) setupTTFParser() {
+	s.ttfp.SetUseKerning(s.ttfFontOption.UseKerning)
+	s.ttfp.SetCollectionFace(s.ttfFontOption.CollectionIndex, s.ttfFontOption.CollectionFontName)
+	s.ttfp.SetVariation(s.ttfFontOption.Variations)
+}
+
 // SetTTFByPath set ttf
 func (s *SubsetFontObj) SetTTFByPath(ttfpath string) error {
-	useKerning := s.ttfFontOption.UseKerning
-	s.ttfp.SetUseKerning(useKerning)
+	s.setupTTFParser()
 	err := s.ttfp.Parse(ttfpath)
 	if err != nil {
 		return err
@@ -113,8 +132,7 @@ func (s *SubsetFontObj) SetTTFByPath(ttfpath string) error {
 
 // SetTTFByReader set ttf
 func (s *SubsetFontObj) SetTTFByReader(rd io.Reader) error {
-	useKerning := s.ttfFontOption.UseKerning
-	s.ttfp.SetUseKerning(useKerning)
+	s.setupTTFParser()
 	err := s.ttfp.ParseByReader(rd)
 	if err != nil {
 		return err
@@ -124,8 +142,7 @@ func (s *SubsetFontObj) SetTTFByReader(rd io.Reader) error {
 
 // SetTTFData set ttf
 func (s *SubsetFontObj) SetTTFData(data []byte) error {
-	useKerning := s.ttfFontOption.UseKerning
-	s.ttfp.SetUseKerning(useKerning)
+	s.setupTTFParser()
 	err := s.ttfp.ParseFontData(data)
 	if err != nil {
 		return err
@@ -142,7 +159,9 @@ func (s *SubsetFontObj) AddChars(txt string) (string, error) {
 			continue
 		}
 		glyphIndex, err := s.CharCodeToGlyphIndex(runeValue)
-		if err == ErrGlyphNotFound {
+		if err == ErrGlyphNotFound && s.standard != nil {
+			return "", fmt.Errorf("%w: %q (U+%04X) in %s", ErrCharNotEncodable, runeValue, runeValue, s.standard.name)
+		} else if err == ErrGlyphNotFound {
 			//never return error on this, just call function OnGlyphNotFound
 			if s.ttfFontOption.OnGlyphNotFound != nil {
 				s.ttfFontOption.OnGlyphNotFound(runeValue)
@@ -173,7 +192,9 @@ func (s *SubsetFontObj) AddChars(txt string) error {
 			continue
 		}
 		glyphIndex, err := s.CharCodeToGlyphIndex(runeValue)
-		if err == ErrGlyphNotFound {
+		if err == ErrGlyphNotFound && s.standard != nil {
+			return "", fmt.Errorf("%w: %q (U+%04X) in %s", ErrCharNotEncodable, runeValue, runeValue, s.standard.name)
+		} else if err == ErrGlyphNotFound {
 			//never return error on this, just call function OnGlyphNotFound
 			if s.ttfFontOption.OnGlyphNotFound != nil {
 				s.ttfFontOption.OnGlyphNotFound(runeValue)
@@ -282,6 +303,13 @@ func (s *SubsetFontObj) charCodeToGlyphIndexFormat4(r rune) (uint, error) {
 
 // CharCodeToGlyphIndex gets glyph index from char code.
 func (s *SubsetFontObj) CharCodeToGlyphIndex(r rune) (uint, error) {
+	if s.standard != nil {
+		code, ok := s.standard.encode(r)
+		if !ok {
+			return 0, ErrGlyphNotFound
+		}
+		return uint(code), nil
+	}
 	value := uint64(r)
 	if value <= 0xFFFF {
 		gIndex, err := s.charCodeToGlyphIndexFormat4(r)
@@ -299,14 +327,17 @@ func (s *SubsetFontObj) CharCodeToGlyphIndex(r rune) (uint, error) {
 
 // GlyphIndexToPdfWidth gets width from glyphIndex.
 func (s *SubsetFontObj) GlyphIndexToPdfWidth(glyphIndex uint) uint {
+	if s.standard != nil {
+		return uint(s.standard.widths[glyphIndex&0xFF])
+	}
 
-	numberOfHMetrics := s.ttfp.NumberOfHMetrics()
+	widths := s.ttfp.Widths() // padded to the number of glyphs
 	unitsPerEm := s.ttfp.UnitsPerEm()
-	if glyphIndex >= numberOfHMetrics {
-		glyphIndex = numberOfHMetrics - 1
+	if glyphIndex >= uint(len(widths)) {
+		glyphIndex = uint(len(widths)) - 1
 	}
 
-	width := s.ttfp.Widths()[glyphIndex]
+	width := widths[glyphIndex]
 	if unitsPerEm == 1000 {
 		return width
 	}
@@ -320,34 +351,123 @@ func (s *SubsetFontObj) GetTTFParser() *core.TTFParser {
 
 // GetUnderlineThickness underlineThickness.
 func (s *SubsetFontObj) GetUnderlineThickness() int {
+	if s.standard != nil {
+		return s.standard.underlineThickness
+	}
 	return s.ttfp.UnderlineThickness()
 }
 
 func (s *SubsetFontObj) GetUnderlineThicknessPx(fontSize float64) float64 {
-	return (float64(s.ttfp.UnderlineThickness()) / float64(s.ttfp.UnitsPerEm())) * fontSize
+	return (float64(s.GetUnderlineThickness()) / float64(s.unitsPerEm())) * fontSize
 }
 
 // GetUnderlinePosition underline position.
 func (s *SubsetFontObj) GetUnderlinePosition() int {
+	if s.standard != nil {
+		return s.standard.underlinePosition
+	}
 	return s.ttfp.UnderlinePosition()
 }
 
 func (s *SubsetFontObj) GetUnderlinePositionPx(fontSize float64) float64 {
-	return (float64(s.ttfp.UnderlinePosition()) / float64(s.ttfp.UnitsPerEm())) * fontSize
+	return (float64(s.GetUnderlinePosition()) / float64(s.unitsPerEm())) * fontSize
+}
+
+// GetStrikeoutThickness strikeout thickness, from the OS/2 table or else
+// the underline thickness.
+func (s *SubsetFontObj) GetStrikeoutThickness() int {
+	if s.standard == nil && s.ttfp.StrikeoutSize() != 0 {
+		return s.ttfp.StrikeoutSize()
+	}
+	return s.GetUnderlineThickness()
+}
+
+func (s *SubsetFontObj) GetStrikeoutThicknessPx(fontSize float64) float64 {
+	return (float64(s.GetStrikeoutThickness()) / float64(s.unitsPerEm())) * fontSize
+}
+
+// GetStrikeoutPosition position of the top of the strikeout above the
+// baseline, from the OS/2 table or else centered at half the x-height.
+func (s *SubsetFontObj) GetStrikeoutPosition() int {
+	if s.standard != nil {
+		return s.standard.ascent/3 + s.GetStrikeoutThickness()/2
+	}
+	if s.ttfp.StrikeoutSize() != 0 {
+		return s.ttfp.StrikeoutPosition()
+	}
+	return s.ttfp.XHeight()/2 + s.GetStrikeoutThickness()/2
+}
+
+func (s *SubsetFontObj) GetStrikeoutPositionPx(fontSize float64) float64 {
+	return (float64(s.GetStrikeoutPosition()) / float64(s.unitsPerEm())) * fontSize
+}
+
+// scriptMetrics returns the font size of superscripts, or of subscripts
+// when sub is set, of text of fontSize and the rise of their baseline, from
+// the OS/2 table or else 0.7 times the size raised by a third of it or
+// lowered by 0.15 of it.
+func (s *SubsetFontObj) scriptMetrics(fontSize float64, sub bool) (size, rise float64) {
+	var em, offset int
+	if s.standard == nil {
+		if sub {
+			em, offset = s.ttfp.SubscriptMetrics()
+			offset = -offset
+		} else {
+			em, offset = s.ttfp.SuperscriptMetrics()
+		}
+	}
+	if em <= 0 {
+		if sub {
+			return fontSize * 0.7, -fontSize * 0.15
+		}
+		return fontSize * 0.7, fontSize / 3
+	}
+	unitsPerEm := float64(s.unitsPerEm())
+	return fontSize * float64(em) / unitsPerEm, fontSize * float64(offset) / unitsPerEm
 }
 
 func (s *SubsetFontObj) GetAscender() int {
+	if s.standard != nil {
+		return s.standard.ascent
+	}
 	return s.ttfp.Ascender()
 }
 
 func (s *SubsetFontObj) GetAscenderPx(fontSize float64) float64 {
-	return (float64(s.ttfp.Ascender()) / float64(s.ttfp.UnitsPerEm())) * fontSize
+	return (float64(s.GetAscender()) / float64(s.unitsPerEm())) * fontSize
 }
 
 func (s *SubsetFontObj) GetDescender() int {
+	if s.standard != nil {
+		return s.standard.descent
+	}
 	return s.ttfp.Descender()
 }
 
 func (s *SubsetFontObj) GetDescenderPx(fontSize float64) float64 {
-	return (float64(s.ttfp.Descender()) / float64(s.ttfp.UnitsPerEm())) * fontSize
+	return (float64(s.GetDescender()) / float64(s.unitsPerEm())) * fontSize
+}
+
+// unitsPerEm returns the units per em of the glyph metrics.
+func (s *SubsetFontObj) unitsPerEm() uint {
+	if s.standard != nil {
+		return 1000
+	}
+	return s.ttfp.UnitsPerEm()
+}
+
+// typoAscender returns the typographic ascender in font units.
+func (s *SubsetFontObj) typoAscender() int {
+	if s.standard != nil {
+		return s.standard.ascent
+	}
+	return s.ttfp.TypoAscender()
+}
+
+// typoDescender returns the typographic descender in font units.
+func (s *SubsetFontObj) typoDescender() int {
+	if s.standard != nil {
+		return s.standard.descent
+	}
+	return s.ttfp.TypoDescender()
 }
@@ -1,12 +1,16 @@
 package gopdf
 
 import (
+	"bytes"
+	"encoding/base64"
 	"encoding/xml"
 	"errors"
 	"fmt"
+	"image"
 	"io"
 	"math"
 	"os"
+	"path/filepath"
 	"strconv"
 	"strings"
 )
@@ -21,6 +25,14 @@ type SVGOption struct {
 	Width float64
 	// Height is the target height. If 0, uses the SVG's native height.
 	Height float64
+	// FontFamily is the fallback font family for <text> elements whose
+	// font-family is not loaded. It must already be added via AddTTFFont.
+	// Text is skipped when neither family is available.
+	FontFamily string
+	// ImageBaseDir is the directory that relative <image> hrefs are read
+	// from. When empty, only data URIs are embedded. Hrefs that are URLs,
+	// absolute paths or that leave the directory are never read.
+	ImageBaseDir string
 }
 
 var (
@@ -33,7 +45,11 @@ var (
 // rectangles, circles, paths) — no rasterization is needed.
 //
 // Supported SVG elements: rect, circle, ellipse, line, polyline,
-// polygon, path (M, L, C, Q, Z commands), text (basic).
+// polygon, path (all commands, including elliptical arcs), text and
+// tspan (rendered with loaded TTF families), image (data URI, or a file
+// in SVGOption.ImageBaseDir), and g groups.
+// clip-path and mask references to <clipPath> and <mask> definitions
+// are applied as PDF clipping paths and luminosity soft masks.
 //
 // Example:
 //
@@ -85,7 +101,27 @@ func (gp *GoPdf) ImageSVGFromBytes(svgData []byte, opt SVGOption) error {
 
 	// Render each SVG element
 	for _, elem := range svg.elements {
-		gp.renderSVGElement(elem, opt.X, opt.Y, scaleX, scaleY)
+		if elem.clipPath == "" && elem.mask == "" {
+			if err := gp.renderSVGElement(elem, opt, scaleX, scaleY); err != nil {
+				return err
+			}
+			continue
+		}
+		gp.SaveGraphicsState()
+		if clip, ok := svg.clipPaths[elem.clipPath]; ok {
+			gp.renderSVGClipPath(clip, opt.X, opt.Y, scaleX, scaleY)
+		}
+		if mask, ok := svg.masks[elem.mask]; ok {
+			if err := gp.renderSVGMask(mask, opt.X, opt.Y, scaleX, scaleY); err != nil {
+				gp.RestoreGraphicsState()
+				return err
+			}
+		}
+		err := gp.renderSVGElement(elem, opt, scaleX, scaleY)
+		gp.RestoreGraphicsState()
+		if err != nil {
+			return err
+		}
 	}
 
 	return nil
@@ -103,10 +139,12 @@ func (gp *GoPdf) ImageSVGFromReader(r io.Reader, opt SVGOption) error {
 // ---- SVG parsing ----
 
 type svgDoc struct {
-	width    float64
-	height   float64
-	viewBox  [4]float64
-	elements []svgElement
+	width     float64
+	height    float64
+	viewBox   [4]float64
+	elements  []svgElement
+	clipPaths map[string][]svgElement // <clipPath> children by id
+	masks     map[string][]svgElement // <mask> children by id
 }
 
 type svgElementType int
@@ -119,24 +157,48 @@ const (
 	svgPolyline
 	svgPolygon
 	svgPath
+	svgText
+	svgImage
 )
 
 type svgElement struct {
-	typ    svgElementType
+	typ svgElementType
 	// Common style
-	fill       [3]uint8
-	hasFill    bool
-	stroke     [3]uint8
-	hasStroke  bool
-	strokeW    float64
-	opacity    float64
+	fill      [3]uint8
+	hasFill   bool
+	stroke    [3]uint8
+	hasStroke bool
+	strokeW   float64
+	opacity   float64
 	// Geometry
-	x, y, w, h    float64 // rect
-	cx, cy, r     float64 // circle
+	x, y, w, h     float64 // rect, image
+	cx, cy, r      float64 // circle
 	rx, ry         float64 // ellipse / rect corner radius
 	x1, y1, x2, y2 float64 // line
 	points         []Point // polyline, polygon
 	pathData       string  // path d attribute
+	// Text
+	fontFamily string
+	fontSize   float64
+	bold       bool
+	italic     bool
+	textAnchor string
+	runs       []svgTextRun
+	// Image
+	href                string
+	preserveAspectRatio string
+	// References to <clipPath> / <mask> definitions
+	clipPath string
+	mask     string
+}
+
+// svgTextRun is a piece of character data inside <text> or <tspan>.
+type svgTextRun struct {
+	text       string
+	x, y       float64
+	hasX, hasY bool
+	dx, dy     float64
+	style      svgElement // fill and font attributes for this run
 }
 
 type xmlSVG struct {
@@ -151,6 +213,15 @@ type xmlElement struct {
 	XMLName xml.Name
 	Attrs   []xml.Attr   `xml:",any,attr"`
 	Content []xmlElement `xml:",any"`
+	Inner   []byte       `xml:",innerxml"`
+}
+
+// svgInheritedAttrs lists the presentation attributes passed from a group
+// to its children. clip-path and mask are not inherited in SVG, but since
+// groups are flattened here they are propagated to every child instead.
+var svgInheritedAttrs = []string{
+	"fill", "stroke", "stroke-width", "font-family", "font-size",
+	"font-weight", "font-style", "text-anchor", "clip-path", "mask",
 }
 
 func parseSVG(data []byte) (*svgDoc, error) {
@@ -160,8 +231,10 @@ func parseSVG(data []byte) (*svgDoc, error) {
 	}
 
 	doc := &svgDoc{
-		width:  parseSVGLength(raw.Width),
-		height: parseSVGLength(raw.Height),
+		width:     parseSVGLength(raw.Width),
+		height:    parseSVGLength(raw.Height),
+		clipPaths: make(map[string][]svgElement),
+		masks:     make(map[string][]svgElement),
 	}
 
 	if raw.ViewBox != "" {
@@ -180,31 +253,67 @@ func parseSVG(data []byte) (*svgDoc, error) {
 		}
 	}
 
-	for _, el := range raw.Elements {
-		if elem, ok := parseSVGElement(el); ok {
-			doc.elements = append(doc.elements, elem)
-		}
-		// Recurse into groups
-		for _, child := range el.Content {
-			if elem, ok := parseSVGElement(child); ok {
-				doc.elements = append(doc.elements, elem)
+	doc.elements = doc.parseSVGNodes(raw.Elements, nil)
+
+	return doc, nil
+}
+
+// parseSVGNodes walks els recursively, flattening groups and registering
+// clipPath and mask definitions. inherited holds the group attributes.
+func (doc *svgDoc) parseSVGNodes(els []xmlElement, inherited map[string]string) []svgElement {
+	var out []svgElement
+	for _, el := range els {
+		attrs := svgAttrs(el, inherited)
+		switch el.XMLName.Local {
+		case "g", "a", "svg", "switch":
+			out = append(out, doc.parseSVGNodes(el.Content, attrs)...)
+		case "defs":
+			doc.parseSVGNodes(el.Content, attrs)
+		case "clipPath":
+			if id := attrs["id"]; id != "" {
+				doc.clipPaths[id] = doc.parseSVGNodes(el.Content, nil)
+			}
+		case "mask":
+			if id := attrs["id"]; id != "" {
+				doc.masks[id] = doc.parseSVGNodes(el.Content, nil)
+			}
+		default:
+			if elem, ok := parseSVGElement(el, attrs); ok {
+				out = append(out, elem)
 			}
 		}
 	}
-
-	return doc, nil
+	return out
 }
 
-func parseSVGElement(el xmlElement) (svgElement, bool) {
+// svgAttrs merges inherited attributes, the element's own attributes and
+// its style declarations (in increasing priority) into a single map.
+func svgAttrs(el xmlElement, inherited map[string]string) map[string]string {
 	attrs := make(map[string]string)
+	for _, k := range svgInheritedAttrs {
+		if v, ok := inherited[k]; ok {
+			attrs[k] = v
+		}
+	}
 	for _, a := range el.Attrs {
 		attrs[a.Name.Local] = a.Value
 	}
+	if style, ok := attrs["style"]; ok {
+		for k, v := range parseInlineStyle(style) {
+			attrs[k] = v
+		}
+		delete(attrs, "style")
+	}
+	return attrs
+}
 
+func parseSVGElement(el xmlElement, attrs map[string]string) (svgElement, bool) {
 	var elem svgElement
 	elem.opacity = 1.0
 	elem.strokeW = 1.0
 	parseSVGStyle(&elem, attrs)
+	elem.clipPath = parseSVGURLRef(attrs["clip-path"])
+	elem.mask = parseSVGURLRef(attrs["mask"])
 
 	switch el.XMLName.Local {
 	case "rect":
@@ -248,10 +357,176 @@ func parseSVGElement(el xmlElement) (svgElement, bool) {
 		elem.typ = svgPath
 		elem.pathData = attrs["d"]
 		return elem, elem.pathData != ""
+	case "text":
+		elem.typ = svgText
+		elem.runs = parseSVGTextRuns(el.Inner, elem, attrs)
+		return elem, len(elem.runs) > 0
+	case "image":
+		elem.typ = svgImage
+		elem.x = atof(attrs["x"])
+		elem.y = atof(attrs["y"])
+		elem.w = parseSVGLength(attrs["width"])
+		elem.h = parseSVGLength(attrs["height"])
+		elem.href = attrs["href"] // both href and xlink:href
+		elem.preserveAspectRatio = attrs["preserveAspectRatio"]
+		return elem, elem.href != ""
 	}
 	return elem, false
 }
 
+// parseSVGURLRef extracts the id from a "url(#id)" reference.
+func parseSVGURLRef(s string) string {
+	s = strings.TrimSpace(s)
+	if !strings.HasPrefix(s, "url(") || !strings.HasSuffix(s, ")") {
+		return ""
+	}
+	s = strings.Trim(s[4:len(s)-1], "'\" ")
+	return strings.TrimPrefix(s, "#")
+}
+
+// parseSVGTextFont reads the font and anchor attributes of a text element.
+func parseSVGTextFont(elem *svgElement, attrs map[string]string) {
+	if v, ok := attrs["font-family"]; ok {
+		elem.fontFamily = v
+	}
+	if v, ok := attrs["font-size"]; ok {
+		elem.fontSize = parseSVGLength(v)
+	}
+	if v, ok := attrs["font-weight"]; ok {
+		elem.bold = v == "bold" || v == "bolder" || atof(v) >= 600
+	}
+	if v, ok := attrs["font-style"]; ok {
+		elem.italic = v == "italic" || v == "oblique"
+	}
+	if v, ok := attrs["text-anchor"]; ok {
+		elem.textAnchor = v
+	}
+}
+
+// parseSVGTextRuns splits the inner XML of a <text> element into runs of
+// character data, each carrying the position and style of its <tspan>.
+func parseSVGTextRuns(inner []byte, parent svgElement, attrs map[string]string) []svgTextRun {
+	parent.fontSize = 16 // SVG default (medium)
+	parseSVGTextFont(&parent, attrs)
+	if _, ok := attrs["fill"]; !ok {
+		parent.hasFill = true // text is filled black by default
+	}
+
+	first := svgTextRun{style: parent}
+	first.x, first.hasX = svgFirstCoord(attrs["x"])
+	first.y, first.hasY = svgFirstCoord(attrs["y"])
+	first.dx, _ = svgFirstCoord(attrs["dx"])
+	first.dy, _ = svgFirstCoord(attrs["dy"])
+
+	var runs []svgTextRun
+	stack := []svgTextRun{first}
+	pending := &stack[0] // positioning not yet consumed by a run
+	dec := xml.NewDecoder(strings.NewReader(string(inner)))
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			break
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			top := stack[len(stack)-1]
+			tspanAttrs := make(map[string]string)
+			for _, a := range t.Attr {
+				tspanAttrs[a.Name.Local] = a.Value
+			}
+			if style, ok := tspanAttrs["style"]; ok {
+				for k, v := range parseInlineStyle(style) {
+					tspanAttrs[k] = v
+				}
+			}
+			run := svgTextRun{style: top.style}
+			parseSVGStyle(&run.style, tspanAttrs)
+			parseSVGTextFont(&run.style, tspanAttrs)
+			run.x, run.hasX = svgFirstCoord(tspanAttrs["x"])
+			run.y, run.hasY = svgFirstCoord(tspanAttrs["y"])
+			run.dx, _ = svgFirstCoord(tspanAttrs["dx"])
+			run.dy, _ = svgFirstCoord(tspanAttrs["dy"])
+			if pending != nil { // unconsumed positioning of the parent
+				if !run.hasX {
+					run.x, run.hasX = pending.x, pending.hasX
+				}
+				if !run.hasY {
+					run.y, run.hasY = pending.y, pending.hasY
+				}
+				run.dx += pending.dx
+				run.dy += pending.dy
+			}
+			stack = append(stack, run)
+			pending = &stack[len(stack)-1]
+		case xml.EndElement:
+			if len(stack) > 1 {
+				stack = stack[:len(stack)-1]
+			}
+		case xml.CharData:
+			text := collapseSVGWhitespace(string(t))
+			if strings.TrimSpace(text) == "" && len(runs) == 0 {
+				continue
+			}
+			run := stack[len(stack)-1]
+			run.text = text
+			if pending != nil {
+				run.x, run.hasX = pending.x, pending.hasX
+				run.y, run.hasY = pending.y, pending.hasY
+				run.dx, run.dy = pending.dx, pending.dy
+				pending.hasX, pending.hasY = false, false
+				pending.dx, pending.dy = 0, 0
+			} else {
+				run.hasX, run.hasY = false, false
+				run.dx, run.dy = 0, 0
+			}
+			pending = nil
+			runs = append(runs, run)
+		}
+	}
+
+	// trim leading and trailing white space of the whole text
+	if len(runs) > 0 {
+		runs[0].text = strings.TrimLeft(runs[0].text, " ")
+		last := len(runs) - 1
+		runs[last].text = strings.TrimRight(runs[last].text, " ")
+	}
+	out := runs[:0]
+	for _, run := range runs {
+		if run.text != "" {
+			out = append(out, run)
+		}
+	}
+	return out
+}
+
+// svgFirstCoord parses the first value of a coordinate list attribute.
+func svgFirstCoord(s string) (float64, bool) {
+	fields := strings.Fields(strings.ReplaceAll(s, ",", " "))
+	if len(fields) == 0 {
+		return 0, false
+	}
+	return parseSVGLength(fields[0]), true
+}
+
+// collapseSVGWhitespace applies the default xml:space handling: newlines
+// and tabs become spaces and runs of spaces collapse into one.
+func collapseSVGWhitespace(s string) string {
+	var sb strings.Builder
+	inSpace := false
+	for _, ch := range s {
+		if ch == ' ' || ch == '\t' || ch == '\n' || ch == '\r' {
+			if !inSpace {
+				sb.WriteByte(' ')
+			}
+			inSpace = true
+			continue
+		}
+		sb.WriteRune(ch)
+		inSpace = false
+	}
+	return sb.String()
+}
+
 func parseSVGStyle(elem *svgElement, attrs map[string]string) {
 	if v, ok := attrs["fill"]; ok && v != "none" {
 		if c, ok := parseSVGColor(v); ok {
@@ -386,7 +661,15 @@ func atof(s string) float64 {
 
 // ---- SVG rendering to PDF ----
 
-func (gp *GoPdf) renderSVGElement(elem svgElement, offX, offY, scaleX, scaleY float64) {
+func (gp *GoPdf) renderSVGElement(elem svgElement, opt SVGOption, scaleX, scaleY float64) error {
+	offX, offY := opt.X, opt.Y
+	switch elem.typ {
+	case svgText:
+		return gp.renderSVGText(elem, opt, scaleX, scaleY)
+	case svgImage:
+		return gp.renderSVGImage(elem, opt, scaleX, scaleY)
+	}
+
 	// Apply style
 	if elem.hasStroke {
 		gp.SetStrokeColor(elem.stroke[0], elem.stroke[1], elem.stroke[2])
@@ -444,205 +727,553 @@ func (gp *GoPdf) renderSVGElement(elem svgElement, offX, offY, scaleX, scaleY fl
 		}
 
 	case svgPath:
-		gp.renderSVGPath(elem.pathData, offX, offY, scaleX, scaleY, elem)
+		gp.renderSVGPath(elem, offX, offY, scaleX, scaleY)
 	}
+	return nil
 }
 
-func svgPaintStyle(elem svgElement) string {
-	if elem.hasFill && elem.hasStroke {
-		return "FD"
+// renderSVGText draws the runs of a <text> element with the current page
+// font state saved and restored around it.
+func (gp *GoPdf) renderSVGText(elem svgElement, opt SVGOption, scaleX, scaleY float64) error {
+	fontISubset, fontSize, fontStyle := gp.curr.FontISubset, gp.curr.FontSize, gp.curr.FontStyle
+	fontCount, txtColor, txtColorMode := gp.curr.FontFontCount, gp.curr.txtColor, gp.curr.txtColorMode
+	x, y := gp.curr.X, gp.curr.Y
+	defer func() {
+		gp.curr.FontISubset, gp.curr.FontSize, gp.curr.FontStyle = fontISubset, fontSize, fontStyle
+		gp.curr.FontFontCount, gp.curr.txtColor, gp.curr.txtColorMode = fontCount, txtColor, txtColorMode
+		gp.curr.X, gp.curr.Y = x, y
+	}()
+	// Measure every run first so text-anchor can shift each chunk. A new
+	// chunk starts at every run with an absolute x position.
+	widths := make([]float64, len(elem.runs))
+	for i, run := range elem.runs {
+		if !gp.setSVGFont(run.style, opt.FontFamily, scaleY) {
+			return nil
+		}
+		w, err := gp.MeasureTextWidth(run.text)
+		if err != nil {
+			return err
+		}
+		widths[i] = gp.UnitsToPoints(w) / scaleX
 	}
-	if elem.hasFill {
-		return "F"
+
+	penX, penY := 0.0, 0.0
+	shift := 0.0
+	for i, run := range elem.runs {
+		if run.hasX {
+			penX = run.x
+		}
+		if run.hasY {
+			penY = run.y
+		}
+		penX += run.dx
+		penY += run.dy
+
+		if i == 0 || run.hasX {
+			chunk := widths[i]
+			for j := i + 1; j < len(elem.runs) && !elem.runs[j].hasX; j++ {
+				chunk += widths[j] + elem.runs[j].dx
+			}
+			switch elem.runs[i].style.textAnchor {
+			case "middle":
+				shift = -chunk / 2
+			case "end":
+				shift = -chunk
+			default:
+				shift = 0
+			}
+		}
+
+		gp.setSVGFont(run.style, opt.FontFamily, scaleY)
+		c := run.style.fill
+		gp.SetTextColor(c[0], c[1], c[2])
+		gp.curr.X = opt.X + (penX+shift)*scaleX
+		gp.curr.Y = opt.Y + penY*scaleY
+		if run.style.hasFill {
+			if err := gp.Text(run.text); err != nil {
+				return err
+			}
+		}
+		penX += widths[i]
 	}
-	return "D"
+	return nil
 }
 
-func scaleSVGPoints(pts []Point, offX, offY, scaleX, scaleY float64) []Point {
-	out := make([]Point, len(pts))
-	for i, p := range pts {
-		out[i] = Point{X: offX + p.X*scaleX, Y: offY + p.Y*scaleY}
+// setSVGFont selects the first loaded family listed in the font-family of
+// style, falling back to fallback. It reports whether a font was selected.
+func (gp *GoPdf) setSVGFont(style svgElement, fallback string, scaleY float64) bool {
+	fontStyle := Regular
+	if style.bold {
+		fontStyle |= Bold
 	}
-	return out
+	if style.italic {
+		fontStyle |= Italic
+	}
+	size := style.fontSize * scaleY
+	var families []string
+	for _, f := range strings.Split(style.fontFamily, ",") {
+		if f = strings.Trim(strings.TrimSpace(f), "'\""); f != "" {
+			families = append(families, f)
+		}
+	}
+	if fallback != "" {
+		families = append(families, fallback)
+	}
+	for _, family := range families {
+		if gp.SetFontWithStyle(family, fontStyle, size) == nil {
+			return true
+		}
+		if fontStyle != Regular && gp.SetFontWithStyle(family, Regular, size) == nil {
+			return true
+		}
+	}
+	return false
 }
 
-// renderSVGPath renders an SVG path "d" attribute using PDF drawing commands.
-// Supports: M/m, L/l, H/h, V/v, C/c, Q/q, Z/z commands.
-func (gp *GoPdf) renderSVGPath(d string, offX, offY, scaleX, scaleY float64, elem svgElement) {
-	cmds := tokenizeSVGPath(d)
-	if len(cmds) == 0 {
-		return
+// renderSVGImage draws an <image> whose href is a base64 data URI or a
+// file in opt.ImageBaseDir. Images that cannot be read or decoded are
+// skipped. The default preserveAspectRatio (xMidYMid meet) is honored;
+// "none" stretches the image to the given box.
+func (gp *GoPdf) renderSVGImage(elem svgElement, opt SVGOption, scaleX, scaleY float64) error {
+	data := svgImageData(elem.href, opt.ImageBaseDir)
+	if data == nil {
+		return nil
+	}
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil || cfg.Width <= 0 || cfg.Height <= 0 {
+		return nil
+	}
+	imgH, err := ImageHolderByBytes(data)
+	if err != nil {
+		return err
 	}
 
-	var curX, curY float64
-	var startX, startY float64
-	i := 0
+	offX, offY := opt.X, opt.Y
+	x := offX + elem.x*scaleX
+	y := offY + elem.y*scaleY
+	w := elem.w * scaleX
+	h := elem.h * scaleY
+	iw, ih := float64(cfg.Width), float64(cfg.Height)
+	switch {
+	case w == 0 && h == 0:
+		w, h = iw*scaleX, ih*scaleY
+	case w == 0:
+		w = h * iw / ih
+	case h == 0:
+		h = w * ih / iw
+	case !strings.HasPrefix(strings.TrimSpace(elem.preserveAspectRatio), "none"):
+		fit := math.Min(w/iw, h/ih)
+		x += (w - iw*fit) / 2
+		y += (h - ih*fit) / 2
+		w, h = iw*fit, ih*fit
+	}
+	if w <= 0 || h <= 0 {
+		return nil
+	}
 
-	for i < len(cmds) {
-		cmd := cmds[i]
+	return gp.imageByHolder(imgH, ImageOptions{X: x, Y: y, Rect: &Rect{W: w, H: h}})
+}
+
+// svgImageData returns the bytes of an <image> href: the payload of a
+// base64 data URI, or a relative file under baseDir when baseDir is set.
+// It returns nil for anything else, and for hrefs that cannot be read.
+func svgImageData(href, baseDir string) []byte {
+	href = strings.TrimSpace(href)
+	if strings.HasPrefix(href, "data:") {
+		comma := strings.Index(href, ",")
+		if comma < 0 || !strings.HasSuffix(href[5:comma], ";base64") {
+			return nil
+		}
+		data, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(href[comma+1:]), ""))
+		if err != nil {
+			return nil
+		}
+		return data
+	}
+	if baseDir == "" || href == "" || strings.Contains(href, ":") || filepath.IsAbs(href) {
+		return nil
+	}
+	rel := filepath.Clean(filepath.FromSlash(href))
+	if rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return nil
+	}
+	data, err := os.ReadFile(filepath.Join(baseDir, rel))
+	if err != nil {
+		return nil
+	}
+	return data
+}
+
+// renderSVGClipPath intersects the clipping path with the union of the
+// shapes of a <clipPath> definition.
+func (gp *GoPdf) renderSVGClipPath(clip []svgElement, offX, offY, scaleX, scaleY float64) {
+	var segs []pathSegment
+	for _, c := range clip {
+		segs = append(segs, svgElementSegments(c, offX, offY, scaleX, scaleY)...)
+	}
+	if len(segs) == 0 {
+		// an empty clip path hides everything
+		segs = []pathSegment{{op: 'M', points: []Point{{X: offX, Y: offY}}}, {op: 'Z'}}
+	}
+	gp.getContent().AppendStreamClipPath(segs)
+}
+
+// renderSVGMask installs a luminosity soft mask built from the shapes of a
+// <mask> definition. Each shape is painted in the gray level matching the
+// luminance of its fill color, so white areas stay fully visible.
+func (gp *GoPdf) renderSVGMask(mask []svgElement, offX, offY, scaleX, scaleY float64) error {
+	pageH := gp.curr.pageSize.H
+	var buf bytes.Buffer
+	for _, m := range mask {
+		if !m.hasFill {
+			continue
+		}
+		segs := svgElementSegments(m, offX, offY, scaleX, scaleY)
+		if len(segs) == 0 {
+			continue
+		}
+		lum := (0.2126*float64(m.fill[0]) + 0.7152*float64(m.fill[1]) + 0.0722*float64(m.fill[2])) / 255
+		fmt.Fprintf(&buf, "%.3f g\n", fixRange10(lum*m.opacity))
+		writePathSegments(&buf, segs, pageH)
+		buf.WriteString("f\n")
+	}
+
+	bbox := [4]float64{0, 0, gp.curr.pageSize.W, pageH}
+	group, err := GetCachedTransparencyXObjectGroup(TransparencyXObjectGroupOptions{
+		BBox:    bbox,
+		Content: buf.Bytes(),
+	}, gp)
+	if err != nil {
+		return err
+	}
+	sMask := GetCachedMask(SMaskOptions{
+		Subtype:                       SMaskLuminositySubtype,
+		TransparencyXObjectGroupIndex: group.Index,
+	}, gp)
+	extGState, err := GetCachedExtGState(ExtGStateOptions{SMaskIndex: &sMask.Index}, gp)
+	if err != nil {
+		return err
+	}
+	gp.getContent().listCache.append(&cacheContentRaw{data: fmt.Sprintf("/GS%d gs\n", extGState.Index+1)})
+	return nil
+}
+
+// svgKappa is the control point distance for approximating a quarter
+// ellipse with a cubic Bézier curve.
+const svgKappa = 0.5522847498
+
+// svgElementSegments converts the outline of a shape element to path
+// segments in page coordinates (points, top-left origin).
+func svgElementSegments(elem svgElement, offX, offY, scaleX, scaleY float64) []pathSegment {
+	pt := func(x, y float64) Point {
+		return Point{X: offX + x*scaleX, Y: offY + y*scaleY}
+	}
+	ellipse := func(cx, cy, rx, ry float64) []pathSegment {
+		kx, ky := rx*svgKappa, ry*svgKappa
+		return []pathSegment{
+			{op: 'M', points: []Point{pt(cx+rx, cy)}},
+			{op: 'C', points: []Point{pt(cx+rx, cy+ky), pt(cx+kx, cy+ry), pt(cx, cy+ry)}},
+			{op: 'C', points: []Point{pt(cx-kx, cy+ry), pt(cx-rx, cy+ky), pt(cx-rx, cy)}},
+			{op: 'C', points: []Point{pt(cx-rx, cy-ky), pt(cx-kx, cy-ry), pt(cx, cy-ry)}},
+			{op: 'C', points: []Point{pt(cx+kx, cy-ry), pt(cx+rx, cy-ky), pt(cx+rx, cy)}},
+			{op: 'Z'},
+		}
+	}
+
+	switch elem.typ {
+	case svgRect:
+		return []pathSegment{
+			{op: 'M', points: []Point{pt(elem.x, elem.y)}},
+			{op: 'L', points: []Point{pt(elem.x+elem.w, elem.y)}},
+			{op: 'L', points: []Point{pt(elem.x+elem.w, elem.y+elem.h)}},
+			{op: 'L', points: []Point{pt(elem.x, elem.y+elem.h)}},
+			{op: 'Z'},
+		}
+	case svgCircle:
+		return ellipse(elem.cx, elem.cy, elem.r, elem.r)
+	case svgEllipse:
+		return ellipse(elem.cx, elem.cy, elem.rx, elem.ry)
+	case svgPolygon, svgPolyline:
+		var segs []pathSegment
+		for i, p := range elem.points {
+			op := byte('L')
+			if i == 0 {
+				op = 'M'
+			}
+			segs = append(segs, pathSegment{op: op, points: []Point{pt(p.X, p.Y)}})
+		}
+		if len(segs) > 0 {
+			segs = append(segs, pathSegment{op: 'Z'})
+		}
+		return segs
+	case svgPath:
+		segs := svgPathSegments(elem.pathData)
+		for i := range segs {
+			for j, p := range segs[i].points {
+				segs[i].points[j] = pt(p.X, p.Y)
+			}
+		}
+		return segs
+	}
+	return nil
+}
+
+// svgPathSegments converts an SVG path "d" attribute to path segments in
+// SVG user space. Quadratic curves are raised to cubic ones and elliptical
+// arcs are approximated by cubic ones.
+func svgPathSegments(d string) []pathSegment {
+	tokens := tokenizeSVGPath(d)
+	var segs []pathSegment
+	var cur, start, lastCtrl Point
+	var lastKind byte // 'C' or 'Q' when lastCtrl holds a reflectable control point
+	var cmd string
+	i := 0
+	num := func() float64 {
+		v := atof(tokens[i])
 		i++
+		return v
+	}
+	has := func(n int) bool {
+		if i+n > len(tokens) {
+			return false
+		}
+		for k := i; k < i+n; k++ {
+			if isSVGCommand(tokens[k]) {
+				return false
+			}
+		}
+		return true
+	}
+	cubic := func(c1, c2, end Point) {
+		segs = append(segs, pathSegment{op: 'C', points: []Point{c1, c2, end}})
+		lastCtrl, lastKind = c2, 'C'
+		cur = end
+	}
+	reflect := func(kind byte) Point {
+		if lastKind != kind {
+			return cur
+		}
+		return Point{X: 2*cur.X - lastCtrl.X, Y: 2*cur.Y - lastCtrl.Y}
+	}
 
-		switch cmd {
+	for i < len(tokens) {
+		if isSVGCommand(tokens[i]) {
+			cmd = tokens[i]
+			i++
+		} else if cmd == "" {
+			i++
+			continue
+		}
+		rel := cmd == strings.ToLower(cmd)
+		base := Point{}
+		if rel {
+			base = cur
+		}
+
+		switch strings.ToUpper(cmd) {
 		case "M":
-			if i+1 < len(cmds) {
-				curX = atof(cmds[i])
-				curY = atof(cmds[i+1])
-				i += 2
-				startX, startY = curX, curY
+			if !has(2) {
+				cmd = ""
+				continue
 			}
-		case "m":
-			if i+1 < len(cmds) {
-				curX += atof(cmds[i])
-				curY += atof(cmds[i+1])
-				i += 2
-				startX, startY = curX, curY
+			cur = Point{X: base.X + num(), Y: base.Y + num()}
+			start = cur
+			segs = append(segs, pathSegment{op: 'M', points: []Point{cur}})
+			// subsequent pairs are implicit lineto commands
+			if rel {
+				cmd = "l"
+			} else {
+				cmd = "L"
 			}
 		case "L":
-			for i+1 < len(cmds) && !isSVGCommand(cmds[i]) {
-				nx := atof(cmds[i])
-				ny := atof(cmds[i+1])
-				gp.Line(
-					offX+curX*scaleX, offY+curY*scaleY,
-					offX+nx*scaleX, offY+ny*scaleY,
-				)
-				curX, curY = nx, ny
-				i += 2
-			}
-		case "l":
-			for i+1 < len(cmds) && !isSVGCommand(cmds[i]) {
-				dx := atof(cmds[i])
-				dy := atof(cmds[i+1])
-				nx, ny := curX+dx, curY+dy
-				gp.Line(
-					offX+curX*scaleX, offY+curY*scaleY,
-					offX+nx*scaleX, offY+ny*scaleY,
-				)
-				curX, curY = nx, ny
-				i += 2
+			if !has(2) {
+				cmd = ""
+				continue
 			}
+			cur = Point{X: base.X + num(), Y: base.Y + num()}
+			segs = append(segs, pathSegment{op: 'L', points: []Point{cur}})
 		case "H":
-			if i < len(cmds) {
-				nx := atof(cmds[i])
-				i++
-				gp.Line(offX+curX*scaleX, offY+curY*scaleY, offX+nx*scaleX, offY+curY*scaleY)
-				curX = nx
-			}
-		case "h":
-			if i < len(cmds) {
-				dx := atof(cmds[i])
-				i++
-				nx := curX + dx
-				gp.Line(offX+curX*scaleX, offY+curY*scaleY, offX+nx*scaleX, offY+curY*scaleY)
-				curX = nx
+			if !has(1) {
+				cmd = ""
+				continue
 			}
+			cur = Point{X: base.X + num(), Y: cur.Y}
+			segs = append(segs, pathSegment{op: 'L', points: []Point{cur}})
 		case "V":
-			if i < len(cmds) {
-				ny := atof(cmds[i])
-				i++
-				gp.Line(offX+curX*scaleX, offY+curY*scaleY, offX+curX*scaleX, offY+ny*scaleY)
-				curY = ny
-			}
-		case "v":
-			if i < len(cmds) {
-				dy := atof(cmds[i])
-				i++
-				ny := curY + dy
-				gp.Line(offX+curX*scaleX, offY+curY*scaleY, offX+curX*scaleX, offY+ny*scaleY)
-				curY = ny
+			if !has(1) {
+				cmd = ""
+				continue
 			}
+			cur = Point{X: cur.X, Y: base.Y + num()}
+			segs = append(segs, pathSegment{op: 'L', points: []Point{cur}})
 		case "C":
-			for i+5 < len(cmds) && !isSVGCommand(cmds[i]) {
-				x1 := atof(cmds[i])
-				y1 := atof(cmds[i+1])
-				x2 := atof(cmds[i+2])
-				y2 := atof(cmds[i+3])
-				x3 := atof(cmds[i+4])
-				y3 := atof(cmds[i+5])
-				gp.Curve(
-					offX+curX*scaleX, offY+curY*scaleY,
-					offX+x1*scaleX, offY+y1*scaleY,
-					offX+x2*scaleX, offY+y2*scaleY,
-					offX+x3*scaleX, offY+y3*scaleY,
-					"D",
-				)
-				curX, curY = x3, y3
-				i += 6
+			if !has(6) {
+				cmd = ""
+				continue
 			}
-		case "c":
-			for i+5 < len(cmds) && !isSVGCommand(cmds[i]) {
-				dx1 := atof(cmds[i])
-				dy1 := atof(cmds[i+1])
-				dx2 := atof(cmds[i+2])
-				dy2 := atof(cmds[i+3])
-				dx3 := atof(cmds[i+4])
-				dy3 := atof(cmds[i+5])
-				gp.Curve(
-					offX+curX*scaleX, offY+curY*scaleY,
-					offX+(curX+dx1)*scaleX, offY+(curY+dy1)*scaleY,
-					offX+(curX+dx2)*scaleX, offY+(curY+dy2)*scaleY,
-					offX+(curX+dx3)*scaleX, offY+(curY+dy3)*scaleY,
-					"D",
-				)
-				curX += dx3
-				curY += dy3
-				i += 6
+			c1 := Point{X: base.X + num(), Y: base.Y + num()}
+			c2 := Point{X: base.X + num(), Y: base.Y + num()}
+			end := Point{X: base.X + num(), Y: base.Y + num()}
+			cubic(c1, c2, end)
+			continue
+		case "S":
+			if !has(4) {
+				cmd = ""
+				continue
 			}
-		case "Q":
-			// Quadratic Bézier — convert to cubic
-			for i+3 < len(cmds) && !isSVGCommand(cmds[i]) {
-				qx := atof(cmds[i])
-				qy := atof(cmds[i+1])
-				ex := atof(cmds[i+2])
-				ey := atof(cmds[i+3])
-				// Convert quadratic to cubic control points
-				c1x := curX + 2.0/3.0*(qx-curX)
-				c1y := curY + 2.0/3.0*(qy-curY)
-				c2x := ex + 2.0/3.0*(qx-ex)
-				c2y := ey + 2.0/3.0*(qy-ey)
-				gp.Curve(
-					offX+curX*scaleX, offY+curY*scaleY,
-					offX+c1x*scaleX, offY+c1y*scaleY,
-					offX+c2x*scaleX, offY+c2y*scaleY,
-					offX+ex*scaleX, offY+ey*scaleY,
-					"D",
-				)
-				curX, curY = ex, ey
-				i += 4
+			c1 := reflect('C')
+			c2 := Point{X: base.X + num(), Y: base.Y + num()}
+			end := Point{X: base.X + num(), Y: base.Y + num()}
+			cubic(c1, c2, end)
+			continue
+		case "Q", "T":
+			n := 4
+			if strings.ToUpper(cmd) == "T" {
+				n = 2
 			}
-		case "q":
-			for i+3 < len(cmds) && !isSVGCommand(cmds[i]) {
-				dqx := atof(cmds[i])
-				dqy := atof(cmds[i+1])
-				dex := atof(cmds[i+2])
-				dey := atof(cmds[i+3])
-				qx := curX + dqx
-				qy := curY + dqy
-				ex := curX + dex
-				ey := curY + dey
-				c1x := curX + 2.0/3.0*(qx-curX)
-				c1y := curY + 2.0/3.0*(qy-curY)
-				c2x := ex + 2.0/3.0*(qx-ex)
-				c2y := ey + 2.0/3.0*(qy-ey)
-				gp.Curve(
-					offX+curX*scaleX, offY+curY*scaleY,
-					offX+c1x*scaleX, offY+c1y*scaleY,
-					offX+c2x*scaleX, offY+c2y*scaleY,
-					offX+ex*scaleX, offY+ey*scaleY,
-					"D",
-				)
-				curX, curY = ex, ey
-				i += 4
+			if !has(n) {
+				cmd = ""
+				continue
 			}
-		case "Z", "z":
-			if curX != startX || curY != startY {
-				gp.Line(
-					offX+curX*scaleX, offY+curY*scaleY,
-					offX+startX*scaleX, offY+startY*scaleY,
-				)
-				curX, curY = startX, startY
+			var q Point
+			if n == 4 {
+				q = Point{X: base.X + num(), Y: base.Y + num()}
+			} else {
+				q = reflect('Q')
 			}
+			end := Point{X: base.X + num(), Y: base.Y + num()}
+			c1 := Point{X: cur.X + 2.0/3.0*(q.X-cur.X), Y: cur.Y + 2.0/3.0*(q.Y-cur.Y)}
+			c2 := Point{X: end.X + 2.0/3.0*(q.X-end.X), Y: end.Y + 2.0/3.0*(q.Y-end.Y)}
+			cubic(c1, c2, end)
+			lastCtrl, lastKind = q, 'Q' // T reflects the quadratic control point
+			continue
+		case "A":
+			if !has(7) {
+				cmd = ""
+				continue
+			}
+			rx, ry, rotation := num(), num(), num()
+			largeArc, sweep := num() != 0, num() != 0
+			end := Point{X: base.X + num(), Y: base.Y + num()}
+			segs = append(segs, svgArcCurves(cur, end, rx, ry, rotation, largeArc, sweep)...)
+			cur = end
+		case "Z":
+			segs = append(segs, pathSegment{op: 'Z'})
+			cur = start
+			cmd = ""
+		default:
+			cmd = ""
 		}
+		lastKind = 0
 	}
+	return segs
+}
+
+// svgArcCurves converts the SVG elliptical arc from "from" to "to" to
+// cubic Bézier segments of at most 90 degrees each, following the
+// endpoint to center parameterization of the SVG implementation notes.
+// Out of range radii are scaled up, and a zero radius gives a line.
+func svgArcCurves(from, to Point, rx, ry, rotation float64, largeArc, sweep bool) []pathSegment {
+	if from == to {
+		return nil
+	}
+	rx, ry = math.Abs(rx), math.Abs(ry)
+	if rx == 0 || ry == 0 {
+		return []pathSegment{{op: 'L', points: []Point{to}}}
+	}
+	sinPhi, cosPhi := math.Sincos(rotation * math.Pi / 180)
+
+	// the start point in the coordinates of the unrotated ellipse centered
+	// between the end points
+	dx, dy := (from.X-to.X)/2, (from.Y-to.Y)/2
+	x1 := cosPhi*dx + sinPhi*dy
+	y1 := -sinPhi*dx + cosPhi*dy
+	if l := x1*x1/(rx*rx) + y1*y1/(ry*ry); l > 1 {
+		rx, ry = rx*math.Sqrt(l), ry*math.Sqrt(l)
+	}
+	num := rx*rx*ry*ry - rx*rx*y1*y1 - ry*ry*x1*x1
+	den := rx*rx*y1*y1 + ry*ry*x1*x1
+	coef := math.Sqrt(math.Max(0, num/den))
+	if largeArc == sweep {
+		coef = -coef
+	}
+	cx1, cy1 := coef*rx*y1/ry, -coef*ry*x1/rx
+	cx := cosPhi*cx1 - sinPhi*cy1 + (from.X+to.X)/2
+	cy := sinPhi*cx1 + cosPhi*cy1 + (from.Y+to.Y)/2
+
+	start := math.Atan2((y1-cy1)/ry, (x1-cx1)/rx)
+	delta := math.Atan2((-y1-cy1)/ry, (-x1-cx1)/rx) - start
+	if sweep && delta < 0 {
+		delta += 2 * math.Pi
+	} else if !sweep && delta > 0 {
+		delta -= 2 * math.Pi
+	}
+
+	// the point and the tangent of the ellipse at angle t
+	point := func(t float64) Point {
+		sin, cos := math.Sincos(t)
+		return Point{X: cx + rx*cos*cosPhi - ry*sin*sinPhi, Y: cy + rx*cos*sinPhi + ry*sin*cosPhi}
+	}
+	tangent := func(t float64) Point {
+		sin, cos := math.Sincos(t)
+		return Point{X: -rx*sin*cosPhi - ry*cos*sinPhi, Y: -rx*sin*sinPhi + ry*cos*cosPhi}
+	}
+
+	n := int(math.Ceil(math.Abs(delta) / (math.Pi / 2)))
+	step := delta / float64(n)
+	k := 4.0 / 3.0 * math.Tan(step/4)
+	segs := make([]pathSegment, 0, n)
+	for i := 0; i < n; i++ {
+		t1, t2 := start+float64(i)*step, start+float64(i+1)*step
+		p1, p2 := point(t1), point(t2)
+		d1, d2 := tangent(t1), tangent(t2)
+		if i == n-1 {
+			p2 = to
+		}
+		segs = append(segs, pathSegment{op: 'C', points: []Point{
+			{X: p1.X + k*d1.X, Y: p1.Y + k*d1.Y},
+			{X: p2.X - k*d2.X, Y: p2.Y - k*d2.Y},
+			p2,
+		}})
+	}
+	return segs
+}
+
+func svgPaintStyle(elem svgElement) string {
+	if elem.hasFill && elem.hasStroke {
+		return "FD"
+	}
+	if elem.hasFill {
+		return "F"
+	}
+	return "D"
+}
+
+func scaleSVGPoints(pts []Point, offX, offY, scaleX, scaleY float64) []Point {
+	out := make([]Point, len(pts))
+	for i, p := range pts {
+		out[i] = Point{X: offX + p.X*scaleX, Y: offY + p.Y*scaleY}
+	}
+	return out
+}
+
+// renderSVGPath paints an SVG path as a single PDF path, so that it can be
+// filled as well as stroked.
+func (gp *GoPdf) renderSVGPath(elem svgElement, offX, offY, scaleX, scaleY float64) {
+	segs := svgElementSegments(elem, offX, offY, scaleX, scaleY)
+	if len(segs) == 0 {
+		return
+	}
+
+	transparency, err := gp.getCachedTransparency(nil)
+	if err != nil {
+		transparency = nil
+	}
+	var opts = polygonOptions{}
+	if transparency != nil {
+		opts.extGStateIndexes = append(opts.extGStateIndexes, transparency.extGStateIndex)
+	}
+	gp.getContent().AppendStreamPath(segs, svgPaintStyle(elem), opts)
 }
 
 // tokenizeSVGPath splits an SVG path "d" attribute into commands and numbers.
@@ -693,6 +1324,3 @@ func isSVGCommandByte(ch byte) bool {
 	}
 	return false
 }
-
-// Ensure math import is used
-var _ = math.Pi
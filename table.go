@@ -315,25 +315,31 @@ func (t *tableLayout) drawCell(
 
 // Draws a border around a rectangular area
 func (t *tableLayout) drawBorder(x1, y1, x2, y2 float64, borderStyle BorderStyle) error {
+	return t.pdf.drawBorder(x1, y1, x2, y2, borderStyle)
+}
+
+// drawBorder draws the enabled sides of borderStyle around a rectangular area
+// given by its upper-left (x1, y1) and lower-right (x2, y2) corners.
+func (gp *GoPdf) drawBorder(x1, y1, x2, y2 float64, borderStyle BorderStyle) error {
 	if borderStyle.Width <= 0 {
 		return nil
 	}
-	t.pdf.SetLineWidth(borderStyle.Width)
-	t.pdf.SetStrokeColor(borderStyle.RGBColor.R, borderStyle.RGBColor.G, borderStyle.RGBColor.B)
+	gp.SetLineWidth(borderStyle.Width)
+	gp.SetStrokeColor(borderStyle.RGBColor.R, borderStyle.RGBColor.G, borderStyle.RGBColor.B)
 	half := borderStyle.Width / 2.0
 
 	// Draw each side of the border if specified
 	if borderStyle.Top {
-		t.pdf.Line(x1-half, y1, x2+half, y1)
+		gp.Line(x1-half, y1, x2+half, y1)
 	}
 	if borderStyle.Bottom {
-		t.pdf.Line(x1-half, y2, x2+half, y2)
+		gp.Line(x1-half, y2, x2+half, y2)
 	}
 	if borderStyle.Left {
-		t.pdf.Line(x1, y1-half, x1, y2+half)
+		gp.Line(x1, y1-half, x1, y2+half)
 	}
 	if borderStyle.Right {
-		t.pdf.Line(x2, y1-half, x2, y2+half)
+		gp.Line(x2, y1-half, x2, y2+half)
 	}
 
 	return nil
@@ -0,0 +1,194 @@
+package gopdf
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// ErrTextHorizontalScale is returned by SetTextHorizontalScale for a scale
+// that is not positive.
+var ErrTextHorizontalScale = errors.New("horizontal text scale must be positive")
+
+// TextRenderMode is how the glyphs of text are painted, the PDF text
+// rendering mode (Tr).
+type TextRenderMode int
+
+const (
+	// TextRenderFill fills the glyphs with the text color (the default).
+	TextRenderFill TextRenderMode = iota
+	// TextRenderStroke strokes the outlines of the glyphs.
+	TextRenderStroke
+	// TextRenderFillStroke fills and then strokes the glyphs.
+	TextRenderFillStroke
+	// TextRenderInvisible neither fills nor strokes the glyphs. The text is
+	// still selectable and searchable, as in the OCR layer of a scan.
+	TextRenderInvisible
+	// TextRenderFillClip fills the glyphs and adds them to the clipping path.
+	TextRenderFillClip
+	// TextRenderStrokeClip strokes the glyphs and adds them to the clipping
+	// path.
+	TextRenderStrokeClip
+	// TextRenderFillStrokeClip fills and strokes the glyphs and adds them to
+	// the clipping path.
+	TextRenderFillStrokeClip
+	// TextRenderClip adds the glyphs to the clipping path.
+	TextRenderClip
+)
+
+// textState is the text state of text besides its font, color and
+// character spacing. The zero value is the PDF default.
+type textState struct {
+	renderMode  TextRenderMode
+	rise        float64               // points
+	scale       float64               // horizontal scaling in percent, 0 for 100
+	wordSpacing float64               // points
+	strokeColor *cacheContentColorRGB // nil for the current stroke color
+	strokeWidth float64               // points, 0 for the current line width
+}
+
+// SetTextRenderMode sets how the glyphs of text are painted: filled,
+// stroked, both, invisible or added to the clipping path.
+//
+// The clipping modes intersect the clipping path with the glyphs of each
+// piece of text, so that the following drawing shows only through the
+// text. Bracket them with SaveGraphicsState and RestoreGraphicsState to end
+// the clipping.
+//
+// Example:
+//
+//	pdf.SetTextRenderMode(gopdf.TextRenderStroke)
+//	pdf.SetTextStrokeColor(200, 0, 0)
+//	pdf.SetTextStrokeWidth(0.5)
+//	pdf.Cell(nil, "Outlined")
+//	pdf.SetTextRenderMode(gopdf.TextRenderFill)
+func (gp *GoPdf) SetTextRenderMode(mode TextRenderMode) {
+	gp.curr.textState.renderMode = mode
+}
+
+// SetTextRise sets the distance to raise the baseline of text, or to lower
+// it when negative, as for superscripts and subscripts. It does not move
+// the current position.
+func (gp *GoPdf) SetTextRise(rise float64) {
+	gp.curr.textState.rise = gp.UnitsToPoints(rise)
+}
+
+// SetTextHorizontalScale sets the horizontal scaling of text in percent of
+// its normal width, 100 by default. The scaling applies to the glyphs and
+// to the character and word spacing, and is measured by MeasureTextWidth.
+func (gp *GoPdf) SetTextHorizontalScale(scale float64) error {
+	if scale <= 0 {
+		return ErrTextHorizontalScale
+	}
+	if scale == 100 {
+		scale = 0
+	}
+	gp.curr.textState.scale = scale
+	return nil
+}
+
+// SetWordSpacing sets the space added after each space character of text.
+// Unlike the PDF word spacing operator (Tw), it applies to all fonts,
+// including the two-byte encoded TrueType fonts.
+func (gp *GoPdf) SetWordSpacing(spacing float64) {
+	gp.curr.textState.wordSpacing = gp.UnitsToPoints(spacing)
+}
+
+// SetTextStrokeColor sets the color of the outlines of text drawn in a
+// stroking render mode, without changing the stroke color of lines and
+// shapes.
+func (gp *GoPdf) SetTextStrokeColor(r, g, b uint8) {
+	gp.curr.textState.strokeColor = &cacheContentColorRGB{colorType: colorTypeStrokeRGB, r: r, g: g, b: b}
+}
+
+// SetTextStrokeWidth sets the width of the outlines of text drawn in a
+// stroking render mode, without changing the line width. 0 uses the line
+// width.
+func (gp *GoPdf) SetTextStrokeWidth(width float64) {
+	gp.curr.textState.strokeWidth = gp.UnitsToPoints(width)
+}
+
+// horizontalScale returns the horizontal scaling as a factor.
+func (t textState) horizontalScale() float64 {
+	if t.scale == 0 {
+		return 1
+	}
+	return t.scale / 100
+}
+
+// width returns the width of text set with t, from its width w in points
+// without word spacing and horizontal scaling.
+func (t textState) width(text string, w float64) float64 {
+	return (w + t.wordSpacing*float64(strings.Count(text, " "))) * t.horizontalScale()
+}
+
+// paints reports whether the glyphs are visible, so that they are
+// underlined or struck through.
+func (t textState) paints() bool {
+	return t.renderMode != TextRenderInvisible && t.renderMode != TextRenderClip
+}
+
+// isolated reports whether text is written between q and Q, so that its
+// stroke color and width do not change those of the page. Text that clips
+// is not, as Q would end the clipping.
+func (t textState) isolated() bool {
+	return (t.strokeColor != nil || t.strokeWidth != 0) && t.renderMode < TextRenderFillClip
+}
+
+// writeGraphics writes the stroke color and width of text, before BT.
+func (t textState) writeGraphics(w io.Writer, protection *PDFProtection) error {
+	if t.strokeColor != nil {
+		if err := t.strokeColor.write(w, protection); err != nil {
+			return err
+		}
+	}
+	if t.strokeWidth != 0 {
+		if _, err := fmt.Fprintf(w, "%s w\n", FormatFloatTrim(t.strokeWidth)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// write writes the text state operators that differ from the defaults,
+// inside BT. The text state outlasts ET, so reset restores them after it.
+func (t textState) write(w io.Writer) error {
+	if t.renderMode != TextRenderFill {
+		if _, err := fmt.Fprintf(w, "%d Tr\n", t.renderMode); err != nil {
+			return err
+		}
+	}
+	if t.rise != 0 {
+		if _, err := fmt.Fprintf(w, "%s Ts\n", FormatFloatTrim(t.rise)); err != nil {
+			return err
+		}
+	}
+	if t.scale != 0 {
+		if _, err := fmt.Fprintf(w, "%s Tz\n", FormatFloatTrim(t.scale)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// reset restores the text state operators written by write to their
+// defaults, after ET.
+func (t textState) reset(w io.Writer) error {
+	if t.renderMode != TextRenderFill {
+		if _, err := io.WriteString(w, "0 Tr\n"); err != nil {
+			return err
+		}
+	}
+	if t.rise != 0 {
+		if _, err := io.WriteString(w, "0 Ts\n"); err != nil {
+			return err
+		}
+	}
+	if t.scale != 0 {
+		if _, err := io.WriteString(w, "100 Tz\n"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
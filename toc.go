@@ -10,6 +10,9 @@ type TOCItem struct {
 	PageNo int
 	// Y is the vertical position on the target page (in points from top).
 	Y float64
+	// Dest is an explicit or named destination with a view mode, used
+	// instead of PageNo and Y. Its Page defaults to PageNo.
+	Dest *Destination
 }
 
 // GetTOC returns the table of contents (outline/bookmark tree) as a flat list.
@@ -50,12 +53,8 @@ func (gp *GoPdf) GetTOC() []TOCItem {
 
 	// Map dest (page obj ID) -> page number.
 	pageObjIDs := make(map[int]int) // objID -> 1-based page number
-	pageCount := 0
-	for i, obj := range gp.pdfObjs {
-		if _, ok := obj.(*PageObj); ok {
-			pageCount++
-			pageObjIDs[i+1] = pageCount
-		}
+	for n, i := range gp.pageObjIndexes() {
+		pageObjIDs[i+1] = n + 1
 	}
 
 	// Traverse the linked list starting from first.
@@ -89,12 +88,17 @@ func (gp *GoPdf) collectTOCItems(
 		pageNo = -1
 	}
 
-	*items = append(*items, TOCItem{
+	item := TOCItem{
 		Level:  level,
 		Title:  o.title,
 		PageNo: pageNo,
 		Y:      o.height,
-	})
+	}
+	if o.destination != nil {
+		dest := gp.destToUnits(*o.destination)
+		item.Dest = &dest
+	}
+	*items = append(*items, item)
 
 	// Recurse into children.
 	if o.first > 0 {
@@ -143,12 +147,8 @@ func (gp *GoPdf) SetTOC(items []TOCItem) error {
 
 	// Build page number -> page obj ID map.
 	pageObjIDByNo := make(map[int]int) // 1-based page number -> obj ID
-	pageCount := 0
-	for i, obj := range gp.pdfObjs {
-		if _, ok := obj.(*PageObj); ok {
-			pageCount++
-			pageObjIDByNo[pageCount] = i + 1
-		}
+	for n, i := range gp.pageObjIndexes() {
+		pageObjIDByNo[n+1] = i + 1
 	}
 
 	// Reset outlines.
@@ -168,11 +168,13 @@ func (gp *GoPdf) SetTOC(items []TOCItem) error {
 
 	if allFlat {
 		for _, item := range items {
-			dest := pageObjIDByNo[item.PageNo]
+			dest := tocItemPageObjID(item, pageObjIDByNo)
 			if dest == 0 {
 				continue
 			}
-			if item.Y > 0 {
+			if item.Dest != nil {
+				gp.setTOCItemDest(gp.outlines.AddOutlinesWithPosition(dest, item.Title, item.Y), item)
+			} else if item.Y > 0 {
 				gp.outlines.AddOutlinesWithPosition(dest, item.Title, item.Y)
 			} else {
 				gp.outlines.AddOutline(dest, item.Title)
@@ -191,12 +193,15 @@ func (gp *GoPdf) SetTOC(items []TOCItem) error {
 	var stack []nodeInfo
 
 	for _, item := range items {
-		dest := pageObjIDByNo[item.PageNo]
+		dest := tocItemPageObjID(item, pageObjIDByNo)
 		if dest == 0 {
 			continue
 		}
 
 		oo := gp.outlines.AddOutlinesWithPosition(dest, item.Title, item.Y)
+		if item.Dest != nil {
+			gp.setTOCItemDest(oo, item)
+		}
 		node := &OutlineNode{Obj: oo}
 
 		// Pop stack until we find the parent level.
@@ -226,6 +231,29 @@ func (gp *GoPdf) SetTOC(items []TOCItem) error {
 	return nil
 }
 
+// tocItemPageObjID returns the page object ID of the target page of a TOC
+// item. Items with a named destination fall back to the first page.
+func tocItemPageObjID(item TOCItem, pageObjIDByNo map[int]int) int {
+	pageNo := item.PageNo
+	if item.Dest != nil {
+		if item.Dest.Page > 0 {
+			pageNo = item.Dest.Page
+		} else if pageNo <= 0 && item.Dest.Name != "" {
+			pageNo = 1
+		}
+	}
+	return pageObjIDByNo[pageNo]
+}
+
+// setTOCItemDest sets the destination of the outline item of a TOC item.
+func (gp *GoPdf) setTOCItemDest(oo *OutlineObj, item TOCItem) {
+	dest := *item.Dest
+	if dest.Page <= 0 && dest.Name == "" {
+		dest.Page = item.PageNo
+	}
+	oo.setDestination(gp.destToPoints(dest), func() *GoPdf { return gp })
+}
+
 // ErrInvalidTOCLevel is returned when TOC items have invalid hierarchy levels.
 var ErrInvalidTOCLevel = errorf("invalid TOC level: first item must be level 1, and levels may increase by at most 1")
 
@@ -0,0 +1,437 @@
+package gopdf
+
+import (
+	"errors"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// ============================================================
+// Printed table of contents
+// ============================================================
+
+var (
+	// ErrNoTOCPages is returned by RenderTOC without ReserveTOCPages.
+	ErrNoTOCPages = errors.New("no pages reserved for the table of contents")
+	// ErrTOCRendered is returned when the table of contents is rendered twice.
+	ErrTOCRendered = errors.New("table of contents already rendered")
+)
+
+// TOCPageOption is the layout of a printed table of contents.
+type TOCPageOption struct {
+	// Title is the heading of the first page, e.g. "Contents". No heading
+	// when empty.
+	Title string
+	// FontFamily is the font of the entries (must be pre-loaded). Default
+	// is the current font.
+	FontFamily string
+	// FontSize is the font size of the entries (default 12).
+	FontSize float64
+	// TitleFontSize is the font size of the heading (default 18).
+	TitleFontSize float64
+	// LineHeight is the distance between entries in document units
+	// (default 1.6 × FontSize).
+	LineHeight float64
+	// Indent is the indentation per level in document units (default 20pt).
+	Indent float64
+	// Leader is the string repeated between a title and its page number
+	// (default "."). Use " " for no leader.
+	Leader string
+	// MaxLevel is the deepest outline level listed (0 = all levels).
+	MaxLevel int
+	// UsePageLabels prints the page labels set with SetPageLabels instead
+	// of page numbers.
+	UsePageLabels bool
+}
+
+// tocReservation is the pages reserved by ReserveTOCPages.
+type tocReservation struct {
+	pages    []*PageObj
+	opt      TOCPageOption
+	rendered bool
+}
+
+// tocLine is an entry of a printed table of contents.
+type tocLine struct {
+	item  TOCItem
+	label string
+}
+
+// ReserveTOCPages adds n pages at the current position for a table of
+// contents, printed by RenderTOC when the document is complete.
+//
+// Example:
+//
+//	pdf.AddPage() // cover
+//	pdf.ReserveTOCPages(1, gopdf.TOCPageOption{Title: "Contents"})
+//	// ... chapters with AddOutline ...
+//	pdf.RenderTOC()
+func (gp *GoPdf) ReserveTOCPages(n int, opt TOCPageOption) error {
+	if n < 1 {
+		return errors.New("table of contents needs at least one page")
+	}
+	if gp.tocPages != nil {
+		return errors.New("pages for the table of contents are already reserved")
+	}
+	res := &tocReservation{opt: opt}
+	for i := 0; i < n; i++ {
+		res.pages = append(res.pages, gp.addTOCPage(PageOption{}))
+	}
+	gp.tocPages = res
+	return nil
+}
+
+// addTOCPage adds a page with a content stream, so that it can be drawn
+// on later.
+func (gp *GoPdf) addTOCPage(opt PageOption) *PageObj {
+	gp.AddPageWithOption(opt)
+	gp.getContent()
+	return gp.pdfObjs[gp.curr.IndexOfPageObj].(*PageObj)
+}
+
+// RenderTOC prints the outline into the pages reserved with
+// ReserveTOCPages: one entry per outline item, indented by level, with a
+// dot leader, a right-aligned page number and a link to the destination.
+// Pages are inserted after the reserved pages when the entries do not fit;
+// page numbers of destinations, named destinations and page labels after
+// them are shifted. It returns the number of pages of the table of
+// contents.
+func (gp *GoPdf) RenderTOC() (int, error) {
+	res := gp.tocPages
+	if res == nil {
+		return 0, ErrNoTOCPages
+	}
+	if res.rendered {
+		return 0, ErrTOCRendered
+	}
+	opt := res.opt
+	if opt.FontFamily == "" {
+		if gp.curr.FontISubset == nil {
+			return 0, ErrMissingFontFamily
+		}
+		opt.FontFamily = gp.curr.FontISubset.GetFamily()
+	}
+	if opt.FontSize <= 0 {
+		opt.FontSize = 12
+	}
+	if opt.TitleFontSize <= 0 {
+		opt.TitleFontSize = 18
+	}
+	lineH := opt.FontSize * 1.6
+	if opt.LineHeight > 0 {
+		lineH = gp.UnitsToPoints(opt.LineHeight)
+	}
+	indent := 20.0
+	if opt.Indent > 0 {
+		indent = gp.UnitsToPoints(opt.Indent)
+	}
+	if opt.Leader == "" {
+		opt.Leader = "."
+	}
+
+	// Save the state changed by drawing.
+	curX, curY, pageIdx, content := gp.curr.X, gp.curr.Y, gp.curr.IndexOfPageObj, gp.indexOfContent
+	fontSize, fontStyle, fontCount, font := gp.curr.FontSize, gp.curr.FontStyle, gp.curr.FontFontCount, gp.curr.FontISubset
+	pageSize, trimBox := gp.curr.pageSize, gp.curr.trimBox
+	defer func() {
+		gp.curr.X, gp.curr.Y, gp.curr.IndexOfPageObj, gp.indexOfContent = curX, curY, pageIdx, content
+		gp.curr.FontSize, gp.curr.FontStyle, gp.curr.FontFontCount, gp.curr.FontISubset = fontSize, fontStyle, fontCount, font
+		gp.curr.pageSize, gp.curr.trimBox = pageSize, trimBox
+	}()
+	if err := gp.SetFont(opt.FontFamily, "", opt.FontSize); err != nil {
+		return 0, err
+	}
+
+	// Paginate: the first page starts below the heading.
+	pageW, pageH := gp.config.PageSize.W, gp.config.PageSize.H
+	if first := res.pages[0]; !first.pageOption.isEmpty() {
+		pageW, pageH = first.pageOption.PageSize.W, first.pageOption.PageSize.H
+	}
+	top, bottom := gp.margins.Top, pageH-gp.margins.Bottom
+	titleH := 0.0
+	if opt.Title != "" {
+		titleH = opt.TitleFontSize * 2
+	}
+	counts := []int{0} // entries per page
+	rowY := top + titleH
+	for range gp.tocItems(opt.MaxLevel) {
+		if rowY+lineH > bottom {
+			counts = append(counts, 0)
+			rowY = top
+		}
+		counts[len(counts)-1]++
+		rowY += lineH
+	}
+
+	// Insert the missing pages after the reserved ones.
+	if extra := len(counts) - len(res.pages); extra > 0 {
+		last := gp.pageNoOf(res.pages[len(res.pages)-1])
+		var added []*PageObj
+		for i := 0; i < extra; i++ {
+			added = append(added, gp.addTOCPage(res.pages[0].pageOption))
+		}
+		gp.insertPagesAfter(last, added)
+		res.pages = append(res.pages, added...)
+	}
+
+	// Page numbers, right-aligned in a column as wide as the widest.
+	items := gp.tocItems(opt.MaxLevel)
+	lines := make([][]tocLine, len(counts))
+	numW := 0.0
+	for i, n := range counts {
+		for _, item := range items[:n] {
+			line := tocLine{item: item, label: gp.tocPageLabel(item, opt.UsePageLabels)}
+			w, err := gp.measureTOCText(line.label)
+			if err != nil {
+				return 0, err
+			}
+			numW = math.Max(numW, w)
+			lines[i] = append(lines[i], line)
+		}
+		items = items[n:]
+	}
+	leaderW, err := gp.measureTOCText(opt.Leader)
+	if err != nil {
+		return 0, err
+	}
+	left, right := gp.margins.Left, pageW-gp.margins.Right
+	const gap = 4.0
+
+	for i, page := range lines {
+		gp.selectTOCPage(res.pages[i])
+		y := top
+		if i == 0 && opt.Title != "" {
+			if err := gp.SetFont(opt.FontFamily, "", opt.TitleFontSize); err != nil {
+				return 0, err
+			}
+			if err := gp.drawTOCText(left, y, opt.Title); err != nil {
+				return 0, err
+			}
+			if err := gp.SetFont(opt.FontFamily, "", opt.FontSize); err != nil {
+				return 0, err
+			}
+			y += titleH
+		}
+		for _, line := range page {
+			x := left + float64(line.item.Level-1)*indent
+			numX := right - numW
+			title := line.item.Title
+			titleW, err := gp.measureTOCText(title)
+			if err != nil {
+				return 0, err
+			}
+			// Shorten titles that run into the page numbers.
+			for titleW > numX-gap-x && title != "" {
+				runes := []rune(strings.TrimSuffix(title, "..."))
+				if len(runes) == 0 {
+					title, titleW = "", 0
+					break
+				}
+				title = string(runes[:len(runes)-1]) + "..."
+				if titleW, err = gp.measureTOCText(title); err != nil {
+					return 0, err
+				}
+			}
+			if err := gp.drawTOCText(x, y, title); err != nil {
+				return 0, err
+			}
+			if n := int((numX - gap - (x + titleW + gap)) / leaderW); n > 0 && leaderW > 0 {
+				if err := gp.drawTOCText(numX-gap-float64(n)*leaderW, y, strings.Repeat(opt.Leader, n)); err != nil {
+					return 0, err
+				}
+			}
+			labelW, _ := gp.measureTOCText(line.label)
+			if err := gp.drawTOCText(right-labelW, y, line.label); err != nil {
+				return 0, err
+			}
+			if dest, ok := gp.tocItemDest(line.item); ok {
+				gp.addLink(linkOption{x: x, y: pageH - y, w: right - x, h: lineH, dest: &dest})
+			}
+			y += lineH
+		}
+	}
+	res.rendered = true
+	return len(res.pages), nil
+}
+
+// measureTOCText returns the width of text in points in the current font.
+func (gp *GoPdf) measureTOCText(text string) (float64, error) {
+	text, err := gp.curr.FontISubset.AddChars(text)
+	if err != nil {
+		return 0, err
+	}
+	_, _, w, err := createContent(gp.curr.FontISubset, text, gp.curr.FontSize, gp.curr.CharSpacing, nil)
+	return w, err
+}
+
+// drawTOCText draws text with its upper-left corner at x, y in points.
+func (gp *GoPdf) drawTOCText(x, y float64, text string) error {
+	gp.curr.X, gp.curr.Y = x, y
+	gp.curr.setXCount++
+	text, err := gp.curr.FontISubset.AddChars(text)
+	if err != nil {
+		return err
+	}
+	return gp.getContent().AppendStreamSubsetFont(nil, text, CellOption{Align: Left | Top})
+}
+
+// selectTOCPage makes page the current page for drawing.
+func (gp *GoPdf) selectTOCPage(page *PageObj) {
+	for i, obj := range gp.pdfObjs {
+		if obj == IObj(page) {
+			gp.curr.IndexOfPageObj = i
+			gp.curr.pageSize = &gp.config.PageSize
+			if !page.pageOption.isEmpty() {
+				gp.curr.pageSize = page.pageOption.PageSize
+			}
+			for j := i + 1; j < len(gp.pdfObjs); j++ {
+				if _, ok := gp.pdfObjs[j].(*ContentObj); ok {
+					gp.indexOfContent = j
+					break
+				}
+			}
+			return
+		}
+	}
+}
+
+// pageNoOf returns the 1-based number of page, or 0.
+func (gp *GoPdf) pageNoOf(page *PageObj) int {
+	for n, i := range gp.pageObjIndexes() {
+		if gp.pdfObjs[i] == IObj(page) {
+			return n + 1
+		}
+	}
+	return 0
+}
+
+// tocItems returns the outline items up to level maxLevel (0 = all).
+func (gp *GoPdf) tocItems(maxLevel int) []TOCItem {
+	var items []TOCItem
+	for _, item := range gp.GetTOC() {
+		if maxLevel <= 0 || item.Level <= maxLevel {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// tocPageLabel returns the printed page number or label of a TOC item.
+func (gp *GoPdf) tocPageLabel(item TOCItem, useLabels bool) string {
+	pageNo := item.PageNo
+	if item.Dest != nil {
+		pageNo = gp.destPageNo(*item.Dest, pageNo)
+	}
+	if pageNo < 1 {
+		return ""
+	}
+	if useLabels && len(gp.pageLabels) > 0 {
+		return gp.computePageLabel(pageNo - 1)
+	}
+	return strconv.Itoa(pageNo)
+}
+
+// destPageNo returns the page number of a destination, resolving named
+// destinations and anchors, or def.
+func (gp *GoPdf) destPageNo(d Destination, def int) int {
+	if d.Name == "" {
+		if d.Page > 0 {
+			return d.Page
+		}
+		return def
+	}
+	if nd, ok := gp.namedDests[d.Name]; ok {
+		return nd.Page
+	}
+	if a, ok := gp.anchors[d.Name]; ok {
+		for n, i := range gp.pageObjIndexes() {
+			if i == a.page {
+				return n + 1
+			}
+		}
+	}
+	return def
+}
+
+// tocItemDest returns the destination in points of the link of a TOC item.
+func (gp *GoPdf) tocItemDest(item TOCItem) (Destination, bool) {
+	if item.Dest != nil {
+		d := gp.destToPoints(*item.Dest)
+		if d.Page <= 0 && d.Name == "" {
+			d.Page = item.PageNo
+		}
+		return d, true
+	}
+	if item.PageNo < 1 {
+		return Destination{}, false
+	}
+	return Destination{Page: item.PageNo, Top: gp.pageHeight(item.PageNo) - item.Y}, true
+}
+
+// shiftPageRefs adds by to the page numbers greater than after of the
+// destinations of links, outline items, pages, form fields, the document,
+// named destinations and page label ranges.
+func (gp *GoPdf) shiftPageRefs(after, by int) {
+	seen := make(map[*Action]bool)
+	var shiftDest func(d *Destination)
+	shiftDest = func(d *Destination) {
+		if d != nil && d.Name == "" && d.Page > after {
+			d.Page += by
+		}
+	}
+	var shiftAction func(a *Action)
+	shiftAction = func(a *Action) {
+		if a == nil || seen[a] {
+			return
+		}
+		seen[a] = true
+		if a.Type == ActionGoTo {
+			shiftDest(&a.Dest)
+		}
+		for i := range a.Next {
+			shiftAction(&a.Next[i])
+		}
+	}
+	for _, obj := range gp.pdfObjs {
+		switch o := obj.(type) {
+		case annotObj:
+			shiftAction(o.action)
+			shiftDest(o.dest)
+		case *OutlineObj:
+			shiftAction(o.action)
+			shiftDest(o.destination)
+		case *PageObj:
+			shiftAction(o.openAction)
+			shiftAction(o.closeAction)
+		case *CatalogObj:
+			shiftAction(o.openAction)
+			if a := o.actions; a != nil {
+				for _, e := range a.entries() {
+					shiftAction(e.action)
+				}
+			}
+		case annotationObj:
+			if a := o.opt.Actions; a != nil {
+				for _, e := range a.entries() {
+					shiftAction(e.action)
+				}
+			}
+		case formFieldObj:
+			if a := o.field.Actions; a != nil {
+				for _, fa := range []*Action{a.Activate, a.Enter, a.Exit, a.Down, a.Up, a.Focus, a.Blur} {
+					shiftAction(fa)
+				}
+			}
+		}
+	}
+	for name, d := range gp.namedDests {
+		shiftDest(&d)
+		gp.namedDests[name] = d
+	}
+	for i := range gp.pageLabels {
+		if gp.pageLabels[i].PageIndex >= after {
+			gp.pageLabels[i].PageIndex += by
+		}
+	}
+}
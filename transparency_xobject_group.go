@@ -11,6 +11,8 @@ type TransparencyXObjectGroup struct {
 	Matrix           [6]float64
 	ExtGStateIndexes []int
 	XObjects         []cacheContentImage
+	// Content is a raw content stream painted after XObjects (e.g. vector mask shapes).
+	Content []byte
 
 	getRoot       func() *GoPdf
 	pdfProtection *PDFProtection
@@ -21,12 +23,14 @@ type TransparencyXObjectGroupOptions struct {
 	ExtGStateIndexes []int
 	BBox             [4]float64
 	XObjects         []cacheContentImage
+	Content          []byte
 }
 
 func GetCachedTransparencyXObjectGroup(opts TransparencyXObjectGroupOptions, gp *GoPdf) (TransparencyXObjectGroup, error) {
 	group := TransparencyXObjectGroup{
 		BBox:             opts.BBox,
 		XObjects:         opts.XObjects,
+		Content:          opts.Content,
 		pdfProtection:    opts.Protection,
 		ExtGStateIndexes: opts.ExtGStateIndexes,
 	}
@@ -63,6 +67,7 @@ func (s TransparencyXObjectGroup) write(w io.Writer, objId int) error {
 			return err
 		}
 	}
+	streamBuff.Write(s.Content)
 
 	content := "<<\n"
 	content += "\t/FormType 1\n"
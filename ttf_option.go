@@ -1,11 +1,16 @@
 package gopdf
 
+import "github.com/VantageDataChat/GoPDF2/fontmaker/core"
+
 // TtfOption  font option
 type TtfOption struct {
 	UseKerning                bool
-	Style                     int               //Regular|Bold|Italic
-	OnGlyphNotFound           func(r rune)      //Called when a glyph cannot be found, just for debugging
-	OnGlyphNotFoundSubstitute func(r rune) rune //Called when a glyph cannot be found, we can return a new rune to replace it.
+	Style                     int                //Regular|Bold|Italic
+	OnGlyphNotFound           func(r rune)       //Called when a glyph cannot be found, just for debugging
+	OnGlyphNotFoundSubstitute func(r rune) rune  //Called when a glyph cannot be found, we can return a new rune to replace it.
+	CollectionIndex           int                //Face of a TrueType Collection (.ttc) to load, by index
+	CollectionFontName        string             //Face of a TrueType Collection (.ttc) to load, by PostScript name; takes precedence over CollectionIndex
+	Variations                map[string]float64 //Axis values of a variable font, by axis tag (e.g. "wght": 700), at which it is instantiated
 }
 
 func defaultTtfFontOption() TtfOption {
@@ -19,3 +24,9 @@ func defaultTtfFontOption() TtfOption {
 func DefaultOnGlyphNotFoundSubstitute(r rune) rune {
 	return rune('\u0020')
 }
+
+// CollectionFontNames returns the PostScript names of the faces of a
+// TrueType Collection (.ttc), in the order of TtfOption.CollectionIndex.
+func CollectionFontNames(fontData []byte) ([]string, error) {
+	return core.CollectionPostScriptNames(fontData)
+}
@@ -61,6 +61,16 @@ func (u *UnicodeMap) write(w io.Writer, objID int) error {
 		//glyphIndexToCharacter[index] = k
 		glyphIndexToCharacter.set(index, k)
 	}
+	for _, v := range u.PtrToSubsetFontObj.verticalGlyphs() {
+		index := int(v)
+		if index < lowIndex {
+			lowIndex = index
+		}
+		if index > hiIndex {
+			hiIndex = index
+		}
+		glyphIndexToCharacter.set(index, u.PtrToSubsetFontObj.vertical.glyphs[v])
+	}
 
 	buff := GetBuffer()
 	defer PutBuffer(buff)
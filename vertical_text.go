@@ -0,0 +1,478 @@
+package gopdf
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+)
+
+// ============================================================
+// Vertical writing (tategaki)
+// ============================================================
+
+// WritingMode is the direction in which lines of text run.
+type WritingMode int
+
+const (
+	// WritingModeHorizontal writes lines left to right, stacked top to
+	// bottom (default).
+	WritingModeHorizontal WritingMode = iota
+	// WritingModeVertical writes lines (columns) top to bottom, stacked
+	// right to left, as in Japanese tategaki.
+	WritingModeVertical
+)
+
+// ErrVerticalFont is returned when vertical text is drawn with a font that
+// cannot be written vertically, such as a standard 14 font.
+var ErrVerticalFont = errors.New("vertical writing needs a TrueType font")
+
+// defaultTateChuYoko is the longest run of digits set horizontally in
+// vertical text by default.
+const defaultTateChuYoko = 2
+
+// SetWritingMode sets the writing mode of Text, Cell, CellWithOption,
+// MultiCell and MultiCellWithOption. In WritingModeVertical:
+//
+//   - Text draws a column downward from the current position, which is the
+//     center of the column at the top of the first character, and moves the
+//     current position below the text.
+//   - Cell and CellWithOption draw a column of the width of the rectangle,
+//     centered in it and aligned along it by Top, Middle or Bottom, and move
+//     the current position below the rectangle.
+//   - MultiCell and MultiCellWithOption break text into columns of the height
+//     of the rectangle and stack them from its right edge to the left.
+//   - MeasureTextWidth, and thus the functions that split text, measure the
+//     length of text along the column.
+//
+// Characters of vertical scripts, such as CJK ideographs and kana, are
+// upright and take their vertical alternates (GSUB "vrt2" or "vert"), for
+// instance for punctuation and brackets; their advance comes from the vmtx
+// table, or is one em. The fonts are written as CIDFonts with the Identity-V
+// encoding and /W2 vertical metrics. Other characters, such as Latin words,
+// are rotated 90° clockwise, except short runs of digits, which are set
+// horizontally in one em (tate-chu-yoko, see SetTateChuYoko). Vertical text
+// uses the current font only: fallback chains, bidi, justification and
+// underline do not apply.
+//
+// Example:
+//
+//	pdf.AddTTFFont("NotoSerifJP", "NotoSerifJP-Regular.ttf")
+//	pdf.SetFont("NotoSerifJP", "", 14)
+//	pdf.SetWritingMode(gopdf.WritingModeVertical)
+//	pdf.SetXY(50, 50)
+//	pdf.MultiCell(&gopdf.Rect{W: 200, H: 400}, "吾輩は猫である。名前はまだ無い。")
+func (gp *GoPdf) SetWritingMode(mode WritingMode) {
+	gp.writingMode = mode
+}
+
+// GetWritingMode returns the writing mode set with SetWritingMode.
+func (gp *GoPdf) GetWritingMode() WritingMode {
+	return gp.writingMode
+}
+
+// SetTateChuYoko sets the longest run of ASCII digits that vertical text
+// sets horizontally in one em (tate-chu-yoko), 2 by default. Longer runs
+// are rotated like Latin text. 0 turns tate-chu-yoko off.
+func (gp *GoPdf) SetTateChuYoko(maxDigits int) {
+	gp.tateChuYoko = maxDigits
+}
+
+// isVertical reports whether text is written vertically.
+func (gp *GoPdf) isVertical() bool {
+	return gp.writingMode == WritingModeVertical
+}
+
+// verticalFont is the vertical (Identity-V) font of a SubsetFontObj.
+type verticalFont struct {
+	countOfFont   int
+	substitutions map[uint]uint // glyph to vertical alternate
+	glyphs        map[uint]rune // vertical alternates in use, to their characters
+}
+
+// verticalFontObj is the Type0 font object of a verticalFont. It shares
+// the CIDFont and ToUnicode CMap of its SubsetFontObj.
+type verticalFontObj struct {
+	PtrToSubsetFontObj *SubsetFontObj
+}
+
+func (v *verticalFontObj) init(funcGetRoot func() *GoPdf) {
+}
+
+func (v *verticalFontObj) getType() string {
+	return "VerticalFont"
+}
+
+func (v *verticalFontObj) write(w io.Writer, objID int) error {
+	s := v.PtrToSubsetFontObj
+	io.WriteString(w, "<<\n")
+	fmt.Fprintf(w, "/BaseFont /%s\n", CreateEmbeddedFontSubsetName(s.Family))
+	fmt.Fprintf(w, "/DescendantFonts [%d 0 R]\n", s.indexObjCIDFont+1)
+	io.WriteString(w, "/Encoding /Identity-V\n")
+	io.WriteString(w, "/Subtype /Type0\n")
+	fmt.Fprintf(w, "/ToUnicode %d 0 R\n", s.indexObjUnicodeMap+1)
+	io.WriteString(w, "/Type /Font\n")
+	io.WriteString(w, ">>\n")
+	return nil
+}
+
+// verticalFontOf returns the vertical font of sub, adding it to the
+// document on first use.
+func (gp *GoPdf) verticalFontOf(sub *SubsetFontObj) (*verticalFont, error) {
+	if sub.standard != nil {
+		return nil, ErrVerticalFont
+	}
+	if sub.vertical != nil {
+		return sub.vertical, nil
+	}
+	substitutions, err := sub.ttfp.VerticalSubstitutions()
+	if err != nil {
+		return nil, err
+	}
+	vertical := &verticalFont{substitutions: substitutions, glyphs: make(map[uint]rune)}
+	index := gp.addObj(&verticalFontObj{PtrToSubsetFontObj: sub})
+	if gp.indexOfProcSet != -1 {
+		procset := gp.pdfObjs[gp.indexOfProcSet].(*ProcSetObj)
+		procset.Relates = append(procset.Relates, RelateFont{Family: sub.GetFamily(), IndexOfObj: index, CountOfFont: gp.curr.CountOfFont, Style: sub.GetTtfFontOption().Style})
+		vertical.countOfFont = gp.curr.CountOfFont
+		gp.curr.CountOfFont++
+	}
+	sub.vertical = vertical
+	return vertical, nil
+}
+
+// verticalGlyphs returns the vertical alternate glyphs used by s.
+func (s *SubsetFontObj) verticalGlyphs() []uint {
+	if s.vertical == nil {
+		return nil
+	}
+	glyphs := make([]uint, 0, len(s.vertical.glyphs))
+	for glyph := range s.vertical.glyphs {
+		glyphs = append(glyphs, glyph)
+	}
+	sort.Slice(glyphs, func(i, j int) bool { return glyphs[i] < glyphs[j] })
+	return glyphs
+}
+
+// verticalAdvance returns the advance height of glyph in thousandths of
+// an em, and the y coordinate of its vertical origin when the font has
+// vertical metrics.
+func verticalAdvance(sub *SubsetFontObj, glyph uint) (advance int, originY int, ok bool) {
+	unitsPerEm := int(sub.unitsPerEm())
+	h, y, ok := sub.ttfp.VerticalMetric(glyph)
+	if !ok {
+		return 1000, 0, false
+	}
+	return int(h) * 1000 / unitsPerEm, y * 1000 / unitsPerEm, true
+}
+
+// verticalRunKind is how a run of vertical text is set.
+type verticalRunKind int
+
+const (
+	verticalUpright    verticalRunKind = iota // with the vertical font
+	verticalRotated                           // rotated 90° clockwise
+	verticalHorizontal                        // tate-chu-yoko
+)
+
+// verticalRun is a run of vertical text with its length along the column
+// in points.
+type verticalRun struct {
+	kind    verticalRunKind
+	text    string
+	glyphs  []uint // of upright runs, with vertical alternates
+	advance float64
+}
+
+// verticalRuns splits text into the runs of a column of the current font.
+func (gp *GoPdf) verticalRuns(text string) ([]verticalRun, error) {
+	sub := gp.curr.FontISubset
+	vertical, err := gp.verticalFontOf(sub)
+	if err != nil {
+		return nil, err
+	}
+	text, err = sub.AddChars(text)
+	if err != nil {
+		return nil, err
+	}
+	runes := []rune(text)
+	fontSize, charSpacing := gp.curr.FontSize, gp.curr.CharSpacing
+
+	var runs []verticalRun
+	appendRun := func(kind verticalRunKind, rs []rune) error {
+		run := verticalRun{kind: kind, text: string(rs)}
+		switch kind {
+		case verticalUpright:
+			for _, r := range rs {
+				glyph, err := sub.CharIndex(r)
+				if err == ErrCharNotFound {
+					continue
+				} else if err != nil {
+					return err
+				}
+				if alternate, ok := vertical.substitutions[glyph]; ok {
+					glyph = alternate
+					vertical.glyphs[glyph] = r
+				}
+				advance, _, _ := verticalAdvance(sub, glyph)
+				run.glyphs = append(run.glyphs, glyph)
+				run.advance += float64(advance)*fontSize/1000 + charSpacing
+			}
+		case verticalRotated:
+			_, _, w, err := createContent(sub, run.text, fontSize, charSpacing, nil)
+			if err != nil {
+				return err
+			}
+			run.advance = w
+		case verticalHorizontal:
+			run.advance = fontSize + charSpacing
+		}
+		if n := len(runs); n > 0 && kind != verticalHorizontal && runs[n-1].kind == kind {
+			runs[n-1].text += run.text
+			runs[n-1].glyphs = append(runs[n-1].glyphs, run.glyphs...)
+			runs[n-1].advance += run.advance
+			return nil
+		}
+		runs = append(runs, run)
+		return nil
+	}
+
+	for i := 0; i < len(runes); {
+		j := i
+		for j < len(runes) && runes[j] >= '0' && runes[j] <= '9' {
+			j++
+		}
+		switch {
+		case j > i && j-i <= gp.tateChuYoko:
+			err = appendRun(verticalHorizontal, runes[i:j])
+		case j > i:
+			err = appendRun(verticalRotated, runes[i:j])
+		default:
+			kind := verticalRotated
+			if isVerticalUpright(runes[i]) {
+				kind = verticalUpright
+			}
+			j = i + 1
+			err = appendRun(kind, runes[i:j])
+		}
+		if err != nil {
+			return nil, err
+		}
+		i = j
+	}
+	return runs, nil
+}
+
+// isVerticalUpright reports whether r stays upright in vertical text,
+// approximately after the Vertical_Orientation property of UAX #50:
+// characters of vertical scripts and most symbols are upright, Latin,
+// Greek, Cyrillic, general punctuation, arrows and mathematical operators
+// are rotated.
+func isVerticalUpright(r rune) bool {
+	switch {
+	case r < 0x1100:
+		return r == '§' || r == '©' || r == '®' || r == '±' || r == '×' || r == '÷' || (r >= '¼' && r <= '¾')
+	case r >= 0x2000 && r <= 0x206F: // general punctuation
+		switch r {
+		case '‖', '†', '‡', '‥', '…', '‰', '‱', '※', '‼', '⁂', '⁇', '⁈', '⁉', '⁑':
+			return true
+		}
+		return false
+	case r >= 0x2190 && r <= 0x22FF, // arrows, mathematical operators
+		r >= 0x27C0 && r <= 0x2AFF, // supplemental arrows and operators
+		r >= 0xFF61 && r <= 0xFF64: // halfwidth punctuation
+		return false
+	}
+	return true
+}
+
+// verticalRunsLength returns the length of runs along the column.
+func verticalRunsLength(runs []verticalRun) float64 {
+	length := 0.0
+	for _, run := range runs {
+		length += run.advance
+	}
+	return length
+}
+
+// appendVerticalText draws runs as a column whose center is at x and top
+// at y (in points).
+func (gp *GoPdf) appendVerticalText(runs []verticalRun, x, y float64) {
+	gp.getContent().listCache.append(&cacheContentVerticalText{
+		fontSubset:   gp.curr.FontISubset,
+		runs:         runs,
+		x:            x,
+		y:            y,
+		fontSize:     gp.curr.FontSize,
+		charSpacing:  gp.curr.CharSpacing,
+		pageHeight:   gp.curr.pageSize.H,
+		textColor:    gp.curr.textColor(),
+		txtColorMode: gp.curr.txtColorMode,
+	})
+}
+
+// verticalText draws text downward from the current position, like Text.
+func (gp *GoPdf) verticalText(text string) error {
+	runs, err := gp.verticalRuns(text)
+	if err != nil {
+		return err
+	}
+	gp.appendVerticalText(runs, gp.curr.X, gp.curr.Y)
+	gp.curr.Y += verticalRunsLength(runs)
+	return nil
+}
+
+// verticalCell draws text as a column cell of rectangle (in points), like
+// CellWithOption, and moves the current position below it.
+func (gp *GoPdf) verticalCell(rectangle *Rect, text string, opt CellOption) error {
+	runs, err := gp.verticalRuns(text)
+	if err != nil {
+		return err
+	}
+	length := verticalRunsLength(runs)
+	if rectangle == nil {
+		_, h, _, err := createContent(gp.curr.FontISubset, "", gp.curr.FontSize, gp.curr.CharSpacing, nil)
+		if err != nil {
+			return err
+		}
+		rectangle = &Rect{W: h, H: length}
+	}
+
+	x, y := gp.curr.X, gp.curr.Y
+	if opt.Border != 0 {
+		frame := opt
+		frame.Align = Left | Top
+		if err := gp.getContent().AppendStreamSubsetFont(rectangle, "", frame); err != nil {
+			return err
+		}
+	}
+	top := y
+	if opt.Align&Bottom == Bottom {
+		top = y + rectangle.H - length
+	} else if opt.Align&Middle == Middle {
+		top = y + (rectangle.H-length)/2
+	}
+	gp.appendVerticalText(runs, x+rectangle.W/2, top)
+	gp.curr.X, gp.curr.Y = x, y+rectangle.H
+	return nil
+}
+
+// verticalMultiCell breaks text into columns of the height of rectangle
+// (in units) and draws them from its right edge to the left. The current
+// position ends at the top left of the last column.
+func (gp *GoPdf) verticalMultiCell(rectangle *Rect, text string, opt CellOption) error {
+	if opt.BreakOption == nil {
+		opt.BreakOption = &BreakOption{Mode: BreakModeUnicode}
+	}
+	opt.Border = 0
+	columns, err := gp.splitTextWithOption(text, rectangle.H, opt.BreakOption)
+	if err != nil {
+		return err
+	}
+	_, columnWidth, _, err := createContent(gp.curr.FontISubset, "", gp.curr.FontSize, gp.curr.CharSpacing, nil)
+	if err != nil {
+		return err
+	}
+	area := rectangle.UnitsToPoints(gp.config.Unit)
+	x, y := gp.curr.X, gp.curr.Y
+	right := x + area.W
+	for _, column := range columns {
+		if right-columnWidth < x-1e-9 {
+			break
+		}
+		right -= columnWidth
+		gp.curr.X, gp.curr.Y = right, y
+		if err := gp.verticalCell(&Rect{W: columnWidth, H: area.H}, column, opt); err != nil {
+			return err
+		}
+	}
+	gp.curr.X, gp.curr.Y = right, y
+	return nil
+}
+
+// cacheContentVerticalText is a column of vertical text.
+type cacheContentVerticalText struct {
+	fontSubset   *SubsetFontObj
+	runs         []verticalRun
+	x, y         float64 // center and top of the column
+	fontSize     float64
+	charSpacing  float64
+	pageHeight   float64
+	textColor    ICacheColorText
+	txtColorMode string
+}
+
+func (c *cacheContentVerticalText) write(w io.Writer, protection *PDFProtection) error {
+	sub := c.fontSubset
+	size := FormatFloatTrim(c.fontSize)
+	ascender := convertTypoUnit(float64(sub.typoAscender()), sub.unitsPerEm(), c.fontSize)
+	descender := convertTypoUnit(float64(sub.typoDescender()), sub.unitsPerEm(), c.fontSize)
+	middle := (ascender + descender) / 2 // of the em box, above the baseline
+
+	io.WriteString(w, "BT\n")
+	if c.txtColorMode == "color" {
+		c.textColor.write(w, protection)
+	}
+	y := c.pageHeight - c.y
+	for _, run := range c.runs {
+		switch run.kind {
+		case verticalUpright:
+			spacing := 0.0 // the advance of vertical text is negative: Tc is added to it
+			if c.charSpacing != 0 {
+				spacing = -c.charSpacing
+			}
+			fmt.Fprintf(w, "/F%d %s Tf %s Tc\n", sub.vertical.countOfFont+1, size, FormatFloatTrim(spacing))
+			fmt.Fprintf(w, "1 0 0 1 %0.2f %0.2f Tm\n", c.x, y)
+			io.WriteString(w, "[<")
+			for _, glyph := range run.glyphs {
+				fmt.Fprintf(w, "%04X", glyph)
+			}
+			io.WriteString(w, ">] TJ\n")
+		case verticalRotated:
+			fmt.Fprintf(w, "/F%d %s Tf %s Tc\n", sub.CountOfFont+1, size, FormatFloatTrim(c.charSpacing))
+			fmt.Fprintf(w, "0 -1 1 0 %0.2f %0.2f Tm\n", c.x-middle, y)
+			c.writeHorizontal(w, run.text)
+		case verticalHorizontal:
+			_, _, width, err := createContent(sub, run.text, c.fontSize, 0, nil)
+			if err != nil {
+				return err
+			}
+			scale := 100.0
+			if width > c.fontSize {
+				scale = 100 * c.fontSize / width
+				width = c.fontSize
+			}
+			fmt.Fprintf(w, "/F%d %s Tf 0 Tc %s Tz\n", sub.CountOfFont+1, size, FormatFloatTrim(scale))
+			fmt.Fprintf(w, "1 0 0 1 %0.2f %0.2f Tm\n", c.x-width/2, y-c.fontSize/2-middle)
+			c.writeHorizontal(w, run.text)
+			io.WriteString(w, "100 Tz\n")
+		}
+		y -= run.advance
+	}
+	io.WriteString(w, "ET\n")
+	return nil
+}
+
+// writeHorizontal writes text with the horizontal font, kerned.
+func (c *cacheContentVerticalText) writeHorizontal(w io.Writer, text string) {
+	sub := c.fontSubset
+	unitsPerEm := int(sub.unitsPerEm())
+	var leftRune rune
+	var leftRuneIndex uint
+	io.WriteString(w, "[<")
+	for i, r := range text {
+		glyph, err := sub.CharIndex(r)
+		if err != nil {
+			continue
+		}
+		if i > 0 && sub.ttfFontOption.UseKerning {
+			pairval := kern(sub, leftRune, r, leftRuneIndex, glyph)
+			if kerning := convertTTFUnit2PDFUnit(int(pairval), unitsPerEm); kerning != 0 {
+				fmt.Fprintf(w, ">%d<", -kerning)
+			}
+		}
+		fmt.Fprintf(w, "%04X", glyph)
+		leftRune, leftRuneIndex = r, glyph
+	}
+	io.WriteString(w, ">] TJ\n")
+}